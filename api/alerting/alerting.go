@@ -0,0 +1,206 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/alerting"
+	pkgAlerting "github.com/banzaicloud/pipeline/pkg/alerting"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	service       *alerting.Service
+	clusterGetter common.ClusterGetter
+	errorHandler  emperror.Handler
+}
+
+func NewAPI(service *alerting.Service, clusterGetter common.ClusterGetter, errorHandler emperror.Handler) *API {
+	return &API{
+		service:       service,
+		clusterGetter: clusterGetter,
+		errorHandler:  errorHandler,
+	}
+}
+
+// RegisterRoutes registers the alerting routes of an organization.
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.GetOrganizationConfig)
+	r.PUT("", a.SetOrganizationConfig)
+	r.DELETE("", a.DeleteOrganizationConfig)
+}
+
+// RegisterClusterRoutes registers the alerting routes of a cluster.
+func (a *API) RegisterClusterRoutes(r gin.IRouter) {
+	r.GET("", a.GetClusterConfig)
+	r.PUT("", a.SetClusterConfig)
+	r.DELETE("", a.DeleteClusterConfig)
+	r.GET("/rendered", a.RenderClusterConfig)
+}
+
+// RegisterEmailRelayRoutes registers the email relay called by the Alertmanagers of the clusters.
+// The relay authenticates the requests with the signatures of the URLs, so it has to be registered without authentication.
+func (a *API) RegisterEmailRelayRoutes(r gin.IRouter) {
+	r.POST("/email/:clusterid/:receiver/:signature", a.RelayEmail)
+}
+
+// GetOrganizationConfig returns the alerting config of an organization.
+func (a *API) GetOrganizationConfig(c *gin.Context) {
+	config, err := a.service.GetOrganizationConfig(auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting alerting config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// SetOrganizationConfig replaces the alerting config of an organization.
+func (a *API) SetOrganizationConfig(c *gin.Context) {
+	var req pkgAlerting.Config
+	if err := c.BindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	config, err := a.service.SetOrganizationConfig(auth.GetCurrentOrganization(c.Request).ID, &req, currentUserID(c))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error setting alerting config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// DeleteOrganizationConfig deletes the alerting config of an organization.
+func (a *API) DeleteOrganizationConfig(c *gin.Context) {
+	err := a.service.DeleteOrganizationConfig(auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error deleting alerting config", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetClusterConfig returns the alerting config of a cluster.
+func (a *API) GetClusterConfig(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	config, err := a.service.GetClusterConfig(commonCluster)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting alerting config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// SetClusterConfig replaces the alerting config of a cluster.
+func (a *API) SetClusterConfig(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	var req pkgAlerting.Config
+	if err := c.BindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	config, err := a.service.SetClusterConfig(commonCluster, &req, currentUserID(c))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error setting alerting config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// DeleteClusterConfig deletes the alerting config of a cluster.
+func (a *API) DeleteClusterConfig(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := a.service.DeleteClusterConfig(commonCluster); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error deleting alerting config", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RenderClusterConfig returns the alerting rules and the Alertmanager configuration rendered for a cluster.
+func (a *API) RenderClusterConfig(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	rendered, err := a.service.Render(commonCluster)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error rendering alerting config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rendered)
+}
+
+// RelayEmail sends the alert emails of an Alertmanager webhook notification.
+func (a *API) RelayEmail(c *gin.Context) {
+	clusterID, err := strconv.ParseUint(c.Param("clusterid"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid cluster ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var msg pkgAlerting.WebhookMessage
+	if err := c.BindJSON(&msg); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	err = a.service.RelayEmail(c.Request.Context(), uint(clusterID), c.Param("receiver"), c.Param("signature"), &msg)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error relaying alert email", err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func currentUserID(c *gin.Context) uint {
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		return user.ID
+	}
+
+	return 0
+}
@@ -0,0 +1,38 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"github.com/banzaicloud/pipeline/internal/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	events       *audit.EventRepository
+	errorHandler emperror.Handler
+}
+
+func NewAPI(events *audit.EventRepository, errorHandler emperror.Handler) *API {
+	return &API{
+		events:       events,
+		errorHandler: errorHandler,
+	}
+}
+
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.List)
+	r.GET("/export", a.Export)
+}
@@ -0,0 +1,166 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditlog
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/audit"
+	pkgAudit "github.com/banzaicloud/pipeline/pkg/audit"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+
+	exportBatchSize = 1000
+)
+
+var exportContentTypes = map[string]string{
+	pkgAudit.FormatCSV:    "text/csv",
+	pkgAudit.FormatNDJSON: "application/x-ndjson",
+}
+
+// List returns a page of the audit events of the organization, the latest first.
+func (a *API) List(c *gin.Context) {
+	var query pkgAudit.ListEventsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+	filter, err := audit.NewEventFilter(organizationID, query)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Invalid audit event filter", err)
+		return
+	}
+
+	cursor, err := parseCursor(query.Cursor)
+	if err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	} else if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	// One more event is fetched to know whether there is a next page
+	events, err := a.events.Find(filter, cursor, limit+1)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing audit events", err)
+		return
+	}
+
+	response := pkgAudit.ListEventsResponse{
+		Events: make([]pkgAudit.EventResponse, 0, limit),
+	}
+
+	if len(events) > limit {
+		events = events[:limit]
+		response.NextCursor = strconv.FormatUint(uint64(events[limit-1].ID), 10)
+	}
+
+	for _, event := range events {
+		response.Events = append(response.Events, event.ConvertModelToEntity())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Export streams every audit event of the organization matching the filters in CSV or NDJSON format.
+func (a *API) Export(c *gin.Context) {
+	var query pkgAudit.ListEventsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+	filter, err := audit.NewEventFilter(organizationID, query)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Invalid audit event filter", err)
+		return
+	}
+
+	format := query.Format
+	if format == "" {
+		format = pkgAudit.FormatCSV
+	}
+
+	writer, err := audit.NewEventWriter(format, c.Writer)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Invalid export format", err)
+		return
+	}
+
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=audit-%d.%s", organizationID, format))
+
+	// The response is already streamed, so errors can only be reported to the error handler
+	var cursor uint
+	for {
+		events, err := a.events.Find(filter, cursor, exportBatchSize)
+		if err != nil {
+			a.errorHandler.Handle(err)
+			return
+		}
+
+		for _, event := range events {
+			if err := writer.Write(event); err != nil {
+				a.errorHandler.Handle(emperror.With(err, "organization", organizationID))
+				return
+			}
+		}
+
+		if err := writer.Flush(); err != nil {
+			a.errorHandler.Handle(emperror.With(err, "organization", organizationID))
+			return
+		}
+		c.Writer.Flush()
+
+		if len(events) < exportBatchSize {
+			return
+		}
+
+		cursor = events[len(events)-1].ID
+	}
+}
+
+func parseCursor(cursor string) (uint, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(cursor, 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid cursor: " + cursor)
+	}
+
+	return uint(id), nil
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/banzaicloud/pipeline/auth"
 	"github.com/banzaicloud/pipeline/cluster"
 	"github.com/banzaicloud/pipeline/config"
+	intAuth "github.com/banzaicloud/pipeline/internal/auth"
 	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/clustertemplate"
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
 	"github.com/banzaicloud/pipeline/pkg/k8sclient"
@@ -58,16 +60,23 @@ const (
 
 // ClusterAPI implements the Cluster API actions.
 type ClusterAPI struct {
-	clusterManager *cluster.Manager
+	clusterManager   *cluster.Manager
+	clusterTemplates *clustertemplate.Service
 
 	logger       logrus.FieldLogger
 	errorHandler emperror.Handler
 }
 
 // NewClusterAPI returns a new ClusterAPI instance.
-func NewClusterAPI(clusterManager *cluster.Manager, logger logrus.FieldLogger, errorHandler emperror.Handler) *ClusterAPI {
+func NewClusterAPI(
+	clusterManager *cluster.Manager,
+	clusterTemplates *clustertemplate.Service,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *ClusterAPI {
 	return &ClusterAPI{
-		clusterManager: clusterManager,
+		clusterManager:   clusterManager,
+		clusterTemplates: clusterTemplates,
 
 		logger:       logger,
 		errorHandler: errorHandler,
@@ -86,7 +95,9 @@ func getClusterFromRequest(c *gin.Context) (cluster.CommonCluster, bool) {
 	return clusterGetter.GetClusterFromRequest(c)
 }
 
-func getPostHookFunctions(postHooks pkgCluster.PostHooks) (ph []cluster.PostFunctioner) {
+// getPostHookFunctions returns the built-in and custom posthook functions matching the posthook names,
+// unknown posthooks are skipped
+func getPostHookFunctions(postHooks pkgCluster.PostHooks, organizationID uint) (ph []cluster.PostFunctioner, err error) {
 
 	log.Info("Get posthook function(s)")
 
@@ -104,14 +115,19 @@ func getPostHookFunctions(postHooks pkgCluster.PostHooks) (ph []cluster.PostFunc
 			log.Infof("posthook function: %s", function)
 			log.Infof("posthook params: %#v", param)
 			ph = append(ph, function)
-		} else {
+		} else if function, err := cluster.GetCustomPostHookFunction(organizationID, postHookName, param); err == nil {
+			log.Infof("custom posthook function: %s", function)
+			ph = append(ph, function)
+		} else if isNotFound(err) {
 			log.Warnf("there's no function with this name [%s]", postHookName)
+		} else {
+			return nil, emperror.With(err, "posthook", postHookName)
 		}
 	}
 
 	log.Infof("Found posthooks: %v", ph)
 
-	return
+	return ph, nil
 }
 
 // GetClusterStatus retrieves the cluster status
@@ -132,6 +148,18 @@ func GetClusterStatus(c *gin.Context) {
 		})
 		return
 	}
+
+	response.PostHooks, err = cluster.GetPostHookStatuses(commonCluster)
+	if err != nil {
+		log.Errorf("Error during getting posthook statuses: %s", err.Error())
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Error during getting posthook statuses",
+			Error:   err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, response)
 	return
 }
@@ -221,7 +249,14 @@ func (a *ClusterAPI) GetClusters(c *gin.Context) {
 
 	response := make([]pkgCluster.GetClusterStatusResponse, 0)
 
+	ctx := c.Request.Context()
+
 	for _, c := range clusters {
+		// tokens restricted to clusters cannot see the other clusters of the organization
+		if !intAuth.IsClusterAllowed(ctx, c.GetID()) {
+			continue
+		}
+
 		logger := logger.WithField("cluster", c.GetName())
 
 		status, err := c.GetStatus()
@@ -237,7 +272,7 @@ func (a *ClusterAPI) GetClusters(c *gin.Context) {
 }
 
 // ReRunPostHooks handles {cluster_id}/posthooks API request
-func ReRunPostHooks(c *gin.Context) {
+func (a *ClusterAPI) ReRunPostHooks(c *gin.Context) {
 
 	log.Info("Get common cluster")
 	commonCluster, ok := getClusterFromRequest(c)
@@ -258,19 +293,51 @@ func ReRunPostHooks(c *gin.Context) {
 
 	var posthooks []cluster.PostFunctioner
 	if len(ph) == 0 {
-		posthooks = cluster.BasePostHookFunctions
+		posthooks = cluster.GetBasePostHookFunctions(commonCluster)
 	} else {
-		posthooks = getPostHookFunctions(ph)
+		var err error
+		posthooks, err = getPostHookFunctions(ph, commonCluster.GetOrganizationId())
+		if err != nil {
+			a.errorHandler.Handle(err)
+			c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "error getting posthooks",
+				Error:   err.Error(),
+			})
+			return
+		}
 	}
 
 	log.Infof("Cluster id: %d", commonCluster.GetID())
 	log.Infof("Run posthook(s): %v", posthooks)
 
-	go cluster.RunPostHooks(posthooks, commonCluster)
+	go a.clusterManager.RunPostHooks(commonCluster, posthooks)
 
 	c.Status(http.StatusOK)
 }
 
+// GetClusterHealth checks whether the Kubernetes API of the cluster is reachable and its nodes are ready
+func GetClusterHealth(c *gin.Context) {
+
+	commonCluster, ok := getClusterFromRequest(c)
+	if ok != true {
+		return
+	}
+
+	health, err := cluster.CheckClusterHealth(commonCluster)
+	if err != nil {
+		log.Errorf("Error during checking cluster health: %s", err.Error())
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error during checking cluster health",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
+
 // ClusterHEAD checks the cluster ready
 func ClusterHEAD(c *gin.Context) {
 
@@ -402,6 +469,12 @@ func GetClusterDetails(c *gin.Context) {
 	details.SecretId = secret.ID
 	details.SecretName = secret.Name
 
+	certificate, err := cluster.GetClusterCertificate(commonCluster)
+	if err != nil {
+		log.Warnf("Error getting cluster certificate: %s", err.Error())
+	}
+	details.Certificate = certificate
+
 	c.JSON(http.StatusOK, details)
 }
 
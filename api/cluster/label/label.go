@@ -0,0 +1,88 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package label
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	clusterGetter common.ClusterGetter
+	errorHandler  emperror.Handler
+}
+
+func NewAPI(clusterGetter common.ClusterGetter, errorHandler emperror.Handler) *API {
+	return &API{
+		clusterGetter: clusterGetter,
+		errorHandler:  errorHandler,
+	}
+}
+
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.Get)
+	r.PUT("", a.Set)
+}
+
+// Get returns the labels of a cluster.
+func (a *API) Get(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	labels, err := cluster.GetClusterLabels(commonCluster)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting cluster labels", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, pkgCluster.ClusterLabels{Labels: labels})
+}
+
+// Set replaces the labels of a cluster.
+func (a *API) Set(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	var req pkgCluster.ClusterLabels
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "error during binding request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if req.Labels == nil {
+		req.Labels = map[string]string{}
+	}
+
+	if err := cluster.SetClusterLabels(commonCluster, req.Labels); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error setting cluster labels", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
@@ -0,0 +1,38 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package posthook
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/gin-gonic/gin"
+)
+
+// List lists the posthooks of a cluster with their state.
+func (a *API) List(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	statuses, err := cluster.GetPostHookStatuses(commonCluster)
+	if err != nil {
+		a.errorResponse(c, "Error listing posthooks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
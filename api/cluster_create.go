@@ -62,11 +62,21 @@ func (a *ClusterAPI) CreateClusterRequest(c *gin.Context) {
 	orgID := auth.GetCurrentOrganization(c.Request).ID
 	userID := auth.GetCurrentUser(c.Request).ID
 
-	ph := getPostHookFunctions(createClusterRequest.PostHooks)
-	ctx := ginutils.Context(context.Background(), c)
-	commonCluster, err := a.CreateCluster(ctx, &createClusterRequest, orgID, userID, ph)
+	ph, err := getPostHookFunctions(createClusterRequest.PostHooks, orgID)
 	if err != nil {
-		c.JSON(err.Code, err)
+		a.errorHandler.Handle(err)
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error getting posthooks",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx := ginutils.Context(context.Background(), c)
+	commonCluster, errResponse := a.CreateCluster(ctx, &createClusterRequest, orgID, userID, ph)
+	if errResponse != nil {
+		c.JSON(errResponse.Code, errResponse)
 		return
 	}
 
@@ -76,6 +86,54 @@ func (a *ClusterAPI) CreateClusterRequest(c *gin.Context) {
 	})
 }
 
+type clusterCreationError struct {
+	response *pkgCommon.ErrorResponse
+}
+
+func (e *clusterCreationError) Error() string {
+	if e.response.Error == "" {
+		return e.response.Message
+	}
+
+	return e.response.Message + ": " + e.response.Error
+}
+
+func (e *clusterCreationError) IsInvalid() bool {
+	return e.response.Code == http.StatusBadRequest || e.response.Code == http.StatusNotFound
+}
+
+// CreateClusterFromRequest creates a K8S cluster in the cloud outside of a cluster creation request,
+// eg. as the target of a cluster migration
+func (a *ClusterAPI) CreateClusterFromRequest(
+	ctx context.Context,
+	createClusterRequest *pkgCluster.CreateClusterRequest,
+	organizationID uint,
+	userID uint,
+) (cluster.CommonCluster, error) {
+	if createClusterRequest.SecretId == "" {
+		if createClusterRequest.SecretName == "" {
+			return nil, errors.WithStack(&clusterCreationError{&pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "either secretId or secretName has to be set",
+			}})
+		}
+
+		createClusterRequest.SecretId = secret.GenerateSecretIDFromName(createClusterRequest.SecretName)
+	}
+
+	ph, err := getPostHookFunctions(createClusterRequest.PostHooks, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	commonCluster, createErr := a.CreateCluster(ctx, createClusterRequest, organizationID, userID, ph)
+	if createErr != nil {
+		return nil, errors.WithStack(&clusterCreationError{createErr})
+	}
+
+	return commonCluster, nil
+}
+
 // CreateCluster creates a K8S cluster in the cloud
 func (a *ClusterAPI) CreateCluster(
 	ctx context.Context,
@@ -0,0 +1,113 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+// CreateClusterFromTemplate creates a cluster from a version of a cluster template
+func (a *ClusterAPI) CreateClusterFromTemplate(c *gin.Context) {
+	var request pkgCluster.CreateClusterFromTemplateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	orgID := auth.GetCurrentOrganization(c.Request).ID
+	userID := auth.GetCurrentUser(c.Request).ID
+
+	instance, err := a.clusterTemplates.Instantiate(orgID, c.Param("name"), &request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if isNotFound(err) {
+			status = http.StatusNotFound
+		} else if isInvalid(err) {
+			status = http.StatusBadRequest
+		} else {
+			a.errorHandler.Handle(err)
+		}
+
+		c.JSON(status, pkgCommon.ErrorResponse{
+			Code:    status,
+			Message: "Error rendering cluster template",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	createClusterRequest := instance.Request
+
+	if createClusterRequest.SecretId == "" {
+		if createClusterRequest.SecretName == "" {
+			c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "either secretId or secretName has to be set in the cluster template",
+			})
+			return
+		}
+
+		createClusterRequest.SecretId = secret.GenerateSecretIDFromName(createClusterRequest.SecretName)
+	}
+
+	if len(instance.Resources.Deployments) > 0 || len(instance.Resources.Secrets) > 0 {
+		if createClusterRequest.PostHooks == nil {
+			createClusterRequest.PostHooks = make(pkgCluster.PostHooks)
+		}
+
+		createClusterRequest.PostHooks[pkgCluster.ApplyClusterTemplate] = instance.Resources
+	}
+
+	ph, err := getPostHookFunctions(createClusterRequest.PostHooks, orgID)
+	if err != nil {
+		a.errorHandler.Handle(err)
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "error getting posthooks",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx := ginutils.Context(context.Background(), c)
+	commonCluster, errResponse := a.CreateCluster(ctx, createClusterRequest, orgID, userID, ph)
+	if errResponse != nil {
+		c.JSON(errResponse.Code, errResponse)
+		return
+	}
+
+	// the cluster is being created already, so failing to record its template must not fail the request
+	if err := a.clusterTemplates.BindCluster(orgID, commonCluster.GetID(), instance); err != nil {
+		a.errorHandler.Handle(emperror.With(err, "cluster", commonCluster.GetID()))
+	}
+
+	c.JSON(http.StatusAccepted, pkgCluster.CreateClusterResponse{
+		Name:       commonCluster.GetName(),
+		ResourceID: commonCluster.GetID(),
+	})
+}
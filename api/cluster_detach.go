@@ -0,0 +1,63 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/internal/platform/gin/utils"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+)
+
+// DetachCluster removes the components installed by Pipeline from an imported cluster and removes it from Pipeline
+func (a *ClusterAPI) DetachCluster(c *gin.Context) {
+	commonCluster, ok := getClusterFromRequest(c)
+	if ok != true {
+		return
+	}
+
+	force, _ := strconv.ParseBool(c.DefaultQuery("force", "false"))
+
+	// DetachCluster deletes the underlying model, so we get this data here
+	clusterID, clusterName := commonCluster.GetID(), commonCluster.GetName()
+
+	ctx := ginutils.Context(c.Request.Context(), c)
+
+	err := a.clusterManager.DetachCluster(ctx, commonCluster, force)
+	if isInvalid(err) {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Error:   err.Error(),
+		})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, pkgCommon.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Error during detaching cluster",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, DeleteClusterResponse{
+		Status:     http.StatusAccepted,
+		Name:       clusterName,
+		Message:    "Cluster is detaching",
+		ResourceID: clusterID,
+	})
+}
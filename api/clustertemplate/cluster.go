@@ -0,0 +1,61 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/gin-gonic/gin"
+)
+
+// GetClusterBinding returns the cluster template version the cluster was created from.
+func (a *API) GetClusterBinding(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	binding, err := a.service.GetBinding(auth.GetCurrentOrganization(c.Request).ID, commonCluster.GetID())
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting cluster template of cluster", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, binding)
+}
+
+// GetClusterDrift compares the cluster with the cluster template version it was created from.
+func (a *API) GetClusterDrift(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	report, err := a.service.GetDrift(
+		auth.GetCurrentOrganization(c.Request).ID,
+		commonCluster.GetID(),
+		commonCluster.GetName(),
+		cluster.NewClusterTemplateInspector(commonCluster),
+	)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error detecting cluster template drift", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,53 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/internal/clustertemplate"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	service       *clustertemplate.Service
+	clusterGetter common.ClusterGetter
+	errorHandler  emperror.Handler
+}
+
+func NewAPI(service *clustertemplate.Service, clusterGetter common.ClusterGetter, errorHandler emperror.Handler) *API {
+	return &API{
+		service:       service,
+		clusterGetter: clusterGetter,
+		errorHandler:  errorHandler,
+	}
+}
+
+// RegisterRoutes registers the cluster template routes.
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.List)
+	r.POST("", a.Create)
+	r.GET("/:name", a.Get)
+	r.DELETE("/:name", a.Delete)
+	r.GET("/:name/versions", a.ListVersions)
+	r.POST("/:name/versions", a.CreateVersion)
+	r.GET("/:name/versions/:version", a.GetVersion)
+}
+
+// RegisterClusterRoutes registers the cluster template routes of a cluster.
+func (a *API) RegisterClusterRoutes(r gin.IRouter) {
+	r.GET("", a.GetClusterBinding)
+	r.GET("/drift", a.GetClusterDrift)
+}
@@ -0,0 +1,147 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+)
+
+// List returns the cluster templates of the organization.
+func (a *API) List(c *gin.Context) {
+	templates, err := a.service.ListTemplates(auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing cluster templates", err)
+		return
+	}
+
+	response := make([]pkgCluster.ClusterTemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		response = append(response, template.ConvertModelToEntity())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Create creates a cluster template with its first version.
+func (a *API) Create(c *gin.Context) {
+	var req pkgCluster.ClusterTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	template, err := a.service.CreateTemplate(auth.GetCurrentOrganization(c.Request).ID, &req, getUserID(c))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating cluster template", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, template.ConvertModelToEntity())
+}
+
+// Get returns a cluster template of the organization.
+func (a *API) Get(c *gin.Context) {
+	template, err := a.service.GetTemplate(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting cluster template", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template.ConvertModelToEntity())
+}
+
+// Delete deletes a cluster template of the organization.
+func (a *API) Delete(c *gin.Context) {
+	err := a.service.DeleteTemplate(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error deleting cluster template", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListVersions returns the versions of a cluster template.
+func (a *API) ListVersions(c *gin.Context) {
+	versions, err := a.service.ListVersions(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing cluster template versions", err)
+		return
+	}
+
+	response := make([]pkgCluster.ClusterTemplateVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		response = append(response, version.ConvertModelToEntity())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateVersion adds a new version to a cluster template.
+func (a *API) CreateVersion(c *gin.Context) {
+	var req pkgCluster.ClusterTemplateVersionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	version, err := a.service.AddVersion(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"), &req, getUserID(c))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating cluster template version", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, version.ConvertModelToEntity())
+}
+
+// GetVersion returns a version of a cluster template. The "latest" version refers to the latest version.
+func (a *API) GetVersion(c *gin.Context) {
+	var version int
+	if c.Param("version") != "latest" {
+		v, err := strconv.Atoi(c.Param("version"))
+		if err != nil || v < 1 {
+			c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: "invalid version",
+				Error:   "version must be a positive number or latest",
+			})
+			return
+		}
+		version = v
+	}
+
+	templateVersion, err := a.service.GetVersion(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"), version)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting cluster template version", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, templateVersion.ConvertModelToEntity())
+}
+
+func getUserID(c *gin.Context) uint {
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		return user.ID
+	}
+
+	return 0
+}
@@ -14,7 +14,14 @@
 
 package common
 
-import "github.com/pkg/errors"
+import (
+	"net/http"
+
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
 
 // isNotFound checks whether an error is about a resource not being found.
 func isNotFound(err error) bool {
@@ -29,3 +36,49 @@ func isNotFound(err error) bool {
 
 	return false
 }
+
+// ErrorStatus returns the HTTP status code matching the behavior of an error.
+func ErrorStatus(err error) int {
+	cause := errors.Cause(err)
+
+	if e, ok := cause.(interface{ NotFound() bool }); ok && e.NotFound() {
+		return http.StatusNotFound
+	} else if e, ok := cause.(interface{ IsInvalid() bool }); ok && e.IsInvalid() {
+		return http.StatusBadRequest
+	} else if e, ok := cause.(interface{ Conflict() bool }); ok && e.Conflict() {
+		return http.StatusConflict
+	} else if e, ok := cause.(interface{ Forbidden() bool }); ok && e.Forbidden() {
+		return http.StatusForbidden
+	}
+
+	return http.StatusInternalServerError
+}
+
+// ErrorResponse writes an error response with a status code matching the error.
+// Unexpected errors are passed to the error handler.
+func ErrorResponse(c *gin.Context, errorHandler emperror.Handler, message string, err error) {
+	status := ErrorStatus(err)
+	if status == http.StatusInternalServerError {
+		errorHandler.Handle(err)
+	}
+
+	ErrorResponseWithStatus(c, status, message, err)
+}
+
+// ErrorResponseWithStatus writes an error response with the given status code.
+func ErrorResponseWithStatus(c *gin.Context, status int, message string, err error) {
+	c.JSON(status, pkgCommon.ErrorResponse{
+		Code:    status,
+		Message: message,
+		Error:   err.Error(),
+	})
+}
+
+// BindingErrorResponse writes an error response for a request that could not be bound.
+func BindingErrorResponse(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+		Code:    http.StatusBadRequest,
+		Message: "error during binding request",
+		Error:   err.Error(),
+	})
+}
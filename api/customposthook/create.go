@@ -0,0 +1,71 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package customposthook
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+)
+
+// Create registers a new custom posthook for the organization.
+func (a *API) Create(c *gin.Context) {
+	var request pkgCluster.CustomPostHookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	if err := cluster.ValidateCustomPostHookRequest(&request); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Invalid custom posthook", err)
+		return
+	}
+
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+	if _, err := a.postHooks.FindOne(organizationID, request.Name); err == nil {
+		c.JSON(http.StatusConflict, pkgCommon.ErrorResponse{
+			Code:    http.StatusConflict,
+			Message: "custom posthook already exists",
+			Error:   "custom posthook already exists",
+		})
+		return
+	} else if !isNotFound(err) {
+		common.ErrorResponse(c, a.errorHandler, "Error checking custom posthook", err)
+		return
+	}
+
+	postHook := &intCluster.CustomPostHookModel{
+		OrganizationID: organizationID,
+		Name:           request.Name,
+	}
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		postHook.CreatedBy = user.ID
+	}
+	postHook.SetValuesFromRequest(&request)
+
+	if err := a.postHooks.Save(postHook); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating custom posthook", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, postHook.ConvertModelToEntity())
+}
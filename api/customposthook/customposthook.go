@@ -0,0 +1,49 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package customposthook
+
+import (
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+type API struct {
+	postHooks    *intCluster.CustomPostHooks
+	errorHandler emperror.Handler
+}
+
+func NewAPI(postHooks *intCluster.CustomPostHooks, errorHandler emperror.Handler) *API {
+	return &API{
+		postHooks:    postHooks,
+		errorHandler: errorHandler,
+	}
+}
+
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.List)
+	r.POST("", a.Create)
+	r.GET("/:name", a.Get)
+	r.PUT("/:name", a.Update)
+	r.DELETE("/:name", a.Delete)
+}
+
+// isNotFound checks whether the cause of an error is a not found error.
+func isNotFound(err error) bool {
+	e, ok := errors.Cause(err).(interface{ NotFound() bool })
+
+	return ok && e.NotFound()
+}
@@ -0,0 +1,51 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package customposthook
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/gin-gonic/gin"
+)
+
+// List returns the custom posthooks of the organization.
+func (a *API) List(c *gin.Context) {
+	postHooks, err := a.postHooks.FindByOrganization(auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing custom posthooks", err)
+		return
+	}
+
+	response := make([]pkgCluster.CustomPostHookResponse, 0, len(postHooks))
+	for _, postHook := range postHooks {
+		response = append(response, postHook.ConvertModelToEntity())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get returns a custom posthook of the organization.
+func (a *API) Get(c *gin.Context) {
+	postHook, err := a.postHooks.FindOne(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting custom posthook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, postHook.ConvertModelToEntity())
+}
@@ -0,0 +1,64 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package customposthook
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+)
+
+// Update modifies a custom posthook of the organization.
+func (a *API) Update(c *gin.Context) {
+	var request pkgCluster.CustomPostHookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	if request.Name != c.Param("name") {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "custom posthooks cannot be renamed",
+			Error:   "name in the request body does not match the path",
+		})
+		return
+	}
+
+	if err := cluster.ValidateCustomPostHookRequest(&request); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Invalid custom posthook", err)
+		return
+	}
+
+	postHook, err := a.postHooks.FindOne(auth.GetCurrentOrganization(c.Request).ID, request.Name)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting custom posthook", err)
+		return
+	}
+
+	postHook.SetValuesFromRequest(&request)
+
+	if err := a.postHooks.Save(postHook); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error updating custom posthook", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, postHook.ConvertModelToEntity())
+}
@@ -0,0 +1,123 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package desiredstate
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/desiredstate"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	service       *desiredstate.Service
+	clusterGetter common.ClusterGetter
+	errorHandler  emperror.Handler
+}
+
+func NewAPI(service *desiredstate.Service, clusterGetter common.ClusterGetter, errorHandler emperror.Handler) *API {
+	return &API{
+		service:       service,
+		clusterGetter: clusterGetter,
+		errorHandler:  errorHandler,
+	}
+}
+
+// RegisterRoutes registers the desired deployment routes of a cluster.
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.Get)
+	r.PUT("", a.Set)
+	r.GET("/diff", a.Diff)
+	r.POST("/reconcile", a.Reconcile)
+}
+
+// Get returns the desired releases of a cluster with their reconciliation status.
+func (a *API) Get(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	releases, err := a.service.GetReleases(commonCluster.GetID())
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting desired deployments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, desiredstate.ConvertReleasesToEntity(releases))
+}
+
+// Set replaces the desired releases of a cluster.
+func (a *API) Set(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	var req pkgHelm.DesiredDeploymentsRequest
+	if err := c.BindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	var userID uint
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		userID = user.ID
+	}
+
+	releases, err := a.service.SetReleases(commonCluster.GetID(), &req, userID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error setting desired deployments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, desiredstate.ConvertReleasesToEntity(releases))
+}
+
+// Diff returns the changes the next reconciliation of a cluster would execute.
+func (a *API) Diff(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	diff, err := a.service.Diff(c.Request.Context(), commonCluster)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error comparing desired deployments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// Reconcile converges the releases of a cluster to their desired state immediately.
+func (a *API) Reconcile(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	releases, err := a.service.Reconcile(c.Request.Context(), commonCluster)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error reconciling desired deployments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, desiredstate.ConvertReleasesToEntity(releases))
+}
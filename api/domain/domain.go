@@ -0,0 +1,142 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/domain"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+type API struct {
+	service      *domain.Service
+	errorHandler emperror.Handler
+}
+
+func NewAPI(service *domain.Service, errorHandler emperror.Handler) *API {
+	return &API{
+		service:      service,
+		errorHandler: errorHandler,
+	}
+}
+
+// RegisterRoutes registers the custom domain routes.
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.List)
+	r.POST("", a.Create)
+	r.GET("/:domain", a.Get)
+	r.DELETE("/:domain", a.Delete)
+	r.POST("/:domain/verify", a.Verify)
+}
+
+// RegisterRecordRoutes registers the DNS record routes of the organization.
+func (a *API) RegisterRecordRoutes(r gin.IRouter) {
+	r.GET("", a.ListRecords)
+	r.POST("", a.CreateRecord)
+	r.DELETE("/:recordid", a.DeleteRecord)
+}
+
+// RegisterClusterRecordRoutes registers the DNS record routes of a cluster.
+func (a *API) RegisterClusterRecordRoutes(r gin.IRouter) {
+	r.GET("", a.ListClusterRecords)
+}
+
+// List returns the custom domains of the organization.
+func (a *API) List(c *gin.Context) {
+	customDomains, err := a.service.ListDomains(auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing custom domains", err)
+		return
+	}
+
+	response := make([]pkgDns.CustomDomainResponse, 0, len(customDomains))
+	for _, customDomain := range customDomains {
+		response = append(response, customDomain.ConvertModelToEntity())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Create attaches a custom domain to the organization.
+func (a *API) Create(c *gin.Context) {
+	var req pkgDns.CustomDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	var userID uint
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		userID = user.ID
+	}
+
+	customDomain, err := a.service.CreateDomain(auth.GetCurrentOrganization(c.Request).ID, &req, userID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating custom domain", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, customDomain.ConvertModelToEntity())
+}
+
+// Get returns a custom domain of the organization with its delegation instructions.
+func (a *API) Get(c *gin.Context) {
+	customDomain, err := a.service.GetDomain(auth.GetCurrentOrganization(c.Request).ID, c.Param("domain"))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting custom domain", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customDomain.ConvertModelToEntity())
+}
+
+// Verify checks the delegation of a custom domain of the organization.
+func (a *API) Verify(c *gin.Context) {
+	customDomain, err := a.service.VerifyDomain(auth.GetCurrentOrganization(c.Request).ID, c.Param("domain"))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error verifying custom domain", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, customDomain.ConvertModelToEntity())
+}
+
+// Delete detaches a custom domain from the organization.
+func (a *API) Delete(c *gin.Context) {
+	if err := a.service.DeleteDomain(auth.GetCurrentOrganization(c.Request).ID, c.Param("domain")); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error deleting custom domain", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getIDParam parses a numeric ID path parameter.
+func getIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		common.BindingErrorResponse(c, errors.Errorf("invalid %s: %s", name, c.Param(name)))
+		return 0, false
+	}
+
+	return uint(id), true
+}
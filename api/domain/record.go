@@ -0,0 +1,96 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRecords returns the DNS records in the domains of the organization.
+func (a *API) ListRecords(c *gin.Context) {
+	records, err := a.service.ListRecords(auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing DNS records", err)
+		return
+	}
+
+	if records == nil {
+		records = []pkgDns.RecordResponse{}
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// ListClusterRecords returns the DNS records owned by a cluster of the organization.
+func (a *API) ListClusterRecords(c *gin.Context) {
+	clusterID, ok := getIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	records, err := a.service.ListClusterRecords(auth.GetCurrentOrganization(c.Request).ID, clusterID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing DNS records of cluster", err)
+		return
+	}
+
+	if records == nil {
+		records = []pkgDns.RecordResponse{}
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// CreateRecord pins a static DNS record in a domain of the organization.
+func (a *API) CreateRecord(c *gin.Context) {
+	var req pkgDns.StaticRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	var userID uint
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		userID = user.ID
+	}
+
+	record, err := a.service.CreateStaticRecord(auth.GetCurrentOrganization(c.Request).ID, &req, userID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating static DNS record", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, record.ConvertModelToEntity())
+}
+
+// DeleteRecord deletes a static DNS record of the organization.
+func (a *API) DeleteRecord(c *gin.Context) {
+	id, ok := getIDParam(c, "recordid")
+	if !ok {
+		return
+	}
+
+	if err := a.service.DeleteStaticRecord(auth.GetCurrentOrganization(c.Request).ID, id); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error deleting static DNS record", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
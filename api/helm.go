@@ -18,6 +18,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -303,6 +304,153 @@ func GetDeploymentResources(c *gin.Context) {
 
 }
 
+// GetDeploymentHistory returns the revisions of a helm deployment
+func GetDeploymentHistory(c *gin.Context) {
+	name := c.Param("name")
+	log.Infof("getting history for deployment: [%s]", name)
+
+	kubeConfig, ok := GetK8sConfig(c)
+	if !ok {
+		log.Errorf("could not get the k8s config for querying the history of deployment: [%s]", name)
+		return
+	}
+
+	revisions, err := helm.GetDeploymentHistory(name, kubeConfig)
+	if err != nil {
+		log.Error("Error during getting deployment history: ", err.Error())
+
+		httpStatusCode := http.StatusInternalServerError
+		if _, ok := err.(*helm.DeploymentNotFoundError); ok {
+			httpStatusCode = http.StatusNotFound
+		}
+
+		c.JSON(httpStatusCode, pkgCommmon.ErrorResponse{
+			Code:    httpStatusCode,
+			Message: "Error getting deployment history",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetDeploymentDiff returns the manifest and values differences between two revisions of a helm deployment
+func GetDeploymentDiff(c *gin.Context) {
+	name := c.Param("name")
+	log.Infof("getting diff for deployment: [%s]", name)
+
+	from, err := parseRevisionQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommmon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	to, err := parseRevisionQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommmon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	kubeConfig, ok := GetK8sConfig(c)
+	if !ok {
+		log.Errorf("could not get the k8s config for querying the diff of deployment: [%s]", name)
+		return
+	}
+
+	diff, err := helm.GetDeploymentDiff(name, from, to, kubeConfig)
+	if err != nil {
+		log.Error("Error during getting deployment diff: ", err.Error())
+
+		httpStatusCode := http.StatusInternalServerError
+		if _, ok := err.(*helm.DeploymentNotFoundError); ok {
+			httpStatusCode = http.StatusNotFound
+		}
+
+		c.JSON(httpStatusCode, pkgCommmon.ErrorResponse{
+			Code:    httpStatusCode,
+			Message: "Error getting deployment diff",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// RollbackDeployment rolls back a helm deployment to a given revision
+func RollbackDeployment(c *gin.Context) {
+	name := c.Param("name")
+	log.Infof("rolling back deployment: [%s]", name)
+
+	var request pkgHelm.RollbackDeploymentRequest
+	if err := c.BindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommmon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if request.Revision < 1 {
+		c.JSON(http.StatusBadRequest, pkgCommmon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error parsing request",
+			Error:   "revision must be a positive number",
+		})
+		return
+	}
+
+	kubeConfig, ok := GetK8sConfig(c)
+	if !ok {
+		log.Errorf("could not get the k8s config for rolling back deployment: [%s]", name)
+		return
+	}
+
+	rollbackRes, err := helm.RollbackDeployment(name, request.Revision, kubeConfig)
+	if err != nil {
+		log.Error("Error during rolling back deployment: ", err.Error())
+
+		httpStatusCode := http.StatusInternalServerError
+		if _, ok := err.(*helm.DeploymentNotFoundError); ok {
+			httpStatusCode = http.StatusNotFound
+		}
+
+		c.JSON(httpStatusCode, pkgCommmon.ErrorResponse{
+			Code:    httpStatusCode,
+			Message: "Error rolling back deployment",
+			Error:   err.Error(),
+		})
+		return
+	}
+	log.Infof("deployment [%s] rolled back to revision %d", name, request.Revision)
+
+	c.JSON(http.StatusOK, helm.ReleaseToDeploymentRevision(rollbackRes.GetRelease()))
+}
+
+func parseRevisionQuery(c *gin.Context, key string) (int32, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	revision, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || revision < 1 {
+		return 0, errors.Errorf("invalid %s revision: %q", key, value)
+	}
+
+	return int32(revision), nil
+}
+
 // InitHelmOnCluster installs Helm on AKS cluster and configure the Helm client
 func InitHelmOnCluster(c *gin.Context) {
 	log.Info("Start helm install")
@@ -0,0 +1,125 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/logging"
+	pkgLogging "github.com/banzaicloud/pipeline/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	service       *logging.Service
+	clusterGetter common.ClusterGetter
+	errorHandler  emperror.Handler
+}
+
+func NewAPI(service *logging.Service, clusterGetter common.ClusterGetter, errorHandler emperror.Handler) *API {
+	return &API{
+		service:       service,
+		clusterGetter: clusterGetter,
+		errorHandler:  errorHandler,
+	}
+}
+
+// RegisterRoutes registers the logging routes of a cluster.
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.GetConfig)
+	r.PUT("", a.SetConfig)
+	r.DELETE("", a.DeleteConfig)
+	r.GET("/rendered", a.RenderConfig)
+}
+
+// GetConfig returns the logging outputs and flows of a cluster.
+func (a *API) GetConfig(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	config, err := a.service.GetConfig(commonCluster)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting logging config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// SetConfig replaces the logging outputs and flows of a cluster.
+func (a *API) SetConfig(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	var req pkgLogging.Config
+	if err := c.BindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	config, err := a.service.SetConfig(commonCluster, &req, currentUserID(c))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error setting logging config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, config)
+}
+
+// DeleteConfig removes the logging outputs and flows of a cluster.
+func (a *API) DeleteConfig(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := a.service.DeleteConfig(commonCluster); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error deleting logging config", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RenderConfig returns the values rendered for the logging release of a cluster.
+func (a *API) RenderConfig(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	rendered, err := a.service.Render(commonCluster)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error rendering logging config", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rendered)
+}
+
+func currentUserID(c *gin.Context) uint {
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		return user.ID
+	}
+
+	return 0
+}
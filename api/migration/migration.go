@@ -0,0 +1,102 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/migration"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	service      *migration.Service
+	errorHandler emperror.Handler
+}
+
+func NewAPI(service *migration.Service, errorHandler emperror.Handler) *API {
+	return &API{
+		service:      service,
+		errorHandler: errorHandler,
+	}
+}
+
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.List)
+	r.POST("", a.Create)
+	r.GET("/:id", a.Get)
+}
+
+func (a *API) List(c *gin.Context) {
+	migrations, err := a.service.ListMigrations(c.Request.Context(), auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing cluster migrations", err)
+		return
+	}
+
+	response := make([]pkgCluster.MigrationResponse, 0, len(migrations))
+	for _, item := range migrations {
+		response = append(response, item.ConvertModelToEntity(false))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (a *API) Create(c *gin.Context) {
+	var req pkgCluster.CreateMigrationRequest
+	if err := c.BindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	var userID uint
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		userID = user.ID
+	}
+
+	item, err := a.service.CreateMigration(c.Request.Context(), auth.GetCurrentOrganization(c.Request).ID, &req, userID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating cluster migration", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, item.ConvertModelToEntity(true))
+}
+
+func (a *API) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid cluster migration ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	item, err := a.service.GetMigration(c.Request.Context(), auth.GetCurrentOrganization(c.Request).ID, uint(id))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting cluster migration", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item.ConvertModelToEntity(true))
+}
@@ -0,0 +1,57 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/internal/notification"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+type API struct {
+	service      *notification.Service
+	errorHandler emperror.Handler
+}
+
+func NewAPI(service *notification.Service, errorHandler emperror.Handler) *API {
+	return &API{
+		service:      service,
+		errorHandler: errorHandler,
+	}
+}
+
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.List)
+	r.POST("", a.Create)
+	r.GET("/:id", a.Get)
+	r.PUT("/:id", a.Update)
+	r.DELETE("/:id", a.Delete)
+	r.POST("/:id/test", a.Test)
+}
+
+// getSubscriptionID parses the subscription ID path parameter.
+func getSubscriptionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		common.BindingErrorResponse(c, errors.New("invalid notification subscription ID: "+c.Param("id")))
+		return 0, false
+	}
+
+	return uint(id), true
+}
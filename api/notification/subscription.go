@@ -0,0 +1,130 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/gin-gonic/gin"
+)
+
+// List returns the notification subscriptions of the organization.
+func (a *API) List(c *gin.Context) {
+	subscriptions, err := a.service.ListSubscriptions(auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing notification subscriptions", err)
+		return
+	}
+
+	response := make([]pkgNotification.SubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, subscription.ConvertModelToEntity())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Create creates a notification subscription for the organization.
+func (a *API) Create(c *gin.Context) {
+	var req pkgNotification.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	var userID uint
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		userID = user.ID
+	}
+
+	subscription, err := a.service.CreateSubscription(auth.GetCurrentOrganization(c.Request).ID, &req, userID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating notification subscription", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription.ConvertModelToEntity())
+}
+
+// Get returns a notification subscription of the organization.
+func (a *API) Get(c *gin.Context) {
+	id, ok := getSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	subscription, err := a.service.GetSubscription(auth.GetCurrentOrganization(c.Request).ID, id)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting notification subscription", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription.ConvertModelToEntity())
+}
+
+// Update updates a notification subscription of the organization.
+func (a *API) Update(c *gin.Context) {
+	id, ok := getSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	var req pkgNotification.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	subscription, err := a.service.UpdateSubscription(auth.GetCurrentOrganization(c.Request).ID, id, &req)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error updating notification subscription", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription.ConvertModelToEntity())
+}
+
+// Delete deletes a notification subscription of the organization.
+func (a *API) Delete(c *gin.Context) {
+	id, ok := getSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	if err := a.service.DeleteSubscription(auth.GetCurrentOrganization(c.Request).ID, id); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error deleting notification subscription", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Test sends a test notification to a subscription of the organization.
+func (a *API) Test(c *gin.Context) {
+	id, ok := getSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	if err := a.service.SendTestNotification(c.Request.Context(), auth.GetCurrentOrganization(c.Request).ID, id); err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error sending test notification", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,113 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package role
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	intAuth "github.com/banzaicloud/pipeline/internal/auth"
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	service      *intAuth.RoleService
+	errorHandler emperror.Handler
+}
+
+func NewAPI(service *intAuth.RoleService, errorHandler emperror.Handler) *API {
+	return &API{
+		service:      service,
+		errorHandler: errorHandler,
+	}
+}
+
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.List)
+	r.POST("", a.Create)
+	r.GET("/:name", a.Get)
+	r.PUT("/:name", a.Update)
+	r.DELETE("/:name", a.Delete)
+}
+
+// List returns the built-in and custom roles of the organization.
+func (a *API) List(c *gin.Context) {
+	roles, err := a.service.ListRoles(auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing roles", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// Create creates a custom role for the organization.
+func (a *API) Create(c *gin.Context) {
+	var req pkgAuth.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	role, err := a.service.CreateRole(auth.GetCurrentOrganization(c.Request).ID, &req)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating role", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// Get returns a role of the organization.
+func (a *API) Get(c *gin.Context) {
+	role, err := a.service.GetRole(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// Update updates a custom role of the organization.
+func (a *API) Update(c *gin.Context) {
+	var req pkgAuth.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	role, err := a.service.UpdateRole(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"), &req)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error updating role", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// Delete deletes a custom role of the organization.
+func (a *API) Delete(c *gin.Context) {
+	err := a.service.DeleteRole(auth.GetCurrentOrganization(c.Request).ID, c.Param("name"))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error deleting role", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/rollout"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	service      *rollout.Service
+	errorHandler emperror.Handler
+}
+
+func NewAPI(service *rollout.Service, errorHandler emperror.Handler) *API {
+	return &API{
+		service:      service,
+		errorHandler: errorHandler,
+	}
+}
+
+// RegisterRoutes registers the multi-cluster deployment routes.
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.List)
+	r.POST("", a.Create)
+	r.GET("/:id", a.Get)
+}
+
+// List lists the multi-cluster deployment rollouts of the organization.
+func (a *API) List(c *gin.Context) {
+	rollouts, err := a.service.ListRollouts(c.Request.Context(), auth.GetCurrentOrganization(c.Request).ID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error listing deployment rollouts", err)
+		return
+	}
+
+	response := make([]pkgHelm.RolloutResponse, 0, len(rollouts))
+	for _, item := range rollouts {
+		response = append(response, item.ConvertModelToEntity(nil))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Create starts rolling out a deployment to the selected clusters of the organization.
+func (a *API) Create(c *gin.Context) {
+	var req pkgHelm.CreateRolloutRequest
+	if err := c.BindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	var userID uint
+	if user := auth.GetCurrentUser(c.Request); user != nil {
+		userID = user.ID
+	}
+
+	item, targets, err := a.service.CreateRollout(c.Request.Context(), auth.GetCurrentOrganization(c.Request).ID, &req, userID)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error creating deployment rollout", err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, item.ConvertModelToEntity(targets))
+}
+
+// Get returns a multi-cluster deployment rollout with its per-cluster results.
+func (a *API) Get(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, pkgCommon.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "invalid deployment rollout ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	item, targets, err := a.service.GetRollout(c.Request.Context(), auth.GetCurrentOrganization(c.Request).ID, uint(id))
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting deployment rollout", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item.ConvertModelToEntity(targets))
+}
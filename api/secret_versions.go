@@ -0,0 +1,244 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/gin-gonic/gin"
+)
+
+// ListSecretVersions returns the metadata of every version of a secret
+func ListSecretVersions(c *gin.Context) {
+
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+	secretID := c.Param("id")
+
+	versions, err := secret.RestrictedStore.ListVersions(organizationID, secretID)
+	if err != nil {
+		log.Errorf("Error during listing secret versions: %s", err.Error())
+		abortWithSecretError(c, err, "Error during listing secret versions")
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetSecretVersion returns a specific version of a secret, the values are hidden unless requested
+func GetSecretVersion(c *gin.Context) {
+
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+	secretID := c.Param("id")
+
+	version, ok := getSecretVersionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	values, _ := strconv.ParseBool(c.DefaultQuery("values", "false"))
+
+	secretItem, err := secret.RestrictedStore.GetVersion(organizationID, secretID, version)
+	if err != nil {
+		log.Errorf("Error during getting secret version: %s", err.Error())
+		abortWithSecretError(c, err, "Error during getting secret version")
+		return
+	}
+
+	if !values {
+		for k := range secretItem.Values {
+			secretItem.Values[k] = "<hidden>"
+		}
+	}
+
+	c.JSON(http.StatusOK, secretItem)
+}
+
+// DiffSecretVersions returns the differences between two versions of a secret
+func DiffSecretVersions(c *gin.Context) {
+
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+	secretID := c.Param("id")
+
+	fromVersion, ok := getSecretVersionParam(c, c.Query("from"))
+	if !ok {
+		return
+	}
+
+	var to *secret.SecretItemResponse
+	var err error
+	if toParam := c.Query("to"); toParam != "" {
+		toVersion, ok := getSecretVersionParam(c, toParam)
+		if !ok {
+			return
+		}
+
+		to, err = secret.RestrictedStore.GetVersion(organizationID, secretID, toVersion)
+	} else {
+		to, err = secret.RestrictedStore.Get(organizationID, secretID)
+		if err == nil {
+			err = secret.HasForbiddenTag(to.Tags)
+		}
+	}
+	if err != nil {
+		log.Errorf("Error during getting secret version: %s", err.Error())
+		abortWithSecretError(c, err, "Error during getting secret version")
+		return
+	}
+
+	from, err := secret.RestrictedStore.GetVersion(organizationID, secretID, fromVersion)
+	if err != nil {
+		log.Errorf("Error during getting secret version: %s", err.Error())
+		abortWithSecretError(c, err, "Error during getting secret version")
+		return
+	}
+
+	c.JSON(http.StatusOK, secret.DiffSecrets(from, to))
+}
+
+// RollbackSecret restores a previous version of a secret as a new version
+func RollbackSecret(c *gin.Context) {
+
+	organizationID := auth.GetCurrentOrganization(c.Request).ID
+
+	secretID := c.Param("id")
+
+	validate, err := strconv.ParseBool(c.DefaultQuery("validate", "true"))
+	if err != nil {
+		validate = true
+	}
+
+	var request secret.RollbackSecretRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		log.Errorf("Error during binding RollbackSecretRequest: %s", err.Error())
+		c.AbortWithStatusJSON(http.StatusBadRequest, common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error during binding",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	current, err := secret.RestrictedStore.Get(organizationID, secretID)
+	if err != nil {
+		log.Errorf("Error during getting secret: %s", err.Error())
+		abortWithSecretError(c, err, "Error during getting secret")
+		return
+	}
+
+	if request.Version == current.Version {
+		msg := fmt.Sprintf("version %d is the current version of the secret", request.Version)
+		c.AbortWithStatusJSON(http.StatusBadRequest, common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Error during rollback",
+			Error:   msg,
+		})
+		return
+	}
+
+	previous, err := secret.RestrictedStore.GetVersion(organizationID, secretID, request.Version)
+	if err != nil {
+		log.Errorf("Error during getting secret version: %s", err.Error())
+		abortWithSecretError(c, err, "Error during getting secret version")
+		return
+	}
+
+	updateRequest := secret.CreateSecretRequest{
+		Name:      previous.Name,
+		Type:      previous.Type,
+		Values:    previous.Values,
+		Tags:      previous.Tags,
+		Version:   &current.Version,
+		UpdatedBy: auth.GetCurrentUser(c.Request).Login,
+	}
+
+	var validationError error
+	var ok bool
+	if ok, validationError = validateSecret(c, &updateRequest, validate, false); !ok {
+		return
+	}
+
+	if err := secret.RestrictedStore.Update(organizationID, secretID, &updateRequest); err != nil {
+		statusCode := http.StatusInternalServerError
+		if secret.IsCASError(err) {
+			statusCode = http.StatusBadRequest
+		}
+		log.Errorf("Error during rollback: %s", err.Error())
+		c.AbortWithStatusJSON(statusCode, common.ErrorResponse{
+			Code:    statusCode,
+			Message: "Error during rollback",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	log.Debugf("Secret rolled back to version %d at: %d/%s", request.Version, organizationID, secretID)
+
+	s, err := secret.RestrictedStore.Get(organizationID, secretID)
+	if err != nil {
+		log.Errorf("error during getting secret: %s", err.Error())
+		abortWithSecretError(c, err, "Error during getting secret")
+		return
+	}
+
+	var errorMsg string
+	if validationError != nil {
+		errorMsg = validationError.Error()
+	}
+
+	c.JSON(http.StatusOK, secret.CreateSecretResponse{
+		Name:      s.Name,
+		Type:      s.Type,
+		ID:        secretID,
+		Error:     errorMsg,
+		UpdatedAt: s.UpdatedAt,
+		UpdatedBy: s.UpdatedBy,
+		Version:   s.Version,
+	})
+}
+
+func getSecretVersionParam(c *gin.Context, param string) (int, bool) {
+	version, err := strconv.Atoi(param)
+	if err != nil || version < 1 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid secret version",
+			Error:   fmt.Sprintf("invalid secret version: %q", param),
+		})
+		return 0, false
+	}
+
+	return version, true
+}
+
+func abortWithSecretError(c *gin.Context, err error, message string) {
+	statusCode := http.StatusBadRequest
+	if err == secret.ErrSecretNotExists {
+		statusCode = http.StatusNotFound
+	}
+
+	c.AbortWithStatusJSON(statusCode, common.ErrorResponse{
+		Code:    statusCode,
+		Message: message,
+		Error:   err.Error(),
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretrotation
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/gin-gonic/gin"
+)
+
+// GetPolicy returns the rotation policy of a secret.
+func (a *API) GetPolicy(c *gin.Context) {
+	policy, err := a.service.GetPolicy(auth.GetCurrentOrganization(c.Request).ID, c.Param("id"))
+	if err != nil {
+		a.errorResponse(c, "Error getting secret rotation policy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy.ConvertModelToEntity())
+}
+
+// SetPolicy creates or updates the rotation policy of a secret.
+func (a *API) SetPolicy(c *gin.Context) {
+	var req pkgSecret.RotationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	policy, err := a.service.SetPolicy(
+		auth.GetCurrentOrganization(c.Request).ID,
+		c.Param("id"),
+		&req,
+		auth.GetCurrentUser(c.Request).ID,
+	)
+	if err != nil {
+		a.errorResponse(c, "Error saving secret rotation policy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, policy.ConvertModelToEntity())
+}
+
+// DeletePolicy deletes the rotation policy of a secret.
+// Keys replaced by earlier rotations are still revoked after their grace period.
+func (a *API) DeletePolicy(c *gin.Context) {
+	if err := a.service.DeletePolicy(auth.GetCurrentOrganization(c.Request).ID, c.Param("id")); err != nil {
+		a.errorResponse(c, "Error deleting secret rotation policy", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
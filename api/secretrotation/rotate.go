@@ -0,0 +1,51 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretrotation
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/auth"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/gin-gonic/gin"
+)
+
+// Rotate rotates a secret immediately.
+// A failed rotation is returned with the FAILED status.
+func (a *API) Rotate(c *gin.Context) {
+	rotation, err := a.service.Rotate(c.Request.Context(), auth.GetCurrentOrganization(c.Request).ID, c.Param("id"))
+	if err != nil {
+		a.errorResponse(c, "Error rotating secret", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rotation.ConvertModelToEntity())
+}
+
+// ListRotations returns the rotation history of a secret.
+func (a *API) ListRotations(c *gin.Context) {
+	rotations, err := a.service.ListRotations(auth.GetCurrentOrganization(c.Request).ID, c.Param("id"))
+	if err != nil {
+		a.errorResponse(c, "Error listing secret rotations", err)
+		return
+	}
+
+	response := make([]pkgSecret.RotationResponse, 0, len(rotations))
+	for _, rotation := range rotations {
+		response = append(response, rotation.ConvertModelToEntity())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
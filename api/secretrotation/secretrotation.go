@@ -0,0 +1,56 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretrotation
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+type API struct {
+	service      *rotation.Service
+	errorHandler emperror.Handler
+}
+
+func NewAPI(service *rotation.Service, errorHandler emperror.Handler) *API {
+	return &API{
+		service:      service,
+		errorHandler: errorHandler,
+	}
+}
+
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.GetPolicy)
+	r.PUT("", a.SetPolicy)
+	r.DELETE("", a.DeletePolicy)
+	r.POST("/rotate", a.Rotate)
+	r.GET("/history", a.ListRotations)
+}
+
+// errorResponse writes an error response with a status code matching the error.
+func (a *API) errorResponse(c *gin.Context, message string, err error) {
+	if errors.Cause(err) == secret.ErrSecretNotExists {
+		common.ErrorResponseWithStatus(c, http.StatusNotFound, message, err)
+		return
+	}
+
+	common.ErrorResponse(c, a.errorHandler, message, err)
+}
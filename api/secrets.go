@@ -23,6 +23,7 @@ import (
 	"github.com/banzaicloud/pipeline/auth"
 	"github.com/banzaicloud/pipeline/cluster"
 	"github.com/banzaicloud/pipeline/config"
+	intAuth "github.com/banzaicloud/pipeline/internal/auth"
 	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
 	"github.com/banzaicloud/pipeline/pkg/common"
 	"github.com/banzaicloud/pipeline/pkg/providers"
@@ -32,6 +33,7 @@ import (
 	"github.com/banzaicloud/pipeline/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/go-errors/errors"
+	"github.com/goph/emperror"
 )
 
 // ErrNotSupportedSecretType describe an error if the secret type is not supported
@@ -87,6 +89,15 @@ func validateSecret(c *gin.Context, createSecretRequest *secret.CreateSecretRequ
 		validationError = createSecretRequest.Validate(verifier)
 	}
 
+	if validationError != nil {
+		secret.PublishValidationFailed(
+			auth.GetCurrentOrganization(c.Request).ID,
+			createSecretRequest.Name,
+			createSecretRequest.Type,
+			validationError.Error(),
+		)
+	}
+
 	if validationError != nil && validate {
 		ok = false
 		log.Errorf("Validation error: %s", validationError.Error())
@@ -305,6 +316,12 @@ func ListSecrets(c *gin.Context) {
 		return
 	}
 
+	// Tokens restricted to clusters can only list the secrets of their clusters, without the values
+	clusterIDs := intAuth.TokenClusters(c.Request.Context())
+	if clusterIDs != nil {
+		query.Values = false
+	}
+
 	log.Debugln("Organization:", organizationID, "type:", query.Type, "tags:", query.Tags, "values:", query.Values)
 
 	if err := IsValidSecretType(query.Type); err != nil {
@@ -322,12 +339,51 @@ func ListSecrets(c *gin.Context) {
 				Message: "Error during listing secrets",
 				Error:   err.Error(),
 			})
+		} else if clusterIDs == nil {
+			c.JSON(http.StatusOK, secrets)
+		} else if secrets, err := filterClusterSecrets(organizationID, clusterIDs, secrets); err != nil {
+			errorHandler.Handle(err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, common.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Error during listing secrets",
+				Error:   err.Error(),
+			})
 		} else {
 			c.JSON(http.StatusOK, secrets)
 		}
 	}
 }
 
+// filterClusterSecrets keeps the secrets tagged with the UID of one of the clusters.
+func filterClusterSecrets(organizationID uint, clusterIDs []uint, secrets []*secret.SecretItemResponse) ([]*secret.SecretItemResponse, error) {
+	clusters, err := intCluster.NewClusters(config.DB()).FindByOrganization(organizationID)
+	if err != nil {
+		return nil, emperror.Wrap(err, "could not list clusters")
+	}
+
+	clusterTags := make(map[string]bool)
+	for _, c := range clusters {
+		for _, clusterID := range clusterIDs {
+			if c.ID == clusterID {
+				clusterTags[fmt.Sprintf("clusterUID:%s", c.UID)] = true
+			}
+		}
+	}
+
+	filtered := make([]*secret.SecretItemResponse, 0, len(secrets))
+	for _, s := range secrets {
+		for _, tag := range s.Tags {
+			if clusterTags[tag] {
+				filtered = append(filtered, s)
+
+				break
+			}
+		}
+	}
+
+	return filtered, nil
+}
+
 // GetSecret returns a secret by ID
 func GetSecret(c *gin.Context) {
 
@@ -0,0 +1,89 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"net/http"
+
+	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/internal/usage"
+	pkgUsage "github.com/banzaicloud/pipeline/pkg/usage"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type API struct {
+	service       *usage.Service
+	clusterGetter common.ClusterGetter
+	errorHandler  emperror.Handler
+}
+
+func NewAPI(service *usage.Service, clusterGetter common.ClusterGetter, errorHandler emperror.Handler) *API {
+	return &API{
+		service:       service,
+		clusterGetter: clusterGetter,
+		errorHandler:  errorHandler,
+	}
+}
+
+// RegisterRoutes registers the usage routes of an organization.
+func (a *API) RegisterRoutes(r gin.IRouter) {
+	r.GET("", a.GetOrganizationReport)
+}
+
+// RegisterClusterRoutes registers the usage routes of a cluster.
+func (a *API) RegisterClusterRoutes(r gin.IRouter) {
+	r.GET("", a.GetClusterReport)
+}
+
+// GetOrganizationReport returns the utilization and cost report of the clusters of an organization.
+func (a *API) GetOrganizationReport(c *gin.Context) {
+	var query pkgUsage.ReportQuery
+	if err := c.BindQuery(&query); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	report, err := a.service.Report(auth.GetCurrentOrganization(c.Request).ID, 0, query)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting usage report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetClusterReport returns the utilization and cost report of a cluster.
+func (a *API) GetClusterReport(c *gin.Context) {
+	commonCluster, ok := a.clusterGetter.GetClusterFromRequest(c)
+	if !ok {
+		return
+	}
+
+	var query pkgUsage.ReportQuery
+	if err := c.BindQuery(&query); err != nil {
+		common.BindingErrorResponse(c, err)
+		return
+	}
+
+	report, err := a.service.Report(commonCluster.GetOrganizationId(), commonCluster.GetID(), query)
+	if err != nil {
+		common.ErrorResponse(c, a.errorHandler, "Error getting usage report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -21,24 +21,31 @@ import (
 
 	"github.com/banzaicloud/pipeline/auth"
 	"github.com/banzaicloud/pipeline/config"
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
 	"github.com/banzaicloud/pipeline/pkg/common"
 	"github.com/gin-gonic/gin"
 )
 
 type userAccessManager interface {
-	GrantOganizationAccessToUser(userID string, orgID uint)
+	GrantOrganizationRoleToUser(userID string, orgID uint, role string)
 	RevokeOrganizationAccessFromUser(userID string, orgID uint)
 }
 
+type userRoleChecker interface {
+	RoleExists(organizationID uint, name string) (bool, error)
+}
+
 // UserAPI implements user functions.
 type UserAPI struct {
 	accessManager userAccessManager
+	roles         userRoleChecker
 }
 
 // NewUserAPI returns a new UserAPI instance.
-func NewUserAPI(accessManager userAccessManager) *UserAPI {
+func NewUserAPI(accessManager userAccessManager, roles userRoleChecker) *UserAPI {
 	return &UserAPI{
 		accessManager: accessManager,
+		roles:         roles,
 	}
 }
 
@@ -98,7 +105,8 @@ func (a *UserAPI) GetUsers(c *gin.Context) {
 	}
 }
 
-// AddUser adds a user to an organization, role=admin|member has to be in the body, otherwise member is the default role.
+// AddUser adds a user to an organization with a built-in or custom role given in the body, developer is the default role.
+// Adding a user who is already a member changes the role of the user.
 func (a *UserAPI) AddUser(c *gin.Context) {
 
 	log.Info("Adding user to organization")
@@ -116,9 +124,7 @@ func (a *UserAPI) AddUser(c *gin.Context) {
 		return
 	}
 
-	role := struct {
-		Role string `json:"role" binding:"required,eq=member|eq=admin"`
-	}{Role: "member"}
+	role := pkgAuth.AddUserRequest{Role: pkgAuth.DefaultRole}
 
 	if c.Request.ContentLength != 0 {
 		err = c.ShouldBindJSON(&role)
@@ -137,6 +143,27 @@ func (a *UserAPI) AddUser(c *gin.Context) {
 	organization := auth.GetCurrentOrganization(c.Request)
 	user := &auth.User{ID: uint(id)}
 
+	exists, err := a.roles.RoleExists(organization.ID, role.Role)
+	if err != nil {
+		message := "failed to check role: " + err.Error()
+		log.Info(message)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, common.ErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: message,
+			Error:   message,
+		})
+		return
+	} else if !exists {
+		message := fmt.Sprintf("role not found: %q", role.Role)
+		log.Info(message)
+		c.JSON(http.StatusBadRequest, common.ErrorResponse{
+			Code:    http.StatusBadRequest,
+			Message: message,
+			Error:   message,
+		})
+		return
+	}
+
 	err = addUserToOrgInDb(organization, user, role.Role)
 
 	if err != nil {
@@ -151,7 +178,7 @@ func (a *UserAPI) AddUser(c *gin.Context) {
 		return
 	}
 
-	a.accessManager.GrantOganizationAccessToUser(user.IDString(), organization.ID)
+	a.accessManager.GrantOrganizationRoleToUser(user.IDString(), organization.ID, role.Role)
 
 	c.Status(http.StatusNoContent)
 }
@@ -211,7 +238,7 @@ func (a *UserAPI) RemoveUser(c *gin.Context) {
 		return
 	}
 
-	user := auth.GetCurrentUser(c.Request)
+	user := &auth.User{ID: uint(id)}
 
 	a.accessManager.RevokeOrganizationAccessFromUser(user.IDString(), organization.ID)
 
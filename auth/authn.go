@@ -24,6 +24,7 @@ import (
 
 	bauth "github.com/banzaicloud/bank-vaults/pkg/auth"
 	"github.com/banzaicloud/pipeline/config"
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
 	"github.com/banzaicloud/pipeline/utils"
 	"github.com/dgrijalva/jwt-go"
@@ -79,6 +80,11 @@ var (
 	signingKeyBase32 string
 	TokenStore       bauth.TokenStore
 
+	// APITokens stores the restrictions and the last usage of the API tokens
+	APITokens *APITokenStore
+
+	localProvider *LocalProvider
+
 	// JwtIssuer ("iss") claim identifies principal that issued the JWT
 	JwtIssuer string
 
@@ -113,6 +119,8 @@ func claimConverter(claims *bauth.ScopedClaims) interface{} {
 		ID:      uint(userID),
 		Login:   claims.Text, // This is needed for Drone virtual user tokens
 		Virtual: claims.Type == DroneHookTokenType,
+
+		APITokenID: claims.Id,
 	}
 }
 
@@ -128,13 +136,14 @@ type accessManager interface {
 	GrantDefaultAccessToUser(userID string)
 	GrantDefaultAccessToVirtualUser(userID string)
 	AddOrganizationPolicies(orgID uint)
-	GrantOganizationAccessToUser(userID string, orgID uint)
+	GrantOrganizationRoleToUser(userID string, orgID uint, role string)
+	GetOrganizationRoleOfUser(userID string, orgID uint) (string, bool)
 	RevokeOrganizationAccessFromUser(userID string, orgID uint)
 	RevokeAllAccessFromUser(userID string)
 }
 
 // Init initializes the auth
-func Init(db *gorm.DB, accessManager accessManager, githubImporter *GithubImporter) {
+func Init(db *gorm.DB, tokenStore bauth.TokenStore, accessManager accessManager, githubImporter *GithubImporter, organizationSyncer *OrganizationSyncer) {
 	JwtIssuer = viper.GetString("auth.jwtissuer")
 	JwtAudience = viper.GetString("auth.jwtaudience")
 	CookieDomain = viper.GetString("auth.cookieDomain")
@@ -193,36 +202,84 @@ func Init(db *gorm.DB, accessManager accessManager, githubImporter *GithubImport
 		ViewPaths:         []string{"views"},
 		SessionStorer:     sessionStorer,
 		UserStorer: BanzaiUserStorer{
-			signingKeyBase32: signingKeyBase32,
-			droneDB:          DroneDB,
-			events:           ebAuthEvents{eb: config.EventBus},
-			accessManager:    accessManager,
-			githubImporter:   githubImporter,
+			signingKeyBase32:   signingKeyBase32,
+			droneDB:            DroneDB,
+			events:             ebAuthEvents{eb: config.EventBus},
+			accessManager:      accessManager,
+			githubImporter:     githubImporter,
+			organizationSyncer: organizationSyncer,
 		},
 		LogoutHandler:     BanzaiLogoutHandler,
 		DeregisterHandler: NewBanzaiDeregisterHandler(accessManager),
 	})
 
-	githubProvider := github.New(&github.Config{
-		// ClientID and ClientSecret is validated inside github.New()
-		ClientID:     viper.GetString("auth.clientid"),
-		ClientSecret: viper.GetString("auth.clientsecret"),
+	if viper.GetString("auth.clientid") != "" {
+		githubProvider := github.New(&github.Config{
+			// ClientID and ClientSecret is validated inside github.New()
+			ClientID:     viper.GetString("auth.clientid"),
+			ClientSecret: viper.GetString("auth.clientsecret"),
+
+			// The same as Drone's scopes
+			Scopes: []string{
+				"repo",
+				"user:email",
+				"read:org",
+			},
+		})
+		githubProvider.AuthorizeHandler = NewGithubAuthorizeHandler(githubProvider)
+		Auth.RegisterProvider(githubProvider)
+	}
+
+	if viper.GetBool(config.AuthOIDCEnabled) {
+		groupMappings, err := ParseGroupMappings(viper.GetStringSlice(config.AuthOIDCGroupMappings))
+		if err != nil {
+			panic(err)
+		}
 
-		// The same as Drone's scopes
-		Scopes: []string{
-			"repo",
-			"user:email",
-			"read:org",
-		},
-	})
-	githubProvider.AuthorizeHandler = NewGithubAuthorizeHandler(githubProvider)
-	Auth.RegisterProvider(githubProvider)
+		Auth.RegisterProvider(NewOIDCProvider(OIDCConfig{
+			Issuer:        viper.GetString(config.AuthOIDCIssuer),
+			ClientID:      viper.GetString(config.AuthOIDCClientID),
+			ClientSecret:  viper.GetString(config.AuthOIDCClientSecret),
+			Scopes:        viper.GetStringSlice(config.AuthOIDCScopes),
+			GroupsClaim:   viper.GetString(config.AuthOIDCGroupsClaim),
+			GroupMappings: groupMappings,
+		}))
+	}
+
+	if viper.GetBool(config.AuthLocalEnabled) {
+		var users []LocalUser
+		err := viper.UnmarshalKey(config.AuthLocalUsers, &users)
+		if err != nil {
+			panic(err)
+		}
 
-	TokenStore = bauth.NewVaultTokenStore("pipeline")
+		localProvider, err = NewLocalProvider(users)
+		if err != nil {
+			panic(err)
+		}
+
+		Auth.RegisterProvider(localProvider)
+	}
+
+	if len(Auth.GetProviders()) == 0 {
+		panic("At least one login provider (GitHub, OIDC or local) must be configured")
+	}
+
+	TokenStore = tokenStore
+	APITokens = NewAPITokenStore(config.DB())
 
 	Handler = bauth.JWTAuth(TokenStore, signingKey, claimConverter, cookieExtractor{sessionStorer})
 }
 
+// BootstrapLocalUsers stores the local accounts from the configuration if the local login provider is enabled.
+func BootstrapLocalUsers(db *gorm.DB) error {
+	if localProvider == nil {
+		return nil
+	}
+
+	return localProvider.BootstrapUsers(db)
+}
+
 func StartTokenStoreGC() {
 	ticker := time.NewTicker(time.Hour * 12)
 	go func() {
@@ -234,6 +291,11 @@ func StartTokenStoreGC() {
 			} else {
 				log.Info("TokenStore garbage collected")
 			}
+
+			err = APITokens.DeleteExpired(time.Now())
+			if err != nil {
+				errorHandler.Handle(errors.Wrap(err, "failed to garbage collect API tokens"))
+			}
 		}
 	}()
 }
@@ -256,6 +318,12 @@ func Install(engine *gin.Engine, generateTokenHandler gin.HandlerFunc) {
 		authGroup.GET("/github/logout", authHandler)
 		authGroup.GET("/github/register", authHandler)
 		authGroup.GET("/github/callback", authHandler)
+		authGroup.GET("/oidc/login", authHandler)
+		authGroup.GET("/oidc/logout", authHandler)
+		authGroup.GET("/oidc/register", authHandler)
+		authGroup.GET("/oidc/callback", authHandler)
+		authGroup.POST("/local/login", authHandler)
+		authGroup.GET("/local/logout", authHandler)
 		authGroup.POST("/tokens", generateTokenHandler)
 		authGroup.GET("/tokens", GetTokens)
 		authGroup.GET("/tokens/:id", GetTokens)
@@ -307,13 +375,24 @@ func (h *tokenHandler) GenerateToken(c *gin.Context) {
 			return
 		}
 		currentUser = GetCurrentUser(c.Request)
+
+		// Restricted tokens could otherwise be exchanged for unrestricted ones
+		if currentUser.APITokenID != "" {
+			currentToken, err := APITokens.Find(currentUser.APITokenID)
+			if err != nil {
+				errorHandler.Handle(errors.Wrap(err, "failed to query current API token"))
+				c.AbortWithStatus(http.StatusInternalServerError)
+				return
+			}
+
+			if currentToken != nil && currentToken.IsRestricted() {
+				c.AbortWithError(http.StatusForbidden, fmt.Errorf("restricted tokens cannot create tokens"))
+				return
+			}
+		}
 	}
 
-	tokenRequest := struct {
-		Name        string     `json:"name,omitempty"`
-		VirtualUser string     `json:"virtualUser,omitempty"`
-		ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
-	}{Name: "generated"}
+	tokenRequest := pkgAuth.CreateTokenRequest{Name: "generated"}
 
 	if c.Request.Method == http.MethodPost && c.Request.ContentLength > 0 {
 		if err := c.ShouldBindJSON(&tokenRequest); err != nil {
@@ -324,6 +403,33 @@ func (h *tokenHandler) GenerateToken(c *gin.Context) {
 
 	isForVirtualUser := tokenRequest.VirtualUser != ""
 
+	apiToken := &APIToken{}
+	if err := apiToken.setRestrictions(&tokenRequest); err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	if isForVirtualUser && apiToken.IsRestricted() {
+		c.AbortWithError(http.StatusBadRequest, fmt.Errorf("virtual user tokens cannot be restricted"))
+		return
+	}
+
+	if tokenRequest.OrganizationID != 0 {
+		organization := Organization{ID: tokenRequest.OrganizationID}
+		err := Auth.GetDB(c.Request).
+			Model(currentUser).
+			Where(&organization).
+			Related(&organization, "Organizations").Error
+		if err != nil {
+			statusCode := GormErrorToStatusCode(err)
+			if statusCode == http.StatusNotFound {
+				statusCode = http.StatusForbidden
+			}
+			c.AbortWithError(statusCode, errors.Wrap(err, "failed to query organization of the token"))
+			return
+		}
+	}
+
 	userID := currentUser.IDString()
 	userLogin := currentUser.Login
 	tokenType := DroneUserTokenType
@@ -333,18 +439,13 @@ func (h *tokenHandler) GenerateToken(c *gin.Context) {
 		tokenType = DroneHookTokenType
 	}
 
-	tokenID, signedToken, err := createAndStoreAPIToken(userID, userLogin, tokenType, tokenRequest.Name, tokenRequest.ExpiresAt)
-
-	if err != nil {
-		err = c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("%s", err))
-		errorHandler.Handle(errors.Wrap(err, "failed to create and store API token"))
-		return
-	}
-
+	var virtualUserOrgID uint
+	var virtualUserRole string
+	var virtualUserHasRole bool
 	if isForVirtualUser {
 		orgName := GetOrgNameFromVirtualUser(tokenRequest.VirtualUser)
 		organization := Organization{Name: orgName}
-		err = Auth.GetDB(c.Request).
+		err := Auth.GetDB(c.Request).
 			Model(currentUser).
 			Where(&organization).
 			Related(&organization, "Organizations").Error
@@ -355,8 +456,38 @@ func (h *tokenHandler) GenerateToken(c *gin.Context) {
 			return
 		}
 
+		// virtual users get the role of the user creating their first token,
+		// later tokens cannot be created by users with a different role unless they are admins
+		creatorRole, ok := h.accessManager.GetOrganizationRoleOfUser(currentUser.IDString(), organization.ID)
+		if !ok {
+			c.AbortWithError(http.StatusForbidden, fmt.Errorf("user has no role in the organization of the virtual user"))
+			return
+		}
+
+		virtualUserOrgID = organization.ID
+		virtualUserRole, virtualUserHasRole = h.accessManager.GetOrganizationRoleOfUser(userID, organization.ID)
+		if !virtualUserHasRole {
+			virtualUserRole = creatorRole
+		} else if virtualUserRole != creatorRole && creatorRole != pkgAuth.RoleAdmin {
+			c.AbortWithError(http.StatusForbidden, fmt.Errorf("virtual user has a different role in the organization"))
+			return
+		}
+	}
+
+	tokenID, signedToken, err := createAndStoreAPIToken(userID, userLogin, tokenType, tokenRequest.Name, tokenRequest.ExpiresAt, apiToken)
+
+	if err != nil {
+		err = c.AbortWithError(http.StatusInternalServerError, fmt.Errorf("%s", err))
+		errorHandler.Handle(errors.Wrap(err, "failed to create and store API token"))
+		return
+	}
+
+	if isForVirtualUser {
 		h.accessManager.GrantDefaultAccessToVirtualUser(userID)
-		h.accessManager.GrantOganizationAccessToUser(userID, organization.ID)
+
+		if !virtualUserHasRole {
+			h.accessManager.GrantOrganizationRoleToUser(userID, virtualUserOrgID, virtualUserRole)
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"id": tokenID, "token": signedToken})
@@ -394,7 +525,7 @@ func createAPIToken(userID string, userLogin string, tokenType bauth.TokenType,
 	return tokenID, signedToken, nil
 }
 
-func createAndStoreAPIToken(userID string, userLogin string, tokenType bauth.TokenType, tokenName string, expiresAt *time.Time) (string, string, error) {
+func createAndStoreAPIToken(userID string, userLogin string, tokenType bauth.TokenType, tokenName string, expiresAt *time.Time, apiToken *APIToken) (string, string, error) {
 	tokenID, signedToken, err := createAPIToken(userID, userLogin, tokenType, expiresAt)
 	if err != nil {
 		return "", "", err
@@ -407,31 +538,46 @@ func createAndStoreAPIToken(userID string, userLogin string, tokenType bauth.Tok
 		return "", "", errors.Wrap(err, "failed to store user token")
 	}
 
+	apiToken.ID = tokenID
+	apiToken.UserID = userID
+	apiToken.ExpiresAt = expiresAt
+	err = APITokens.Save(apiToken)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to store user token restrictions")
+	}
+
 	return tokenID, signedToken, nil
 }
 
-// GetTokens returns the calling user's access tokens
+// GetTokens returns the calling user's access tokens with their scopes and last usage
 func GetTokens(c *gin.Context) {
 	currentUser := GetCurrentUser(c.Request)
 	tokenID := c.Param("id")
 
+	apiTokens, err := APITokens.FindByUser(currentUser.IDString())
+	if err != nil {
+		errorHandler.Handle(err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err)
+		return
+	}
+
 	if tokenID == "" {
 		tokens, err := TokenStore.List(currentUser.IDString())
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, err)
 		} else {
+			response := make([]pkgAuth.TokenResponse, 0, len(tokens))
 			for _, token := range tokens {
-				token.Value = ""
+				response = append(response, tokenResponse(token, apiTokens[token.ID]))
 			}
-			c.JSON(http.StatusOK, tokens)
+			c.JSON(http.StatusOK, response)
 		}
 	} else {
 		token, err := TokenStore.Lookup(currentUser.IDString(), tokenID)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, err)
 		} else if token != nil {
-			token.Value = ""
-			c.JSON(http.StatusOK, token)
+			c.JSON(http.StatusOK, tokenResponse(token, apiTokens[token.ID]))
 		} else {
 			c.AbortWithStatusJSON(http.StatusNotFound, pkgCommon.ErrorResponse{
 				Code:    http.StatusNotFound,
@@ -451,6 +597,10 @@ func DeleteToken(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, fmt.Errorf("Missing token id"))
 	} else {
 		err := TokenStore.Revoke(currentUser.IDString(), tokenID)
+		if err == nil {
+			err = APITokens.Delete(currentUser.IDString(), tokenID)
+		}
+
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusInternalServerError, err)
 		} else {
@@ -481,7 +631,7 @@ func (sessionStorer *BanzaiSessionStorer) Update(w http.ResponseWriter, req *htt
 	// These tokens are GCd after they expire
 	expiresAt := time.Now().Add(SessionCookieMaxAge * time.Second)
 
-	_, cookieToken, err := createAndStoreAPIToken(claims.UserID, currentUser.Login, DroneUserTokenType, SessionCookieName, &expiresAt)
+	_, cookieToken, err := createAndStoreAPIToken(claims.UserID, currentUser.Login, DroneUserTokenType, SessionCookieName, &expiresAt, &APIToken{})
 	if err != nil {
 		errorHandler.Handle(errors.Wrap(err, "failed to create user session cookie"))
 		return err
@@ -595,6 +745,12 @@ func (h *banzaiDeregisterHandler) handler(context *auth.Context) {
 		}
 	}
 
+	if err := APITokens.DeleteByUser(user.IDString()); err != nil {
+		errorHandler.Handle(errors.Wrap(err, "failed remove user's token restrictions during user deletetion"))
+		http.Error(context.Writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	// Delete Casbin roles
 	h.accessManager.RevokeAllAccessFromUser(user.IDString())
 
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"reflect"
 
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
 	"github.com/goph/emperror"
 	"github.com/pkg/errors"
 
@@ -234,6 +235,15 @@ type githubOrganization struct {
 	role string
 }
 
+// githubMembershipRole maps the role of a GitHub organization membership to a built-in organization role.
+func githubMembershipRole(role string) string {
+	if role == "admin" {
+		return pkgAuth.RoleAdmin
+	}
+
+	return pkgAuth.DefaultRole
+}
+
 func getGithubOrganizations(token string) ([]githubOrganization, error) {
 	httpClient := oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}))
 	githubClient := github.NewClient(httpClient)
@@ -0,0 +1,229 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/qor/auth"
+	"github.com/qor/auth/auth_identity"
+	"github.com/qor/auth/claims"
+	"github.com/qor/qor/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LocalProviderName is the name of the username/password login provider
+const LocalProviderName = "local"
+
+// LocalUser is a local account defined in the configuration.
+type LocalUser struct {
+	Login string `mapstructure:"login"`
+
+	// Password is the bcrypt hash of the password
+	Password string `mapstructure:"password"`
+
+	// Organizations lists the roles of the user in the organization:role format
+	Organizations []string `mapstructure:"organizations"`
+}
+
+// LocalProvider is a qor/auth provider logging in users with local accounts.
+// Accounts cannot register themselves, they are bootstrapped from the configuration.
+type LocalProvider struct {
+	users map[string]LocalUser
+}
+
+// NewLocalProvider validates the local accounts and returns a new LocalProvider instance.
+func NewLocalProvider(users []LocalUser) (*LocalProvider, error) {
+	provider := &LocalProvider{
+		users: make(map[string]LocalUser, len(users)),
+	}
+
+	for _, user := range users {
+		if user.Login == "" {
+			return nil, errors.New("local user login must not be empty")
+		}
+
+		if _, err := bcrypt.Cost([]byte(user.Password)); err != nil {
+			return nil, emperror.With(errors.Wrap(err, "local user password must be a bcrypt hash"), "login", user.Login)
+		}
+
+		if _, err := user.mappedUserInfo(); err != nil {
+			return nil, emperror.With(err, "login", user.Login)
+		}
+
+		provider.users[user.Login] = user
+	}
+
+	return provider, nil
+}
+
+func (u LocalUser) mappedUserInfo() (*MappedUserInfo, error) {
+	info := &MappedUserInfo{
+		Login:         u.Login,
+		Organizations: make(map[string]string, len(u.Organizations)),
+	}
+
+	for _, organization := range u.Organizations {
+		mapping, err := ParseOrganizationMapping(organization)
+		if err != nil {
+			return nil, err
+		}
+
+		info.Organizations[mapping.Organization] = mapping.Role
+		info.ManagedOrganizations = append(info.ManagedOrganizations, mapping.Organization)
+	}
+
+	return info, nil
+}
+
+// BootstrapUsers stores the password hashes of the local accounts.
+// Users are created in Pipeline at their first login.
+func (p *LocalProvider) BootstrapUsers(db *gorm.DB) error {
+	for _, user := range p.users {
+		authIdentity := AuthIdentity{}
+		authIdentity.Provider = p.GetName()
+		authIdentity.UID = user.Login
+
+		err := db.Where(authIdentity).Assign(AuthIdentity{
+			Basic: auth_identity.Basic{EncryptedPassword: user.Password},
+		}).FirstOrCreate(&authIdentity).Error
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "failed to bootstrap local user"), "login", user.Login)
+		}
+	}
+
+	return nil
+}
+
+// GetName returns the name of the provider.
+func (p *LocalProvider) GetName() string {
+	return LocalProviderName
+}
+
+// ConfigAuth is called when the provider is registered.
+func (p *LocalProvider) ConfigAuth(*auth.Auth) {}
+
+// Login checks the credentials posted from the login form or as JSON.
+func (p *LocalProvider) Login(context *auth.Context) {
+	context.Auth.LoginHandler(context, p.authorize)
+}
+
+// Logout logs the user out.
+func (p *LocalProvider) Logout(context *auth.Context) {
+	context.Auth.LogoutHandler(context)
+}
+
+// Register is not supported, local accounts are bootstrapped from the configuration.
+func (p *LocalProvider) Register(context *auth.Context) {
+	http.Error(context.Writer, "local accounts cannot be registered", http.StatusForbidden)
+}
+
+// Callback is not used by the provider.
+func (p *LocalProvider) Callback(*auth.Context) {}
+
+// ServeHTTP handles the other requests of the provider.
+func (p *LocalProvider) ServeHTTP(*auth.Context) {}
+
+type localCredentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+func parseLocalCredentials(req *http.Request) (localCredentials, error) {
+	var credentials localCredentials
+
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		err := json.NewDecoder(req.Body).Decode(&credentials)
+		return credentials, errors.Wrap(err, "failed to decode credentials")
+	}
+
+	if err := req.ParseForm(); err != nil {
+		return credentials, errors.Wrap(err, "failed to parse login form")
+	}
+
+	credentials.Login = strings.TrimSpace(req.Form.Get("login"))
+	credentials.Password = req.Form.Get("password")
+
+	return credentials, nil
+}
+
+func (p *LocalProvider) authorize(context *auth.Context) (*claims.Claims, error) {
+	var (
+		schema       auth.Schema
+		authInfo     auth_identity.Basic
+		authIdentity = reflect.New(utils.ModelType(context.Auth.Config.AuthIdentityModel)).Interface()
+		req          = context.Request
+		db           = context.Auth.GetDB(req)
+	)
+
+	credentials, err := parseLocalCredentials(req)
+	if err != nil {
+		log.Infoln("invalid login request", err.Error())
+		return nil, auth.ErrUnauthorized
+	}
+
+	user, ok := p.users[credentials.Login]
+	if !ok {
+		return nil, auth.ErrUnauthorized
+	}
+
+	authInfo.Provider = p.GetName()
+	authInfo.UID = user.Login
+
+	if err := db.Model(authIdentity).Where(authInfo).Scan(&authInfo).Error; err != nil {
+		log.Errorln("failed to fetch local user", err.Error())
+		return nil, auth.ErrUnauthorized
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(authInfo.EncryptedPassword), []byte(credentials.Password))
+	if err != nil {
+		return nil, auth.ErrUnauthorized
+	}
+
+	// The error is checked by NewLocalProvider
+	info, _ := user.mappedUserInfo()
+	schema.RawInfo = info
+
+	// If the user is already registered, synchronize the organizations of the user
+	if authInfo.UserID != "" {
+		context.Claims = authInfo.ToClaims()
+		return authInfo.ToClaims(), context.Auth.UserStorer.Update(&schema, context)
+	}
+
+	schema.Provider = p.GetName()
+	schema.UID = user.Login
+
+	_, userID, err := context.Auth.UserStorer.Save(&schema, context)
+	if err != nil {
+		log.Errorln("failed to store user in db", err.Error())
+		return nil, err
+	}
+
+	authInfo.UserID = userID
+
+	err = db.Model(authIdentity).Where(auth_identity.Basic{Provider: authInfo.Provider, UID: authInfo.UID}).Update("user_id", userID).Error
+	if err != nil {
+		log.Errorln("failed to update auth identity of user in db", err.Error())
+		return nil, err
+	}
+
+	return authInfo.ToClaims(), nil
+}
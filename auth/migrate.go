@@ -30,6 +30,8 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		&User{},
 		&UserOrganization{},
 		&Organization{},
+		&APIToken{},
+		&AuthToken{},
 	}
 
 	var tableNames string
@@ -0,0 +1,381 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pkg/errors"
+	"github.com/qor/auth"
+	"github.com/qor/auth/auth_identity"
+	"github.com/qor/auth/claims"
+	"github.com/qor/qor/utils"
+	"golang.org/x/oauth2"
+)
+
+// OIDCProviderName is the name of the OpenID Connect login provider
+const OIDCProviderName = "oidc"
+
+// OIDCConfig holds the configuration of the OpenID Connect login provider.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	// GroupsClaim is the name of the ID token claim listing the groups of the user
+	GroupsClaim string
+
+	// GroupMappings maps groups to roles in organizations
+	GroupMappings map[string][]OrganizationMapping
+}
+
+// ParseGroupMappings parses group mappings in the group=organization:role format.
+func ParseGroupMappings(mappings []string) (map[string][]OrganizationMapping, error) {
+	groupMappings := make(map[string][]OrganizationMapping, len(mappings))
+
+	for _, mapping := range mappings {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("invalid group mapping %q, expected group=organization:role", mapping)
+		}
+
+		organizationMapping, err := ParseOrganizationMapping(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		groupMappings[parts[0]] = append(groupMappings[parts[0]], organizationMapping)
+	}
+
+	return groupMappings, nil
+}
+
+// mapGroups returns the organizations (and the roles in them) of a user with the given groups.
+// When multiple groups grant a role in the same organization, the first mapped group wins.
+func (c OIDCConfig) mapGroups(groups []string) *MappedUserInfo {
+	info := &MappedUserInfo{
+		Organizations: make(map[string]string),
+	}
+
+	managed := make(map[string]bool)
+	for _, mappings := range c.GroupMappings {
+		for _, mapping := range mappings {
+			if !managed[mapping.Organization] {
+				managed[mapping.Organization] = true
+				info.ManagedOrganizations = append(info.ManagedOrganizations, mapping.Organization)
+			}
+		}
+	}
+
+	for _, group := range groups {
+		for _, mapping := range c.GroupMappings[group] {
+			if _, ok := info.Organizations[mapping.Organization]; !ok {
+				info.Organizations[mapping.Organization] = mapping.Role
+			}
+		}
+	}
+
+	return info
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// OIDCProvider is a qor/auth provider logging in users with the authorization code flow of an OpenID Connect IdP.
+type OIDCProvider struct {
+	config OIDCConfig
+
+	discovery     *oidcDiscovery
+	discoveryLock sync.Mutex
+}
+
+// NewOIDCProvider returns a new OIDCProvider instance.
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email", "groups"}
+	}
+
+	return &OIDCProvider{config: config}
+}
+
+// GetName returns the name of the provider.
+func (p *OIDCProvider) GetName() string {
+	return OIDCProviderName
+}
+
+// ConfigAuth is called when the provider is registered.
+func (p *OIDCProvider) ConfigAuth(*auth.Auth) {}
+
+// Login redirects the user to the IdP.
+func (p *OIDCProvider) Login(context *auth.Context) {
+	oauthConfig, err := p.oauthConfig(context)
+	if err != nil {
+		log.Errorln("failed to discover OIDC provider", err.Error())
+		http.Error(context.Writer, "OIDC provider is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	state := claims.Claims{}
+	state.Subject = "state"
+	state.ExpiresAt = time.Now().Add(10 * time.Minute).Unix()
+
+	url := oauthConfig.AuthCodeURL(context.Auth.SessionStorer.SignedToken(&state))
+	http.Redirect(context.Writer, context.Request, url, http.StatusFound)
+}
+
+// Logout logs the user out of Pipeline (but not out of the IdP).
+func (p *OIDCProvider) Logout(context *auth.Context) {
+	context.Auth.LogoutHandler(context)
+}
+
+// Register is the same as login, users are registered at their first login.
+func (p *OIDCProvider) Register(context *auth.Context) {
+	p.Login(context)
+}
+
+// Callback handles the redirect from the IdP.
+func (p *OIDCProvider) Callback(context *auth.Context) {
+	context.Auth.LoginHandler(context, p.authorize)
+}
+
+// ServeHTTP handles the other requests of the provider.
+func (p *OIDCProvider) ServeHTTP(*auth.Context) {}
+
+func (p *OIDCProvider) getDiscovery() (*oidcDiscovery, error) {
+	p.discoveryLock.Lock()
+	defer p.discoveryLock.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	resp, err := http.Get(strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch OIDC discovery document")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch OIDC discovery document: %s", resp.Status)
+	}
+
+	var discovery oidcDiscovery
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return nil, errors.Wrap(err, "failed to decode OIDC discovery document")
+	}
+
+	if discovery.Issuer != p.config.Issuer {
+		return nil, errors.Errorf("OIDC issuer mismatch: expected %q, got %q", p.config.Issuer, discovery.Issuer)
+	}
+
+	p.discovery = &discovery
+
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) oauthConfig(context *auth.Context) (*oauth2.Config, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+		RedirectURL: context.Auth.AuthURL(OIDCProviderName + "/callback"),
+		Scopes:      p.config.Scopes,
+	}, nil
+}
+
+func (p *OIDCProvider) authorize(context *auth.Context) (*claims.Claims, error) {
+	var (
+		schema       auth.Schema
+		authInfo     auth_identity.Basic
+		authIdentity = reflect.New(utils.ModelType(context.Auth.Config.AuthIdentityModel)).Interface()
+		req          = context.Request
+		db           = context.Auth.GetDB(req)
+	)
+
+	state, err := context.Auth.SessionStorer.ValidateClaims(req.URL.Query().Get("state"))
+	if err != nil {
+		log.Errorln("failed to validate user claims", err.Error())
+		return nil, err
+	}
+
+	if state.Valid() != nil || state.Subject != "state" {
+		log.Infoln("invalid user claims", auth.ErrUnauthorized.Error())
+		return nil, auth.ErrUnauthorized
+	}
+
+	oauthConfig, err := p.oauthConfig(context)
+	if err != nil {
+		log.Errorln("failed to discover OIDC provider", err.Error())
+		return nil, err
+	}
+
+	token, err := oauthConfig.Exchange(oauth2.NoContext, req.URL.Query().Get("code"))
+	if err != nil {
+		log.Errorln("oauth exchange failed", err.Error())
+		return nil, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("id_token is missing from the token response")
+	}
+
+	idToken, err := p.parseIDToken(rawIDToken, time.Now())
+	if err != nil {
+		log.Errorln("invalid ID token", err.Error())
+		return nil, auth.ErrUnauthorized
+	}
+
+	authInfo.Provider = p.GetName()
+	authInfo.UID = idToken.subject
+
+	info := p.config.mapGroups(idToken.groups)
+	info.Login = idToken.login
+	schema.RawInfo = info
+
+	// If the user is already registered, synchronize the organizations of the user
+	if tx := db.Model(authIdentity).Where(authInfo).Scan(&authInfo); tx.Error == nil {
+		context.Claims = authInfo.ToClaims()
+		return authInfo.ToClaims(), context.Auth.UserStorer.Update(&schema, context)
+	} else if !tx.RecordNotFound() {
+		log.Errorln("failed to check if user is already registered", tx.Error.Error())
+		return nil, tx.Error
+	}
+
+	schema.Provider = p.GetName()
+	schema.UID = idToken.subject
+	schema.Name = idToken.name
+	schema.Email = idToken.email
+	schema.Image = idToken.picture
+
+	if _, userID, err := context.Auth.UserStorer.Save(&schema, context); err == nil {
+		if userID != "" {
+			authInfo.UserID = userID
+		}
+	} else {
+		log.Errorln("failed to store user in db", err.Error())
+		return nil, err
+	}
+
+	if err = db.Where(authInfo).FirstOrCreate(authIdentity).Error; err == nil {
+		return authInfo.ToClaims(), nil
+	}
+
+	log.Errorln("failed to create auth identity for user in db", err.Error())
+	return nil, err
+}
+
+type oidcIDToken struct {
+	subject string
+	login   string
+	name    string
+	email   string
+	picture string
+	groups  []string
+}
+
+// parseIDToken parses the claims of an ID token and validates its issuer, audience and expiry.
+// The signature is not verified: the token is received directly from the token endpoint of the issuer over TLS,
+// which the OpenID Connect specification accepts in place of checking the signature.
+func (p *OIDCProvider) parseIDToken(rawIDToken string, now time.Time) (*oidcIDToken, error) {
+	tokenClaims := jwt.MapClaims{}
+
+	_, _, err := new(jwt.Parser).ParseUnverified(rawIDToken, tokenClaims)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse ID token")
+	}
+
+	if iss, _ := tokenClaims["iss"].(string); iss != p.config.Issuer {
+		return nil, errors.Errorf("unexpected ID token issuer: %q", iss)
+	}
+
+	if !hasAudience(tokenClaims["aud"], p.config.ClientID) {
+		return nil, errors.New("ID token is not issued for this client")
+	}
+
+	if !tokenClaims.VerifyExpiresAt(now.Unix(), true) {
+		return nil, errors.New("ID token is expired")
+	}
+
+	idToken := &oidcIDToken{}
+	idToken.subject, _ = tokenClaims["sub"].(string)
+	idToken.name, _ = tokenClaims["name"].(string)
+	idToken.email, _ = tokenClaims["email"].(string)
+	idToken.picture, _ = tokenClaims["picture"].(string)
+
+	if idToken.subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	idToken.login, _ = tokenClaims["preferred_username"].(string)
+	if idToken.login == "" && idToken.email != "" {
+		idToken.login = strings.Split(idToken.email, "@")[0]
+	}
+	if idToken.login == "" {
+		idToken.login = idToken.subject
+	}
+
+	switch groups := tokenClaims[p.config.GroupsClaim].(type) {
+	case []interface{}:
+		for _, group := range groups {
+			idToken.groups = append(idToken.groups, fmt.Sprint(group))
+		}
+
+	case string:
+		idToken.groups = []string{groups}
+	}
+
+	return idToken, nil
+}
+
+// hasAudience checks the aud claim, which is either a string or an array of strings.
+func hasAudience(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
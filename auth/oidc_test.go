@@ -0,0 +1,124 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestParseGroupMappings(t *testing.T) {
+	mappings, err := ParseGroupMappings([]string{
+		"admins=pipeline:admin",
+		"admins=sandbox:admin",
+		"devs=pipeline:developer",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expected := map[string][]OrganizationMapping{
+		"admins": {{Organization: "pipeline", Role: "admin"}, {Organization: "sandbox", Role: "admin"}},
+		"devs":   {{Organization: "pipeline", Role: "developer"}},
+	}
+
+	if !reflect.DeepEqual(expected, mappings) {
+		t.Errorf("expected %v, got %v", expected, mappings)
+	}
+
+	for _, mapping := range []string{"admins", "=pipeline:admin", "admins=pipeline", "admins=:admin"} {
+		if _, err := ParseGroupMappings([]string{mapping}); err == nil {
+			t.Errorf("expected error for mapping %q", mapping)
+		}
+	}
+}
+
+func TestOIDCConfig_mapGroups(t *testing.T) {
+	config := OIDCConfig{
+		GroupMappings: map[string][]OrganizationMapping{
+			"admins": {{Organization: "pipeline", Role: "admin"}},
+			"devs":   {{Organization: "pipeline", Role: "developer"}, {Organization: "sandbox", Role: "developer"}},
+		},
+	}
+
+	info := config.mapGroups([]string{"devs", "admins", "unknown"})
+
+	expected := map[string]string{"pipeline": "developer", "sandbox": "developer"}
+	if !reflect.DeepEqual(expected, info.Organizations) {
+		t.Errorf("expected %v, got %v", expected, info.Organizations)
+	}
+
+	if len(info.ManagedOrganizations) != 2 {
+		t.Errorf("expected 2 managed organizations, got %v", info.ManagedOrganizations)
+	}
+}
+
+func TestOIDCProvider_parseIDToken(t *testing.T) {
+	provider := NewOIDCProvider(OIDCConfig{
+		Issuer:   "https://dex.example.com",
+		ClientID: "pipeline",
+	})
+
+	now := time.Now()
+
+	newToken := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return token
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		idToken, err := provider.parseIDToken(newToken(jwt.MapClaims{
+			"iss":    "https://dex.example.com",
+			"aud":    []interface{}{"other", "pipeline"},
+			"exp":    now.Add(time.Hour).Unix(),
+			"sub":    "CgNqb2U",
+			"email":  "joe@example.com",
+			"groups": []interface{}{"admins", "devs"},
+		}), now)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err.Error())
+		}
+
+		if idToken.login != "joe" {
+			t.Errorf("expected login joe, got %q", idToken.login)
+		}
+
+		if !reflect.DeepEqual([]string{"admins", "devs"}, idToken.groups) {
+			t.Errorf("unexpected groups: %v", idToken.groups)
+		}
+	})
+
+	tests := map[string]jwt.MapClaims{
+		"issuer":   {"iss": "https://evil.example.com", "aud": "pipeline", "exp": now.Add(time.Hour).Unix(), "sub": "joe"},
+		"audience": {"iss": "https://dex.example.com", "aud": "other", "exp": now.Add(time.Hour).Unix(), "sub": "joe"},
+		"expired":  {"iss": "https://dex.example.com", "aud": "pipeline", "exp": now.Add(-time.Hour).Unix(), "sub": "joe"},
+		"subject":  {"iss": "https://dex.example.com", "aud": "pipeline", "exp": now.Add(time.Hour).Unix()},
+	}
+
+	for name, claims := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := provider.parseIDToken(newToken(claims), now); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
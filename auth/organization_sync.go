@@ -0,0 +1,147 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// MappedUserInfo is the raw info of users whose organizations are mapped from the configuration (instead of GitHub).
+type MappedUserInfo struct {
+	Login string
+
+	// Organizations contains the role of the user by organization name
+	Organizations map[string]string
+
+	// ManagedOrganizations contains every organization the mapping can grant access to,
+	// the user is removed from the ones not present in Organizations
+	ManagedOrganizations []string
+}
+
+// OrganizationMapping maps an external group or account to a role in an organization.
+type OrganizationMapping struct {
+	Organization string
+	Role         string
+}
+
+// ParseOrganizationMapping parses an organization mapping in the organization:role format.
+func ParseOrganizationMapping(mapping string) (OrganizationMapping, error) {
+	parts := strings.Split(mapping, ":")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return OrganizationMapping{}, errors.Errorf("invalid organization mapping %q, expected organization:role", mapping)
+	}
+
+	return OrganizationMapping{Organization: parts[0], Role: parts[1]}, nil
+}
+
+// OrganizationSyncer synchronizes the organization memberships of users with mapped organizations.
+type OrganizationSyncer struct {
+	db            *gorm.DB
+	accessManager accessManager
+	events        authEvents
+}
+
+// NewOrganizationSyncer returns a new OrganizationSyncer instance.
+func NewOrganizationSyncer(
+	db *gorm.DB,
+	accessManager accessManager,
+	events eventBus,
+) *OrganizationSyncer {
+	return &OrganizationSyncer{
+		db:            db,
+		accessManager: accessManager,
+		events:        ebAuthEvents{eb: events},
+	}
+}
+
+// SyncOrganizations creates the mapped organizations of a user and grants the mapped roles,
+// then removes the user from the managed organizations that are not mapped anymore.
+func (s *OrganizationSyncer) SyncOrganizations(user *User, info *MappedUserInfo) error {
+	var organizations []Organization
+	err := s.db.Model(user).Related(&organizations, "Organizations").Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "failed to fetch organizations of user"), "user", user.ID)
+	}
+
+	memberships := make(map[string]uint, len(organizations))
+	for _, organization := range organizations {
+		memberships[organization.Name] = organization.ID
+	}
+
+	for name, role := range info.Organizations {
+		organization := Organization{Name: name}
+
+		created, err := s.ensureOrganization(&organization)
+		if err != nil {
+			return err
+		}
+
+		err = s.db.Model(&organization).Association("Users").Append(user).Error
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "failed to associate user with organization"), "organization", name)
+		}
+
+		userRoleInOrg := UserOrganization{UserID: user.ID, OrganizationID: organization.ID}
+		err = s.db.Model(&UserOrganization{}).Where(userRoleInOrg).Update("role", role).Error
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "failed to save user role in organization"), "organization", name)
+		}
+
+		s.accessManager.GrantOrganizationRoleToUser(user.IDString(), organization.ID, role)
+
+		if created {
+			s.events.OrganizationRegistered(organization.ID)
+		}
+	}
+
+	for _, name := range info.ManagedOrganizations {
+		orgID, member := memberships[name]
+		if _, mapped := info.Organizations[name]; mapped || !member {
+			continue
+		}
+
+		err := s.db.Model(&Organization{ID: orgID}).Association("Users").Delete(user).Error
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "failed to remove user from organization"), "organization", name)
+		}
+
+		s.accessManager.RevokeOrganizationAccessFromUser(user.IDString(), orgID)
+	}
+
+	return nil
+}
+
+func (s *OrganizationSyncer) ensureOrganization(organization *Organization) (bool, error) {
+	err := s.db.Where(organization).First(organization).Error
+	if err == nil {
+		return false, nil
+	} else if !gorm.IsRecordNotFoundError(err) {
+		return false, errors.Wrap(err, "failed to check if organization exists")
+	}
+
+	err = s.db.Create(organization).Error
+	if err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("failed to create organization %q", organization.Name))
+	}
+
+	s.accessManager.AddOrganizationPolicies(organization.ID)
+
+	return true, nil
+}
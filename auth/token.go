@@ -0,0 +1,222 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	bauth "github.com/banzaicloud/bank-vaults/pkg/auth"
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// apiTokenUsageResolution limits how often the last usage of a token is written to the database.
+const apiTokenUsageResolution = time.Minute
+
+// APIToken stores the restrictions and the last usage of an API token, the token itself is kept in the TokenStore.
+type APIToken struct {
+	ID             string `gorm:"primary_key;size:36"`
+	UserID         string `gorm:"index"`
+	OrganizationID uint
+	ClusterIDs     string `sql:"type:text;"`
+	Scopes         string `sql:"type:text;"`
+	ExpiresAt      *time.Time
+	LastUsedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+// TableName changes the default table name.
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// IsRestricted returns true if the token cannot be used for everything its user has access to.
+func (t *APIToken) IsRestricted() bool {
+	return t.OrganizationID != 0 || t.ClusterIDs != "" || t.Scopes != ""
+}
+
+// GetScopes returns the scopes of the token, every operation is allowed if empty.
+func (t *APIToken) GetScopes() []string {
+	if t.Scopes == "" {
+		return nil
+	}
+
+	return strings.Split(t.Scopes, ",")
+}
+
+// GetClusterIDs returns the clusters the token is restricted to, every cluster is allowed if empty.
+func (t *APIToken) GetClusterIDs() []uint {
+	if t.ClusterIDs == "" {
+		return nil
+	}
+
+	var ids []uint
+	for _, id := range strings.Split(t.ClusterIDs, ",") {
+		clusterID, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			continue
+		}
+
+		ids = append(ids, uint(clusterID))
+	}
+
+	return ids
+}
+
+// setRestrictions applies the restrictions of a token request.
+func (t *APIToken) setRestrictions(req *pkgAuth.CreateTokenRequest) error {
+	if len(req.ClusterIDs) > 0 && req.OrganizationID == 0 {
+		return errors.New("clusterIds can only be used together with organizationId")
+	}
+
+	validScopes := make(map[string]bool, len(pkgAuth.TokenScopes))
+	for _, scope := range pkgAuth.TokenScopes {
+		validScopes[scope] = true
+	}
+
+	for _, scope := range req.Scopes {
+		if !validScopes[scope] {
+			return errors.Errorf("invalid scope: %q", scope)
+		}
+	}
+
+	clusterIDs := make([]string, 0, len(req.ClusterIDs))
+	for _, id := range req.ClusterIDs {
+		clusterIDs = append(clusterIDs, fmt.Sprint(id))
+	}
+
+	t.OrganizationID = req.OrganizationID
+	t.ClusterIDs = strings.Join(clusterIDs, ",")
+	t.Scopes = strings.Join(req.Scopes, ",")
+
+	return nil
+}
+
+// tokenResponse merges an API token with its restrictions and usage.
+func tokenResponse(token *bauth.Token, apiToken *APIToken) pkgAuth.TokenResponse {
+	response := pkgAuth.TokenResponse{
+		ID:        token.ID,
+		Name:      token.Name,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	}
+
+	if apiToken != nil {
+		response.OrganizationID = apiToken.OrganizationID
+		response.ClusterIDs = apiToken.GetClusterIDs()
+		response.Scopes = apiToken.GetScopes()
+		response.LastUsedAt = apiToken.LastUsedAt
+	}
+
+	return response
+}
+
+// APITokenStore stores the restrictions and the last usage of API tokens.
+type APITokenStore struct {
+	db *gorm.DB
+}
+
+// NewAPITokenStore returns a new APITokenStore instance.
+func NewAPITokenStore(db *gorm.DB) *APITokenStore {
+	return &APITokenStore{db: db}
+}
+
+// Find returns the restrictions of a token or nil if the token has none stored.
+func (s *APITokenStore) Find(tokenID string) (*APIToken, error) {
+	var token APIToken
+
+	err := s.db.Where(&APIToken{ID: tokenID}).First(&token).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch API token"), "token", tokenID)
+	}
+
+	return &token, nil
+}
+
+// FindByUser returns the restrictions of the tokens of a user indexed by token ID.
+func (s *APITokenStore) FindByUser(userID string) (map[string]*APIToken, error) {
+	var tokens []*APIToken
+
+	err := s.db.Where(&APIToken{UserID: userID}).Find(&tokens).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch API tokens"), "user", userID)
+	}
+
+	tokensByID := make(map[string]*APIToken, len(tokens))
+	for _, token := range tokens {
+		tokensByID[token.ID] = token
+	}
+
+	return tokensByID, nil
+}
+
+// Save stores the restrictions of a token.
+func (s *APITokenStore) Save(token *APIToken) error {
+	err := s.db.Save(token).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not save API token"), "token", token.ID)
+	}
+
+	return nil
+}
+
+// Touch records the usage of a token, at most once in a minute.
+func (s *APITokenStore) Touch(tokenID string, now time.Time) error {
+	err := s.db.Model(&APIToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", tokenID, now.Add(-apiTokenUsageResolution)).
+		UpdateColumn("last_used_at", now).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not update API token usage"), "token", tokenID)
+	}
+
+	return nil
+}
+
+// Delete deletes the restrictions of a token of a user.
+func (s *APITokenStore) Delete(userID string, tokenID string) error {
+	err := s.db.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&APIToken{}).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete API token"), "token", tokenID)
+	}
+
+	return nil
+}
+
+// DeleteByUser deletes the restrictions of every token of a user.
+func (s *APITokenStore) DeleteByUser(userID string) error {
+	err := s.db.Where("user_id = ?", userID).Delete(&APIToken{}).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete API tokens"), "user", userID)
+	}
+
+	return nil
+}
+
+// DeleteExpired deletes the restrictions of expired tokens.
+func (s *APITokenStore) DeleteExpired(now time.Time) error {
+	err := s.db.Where("expires_at < ?", now).Delete(&APIToken{}).Error
+	if err != nil {
+		return errors.Wrap(err, "could not delete expired API tokens")
+	}
+
+	return nil
+}
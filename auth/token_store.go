@@ -0,0 +1,223 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/base64"
+	"time"
+
+	bauth "github.com/banzaicloud/bank-vaults/pkg/auth"
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// tokenStoreRole is the Vault role used by the Vault token store.
+const tokenStoreRole = "pipeline"
+
+// AuthToken is an access token of a user kept in the database by the database token store.
+// The token value (eg. a GitHub access token) is encrypted with envelope encryption.
+type AuthToken struct {
+	UserID    string `gorm:"primary_key;size:36"`
+	ID        string `gorm:"primary_key;size:36"`
+	Name      string
+	DataKey   string `sql:"type:text;"`
+	Value     string `sql:"type:text;"`
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
+
+// TableName changes the default table name.
+func (AuthToken) TableName() string {
+	return "auth_tokens"
+}
+
+// NewTokenStore returns the token store matching the configured secret store backend:
+// tokens are kept in Vault next to the secrets or encrypted in the Pipeline database.
+func NewTokenStore(db *gorm.DB) (bauth.TokenStore, error) {
+	switch backend := viper.GetString(config.SecretStoreBackend); backend {
+	case secret.BackendVault:
+		return bauth.NewVaultTokenStore(tokenStoreRole), nil
+
+	case secret.BackendDatabase:
+		masterKey, err := secret.MasterKey()
+		if err != nil {
+			return nil, err
+		}
+
+		envelope, err := secret.NewEnvelope(masterKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid secret store master key")
+		}
+
+		return NewDatabaseTokenStore(db, envelope), nil
+
+	default:
+		return nil, errors.Errorf("unknown secret store backend: %s", backend)
+	}
+}
+
+// DatabaseTokenStore stores the access tokens of users in the Pipeline database.
+type DatabaseTokenStore struct {
+	db       *gorm.DB
+	envelope *secret.Envelope
+}
+
+// NewDatabaseTokenStore returns a new DatabaseTokenStore instance.
+func NewDatabaseTokenStore(db *gorm.DB, envelope *secret.Envelope) *DatabaseTokenStore {
+	return &DatabaseTokenStore{db: db, envelope: envelope}
+}
+
+// Store saves a token of a user, replacing the token with the same ID.
+func (s *DatabaseTokenStore) Store(userID string, token *bauth.Token) error {
+	model := AuthToken{
+		UserID:    userID,
+		ID:        token.ID,
+		Name:      token.Name,
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+
+	if token.CreatedAt != nil {
+		model.CreatedAt = *token.CreatedAt
+	}
+
+	if token.Value != "" {
+		dataKey, ciphertext, err := s.envelope.Encrypt([]byte(token.Value), tokenAssociatedData(userID, token.ID))
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "could not encrypt token"), "token", token.ID)
+		}
+
+		model.DataKey = base64.StdEncoding.EncodeToString(dataKey)
+		model.Value = base64.StdEncoding.EncodeToString(ciphertext)
+	}
+
+	if err := s.db.Save(&model).Error; err != nil {
+		return emperror.With(errors.Wrap(err, "could not save token"), "token", token.ID)
+	}
+
+	return nil
+}
+
+// Lookup returns a token of a user with its value or nil if the token does not exist.
+func (s *DatabaseTokenStore) Lookup(userID string, tokenID string) (*bauth.Token, error) {
+	var model AuthToken
+
+	err := s.db.Where(&AuthToken{UserID: userID, ID: tokenID}).First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch token"), "token", tokenID)
+	}
+
+	token := parseAuthToken(&model)
+
+	if model.Value != "" {
+		value, err := s.decrypt(&model)
+		if err != nil {
+			return nil, emperror.With(errors.Wrap(err, "could not decrypt token"), "token", tokenID)
+		}
+
+		token.Value = value
+	}
+
+	return token, nil
+}
+
+func (s *DatabaseTokenStore) decrypt(model *AuthToken) (string, error) {
+	dataKey, err := base64.StdEncoding.DecodeString(model.DataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(model.Value)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := s.envelope.Decrypt(dataKey, ciphertext, tokenAssociatedData(model.UserID, model.ID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+// tokenAssociatedData binds the encrypted value of a token to its user and ID.
+func tokenAssociatedData(userID string, tokenID string) []byte {
+	return []byte(userID + "/" + tokenID)
+}
+
+// Exists checks whether a token of a user exists.
+func (s *DatabaseTokenStore) Exists(userID string, tokenID string) (bool, error) {
+	var count int
+
+	err := s.db.Model(&AuthToken{}).Where(&AuthToken{UserID: userID, ID: tokenID}).Count(&count).Error
+	if err != nil {
+		return false, emperror.With(errors.Wrap(err, "could not check token"), "token", tokenID)
+	}
+
+	return count > 0, nil
+}
+
+// Revoke deletes a token of a user.
+func (s *DatabaseTokenStore) Revoke(userID string, tokenID string) error {
+	err := s.db.Where("user_id = ? AND id = ?", userID, tokenID).Delete(&AuthToken{}).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not revoke token"), "token", tokenID)
+	}
+
+	return nil
+}
+
+// List returns the tokens of a user without their values.
+func (s *DatabaseTokenStore) List(userID string) ([]*bauth.Token, error) {
+	var models []AuthToken
+
+	err := s.db.Where(&AuthToken{UserID: userID}).Order("created_at").Find(&models).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not list tokens"), "user", userID)
+	}
+
+	tokens := make([]*bauth.Token, 0, len(models))
+	for i := range models {
+		tokens = append(tokens, parseAuthToken(&models[i]))
+	}
+
+	return tokens, nil
+}
+
+// GC deletes the expired tokens.
+func (s *DatabaseTokenStore) GC() error {
+	err := s.db.Where("expires_at < ?", time.Now()).Delete(&AuthToken{}).Error
+	if err != nil {
+		return errors.Wrap(err, "could not delete expired tokens")
+	}
+
+	return nil
+}
+
+func parseAuthToken(model *AuthToken) *bauth.Token {
+	token := bauth.NewToken(model.ID, model.Name)
+	token.ExpiresAt = model.ExpiresAt
+
+	createdAt := model.CreatedAt
+	token.CreatedAt = &createdAt
+
+	return token
+}
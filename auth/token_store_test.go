@@ -0,0 +1,124 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	bauth "github.com/banzaicloud/bank-vaults/pkg/auth"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+var _ bauth.TokenStore = (*DatabaseTokenStore)(nil)
+
+func newTestDatabaseTokenStore(t *testing.T) (*DatabaseTokenStore, *gorm.DB) {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every connection would get its own in-memory database
+	db.DB().SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&AuthToken{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, err := secret.NewEnvelope(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewDatabaseTokenStore(db, envelope), db
+}
+
+func TestDatabaseTokenStore(t *testing.T) {
+	store, db := newTestDatabaseTokenStore(t)
+
+	githubToken := bauth.NewToken(GithubTokenID, "Github access token")
+	githubToken.Value = "old"
+	if err := store.Store("1", githubToken); err != nil {
+		t.Fatal(err)
+	}
+
+	githubToken.Value = "new"
+	if err := store.Store("1", githubToken); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := store.Lookup("1", GithubTokenID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token == nil || token.Value != "new" {
+		t.Fatalf("expected the replaced token value, got %+v", token)
+	}
+
+	var model AuthToken
+	if err := db.Where(&AuthToken{UserID: "1", ID: GithubTokenID}).First(&model).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if model.Value == "" || model.Value == "new" {
+		t.Error("expected the token value to be stored encrypted")
+	}
+
+	if token, err := store.Lookup("2", GithubTokenID); err != nil || token != nil {
+		t.Errorf("expected no token for another user, got %+v (%v)", token, err)
+	}
+
+	expiresAt := time.Now().Add(-time.Hour)
+	apiToken := bauth.NewToken("api-token", "expired")
+	apiToken.ExpiresAt = &expiresAt
+	if err := store.Store("1", apiToken); err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := store.List("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tokens) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(tokens))
+	}
+
+	for _, token := range tokens {
+		if token.Value != "" {
+			t.Errorf("expected listed token %q without its value", token.ID)
+		}
+	}
+
+	if err := store.GC(); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, err := store.Exists("1", "api-token"); err != nil || exists {
+		t.Errorf("expected the expired token to be garbage collected (%v)", err)
+	}
+
+	if err := store.Revoke("1", GithubTokenID); err != nil {
+		t.Fatal(err)
+	}
+
+	if exists, err := store.Exists("1", GithubTokenID); err != nil || exists {
+		t.Errorf("expected the revoked token to be deleted (%v)", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	bauth "github.com/banzaicloud/bank-vaults/pkg/auth"
 	"github.com/banzaicloud/pipeline/config"
 	"github.com/banzaicloud/pipeline/helm"
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/drone/drone-go/drone"
 	"github.com/goph/emperror"
@@ -91,6 +92,7 @@ type User struct {
 	Image         string         `form:"image" json:"image,omitempty"`
 	Organizations []Organization `gorm:"many2many:user_organizations" json:"organizations,omitempty"`
 	Virtual       bool           `json:"-" gorm:"-"` // Used only internally
+	APITokenID    string         `json:"-" gorm:"-"` // Used only internally
 }
 
 //DroneUser struct
@@ -197,11 +199,12 @@ func NewDroneClient(request *http.Request) (drone.Client, error) {
 //BanzaiUserStorer struct
 type BanzaiUserStorer struct {
 	auth.UserStorer
-	signingKeyBase32 string // Drone uses base32 Hash
-	droneDB          *gorm.DB
-	events           authEvents
-	accessManager    accessManager
-	githubImporter   *GithubImporter
+	signingKeyBase32   string // Drone uses base32 Hash
+	droneDB            *gorm.DB
+	events             authEvents
+	accessManager      accessManager
+	githubImporter     *GithubImporter
+	organizationSyncer *OrganizationSyncer
 }
 
 // Save differs from the default UserStorer.Save() in that it
@@ -214,16 +217,24 @@ func (bus BanzaiUserStorer) Save(schema *auth.Schema, context *auth.Context) (us
 		return nil, "", err
 	}
 
-	// This assumes GitHub auth only right now
-	githubExtraInfo := schema.RawInfo.(*GithubExtraInfo)
-	currentUser.Login = githubExtraInfo.Login
-	err = bus.createUserInDroneDB(currentUser, githubExtraInfo.Token)
-	if err != nil {
-		log.Info(context.Request.RemoteAddr, err.Error())
-		return nil, "", err
-	}
+	switch info := schema.RawInfo.(type) {
+	case *GithubExtraInfo:
+		currentUser.Login = info.Login
+		err = bus.createUserInDroneDB(currentUser, info.Token)
+		if err != nil {
+			log.Info(context.Request.RemoteAddr, err.Error())
+			return nil, "", err
+		}
+
+		synchronizeDroneRepos(currentUser.Login)
 
-	synchronizeDroneRepos(currentUser.Login)
+	case *MappedUserInfo:
+		// Drone can only be used with GitHub accounts
+		currentUser.Login = info.Login
+
+	default:
+		return nil, "", errors.Errorf("unsupported user info: %T", schema.RawInfo)
+	}
 
 	// When a user registers a default organization is created in which he/she is admin
 	userOrg := Organization{
@@ -244,30 +255,50 @@ func (bus BanzaiUserStorer) Save(schema *auth.Schema, context *auth.Context) (us
 
 	bus.accessManager.GrantDefaultAccessToUser(currentUser.IDString())
 
-	// Save the Github token to Vault
-	token := bauth.NewToken(GithubTokenID, "Github access token")
-	token.Value = githubExtraInfo.Token
-	err = TokenStore.Store(fmt.Sprint(currentUser.ID), token)
-	if err != nil {
-		return "", "", fmt.Errorf("failed to store Github access token: %s", err.Error())
+	if githubExtraInfo, ok := schema.RawInfo.(*GithubExtraInfo); ok {
+		// Save the Github token to Vault
+		token := bauth.NewToken(GithubTokenID, "Github access token")
+		token.Value = githubExtraInfo.Token
+		err = TokenStore.Store(fmt.Sprint(currentUser.ID), token)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to store Github access token: %s", err.Error())
+		}
 	}
 
 	bus.accessManager.AddOrganizationPolicies(currentUser.Organizations[0].ID)
-	bus.accessManager.GrantOganizationAccessToUser(currentUser.IDString(), currentUser.Organizations[0].ID)
+	bus.accessManager.GrantOrganizationRoleToUser(currentUser.IDString(), currentUser.Organizations[0].ID, pkgAuth.RoleAdmin)
 	bus.events.OrganizationRegistered(currentUser.Organizations[0].ID)
 
-	err = bus.githubImporter.ImportOrganizations(currentUser, githubExtraInfo.Token)
+	switch info := schema.RawInfo.(type) {
+	case *GithubExtraInfo:
+		err = bus.githubImporter.ImportOrganizations(currentUser, info.Token)
+
+	case *MappedUserInfo:
+		err = bus.organizationSyncer.SyncOrganizations(currentUser, info)
+	}
 
 	return currentUser, fmt.Sprint(db.NewScope(currentUser).PrimaryKeyValue()), err
 }
 
 // Update differs from the default UserStorer.Update() in that it
 // updates the GitHub access token of the given user
+// or synchronizes the mapped organizations of the user
 func (bus BanzaiUserStorer) Update(schema *auth.Schema, context *auth.Context) error {
+	if info, ok := schema.RawInfo.(*MappedUserInfo); ok {
+		currentUser := &User{}
+		err := context.Auth.GetDB(context.Request).Where("id = ?", context.Claims.UserID).First(currentUser).Error
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "failed to fetch user"), "user", context.Claims.UserID)
+		}
+
+		return bus.organizationSyncer.SyncOrganizations(currentUser, info)
+	}
 
-	// This assumes GitHub auth only right now
 	currentUser := &User{}
-	githubExtraInfo := schema.RawInfo.(*GithubExtraInfo)
+	githubExtraInfo, ok := schema.RawInfo.(*GithubExtraInfo)
+	if !ok {
+		return errors.Errorf("unsupported user info: %T", schema.RawInfo)
+	}
 	currentUser.Login = githubExtraInfo.Login
 
 	// Revoke the old Github token from Vault
@@ -352,11 +383,15 @@ func (i *GithubImporter) ImportOrganizations(currentUser *User, githubToken stri
 		return emperror.With(err, "failed to import organizations")
 	}
 
-	for id, created := range githubOrgIDs {
+	for id, imported := range githubOrgIDs {
 		i.accessManager.AddOrganizationPolicies(id)
-		i.accessManager.GrantOganizationAccessToUser(currentUser.IDString(), id)
 
-		if created {
+		// roles assigned in Pipeline take precedence over the GitHub membership
+		if _, ok := i.accessManager.GetOrganizationRoleOfUser(currentUser.IDString(), id); !ok {
+			i.accessManager.GrantOrganizationRoleToUser(currentUser.IDString(), id, imported.role)
+		}
+
+		if imported.created {
 			i.events.OrganizationRegistered(id)
 		}
 	}
@@ -364,13 +399,19 @@ func (i *GithubImporter) ImportOrganizations(currentUser *User, githubToken stri
 	return nil
 }
 
-func importGithubOrganizations(db *gorm.DB, currentUser *User, githubToken string) (map[uint]bool, error) {
+// importedGithubOrganization is an organization imported from GitHub with the built-in role of the user.
+type importedGithubOrganization struct {
+	created bool
+	role    string
+}
+
+func importGithubOrganizations(db *gorm.DB, currentUser *User, githubToken string) (map[uint]importedGithubOrganization, error) {
 	orgs, err := getGithubOrganizations(githubToken)
 	if err != nil {
 		return nil, err
 	}
 
-	orgIDs := make(map[uint]bool, len(orgs))
+	orgIDs := make(map[uint]importedGithubOrganization, len(orgs))
 
 	tx := db.Begin()
 	for _, org := range orgs {
@@ -380,9 +421,11 @@ func importGithubOrganizations(db *gorm.DB, currentUser *User, githubToken strin
 			Role:     org.role,
 		}
 
+		role := githubMembershipRole(org.role)
+
 		err := tx.Where(o).First(&o).Error
 		if err == nil {
-			orgIDs[o.ID] = false
+			orgIDs[o.ID] = importedGithubOrganization{role: role}
 
 			continue
 		} else if !gorm.IsRecordNotFoundError(err) {
@@ -398,7 +441,7 @@ func importGithubOrganizations(db *gorm.DB, currentUser *User, githubToken strin
 			return nil, errors.Wrap(err, "failed to create organization")
 		}
 
-		orgIDs[o.ID] = true
+		orgIDs[o.ID] = importedGithubOrganization{created: true, role: role}
 
 		err = tx.Model(currentUser).Association("Organizations").Append(o).Error
 		if err != nil {
@@ -408,7 +451,7 @@ func importGithubOrganizations(db *gorm.DB, currentUser *User, githubToken strin
 		}
 
 		userRoleInOrg := UserOrganization{UserID: currentUser.ID, OrganizationID: o.ID}
-		err = tx.Model(&UserOrganization{}).Where(userRoleInOrg).Update("role", o.Role).Error
+		err = tx.Model(&UserOrganization{}).Where(userRoleInOrg).Update("role", role).Error
 		if err != nil {
 			tx.Rollback()
 
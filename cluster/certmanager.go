@@ -0,0 +1,508 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/dns"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/banzaicloud/pipeline/pkg/k8sutil"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/banzaicloud/pipeline/secret/verify"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// certManagerReleaseName is the name of the cert-manager release deployed by the InstallCertManagerPostHook
+	certManagerReleaseName = "cert-manager"
+
+	// acmeIssuerName is the name of the ClusterIssuer issuing certificates from the configured ACME server
+	acmeIssuerName = "pipeline-acme"
+
+	// acmeDNSCredentialsSecretName is the name of the Kubernetes secret holding the DNS provider credentials
+	// used by cert-manager to solve DNS-01 challenges
+	acmeDNSCredentialsSecretName = "acme-dns-credentials"
+
+	// acmeDNSProviderName is the name of the DNS-01 challenge provider of the ClusterIssuer
+	acmeDNSProviderName = "pipeline"
+
+	// clusterCertificateName is the name of the Certificate issued for the domain of the cluster
+	clusterCertificateName = "cluster-wildcard"
+
+	// clusterCertificateSecretName is the name of the Kubernetes secret cert-manager stores the issued certificate in
+	clusterCertificateSecretName = "cluster-wildcard-tls"
+)
+
+var (
+	clusterIssuerResource = schema.GroupVersionResource{Group: "certmanager.k8s.io", Version: "v1alpha1", Resource: "clusterissuers"}
+	certificateResource   = schema.GroupVersionResource{Group: "certmanager.k8s.io", Version: "v1alpha1", Resource: "certificates"}
+)
+
+// InstallCertManagerPostHook installs cert-manager into the cluster and requests a wildcard certificate
+// for the domain of the cluster from the configured ACME server. The DNS-01 challenges are solved
+// using the credentials of the DNS provider managing the domain of the organization.
+func InstallCertManagerPostHook(input interface{}) error {
+	commonCluster, ok := input.(CommonCluster)
+	if !ok {
+		return errors.Errorf("Wrong parameter type: %T", commonCluster)
+	}
+
+	if !viper.GetBool(config.ACMEEnabled) {
+		log.Info("Exiting as ACME certificate issuance is not enabled")
+		return nil
+	}
+
+	orgId := commonCluster.GetOrganizationId()
+
+	dnsSvc, err := dns.GetExternalDnsServiceClient()
+	if err != nil {
+		return emperror.Wrap(err, "Getting external dns service client failed")
+	}
+
+	if dnsSvc == nil {
+		log.Info("Exiting as external dns service functionality is not enabled")
+		return nil
+	}
+
+	settings, err := dnsSvc.GetProviderSettings(orgId)
+	if err != nil {
+		return emperror.Wrap(err, "Getting DNS provider settings failed")
+	}
+
+	if settings == nil {
+		log.Info("Exiting as the domain of the organization is not registered")
+		return nil
+	}
+
+	providerSecret, err := secret.Store.Get(orgId, settings.SecretID)
+	if err != nil {
+		return emperror.Wrapf(err, "Getting %s secret failed", settings.Provider)
+	}
+
+	dns01Provider, credentials, err := certManagerDNS01Provider(settings, providerSecret.Values)
+	if err != nil {
+		return emperror.Wrap(err, "Failed to configure cert-manager")
+	}
+
+	namespace := viper.GetString(config.PipelineSystemNamespace)
+
+	values, err := yaml.Marshal(map[string]interface{}{
+		"rbac": map[string]bool{
+			"create": commonCluster.RbacEnabled() == true,
+		},
+		"clusterResourceNamespace": namespace,
+		"ingressShim": map[string]string{
+			"defaultIssuerName":                 acmeIssuerName,
+			"defaultIssuerKind":                 "ClusterIssuer",
+			"defaultACMEChallengeType":          "dns01",
+			"defaultACMEDNS01ChallengeProvider": acmeDNSProviderName,
+		},
+		"affinity":    getHeadNodeAffinity(commonCluster),
+		"tolerations": getHeadNodeTolerations(),
+	})
+	if err != nil {
+		return emperror.Wrap(err, "Json Convert Failed")
+	}
+
+	chartVersion := viper.GetString(config.ACMECertManagerChartVersion)
+
+	err = installDeployment(commonCluster, namespace, pkgHelm.StableRepository+"/cert-manager", certManagerReleaseName, values, chartVersion, true)
+	if err != nil {
+		return emperror.Wrap(err, "Installing cert-manager failed")
+	}
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "Getting kubeconfig failed")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "Creating Kubernetes client failed")
+	}
+
+	err = k8sutil.EnsureNamespace(client, namespace)
+	if err != nil {
+		return emperror.Wrap(err, "Checking namespace failed")
+	}
+
+	credentialsSecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      acmeDNSCredentialsSecretName,
+			Namespace: namespace,
+		},
+		StringData: credentials,
+	}
+
+	_, err = client.CoreV1().Secrets(namespace).Create(credentialsSecret)
+	if apierrors.IsAlreadyExists(err) {
+		_, err = client.CoreV1().Secrets(namespace).Update(credentialsSecret)
+	}
+	if err != nil {
+		return emperror.Wrap(err, "Installing ACME DNS credentials secret failed")
+	}
+
+	clientConfig, err := k8sclient.NewClientConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "Creating Kubernetes client config failed")
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(clientConfig)
+	if err != nil {
+		return emperror.Wrap(err, "Creating Kubernetes dynamic client failed")
+	}
+
+	issuer := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": clusterIssuerResource.GroupVersion().String(),
+			"kind":       "ClusterIssuer",
+			"metadata": map[string]interface{}{
+				"name": acmeIssuerName,
+			},
+			"spec": map[string]interface{}{
+				"acme": map[string]interface{}{
+					"server": viper.GetString(config.ACMEServer),
+					"email":  viper.GetString(config.ACMEEmail),
+					"privateKeySecretRef": map[string]interface{}{
+						"name": acmeIssuerName + "-account-key",
+					},
+					"dns01": map[string]interface{}{
+						"providers": []interface{}{dns01Provider},
+					},
+				},
+			},
+		},
+	}
+
+	clusterDomain := fmt.Sprintf("%s.%s", commonCluster.GetName(), settings.Domain)
+
+	certificate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": certificateResource.GroupVersion().String(),
+			"kind":       "Certificate",
+			"metadata": map[string]interface{}{
+				"name":      clusterCertificateName,
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"secretName": clusterCertificateSecretName,
+				"commonName": clusterDomain,
+				"dnsNames":   []interface{}{clusterDomain, "*." + clusterDomain},
+				"issuerRef": map[string]interface{}{
+					"name": acmeIssuerName,
+					"kind": "ClusterIssuer",
+				},
+				"acme": map[string]interface{}{
+					"config": []interface{}{
+						map[string]interface{}{
+							"dns01": map[string]interface{}{
+								"provider": acmeDNSProviderName,
+							},
+							"domains": []interface{}{clusterDomain, "*." + clusterDomain},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// the custom resource definitions may not be served right after the chart is installed
+	err = retry(func() error {
+		if err := applyUnstructured(dynamicClient.Resource(clusterIssuerResource), issuer); err != nil {
+			return err
+		}
+
+		return applyUnstructured(dynamicClient.Resource(certificateResource).Namespace(namespace), certificate)
+	}, 10, 5)
+	if err != nil {
+		return emperror.Wrap(err, "Creating cert-manager resources failed")
+	}
+
+	log.Infof("Certificate requested for domain '%s'", clusterDomain)
+
+	// issuing the certificate takes a while (DNS propagation), the periodic sync picks it up if this one gives up
+	go func() {
+		if err := retry(func() error { return syncClusterCertificate(commonCluster) }, 20, 15); err != nil {
+			log.Warnf("Mirroring the certificate of cluster %q failed: %s", commonCluster.GetName(), err.Error())
+		}
+	}()
+
+	return nil
+}
+
+// applyUnstructured creates a resource or updates it if it already exists.
+func applyUnstructured(client dynamic.ResourceInterface, obj *unstructured.Unstructured) error {
+	current, err := client.Get(obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = client.Create(obj)
+
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion(current.GetResourceVersion())
+	_, err = client.Update(obj)
+
+	return err
+}
+
+// certManagerDNS01Provider returns the cert-manager DNS-01 challenge provider configuration for the DNS provider
+// which manages the domain of the organization, along with the data of the secret referenced by the configuration.
+func certManagerDNS01Provider(settings *pkgDns.ProviderSettings, credentials map[string]string) (map[string]interface{}, map[string]string, error) {
+	const secretKey = "secret"
+
+	secretRef := map[string]interface{}{
+		"name": acmeDNSCredentialsSecretName,
+		"key":  secretKey,
+	}
+
+	switch settings.Provider {
+	case pkgDns.Route53:
+		return map[string]interface{}{
+				"name": acmeDNSProviderName,
+				"route53": map[string]interface{}{
+					"region":                   credentials[pkgSecret.AwsRegion],
+					"accessKeyID":              credentials[pkgSecret.AwsAccessKeyId],
+					"secretAccessKeySecretRef": secretRef,
+				},
+			},
+			map[string]string{secretKey: credentials[pkgSecret.AwsSecretAccessKey]},
+			nil
+
+	case pkgDns.Azure:
+		return map[string]interface{}{
+				"name": acmeDNSProviderName,
+				"azuredns": map[string]interface{}{
+					"clientID":              credentials[pkgSecret.AzureClientId],
+					"clientSecretSecretRef": secretRef,
+					"subscriptionID":        credentials[pkgSecret.AzureSubscriptionId],
+					"tenantID":              credentials[pkgSecret.AzureTenantId],
+					"resourceGroupName":     settings.Options[pkgDns.AzureResourceGroup],
+				},
+			},
+			map[string]string{secretKey: credentials[pkgSecret.AzureClientSecret]},
+			nil
+
+	case pkgDns.Google:
+		serviceAccountKey, err := json.Marshal(verify.CreateServiceAccount(credentials))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to encode Google service account")
+		}
+
+		return map[string]interface{}{
+				"name": acmeDNSProviderName,
+				"clouddns": map[string]interface{}{
+					"project":                 settings.Options[pkgDns.GoogleProject],
+					"serviceAccountSecretRef": secretRef,
+				},
+			},
+			map[string]string{secretKey: string(serviceAccountKey)},
+			nil
+
+	case pkgDns.RFC2136:
+		algorithm := strings.ToUpper(strings.Replace(settings.Options[pkgDns.RFC2136TSIGAlg], "-", "", -1))
+
+		return map[string]interface{}{
+				"name": acmeDNSProviderName,
+				"rfc2136": map[string]interface{}{
+					"nameserver":          net.JoinHostPort(settings.Options[pkgDns.RFC2136Host], settings.Options[pkgDns.RFC2136Port]),
+					"tsigKeyName":         settings.Options[pkgDns.RFC2136TSIGKeyName],
+					"tsigAlgorithm":       algorithm,
+					"tsigSecretSecretRef": secretRef,
+				},
+			},
+			map[string]string{secretKey: credentials[pkgDns.RFC2136TSIGSecret]},
+			nil
+
+	default:
+		return nil, nil, emperror.With(errors.New("unsupported DNS provider"), "provider", settings.Provider)
+	}
+}
+
+// clusterCertificateSecretRequest returns the secret store request of the certificate issued for the cluster.
+func clusterCertificateSecretRequest(commonCluster CommonCluster) *secret.CreateSecretRequest {
+	return &secret.CreateSecretRequest{
+		Name: fmt.Sprintf("cluster-%d-acme-tls", commonCluster.GetID()),
+		Type: pkgSecret.TLSSecretType,
+		Tags: []string{
+			fmt.Sprintf("cluster:%s", commonCluster.GetName()),
+			fmt.Sprintf("clusterUID:%s", commonCluster.GetUID()),
+			pkgSecret.TagBanzaiReadonly,
+			"app:cert-manager",
+		},
+	}
+}
+
+// syncClusterCertificate mirrors the certificate issued by cert-manager for the cluster into the secret store.
+func syncClusterCertificate(commonCluster CommonCluster) error {
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to create Kubernetes client")
+	}
+
+	namespace := viper.GetString(config.PipelineSystemNamespace)
+
+	k8sSecret, err := client.CoreV1().Secrets(namespace).Get(clusterCertificateSecretName, metav1.GetOptions{})
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "failed to get certificate secret"), "cluster", commonCluster.GetName())
+	}
+
+	serverCert := string(k8sSecret.Data[v1.TLSCertKey])
+	if serverCert == "" {
+		return emperror.With(errors.New("certificate is not issued yet"), "cluster", commonCluster.GetName())
+	}
+
+	cert, err := parseCertificate(serverCert)
+	if err != nil {
+		return emperror.With(err, "cluster", commonCluster.GetName())
+	}
+
+	request := clusterCertificateSecretRequest(commonCluster)
+
+	current, err := secret.Store.Get(commonCluster.GetOrganizationId(), secret.GenerateSecretID(request))
+	if err != nil && err != secret.ErrSecretNotExists {
+		return errors.Wrap(err, "failed to get certificate from secret store")
+	} else if current != nil && current.Values[pkgSecret.ServerCert] == serverCert {
+		return nil
+	}
+
+	request.Values = map[string]string{
+		pkgSecret.TLSHosts:   strings.Join(cert.DNSNames, ","),
+		pkgSecret.CACert:     string(k8sSecret.Data["ca.crt"]),
+		pkgSecret.ServerCert: serverCert,
+		pkgSecret.ServerKey:  string(k8sSecret.Data[v1.TLSPrivateKeyKey]),
+	}
+
+	if _, err := secret.Store.CreateOrUpdate(commonCluster.GetOrganizationId(), request); err != nil {
+		return errors.Wrap(err, "failed to store certificate")
+	}
+
+	log.Infof("Certificate of cluster %q mirrored into the secret store, expires at %s", commonCluster.GetName(), cert.NotAfter)
+
+	return nil
+}
+
+// parseCertificate parses the first certificate of a PEM encoded certificate chain.
+func parseCertificate(certPEM string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil {
+		return nil, errors.New("failed to decode certificate PEM")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+
+	return cert, nil
+}
+
+// GetClusterCertificate returns the details of the certificate issued for the cluster
+// or nil if no certificate has been issued.
+func GetClusterCertificate(commonCluster CommonCluster) (*pkgCluster.CertificateDetails, error) {
+	request := clusterCertificateSecretRequest(commonCluster)
+
+	certSecret, err := secret.Store.Get(commonCluster.GetOrganizationId(), secret.GenerateSecretID(request))
+	if err == secret.ErrSecretNotExists {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get certificate from secret store")
+	}
+
+	cert, err := parseCertificate(certSecret.Values[pkgSecret.ServerCert])
+	if err != nil {
+		return nil, emperror.With(err, "secret", certSecret.ID)
+	}
+
+	return &pkgCluster.CertificateDetails{
+		SecretID:   certSecret.ID,
+		SecretName: certSecret.Name,
+		Hosts:      cert.DNSNames,
+		NotBefore:  cert.NotBefore,
+		NotAfter:   cert.NotAfter,
+	}, nil
+}
+
+// RunCertificateSync periodically mirrors the certificates issued for the running clusters into the secret store.
+func (m *Manager) RunCertificateSync(ctx context.Context, interval time.Duration) {
+	logger := m.getLogger(ctx)
+	errorHandler := m.getErrorHandler(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			logger.Debug("syncing cluster certificates")
+
+			clusters, err := m.GetAllClusters(ctx)
+			if err != nil {
+				errorHandler.Handle(err)
+				continue
+			}
+
+			for _, commonCluster := range clusters {
+				status, err := commonCluster.GetStatus()
+				if err != nil {
+					errorHandler.Handle(emperror.With(err, "cluster", commonCluster.GetID()))
+					continue
+				}
+
+				if status.Status != pkgCluster.Running {
+					continue
+				}
+
+				err = syncClusterCertificate(commonCluster)
+				if apierrors.IsNotFound(errors.Cause(err)) {
+					continue
+				} else if err != nil {
+					errorHandler.Handle(emperror.With(err, "cluster", commonCluster.GetID()))
+				}
+			}
+		}
+	}
+}
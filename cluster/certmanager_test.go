@@ -0,0 +1,67 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+)
+
+func TestCertManagerDNS01Provider(t *testing.T) {
+	settings := &pkgDns.ProviderSettings{
+		Provider: pkgDns.RFC2136,
+		Domain:   "org.example.org",
+		Options: map[string]string{
+			pkgDns.RFC2136Host:        "10.0.0.1",
+			pkgDns.RFC2136Port:        "53",
+			pkgDns.RFC2136Zone:        "example.org",
+			pkgDns.RFC2136TSIGKeyName: "pipeline",
+			pkgDns.RFC2136TSIGAlg:     "hmac-sha256",
+		},
+	}
+
+	provider, credentials, err := certManagerDNS01Provider(settings, map[string]string{pkgDns.RFC2136TSIGSecret: "secret"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expected := map[string]interface{}{
+		"name": "pipeline",
+		"rfc2136": map[string]interface{}{
+			"nameserver":    "10.0.0.1:53",
+			"tsigKeyName":   "pipeline",
+			"tsigAlgorithm": "HMACSHA256",
+			"tsigSecretSecretRef": map[string]interface{}{
+				"name": "acme-dns-credentials",
+				"key":  "secret",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(provider, expected) {
+		t.Errorf("unexpected provider: %v", provider)
+	}
+
+	if !reflect.DeepEqual(credentials, map[string]string{"secret": "secret"}) {
+		t.Errorf("unexpected credentials: %v", credentials)
+	}
+
+	_, _, err = certManagerDNS01Provider(&pkgDns.ProviderSettings{Provider: "unknown"}, nil)
+	if err == nil {
+		t.Error("expected error for unsupported provider")
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/helm"
+	"github.com/banzaicloud/pipeline/internal/clustertemplate"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ApplyClusterTemplate installs the secrets and the deployments of the cluster template the cluster was created from.
+func ApplyClusterTemplate(input interface{}, param pkgCluster.PostHookParam) error {
+	commonCluster, ok := input.(CommonCluster)
+	if !ok {
+		return errors.Errorf("Wrong parameter type: %T", commonCluster)
+	}
+
+	var resources pkgCluster.ClusterTemplateResources
+	if err := castToPostHookParam(&param, &resources); err != nil {
+		return errors.Wrap(err, "invalid cluster template resources")
+	}
+
+	for _, templateSecret := range resources.Secrets {
+		_, err := InstallSecrets(
+			commonCluster,
+			&pkgSecret.ListSecretsQuery{
+				IDs: []string{secret.GenerateSecretIDFromName(templateSecret.Name)},
+			},
+			templateSecret.Namespace,
+		)
+		if err != nil {
+			return emperror.With(errors.WithMessage(err, "failed to install secret"), "secret", templateSecret.Name)
+		}
+	}
+
+	for _, deployment := range resources.Deployments {
+		values, err := yaml.Marshal(deployment.Values)
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "failed to encode deployment values"), "release", deployment.ReleaseName)
+		}
+
+		err = installDeployment(
+			commonCluster,
+			deployment.Namespace,
+			deployment.Name,
+			deployment.ReleaseName,
+			values,
+			deployment.Version,
+			deployment.Wait,
+		)
+		if err != nil {
+			return emperror.With(err, "release", deployment.ReleaseName)
+		}
+	}
+
+	return nil
+}
+
+// clusterTemplateInspector reads the state of a cluster compared to its cluster template.
+type clusterTemplateInspector struct {
+	cluster CommonCluster
+}
+
+// NewClusterTemplateInspector returns a cluster template inspector for the cluster.
+func NewClusterTemplateInspector(cluster CommonCluster) clustertemplate.ClusterInspector {
+	return &clusterTemplateInspector{cluster: cluster}
+}
+
+// NodePools returns the node pools of the cluster by name.
+func (i *clusterTemplateInspector) NodePools() (map[string]clustertemplate.NodePoolState, error) {
+	details, err := i.cluster.GetClusterDetails()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get cluster details")
+	}
+
+	nodePools := make(map[string]clustertemplate.NodePoolState, len(details.NodePools))
+	for name, nodePool := range details.NodePools {
+		nodePools[name] = clustertemplate.NodePoolState{
+			Count:    nodePool.Count,
+			MinCount: nodePool.MinCount,
+			MaxCount: nodePool.MaxCount,
+		}
+	}
+
+	return nodePools, nil
+}
+
+// Release returns a Helm release of the cluster or nil if it is not installed.
+func (i *clusterTemplateInspector) Release(releaseName string) (*clustertemplate.ReleaseState, error) {
+	kubeConfig, err := i.cluster.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	deployment, err := helm.GetDeployment(releaseName, kubeConfig)
+	if _, ok := err.(*helm.DeploymentNotFoundError); ok {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.WithMessage(err, "failed to get deployment")
+	}
+
+	// normalize the nested chart values to plain maps
+	var values map[string]interface{}
+	valuesJSON, err := json.Marshal(deployment.Values)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode deployment values")
+	}
+	if err := json.Unmarshal(valuesJSON, &values); err != nil {
+		return nil, errors.Wrap(err, "failed to decode deployment values")
+	}
+
+	return &clustertemplate.ReleaseState{
+		ChartName:    deployment.ChartName,
+		ChartVersion: deployment.ChartVersion,
+		Namespace:    deployment.Namespace,
+		Values:       values,
+	}, nil
+}
+
+// SecretExists checks whether a secret is installed into the cluster.
+func (i *clusterTemplateInspector) SecretExists(namespace string, name string) (bool, error) {
+	kubeConfig, err := i.cluster.GetK8sConfig()
+	if err != nil {
+		return false, emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return false, emperror.Wrap(err, "failed to create Kubernetes client")
+	}
+
+	_, err = client.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to get secret")
+	}
+
+	return true, nil
+}
+
+// PostHooks returns the status of the posthooks of the cluster by name.
+func (i *clusterTemplateInspector) PostHooks() (map[string]string, error) {
+	statuses, err := GetPostHookStatuses(i.cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	postHooks := make(map[string]string, len(statuses))
+	for _, status := range statuses {
+		postHooks[status.Name] = status.Status
+	}
+
+	return postHooks, nil
+}
+
+// deleteClusterTemplateBinding deletes the record of the cluster template the cluster was created from.
+func deleteClusterTemplateBinding(cluster CommonCluster) error {
+	return clustertemplate.NewRepository(config.DB()).DeleteBinding(cluster.GetID())
+}
@@ -0,0 +1,162 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"regexp"
+	"text/template"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/config"
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const defaultCustomPostHookNamespace = "default"
+
+var customPostHookNameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// CustomPostFunction is an organization defined posthook installing a Helm chart
+type CustomPostFunction struct {
+	postHook *intCluster.CustomPostHookModel
+	params   pkgCluster.PostHookParam
+	ErrorHandler
+}
+
+// customPostHookValuesData is passed to the values template of custom posthooks
+type customPostHookValuesData struct {
+	ClusterID        uint
+	ClusterUID       string
+	ClusterName      string
+	Cloud            string
+	Distribution     string
+	Location         string
+	OrganizationID   uint
+	OrganizationName string
+	Params           pkgCluster.PostHookParam
+}
+
+// Name returns the name of the posthook
+func (f *CustomPostFunction) Name() string {
+	return f.postHook.Name
+}
+
+// Do renders the values template and installs the chart of the posthook
+func (f *CustomPostFunction) Do(cluster CommonCluster) error {
+	org, err := auth.GetOrganizationById(cluster.GetOrganizationId())
+	if err != nil {
+		return emperror.Wrap(err, "could not get organization")
+	}
+
+	values, err := renderCustomPostHookValues(f.postHook.Values, customPostHookValuesData{
+		ClusterID:        cluster.GetID(),
+		ClusterUID:       cluster.GetUID(),
+		ClusterName:      cluster.GetName(),
+		Cloud:            cluster.GetCloud(),
+		Distribution:     cluster.GetDistribution(),
+		Location:         cluster.GetLocation(),
+		OrganizationID:   org.ID,
+		OrganizationName: org.Name,
+		Params:           f.params,
+	})
+	if err != nil {
+		return emperror.With(err, "posthook", f.postHook.Name)
+	}
+
+	return installDeployment(
+		cluster,
+		f.postHook.Namespace,
+		f.postHook.GetChart(),
+		f.postHook.ReleaseName,
+		values,
+		f.postHook.ChartVersion,
+		f.postHook.Wait,
+	)
+}
+
+func (f *CustomPostFunction) String() string {
+	return f.postHook.Name
+}
+
+// GetParams returns posthook params
+func (f *CustomPostFunction) GetParams() pkgCluster.PostHookParam {
+	return f.params
+}
+
+func renderCustomPostHookValues(valuesTemplate string, data customPostHookValuesData) ([]byte, error) {
+	tmpl, err := template.New("values").Funcs(pkgHelm.TemplateFuncMap()).Parse(valuesTemplate)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse values template")
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return nil, errors.Wrap(err, "could not render values template")
+	}
+
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(buffer.Bytes(), &values); err != nil {
+		return nil, errors.Wrap(err, "rendered values are not valid YAML")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func getCustomPostHookRepository() *intCluster.CustomPostHooks {
+	return intCluster.NewCustomPostHooks(config.DB())
+}
+
+// GetCustomPostHookFunction returns the custom posthook of an organization as a posthook function
+func GetCustomPostHookFunction(organizationID uint, name string, params pkgCluster.PostHookParam) (PostFunctioner, error) {
+	postHook, err := getCustomPostHookRepository().FindOne(organizationID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CustomPostFunction{
+		postHook: postHook,
+		params:   params,
+	}, nil
+}
+
+// ValidateCustomPostHookRequest validates a custom posthook request and fills the default values
+func ValidateCustomPostHookRequest(req *pkgCluster.CustomPostHookRequest) error {
+	if !customPostHookNameRegexp.MatchString(req.Name) {
+		return &invalidError{errors.Errorf("name must match %s", customPostHookNameRegexp.String())}
+	}
+
+	if _, ok := HookMap[req.Name]; ok {
+		return &invalidError{errors.Errorf("%s is a built-in posthook", req.Name)}
+	}
+
+	if _, err := template.New("values").Funcs(pkgHelm.TemplateFuncMap()).Parse(req.Values); err != nil {
+		return &invalidError{errors.Wrap(err, "invalid values template")}
+	}
+
+	if req.Namespace == "" {
+		req.Namespace = defaultCustomPostHookNamespace
+	}
+
+	if req.ReleaseName == "" {
+		req.ReleaseName = req.Name
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+func TestRenderCustomPostHookValues(t *testing.T) {
+	data := customPostHookValuesData{
+		ClusterID:        1,
+		ClusterName:      "my-cluster",
+		Cloud:            "amazon",
+		OrganizationName: "my-org",
+		Params:           map[string]interface{}{"replicas": 3},
+	}
+
+	values, err := renderCustomPostHookValues(
+		"name: {{ .ClusterName }}\ncloud: {{ .Cloud | upper }}\norg: {{ .OrganizationName | quote }}\nreplicas: {{ .Params.replicas }}\n",
+		data,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expected := "name: my-cluster\ncloud: AMAZON\norg: \"my-org\"\nreplicas: 3\n"
+	if string(values) != expected {
+		t.Errorf("unexpected values: %q", string(values))
+	}
+
+	if _, err := renderCustomPostHookValues("name: {{ .ClusterName", data); err == nil {
+		t.Error("expected a parse error")
+	}
+
+	if _, err := renderCustomPostHookValues("{{ .Unknown }}", data); err == nil {
+		t.Error("expected a render error")
+	}
+
+	if _, err := renderCustomPostHookValues("name: [{{ .ClusterName }}", data); err == nil {
+		t.Error("expected an invalid YAML error")
+	}
+
+	for _, valuesTemplate := range []string{`secret: {{ env "HOME" }}`, `secret: {{ expandenv "$HOME" }}`} {
+		if _, err := renderCustomPostHookValues(valuesTemplate, data); err == nil {
+			t.Errorf("expected a parse error for %q", valuesTemplate)
+		}
+	}
+}
+
+func TestValidateCustomPostHookRequest(t *testing.T) {
+	req := pkgCluster.CustomPostHookRequest{
+		Name:   "my-posthook",
+		Values: "name: {{ .ClusterName }}",
+	}
+
+	if err := ValidateCustomPostHookRequest(&req); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if req.Namespace != defaultCustomPostHookNamespace {
+		t.Errorf("unexpected namespace: %s", req.Namespace)
+	}
+
+	if req.ReleaseName != req.Name {
+		t.Errorf("unexpected release name: %s", req.ReleaseName)
+	}
+
+	req = pkgCluster.CustomPostHookRequest{
+		Name:        "my-posthook",
+		Namespace:   "my-namespace",
+		ReleaseName: "my-release",
+	}
+
+	if err := ValidateCustomPostHookRequest(&req); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if req.Namespace != "my-namespace" || req.ReleaseName != "my-release" {
+		t.Errorf("explicit values should not be overridden: %s, %s", req.Namespace, req.ReleaseName)
+	}
+
+	invalidRequests := map[string]pkgCluster.CustomPostHookRequest{
+		"uppercase name": {Name: "MyPostHook"},
+		"trailing dash":  {Name: "my-posthook-"},
+		"bad template":   {Name: "my-posthook", Values: "{{ .ClusterName"},
+		"env function":   {Name: "my-posthook", Values: `home: {{ env "HOME" }}`},
+	}
+
+	for name, req := range invalidRequests {
+		req := req
+
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCustomPostHookRequest(&req)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if e, ok := err.(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+				t.Errorf("expected an invalid error, got: %s", err.Error())
+			}
+		})
+	}
+}
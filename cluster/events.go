@@ -18,6 +18,12 @@ type clusterEvents interface {
 	// ClusterCreated event is emitted when a cluster creation workflow finishes.
 	ClusterCreated(clusterID uint)
 
+	// ClusterCreationFailed event is emitted when a cluster could not be created.
+	ClusterCreationFailed(clusterID uint, reason string)
+
+	// ClusterPostHooksFailed event is emitted when the posthooks of a cluster fail.
+	ClusterPostHooksFailed(clusterID uint, reason string)
+
 	// ClusterDeleted event is emitted when a cluster is completely deleted.
 	ClusterDeleted(orgID uint, clusterName string)
 }
@@ -32,6 +38,12 @@ func NewNopClusterEvents() *nopClusterEvents {
 func (*nopClusterEvents) ClusterCreated(clusterID uint) {
 }
 
+func (*nopClusterEvents) ClusterCreationFailed(clusterID uint, reason string) {
+}
+
+func (*nopClusterEvents) ClusterPostHooksFailed(clusterID uint, reason string) {
+}
+
 func (*nopClusterEvents) ClusterDeleted(orgID uint, clusterName string) {
 }
 
@@ -44,8 +56,10 @@ type clusterEventBus struct {
 }
 
 const (
-	clusterCreatedTopic = "cluster_created"
-	clusterDeletedTopic = "cluster_deleted"
+	clusterCreatedTopic         = "cluster_created"
+	clusterCreationFailedTopic  = "cluster_creation_failed"
+	clusterPostHooksFailedTopic = "cluster_posthooks_failed"
+	clusterDeletedTopic         = "cluster_deleted"
 )
 
 func NewClusterEvents(eb eventBus) *clusterEventBus {
@@ -58,6 +72,14 @@ func (c *clusterEventBus) ClusterCreated(clusterID uint) {
 	c.eb.Publish(clusterCreatedTopic, clusterID)
 }
 
+func (c *clusterEventBus) ClusterCreationFailed(clusterID uint, reason string) {
+	c.eb.Publish(clusterCreationFailedTopic, clusterID, reason)
+}
+
+func (c *clusterEventBus) ClusterPostHooksFailed(clusterID uint, reason string) {
+	c.eb.Publish(clusterPostHooksFailedTopic, clusterID, reason)
+}
+
 func (c *clusterEventBus) ClusterDeleted(orgID uint, clusterName string) {
 	c.eb.Publish(clusterDeletedTopic, orgID, clusterName)
 }
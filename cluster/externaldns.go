@@ -0,0 +1,205 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/dns"
+	"github.com/banzaicloud/pipeline/helm"
+	"github.com/banzaicloud/pipeline/internal/domain"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret/verify"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// externalDnsReleaseName is the name of the external-dns release deployed by the RegisterDomainPostHook
+const externalDnsReleaseName = "dns"
+
+// externalDnsProviderValues returns the external-dns chart values configuring the DNS provider
+// which manages the domain of the organization.
+func externalDnsProviderValues(settings *pkgDns.ProviderSettings, credentials map[string]string) (map[string]interface{}, error) {
+	switch settings.Provider {
+	case pkgDns.Route53:
+		return map[string]interface{}{
+			"aws": map[string]string{
+				"secretKey": credentials[pkgSecret.AwsSecretAccessKey],
+				"accessKey": credentials[pkgSecret.AwsAccessKeyId],
+				"region":    credentials[pkgSecret.AwsRegion],
+			},
+		}, nil
+
+	case pkgDns.Azure:
+		return map[string]interface{}{
+			"provider": "azure",
+			"azure": map[string]string{
+				"resourceGroup":   settings.Options[pkgDns.AzureResourceGroup],
+				"tenantId":        credentials[pkgSecret.AzureTenantId],
+				"subscriptionId":  credentials[pkgSecret.AzureSubscriptionId],
+				"aadClientId":     credentials[pkgSecret.AzureClientId],
+				"aadClientSecret": credentials[pkgSecret.AzureClientSecret],
+			},
+		}, nil
+
+	case pkgDns.Google:
+		serviceAccountKey, err := json.Marshal(verify.CreateServiceAccount(credentials))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode Google service account")
+		}
+
+		return map[string]interface{}{
+			"provider": "google",
+			"google": map[string]string{
+				"project":           settings.Options[pkgDns.GoogleProject],
+				"serviceAccountKey": string(serviceAccountKey),
+			},
+		}, nil
+
+	case pkgDns.RFC2136:
+		return map[string]interface{}{
+			"provider": "rfc2136",
+			"rfc2136": map[string]interface{}{
+				"host":          settings.Options[pkgDns.RFC2136Host],
+				"port":          settings.Options[pkgDns.RFC2136Port],
+				"zone":          settings.Options[pkgDns.RFC2136Zone],
+				"tsigKeyname":   settings.Options[pkgDns.RFC2136TSIGKeyName],
+				"tsigSecretAlg": settings.Options[pkgDns.RFC2136TSIGAlg],
+				"tsigSecret":    credentials[pkgDns.RFC2136TSIGSecret],
+				"tsigAxfr":      true,
+			},
+		}, nil
+
+	default:
+		return nil, emperror.With(errors.New("unsupported DNS provider"), "provider", settings.Provider)
+	}
+}
+
+// externalDnsDomainFilters returns the domains external-dns manages the records of:
+// the domain of the organization and its verified custom domains.
+func externalDnsDomainFilters(orgId uint, orgDomain string) ([]string, error) {
+	customDomains, err := domain.NewRepository(config.DB()).FindVerifiedDomains(orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	domainFilters := []string{orgDomain}
+	for _, customDomain := range customDomains {
+		domainFilters = append(domainFilters, customDomain.Domain)
+	}
+
+	return domainFilters, nil
+}
+
+// UpdateExternalDnsDomainFilters updates the domains managed by the external-dns deployed to the running clusters
+// of the organization after the custom domains of the organization changed.
+func (m *Manager) UpdateExternalDnsDomainFilters(ctx context.Context, organizationID uint) {
+	logger := m.getLogger(ctx).WithField("organization", organizationID)
+	errorHandler := emperror.HandlerWith(m.getErrorHandler(ctx), "organization", organizationID)
+
+	clusters, err := m.GetClusters(ctx, organizationID)
+	if err != nil {
+		errorHandler.Handle(err)
+		return
+	}
+
+	for _, commonCluster := range clusters {
+		status, err := commonCluster.GetStatus()
+		if err != nil {
+			errorHandler.Handle(emperror.With(err, "cluster", commonCluster.GetID()))
+			continue
+		}
+
+		if status.Status != pkgCluster.Running {
+			continue
+		}
+
+		if err := updateExternalDnsDomainFilters(commonCluster); err != nil {
+			errorHandler.Handle(emperror.With(err, "cluster", commonCluster.GetID()))
+			continue
+		}
+
+		logger.WithField("cluster", commonCluster.GetName()).Info("external-dns domain filters updated")
+	}
+}
+
+// updateExternalDnsDomainFilters updates the domains managed by the external-dns deployed to the cluster.
+func updateExternalDnsDomainFilters(commonCluster CommonCluster) error {
+	orgId := commonCluster.GetOrganizationId()
+
+	dnsSvc, err := dns.GetExternalDnsServiceClient()
+	if err != nil {
+		return emperror.Wrap(err, "getting external dns service client failed")
+	}
+
+	if dnsSvc == nil {
+		return nil
+	}
+
+	settings, err := dnsSvc.GetProviderSettings(orgId)
+	if err != nil {
+		return emperror.Wrap(err, "getting DNS provider settings failed")
+	}
+
+	if settings == nil {
+		return nil
+	}
+
+	domainFilters, err := externalDnsDomainFilters(orgId, settings.Domain)
+	if err != nil {
+		return err
+	}
+
+	values, err := yaml.Marshal(map[string]interface{}{
+		"domainFilters": domainFilters,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode external-dns values")
+	}
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "failed to get kubeconfig")
+	}
+
+	org, err := auth.GetOrganizationById(orgId)
+	if err != nil {
+		return emperror.Wrap(err, "failed to get organization")
+	}
+
+	_, err = helm.UpgradeDeployment(
+		externalDnsReleaseName,
+		pkgHelm.StableRepository+"/external-dns",
+		viper.GetString(config.DNSExternalDnsChartVersion),
+		nil,
+		values,
+		true,
+		kubeConfig,
+		helm.GenerateHelmRepoEnv(org.Name),
+	)
+	if err != nil {
+		return emperror.With(errors.WithMessage(err, "failed to upgrade external-dns"), "cluster", commonCluster.GetName())
+	}
+
+	return nil
+}
@@ -25,69 +25,95 @@ import (
 // HookMap for api hook endpoints
 var HookMap = map[string]PostFunctioner{
 	pkgCluster.StoreKubeConfig: &BasePostFunction{
+		name:         pkgCluster.StoreKubeConfig,
 		f:            StoreKubeConfig,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.SetupPrivileges: &BasePostFunction{
+		name:         pkgCluster.SetupPrivileges,
 		f:            SetupPrivileges,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallHelmPostHook: &BasePostFunction{
+		name:         pkgCluster.InstallHelmPostHook,
 		f:            InstallHelmPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallIngressControllerPostHook: &BasePostFunction{
+		name:         pkgCluster.InstallIngressControllerPostHook,
 		f:            InstallIngressControllerPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallKubernetesDashboardPostHook: &BasePostFunction{
+		name:         pkgCluster.InstallKubernetesDashboardPostHook,
 		f:            InstallKubernetesDashboardPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallClusterAutoscalerPostHook: &BasePostFunction{
+		name:         pkgCluster.InstallClusterAutoscalerPostHook,
 		f:            InstallClusterAutoscalerPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallHorizontalPodAutoscalerPostHook: &BasePostFunction{
+		name:         pkgCluster.InstallHorizontalPodAutoscalerPostHook,
 		f:            InstallHorizontalPodAutoscalerPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallMonitoring: &BasePostFunction{
+		name:         pkgCluster.InstallMonitoring,
 		f:            InstallMonitoring,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallLogging: &PostFunctionWithParam{
+		name:         pkgCluster.InstallLogging,
 		f:            InstallLogging,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.RegisterDomainPostHook: &BasePostFunction{
+		name:         pkgCluster.RegisterDomainPostHook,
 		f:            RegisterDomainPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
+	pkgCluster.InstallCertManagerPostHook: &BasePostFunction{
+		name:         pkgCluster.InstallCertManagerPostHook,
+		f:            InstallCertManagerPostHook,
+		ErrorHandler: ErrorHandler{},
+	},
 	pkgCluster.LabelNodes: &BasePostFunction{
+		name:         pkgCluster.LabelNodes,
 		f:            LabelNodes,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.TaintHeadNodes: &BasePostFunction{
+		name:         pkgCluster.TaintHeadNodes,
 		f:            TaintHeadNodes,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallPVCOperator: &BasePostFunction{
+		name:         pkgCluster.InstallPVCOperator,
 		f:            InstallPVCOperatorPostHook,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InstallAnchoreImageValidator: &BasePostFunction{
+		name:         pkgCluster.InstallAnchoreImageValidator,
 		f:            InstallAnchoreImageValidator,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.RestoreFromBackup: &PostFunctionWithParam{
+		name:         pkgCluster.RestoreFromBackup,
 		f:            RestoreFromBackup,
 		ErrorHandler: ErrorHandler{},
 	},
 	pkgCluster.InitSpotConfig: &BasePostFunction{
+		name:         pkgCluster.InitSpotConfig,
 		f:            InitSpotConfig,
 		ErrorHandler: ErrorHandler{},
 	},
+	pkgCluster.ApplyClusterTemplate: &PostFunctionWithParam{
+		name:         pkgCluster.ApplyClusterTemplate,
+		f:            ApplyClusterTemplate,
+		ErrorHandler: ErrorHandler{},
+	},
 }
 
 // BasePostHookFunctions default posthook functions after cluster create
@@ -98,6 +124,7 @@ var BasePostHookFunctions = []PostFunctioner{
 	HookMap[pkgCluster.TaintHeadNodes],
 	HookMap[pkgCluster.InstallHelmPostHook],
 	HookMap[pkgCluster.RegisterDomainPostHook],
+	HookMap[pkgCluster.InstallCertManagerPostHook],
 	HookMap[pkgCluster.InstallIngressControllerPostHook],
 	HookMap[pkgCluster.InstallKubernetesDashboardPostHook],
 	HookMap[pkgCluster.InstallClusterAutoscalerPostHook],
@@ -109,6 +136,7 @@ var BasePostHookFunctions = []PostFunctioner{
 
 // PostFunctioner manages posthook functions
 type PostFunctioner interface {
+	Name() string
 	Do(CommonCluster) error
 	Error(CommonCluster, error)
 }
@@ -123,17 +151,29 @@ func (*ErrorHandler) Error(c CommonCluster, err error) {
 
 // BasePostFunction describe a default posthook function
 type BasePostFunction struct {
-	f func(interface{}) error
+	name string
+	f    func(interface{}) error
 	ErrorHandler
 }
 
 // PostFunctionWithParam describes a posthook function with params
 type PostFunctionWithParam struct {
+	name   string
 	f      func(interface{}, pkgCluster.PostHookParam) error
 	params pkgCluster.PostHookParam
 	ErrorHandler
 }
 
+// Name returns the name of the posthook
+func (p *PostFunctionWithParam) Name() string {
+	return p.name
+}
+
+// Name returns the name of the posthook
+func (b *BasePostFunction) Name() string {
+	return b.name
+}
+
 // Do call function and pass CommonCluster and posthookParams
 func (p *PostFunctionWithParam) Do(cluster CommonCluster) error {
 	return p.f(cluster, p.params)
@@ -164,3 +204,8 @@ func (p *PostFunctionWithParam) String() string {
 func (p *PostFunctionWithParam) SetParams(params pkgCluster.PostHookParam) {
 	p.params = params
 }
+
+// GetParams returns posthook params
+func (p *PostFunctionWithParam) GetParams() pkgCluster.PostHookParam {
+	return p.params
+}
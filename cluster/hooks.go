@@ -24,12 +24,12 @@ import (
 	"github.com/banzaicloud/pipeline/auth"
 	pipConfig "github.com/banzaicloud/pipeline/config"
 	"github.com/banzaicloud/pipeline/dns"
-	"github.com/banzaicloud/pipeline/dns/route53"
 	"github.com/banzaicloud/pipeline/helm"
 	"github.com/banzaicloud/pipeline/internal/ark"
 	arkAPI "github.com/banzaicloud/pipeline/internal/ark/api"
 	"github.com/banzaicloud/pipeline/internal/providers/azure"
 	"github.com/banzaicloud/pipeline/internal/security"
+	pkgAlerting "github.com/banzaicloud/pipeline/pkg/alerting"
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
 	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
@@ -53,38 +53,30 @@ import (
 )
 
 //RunPostHooks calls posthook functions with created cluster
+// The state of every posthook is persisted, so an interrupted run can be resumed later.
 func RunPostHooks(postHooks []PostFunctioner, cluster CommonCluster) (err error) {
 
 	log := log.WithFields(logrus.Fields{"cluster": cluster.GetName(), "org": cluster.GetOrganizationId()})
 
-	for _, postHook := range postHooks {
-		if postHook != nil {
-			log.Infof("Start posthook function[%s]", postHook)
-			err = postHook.Do(cluster)
-			if err != nil {
-				log.Errorf("Error during posthook function[%s]: %s", postHook, err.Error())
-				postHook.Error(cluster, err)
-				return
-			}
-
-			statusMsg := fmt.Sprintf("Posthook function finished: %s", postHook)
-			err = cluster.UpdateStatus(pkgCluster.Creating, statusMsg)
-			if err != nil {
-				log.Errorf("Error during posthook status update in db [%s]: %s", postHook, err.Error())
-				return
-			}
-		}
+	if !runningPostHooks.acquire(cluster.GetID()) {
+		log.Warn("posthooks are already running for cluster")
+		return ErrPostHooksRunning
 	}
+	defer runningPostHooks.release(cluster.GetID())
 
-	log.Info("Run all posthooks for cluster successfully.")
-
-	err = cluster.UpdateStatus(pkgCluster.Running, pkgCluster.RunningMessage)
+	postHookModels, err := newPostHookModels(postHooks)
+	if err != nil {
+		log.Errorf("Error during preparing posthooks: %s", err.Error())
+		return
+	}
 
+	err = getPostHookRepository().ReplaceByClusterID(cluster.GetID(), postHookModels)
 	if err != nil {
-		log.Errorf("Error during posthook status update in db: %s", err.Error())
+		log.Errorf("Error during persisting posthooks: %s", err.Error())
+		return
 	}
 
-	return
+	return executePostHooks(cluster)
 }
 
 // PollingKubernetesConfig polls kubeconfig from the cloud
@@ -218,6 +210,10 @@ func InstallMonitoring(input interface{}) error {
 				"affinity":    getHeadNodeAffinity(cluster),
 				"tolerations": getHeadNodeTolerations(),
 			},
+			// the default cluster health alerts, the alerting API overrides them with the configured rules
+			"serverFiles": map[string]interface{}{
+				"alerts": pkgAlerting.RenderRules(pkgAlerting.Config{}, cluster.GetName()),
+			},
 		},
 	}
 	grafanaValuesJson, err := yaml.Marshal(grafanaValues)
@@ -537,6 +533,36 @@ func installDeployment(cluster CommonCluster, namespace string, deploymentName s
 	return nil
 }
 
+// UpgradeReleaseValues merges the values into a release of the cluster installed from the Banzai Cloud repository,
+// keeping the chart version of the release.
+func UpgradeReleaseValues(cluster CommonCluster, deployment *pkgHelm.GetDeploymentResponse, values map[string]interface{}, kubeConfig []byte) error {
+	org, err := auth.GetOrganizationById(cluster.GetOrganizationId())
+	if err != nil {
+		return emperror.Wrap(err, "could not get organization")
+	}
+
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return emperror.Wrap(err, "could not marshal release values")
+	}
+
+	_, err = helm.UpgradeDeployment(
+		deployment.ReleaseName,
+		pkgHelm.BanzaiRepository+"/"+deployment.ChartName,
+		deployment.ChartVersion,
+		nil,
+		valuesYAML,
+		true,
+		kubeConfig,
+		helm.GenerateHelmRepoEnv(org.Name),
+	)
+	if err != nil {
+		return emperror.With(emperror.Wrap(err, "could not upgrade release"), "release", deployment.ReleaseName)
+	}
+
+	return nil
+}
+
 type treafikSslConfig struct {
 	Enabled        bool     `json:"enabled"`
 	GenerateTLS    bool     `json:"generateTLS"`
@@ -971,7 +997,7 @@ func RegisterDomainPostHook(input interface{}) error {
 	}
 
 	domainBase := viper.GetString(pipConfig.DNSBaseDomain)
-	route53SecretNamespace := viper.GetString(pipConfig.PipelineSystemNamespace)
+	secretNamespace := viper.GetString(pipConfig.PipelineSystemNamespace)
 
 	orgId := commonCluster.GetOrganizationId()
 
@@ -1005,39 +1031,51 @@ func RegisterDomainPostHook(input interface{}) error {
 		log.Infof("Domain '%s' already registered", domain)
 	}
 
-	route53Secret, err := secret.Store.GetByName(orgId, route53.IAMUserAccessKeySecretName)
+	settings, err := dnsSvc.GetProviderSettings(orgId)
 	if err != nil {
-		return emperror.Wrap(err, "Failed to install route53 secret into cluster")
+		return emperror.Wrap(err, "Getting DNS provider settings failed")
+	}
+
+	if settings == nil {
+		return errors.Errorf("Domain '%s' is not registered", domain)
+	}
+
+	providerSecret, err := secret.Store.Get(orgId, settings.SecretID)
+	if err != nil {
+		return emperror.Wrapf(err, "Failed to install %s secret into cluster", settings.Provider)
 	}
 	_, err = InstallSecrets(
 		commonCluster,
 		&pkgSecret.ListSecretsQuery{
-			Type: pkgCluster.Amazon,
-			IDs:  []string{route53Secret.ID},
+			Type: providerSecret.Type,
+			IDs:  []string{providerSecret.ID},
 		},
-		route53SecretNamespace,
+		secretNamespace,
 	)
 	if err != nil {
-		return emperror.Wrap(err, "Failed to install route53 secret into cluster")
+		return emperror.Wrapf(err, "Failed to install %s secret into cluster", settings.Provider)
 	}
 
-	log.Info("route53 secret successfully installed into cluster.")
+	log.Infof("%s secret successfully installed into cluster.", settings.Provider)
 
-	externalDnsValues := map[string]interface{}{
-		"rbac": map[string]bool{
-			"create": commonCluster.RbacEnabled() == true,
-		},
-		"aws": map[string]string{
-			"secretKey": route53Secret.Values[pkgSecret.AwsSecretAccessKey],
-			"accessKey": route53Secret.Values[pkgSecret.AwsAccessKeyId],
-			"region":    route53Secret.Values[pkgSecret.AwsRegion],
-		},
-		"domainFilters": []string{domain},
-		"policy":        "sync",
-		"txtOwnerId":    commonCluster.GetUID(),
-		"affinity":      getHeadNodeAffinity(commonCluster),
-		"tolerations":   getHeadNodeTolerations(),
+	externalDnsValues, err := externalDnsProviderValues(settings, providerSecret.Values)
+	if err != nil {
+		return emperror.Wrap(err, "Failed to configure external-dns")
+	}
+
+	domainFilters, err := externalDnsDomainFilters(orgId, settings.Domain)
+	if err != nil {
+		return emperror.Wrap(err, "Failed to configure external-dns")
+	}
+
+	externalDnsValues["rbac"] = map[string]bool{
+		"create": commonCluster.RbacEnabled() == true,
 	}
+	externalDnsValues["domainFilters"] = domainFilters
+	externalDnsValues["policy"] = "sync"
+	externalDnsValues["txtOwnerId"] = commonCluster.GetUID()
+	externalDnsValues["affinity"] = getHeadNodeAffinity(commonCluster)
+	externalDnsValues["tolerations"] = getHeadNodeTolerations()
 
 	externalDnsValuesJson, err := yaml.Marshal(externalDnsValues)
 	if err != nil {
@@ -1045,7 +1083,7 @@ func RegisterDomainPostHook(input interface{}) error {
 	}
 	chartVersion := viper.GetString(pipConfig.DNSExternalDnsChartVersion)
 
-	return installDeployment(commonCluster, route53SecretNamespace, pkgHelm.StableRepository+"/external-dns", "dns", externalDnsValuesJson, chartVersion, false)
+	return installDeployment(commonCluster, secretNamespace, pkgHelm.StableRepository+"/external-dns", externalDnsReleaseName, externalDnsValuesJson, chartVersion, false)
 }
 
 // LabelNodes adds labels for all nodes
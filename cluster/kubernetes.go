@@ -16,13 +16,16 @@ package cluster
 
 import (
 	"encoding/base64"
+	"strings"
 
 	"github.com/banzaicloud/pipeline/config"
 	"github.com/banzaicloud/pipeline/model"
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
 	"github.com/banzaicloud/pipeline/secret"
+	"github.com/goph/emperror"
 	"gopkg.in/yaml.v2"
 	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -69,7 +72,53 @@ func (c *KubeCluster) CreateCluster() error {
 		return err
 	}
 
-	return nil
+	return c.importCluster()
+}
+
+// importCluster validates the kubeconfig, then discovers and persists the provider and the node pools of the cluster
+func (c *KubeCluster) importCluster() error {
+	kubeConfig, err := c.GetK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "could not get kubeconfig")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "invalid kubeconfig")
+	}
+
+	health, nodes, err := checkHealth(client)
+	if err != nil {
+		return emperror.Wrap(err, "could not access the cluster")
+	}
+
+	if !health.Healthy {
+		log.Warnf("imported cluster %s is not healthy: %v", c.GetName(), health.Problems)
+	}
+
+	c.modelCluster.Kubernetes.Provider = detectProvider(nodes)
+	if c.modelCluster.Location == "" {
+		c.modelCluster.Location = detectLocation(nodes)
+	}
+
+	tillerPreinstalled, releaseNames, err := findPreinstalledHelm(client, kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "could not discover helm releases")
+	}
+
+	c.modelCluster.Kubernetes.TillerPreinstalled = tillerPreinstalled
+	c.modelCluster.Kubernetes.PreinstalledReleases = strings.Join(releaseNames, ",")
+
+	c.modelCluster.Kubernetes.NodePools = nil
+	for _, nodePool := range discoverNodePools(nodes) {
+		c.modelCluster.Kubernetes.NodePools = append(c.modelCluster.Kubernetes.NodePools, &model.KubernetesNodePoolModel{
+			Name:             nodePool.name,
+			Count:            len(nodePool.nodeNames),
+			NodeInstanceType: nodePool.instanceType,
+		})
+	}
+
+	return c.modelCluster.Save()
 }
 
 // Persist save the cluster model
@@ -131,6 +180,16 @@ func (c *KubeCluster) GetStatus() (*pkgCluster.GetClusterStatusResponse, error)
 		db.Find(&c.modelCluster, model.ClusterModel{ID: c.GetID()})
 	}
 
+	nodePools := make(map[string]*pkgCluster.NodePoolStatus)
+	for _, np := range c.modelCluster.Kubernetes.NodePools {
+		if np != nil {
+			nodePools[np.Name] = &pkgCluster.NodePoolStatus{
+				Count:        np.Count,
+				InstanceType: np.NodeInstanceType,
+			}
+		}
+	}
+
 	return &pkgCluster.GetClusterStatusResponse{
 		Status:            c.modelCluster.Status,
 		StatusMessage:     c.modelCluster.StatusMessage,
@@ -138,9 +197,10 @@ func (c *KubeCluster) GetStatus() (*pkgCluster.GetClusterStatusResponse, error)
 		Location:          c.modelCluster.Location,
 		Cloud:             pkgCluster.Kubernetes,
 		Distribution:      c.modelCluster.Distribution,
+		Provider:          c.modelCluster.Kubernetes.Provider,
 		ResourceID:        c.modelCluster.ID,
 		CreatorBaseFields: *NewCreatorBaseFields(c.modelCluster.CreatedAt, c.modelCluster.CreatedBy),
-		NodePools:         nil,
+		NodePools:         nodePools,
 	}, nil
 }
 
@@ -245,8 +305,23 @@ func (c *KubeCluster) UpdateStatus(status, statusMessage string) error {
 	return c.modelCluster.UpdateStatus(status, statusMessage)
 }
 
+// GetPreinstalledHelm returns whether Tiller was running in the cluster before the import and the releases it managed then.
+func (c *KubeCluster) GetPreinstalledHelm() (bool, []string) {
+	var releaseNames []string
+	if c.modelCluster.Kubernetes.PreinstalledReleases != "" {
+		releaseNames = strings.Split(c.modelCluster.Kubernetes.PreinstalledReleases, ",")
+	}
+
+	return c.modelCluster.Kubernetes.TillerPreinstalled, releaseNames
+}
+
 // NodePoolExists returns true if node pool with nodePoolName exists
 func (c *KubeCluster) NodePoolExists(nodePoolName string) bool {
+	for _, np := range c.modelCluster.Kubernetes.NodePools {
+		if np != nil && np.Name == nodePoolName {
+			return true
+		}
+	}
 	return false
 }
 
@@ -263,6 +338,15 @@ func (c *KubeCluster) GetClusterDetails() (*pkgCluster.DetailsResponse, error) {
 
 // ValidateCreationFields validates all field
 func (c *KubeCluster) ValidateCreationFields(r *pkgCluster.CreateClusterRequest) error {
+	kubeConfig, err := c.DownloadK8sConfig()
+	if err != nil {
+		return emperror.Wrap(err, "could not get kubeconfig")
+	}
+
+	if _, err := k8sclient.NewClientConfig(kubeConfig); err != nil {
+		return emperror.Wrap(err, "invalid kubeconfig")
+	}
+
 	return nil
 }
 
@@ -288,6 +372,26 @@ func (c *KubeCluster) GetK8sConfig() ([]byte, error) {
 
 // ListNodeNames returns node names to label them
 func (c *KubeCluster) ListNodeNames() (nodeNames pkgCommon.NodeNames, err error) {
+	kubeConfig, err := c.GetK8sConfig()
+	if err != nil {
+		return
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return
+	}
+
+	nodeNames = make(pkgCommon.NodeNames)
+	for _, nodePool := range discoverNodePools(nodes.Items) {
+		nodeNames[nodePool.name] = nodePool.nodeNames
+	}
+
 	return
 }
 
@@ -0,0 +1,293 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/banzaicloud/pipeline/helm"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/goph/emperror"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	defaultImportedNodePoolName = "default"
+
+	instanceTypeLabelKey = "beta.kubernetes.io/instance-type"
+	regionLabelKey       = "failure-domain.beta.kubernetes.io/region"
+)
+
+// nodePoolLabelKeys are the node labels marking the node pool of a node in order of precedence:
+// the one set by Pipeline first, then the ones set by the managed Kubernetes providers and installers.
+var nodePoolLabelKeys = []string{
+	pkgCommon.LabelKey,
+	"cloud.google.com/gke-nodepool",
+	"agentpool",
+	"eks.amazonaws.com/nodegroup",
+	"alpha.eksctl.io/nodegroup-name",
+	"doks.digitalocean.com/node-pool",
+	"kops.k8s.io/instancegroup",
+}
+
+// providerIDPrefixes maps the scheme of node provider IDs to cloud providers.
+var providerIDPrefixes = map[string]string{
+	"aws":          pkgCluster.Amazon,
+	"azure":        pkgCluster.Azure,
+	"gce":          pkgCluster.Google,
+	"digitalocean": pkgCluster.DigitalOcean,
+	"oci":          pkgCluster.Oracle,
+	"alicloud":     pkgCluster.Alibaba,
+}
+
+// ImportedClusterPostHookFunctions are the default posthooks of imported clusters.
+// Anything else has to be requested explicitly as the cluster is managed outside of Pipeline.
+var ImportedClusterPostHookFunctions = []PostFunctioner{
+	HookMap[pkgCluster.StoreKubeConfig],
+	HookMap[pkgCluster.SetupPrivileges],
+	HookMap[pkgCluster.LabelNodes],
+	HookMap[pkgCluster.InstallHelmPostHook],
+}
+
+// GetBasePostHookFunctions returns the posthooks executed on every cluster of the given kind.
+func GetBasePostHookFunctions(cluster CommonCluster) []PostFunctioner {
+	if cluster.GetCloud() == pkgCluster.Kubernetes {
+		return ImportedClusterPostHookFunctions
+	}
+
+	return BasePostHookFunctions
+}
+
+// discoveredNodePool is a group of nodes found in an imported cluster.
+type discoveredNodePool struct {
+	name         string
+	instanceType string
+	nodeNames    []string
+}
+
+// getNodePoolName returns the node pool of a node based on its labels.
+func getNodePoolName(node corev1.Node) string {
+	for _, key := range nodePoolLabelKeys {
+		if name := node.Labels[key]; name != "" {
+			return name
+		}
+	}
+
+	return defaultImportedNodePoolName
+}
+
+// discoverNodePools groups the nodes of a cluster into node pools ordered by name.
+func discoverNodePools(nodes []corev1.Node) []discoveredNodePool {
+	nodePools := make(map[string]*discoveredNodePool)
+
+	for _, node := range nodes {
+		name := getNodePoolName(node)
+
+		nodePool, ok := nodePools[name]
+		if !ok {
+			nodePool = &discoveredNodePool{name: name}
+			nodePools[name] = nodePool
+		}
+
+		if nodePool.instanceType == "" {
+			nodePool.instanceType = node.Labels[instanceTypeLabelKey]
+		}
+		nodePool.nodeNames = append(nodePool.nodeNames, node.Name)
+	}
+
+	result := make([]discoveredNodePool, 0, len(nodePools))
+	for _, nodePool := range nodePools {
+		sort.Strings(nodePool.nodeNames)
+		result = append(result, *nodePool)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+
+	return result
+}
+
+// detectProvider returns the cloud provider of a cluster based on the provider IDs of its nodes.
+func detectProvider(nodes []corev1.Node) string {
+	for _, node := range nodes {
+		parts := strings.SplitN(node.Spec.ProviderID, "://", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		if provider, ok := providerIDPrefixes[parts[0]]; ok {
+			return provider
+		}
+	}
+
+	return pkgCluster.Unknown
+}
+
+// detectLocation returns the region of a cluster based on the labels of its nodes.
+func detectLocation(nodes []corev1.Node) string {
+	for _, node := range nodes {
+		if region := node.Labels[regionLabelKey]; region != "" {
+			return region
+		}
+	}
+
+	return ""
+}
+
+func isNodeReady(node corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+// checkHealth checks whether the Kubernetes API is reachable and the nodes are ready.
+// The returned error means the API itself is not accessible.
+func checkHealth(client kubernetes.Interface) (*pkgCluster.HealthResponse, []corev1.Node, error) {
+	version, err := client.Discovery().ServerVersion()
+	if err != nil {
+		return nil, nil, emperror.Wrap(err, "could not get Kubernetes version")
+	}
+
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, emperror.Wrap(err, "could not list nodes")
+	}
+
+	health := &pkgCluster.HealthResponse{
+		Version: version.GitVersion,
+		Nodes:   len(nodes.Items),
+	}
+
+	if len(nodes.Items) == 0 {
+		health.Problems = append(health.Problems, "cluster has no nodes")
+	}
+
+	for _, node := range nodes.Items {
+		if isNodeReady(node) {
+			health.ReadyNodes++
+		} else {
+			health.Problems = append(health.Problems, fmt.Sprintf("node %s is not ready", node.Name))
+		}
+	}
+
+	health.Healthy = len(health.Problems) == 0
+
+	return health, nodes.Items, nil
+}
+
+// CheckClusterHealth checks whether the Kubernetes API of a cluster is reachable and its nodes are ready.
+func CheckClusterHealth(cluster CommonCluster) (*pkgCluster.HealthResponse, error) {
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		return nil, emperror.Wrap(err, "could not get kubeconfig")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, emperror.Wrap(err, "could not create Kubernetes client")
+	}
+
+	health, _, err := checkHealth(client)
+	if err != nil {
+		return &pkgCluster.HealthResponse{Problems: []string{err.Error()}}, nil
+	}
+
+	return health, nil
+}
+
+// removeNodePoolLabels removes the node pool label added by the LabelNodes posthook.
+func removeNodePoolLabels(client kubernetes.Interface) error {
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return emperror.Wrap(err, "could not list nodes")
+	}
+
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:null}}}`, pkgCommon.LabelKey)
+
+	for _, node := range nodes.Items {
+		if _, ok := node.Labels[pkgCommon.LabelKey]; !ok {
+			continue
+		}
+
+		_, err := client.CoreV1().Nodes().Patch(node.Name, types.MergePatchType, []byte(patch))
+		if err != nil {
+			return emperror.Wrapf(err, "could not remove label from node %s", node.Name)
+		}
+	}
+
+	return nil
+}
+
+// tillerLabels are the labels of the Tiller pods created by helm init and by the InstallHelmPostHook.
+var tillerLabels = labels.Set{"app": "helm", "name": "tiller"}
+
+// findPreinstalledHelm returns whether Tiller is running in an imported cluster and the names of the releases it manages.
+func findPreinstalledHelm(client kubernetes.Interface, kubeConfig []byte) (bool, []string, error) {
+	pods, err := client.CoreV1().Pods("kube-system").List(metav1.ListOptions{LabelSelector: tillerLabels.String()})
+	if err != nil {
+		return false, nil, emperror.Wrap(err, "could not list tiller pods")
+	}
+
+	if len(pods.Items) == 0 {
+		return false, nil, nil
+	}
+
+	filter := ""
+	releases, err := helm.ListDeployments(&filter, "", kubeConfig)
+	if err != nil {
+		return true, nil, emperror.Wrap(err, "could not list helm releases")
+	}
+
+	var releaseNames []string
+	if releases != nil {
+		for _, release := range releases.Releases {
+			releaseNames = append(releaseNames, release.Name)
+		}
+	}
+
+	sort.Strings(releaseNames)
+
+	return true, releaseNames, nil
+}
+
+// installedByPipeline returns the releases that were not present when the cluster was imported.
+func installedByPipeline(releaseNames []string, preinstalledReleases []string) []string {
+	preinstalled := make(map[string]bool, len(preinstalledReleases))
+	for _, name := range preinstalledReleases {
+		preinstalled[name] = true
+	}
+
+	var names []string
+	for _, name := range releaseNames {
+		if !preinstalled[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
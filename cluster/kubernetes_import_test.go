@@ -0,0 +1,103 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestNode(name, providerID string, labels map[string]string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{ProviderID: providerID},
+	}
+}
+
+func TestDiscoverNodePools(t *testing.T) {
+	nodes := []corev1.Node{
+		newTestNode("gke-2", "", map[string]string{"cloud.google.com/gke-nodepool": "pool1", instanceTypeLabelKey: "n1-standard-2"}),
+		newTestNode("gke-1", "", map[string]string{"cloud.google.com/gke-nodepool": "pool1"}),
+		newTestNode("labelled", "", map[string]string{pkgCommon.LabelKey: "head", "agentpool": "agents"}),
+		newTestNode("plain", "", nil),
+	}
+
+	expected := []discoveredNodePool{
+		{name: defaultImportedNodePoolName, nodeNames: []string{"plain"}},
+		{name: "head", nodeNames: []string{"labelled"}},
+		{name: "pool1", instanceType: "n1-standard-2", nodeNames: []string{"gke-1", "gke-2"}},
+	}
+
+	nodePools := discoverNodePools(nodes)
+	if !reflect.DeepEqual(nodePools, expected) {
+		t.Errorf("expected %+v, got %+v", expected, nodePools)
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	tests := map[string]struct {
+		nodes    []corev1.Node
+		provider string
+	}{
+		"amazon": {
+			nodes:    []corev1.Node{newTestNode("node", "aws:///eu-west-1a/i-0123456789", nil)},
+			provider: pkgCluster.Amazon,
+		},
+		"google": {
+			nodes:    []corev1.Node{newTestNode("node", "gce://project/europe-west1-b/node", nil)},
+			provider: pkgCluster.Google,
+		},
+		"first known wins": {
+			nodes: []corev1.Node{
+				newTestNode("bare", "", nil),
+				newTestNode("node", "azure:///subscriptions/id/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/node", nil),
+			},
+			provider: pkgCluster.Azure,
+		},
+		"unknown": {
+			nodes:    []corev1.Node{newTestNode("node", "kind://docker/kind/node", nil)},
+			provider: pkgCluster.Unknown,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if provider := detectProvider(test.nodes); provider != test.provider {
+				t.Errorf("expected %s, got %s", test.provider, provider)
+			}
+		})
+	}
+}
+
+func TestInstalledByPipeline(t *testing.T) {
+	releaseNames := []string{"dns", "ingress", "monitor", "user-app"}
+	preinstalled := []string{"ingress", "user-app", "removed-since-import"}
+
+	expected := []string{"dns", "monitor"}
+
+	names := installedByPipeline(releaseNames, preinstalled)
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	if names := installedByPipeline(releaseNames, nil); !reflect.DeepEqual(names, releaseNames) {
+		t.Errorf("expected every release to be deleted without preinstalled releases, got %v", names)
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"strings"
+
+	"github.com/banzaicloud/pipeline/config"
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func getLabelRepository() *intCluster.Labels {
+	return intCluster.NewLabels(config.DB())
+}
+
+// GetClusterLabels returns the labels of a cluster.
+func GetClusterLabels(cluster CommonCluster) (map[string]string, error) {
+	return getLabelRepository().FindByClusterID(cluster.GetID())
+}
+
+// SetClusterLabels replaces the labels of a cluster.
+// Label keys and values follow the syntax of Kubernetes labels.
+func SetClusterLabels(cluster CommonCluster, labels map[string]string) error {
+	for key, value := range labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return &invalidError{errors.Errorf("invalid label key %q: %s", key, strings.Join(errs, "; "))}
+		}
+
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return &invalidError{errors.Errorf("invalid value of label %q: %s", key, strings.Join(errs, "; "))}
+		}
+	}
+
+	return getLabelRepository().ReplaceByClusterID(cluster.GetID(), labels)
+}
+
+func deleteClusterLabels(cluster CommonCluster) error {
+	return getLabelRepository().DeleteByClusterID(cluster.GetID())
+}
@@ -150,12 +150,13 @@ func (m *Manager) createCluster(
 	err := creator.Create(ctx)
 	if err != nil {
 		cluster.UpdateStatus(pkgCluster.Error, err.Error())
+		m.events.ClusterCreationFailed(cluster.GetID(), err.Error())
 		return err
 	}
 
 	// Apply PostHooks
 	// These are hardcoded posthooks maybe we will want a bit more dynamic
-	postHookFunctions := BasePostHookFunctions
+	postHookFunctions := GetBasePostHookFunctions(cluster)
 
 	if postHooks != nil && len(postHooks) != 0 {
 		postHookFunctions = append(postHookFunctions, postHooks...)
@@ -164,6 +165,7 @@ func (m *Manager) createCluster(
 	err = RunPostHooks(postHookFunctions, cluster)
 
 	if err != nil {
+		m.events.ClusterPostHooksFailed(cluster.GetID(), err.Error())
 		return errors.Wrap(err, "error during running cluster posthooks")
 	}
 
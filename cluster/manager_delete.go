@@ -18,8 +18,10 @@ import (
 	"context"
 	"time"
 
+	"github.com/banzaicloud/pipeline/config"
 	"github.com/banzaicloud/pipeline/dns"
 	"github.com/banzaicloud/pipeline/helm"
+	"github.com/banzaicloud/pipeline/internal/domain"
 	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
 	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	"github.com/goph/emperror"
@@ -244,6 +246,11 @@ func deleteDnsRecordsOwnedByCluster(cluster CommonCluster) error {
 		return emperror.Wrapf(err, "deleting DNS records owned by cluster failed")
 	}
 
+	err = domain.DeleteRecordsOwnedBy(domain.NewRepository(config.DB()), dnsSvc, cluster.GetOrganizationId(), cluster.GetUID())
+	if err != nil {
+		return emperror.Wrapf(err, "deleting DNS records owned by cluster from custom domains failed")
+	}
+
 	return nil
 }
 
@@ -335,6 +342,24 @@ func (m *Manager) deleteCluster(ctx context.Context, cluster CommonCluster, forc
 		logger.Error(err)
 	}
 
+	// clean posthook states
+	err = deletePostHooks(cluster)
+	if err != nil {
+		logger.Error(emperror.Wrap(err, "failed to delete posthook states"))
+	}
+
+	// clean cluster template binding
+	err = deleteClusterTemplateBinding(cluster)
+	if err != nil {
+		logger.Error(emperror.Wrap(err, "failed to delete cluster template binding"))
+	}
+
+	// clean cluster labels
+	err = deleteClusterLabels(cluster)
+	if err != nil {
+		logger.Error(emperror.Wrap(err, "failed to delete cluster labels"))
+	}
+
 	// clean statestore
 	logger.Info("cleaning cluster's statestore folder")
 	if err := CleanStateStore(deleteName); err != nil {
@@ -0,0 +1,209 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	pipConfig "github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/helm"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const detachingMessage = "Cluster is detaching"
+
+// DetachCluster removes the components installed by Pipeline from an imported cluster
+// and removes the cluster from Pipeline without deleting it.
+func (m *Manager) DetachCluster(ctx context.Context, cluster CommonCluster, force bool) error {
+	if cluster.GetCloud() != pkgCluster.Kubernetes {
+		return errors.WithStack(&invalidError{errors.New("only imported clusters can be detached")})
+	}
+
+	errorHandler := emperror.HandlerWith(
+		m.getErrorHandler(ctx),
+		"organization", cluster.GetOrganizationId(),
+		"cluster", cluster.GetID(),
+		"force", force,
+	)
+
+	go func() {
+		defer emperror.HandleRecover(m.errorHandler)
+
+		err := m.detachCluster(ctx, cluster, force)
+		if err != nil {
+			errorHandler.Handle(err)
+		}
+	}()
+
+	return nil
+}
+
+func (m *Manager) detachCluster(ctx context.Context, cluster CommonCluster, force bool) error {
+	logger := m.getLogger(ctx).WithFields(logrus.Fields{
+		"organization": cluster.GetOrganizationId(),
+		"cluster":      cluster.GetName(),
+		"force":        force,
+	})
+
+	logger.Info("detaching cluster")
+
+	err := cluster.UpdateStatus(pkgCluster.Deleting, detachingMessage)
+	if err != nil {
+		return emperror.With(
+			emperror.Wrap(err, "cluster status update failed"),
+			"cluster_id", cluster.GetID(),
+		)
+	}
+
+	// fail is a helper for the steps that can be ignored in force mode
+	fail := func(err error) error {
+		if !force {
+			cluster.UpdateStatus(pkgCluster.Error, err.Error())
+			return err
+		}
+		logger.Error(err)
+
+		return nil
+	}
+
+	kubeConfig, err := cluster.GetK8sConfig()
+	if err != nil {
+		if err := fail(emperror.Wrap(err, "cannot access Kubernetes cluster")); err != nil {
+			return err
+		}
+	}
+
+	if kubeConfig != nil {
+		var tillerPreinstalled bool
+		var preinstalledReleases []string
+		if kubeCluster, ok := cluster.(*KubeCluster); ok {
+			tillerPreinstalled, preinstalledReleases = kubeCluster.GetPreinstalledHelm()
+		}
+
+		if err := removePipelineComponents(kubeConfig, tillerPreinstalled, preinstalledReleases, logger); err != nil {
+			if err := fail(err); err != nil {
+				return err
+			}
+		}
+	} else {
+		logger.Info("skipping component removal as kubeconfig is not available.")
+	}
+
+	// clean up dns registrations
+	err = deleteDnsRecordsOwnedByCluster(cluster)
+	if err != nil {
+		logger.Error(emperror.Wrap(err, "failed to delete cluster's DNS records"))
+	}
+
+	m.DeleteKubeProxy(cluster)
+
+	orgID := cluster.GetOrganizationId()
+	clusterName := cluster.GetName()
+
+	err = deletePostHooks(cluster)
+	if err != nil {
+		logger.Error(emperror.Wrap(err, "failed to delete posthook states"))
+	}
+
+	err = deleteClusterTemplateBinding(cluster)
+	if err != nil {
+		logger.Error(emperror.Wrap(err, "failed to delete cluster template binding"))
+	}
+
+	err = deleteClusterLabels(cluster)
+	if err != nil {
+		logger.Error(emperror.Wrap(err, "failed to delete cluster labels"))
+	}
+
+	err = cluster.DeleteFromDatabase()
+	if err != nil {
+		if err := fail(emperror.Wrap(err, "failed to delete from the database")); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("cleaning cluster's statestore folder")
+	if err := CleanStateStore(clusterName); err != nil {
+		return emperror.Wrap(err, "cleaning cluster statestore failed")
+	}
+
+	logger.Info("cluster detached successfully")
+
+	m.events.ClusterDeleted(orgID, clusterName)
+
+	return nil
+}
+
+// removePipelineComponents deletes the deployments, Tiller, the system namespace and the node labels installed by Pipeline.
+// The releases and the Tiller found in the cluster when it was imported are left in place.
+func removePipelineComponents(kubeConfig []byte, tillerPreinstalled bool, preinstalledReleases []string, logger logrus.FieldLogger) error {
+	logger.Info("deleting deployments")
+	filter := ""
+	releases, err := helm.ListDeployments(&filter, "", kubeConfig)
+	if err != nil {
+		return emperror.Wrap(err, "failed to list deployments")
+	}
+
+	var releaseNames []string
+	if releases != nil {
+		for _, release := range releases.Releases {
+			releaseNames = append(releaseNames, release.Name)
+		}
+	}
+
+	for _, releaseName := range installedByPipeline(releaseNames, preinstalledReleases) {
+		logger.Infof("deleting deployment %q", releaseName)
+		if err := helm.DeleteDeployment(releaseName, kubeConfig); err != nil {
+			return emperror.Wrapf(err, "failed to delete deployment %q", releaseName)
+		}
+	}
+
+	if tillerPreinstalled {
+		logger.Info("leaving the preinstalled tiller in place")
+	} else {
+		logger.Info("removing tiller")
+		err := helm.Uninstall(&pkgHelm.Install{Namespace: "kube-system", ServiceAccount: "tiller"}, kubeConfig)
+		if err != nil {
+			return emperror.Wrap(err, "failed to remove tiller")
+		}
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	namespace := viper.GetString(pipConfig.PipelineSystemNamespace)
+	logger.Infof("deleting kubernetes namespace %q", namespace)
+	err = client.CoreV1().Namespaces().Delete(namespace, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return emperror.Wrapf(err, "failed to delete %q namespace", namespace)
+	}
+
+	logger.Info("removing node labels")
+	if err := removeNodePoolLabels(client); err != nil {
+		return emperror.Wrap(err, "failed to remove node labels")
+	}
+
+	return nil
+}
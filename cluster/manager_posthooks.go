@@ -0,0 +1,90 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ResumePostHooks continues the posthook runs which were interrupted (eg. by a Pipeline restart).
+func (m *Manager) ResumePostHooks(ctx context.Context) error {
+	logger := m.getLogger(ctx)
+
+	clusterIDs, err := getPostHookRepository().FindUnfinishedClusterIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, clusterID := range clusterIDs {
+		logger := logger.WithField("cluster", clusterID)
+
+		cluster, err := m.GetClusterByIDOnly(ctx, clusterID)
+		if err != nil {
+			m.errorHandler.Handle(emperror.With(errors.Wrap(err, "could not resume posthooks"), "cluster", clusterID))
+
+			continue
+		}
+
+		status, err := cluster.GetStatus()
+		if err != nil {
+			m.errorHandler.Handle(emperror.With(errors.Wrap(err, "could not resume posthooks"), "cluster", clusterID))
+
+			continue
+		}
+
+		// posthooks which stopped because of an error are resumed by the user explicitly
+		if status.Status != pkgCluster.Creating {
+			logger.Debugf("skip resuming posthooks of cluster in %s state", status.Status)
+
+			continue
+		}
+
+		if !runningPostHooks.acquire(clusterID) {
+			continue
+		}
+
+		logger.Info("resuming interrupted posthooks")
+
+		go m.resumePostHooks(cluster, logger)
+	}
+
+	return nil
+}
+
+func (m *Manager) resumePostHooks(cluster CommonCluster, logger logrus.FieldLogger) {
+	if err := resumePostHooks(cluster); err != nil {
+		logger.Errorf("failed to resume posthooks: %s", err.Error())
+		m.events.ClusterPostHooksFailed(cluster.GetID(), err.Error())
+		return
+	}
+
+	m.events.ClusterCreated(cluster.GetID())
+}
+
+// RunPostHooks runs the given posthooks of an existing cluster.
+func (m *Manager) RunPostHooks(cluster CommonCluster, postHooks []PostFunctioner) {
+	if err := RunPostHooks(postHooks, cluster); err != nil {
+		m.getLogger(context.Background()).WithField("cluster", cluster.GetID()).Errorf("failed to run posthooks: %s", err.Error())
+
+		if err != ErrPostHooksRunning {
+			m.events.ClusterPostHooksFailed(cluster.GetID(), err.Error())
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/pkg/errors"
+)
+
+// postHookDependencies describes the posthooks which have to finish before a posthook can start.
+// Posthooks not listed here install deployments, so they depend on Tiller only.
+var postHookDependencies = map[string][]string{
+	pkgCluster.StoreKubeConfig:     {},
+	pkgCluster.SetupPrivileges:     {pkgCluster.StoreKubeConfig},
+	pkgCluster.LabelNodes:          {pkgCluster.StoreKubeConfig},
+	pkgCluster.TaintHeadNodes:      {pkgCluster.LabelNodes},
+	pkgCluster.InstallHelmPostHook: {pkgCluster.SetupPrivileges, pkgCluster.TaintHeadNodes},
+
+	// cert-manager solves the challenges using the DNS provider settings of the registered domain
+	pkgCluster.InstallCertManagerPostHook: {pkgCluster.InstallHelmPostHook, pkgCluster.RegisterDomainPostHook},
+
+	// restored and templated deployments may rely on any of the system components, so they come last
+	pkgCluster.RestoreFromBackup:    systemPostHooks,
+	pkgCluster.ApplyClusterTemplate: systemPostHooks,
+}
+
+// systemPostHooks are the built-in posthooks preparing the cluster and installing the Pipeline components.
+var systemPostHooks = []string{
+	pkgCluster.StoreKubeConfig,
+	pkgCluster.SetupPrivileges,
+	pkgCluster.LabelNodes,
+	pkgCluster.TaintHeadNodes,
+	pkgCluster.InstallHelmPostHook,
+	pkgCluster.RegisterDomainPostHook,
+	pkgCluster.InstallCertManagerPostHook,
+	pkgCluster.InstallIngressControllerPostHook,
+	pkgCluster.InstallKubernetesDashboardPostHook,
+	pkgCluster.InstallClusterAutoscalerPostHook,
+	pkgCluster.InstallHorizontalPodAutoscalerPostHook,
+	pkgCluster.InstallMonitoring,
+	pkgCluster.InstallLogging,
+	pkgCluster.InstallPVCOperator,
+	pkgCluster.InstallAnchoreImageValidator,
+	pkgCluster.InitSpotConfig,
+}
+
+// getPostHookDependencies returns the dependencies of a posthook.
+func getPostHookDependencies(name string) []string {
+	if dependencies, ok := postHookDependencies[name]; ok {
+		return dependencies
+	}
+
+	return []string{pkgCluster.InstallHelmPostHook}
+}
+
+// postHookNode is a posthook in the dependency graph.
+type postHookNode struct {
+	name      string
+	dependsOn []string
+	finished  bool
+}
+
+type postHookResult struct {
+	name string
+	err  error
+}
+
+// schedulePostHooks runs the unfinished posthooks as soon as their dependencies are finished,
+// at most parallelism of them at the same time.
+// Dependencies missing from the graph are considered finished.
+// After the first failure no more posthooks are started and the error is returned when the running ones return.
+func schedulePostHooks(nodes []postHookNode, parallelism int, run func(name string) error) error {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	finished := make(map[string]bool, len(nodes))
+	inGraph := make(map[string]bool, len(nodes))
+	var pending []postHookNode
+
+	for _, node := range nodes {
+		inGraph[node.name] = true
+		if node.finished {
+			finished[node.name] = true
+		} else {
+			pending = append(pending, node)
+		}
+	}
+
+	isReady := func(node postHookNode) bool {
+		for _, dependency := range node.dependsOn {
+			if inGraph[dependency] && !finished[dependency] {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	results := make(chan postHookResult, len(pending))
+	running := 0
+	var firstErr error
+
+	for {
+		if firstErr == nil {
+			var waiting []postHookNode
+			for _, node := range pending {
+				if running < parallelism && isReady(node) {
+					running++
+					go func(name string) {
+						results <- postHookResult{name: name, err: run(name)}
+					}(node.name)
+				} else {
+					waiting = append(waiting, node)
+				}
+			}
+			pending = waiting
+		}
+
+		if running == 0 {
+			break
+		}
+
+		result := <-results
+		running--
+
+		if result.err != nil {
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			continue
+		}
+
+		finished[result.name] = true
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+
+	if len(pending) > 0 {
+		var names []string
+		for _, node := range pending {
+			names = append(names, node.name)
+		}
+
+		return errors.Errorf("posthooks have unresolvable dependencies: %v", names)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+func TestSchedulePostHooks(t *testing.T) {
+	nodes := []postHookNode{
+		{name: "a"},
+		{name: "b", dependsOn: []string{"a"}},
+		{name: "c", dependsOn: []string{"a"}},
+		{name: "d", dependsOn: []string{"b", "c"}},
+		{name: "e", dependsOn: []string{"missing"}},
+	}
+
+	var mu sync.Mutex
+	var order []string
+
+	err := schedulePostHooks(nodes, 2, func(name string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		order = append(order, name)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(order) != len(nodes) {
+		t.Fatalf("expected %d posthooks to run, got %v", len(nodes), order)
+	}
+
+	position := make(map[string]int)
+	for i, name := range order {
+		position[name] = i
+	}
+
+	for _, node := range nodes {
+		for _, dependency := range node.dependsOn {
+			if p, ok := position[dependency]; ok && p > position[node.name] {
+				t.Errorf("%s started before its dependency %s: %v", node.name, dependency, order)
+			}
+		}
+	}
+}
+
+func TestSchedulePostHooks_Parallelism(t *testing.T) {
+	nodes := []postHookNode{{name: "a"}, {name: "b"}, {name: "c"}, {name: "d"}}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	release := make(chan struct{})
+
+	go func() {
+		for range nodes {
+			release <- struct{}{}
+		}
+	}()
+
+	err := schedulePostHooks(nodes, 2, func(name string) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 posthooks running at the same time, got %d", maxRunning)
+	}
+}
+
+func TestSchedulePostHooks_Failure(t *testing.T) {
+	nodes := []postHookNode{
+		{name: "a"},
+		{name: "b", dependsOn: []string{"a"}},
+		{name: "c", finished: true},
+	}
+	expectedErr := errors.New("failed")
+
+	var ran []string
+	err := schedulePostHooks(nodes, 1, func(name string) error {
+		ran = append(ran, name)
+
+		return expectedErr
+	})
+	if err != expectedErr {
+		t.Fatalf("expected error %v, got %v", expectedErr, err)
+	}
+
+	if len(ran) != 1 || ran[0] != "a" {
+		t.Errorf("expected only a to run, got %v", ran)
+	}
+}
+
+func TestSchedulePostHooks_Cycle(t *testing.T) {
+	nodes := []postHookNode{
+		{name: "a", dependsOn: []string{"b"}},
+		{name: "b", dependsOn: []string{"a"}},
+	}
+
+	err := schedulePostHooks(nodes, 1, func(name string) error {
+		t.Errorf("%s should not run", name)
+
+		return nil
+	})
+	if err == nil {
+		t.Fatal("expected error for cyclic dependencies")
+	}
+}
+
+func TestGetPostHookDependencies_LastPostHooks(t *testing.T) {
+	for _, name := range []string{pkgCluster.RestoreFromBackup, pkgCluster.ApplyClusterTemplate} {
+		dependencies := make(map[string]bool)
+		for _, dependency := range getPostHookDependencies(name) {
+			dependencies[dependency] = true
+		}
+
+		for _, systemPostHook := range []string{
+			pkgCluster.InstallIngressControllerPostHook,
+			pkgCluster.InstallMonitoring,
+			pkgCluster.InstallLogging,
+			pkgCluster.RegisterDomainPostHook,
+			pkgCluster.InstallCertManagerPostHook,
+		} {
+			if !dependencies[systemPostHook] {
+				t.Errorf("%s should depend on %s", name, systemPostHook)
+			}
+		}
+	}
+}
@@ -0,0 +1,392 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/banzaicloud/pipeline/config"
+	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// ErrPostHooksRunning is returned when the posthooks of a cluster are already being executed.
+var ErrPostHooksRunning = stderrors.New("posthooks are already running for this cluster")
+
+type postHookStateError struct {
+	name   string
+	status string
+}
+
+func (e *postHookStateError) Error() string {
+	return fmt.Sprintf("posthook %s cannot be modified in %s state", e.name, e.status)
+}
+
+func (e *postHookStateError) IsInvalid() bool {
+	return true
+}
+
+// postHookRuns keeps track of the clusters which have posthooks being executed by this instance.
+type postHookRuns struct {
+	mu       sync.Mutex
+	clusters map[uint]bool
+}
+
+func (r *postHookRuns) acquire(clusterID uint) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.clusters[clusterID] {
+		return false
+	}
+
+	r.clusters[clusterID] = true
+
+	return true
+}
+
+func (r *postHookRuns) release(clusterID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.clusters, clusterID)
+}
+
+var runningPostHooks = &postHookRuns{clusters: make(map[uint]bool)}
+
+// postFunctionParams is implemented by posthooks accepting parameters.
+type postFunctionParams interface {
+	GetParams() pkgCluster.PostHookParam
+}
+
+func getPostHookRepository() *intCluster.PostHooks {
+	return intCluster.NewPostHooks(config.DB())
+}
+
+// newPostHookModels creates the persistable execution plan of the given posthooks.
+func newPostHookModels(postHooks []PostFunctioner) ([]*intCluster.PostHookModel, error) {
+	var models []*intCluster.PostHookModel
+	seen := make(map[string]bool)
+
+	for _, postHook := range postHooks {
+		if postHook == nil || seen[postHook.Name()] {
+			continue
+		}
+		seen[postHook.Name()] = true
+
+		var params string
+		if p, ok := postHook.(postFunctionParams); ok && p.GetParams() != nil {
+			paramsJSON, err := json.Marshal(p.GetParams())
+			if err != nil {
+				return nil, emperror.With(errors.Wrap(err, "could not marshal posthook params"), "posthook", postHook.Name())
+			}
+			params = string(paramsJSON)
+		}
+
+		model := &intCluster.PostHookModel{
+			Name:     postHook.Name(),
+			Position: len(models),
+			Params:   params,
+			Status:   pkgCluster.PostHookPending,
+		}
+		model.SetDependencies(getPostHookDependencies(postHook.Name()))
+
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// getPostHookFunction restores the posthook function of a persisted posthook.
+// Posthooks not found among the built-in ones are looked up among the custom posthooks of the organization.
+func getPostHookFunction(organizationID uint, postHook *intCluster.PostHookModel) (PostFunctioner, error) {
+	var params pkgCluster.PostHookParam
+	if postHook.Params != "" {
+		if err := json.Unmarshal([]byte(postHook.Params), &params); err != nil {
+			return nil, emperror.With(errors.Wrap(err, "could not unmarshal posthook params"), "posthook", postHook.Name)
+		}
+	}
+
+	function := HookMap[postHook.Name]
+	if function == nil {
+		return GetCustomPostHookFunction(organizationID, postHook.Name, params)
+	}
+
+	if f, ok := function.(*PostFunctionWithParam); ok && params != nil {
+		fa := *f
+		fa.SetParams(params)
+		function = &fa
+	}
+
+	return function, nil
+}
+
+// executePostHooks runs the unfinished posthooks of a cluster respecting their dependencies and persists their state.
+// The caller must own the run lock of the cluster.
+func executePostHooks(cluster CommonCluster) error {
+	log := log.WithFields(logrus.Fields{"cluster": cluster.GetName(), "org": cluster.GetOrganizationId()})
+	repository := getPostHookRepository()
+
+	postHooks, err := repository.FindByClusterID(cluster.GetID())
+	if err != nil {
+		return err
+	}
+
+	nodes := make([]postHookNode, 0, len(postHooks))
+	for _, postHook := range postHooks {
+		nodes = append(nodes, postHookNode{
+			name:      postHook.Name,
+			dependsOn: postHook.GetDependencies(),
+			finished:  postHook.IsFinished(),
+		})
+	}
+
+	// cluster status updates of concurrently running posthooks must not interleave
+	statusLock := &sync.Mutex{}
+
+	err = schedulePostHooks(nodes, viper.GetInt(config.ClusterPostHookParallelism), func(name string) error {
+		return executePostHook(cluster, repository, name, statusLock, log)
+	})
+	if err != nil {
+		// posthooks finished after the failure might have overwritten the error status
+		cluster.UpdateStatus(pkgCluster.Error, err.Error())
+
+		return err
+	}
+
+	log.Info("Run all posthooks for cluster successfully.")
+
+	err = cluster.UpdateStatus(pkgCluster.Running, pkgCluster.RunningMessage)
+	if err != nil {
+		log.Errorf("Error during posthook status update in db: %s", err.Error())
+	}
+
+	return err
+}
+
+// executePostHook runs a single posthook and persists its state.
+func executePostHook(cluster CommonCluster, repository *intCluster.PostHooks, name string, statusLock sync.Locker, log logrus.FieldLogger) error {
+	// reload the posthook as it might have been skipped in the meantime
+	postHook, err := repository.FindOne(cluster.GetID(), name)
+	if err != nil {
+		return err
+	}
+
+	if postHook.IsFinished() {
+		return nil
+	}
+
+	function, err := getPostHookFunction(cluster.GetOrganizationId(), postHook)
+	if err != nil {
+		postHook.Status = pkgCluster.PostHookFailed
+		postHook.LastError = err.Error()
+		if err := repository.Save(postHook); err != nil {
+			log.Errorf("Error during saving posthook state [%s]: %s", name, err.Error())
+		}
+
+		statusLock.Lock()
+		cluster.UpdateStatus(pkgCluster.Error, err.Error())
+		statusLock.Unlock()
+
+		return err
+	}
+
+	log.Infof("Start posthook function[%s]", name)
+
+	startedAt := time.Now()
+	postHook.Status = pkgCluster.PostHookRunning
+	postHook.Attempts++
+	postHook.LastError = ""
+	postHook.StartedAt = &startedAt
+	postHook.FinishedAt = nil
+	if err := repository.Save(postHook); err != nil {
+		return err
+	}
+
+	err = function.Do(cluster)
+
+	finishedAt := time.Now()
+	postHook.FinishedAt = &finishedAt
+
+	if err != nil {
+		log.Errorf("Error during posthook function[%s]: %s", name, err.Error())
+
+		postHook.Status = pkgCluster.PostHookFailed
+		postHook.LastError = err.Error()
+		if err := repository.Save(postHook); err != nil {
+			log.Errorf("Error during saving posthook state [%s]: %s", name, err.Error())
+		}
+
+		statusLock.Lock()
+		function.Error(cluster, err)
+		statusLock.Unlock()
+
+		return err
+	}
+
+	postHook.Status = pkgCluster.PostHookSucceeded
+	if err := repository.Save(postHook); err != nil {
+		return err
+	}
+
+	statusLock.Lock()
+	defer statusLock.Unlock()
+
+	statusMsg := fmt.Sprintf("Posthook function finished: %s", name)
+	err = cluster.UpdateStatus(pkgCluster.Creating, statusMsg)
+	if err != nil {
+		log.Errorf("Error during posthook status update in db [%s]: %s", name, err.Error())
+	}
+
+	return err
+}
+
+// GetPostHookStatuses returns the persisted posthook states of a cluster.
+func GetPostHookStatuses(cluster CommonCluster) ([]pkgCluster.PostHookStatus, error) {
+	postHooks, err := getPostHookRepository().FindByClusterID(cluster.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]pkgCluster.PostHookStatus, 0, len(postHooks))
+	for _, postHook := range postHooks {
+		statuses = append(statuses, postHook.ConvertModelToEntity())
+	}
+
+	return statuses, nil
+}
+
+// RetryPostHooks resets the given posthook and every posthook depending on it, then continues the execution in the background.
+func RetryPostHooks(cluster CommonCluster, name string) error {
+	if !runningPostHooks.acquire(cluster.GetID()) {
+		return ErrPostHooksRunning
+	}
+
+	err := resetPostHooksFrom(cluster, name)
+	if err != nil {
+		runningPostHooks.release(cluster.GetID())
+		return err
+	}
+
+	go resumePostHooks(cluster)
+
+	return nil
+}
+
+func resetPostHooksFrom(cluster CommonCluster, name string) error {
+	repository := getPostHookRepository()
+
+	// make sure the posthook exists before touching anything
+	if _, err := repository.FindOne(cluster.GetID(), name); err != nil {
+		return err
+	}
+
+	postHooks, err := repository.FindByClusterID(cluster.GetID())
+	if err != nil {
+		return err
+	}
+
+	// collect the posthook and its transitive dependents
+	reset := map[string]bool{name: true}
+	for changed := true; changed; {
+		changed = false
+		for _, postHook := range postHooks {
+			if reset[postHook.Name] || postHook.Status == pkgCluster.PostHookSkipped {
+				continue
+			}
+
+			for _, dependency := range postHook.GetDependencies() {
+				if reset[dependency] {
+					reset[postHook.Name] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, postHook := range postHooks {
+		if !reset[postHook.Name] {
+			continue
+		}
+
+		postHook.Reset()
+		if err := repository.Save(postHook); err != nil {
+			return err
+		}
+	}
+
+	return cluster.UpdateStatus(pkgCluster.Creating, fmt.Sprintf("Retrying posthooks from %s", name))
+}
+
+// SkipPostHook marks a pending or failed posthook as skipped.
+// If the posthooks of the cluster are not running the remaining pending ones are executed in the background.
+func SkipPostHook(cluster CommonCluster, name string) error {
+	repository := getPostHookRepository()
+
+	postHook, err := repository.FindOne(cluster.GetID(), name)
+	if err != nil {
+		return err
+	}
+
+	if postHook.Status != pkgCluster.PostHookPending && postHook.Status != pkgCluster.PostHookFailed {
+		return errors.WithStack(&postHookStateError{name: name, status: postHook.Status})
+	}
+
+	wasFailed := postHook.Status == pkgCluster.PostHookFailed
+
+	postHook.Status = pkgCluster.PostHookSkipped
+	if err := repository.Save(postHook); err != nil {
+		return err
+	}
+
+	// a failed posthook stopped the execution, so continue with the rest
+	if wasFailed && runningPostHooks.acquire(cluster.GetID()) {
+		if err := cluster.UpdateStatus(pkgCluster.Creating, fmt.Sprintf("Posthook function skipped: %s", name)); err != nil {
+			runningPostHooks.release(cluster.GetID())
+			return err
+		}
+
+		go resumePostHooks(cluster)
+	}
+
+	return nil
+}
+
+// resumePostHooks executes the pending posthooks of a cluster and releases the run lock afterwards.
+func resumePostHooks(cluster CommonCluster) error {
+	defer emperror.HandleRecover(errorHandler)
+	defer runningPostHooks.release(cluster.GetID())
+
+	err := executePostHooks(cluster)
+	if err != nil {
+		errorHandler.Handle(emperror.With(errors.Wrap(err, "error during running cluster posthooks"), "cluster", cluster.GetID()))
+	}
+
+	return err
+}
+
+// deletePostHooks removes the persisted posthook states of a cluster.
+func deletePostHooks(cluster CommonCluster) error {
+	return getPostHookRepository().DeleteByClusterID(cluster.GetID())
+}
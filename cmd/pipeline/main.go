@@ -24,30 +24,55 @@ import (
 	evbus "github.com/asaskevich/EventBus"
 	"github.com/banzaicloud/go-gin-prometheus"
 	"github.com/banzaicloud/pipeline/api"
+	alertingAPI "github.com/banzaicloud/pipeline/api/alerting"
 	"github.com/banzaicloud/pipeline/api/ark/backups"
 	"github.com/banzaicloud/pipeline/api/ark/backupservice"
 	"github.com/banzaicloud/pipeline/api/ark/buckets"
 	"github.com/banzaicloud/pipeline/api/ark/restores"
 	"github.com/banzaicloud/pipeline/api/ark/schedules"
+	"github.com/banzaicloud/pipeline/api/auditlog"
+	"github.com/banzaicloud/pipeline/api/cluster/label"
 	"github.com/banzaicloud/pipeline/api/cluster/namespace"
+	"github.com/banzaicloud/pipeline/api/cluster/posthook"
+	clusterTemplateAPI "github.com/banzaicloud/pipeline/api/clustertemplate"
 	"github.com/banzaicloud/pipeline/api/common"
+	"github.com/banzaicloud/pipeline/api/customposthook"
+	desiredStateAPI "github.com/banzaicloud/pipeline/api/desiredstate"
+	customdomain "github.com/banzaicloud/pipeline/api/domain"
+	loggingAPI "github.com/banzaicloud/pipeline/api/logging"
 	"github.com/banzaicloud/pipeline/api/middleware"
+	migrationAPI "github.com/banzaicloud/pipeline/api/migration"
+	notificationAPI "github.com/banzaicloud/pipeline/api/notification"
+	roleAPI "github.com/banzaicloud/pipeline/api/role"
+	rolloutAPI "github.com/banzaicloud/pipeline/api/rollout"
+	"github.com/banzaicloud/pipeline/api/secretrotation"
+	usageAPI "github.com/banzaicloud/pipeline/api/usage"
 	"github.com/banzaicloud/pipeline/auth"
 	"github.com/banzaicloud/pipeline/cluster"
 	"github.com/banzaicloud/pipeline/config"
 	"github.com/banzaicloud/pipeline/dns"
+	"github.com/banzaicloud/pipeline/internal/alerting"
 	arkSync "github.com/banzaicloud/pipeline/internal/ark/sync"
 	"github.com/banzaicloud/pipeline/internal/audit"
 	intAuth "github.com/banzaicloud/pipeline/internal/auth"
 	intCluster "github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/clustertemplate"
 	"github.com/banzaicloud/pipeline/internal/dashboard"
+	"github.com/banzaicloud/pipeline/internal/desiredstate"
+	"github.com/banzaicloud/pipeline/internal/domain"
+	"github.com/banzaicloud/pipeline/internal/logging"
+	"github.com/banzaicloud/pipeline/internal/migration"
 	"github.com/banzaicloud/pipeline/internal/monitor"
+	"github.com/banzaicloud/pipeline/internal/notification"
 	ginternal "github.com/banzaicloud/pipeline/internal/platform/gin"
 	"github.com/banzaicloud/pipeline/internal/platform/gin/correlationid"
 	ginlog "github.com/banzaicloud/pipeline/internal/platform/gin/log"
 	platformlog "github.com/banzaicloud/pipeline/internal/platform/log"
+	"github.com/banzaicloud/pipeline/internal/rollout"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/internal/usage"
 	"github.com/banzaicloud/pipeline/model/defaults"
-	"github.com/banzaicloud/pipeline/notify"
+	pkgAlerting "github.com/banzaicloud/pipeline/pkg/alerting"
 	"github.com/banzaicloud/pipeline/pkg/k8sclient"
 	"github.com/banzaicloud/pipeline/pkg/providers"
 	"github.com/banzaicloud/pipeline/secret"
@@ -110,9 +135,21 @@ func main() {
 	accessManager.AddDefaultPolicies()
 
 	githubImporter := auth.NewGithubImporter(db, accessManager, config.EventBus)
+	organizationSyncer := auth.NewOrganizationSyncer(db, accessManager, config.EventBus)
+
+	// Initialize the secret and token stores
+	err = secret.InitStore(db)
+	if err != nil {
+		logger.Panic(err.Error())
+	}
+
+	tokenStore, err := auth.NewTokenStore(db)
+	if err != nil {
+		logger.Panic(err.Error())
+	}
 
 	// Initialize auth
-	auth.Init(droneDb, accessManager, githubImporter)
+	auth.Init(droneDb, tokenStore, accessManager, githubImporter, organizationSyncer)
 
 	if viper.GetBool(config.DBAutoMigrateEnabled) {
 		log.Info("running automatic schema migrations")
@@ -123,6 +160,11 @@ func main() {
 		}
 	}
 
+	err = auth.BootstrapLocalUsers(db)
+	if err != nil {
+		panic(err)
+	}
+
 	err = defaults.SetDefaultValues()
 	if err != nil {
 		panic(err)
@@ -163,6 +205,11 @@ func main() {
 	clusterManager := cluster.NewManager(clusters, secretValidator, clusterEvents, statusChangeDurationMetric, clusterTotalMetric, log, errorHandler)
 	clusterGetter := common.NewClusterGetter(clusterManager, logger, errorHandler)
 
+	err = clusterManager.ResumePostHooks(context.Background())
+	if err != nil {
+		errorHandler.Handle(emperror.Wrap(err, "failed to resume posthooks"))
+	}
+
 	if viper.GetBool(config.MonitorEnabled) {
 		client, err := k8sclient.NewInClusterClient()
 		if err != nil {
@@ -184,11 +231,143 @@ func main() {
 		}
 	}
 
+	domainService := domain.NewService(
+		domain.NewRepository(db),
+		clusters,
+		dnsSvc,
+		func(organizationID uint) {
+			go clusterManager.UpdateExternalDnsDomainFilters(context.Background(), organizationID)
+		},
+		viper.GetDuration(config.DNSCustomDomainVerificationPeriod),
+		log.WithField("subsystem", "domain"),
+		errorHandler,
+	)
+	go domainService.Run(context.Background())
+
+	notificationService := notification.NewService(
+		notification.NewRepository(db),
+		notification.SMTPConfig{
+			Host:     viper.GetString(config.NotificationSMTPHost),
+			Port:     viper.GetInt(config.NotificationSMTPPort),
+			Username: viper.GetString(config.NotificationSMTPUsername),
+			Password: viper.GetString(config.NotificationSMTPPassword),
+			From:     viper.GetString(config.NotificationSMTPFrom),
+		},
+		log.WithField("subsystem", "notification"),
+		errorHandler,
+	)
+	err = notification.NewSubscriber(notificationService, clusterManager).Register(clusterEventBus, config.EventBus)
+	if err != nil {
+		errorHandler.Handle(emperror.Wrap(err, "failed to subscribe to notification events"))
+	}
+
 	if viper.GetBool(config.SpotMetricsEnabled) {
 		go monitor.NewSpotMetricsExporter(context.Background(), clusterManager, log.WithField("subsystem", "spot-metrics-exporter")).Run(viper.GetDuration(config.SpotMetricsCollectionInterval))
 	}
 
-	clusterAPI := api.NewClusterAPI(clusterManager, log, errorHandler)
+	if viper.GetBool(config.ACMEEnabled) {
+		go clusterManager.RunCertificateSync(context.Background(), viper.GetDuration(config.ACMESyncInterval))
+	}
+
+	secretRotationService := rotation.NewService(
+		rotation.NewRepository(db),
+		secret.Store,
+		clusterManager,
+		viper.GetDuration(config.SecretRotationGracePeriod),
+		log.WithField("subsystem", "secret-rotation"),
+		errorHandler,
+	)
+	go secretRotationService.Run(
+		context.Background(),
+		viper.GetDuration(config.SecretRotationCheckInterval),
+		viper.GetDuration(config.SecretRotationEKSClusterUserKeyInterval),
+	)
+
+	clusterTemplateService := clustertemplate.NewService(
+		clustertemplate.NewRepository(db),
+		log.WithField("subsystem", "cluster-template"),
+		errorHandler,
+	)
+
+	desiredStateService := desiredstate.NewService(
+		desiredstate.NewRepository(db),
+		clusterManager,
+		log.WithField("subsystem", "desired-state"),
+		errorHandler,
+	)
+	if viper.GetBool(config.HelmReconcileEnabled) {
+		go desiredStateService.Run(context.Background(), viper.GetDuration(config.HelmReconcileInterval))
+	}
+
+	rolloutService := rollout.NewService(
+		rollout.NewRepository(db),
+		clusterManager,
+		intCluster.NewLabels(db),
+		log.WithField("subsystem", "rollout"),
+		errorHandler,
+	)
+	if err := rolloutService.FailInterruptedRollouts(); err != nil {
+		errorHandler.Handle(emperror.Wrap(err, "failed to clean up interrupted deployment rollouts"))
+	}
+
+	alertingService := alerting.NewService(
+		alerting.NewRepository(db),
+		clusterManager,
+		alerting.NewEmailRelay(
+			viper.GetString(config.AlertingEmailRelayURL),
+			viper.GetString(config.AlertingEmailRelaySecret),
+			pkgAlerting.SMTPConfig{
+				Host:     viper.GetString(config.NotificationSMTPHost),
+				Port:     viper.GetInt(config.NotificationSMTPPort),
+				Username: viper.GetString(config.NotificationSMTPUsername),
+				Password: viper.GetString(config.NotificationSMTPPassword),
+				From:     viper.GetString(config.NotificationSMTPFrom),
+			},
+		),
+		log.WithField("subsystem", "alerting"),
+		errorHandler,
+	)
+	if err := alertingService.Register(clusterEventBus); err != nil {
+		errorHandler.Handle(emperror.Wrap(err, "failed to subscribe to alerting events"))
+	}
+
+	loggingService := logging.NewService(
+		logging.NewRepository(db),
+		log.WithField("subsystem", "logging"),
+		errorHandler,
+	)
+	if err := loggingService.Register(clusterEventBus); err != nil {
+		errorHandler.Handle(emperror.Wrap(err, "failed to subscribe to logging events"))
+	}
+
+	usageService := usage.NewService(
+		usage.NewRepository(db),
+		clusterManager,
+		usage.NewCloudInfoPricer(viper.GetString(config.CloudInfoEndpoint)),
+		log.WithField("subsystem", "usage"),
+		errorHandler,
+	)
+	if viper.GetBool(config.UsageEnabled) {
+		go usageService.Run(
+			context.Background(),
+			viper.GetDuration(config.UsageCollectionInterval),
+			viper.GetDuration(config.UsageRetention),
+		)
+	}
+
+	clusterAPI := api.NewClusterAPI(clusterManager, clusterTemplateService, log, errorHandler)
+
+	migrationService := migration.NewService(
+		migration.NewRepository(db),
+		clusterManager,
+		clusterAPI,
+		db,
+		log.WithField("subsystem", "migration"),
+		errorHandler,
+	)
+	if err := migrationService.FailInterruptedMigrations(); err != nil {
+		errorHandler.Handle(emperror.Wrap(err, "failed to clean up interrupted cluster migrations"))
+	}
 
 	//Initialise Gin router
 	router := gin.New()
@@ -210,7 +389,43 @@ func main() {
 	router.Use(cors.New(config.GetCORS()))
 	if viper.GetBool("audit.enabled") {
 		log.Infoln("Audit enabled, installing Gin audit middleware")
-		router.Use(audit.LogWriter(skipPaths, viper.GetStringSlice("audit.headers"), db, log))
+
+		var auditSinks []audit.Sink
+		if viper.GetBool(config.AuditWebhookSinkEnabled) {
+			auditSinks = append(auditSinks, audit.NewWebhookSink(
+				viper.GetString(config.AuditWebhookSinkURL),
+				viper.GetString(config.AuditWebhookSinkSecret),
+				viper.GetDuration(config.AuditWebhookSinkTimeout),
+			))
+		}
+		if viper.GetBool(config.AuditSyslogSinkEnabled) {
+			syslogSink, err := audit.NewSyslogSink(
+				viper.GetString(config.AuditSyslogSinkNetwork),
+				viper.GetString(config.AuditSyslogSinkAddress),
+				viper.GetString(config.AuditSyslogSinkAppName),
+			)
+			if err != nil {
+				logger.Panic(err.Error())
+			}
+
+			auditSinks = append(auditSinks, syslogSink)
+		}
+		if viper.GetBool(config.AuditFileSinkEnabled) {
+			auditSinks = append(auditSinks, audit.NewFileSink(viper.GetString(config.AuditFileSinkPath)))
+		}
+
+		auditDispatcher := audit.NewDispatcher(
+			audit.NewOutbox(db),
+			auditSinks,
+			viper.GetInt(config.AuditSinkBatchSize),
+			log.WithField("subsystem", "audit-dispatcher"),
+			errorHandler,
+		)
+		if len(auditSinks) > 0 {
+			go auditDispatcher.Run(context.Background(), viper.GetDuration(config.AuditSinkDispatchInterval))
+		}
+
+		router.Use(audit.LogWriter(skipPaths, viper.GetStringSlice("audit.headers"), db, auditDispatcher.SinkNames(), log))
 	}
 
 	root := router.Group("/")
@@ -230,22 +445,29 @@ func main() {
 	auth.StartTokenStoreGC()
 
 	authorizationMiddleware := intAuth.NewMiddleware(enforcer, basePath)
+	tokenScopeMiddleware := intAuth.NewTokenScopeMiddleware(auth.APITokens, basePath, errorHandler)
 
 	dgroup := router.Group(path.Join(basePath, "dashboard", "orgs"))
 	dgroup.Use(auth.Handler)
 	dgroup.Use(authorizationMiddleware)
+	dgroup.Use(tokenScopeMiddleware)
 	dgroup.Use(api.OrganizationMiddleware)
 	dgroup.GET("/:orgid/clusters", dashboard.GetDashboard)
 
 	domainAPI := api.NewDomainAPI(clusterManager, log, errorHandler)
 	organizationAPI := api.NewOrganizationAPI(githubImporter)
-	userAPI := api.NewUserAPI(accessManager)
+	roleService := intAuth.NewRoleService(intAuth.NewRoleRepository(db), accessManager)
+	userAPI := api.NewUserAPI(accessManager, roleService)
+
+	alertAPI := alertingAPI.NewAPI(alertingService, clusterGetter, errorHandler)
 
 	v1 := router.Group(path.Join(basePath, "api", "v1/"))
 	v1.GET("/functions", api.ListFunctions)
+	alertAPI.RegisterEmailRelayRoutes(v1.Group("/alerting"))
 	{
 		v1.Use(auth.Handler)
 		v1.Use(authorizationMiddleware)
+		v1.Use(tokenScopeMiddleware)
 		orgs := v1.Group("/orgs")
 		{
 			orgs.Use(api.OrganizationMiddleware)
@@ -258,6 +480,9 @@ func main() {
 			orgs.HEAD("/:orgid/spotguides/*name", api.GetSpotguide)
 
 			orgs.GET("/:orgid/domain", domainAPI.GetDomain)
+			customDomainAPI := customdomain.NewAPI(domainService, errorHandler)
+			customDomainAPI.RegisterRoutes(orgs.Group("/:orgid/domains"))
+			customDomainAPI.RegisterRecordRoutes(orgs.Group("/:orgid/dns/records"))
 
 			orgs.POST("/:orgid/clusters", clusterAPI.CreateClusterRequest)
 			//v1.GET("/status", api.Status)
@@ -266,12 +491,14 @@ func main() {
 			orgs.GET("/:orgid/clusters/:id/details", api.GetClusterDetails)
 			orgs.GET("/:orgid/clusters/:id/pods", api.GetPodDetails)
 			orgs.PUT("/:orgid/clusters/:id", clusterAPI.UpdateCluster)
-			orgs.PUT("/:orgid/clusters/:id/posthooks", api.ReRunPostHooks)
+			orgs.PUT("/:orgid/clusters/:id/posthooks", clusterAPI.ReRunPostHooks)
 			orgs.POST("/:orgid/clusters/:id/secrets", api.InstallSecretsToCluster)
 			orgs.POST("/:orgid/clusters/:id/secrets/:secretName", api.InstallSecretToCluster)
 			orgs.PATCH("/:orgid/clusters/:id/secrets/:secretName", api.MergeSecretInCluster)
 			orgs.Any("/:orgid/clusters/:id/proxy/*path", clusterAPI.ProxyToCluster)
 			orgs.DELETE("/:orgid/clusters/:id", clusterAPI.DeleteCluster)
+			orgs.POST("/:orgid/clusters/:id/detach", clusterAPI.DetachCluster)
+			orgs.GET("/:orgid/clusters/:id/health", api.GetClusterHealth)
 			orgs.HEAD("/:orgid/clusters/:id", api.ClusterHEAD)
 			orgs.GET("/:orgid/clusters/:id/config", api.GetClusterConfig)
 			orgs.GET("/:orgid/clusters/:id/apiendpoint", api.GetApiEndpoint)
@@ -282,6 +509,9 @@ func main() {
 			orgs.POST("/:orgid/clusters/:id/deployments", api.CreateDeployment)
 			orgs.GET("/:orgid/clusters/:id/deployments/:name", api.GetDeployment)
 			orgs.GET("/:orgid/clusters/:id/deployments/:name/resources", api.GetDeploymentResources)
+			orgs.GET("/:orgid/clusters/:id/deployments/:name/history", api.GetDeploymentHistory)
+			orgs.GET("/:orgid/clusters/:id/deployments/:name/diff", api.GetDeploymentDiff)
+			orgs.POST("/:orgid/clusters/:id/deployments/:name/rollback", api.RollbackDeployment)
 			orgs.GET("/:orgid/clusters/:id/hpa", api.GetHpaResource)
 			orgs.PUT("/:orgid/clusters/:id/hpa", api.PutHpaResource)
 			orgs.DELETE("/:orgid/clusters/:id/hpa", api.DeleteHpaResource)
@@ -313,12 +543,34 @@ func main() {
 			clusters := orgs.Group("/:orgid/clusters/:id")
 			namespaceAPI := namespace.NewAPI(clusterGetter, errorHandler)
 			namespaceAPI.RegisterRoutes(clusters.Group("/namespaces/:namespace"))
+			postHookAPI := posthook.NewAPI(clusterGetter, errorHandler)
+			postHookAPI.RegisterRoutes(clusters.Group("/posthooks"))
+			customDomainAPI.RegisterClusterRecordRoutes(clusters.Group("/dns/records"))
+			templateAPI := clusterTemplateAPI.NewAPI(clusterTemplateService, clusterGetter, errorHandler)
+			templateAPI.RegisterClusterRoutes(clusters.Group("/template"))
+			templateAPI.RegisterRoutes(orgs.Group("/:orgid/clustertemplates"))
+			desiredStateAPI.NewAPI(desiredStateService, clusterGetter, errorHandler).RegisterRoutes(clusters.Group("/desireddeployments"))
+			label.NewAPI(clusterGetter, errorHandler).RegisterRoutes(clusters.Group("/labels"))
+			rolloutAPI.NewAPI(rolloutService, errorHandler).RegisterRoutes(orgs.Group("/:orgid/deployments"))
+			alertAPI.RegisterRoutes(orgs.Group("/:orgid/alerting"))
+			alertAPI.RegisterClusterRoutes(clusters.Group("/alerting"))
+			loggingAPI.NewAPI(loggingService, clusterGetter, errorHandler).RegisterRoutes(clusters.Group("/logging"))
+			reportAPI := usageAPI.NewAPI(usageService, clusterGetter, errorHandler)
+			reportAPI.RegisterRoutes(orgs.Group("/:orgid/usage"))
+			reportAPI.RegisterClusterRoutes(clusters.Group("/usage"))
+			migrationAPI.NewAPI(migrationService, errorHandler).RegisterRoutes(orgs.Group("/:orgid/migrations"))
+			orgs.POST("/:orgid/clustertemplates/:name/clusters", clusterAPI.CreateClusterFromTemplate)
 
 			orgs.GET("/:orgid/helm/repos", api.HelmReposGet)
 			orgs.POST("/:orgid/helm/repos", api.HelmReposAdd)
 			orgs.PUT("/:orgid/helm/repos/:name", api.HelmReposModify)
 			orgs.PUT("/:orgid/helm/repos/:name/update", api.HelmReposUpdate)
 			orgs.DELETE("/:orgid/helm/repos/:name", api.HelmReposDelete)
+			customPostHookAPI := customposthook.NewAPI(intCluster.NewCustomPostHooks(db), errorHandler)
+			customPostHookAPI.RegisterRoutes(orgs.Group("/:orgid/posthooks"))
+			notificationAPI.NewAPI(notificationService, errorHandler).RegisterRoutes(orgs.Group("/:orgid/notifications"))
+			auditLogAPI := auditlog.NewAPI(audit.NewEventRepository(db), errorHandler)
+			auditLogAPI.RegisterRoutes(orgs.Group("/:orgid/audit"))
 			orgs.GET("/:orgid/helm/charts", api.HelmCharts)
 			orgs.GET("/:orgid/helm/chart/:reponame/:name", api.HelmChart)
 			orgs.GET("/:orgid/profiles/cluster/:distribution", api.GetClusterProfiles)
@@ -331,10 +583,17 @@ func main() {
 			orgs.PUT("/:orgid/secrets/:id", api.UpdateSecrets)
 			orgs.DELETE("/:orgid/secrets/:id", api.DeleteSecrets)
 			orgs.GET("/:orgid/secrets/:id/validate", api.ValidateSecret)
+			orgs.GET("/:orgid/secrets/:id/versions", api.ListSecretVersions)
+			orgs.GET("/:orgid/secrets/:id/versions/:version", api.GetSecretVersion)
+			orgs.GET("/:orgid/secrets/:id/diff", api.DiffSecretVersions)
+			orgs.POST("/:orgid/secrets/:id/rollback", api.RollbackSecret)
+			secretRotationAPI := secretrotation.NewAPI(secretRotationService, errorHandler)
+			secretRotationAPI.RegisterRoutes(orgs.Group("/:orgid/secrets/:id/rotation"))
 			orgs.GET("/:orgid/users", userAPI.GetUsers)
 			orgs.GET("/:orgid/users/:id", userAPI.GetUsers)
 			orgs.POST("/:orgid/users/:id", userAPI.AddUser)
 			orgs.DELETE("/:orgid/users/:id", userAPI.RemoveUser)
+			roleAPI.NewAPI(roleService, errorHandler).RegisterRoutes(orgs.Group("/:orgid/roles"))
 
 			orgs.GET("/:orgid/buckets", api.ListAllBuckets)
 			orgs.POST("/:orgid/buckets", api.CreateBucket)
@@ -378,6 +637,7 @@ func main() {
 			context.Background(),
 			config.DB(),
 			clusterManager,
+			arkSync.NewBackupEvents(config.EventBus),
 			platformlog.NewLogger(platformlog.Config{
 				Level:  viper.GetString(config.ARKLogLevel),
 				Format: viper.GetString(config.LoggingLogFormat),
@@ -397,7 +657,6 @@ func main() {
 	}
 	router.POST(basePath+"/issues", auth.Handler, issueHandler)
 
-	notify.SlackNotify("API is already running")
 	var listenPort string
 	port := viper.GetInt("pipeline.listenport")
 	if port != 0 {
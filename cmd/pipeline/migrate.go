@@ -17,12 +17,25 @@ package main
 import (
 	"github.com/banzaicloud/pipeline/auth"
 	"github.com/banzaicloud/pipeline/dns/route53/model"
+	"github.com/banzaicloud/pipeline/dns/zone"
+	"github.com/banzaicloud/pipeline/internal/alerting"
 	"github.com/banzaicloud/pipeline/internal/ark"
 	"github.com/banzaicloud/pipeline/internal/audit"
+	intAuth "github.com/banzaicloud/pipeline/internal/auth"
 	"github.com/banzaicloud/pipeline/internal/cluster"
+	"github.com/banzaicloud/pipeline/internal/clustertemplate"
+	"github.com/banzaicloud/pipeline/internal/desiredstate"
+	"github.com/banzaicloud/pipeline/internal/domain"
+	"github.com/banzaicloud/pipeline/internal/logging"
+	"github.com/banzaicloud/pipeline/internal/migration"
+	"github.com/banzaicloud/pipeline/internal/notification"
 	"github.com/banzaicloud/pipeline/internal/providers"
+	"github.com/banzaicloud/pipeline/internal/rollout"
+	"github.com/banzaicloud/pipeline/internal/secret/rotation"
+	"github.com/banzaicloud/pipeline/internal/usage"
 	"github.com/banzaicloud/pipeline/model"
 	"github.com/banzaicloud/pipeline/model/defaults"
+	"github.com/banzaicloud/pipeline/secret"
 	"github.com/banzaicloud/pipeline/spotguide"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
@@ -38,6 +51,10 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		return err
 	}
 
+	if err := intAuth.Migrate(db, logger); err != nil {
+		return err
+	}
+
 	if err := defaults.Migrate(db, logger); err != nil {
 		return err
 	}
@@ -46,6 +63,14 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		return err
 	}
 
+	if err := zone.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := domain.Migrate(db, logger); err != nil {
+		return err
+	}
+
 	if err := spotguide.Migrate(db, logger); err != nil {
 		return err
 	}
@@ -58,6 +83,10 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		return err
 	}
 
+	if err := clustertemplate.Migrate(db, logger); err != nil {
+		return err
+	}
+
 	if err := providers.Migrate(db, logger); err != nil {
 		return err
 	}
@@ -66,5 +95,41 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		return err
 	}
 
+	if err := secret.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := rotation.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := notification.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := desiredstate.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := rollout.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := alerting.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := usage.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := logging.Migrate(db, logger); err != nil {
+		return err
+	}
+
+	if err := migration.Migrate(db, logger); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -43,6 +43,47 @@ const (
 	// DNSExternalDnsChartVersion set the external-dns chart version default value: "0.5.4"
 	DNSExternalDnsChartVersion = "dns.externalDnsChartVersion"
 
+	// DNSCustomDomainVerificationPeriod configuration key for the time after which never verified custom domains are deleted
+	DNSCustomDomainVerificationPeriod = "dns.customDomainVerificationPeriod"
+
+	// DNSProvider configuration key for the DNS provider managing the organisation level domains
+	DNSProvider = "dns.provider"
+
+	// DNSOrganizationProviders configuration key for the organisation name to DNS provider overrides
+	DNSOrganizationProviders = "dns.organizations"
+
+	// DNSAzureCredentialPath is the path in Vault to get the Azure DNS credentials from
+	DNSAzureCredentialPath = "dns.azure.credentialPath"
+
+	// DNSAzureResourceGroup configuration key for the resource group of the Azure DNS zones
+	DNSAzureResourceGroup = "dns.azure.resourceGroup"
+
+	// DNSGoogleCredentialPath is the path in Vault to get the Google Cloud DNS service account from
+	DNSGoogleCredentialPath = "dns.google.credentialPath"
+
+	// Config keys to the RFC2136 (eg. BIND) name server
+	DNSRFC2136Host          = "dns.rfc2136.host"
+	DNSRFC2136Port          = "dns.rfc2136.port"
+	DNSRFC2136Zone          = "dns.rfc2136.zone"
+	DNSRFC2136TSIGKeyName   = "dns.rfc2136.tsigKeyName"
+	DNSRFC2136TSIGSecret    = "dns.rfc2136.tsigSecret"
+	DNSRFC2136TSIGAlgorithm = "dns.rfc2136.tsigAlgorithm"
+
+	// ACMEEnabled configuration key for installing cert-manager issuing ACME certificates for the clusters
+	ACMEEnabled = "acme.enabled"
+
+	// ACMEServer configuration key for the directory URL of the ACME server
+	ACMEServer = "acme.server"
+
+	// ACMEEmail configuration key for the email address of the ACME account
+	ACMEEmail = "acme.email"
+
+	// ACMECertManagerChartVersion configuration key for the cert-manager chart version
+	ACMECertManagerChartVersion = "acme.certManagerChartVersion"
+
+	// ACMESyncInterval configuration key for the interval the issued certificates are mirrored into the secret store at
+	ACMESyncInterval = "acme.syncInterval"
+
 	// Route53MaintenanceWndMinute configuration key for the maintenance window for Route53.
 	// This is the maintenance window before the next AWS Route53 pricing period starts
 	Route53MaintenanceWndMinute = "route53.maintenanceWindowMinute"
@@ -93,9 +134,58 @@ const (
 	SpotMetricsEnabled            = "spotmetrics.enabled"
 	SpotMetricsCollectionInterval = "spotmetrics.collectionInterval"
 
+	// Utilization and cost reporting
+	UsageEnabled            = "usage.enabled"
+	UsageCollectionInterval = "usage.collectionInterval"
+	UsageRetention          = "usage.retention"
+
+	// CloudInfoEndpoint configuration key for the Cloudinfo API serving the instance prices, costs are not calculated if empty
+	CloudInfoEndpoint = "cloudinfo.endpoint"
+
 	// Database
 	DBAutoMigrateEnabled = "database.autoMigrateEnabled"
 
+	// ClusterPostHookParallelism is the maximum number of posthooks running concurrently for a cluster
+	ClusterPostHookParallelism = "cluster.posthookParallelism"
+
+	// Audit sinks
+	AuditSinkDispatchInterval = "audit.sinks.dispatchInterval"
+	AuditSinkBatchSize        = "audit.sinks.batchSize"
+	AuditWebhookSinkEnabled   = "audit.sinks.webhook.enabled"
+	AuditWebhookSinkURL       = "audit.sinks.webhook.url"
+	AuditWebhookSinkSecret    = "audit.sinks.webhook.secret"
+	AuditWebhookSinkTimeout   = "audit.sinks.webhook.timeout"
+	AuditSyslogSinkEnabled    = "audit.sinks.syslog.enabled"
+	AuditSyslogSinkNetwork    = "audit.sinks.syslog.network"
+	AuditSyslogSinkAddress    = "audit.sinks.syslog.address"
+	AuditSyslogSinkAppName    = "audit.sinks.syslog.appName"
+	AuditFileSinkEnabled      = "audit.sinks.file.enabled"
+	AuditFileSinkPath         = "audit.sinks.file.path"
+
+	// Notifications
+	NotificationSMTPHost     = "notification.smtp.host"
+	NotificationSMTPPort     = "notification.smtp.port"
+	NotificationSMTPUsername = "notification.smtp.username"
+	NotificationSMTPPassword = "notification.smtp.password"
+	NotificationSMTPFrom     = "notification.smtp.from"
+
+	// Alert email relay
+	AlertingEmailRelayURL    = "alerting.emailRelay.url"
+	AlertingEmailRelaySecret = "alerting.emailRelay.secret"
+
+	// Secret store
+	SecretStoreBackend   = "secret.backend"
+	SecretStoreMasterKey = "secret.masterKey"
+
+	// Secret rotation
+	SecretRotationCheckInterval             = "secret.rotation.checkInterval"
+	SecretRotationGracePeriod               = "secret.rotation.gracePeriod"
+	SecretRotationEKSClusterUserKeyInterval = "secret.rotation.eksClusterUserKeyInterval"
+
+	// Desired deployment reconciliation
+	HelmReconcileEnabled  = "helm.reconcile.enabled"
+	HelmReconcileInterval = "helm.reconcile.interval"
+
 	// Monitor config path
 	MonitorEnabled                = "monitor.enabled"
 	MonitorConfigMap              = "monitor.configMap"              // Prometheus config map
@@ -114,6 +204,17 @@ const (
 
 	SetCookieDomain = "auth.setCookieDomain"
 
+	// Login providers
+	AuthOIDCEnabled       = "auth.oidc.enabled"
+	AuthOIDCIssuer        = "auth.oidc.issuer"
+	AuthOIDCClientID      = "auth.oidc.clientId"
+	AuthOIDCClientSecret  = "auth.oidc.clientSecret"
+	AuthOIDCScopes        = "auth.oidc.scopes"
+	AuthOIDCGroupsClaim   = "auth.oidc.groupsClaim"
+	AuthOIDCGroupMappings = "auth.oidc.groupMappings"
+	AuthLocalEnabled      = "auth.local.enabled"
+	AuthLocalUsers        = "auth.local.users"
+
 	// Logging operator constants
 	LoggingReleaseName          = "logging-operator"
 	LoggingOperatorChartVersion = "loggingOperator.chartVersion"
@@ -145,6 +246,13 @@ func init() {
 	viper.SetDefault("cloud.defaultProfileName", "default")
 	viper.SetDefault("cloud.configRetryCount", 30)
 	viper.SetDefault("cloud.configRetrySleep", 15)
+	viper.SetDefault(ClusterPostHookParallelism, 4)
+	viper.SetDefault(SecretStoreBackend, "vault")
+	viper.SetDefault(SecretRotationCheckInterval, "1h")
+	viper.SetDefault(SecretRotationGracePeriod, "24h")
+	viper.SetDefault(SecretRotationEKSClusterUserKeyInterval, "0")
+	viper.SetDefault(HelmReconcileEnabled, true)
+	viper.SetDefault(HelmReconcileInterval, "5m")
 	viper.SetDefault(AwsCredentialPath, "secret/data/banzaicloud/aws")
 	viper.SetDefault(LoggingLogLevel, "debug")
 	viper.SetDefault(LoggingLogFormat, "text")
@@ -160,6 +268,10 @@ func init() {
 	viper.SetDefault("auth.secureCookie", true)
 	viper.SetDefault("auth.whitelistEnabled", false)
 	viper.SetDefault(SetCookieDomain, false)
+	viper.SetDefault(AuthOIDCEnabled, false)
+	viper.SetDefault(AuthOIDCScopes, []string{"openid", "profile", "email", "groups"})
+	viper.SetDefault(AuthOIDCGroupsClaim, "groups")
+	viper.SetDefault(AuthLocalEnabled, false)
 
 	viper.SetDefault("pipeline.listenport", 9090)
 	viper.SetDefault("pipeline.certfile", "")
@@ -179,12 +291,40 @@ func init() {
 	viper.SetDefault(DBAutoMigrateEnabled, false)
 	viper.SetDefault("audit.enabled", true)
 	viper.SetDefault("audit.headers", []string{"secretId"})
-	viper.SetDefault("audit.skippaths", []string{"/auth/github/callback", "/pipeline/api"})
+	viper.SetDefault("audit.skippaths", []string{"/auth/github/callback", "/auth/oidc/callback", "/auth/local/login", "/pipeline/api"})
+	viper.SetDefault(AuditSinkDispatchInterval, "10s")
+	viper.SetDefault(AuditSinkBatchSize, 100)
+	viper.SetDefault(AuditWebhookSinkEnabled, false)
+	viper.SetDefault(AuditWebhookSinkTimeout, "30s")
+	viper.SetDefault(AuditSyslogSinkEnabled, false)
+	viper.SetDefault(AuditSyslogSinkNetwork, "udp")
+	viper.SetDefault(AuditSyslogSinkAppName, "pipeline")
+	viper.SetDefault(AuditFileSinkEnabled, false)
+	viper.SetDefault(NotificationSMTPPort, 587)
+	viper.SetDefault(NotificationSMTPFrom, "pipeline@banzaicloud.io")
 	viper.SetDefault("tls.validity", "8760h") // 1 year
 	viper.SetDefault(DNSBaseDomain, "example.org")
 	viper.SetDefault(DNSGcIntervalMinute, 1)
 	viper.SetDefault(DNSExternalDnsChartVersion, "0.7.5")
 	viper.SetDefault(DNSGcLogLevel, "debug")
+	viper.SetDefault(DNSCustomDomainVerificationPeriod, "72h")
+	viper.SetDefault(DNSProvider, "route53")
+	viper.SetDefault(DNSOrganizationProviders, map[string]string{})
+	viper.SetDefault(DNSAzureCredentialPath, "secret/data/banzaicloud/azure")
+	viper.SetDefault(DNSAzureResourceGroup, "")
+	viper.SetDefault(DNSGoogleCredentialPath, "secret/data/banzaicloud/google")
+	viper.SetDefault(DNSRFC2136Host, "")
+	viper.SetDefault(DNSRFC2136Port, 53)
+	viper.SetDefault(DNSRFC2136Zone, "")
+	viper.SetDefault(DNSRFC2136TSIGKeyName, "")
+	viper.SetDefault(DNSRFC2136TSIGSecret, "")
+	viper.SetDefault(DNSRFC2136TSIGAlgorithm, "hmac-md5")
+
+	viper.SetDefault(ACMEEnabled, false)
+	viper.SetDefault(ACMEServer, "https://acme-v02.api.letsencrypt.org/directory")
+	viper.SetDefault(ACMEEmail, "")
+	viper.SetDefault(ACMECertManagerChartVersion, "v0.5.2")
+	viper.SetDefault(ACMESyncInterval, "1h")
 	viper.SetDefault(Route53MaintenanceWndMinute, 15)
 
 	viper.SetDefault(GKEResourceDeleteWaitAttempt, 12)
@@ -209,6 +349,11 @@ func init() {
 	viper.SetDefault(SpotMetricsEnabled, false)
 	viper.SetDefault(SpotMetricsCollectionInterval, "30s")
 
+	viper.SetDefault(UsageEnabled, true)
+	viper.SetDefault(UsageCollectionInterval, "15m")
+	viper.SetDefault(UsageRetention, "2160h")
+	viper.SetDefault(CloudInfoEndpoint, "")
+
 	viper.SetDefault(MonitorEnabled, false)
 	viper.SetDefault(MonitorConfigMap, "")
 	viper.SetDefault(MonitorConfigMapPrometheusKey, "prometheus.yml")
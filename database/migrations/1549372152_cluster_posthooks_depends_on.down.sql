@@ -0,0 +1 @@
+ALTER TABLE `cluster_posthooks` DROP COLUMN `depends_on`;
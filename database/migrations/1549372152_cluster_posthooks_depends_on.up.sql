@@ -0,0 +1 @@
+ALTER TABLE `cluster_posthooks` ADD COLUMN `depends_on` text COLLATE utf8mb4_unicode_ci;
@@ -0,0 +1,17 @@
+CREATE TABLE `custom_posthooks` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `chart_repository` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `chart_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `chart_version` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `namespace` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `release_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `values` text COLLATE utf8mb4_unicode_ci,
+  `wait` tinyint(1) DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `created_by` int(10) unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_custom_posthook_org_name` (`organization_id`,`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `kubernetes_node_pools`;
+
+ALTER TABLE `kubernetes_clusters` DROP COLUMN `provider`;
@@ -0,0 +1,13 @@
+ALTER TABLE `kubernetes_clusters` ADD COLUMN `provider` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL;
+
+CREATE TABLE `kubernetes_node_pools` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `count` int(11) DEFAULT NULL,
+  `node_instance_type` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_cluster_id_name` (`cluster_id`,`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
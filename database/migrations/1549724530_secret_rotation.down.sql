@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `secret_rotations`;
+
+DROP TABLE IF EXISTS `secret_rotation_policies`;
@@ -0,0 +1,35 @@
+CREATE TABLE `secret_rotation_policies` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `secret_id` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `interval` bigint(20) DEFAULT NULL,
+  `grace_period` bigint(20) DEFAULT NULL,
+  `enabled` tinyint(1) DEFAULT NULL,
+  `last_rotated_at` timestamp NULL DEFAULT NULL,
+  `next_rotation_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `created_by` int(10) unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_secret_rotation_policy_org_secret` (`organization_id`,`secret_id`),
+  KEY `idx_secret_rotation_policy_next_rotation` (`next_rotation_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `secret_rotations` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `secret_id` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `status` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `message` text COLLATE utf8mb4_unicode_ci,
+  `from_version` int(11) DEFAULT NULL,
+  `to_version` int(11) DEFAULT NULL,
+  `new_key_id` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `old_key_id` text COLLATE utf8mb4_unicode_ci,
+  `revoke_after` timestamp NULL DEFAULT NULL,
+  `revoked_at` timestamp NULL DEFAULT NULL,
+  `revoke_error` text COLLATE utf8mb4_unicode_ci,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_secret_rotation_org_secret` (`organization_id`,`secret_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,12 @@
+CREATE TABLE `audit_outbox` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `event_id` int(10) unsigned DEFAULT NULL,
+  `sink` varchar(64) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `attempts` int(11) DEFAULT NULL,
+  `next_attempt_at` timestamp NULL DEFAULT NULL,
+  `last_error` text COLLATE utf8mb4_unicode_ci,
+  `created_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_audit_outbox_event_id` (`event_id`),
+  KEY `idx_audit_outbox_sink_next_attempt` (`sink`,`next_attempt_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `dns_static_records`;
+DROP TABLE IF EXISTS `dns_custom_domains`;
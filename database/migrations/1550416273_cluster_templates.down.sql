@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `cluster_template_bindings`;
+DROP TABLE IF EXISTS `cluster_template_versions`;
+DROP TABLE IF EXISTS `cluster_templates`;
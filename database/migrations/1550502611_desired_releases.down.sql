@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `desired_releases`;
@@ -0,0 +1,19 @@
+CREATE TABLE `desired_releases` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `release_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `chart_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `chart_version` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `namespace` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `values` text COLLATE utf8mb4_unicode_ci,
+  `absent` tinyint(1) DEFAULT NULL,
+  `status` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `last_action` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `last_error` text COLLATE utf8mb4_unicode_ci,
+  `last_reconciled_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `updated_by` int(10) unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_desired_release_cluster_release` (`cluster_id`,`release_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
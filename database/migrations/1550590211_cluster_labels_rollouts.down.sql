@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `deployment_rollout_targets`;
+DROP TABLE IF EXISTS `deployment_rollouts`;
+DROP TABLE IF EXISTS `cluster_labels`;
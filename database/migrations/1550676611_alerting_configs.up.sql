@@ -0,0 +1,12 @@
+CREATE TABLE `alerting_configs` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `cluster_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `spec` text COLLATE utf8mb4_unicode_ci,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `updated_by` int(10) unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_alerting_config_org_cluster` (`organization_id`,`cluster_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `usage_namespace_samples`;
+DROP TABLE IF EXISTS `usage_node_samples`;
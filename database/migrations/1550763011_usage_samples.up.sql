@@ -0,0 +1,40 @@
+CREATE TABLE `usage_node_samples` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `cluster_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `node_pool` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `node_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `instance_type` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `spot` tinyint(1) DEFAULT NULL,
+  `cpu_capacity` bigint(20) DEFAULT NULL,
+  `cpu_requests` bigint(20) DEFAULT NULL,
+  `cpu_usage` bigint(20) DEFAULT NULL,
+  `memory_capacity` bigint(20) DEFAULT NULL,
+  `memory_requests` bigint(20) DEFAULT NULL,
+  `memory_usage` bigint(20) DEFAULT NULL,
+  `hours` double DEFAULT NULL,
+  `priced` tinyint(1) DEFAULT NULL,
+  `price_per_hour` double DEFAULT NULL,
+  `cost` double DEFAULT NULL,
+  `sampled_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_usage_node_sample_org_time` (`organization_id`,`sampled_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `usage_namespace_samples` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `cluster_id` int(10) unsigned DEFAULT NULL,
+  `cluster_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `namespace` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `cpu_requests` bigint(20) DEFAULT NULL,
+  `cpu_usage` bigint(20) DEFAULT NULL,
+  `memory_requests` bigint(20) DEFAULT NULL,
+  `memory_usage` bigint(20) DEFAULT NULL,
+  `hours` double DEFAULT NULL,
+  `cost` double DEFAULT NULL,
+  `sampled_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_usage_namespace_sample_org_time` (`organization_id`,`sampled_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
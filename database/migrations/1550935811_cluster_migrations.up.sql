@@ -0,0 +1,23 @@
+CREATE TABLE `cluster_migrations` (
+  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `organization_id` int(10) unsigned DEFAULT NULL,
+  `source_cluster_id` int(10) unsigned DEFAULT NULL,
+  `source_cluster_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `target_cluster_id` int(10) unsigned DEFAULT NULL,
+  `target_cluster_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `target_created` tinyint(1) DEFAULT NULL,
+  `options` text COLLATE utf8mb4_unicode_ci,
+  `volumes_migrated` tinyint(1) DEFAULT NULL,
+  `backup_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `restore_name` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `status` varchar(255) COLLATE utf8mb4_unicode_ci DEFAULT NULL,
+  `message` text COLLATE utf8mb4_unicode_ci,
+  `storage_classes` text COLLATE utf8mb4_unicode_ci,
+  `results` mediumtext COLLATE utf8mb4_unicode_ci,
+  `created_at` timestamp NULL DEFAULT NULL,
+  `updated_at` timestamp NULL DEFAULT NULL,
+  `finished_at` timestamp NULL DEFAULT NULL,
+  `created_by` int(10) unsigned DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_cluster_migrations_organization_id` (`organization_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE `kubernetes_clusters` DROP COLUMN `preinstalled_releases`;
+ALTER TABLE `kubernetes_clusters` DROP COLUMN `tiller_preinstalled`;
@@ -0,0 +1,2 @@
+ALTER TABLE `kubernetes_clusters` ADD COLUMN `tiller_preinstalled` tinyint(1) DEFAULT NULL;
+ALTER TABLE `kubernetes_clusters` ADD COLUMN `preinstalled_releases` text COLLATE utf8mb4_unicode_ci;
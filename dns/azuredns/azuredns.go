@@ -0,0 +1,378 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package azuredns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/dns/mgmt/2017-10-01/dns"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/banzaicloud/pipeline/dns/zone"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const delegationTTL = 300
+
+// Config holds the credentials and the resource group of the DNS zones.
+type Config struct {
+	TenantID       string
+	SubscriptionID string
+	ClientID       string
+	ClientSecret   string
+	ResourceGroup  string
+
+	// BaseDomain is the domain the zones of the organizations are delegated from.
+	// Its zone must exist in the resource group.
+	BaseDomain string
+}
+
+// backend manages the zones of organization domains in Azure DNS.
+type backend struct {
+	config Config
+
+	zones      dns.ZonesClient
+	recordSets dns.RecordSetsClient
+}
+
+// NewBackend returns a new Azure DNS backend.
+func NewBackend(config Config) (zone.Backend, error) {
+	if config.ResourceGroup == "" {
+		return nil, errors.New("resource group of the Azure DNS zones is not configured")
+	}
+
+	authorizer, err := auth.NewClientCredentialsConfig(config.ClientID, config.ClientSecret, config.TenantID).Authorizer()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create Azure authorizer")
+	}
+
+	b := &backend{
+		config: config,
+
+		zones:      dns.NewZonesClient(config.SubscriptionID),
+		recordSets: dns.NewRecordSetsClient(config.SubscriptionID),
+	}
+	b.zones.Authorizer = authorizer
+	b.recordSets.Authorizer = authorizer
+
+	_, err = b.zones.Get(context.Background(), config.ResourceGroup, config.BaseDomain)
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not find the zone of the base domain"), "domain", config.BaseDomain)
+	}
+
+	return b, nil
+}
+
+func isNotFound(err error) bool {
+	detailedErr, ok := errors.Cause(err).(autorest.DetailedError)
+
+	return ok && detailedErr.Response != nil && detailedErr.Response.StatusCode == http.StatusNotFound
+}
+
+func (b *backend) createZone(ctx context.Context, domain string) (dns.Zone, error) {
+	dnsZone, err := b.zones.Get(ctx, b.config.ResourceGroup, domain)
+	if isNotFound(err) {
+		dnsZone, err = b.zones.CreateOrUpdate(ctx, b.config.ResourceGroup, domain, dns.Zone{
+			Location: to.StringPtr("global"),
+			Tags: map[string]*string{
+				"managed-by": to.StringPtr("banzaicloud-pipeline"),
+			},
+		}, "", "")
+	}
+	if err != nil {
+		return dnsZone, emperror.With(errors.Wrap(err, "could not create DNS zone"), "domain", domain)
+	}
+
+	if dnsZone.ZoneProperties == nil || dnsZone.NameServers == nil {
+		return dnsZone, emperror.With(errors.New("DNS zone has no name servers"), "domain", domain)
+	}
+
+	return dnsZone, nil
+}
+
+func (b *backend) deleteZone(ctx context.Context, domain string) error {
+	future, err := b.zones.Delete(ctx, b.config.ResourceGroup, domain, "")
+	if isNotFound(err) {
+		return nil
+	} else if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete DNS zone"), "domain", domain)
+	}
+
+	err = future.WaitForCompletion(ctx, b.zones.Client)
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete DNS zone"), "domain", domain)
+	}
+
+	return nil
+}
+
+func (b *backend) CreateZone(ctx context.Context, domain string) (string, error) {
+	dnsZone, err := b.createZone(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	var nsRecords []dns.NsRecord
+	for _, nameServer := range *dnsZone.NameServers {
+		nsRecords = append(nsRecords, dns.NsRecord{Nsdname: to.StringPtr(nameServer)})
+	}
+
+	_, err = b.recordSets.CreateOrUpdate(ctx, b.config.ResourceGroup, b.config.BaseDomain, zone.RelativeName(domain, b.config.BaseDomain), dns.NS, dns.RecordSet{
+		RecordSetProperties: &dns.RecordSetProperties{
+			TTL:       to.Int64Ptr(delegationTTL),
+			NsRecords: &nsRecords,
+		},
+	}, "", "")
+	if err != nil {
+		return "", emperror.With(errors.Wrap(err, "could not delegate DNS zone from the base domain"), "domain", domain)
+	}
+
+	return to.String(dnsZone.ID), nil
+}
+
+func (b *backend) DeleteZone(ctx context.Context, domain string, zoneID string) error {
+	_, err := b.recordSets.Delete(ctx, b.config.ResourceGroup, b.config.BaseDomain, zone.RelativeName(domain, b.config.BaseDomain), dns.NS, "")
+	if err != nil && !isNotFound(err) {
+		return emperror.With(errors.Wrap(err, "could not remove DNS zone delegation from the base domain"), "domain", domain)
+	}
+
+	return b.deleteZone(ctx, domain)
+}
+
+func (b *backend) listRecordSets(ctx context.Context, domain string) ([]dns.RecordSet, error) {
+	var recordSets []dns.RecordSet
+
+	page, err := b.recordSets.ListByDNSZone(ctx, b.config.ResourceGroup, domain, nil, "")
+	for ; err == nil && page.NotDone(); err = page.Next() {
+		recordSets = append(recordSets, page.Values()...)
+	}
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not list DNS records"), "domain", domain)
+	}
+
+	return recordSets, nil
+}
+
+func (b *backend) DeleteRecordsOwnedBy(ctx context.Context, domain string, zoneID string, ownerID string) error {
+	recordSets, err := b.listRecordSets(ctx, domain)
+	if err != nil {
+		return err
+	}
+
+	ownedNames := make(map[string]bool)
+	for _, recordSet := range recordSets {
+		if recordType(recordSet) != dns.TXT || recordSet.TxtRecords == nil {
+			continue
+		}
+
+		for _, txtRecord := range *recordSet.TxtRecords {
+			if txtRecord.Value != nil && zone.IsOwnedBy(strings.Join(*txtRecord.Value, ""), ownerID) {
+				ownedNames[to.String(recordSet.Name)] = true
+			}
+		}
+	}
+
+	for _, recordSet := range recordSets {
+		rType := recordType(recordSet)
+		if rType == dns.NS || rType == dns.SOA || !ownedNames[to.String(recordSet.Name)] {
+			continue
+		}
+
+		_, err := b.recordSets.Delete(ctx, b.config.ResourceGroup, domain, to.String(recordSet.Name), rType, "")
+		if err != nil && !isNotFound(err) {
+			return emperror.With(errors.Wrap(err, "could not delete DNS record"), "domain", domain, "name", to.String(recordSet.Name))
+		}
+	}
+
+	return nil
+}
+
+// recordType returns the type of a record set, the type of the resource is "Microsoft.Network/dnszones/<type>".
+func recordType(recordSet dns.RecordSet) dns.RecordType {
+	resourceType := to.String(recordSet.Type)
+
+	return dns.RecordType(resourceType[strings.LastIndex(resourceType, "/")+1:])
+}
+
+func (b *backend) Credentials() (string, map[string]string) {
+	return pkgCluster.Azure, map[string]string{
+		pkgSecret.AzureClientId:       b.config.ClientID,
+		pkgSecret.AzureClientSecret:   b.config.ClientSecret,
+		pkgSecret.AzureTenantId:       b.config.TenantID,
+		pkgSecret.AzureSubscriptionId: b.config.SubscriptionID,
+	}
+}
+
+func (b *backend) Options(domain string, zoneID string) map[string]string {
+	return map[string]string{
+		pkgDns.AzureResourceGroup: b.config.ResourceGroup,
+	}
+}
+
+func (b *backend) CreateCustomZone(ctx context.Context, domain string) (string, error) {
+	dnsZone, err := b.createZone(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	return to.String(dnsZone.ID), nil
+}
+
+func (b *backend) DeleteCustomZone(ctx context.Context, domain string, zoneID string) error {
+	return b.deleteZone(ctx, domain)
+}
+
+func (b *backend) NameServers(ctx context.Context, domain string, zoneID string) ([]string, error) {
+	dnsZone, err := b.zones.Get(ctx, b.config.ResourceGroup, domain)
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get DNS zone"), "domain", domain)
+	}
+
+	if dnsZone.ZoneProperties == nil || dnsZone.NameServers == nil {
+		return nil, emperror.With(errors.New("DNS zone has no name servers"), "domain", domain)
+	}
+
+	return *dnsZone.NameServers, nil
+}
+
+func (b *backend) ListRecords(ctx context.Context, domain string, zoneID string) ([]pkgDns.Record, error) {
+	recordSets, err := b.listRecordSets(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []pkgDns.Record
+	for _, recordSet := range recordSets {
+		if recordSet.RecordSetProperties == nil {
+			continue
+		}
+
+		record := pkgDns.Record{
+			Name: zone.AbsoluteName(to.String(recordSet.Name), domain),
+			Type: string(recordType(recordSet)),
+			TTL:  to.Int64(recordSet.TTL),
+		}
+
+		switch recordType(recordSet) {
+		case dns.A:
+			if recordSet.ARecords != nil {
+				for _, r := range *recordSet.ARecords {
+					record.Values = append(record.Values, to.String(r.Ipv4Address))
+				}
+			}
+		case dns.AAAA:
+			if recordSet.AaaaRecords != nil {
+				for _, r := range *recordSet.AaaaRecords {
+					record.Values = append(record.Values, to.String(r.Ipv6Address))
+				}
+			}
+		case dns.CNAME:
+			if recordSet.CnameRecord != nil {
+				record.Values = append(record.Values, zone.NormalizeName(to.String(recordSet.CnameRecord.Cname)))
+			}
+		case dns.MX:
+			if recordSet.MxRecords != nil {
+				for _, r := range *recordSet.MxRecords {
+					record.Values = append(record.Values, fmt.Sprintf("%d %s", to.Int32(r.Preference), zone.NormalizeName(to.String(r.Exchange))))
+				}
+			}
+		case dns.NS:
+			if recordSet.NsRecords != nil {
+				for _, r := range *recordSet.NsRecords {
+					record.Values = append(record.Values, zone.NormalizeName(to.String(r.Nsdname)))
+				}
+			}
+		case dns.TXT:
+			if recordSet.TxtRecords != nil {
+				for _, r := range *recordSet.TxtRecords {
+					record.Values = append(record.Values, strings.Join(to.StringSlice(r.Value), ""))
+				}
+			}
+		default:
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (b *backend) UpsertRecord(ctx context.Context, domain string, zoneID string, record pkgDns.Record) error {
+	properties := &dns.RecordSetProperties{
+		TTL: to.Int64Ptr(record.TTL),
+	}
+
+	switch record.Type {
+	case pkgDns.RecordTypeA:
+		var aRecords []dns.ARecord
+		for _, value := range record.Values {
+			aRecords = append(aRecords, dns.ARecord{Ipv4Address: to.StringPtr(value)})
+		}
+		properties.ARecords = &aRecords
+	case pkgDns.RecordTypeAAAA:
+		var aaaaRecords []dns.AaaaRecord
+		for _, value := range record.Values {
+			aaaaRecords = append(aaaaRecords, dns.AaaaRecord{Ipv6Address: to.StringPtr(value)})
+		}
+		properties.AaaaRecords = &aaaaRecords
+	case pkgDns.RecordTypeCNAME:
+		properties.CnameRecord = &dns.CnameRecord{Cname: to.StringPtr(record.Values[0])}
+	case pkgDns.RecordTypeMX:
+		var mxRecords []dns.MxRecord
+		for _, value := range record.Values {
+			preference, host, err := zone.ParseMX(value)
+			if err != nil {
+				return err
+			}
+			mxRecords = append(mxRecords, dns.MxRecord{Preference: to.Int32Ptr(int32(preference)), Exchange: to.StringPtr(host)})
+		}
+		properties.MxRecords = &mxRecords
+	case pkgDns.RecordTypeTXT:
+		var txtRecords []dns.TxtRecord
+		for _, value := range record.Values {
+			txtRecords = append(txtRecords, dns.TxtRecord{Value: &[]string{value}})
+		}
+		properties.TxtRecords = &txtRecords
+	default:
+		return emperror.With(errors.New("unsupported record type"), "type", record.Type)
+	}
+
+	_, err := b.recordSets.CreateOrUpdate(ctx, b.config.ResourceGroup, domain, zone.RelativeName(record.Name, domain), dns.RecordType(record.Type), dns.RecordSet{
+		RecordSetProperties: properties,
+	}, "", "")
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not save DNS record"), "domain", domain, "name", record.Name)
+	}
+
+	return nil
+}
+
+func (b *backend) DeleteRecord(ctx context.Context, domain string, zoneID string, name string, recordType string) error {
+	_, err := b.recordSets.Delete(ctx, b.config.ResourceGroup, domain, zone.RelativeName(name, domain), dns.RecordType(recordType), "")
+	if err != nil && !isNotFound(err) {
+		return emperror.With(errors.Wrap(err, "could not delete DNS record"), "domain", domain, "name", name)
+	}
+
+	return nil
+}
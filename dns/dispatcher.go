@@ -0,0 +1,191 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+// providerDispatcher is a DnsServiceClient that routes the operations of an organization
+// to the DNS provider selected for the organization.
+type providerDispatcher struct {
+	clients         map[string]DnsServiceClient
+	defaultProvider string
+
+	// organizations maps organization names to the DNS provider used instead of the default one
+	organizations map[string]string
+
+	getOrganizationName func(orgId uint) (string, error)
+}
+
+// provider returns the DNS provider selected for the organization.
+func (d *providerDispatcher) provider(orgId uint) (string, error) {
+	if len(d.organizations) == 0 {
+		return d.defaultProvider, nil
+	}
+
+	orgName, err := d.getOrganizationName(orgId)
+	if err != nil {
+		return "", emperror.With(errors.WithMessage(err, "failed to get organization"), "organization", orgId)
+	}
+
+	if provider, ok := d.organizations[orgName]; ok {
+		return provider, nil
+	}
+
+	return d.defaultProvider, nil
+}
+
+// client returns the client of the DNS provider selected for the organization
+// or nil if the credentials of the provider are not configured.
+func (d *providerDispatcher) client(orgId uint) (DnsServiceClient, error) {
+	provider, err := d.provider(orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.clients[provider], nil
+}
+
+// mustClient returns the client of the DNS provider selected for the organization
+// or an error if the provider is not configured.
+func (d *providerDispatcher) mustClient(orgId uint) (DnsServiceClient, error) {
+	provider, err := d.provider(orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	client, ok := d.clients[provider]
+	if !ok {
+		return nil, emperror.With(errors.New("DNS provider is not configured"), "provider", provider, "organization", orgId)
+	}
+
+	return client, nil
+}
+
+func (d *providerDispatcher) RegisterDomain(orgId uint, domain string) error {
+	client, err := d.mustClient(orgId)
+	if err != nil {
+		return err
+	}
+
+	return client.RegisterDomain(orgId, domain)
+}
+
+func (d *providerDispatcher) UnregisterDomain(orgId uint, domain string) error {
+	client, err := d.mustClient(orgId)
+	if err != nil {
+		return err
+	}
+
+	return client.UnregisterDomain(orgId, domain)
+}
+
+func (d *providerDispatcher) IsDomainRegistered(orgId uint, domain string) (bool, error) {
+	client, err := d.client(orgId)
+	if err != nil || client == nil {
+		return false, err
+	}
+
+	return client.IsDomainRegistered(orgId, domain)
+}
+
+func (d *providerDispatcher) GetOrgDomain(orgId uint) (string, error) {
+	client, err := d.client(orgId)
+	if err != nil || client == nil {
+		return "", err
+	}
+
+	return client.GetOrgDomain(orgId)
+}
+
+func (d *providerDispatcher) GetProviderSettings(orgId uint) (*pkgDns.ProviderSettings, error) {
+	client, err := d.client(orgId)
+	if err != nil || client == nil {
+		return nil, err
+	}
+
+	return client.GetProviderSettings(orgId)
+}
+
+func (d *providerDispatcher) DeleteDnsRecordsOwnedBy(ownerId string, orgId uint) error {
+	client, err := d.client(orgId)
+	if err != nil || client == nil {
+		return err
+	}
+
+	return client.DeleteDnsRecordsOwnedBy(ownerId, orgId)
+}
+
+func (d *providerDispatcher) CreateCustomZone(orgId uint, domain string) (string, []string, error) {
+	client, err := d.mustClient(orgId)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return client.CreateCustomZone(orgId, domain)
+}
+
+func (d *providerDispatcher) DeleteCustomZone(orgId uint, domain string, zoneID string) error {
+	client, err := d.mustClient(orgId)
+	if err != nil {
+		return err
+	}
+
+	return client.DeleteCustomZone(orgId, domain, zoneID)
+}
+
+func (d *providerDispatcher) ListRecords(orgId uint, domain string, zoneID string) ([]pkgDns.Record, error) {
+	client, err := d.client(orgId)
+	if err != nil || client == nil {
+		return nil, err
+	}
+
+	return client.ListRecords(orgId, domain, zoneID)
+}
+
+func (d *providerDispatcher) UpsertRecord(orgId uint, domain string, zoneID string, record pkgDns.Record) error {
+	client, err := d.mustClient(orgId)
+	if err != nil {
+		return err
+	}
+
+	return client.UpsertRecord(orgId, domain, zoneID, record)
+}
+
+func (d *providerDispatcher) DeleteRecord(orgId uint, domain string, zoneID string, name string, recordType string) error {
+	client, err := d.mustClient(orgId)
+	if err != nil {
+		return err
+	}
+
+	return client.DeleteRecord(orgId, domain, zoneID, name, recordType)
+}
+
+// Cleanup cleans up the unused domains of every configured DNS provider.
+func (d *providerDispatcher) Cleanup() {
+	for _, client := range d.clients {
+		client.Cleanup()
+	}
+}
+
+// ProcessUnfinishedTasks continues the pending domain operations of every configured DNS provider.
+func (d *providerDispatcher) ProcessUnfinishedTasks() {
+	for _, client := range d.clients {
+		client.ProcessUnfinishedTasks()
+	}
+}
@@ -18,12 +18,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/banzaicloud/pipeline/auth"
 	"github.com/banzaicloud/pipeline/config"
-	"github.com/banzaicloud/pipeline/dns/route53"
-	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
-	"github.com/banzaicloud/pipeline/secret"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/pkg/errors"
 	"github.com/satori/go.uuid"
-	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 )
 
@@ -35,7 +34,7 @@ var dnsServiceClient DnsServiceClient
 
 var gc garbageCollector
 
-// dnsNotificationsChannel is used to receive DNS related events from Route53 and fan out the events to consumers.
+// dnsNotificationsChannel is used to receive DNS related events from the DNS providers and fan out the events to consumers.
 var dnsNotificationsChannel chan interface{}
 
 // dnsEventsConsumers stores the channels through which subscribers receive DNS events
@@ -58,6 +57,20 @@ type DnsServiceClient interface {
 	Cleanup()
 	DeleteDnsRecordsOwnedBy(ownerId string, orgId uint) error
 	ProcessUnfinishedTasks()
+
+	// GetProviderSettings returns the settings of the DNS provider managing the domain of the organization
+	// or nil if the organization has no registered domain.
+	GetProviderSettings(orgId uint) (*pkgDns.ProviderSettings, error)
+
+	// CreateCustomZone creates a zone for a custom domain of the organization
+	// and returns the id and the name servers of the zone.
+	CreateCustomZone(orgId uint, domain string) (string, []string, error)
+	DeleteCustomZone(orgId uint, domain string, zoneID string) error
+
+	// ListRecords returns the record sets of a zone managed for the organization.
+	ListRecords(orgId uint, domain string, zoneID string) ([]pkgDns.Record, error)
+	UpsertRecord(orgId uint, domain string, zoneID string, record pkgDns.Record) error
+	DeleteRecord(orgId uint, domain string, zoneID string, name string, recordType string) error
 }
 
 func newExternalDnsServiceClientInstance() {
@@ -66,41 +79,62 @@ func newExternalDnsServiceClientInstance() {
 
 	gcInterval := time.Duration(viper.GetInt(config.DNSGcIntervalMinute)) * time.Minute
 
-	// This is how the secrets are expected to be written in Vault:
-	// vault kv put secret/banzaicloud/aws AWS_REGION=... AWS_ACCESS_KEY_ID=... AWS_SECRET_ACCESS_KEY=...
-	awsCredentialsPath := viper.GetString(config.AwsCredentialPath)
+	defaultProvider := viper.GetString(config.DNSProvider)
+	organizations := viper.GetStringMapString(config.DNSOrganizationProviders)
 
-	secret, err := secret.Store.Logical.Read(awsCredentialsPath)
-	if err != nil {
-		log.Errorf("Failed to read AWS credentials from Vault: %s", err.Error())
-		errCreate = err
-		return
-	}
+	providers := map[string]bool{defaultProvider: true}
+	for orgName, provider := range organizations {
+		if !pkgDns.IsSupportedProvider(provider) {
+			log.Errorf("Unsupported DNS provider '%s' configured for organization '%s'", provider, orgName)
+			errCreate = errors.Errorf("unsupported DNS provider: %s", provider)
+			return
+		}
 
-	if secret == nil {
-		log.Infoln("No AWS credentials for Route53 provided in Vault")
-		return
+		providers[provider] = true
 	}
 
-	awsCredentials := cast.ToStringMapString(secret.Data["data"])
-	region := awsCredentials[secretTypes.AwsRegion]
-	awsSecretId := awsCredentials[secretTypes.AwsAccessKeyId]
-	awsSecretKey := awsCredentials[secretTypes.AwsSecretAccessKey]
-
-	if len(region) == 0 || len(awsSecretId) == 0 || len(awsSecretKey) == 0 {
-		log.Infoln("No AWS credentials for Route53 provided in Vault")
+	if err := pkgDns.ValidateProviderAssignment(defaultProvider, organizations); err != nil {
+		log.Errorf("Invalid DNS provider configuration: %s", err.Error())
+		errCreate = err
 		return
 	}
 
 	dnsNotificationsChannel = make(chan interface{})
-	awsRoute53, err := route53.NewAwsRoute53(region, awsSecretId, awsSecretKey, dnsNotificationsChannel)
-	if err != nil {
-		errCreate = err
 
+	clients := make(map[string]DnsServiceClient)
+	for provider := range providers {
+		client, err := newProviderClient(provider, dnsNotificationsChannel)
+		if err != nil {
+			log.Errorf("Failed to create %s DNS provider: %s", provider, err.Error())
+			errCreate = err
+
+			close(dnsNotificationsChannel)
+			return
+		}
+
+		if client != nil {
+			clients[provider] = client
+		}
+	}
+
+	if len(clients) == 0 {
 		close(dnsNotificationsChannel)
 		return
 	}
-	dnsServiceClient = awsRoute53
+
+	dnsServiceClient = &providerDispatcher{
+		clients:         clients,
+		defaultProvider: defaultProvider,
+		organizations:   organizations,
+		getOrganizationName: func(orgId uint) (string, error) {
+			org, err := auth.GetOrganizationById(orgId)
+			if err != nil {
+				return "", err
+			}
+
+			return org.Name, nil
+		},
+	}
 
 	// initiate and start DNS garbage collector
 	garbageCollector, err := newGarbageCollector(dnsServiceClient, gcInterval)
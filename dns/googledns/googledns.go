@@ -0,0 +1,399 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package googledns
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/banzaicloud/pipeline/dns/zone"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret/verify"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/dns/v1"
+	"google.golang.org/api/googleapi"
+)
+
+const delegationTTL = 300
+
+// Config holds the service account credentials used to manage the DNS zones.
+type Config struct {
+	// Credentials contains the service account key fields
+	Credentials map[string]string
+
+	// BaseDomain is the domain the zones of the organizations are delegated from.
+	// Its managed zone must exist in the project of the service account.
+	BaseDomain string
+}
+
+// backend manages the zones of organization domains in Google Cloud DNS.
+type backend struct {
+	config  Config
+	project string
+
+	service      *dns.Service
+	baseZoneName string
+}
+
+// NewBackend returns a new Google Cloud DNS backend.
+func NewBackend(config Config) (zone.Backend, error) {
+	credentials, err := json.Marshal(verify.CreateServiceAccount(config.Credentials))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode service account")
+	}
+
+	jwtConfig, err := google.JWTConfigFromJSON(credentials, dns.NdevClouddnsReadwriteScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse service account")
+	}
+
+	service, err := dns.New(jwtConfig.Client(context.Background()))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create Cloud DNS client")
+	}
+
+	b := &backend{
+		config:  config,
+		project: config.Credentials[pkgSecret.ProjectId],
+
+		service: service,
+	}
+
+	baseZone, err := b.findZone(context.Background(), config.BaseDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	if baseZone == nil {
+		return nil, emperror.With(errors.New("could not find the managed zone of the base domain"), "domain", config.BaseDomain)
+	}
+
+	b.baseZoneName = baseZone.Name
+
+	return b, nil
+}
+
+var invalidZoneNameChars = regexp.MustCompile("[^a-z0-9-]")
+
+// zoneName returns the name of the managed zone of a domain.
+// Zone names must start with a letter and contain at most 63 lowercase letters, digits or dashes.
+func zoneName(domain string) string {
+	name := "pipeline-" + invalidZoneNameChars.ReplaceAllString(strings.Replace(strings.ToLower(domain), ".", "-", -1), "")
+
+	if len(name) > 63 {
+		name = name[:63]
+	}
+
+	return strings.TrimRight(name, "-")
+}
+
+func isNotFound(err error) bool {
+	apiErr, ok := errors.Cause(err).(*googleapi.Error)
+
+	return ok && apiErr.Code == http.StatusNotFound
+}
+
+func fqdn(domain string) string {
+	return strings.TrimSuffix(domain, ".") + "."
+}
+
+func (b *backend) findZone(ctx context.Context, domain string) (*dns.ManagedZone, error) {
+	zones, err := b.service.ManagedZones.List(b.project).DnsName(fqdn(domain)).Context(ctx).Do()
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not list managed zones"), "domain", domain)
+	}
+
+	if len(zones.ManagedZones) == 0 {
+		return nil, nil
+	}
+
+	return zones.ManagedZones[0], nil
+}
+
+func (b *backend) listRecordSets(ctx context.Context, zoneName string, name string, recordType string) ([]*dns.ResourceRecordSet, error) {
+	var recordSets []*dns.ResourceRecordSet
+
+	call := b.service.ResourceRecordSets.List(b.project, zoneName)
+	if name != "" {
+		call = call.Name(fqdn(name)).Type(recordType)
+	}
+
+	err := call.Pages(ctx, func(page *dns.ResourceRecordSetsListResponse) error {
+		recordSets = append(recordSets, page.Rrsets...)
+		return nil
+	})
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not list DNS records"), "zone", zoneName)
+	}
+
+	return recordSets, nil
+}
+
+func (b *backend) changeRecordSets(ctx context.Context, zoneName string, change *dns.Change) error {
+	if len(change.Additions) == 0 && len(change.Deletions) == 0 {
+		return nil
+	}
+
+	_, err := b.service.Changes.Create(b.project, zoneName, change).Context(ctx).Do()
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not change DNS records"), "zone", zoneName)
+	}
+
+	return nil
+}
+
+func (b *backend) createZone(ctx context.Context, domain string) (*dns.ManagedZone, error) {
+	managedZone, err := b.findZone(ctx, domain)
+	if err != nil || managedZone != nil {
+		return managedZone, err
+	}
+
+	managedZone, err = b.service.ManagedZones.Create(b.project, &dns.ManagedZone{
+		Name:        zoneName(domain),
+		DnsName:     fqdn(domain),
+		Description: "Managed by Banzai Cloud Pipeline",
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not create managed zone"), "domain", domain)
+	}
+
+	return managedZone, nil
+}
+
+// deleteZone deletes a managed zone, managed zones can only be deleted when they contain the NS and SOA records only.
+func (b *backend) deleteZone(ctx context.Context, domain string, zoneID string) error {
+	recordSets, err := b.listRecordSets(ctx, zoneID, "", "")
+	if isNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var deletions []*dns.ResourceRecordSet
+	for _, recordSet := range recordSets {
+		if recordSet.Type != "NS" && recordSet.Type != "SOA" {
+			deletions = append(deletions, recordSet)
+		}
+	}
+
+	err = b.changeRecordSets(ctx, zoneID, &dns.Change{Deletions: deletions})
+	if err != nil {
+		return err
+	}
+
+	err = b.service.ManagedZones.Delete(b.project, zoneID).Context(ctx).Do()
+	if err != nil && !isNotFound(err) {
+		return emperror.With(errors.Wrap(err, "could not delete managed zone"), "domain", domain)
+	}
+
+	return nil
+}
+
+func (b *backend) CreateZone(ctx context.Context, domain string) (string, error) {
+	managedZone, err := b.createZone(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	delegations, err := b.listRecordSets(ctx, b.baseZoneName, domain, "NS")
+	if err != nil {
+		return "", err
+	}
+
+	err = b.changeRecordSets(ctx, b.baseZoneName, &dns.Change{
+		Deletions: delegations,
+		Additions: []*dns.ResourceRecordSet{
+			{
+				Name:    fqdn(domain),
+				Type:    "NS",
+				Ttl:     delegationTTL,
+				Rrdatas: managedZone.NameServers,
+			},
+		},
+	})
+	if err != nil {
+		return "", emperror.With(errors.WithMessage(err, "could not delegate managed zone from the base domain"), "domain", domain)
+	}
+
+	return managedZone.Name, nil
+}
+
+func (b *backend) DeleteZone(ctx context.Context, domain string, zoneID string) error {
+	delegations, err := b.listRecordSets(ctx, b.baseZoneName, domain, "NS")
+	if err != nil {
+		return err
+	}
+
+	err = b.changeRecordSets(ctx, b.baseZoneName, &dns.Change{Deletions: delegations})
+	if err != nil {
+		return emperror.With(errors.WithMessage(err, "could not remove managed zone delegation from the base domain"), "domain", domain)
+	}
+
+	return b.deleteZone(ctx, domain, zoneID)
+}
+
+func (b *backend) DeleteRecordsOwnedBy(ctx context.Context, domain string, zoneID string, ownerID string) error {
+	recordSets, err := b.listRecordSets(ctx, zoneID, "", "")
+	if err != nil {
+		return err
+	}
+
+	ownedNames := make(map[string]bool)
+	for _, recordSet := range recordSets {
+		if recordSet.Type != "TXT" {
+			continue
+		}
+
+		for _, rrdata := range recordSet.Rrdatas {
+			if zone.IsOwnedBy(rrdata, ownerID) {
+				ownedNames[recordSet.Name] = true
+			}
+		}
+	}
+
+	var deletions []*dns.ResourceRecordSet
+	for _, recordSet := range recordSets {
+		if recordSet.Type != "NS" && recordSet.Type != "SOA" && ownedNames[recordSet.Name] {
+			deletions = append(deletions, recordSet)
+		}
+	}
+
+	return b.changeRecordSets(ctx, zoneID, &dns.Change{Deletions: deletions})
+}
+
+func (b *backend) Credentials() (string, map[string]string) {
+	values := make(map[string]string, len(b.config.Credentials))
+	for k, v := range b.config.Credentials {
+		values[k] = v
+	}
+
+	return pkgCluster.Google, values
+}
+
+func (b *backend) Options(domain string, zoneID string) map[string]string {
+	return map[string]string{
+		pkgDns.GoogleProject: b.project,
+	}
+}
+
+func (b *backend) CreateCustomZone(ctx context.Context, domain string) (string, error) {
+	managedZone, err := b.createZone(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	return managedZone.Name, nil
+}
+
+func (b *backend) DeleteCustomZone(ctx context.Context, domain string, zoneID string) error {
+	return b.deleteZone(ctx, domain, zoneID)
+}
+
+func (b *backend) NameServers(ctx context.Context, domain string, zoneID string) ([]string, error) {
+	managedZone, err := b.service.ManagedZones.Get(b.project, zoneID).Context(ctx).Do()
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get managed zone"), "domain", domain)
+	}
+
+	var nameServers []string
+	for _, nameServer := range managedZone.NameServers {
+		nameServers = append(nameServers, zone.NormalizeName(nameServer))
+	}
+
+	return nameServers, nil
+}
+
+func (b *backend) ListRecords(ctx context.Context, domain string, zoneID string) ([]pkgDns.Record, error) {
+	recordSets, err := b.listRecordSets(ctx, zoneID, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	var records []pkgDns.Record
+	for _, recordSet := range recordSets {
+		if recordSet.Type == "SOA" || !zone.InDomain(recordSet.Name, domain) {
+			continue
+		}
+
+		record := pkgDns.Record{
+			Name: zone.NormalizeName(recordSet.Name),
+			Type: recordSet.Type,
+			TTL:  recordSet.Ttl,
+		}
+
+		for _, rrdata := range recordSet.Rrdatas {
+			switch recordSet.Type {
+			case pkgDns.RecordTypeTXT:
+				rrdata = strings.Replace(strings.Trim(rrdata, "\""), "\" \"", "", -1)
+			case pkgDns.RecordTypeCNAME, pkgDns.RecordTypeMX, pkgDns.RecordTypeNS:
+				rrdata = strings.TrimSuffix(rrdata, ".")
+			}
+
+			record.Values = append(record.Values, rrdata)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func (b *backend) UpsertRecord(ctx context.Context, domain string, zoneID string, record pkgDns.Record) error {
+	existing, err := b.listRecordSets(ctx, zoneID, record.Name, record.Type)
+	if err != nil {
+		return err
+	}
+
+	var rrdatas []string
+	for _, value := range record.Values {
+		switch record.Type {
+		case pkgDns.RecordTypeTXT:
+			value = strconv.Quote(value)
+		case pkgDns.RecordTypeCNAME, pkgDns.RecordTypeMX:
+			value = fqdn(value)
+		}
+
+		rrdatas = append(rrdatas, value)
+	}
+
+	return b.changeRecordSets(ctx, zoneID, &dns.Change{
+		Deletions: existing,
+		Additions: []*dns.ResourceRecordSet{
+			{
+				Name:    fqdn(record.Name),
+				Type:    record.Type,
+				Ttl:     record.TTL,
+				Rrdatas: rrdatas,
+			},
+		},
+	})
+}
+
+func (b *backend) DeleteRecord(ctx context.Context, domain string, zoneID string, name string, recordType string) error {
+	existing, err := b.listRecordSets(ctx, zoneID, name, recordType)
+	if err != nil {
+		return err
+	}
+
+	return b.changeRecordSets(ctx, zoneID, &dns.Change{Deletions: existing})
+}
@@ -0,0 +1,162 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/dns/azuredns"
+	"github.com/banzaicloud/pipeline/dns/googledns"
+	"github.com/banzaicloud/pipeline/dns/rfc2136"
+	"github.com/banzaicloud/pipeline/dns/route53"
+	"github.com/banzaicloud/pipeline/dns/zone"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// newProviderClient creates the DnsServiceClient of the given DNS provider.
+// It returns nil if the credentials of the provider are not configured.
+func newProviderClient(provider string, notifications chan interface{}) (DnsServiceClient, error) {
+	var backend zone.Backend
+	var err error
+
+	switch provider {
+	case pkgDns.Route53:
+		return newRoute53Client(notifications)
+
+	case pkgDns.Azure:
+		backend, err = newAzureBackend()
+
+	case pkgDns.Google:
+		backend, err = newGoogleBackend()
+
+	case pkgDns.RFC2136:
+		backend, err = newRFC2136Backend()
+
+	default:
+		return nil, errors.Errorf("unsupported DNS provider: %s", provider)
+	}
+
+	if err != nil || backend == nil {
+		return nil, err
+	}
+
+	return zone.NewClient(provider, backend, config.DB(), notifications, log), nil
+}
+
+func newRoute53Client(notifications chan interface{}) (DnsServiceClient, error) {
+	// This is how the secrets are expected to be written in Vault:
+	// vault kv put secret/banzaicloud/aws AWS_REGION=... AWS_ACCESS_KEY_ID=... AWS_SECRET_ACCESS_KEY=...
+	awsCredentials, err := readVaultCredentials(viper.GetString(config.AwsCredentialPath))
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to read AWS credentials from Vault")
+	}
+
+	region := awsCredentials[secretTypes.AwsRegion]
+	awsSecretId := awsCredentials[secretTypes.AwsAccessKeyId]
+	awsSecretKey := awsCredentials[secretTypes.AwsSecretAccessKey]
+
+	if len(region) == 0 || len(awsSecretId) == 0 || len(awsSecretKey) == 0 {
+		log.Infoln("No AWS credentials for Route53 provided in Vault")
+		return nil, nil
+	}
+
+	awsRoute53, err := route53.NewAwsRoute53(region, awsSecretId, awsSecretKey, notifications)
+	if err != nil {
+		return nil, err
+	}
+
+	return awsRoute53, nil
+}
+
+func newAzureBackend() (zone.Backend, error) {
+	// vault kv put secret/banzaicloud/azure AZURE_CLIENT_ID=... AZURE_CLIENT_SECRET=... AZURE_TENANT_ID=... AZURE_SUBSCRIPTION_ID=...
+	azureCredentials, err := readVaultCredentials(viper.GetString(config.DNSAzureCredentialPath))
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to read Azure credentials from Vault")
+	}
+
+	if len(azureCredentials[secretTypes.AzureClientId]) == 0 {
+		log.Infoln("No Azure credentials for Azure DNS provided in Vault")
+		return nil, nil
+	}
+
+	return azuredns.NewBackend(azuredns.Config{
+		TenantID:       azureCredentials[secretTypes.AzureTenantId],
+		SubscriptionID: azureCredentials[secretTypes.AzureSubscriptionId],
+		ClientID:       azureCredentials[secretTypes.AzureClientId],
+		ClientSecret:   azureCredentials[secretTypes.AzureClientSecret],
+		ResourceGroup:  viper.GetString(config.DNSAzureResourceGroup),
+		BaseDomain:     viper.GetString(config.DNSBaseDomain),
+	})
+}
+
+func newGoogleBackend() (zone.Backend, error) {
+	// The keys of the service account JSON are expected to be written in Vault:
+	// vault kv put secret/banzaicloud/google type=service_account project_id=... private_key=... client_email=... ...
+	googleCredentials, err := readVaultCredentials(viper.GetString(config.DNSGoogleCredentialPath))
+	if err != nil {
+		return nil, emperror.Wrap(err, "failed to read Google credentials from Vault")
+	}
+
+	if len(googleCredentials[secretTypes.ProjectId]) == 0 {
+		log.Infoln("No Google service account for Google Cloud DNS provided in Vault")
+		return nil, nil
+	}
+
+	return googledns.NewBackend(googledns.Config{
+		Credentials: googleCredentials,
+		BaseDomain:  viper.GetString(config.DNSBaseDomain),
+	})
+}
+
+func newRFC2136Backend() (zone.Backend, error) {
+	if len(viper.GetString(config.DNSRFC2136Host)) == 0 {
+		log.Infoln("No RFC2136 name server configured")
+		return nil, nil
+	}
+
+	return rfc2136.NewBackend(rfc2136.Config{
+		Host:          viper.GetString(config.DNSRFC2136Host),
+		Port:          viper.GetInt(config.DNSRFC2136Port),
+		Zone:          viper.GetString(config.DNSRFC2136Zone),
+		TSIGKeyName:   viper.GetString(config.DNSRFC2136TSIGKeyName),
+		TSIGSecret:    viper.GetString(config.DNSRFC2136TSIGSecret),
+		TSIGAlgorithm: viper.GetString(config.DNSRFC2136TSIGAlgorithm),
+	})
+}
+
+// readVaultCredentials reads the key-value pairs stored at the given Vault path.
+// A missing path means the provider is not configured, but without Vault the credentials cannot be read at all.
+func readVaultCredentials(path string) (map[string]string, error) {
+	vaultSecret, err := secret.ReadVaultPath(path)
+	if err != nil {
+		return nil, emperror.With(err, "path", path)
+	}
+
+	if vaultSecret == nil {
+		if !secret.IsVaultStore() {
+			return nil, emperror.With(errors.New("DNS provider credentials require the Vault secret store backend"), "path", path)
+		}
+
+		return map[string]string{}, nil
+	}
+
+	return cast.ToStringMapString(vaultSecret.Data["data"]), nil
+}
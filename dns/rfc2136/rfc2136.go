@@ -0,0 +1,349 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rfc2136
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/dns/zone"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/goph/emperror"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultPort   = 53
+	tsigFudge     = 300
+	exchangeLimit = 30 * time.Second
+)
+
+// Config holds the name server and the TSIG key used for dynamic updates and zone transfers.
+type Config struct {
+	Host string
+	Port int
+
+	// Zone is the zone managed by the name server containing the organization domains
+	Zone string
+
+	TSIGKeyName   string
+	TSIGSecret    string
+	TSIGAlgorithm string
+}
+
+// backend manages the records of organization domains in a zone of an RFC2136 (eg. BIND) name server.
+// The name server does not support creating zones, so organization domains are subdomains of a single zone.
+type backend struct {
+	config Config
+}
+
+// NewBackend returns a new RFC2136 backend.
+func NewBackend(config Config) (zone.Backend, error) {
+	if config.Host == "" {
+		return nil, errors.New("RFC2136 name server host is not configured")
+	}
+
+	if config.Zone == "" {
+		return nil, errors.New("RFC2136 zone is not configured")
+	}
+
+	if config.Port == 0 {
+		config.Port = defaultPort
+	}
+
+	if config.TSIGAlgorithm == "" {
+		config.TSIGAlgorithm = dns.HmacMD5
+	}
+	config.TSIGAlgorithm = dns.Fqdn(config.TSIGAlgorithm)
+
+	switch config.TSIGAlgorithm {
+	case dns.HmacMD5, dns.HmacSHA1, dns.HmacSHA256, dns.HmacSHA512:
+	default:
+		return nil, emperror.With(errors.New("unsupported TSIG algorithm"), "algorithm", config.TSIGAlgorithm)
+	}
+
+	config.Zone = dns.Fqdn(config.Zone)
+
+	return &backend{config: config}, nil
+}
+
+func (b *backend) address() string {
+	return net.JoinHostPort(b.config.Host, strconv.Itoa(b.config.Port))
+}
+
+func (b *backend) tsigSecret() map[string]string {
+	if b.config.TSIGKeyName == "" {
+		return nil
+	}
+
+	return map[string]string{dns.Fqdn(b.config.TSIGKeyName): b.config.TSIGSecret}
+}
+
+func (b *backend) sign(msg *dns.Msg) {
+	if b.config.TSIGKeyName != "" {
+		msg.SetTsig(dns.Fqdn(b.config.TSIGKeyName), b.config.TSIGAlgorithm, tsigFudge, time.Now().Unix())
+	}
+}
+
+// transfer returns the records of the zone.
+func (b *backend) transfer() ([]dns.RR, error) {
+	msg := new(dns.Msg)
+	msg.SetAxfr(b.config.Zone)
+	b.sign(msg)
+
+	transfer := &dns.Transfer{
+		DialTimeout:  exchangeLimit,
+		ReadTimeout:  exchangeLimit,
+		WriteTimeout: exchangeLimit,
+		TsigSecret:   b.tsigSecret(),
+	}
+
+	envelopes, err := transfer.In(msg, b.address())
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "zone transfer failed"), "zone", b.config.Zone)
+	}
+
+	var records []dns.RR
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, emperror.With(errors.Wrap(envelope.Error, "zone transfer failed"), "zone", b.config.Zone)
+		}
+
+		records = append(records, envelope.RR...)
+	}
+
+	return records, nil
+}
+
+// removeNames deletes every record set of the given names.
+func (b *backend) removeNames(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(b.config.Zone)
+
+	var records []dns.RR
+	for _, name := range names {
+		records = append(records, &dns.ANY{Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeANY, Class: dns.ClassANY}})
+	}
+	msg.RemoveName(records)
+
+	return b.update(msg)
+}
+
+// update sends a signed dynamic update message to the name server.
+func (b *backend) update(msg *dns.Msg) error {
+	b.sign(msg)
+
+	client := &dns.Client{
+		Net:        "tcp",
+		Timeout:    exchangeLimit,
+		TsigSecret: b.tsigSecret(),
+	}
+
+	resp, _, err := client.Exchange(msg, b.address())
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "dynamic update failed"), "zone", b.config.Zone)
+	}
+
+	if resp.Rcode != dns.RcodeSuccess {
+		return emperror.With(errors.New("dynamic update refused"), "zone", b.config.Zone, "rcode", dns.RcodeToString[resp.Rcode])
+	}
+
+	return nil
+}
+
+func (b *backend) CreateZone(ctx context.Context, domain string) (string, error) {
+	if !dns.IsSubDomain(b.config.Zone, dns.Fqdn(domain)) {
+		return "", emperror.With(errors.New("domain is not part of the zone of the name server"), "domain", domain, "zone", b.config.Zone)
+	}
+
+	// Check that zone transfers (needed to clean up records) are permitted
+	if _, err := b.transfer(); err != nil {
+		return "", err
+	}
+
+	return b.config.Zone, nil
+}
+
+func (b *backend) DeleteZone(ctx context.Context, domain string, zoneID string) error {
+	records, err := b.transfer()
+	if err != nil {
+		return err
+	}
+
+	domain = dns.Fqdn(domain)
+
+	return b.removeNames(filterNames(records, func(record dns.RR) bool {
+		return dns.IsSubDomain(domain, record.Header().Name)
+	}))
+}
+
+func (b *backend) DeleteRecordsOwnedBy(ctx context.Context, domain string, zoneID string, ownerID string) error {
+	records, err := b.transfer()
+	if err != nil {
+		return err
+	}
+
+	domain = dns.Fqdn(domain)
+
+	return b.removeNames(filterNames(records, func(record dns.RR) bool {
+		txt, ok := record.(*dns.TXT)
+
+		return ok && dns.IsSubDomain(domain, txt.Hdr.Name) && zone.IsOwnedBy(strings.Join(txt.Txt, ""), ownerID)
+	}))
+}
+
+// filterNames returns the distinct names of the matching records, except the NS and SOA records.
+func filterNames(records []dns.RR, match func(dns.RR) bool) []string {
+	var names []string
+	seen := make(map[string]bool)
+
+	for _, record := range records {
+		header := record.Header()
+		if header.Rrtype == dns.TypeNS || header.Rrtype == dns.TypeSOA || seen[header.Name] || !match(record) {
+			continue
+		}
+
+		seen[header.Name] = true
+		names = append(names, header.Name)
+	}
+
+	return names
+}
+
+func (b *backend) Credentials() (string, map[string]string) {
+	return pkgSecret.GenericSecret, map[string]string{
+		pkgDns.RFC2136TSIGSecret: b.config.TSIGSecret,
+	}
+}
+
+func (b *backend) Options(domain string, zoneID string) map[string]string {
+	return map[string]string{
+		pkgDns.RFC2136Host:        b.config.Host,
+		pkgDns.RFC2136Port:        strconv.Itoa(b.config.Port),
+		pkgDns.RFC2136Zone:        strings.TrimSuffix(b.config.Zone, "."),
+		pkgDns.RFC2136TSIGKeyName: b.config.TSIGKeyName,
+		pkgDns.RFC2136TSIGAlg:     strings.TrimSuffix(b.config.TSIGAlgorithm, "."),
+	}
+}
+
+func (b *backend) CreateCustomZone(ctx context.Context, domain string) (string, error) {
+	return "", &zone.UnsupportedError{Provider: pkgDns.RFC2136, Operation: "creating zones"}
+}
+
+func (b *backend) DeleteCustomZone(ctx context.Context, domain string, zoneID string) error {
+	return &zone.UnsupportedError{Provider: pkgDns.RFC2136, Operation: "deleting zones"}
+}
+
+func (b *backend) NameServers(ctx context.Context, domain string, zoneID string) ([]string, error) {
+	return nil, &zone.UnsupportedError{Provider: pkgDns.RFC2136, Operation: "delegating zones"}
+}
+
+func (b *backend) ListRecords(ctx context.Context, domain string, zoneID string) ([]pkgDns.Record, error) {
+	rrs, err := b.transfer()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []pkgDns.Record
+	index := make(map[string]int)
+
+	for _, rr := range rrs {
+		header := rr.Header()
+		if header.Rrtype == dns.TypeSOA || !zone.InDomain(header.Name, domain) {
+			continue
+		}
+
+		var value string
+		switch r := rr.(type) {
+		case *dns.TXT:
+			value = strings.Join(r.Txt, "")
+		default:
+			value = strings.TrimSuffix(strings.TrimPrefix(rr.String(), header.String()), ".")
+		}
+
+		recordType := dns.TypeToString[header.Rrtype]
+		key := header.Name + " " + recordType
+
+		if i, ok := index[key]; ok {
+			records[i].Values = append(records[i].Values, value)
+			continue
+		}
+
+		index[key] = len(records)
+		records = append(records, pkgDns.Record{
+			Name:   zone.NormalizeName(header.Name),
+			Type:   recordType,
+			TTL:    int64(header.Ttl),
+			Values: []string{value},
+		})
+	}
+
+	return records, nil
+}
+
+func (b *backend) UpsertRecord(ctx context.Context, domain string, zoneID string, record pkgDns.Record) error {
+	var rrs []dns.RR
+	for _, value := range record.Values {
+		switch record.Type {
+		case pkgDns.RecordTypeTXT:
+			value = strconv.Quote(value)
+		case pkgDns.RecordTypeCNAME:
+			value = dns.Fqdn(value)
+		case pkgDns.RecordTypeMX:
+			preference, host, err := zone.ParseMX(value)
+			if err != nil {
+				return err
+			}
+			value = fmt.Sprintf("%d %s", preference, dns.Fqdn(host))
+		}
+
+		rr, err := dns.NewRR(fmt.Sprintf("%s %d IN %s %s", dns.Fqdn(record.Name), record.TTL, record.Type, value))
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "invalid DNS record"), "name", record.Name, "type", record.Type)
+		}
+
+		rrs = append(rrs, rr)
+	}
+
+	msg := new(dns.Msg)
+	msg.SetUpdate(b.config.Zone)
+	msg.RemoveRRset(rrSetHeader(record.Name, record.Type))
+	msg.Insert(rrs)
+
+	return b.update(msg)
+}
+
+func (b *backend) DeleteRecord(ctx context.Context, domain string, zoneID string, name string, recordType string) error {
+	msg := new(dns.Msg)
+	msg.SetUpdate(b.config.Zone)
+	msg.RemoveRRset(rrSetHeader(name, recordType))
+
+	return b.update(msg)
+}
+
+// rrSetHeader returns a record identifying the record set with the given name and type.
+func rrSetHeader(name string, recordType string) []dns.RR {
+	return []dns.RR{&dns.ANY{Hdr: dns.RR_Header{Name: dns.Fqdn(name), Rrtype: dns.StringToType[recordType], Class: dns.ClassINET}}}
+}
@@ -0,0 +1,263 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rfc2136
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/miekg/dns"
+)
+
+const (
+	testKeyName = "pipeline."
+	testSecret  = "c2VjcmV0LXNlY3JldC1zZWNyZXQ="
+)
+
+// testNameServer is a minimal name server supporting TSIG signed zone transfers and dynamic updates.
+type testNameServer struct {
+	records []dns.RR
+
+	removedNames []string
+	updates      []dns.RR
+	mu           sync.Mutex
+}
+
+func (s *testNameServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	resp := new(dns.Msg)
+	resp.SetReply(req)
+
+	if req.IsTsig() == nil || w.TsigStatus() != nil {
+		resp.SetRcode(req, dns.RcodeNotAuth)
+		w.WriteMsg(resp)
+		return
+	}
+
+	switch req.Opcode {
+	case dns.OpcodeQuery:
+		if req.Question[0].Qtype != dns.TypeAXFR {
+			resp.SetRcode(req, dns.RcodeNotImplemented)
+			break
+		}
+
+		resp.Answer = s.records
+
+	case dns.OpcodeUpdate:
+		s.mu.Lock()
+		for _, record := range req.Ns {
+			s.removedNames = append(s.removedNames, record.Header().Name)
+		}
+		s.updates = append(s.updates, req.Ns...)
+		s.mu.Unlock()
+	}
+
+	resp.SetTsig(testKeyName, dns.HmacMD5, tsigFudge, time.Now().Unix())
+	w.WriteMsg(resp)
+}
+
+func newTestBackend(t *testing.T, nameServer *testNameServer) (*backend, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := &dns.Server{
+		Listener:   listener,
+		Handler:    nameServer,
+		TsigSecret: map[string]string{testKeyName: testSecret},
+
+		// The default accept function refuses dynamic updates
+		MsgAcceptFunc: func(dns.Header) dns.MsgAcceptAction { return dns.MsgAccept },
+	}
+
+	go server.ActivateAndServe()
+
+	addr := listener.Addr().(*net.TCPAddr)
+
+	b, err := NewBackend(Config{
+		Host:        addr.IP.String(),
+		Port:        addr.Port,
+		Zone:        "example.com",
+		TSIGKeyName: testKeyName,
+		TSIGSecret:  testSecret,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b.(*backend), func() { server.Shutdown() }
+}
+
+func mustRR(t *testing.T, s string) dns.RR {
+	record, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return record
+}
+
+func TestBackend_DeleteRecordsOwnedBy(t *testing.T) {
+	soa := mustRR(t, "example.com. 300 IN SOA ns.example.com. admin.example.com. 1 3600 600 86400 300")
+
+	nameServer := &testNameServer{
+		records: []dns.RR{
+			soa,
+			mustRR(t, "example.com. 300 IN NS ns.example.com."),
+			mustRR(t, `app.org.example.com. 300 IN TXT "heritage=external-dns,external-dns/owner=cluster-1"`),
+			mustRR(t, "app.org.example.com. 300 IN A 10.0.0.1"),
+			mustRR(t, `web.org.example.com. 300 IN TXT "heritage=external-dns,external-dns/owner=cluster-10"`),
+			mustRR(t, "web.org.example.com. 300 IN A 10.0.0.2"),
+			mustRR(t, `app.other.example.com. 300 IN TXT "heritage=external-dns,external-dns/owner=cluster-1"`),
+			soa,
+		},
+	}
+
+	b, shutdown := newTestBackend(t, nameServer)
+	defer shutdown()
+
+	err := b.DeleteRecordsOwnedBy(context.Background(), "org.example.com", "example.com.", "cluster-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expected := []string{"app.org.example.com."}
+	if !reflect.DeepEqual(expected, nameServer.removedNames) {
+		t.Errorf("expected removed names %v, got %v", expected, nameServer.removedNames)
+	}
+}
+
+func TestBackend_DeleteZone(t *testing.T) {
+	soa := mustRR(t, "example.com. 300 IN SOA ns.example.com. admin.example.com. 1 3600 600 86400 300")
+
+	nameServer := &testNameServer{
+		records: []dns.RR{
+			soa,
+			mustRR(t, "example.com. 300 IN NS ns.example.com."),
+			mustRR(t, "app.org.example.com. 300 IN A 10.0.0.1"),
+			mustRR(t, "app.org.example.com. 300 IN TXT \"heritage=external-dns\""),
+			mustRR(t, "org.example.com. 300 IN A 10.0.0.2"),
+			mustRR(t, "app.other.example.com. 300 IN A 10.0.0.3"),
+			soa,
+		},
+	}
+
+	b, shutdown := newTestBackend(t, nameServer)
+	defer shutdown()
+
+	err := b.DeleteZone(context.Background(), "org.example.com", "example.com.")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	sort.Strings(nameServer.removedNames)
+
+	expected := []string{"app.org.example.com.", "org.example.com."}
+	if !reflect.DeepEqual(expected, nameServer.removedNames) {
+		t.Errorf("expected removed names %v, got %v", expected, nameServer.removedNames)
+	}
+}
+
+func TestBackend_CreateZone(t *testing.T) {
+	b, err := NewBackend(Config{Host: "127.0.0.1", Zone: "example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = b.CreateZone(context.Background(), "org.example.org")
+	if err == nil {
+		t.Error("expected error for a domain outside of the zone")
+	}
+}
+
+func TestBackend_ListRecords(t *testing.T) {
+	soa := mustRR(t, "example.com. 300 IN SOA ns.example.com. admin.example.com. 1 3600 600 86400 300")
+
+	nameServer := &testNameServer{
+		records: []dns.RR{
+			soa,
+			mustRR(t, "example.com. 300 IN NS ns.example.com."),
+			mustRR(t, `app.org.example.com. 300 IN TXT "heritage=external-dns,external-dns/owner=cluster-1"`),
+			mustRR(t, "app.org.example.com. 300 IN A 10.0.0.1"),
+			mustRR(t, "app.org.example.com. 300 IN A 10.0.0.2"),
+			mustRR(t, "www.org.example.com. 60 IN CNAME app.org.example.com."),
+			mustRR(t, "app.other.example.com. 300 IN A 10.0.0.3"),
+			soa,
+		},
+	}
+
+	b, shutdown := newTestBackend(t, nameServer)
+	defer shutdown()
+
+	records, err := b.ListRecords(context.Background(), "org.example.com", "example.com.")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expected := []pkgDns.Record{
+		{Name: "app.org.example.com", Type: "TXT", TTL: 300, Values: []string{"heritage=external-dns,external-dns/owner=cluster-1"}},
+		{Name: "app.org.example.com", Type: "A", TTL: 300, Values: []string{"10.0.0.1", "10.0.0.2"}},
+		{Name: "www.org.example.com", Type: "CNAME", TTL: 60, Values: []string{"app.org.example.com"}},
+	}
+	if !reflect.DeepEqual(expected, records) {
+		t.Errorf("expected records %v, got %v", expected, records)
+	}
+}
+
+func TestBackend_UpsertRecord(t *testing.T) {
+	nameServer := &testNameServer{}
+
+	b, shutdown := newTestBackend(t, nameServer)
+	defer shutdown()
+
+	err := b.UpsertRecord(context.Background(), "org.example.com", "example.com.", pkgDns.Record{
+		Name:   "mail.org.example.com",
+		Type:   "MX",
+		TTL:    300,
+		Values: []string{"10 mx1.example.net", "20 mx2.example.net"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(nameServer.updates) != 3 {
+		t.Fatalf("expected 3 update records, got %d", len(nameServer.updates))
+	}
+
+	// The record set is deleted first, then the records are added
+	header := nameServer.updates[0].Header()
+	if header.Name != "mail.org.example.com." || header.Rrtype != dns.TypeMX || header.Class != dns.ClassANY {
+		t.Errorf("expected record set deletion, got %s", nameServer.updates[0].String())
+	}
+
+	var additions []string
+	for _, update := range nameServer.updates[1:] {
+		additions = append(additions, update.String())
+	}
+
+	expected := []string{
+		"mail.org.example.com.\t300\tIN\tMX\t10 mx1.example.net.",
+		"mail.org.example.com.\t300\tIN\tMX\t20 mx2.example.net.",
+	}
+	if !reflect.DeepEqual(expected, additions) {
+		t.Errorf("expected additions %q, got %q", expected, additions)
+	}
+}
@@ -77,14 +77,17 @@ func (stateStore *awsRoute53DatabaseStateStore) find(orgId uint, domain string,
 }
 
 // listUnused returns all the domain state entries from database that belong to organizations with no live clusters
+// and no custom domains
 // thus the DNS domain entries earlier created for these domain are not used any more
 func (stateStore *awsRoute53DatabaseStateStore) listUnused() ([]domainState, error) {
 	db := config.DB()
 	var dbRecs []route53model.Route53Domain
 
 	sqlFilter := fmt.Sprintf("organization_id NOT IN (SELECT organization_id FROM clusters WHERE deleted_at is NULL AND status<>'%s')", cluster.Error)
+	// custom domains are managed with the credentials of the organization domain
+	customDomainsFilter := "organization_id NOT IN (SELECT organization_id FROM dns_custom_domains)"
 
-	err := db.Where(&route53model.Route53Domain{Status: CREATED}).Where(sqlFilter).Find(&dbRecs).Error
+	err := db.Where(&route53model.Route53Domain{Status: CREATED}).Where(sqlFilter).Where(customDomainsFilter).Find(&dbRecs).Error
 	if err != nil {
 		return nil, err
 	}
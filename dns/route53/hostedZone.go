@@ -22,6 +22,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/banzaicloud/pipeline/dns/zone"
 	"github.com/banzaicloud/pipeline/pkg/amazon"
 	"github.com/sirupsen/logrus"
 )
@@ -148,21 +149,18 @@ func (dns *awsRoute53) deleteHostedZone(id *string) error {
 func (dns *awsRoute53) deleteHostedZoneResourceRecordSetsOwnedBy(hostedZoneId *string, ownerId string) error {
 	log := loggerWithFields(logrus.Fields{"hosted zone": aws.StringValue(hostedZoneId), "ownerId": ownerId})
 
-	listResourceRecordSetsInput := &route53.ListResourceRecordSetsInput{HostedZoneId: hostedZoneId}
-	resourceRecordSets, err := dns.route53Svc.ListResourceRecordSets(listResourceRecordSetsInput)
+	resourceRecordSets, err := dns.listResourceRecordSets(hostedZoneId)
 	if err != nil {
 		log.Errorf("retrieving resource record sets of the hosted zone failed: %s", extractErrorMessage(err))
 		return err
 	}
 
-	ownerReference := "external-dns/owner=" + ownerId
-
 	var ownedRecordNames = make(map[string]bool)
 
-	for _, resourceRecordSet := range resourceRecordSets.ResourceRecordSets {
+	for _, resourceRecordSet := range resourceRecordSets {
 		if aws.StringValue(resourceRecordSet.Type) == route53.RRTypeTxt {
 			for _, resourceRecord := range resourceRecordSet.ResourceRecords {
-				if strings.Contains(aws.StringValue(resourceRecord.Value), ownerReference) {
+				if zone.IsOwnedBy(aws.StringValue(resourceRecord.Value), ownerId) {
 					ownedRecordNames[aws.StringValue(resourceRecordSet.Name)] = true
 					break
 				}
@@ -171,7 +169,7 @@ func (dns *awsRoute53) deleteHostedZoneResourceRecordSetsOwnedBy(hostedZoneId *s
 	}
 
 	var resourceRecordSetChanges []*route53.ResourceRecordSet
-	for _, resourceRecordSet := range resourceRecordSets.ResourceRecordSets {
+	for _, resourceRecordSet := range resourceRecordSets {
 		if aws.StringValue(resourceRecordSet.Type) != route53.RRTypeNs && aws.StringValue(resourceRecordSet.Type) != route53.RRTypeSoa {
 			if _, ok := ownedRecordNames[aws.StringValue(resourceRecordSet.Name)]; ok {
 				resourceRecordSetChanges = append(resourceRecordSetChanges, resourceRecordSet)
@@ -190,6 +188,33 @@ func (dns *awsRoute53) deleteHostedZoneResourceRecordSetsOwnedBy(hostedZoneId *s
 	return nil
 }
 
+// listResourceRecordSets returns all resource record sets of the hosted zone identified by the given id
+// following the pagination of the Route53 API.
+func (dns *awsRoute53) listResourceRecordSets(hostedZoneId *string) ([]*route53.ResourceRecordSet, error) {
+	var resourceRecordSets []*route53.ResourceRecordSet
+
+	input := &route53.ListResourceRecordSetsInput{HostedZoneId: hostedZoneId}
+	for {
+		output, err := dns.route53Svc.ListResourceRecordSets(input)
+		if err != nil {
+			return nil, err
+		}
+
+		resourceRecordSets = append(resourceRecordSets, output.ResourceRecordSets...)
+
+		if !aws.BoolValue(output.IsTruncated) {
+			return resourceRecordSets, nil
+		}
+
+		input = &route53.ListResourceRecordSetsInput{
+			HostedZoneId:          hostedZoneId,
+			StartRecordName:       output.NextRecordName,
+			StartRecordType:       output.NextRecordType,
+			StartRecordIdentifier: output.NextRecordIdentifier,
+		}
+	}
+}
+
 // setHostedZoneAuthorisation sets up authorisation for the Route53 hosted zone identified by the specified id.
 // It creates a policy that allows changing only the specified hosted zone and a IAM user with the policy attached.
 func (dns *awsRoute53) setHostedZoneAuthorisation(hostedZoneId string, ctx *context) error {
@@ -38,6 +38,16 @@ func (dns *awsRoute53) createHostedZoneRoute53Policy(orgId uint, hostedZoneId st
 	}
 
 	policyName := fmt.Sprintf(hostedZoneAccessPolicyNameTemplate, getHashedControlPlaneHostName(viper.GetString(config.DNSBaseDomain)), org.Name)
+	policyDescription := fmt.Sprintf("Access permissions for hosted zone of the '%s' organization", org.Name)
+
+	return dns.createRoute53Policy(policyName, policyDescription, hostedZoneId)
+}
+
+// createRoute53Policy creates an AWS policy with the given name that allows listing route53 hosted zones and record sets
+// in general also modifying only the records of the hosted zone identified by the given id.
+func (dns *awsRoute53) createRoute53Policy(policyName, description, hostedZoneId string) (*iam.Policy, error) {
+	log := loggerWithFields(logrus.Fields{"hostedzone": hostedZoneId})
+
 	policyDocument := aws.String(fmt.Sprintf(
 		`{
 		"Version": "2012-10-17",
@@ -61,10 +71,10 @@ func (dns *awsRoute53) createHostedZoneRoute53Policy(orgId uint, hostedZoneId st
 				"Resource": "arn:aws:route53:::change/*"
 			}
 		]}`, hostedZoneId))
-	policyDescription := aws.String(fmt.Sprintf("Access permissions for hosted zone of the '%s' organization", org.Name))
+	policyDescription := aws.String(description)
 
 	var policy *iam.Policy
-	policy, err = amazon.CreatePolicy(dns.iamSvc, aws.String(policyName), policyDocument, policyDescription)
+	policy, err := amazon.CreatePolicy(dns.iamSvc, aws.String(policyName), policyDocument, policyDescription)
 	if aerr, ok := err.(awserr.Error); ok {
 		if aerr.Code() == iam.ErrCodeEntityAlreadyExistsException {
 			policy, err = amazon.GetPolicyByName(dns.iamSvc, policyName, "Local")
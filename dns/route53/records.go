@@ -0,0 +1,215 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package route53
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/dns/zone"
+	"github.com/banzaicloud/pipeline/pkg/amazon"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// CreateCustomZone creates a hosted zone for a custom domain of the organization and grants
+// access to it for the IAM user of the organization. Returns the id and the name servers of the hosted zone.
+func (dns *awsRoute53) CreateCustomZone(orgId uint, domain string) (string, []string, error) {
+	log := loggerWithFields(logrus.Fields{"organisationId": orgId, "domain": domain})
+
+	org, err := dns.getOrganization(orgId)
+	if err != nil {
+		log.Errorf("retrieving organization details failed: %s", extractErrorMessage(err))
+		return "", nil, err
+	}
+
+	hostedZoneId, err := dns.hostedZoneExistsByDomain(domain)
+	if err != nil {
+		log.Errorf("querying hosted zone failed: %s", extractErrorMessage(err))
+		return "", nil, err
+	}
+
+	if hostedZoneId == "" {
+		hostedZone, err := dns.createHostedZone(domain)
+		if err != nil {
+			return "", nil, err
+		}
+
+		hostedZoneId = aws.StringValue(hostedZone.Id)
+	}
+
+	hostedZone, err := dns.getHostedZoneWithNameServers(aws.String(hostedZoneId))
+	if err != nil {
+		log.Errorf("retrieving hosted zone failed: %s", extractErrorMessage(err))
+		return "", nil, err
+	}
+
+	policy, err := dns.createRoute53Policy(
+		customZonePolicyName(org.Name, domain),
+		fmt.Sprintf("Access permissions for hosted zone of the '%s' domain of the '%s' organization", domain, org.Name),
+		stripHostedZoneId(hostedZoneId),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := dns.attachUserPolicy(aws.String(getIAMUserName(org)), policy.Arn); err != nil {
+		return "", nil, err
+	}
+
+	var nameServers []string
+	if hostedZone.DelegationSet != nil {
+		nameServers = aws.StringValueSlice(hostedZone.DelegationSet.NameServers)
+	}
+
+	return stripHostedZoneId(hostedZoneId), nameServers, nil
+}
+
+// DeleteCustomZone revokes the access of the IAM user of the organization to the hosted zone
+// of the custom domain and deletes the hosted zone.
+func (dns *awsRoute53) DeleteCustomZone(orgId uint, domain string, zoneID string) error {
+	log := loggerWithFields(logrus.Fields{"organisationId": orgId, "domain": domain, "hostedzone": zoneID})
+
+	org, err := dns.getOrganization(orgId)
+	if err != nil {
+		log.Errorf("retrieving organization details failed: %s", extractErrorMessage(err))
+		return err
+	}
+
+	policy, err := amazon.GetPolicyByName(dns.iamSvc, customZonePolicyName(org.Name, domain), "Local")
+	if err != nil {
+		log.Errorf("retrieving access policy failed: %s", extractErrorMessage(err))
+		return err
+	}
+
+	if policy != nil {
+		if err := dns.detachUserPolicy(aws.String(getIAMUserName(org)), policy.Arn); err != nil {
+			return err
+		}
+
+		if err := dns.deletePolicy(policy.Arn); err != nil {
+			return err
+		}
+	}
+
+	return dns.deleteHostedZone(aws.String(zoneID))
+}
+
+// ListRecords returns the record sets of the hosted zone identified by the given id.
+func (dns *awsRoute53) ListRecords(orgId uint, domain string, zoneID string) ([]pkgDns.Record, error) {
+	resourceRecordSets, err := dns.listResourceRecordSets(aws.String(zoneID))
+	if err != nil {
+		return nil, errors.Wrap(wrapAwsError(err), "failed to list record sets")
+	}
+
+	var records []pkgDns.Record
+	for _, resourceRecordSet := range resourceRecordSets {
+		recordType := aws.StringValue(resourceRecordSet.Type)
+		if recordType == route53.RRTypeSoa {
+			continue
+		}
+
+		record := pkgDns.Record{
+			Name: recordSetName(resourceRecordSet.Name),
+			Type: recordType,
+			TTL:  aws.Int64Value(resourceRecordSet.TTL),
+		}
+
+		if resourceRecordSet.AliasTarget != nil {
+			record.Values = []string{zone.NormalizeName(aws.StringValue(resourceRecordSet.AliasTarget.DNSName))}
+		}
+
+		for _, resourceRecord := range resourceRecordSet.ResourceRecords {
+			value := aws.StringValue(resourceRecord.Value)
+			if recordType == route53.RRTypeTxt {
+				if unquoted, err := strconv.Unquote(value); err == nil {
+					value = unquoted
+				}
+			}
+
+			record.Values = append(record.Values, value)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// UpsertRecord creates or replaces the record set in the hosted zone identified by the given id.
+func (dns *awsRoute53) UpsertRecord(orgId uint, domain string, zoneID string, record pkgDns.Record) error {
+	resourceRecordSet := &route53.ResourceRecordSet{
+		Name: aws.String(zone.NormalizeName(record.Name)),
+		Type: aws.String(record.Type),
+		TTL:  aws.Int64(record.TTL),
+	}
+
+	for _, value := range record.Values {
+		if record.Type == route53.RRTypeTxt {
+			value = strconv.Quote(value)
+		}
+
+		resourceRecordSet.ResourceRecords = append(resourceRecordSet.ResourceRecords, &route53.ResourceRecord{
+			Value: aws.String(value),
+		})
+	}
+
+	err := dns.updateResourceRecordSets(aws.String(zoneID), []*route53.ResourceRecordSet{resourceRecordSet})
+	if err != nil {
+		return errors.Wrap(wrapAwsError(err), "failed to upsert record set")
+	}
+
+	return nil
+}
+
+// DeleteRecord deletes the record set with the given name and type from the hosted zone identified by the given id.
+func (dns *awsRoute53) DeleteRecord(orgId uint, domain string, zoneID string, name string, recordType string) error {
+	resourceRecordSets, err := dns.listResourceRecordSets(aws.String(zoneID))
+	if err != nil {
+		return errors.Wrap(wrapAwsError(err), "failed to list record sets")
+	}
+
+	name = zone.NormalizeName(name)
+
+	for _, resourceRecordSet := range resourceRecordSets {
+		if recordSetName(resourceRecordSet.Name) == name && aws.StringValue(resourceRecordSet.Type) == recordType {
+			err := dns.deleteResourceRecordSets(aws.String(zoneID), []*route53.ResourceRecordSet{resourceRecordSet})
+			if err != nil {
+				return errors.Wrap(wrapAwsError(err), "failed to delete record set")
+			}
+
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// recordSetName returns the normalized name of a record set. Route53 returns the '*' character
+// of wildcard records in its octal escaped form.
+func recordSetName(name *string) string {
+	return zone.NormalizeName(strings.Replace(aws.StringValue(name), "\\052", "*", 1))
+}
+
+// customZonePolicyName returns the name of the access policy of the hosted zone of a custom domain.
+func customZonePolicyName(orgName string, domain string) string {
+	return fmt.Sprintf(hostedZoneAccessPolicyNameTemplate, getHashedControlPlaneHostName(viper.GetString(config.DNSBaseDomain)), orgName) + "." + domain
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/banzaicloud/pipeline/config"
 	"github.com/banzaicloud/pipeline/pkg/amazon"
 	"github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
 	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
 	"github.com/banzaicloud/pipeline/secret"
 	"github.com/jinzhu/now"
@@ -669,6 +670,38 @@ func (dns *awsRoute53) GetOrgDomain(orgId uint) (string, error) {
 	return fmt.Sprintf("%s", response.result), nil
 }
 
+// GetProviderSettings returns the settings of the domain registered for the organization with given id
+func (dns *awsRoute53) GetProviderSettings(orgId uint) (*pkgDns.ProviderSettings, error) {
+	domain, err := dns.GetOrgDomain(orgId)
+	if err != nil || domain == "" {
+		return nil, err
+	}
+
+	route53Secret, err := dns.getRoute53Secret(orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	if route53Secret == nil {
+		return nil, nil
+	}
+
+	state := &domainState{}
+	if _, err := dns.stateStore.findByOrgId(orgId, state); err != nil {
+		return nil, err
+	}
+
+	return &pkgDns.ProviderSettings{
+		Provider: pkgDns.Route53,
+		Domain:   domain,
+		ZoneID:   stripHostedZoneId(state.hostedZoneId),
+		SecretID: route53Secret.ID,
+		Options: map[string]string{
+			pkgDns.Route53Region: dns.region,
+		},
+	}, nil
+}
+
 // setupAmazonAccess creates Amazon access key for the IAM user
 // and stores it in Vault. If there is a stale Amazon access key in Vault
 // creates a new Amazon access key and updates Vault
@@ -0,0 +1,438 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zone
+
+import (
+	"context"
+	"sync"
+
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Backend manages the zones of organization domains in a DNS provider.
+type Backend interface {
+	// CreateZone creates the zone of a domain (or returns the existing one) and delegates it from the base domain.
+	CreateZone(ctx context.Context, domain string) (string, error)
+
+	// DeleteZone removes the delegation of a domain from the base domain and deletes its zone.
+	DeleteZone(ctx context.Context, domain string, zoneID string) error
+
+	// DeleteRecordsOwnedBy deletes the records of a zone created by the external-dns instance with the given owner ID.
+	DeleteRecordsOwnedBy(ctx context.Context, domain string, zoneID string, ownerID string) error
+
+	// Credentials returns the secret type and values clients can use to manage the records of the zones.
+	// The credentials are not restricted to a zone, so a backend must only serve a single organization.
+	Credentials() (string, map[string]string)
+
+	// Options returns the provider specific settings clients need to manage the records of a zone.
+	Options(domain string, zoneID string) map[string]string
+
+	// CreateCustomZone creates the zone of a custom domain (or returns the existing one) without delegating it.
+	CreateCustomZone(ctx context.Context, domain string) (string, error)
+
+	// DeleteCustomZone deletes the zone of a custom domain.
+	DeleteCustomZone(ctx context.Context, domain string, zoneID string) error
+
+	// NameServers returns the name servers a parent domain has to delegate a zone to.
+	NameServers(ctx context.Context, domain string, zoneID string) ([]string, error)
+
+	// ListRecords returns the record sets of a domain.
+	ListRecords(ctx context.Context, domain string, zoneID string) ([]pkgDns.Record, error)
+
+	// UpsertRecord creates or replaces a record set of a domain.
+	UpsertRecord(ctx context.Context, domain string, zoneID string, record pkgDns.Record) error
+
+	// DeleteRecord deletes a record set of a domain.
+	DeleteRecord(ctx context.Context, domain string, zoneID string, name string, recordType string) error
+}
+
+type secretStore interface {
+	CreateOrUpdate(organizationID uint, value *secret.CreateSecretRequest) (string, error)
+	Delete(organizationID uint, secretID string) error
+}
+
+// Client implements the DNS service client operations for DNS providers managing a zone for every organization domain.
+type Client struct {
+	provider string
+	backend  Backend
+
+	store   domainStore
+	secrets secretStore
+
+	notifications chan<- interface{}
+	logger        logrus.FieldLogger
+
+	// locks serializes the operations of an organization
+	locks     map[uint]*sync.Mutex
+	locksLock sync.Mutex
+}
+
+// NewClient returns a new Client instance.
+func NewClient(
+	provider string,
+	backend Backend,
+	db *gorm.DB,
+	notifications chan<- interface{},
+	logger logrus.FieldLogger,
+) *Client {
+	return &Client{
+		provider: provider,
+		backend:  backend,
+
+		store:   &dbDomainStore{db: db, provider: provider},
+		secrets: secret.Store,
+
+		notifications: notifications,
+		logger:        logger.WithField("provider", provider),
+
+		locks: make(map[uint]*sync.Mutex),
+	}
+}
+
+func (c *Client) lock(orgID uint) func() {
+	c.locksLock.Lock()
+	lock, ok := c.locks[orgID]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.locks[orgID] = lock
+	}
+	c.locksLock.Unlock()
+
+	lock.Lock()
+
+	return lock.Unlock
+}
+
+// secretName returns the name of the organization secret storing the credentials of the provider.
+func (c *Client) secretName() string {
+	return "dns-" + c.provider
+}
+
+// IsDomainRegistered returns true if the domain has already been registered for the given organization.
+func (c *Client) IsDomainRegistered(orgId uint, domain string) (bool, error) {
+	state, err := c.store.find(orgId)
+	if err != nil {
+		return false, err
+	}
+
+	return state != nil && state.Domain == domain && state.Status == CREATED, nil
+}
+
+// RegisterDomain creates the zone of the domain and stores the credentials of the provider in the organization.
+func (c *Client) RegisterDomain(orgId uint, domain string) error {
+	err := c.registerDomain(orgId, domain)
+
+	event := DomainEvent{Provider: c.provider, Domain: domain, OrganisationId: orgId}
+	if err != nil {
+		c.notify(RegisterDomainFailedEvent{DomainEvent: event, Cause: err})
+	} else {
+		c.notify(RegisterDomainSucceededEvent{DomainEvent: event})
+	}
+
+	return err
+}
+
+func (c *Client) registerDomain(orgId uint, domain string) error {
+	defer c.lock(orgId)()
+
+	logger := c.logger.WithFields(logrus.Fields{"organization": orgId, "domain": domain})
+
+	state, err := c.store.find(orgId)
+	if err != nil {
+		return err
+	}
+
+	if state == nil {
+		state = &DomainModel{OrganizationID: orgId, Domain: domain}
+	} else if state.Domain != domain {
+		return emperror.With(errors.New("organization already has a registered domain"), "domain", state.Domain)
+	} else if state.Status == REMOVING {
+		return errors.New("removing the domain is in progress")
+	}
+
+	state.Status = CREATING
+	state.ErrorMessage = ""
+	if err := c.store.save(state); err != nil {
+		return err
+	}
+
+	logger.Info("registering domain")
+
+	zoneID, err := c.backend.CreateZone(context.Background(), domain)
+	if err != nil {
+		return c.fail(state, errors.WithMessage(err, "failed to create zone"))
+	}
+
+	state.ZoneID = zoneID
+
+	secretType, values := c.backend.Credentials()
+	_, err = c.secrets.CreateOrUpdate(orgId, &secret.CreateSecretRequest{
+		Name:   c.secretName(),
+		Type:   secretType,
+		Values: values,
+		Tags: []string{
+			secretTypes.TagBanzaiHidden,
+			secretTypes.TagBanzaiReadonly,
+		},
+	})
+	if err != nil {
+		return c.fail(state, errors.WithMessage(err, "failed to store DNS provider credentials"))
+	}
+
+	state.Status = CREATED
+	if err := c.store.save(state); err != nil {
+		return err
+	}
+
+	logger.Info("domain registered")
+
+	return nil
+}
+
+// UnregisterDomain deletes the zone of the domain and the credentials of the provider from the organization.
+func (c *Client) UnregisterDomain(orgId uint, domain string) error {
+	err := c.unregisterDomain(orgId, domain)
+
+	event := DomainEvent{Provider: c.provider, Domain: domain, OrganisationId: orgId}
+	if err != nil {
+		c.notify(UnregisterDomainFailedEvent{DomainEvent: event, Cause: err})
+	} else {
+		c.notify(UnregisterDomainSucceededEvent{DomainEvent: event})
+	}
+
+	return err
+}
+
+func (c *Client) unregisterDomain(orgId uint, domain string) error {
+	defer c.lock(orgId)()
+
+	logger := c.logger.WithFields(logrus.Fields{"organization": orgId, "domain": domain})
+
+	state, err := c.store.find(orgId)
+	if err != nil {
+		return err
+	}
+
+	if state == nil || state.Domain != domain {
+		return emperror.With(errors.New("domain not found"), "domain", domain)
+	} else if state.Status == CREATING {
+		return errors.New("registering the domain is in progress")
+	}
+
+	state.Status = REMOVING
+	if err := c.store.save(state); err != nil {
+		return err
+	}
+
+	logger.Info("unregistering domain")
+
+	err = c.secrets.Delete(orgId, secret.GenerateSecretIDFromName(c.secretName()))
+	if err != nil && err != secret.ErrSecretNotExists {
+		return c.fail(state, errors.WithMessage(err, "failed to delete DNS provider credentials"))
+	}
+
+	if state.ZoneID != "" {
+		err := c.backend.DeleteZone(context.Background(), domain, state.ZoneID)
+		if err != nil {
+			return c.fail(state, errors.WithMessage(err, "failed to delete zone"))
+		}
+	}
+
+	if err := c.store.delete(state); err != nil {
+		return err
+	}
+
+	logger.Info("domain unregistered")
+
+	return nil
+}
+
+// GetOrgDomain returns the domain registered for the organization.
+func (c *Client) GetOrgDomain(orgId uint) (string, error) {
+	state, err := c.store.find(orgId)
+	if err != nil || state == nil {
+		return "", err
+	}
+
+	return state.Domain, nil
+}
+
+// GetProviderSettings returns the settings of the provider managing the domain of the organization.
+func (c *Client) GetProviderSettings(orgId uint) (*pkgDns.ProviderSettings, error) {
+	state, err := c.store.find(orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	if state == nil || state.Status != CREATED {
+		return nil, nil
+	}
+
+	return &pkgDns.ProviderSettings{
+		Provider: c.provider,
+		Domain:   state.Domain,
+		ZoneID:   state.ZoneID,
+		SecretID: secret.GenerateSecretIDFromName(c.secretName()),
+		Options:  c.backend.Options(state.Domain, state.ZoneID),
+	}, nil
+}
+
+// DeleteDnsRecordsOwnedBy deletes the DNS records created by the external-dns instance with the given owner ID.
+func (c *Client) DeleteDnsRecordsOwnedBy(ownerId string, orgId uint) error {
+	defer c.lock(orgId)()
+
+	state, err := c.store.find(orgId)
+	if err != nil {
+		return err
+	}
+
+	if state == nil || state.Status != CREATED {
+		return nil
+	}
+
+	err = c.backend.DeleteRecordsOwnedBy(context.Background(), state.Domain, state.ZoneID, ownerId)
+	if err != nil {
+		return emperror.With(errors.WithMessage(err, "failed to delete DNS records"), "owner", ownerId)
+	}
+
+	return nil
+}
+
+// Cleanup unregisters the domains of the organizations with no live clusters.
+// CreateCustomZone creates the zone of a custom domain of the organization and returns its ID and name servers.
+func (c *Client) CreateCustomZone(orgId uint, domain string) (string, []string, error) {
+	c.logger.WithFields(logrus.Fields{"organization": orgId, "domain": domain}).Info("creating custom domain zone")
+
+	zoneID, err := c.backend.CreateCustomZone(context.Background(), domain)
+	if err != nil {
+		return "", nil, emperror.With(errors.WithMessage(err, "failed to create zone"), "domain", domain)
+	}
+
+	nameServers, err := c.backend.NameServers(context.Background(), domain, zoneID)
+	if err != nil {
+		return "", nil, emperror.With(errors.WithMessage(err, "failed to get zone name servers"), "domain", domain)
+	}
+
+	return zoneID, nameServers, nil
+}
+
+// DeleteCustomZone deletes the zone of a custom domain of the organization.
+func (c *Client) DeleteCustomZone(orgId uint, domain string, zoneID string) error {
+	c.logger.WithFields(logrus.Fields{"organization": orgId, "domain": domain}).Info("deleting custom domain zone")
+
+	err := c.backend.DeleteCustomZone(context.Background(), domain, zoneID)
+	if err != nil {
+		return emperror.With(errors.WithMessage(err, "failed to delete zone"), "domain", domain)
+	}
+
+	return nil
+}
+
+// ListRecords returns the record sets of a domain of the organization.
+func (c *Client) ListRecords(orgId uint, domain string, zoneID string) ([]pkgDns.Record, error) {
+	records, err := c.backend.ListRecords(context.Background(), domain, zoneID)
+	if err != nil {
+		return nil, emperror.With(errors.WithMessage(err, "failed to list DNS records"), "domain", domain)
+	}
+
+	return records, nil
+}
+
+// UpsertRecord creates or replaces a record set in a domain of the organization.
+func (c *Client) UpsertRecord(orgId uint, domain string, zoneID string, record pkgDns.Record) error {
+	err := c.backend.UpsertRecord(context.Background(), domain, zoneID, record)
+	if err != nil {
+		return emperror.With(errors.WithMessage(err, "failed to save DNS record"), "domain", domain, "name", record.Name)
+	}
+
+	return nil
+}
+
+// DeleteRecord deletes a record set from a domain of the organization.
+func (c *Client) DeleteRecord(orgId uint, domain string, zoneID string, name string, recordType string) error {
+	err := c.backend.DeleteRecord(context.Background(), domain, zoneID, name, recordType)
+	if err != nil {
+		return emperror.With(errors.WithMessage(err, "failed to delete DNS record"), "domain", domain, "name", name)
+	}
+
+	return nil
+}
+
+func (c *Client) Cleanup() {
+	states, err := c.store.listUnused()
+	if err != nil {
+		c.logger.Errorf("retrieving domains that are not used failed: %s", err.Error())
+		return
+	}
+
+	for _, state := range states {
+		c.logger.Infof("cleanup domain '%s' as it is not used by organization '%d'", state.Domain, state.OrganizationID)
+
+		if err := c.UnregisterDomain(state.OrganizationID, state.Domain); err != nil {
+			c.logger.Errorf("cleanup domain '%s' failed: %s", state.Domain, err.Error())
+		}
+	}
+}
+
+// ProcessUnfinishedTasks continues processing in-progress domain registrations/unregistrations.
+func (c *Client) ProcessUnfinishedTasks() {
+	pendingUnregister, err := c.store.findByStatus(REMOVING)
+	if err != nil {
+		c.logger.Errorf("retrieving domains pending removal failed: %s", err.Error())
+		return
+	}
+
+	for _, state := range pendingUnregister {
+		c.logger.Infof("continue un-registering domain '%s'", state.Domain)
+
+		go c.UnregisterDomain(state.OrganizationID, state.Domain)
+	}
+
+	pendingRegister, err := c.store.findByStatus(CREATING)
+	if err != nil {
+		c.logger.Errorf("retrieving domains pending registration failed: %s", err.Error())
+		return
+	}
+
+	for _, state := range pendingRegister {
+		c.logger.Infof("continue registering domain '%s'", state.Domain)
+
+		go c.RegisterDomain(state.OrganizationID, state.Domain)
+	}
+}
+
+// fail saves the error in the domain state and returns it.
+func (c *Client) fail(state *DomainModel, err error) error {
+	state.Status = FAILED
+	state.ErrorMessage = err.Error()
+
+	if err := c.store.save(state); err != nil {
+		c.logger.Errorf("updating domain state failed: %s", err.Error())
+	}
+
+	return emperror.With(err, "domain", state.Domain)
+}
+
+func (c *Client) notify(event interface{}) {
+	if c.notifications != nil {
+		c.notifications <- event
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zone
+
+import (
+	"context"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type inmemoryDomainStore struct {
+	domains map[uint]DomainModel
+}
+
+func (s *inmemoryDomainStore) find(orgID uint) (*DomainModel, error) {
+	domain, ok := s.domains[orgID]
+	if !ok {
+		return nil, nil
+	}
+
+	return &domain, nil
+}
+
+func (s *inmemoryDomainStore) findByStatus(status string) ([]DomainModel, error) {
+	var domains []DomainModel
+	for _, domain := range s.domains {
+		if domain.Status == status {
+			domains = append(domains, domain)
+		}
+	}
+
+	return domains, nil
+}
+
+func (s *inmemoryDomainStore) listUnused() ([]DomainModel, error) {
+	return nil, nil
+}
+
+func (s *inmemoryDomainStore) save(domain *DomainModel) error {
+	s.domains[domain.OrganizationID] = *domain
+
+	return nil
+}
+
+func (s *inmemoryDomainStore) delete(domain *DomainModel) error {
+	delete(s.domains, domain.OrganizationID)
+
+	return nil
+}
+
+type inmemorySecretStore struct {
+	secrets map[string]*secret.CreateSecretRequest
+}
+
+func (s *inmemorySecretStore) CreateOrUpdate(organizationID uint, value *secret.CreateSecretRequest) (string, error) {
+	s.secrets[value.Name] = value
+
+	return secret.GenerateSecretID(value), nil
+}
+
+func (s *inmemorySecretStore) Delete(organizationID uint, secretID string) error {
+	for name := range s.secrets {
+		if secret.GenerateSecretIDFromName(name) == secretID {
+			delete(s.secrets, name)
+			return nil
+		}
+	}
+
+	return secret.ErrSecretNotExists
+}
+
+type fakeBackend struct {
+	zones     map[string]bool
+	createErr error
+}
+
+func (b *fakeBackend) CreateZone(ctx context.Context, domain string) (string, error) {
+	if b.createErr != nil {
+		return "", b.createErr
+	}
+
+	b.zones[domain] = true
+
+	return "zone-" + domain, nil
+}
+
+func (b *fakeBackend) DeleteZone(ctx context.Context, domain string, zoneID string) error {
+	delete(b.zones, domain)
+
+	return nil
+}
+
+func (b *fakeBackend) DeleteRecordsOwnedBy(ctx context.Context, domain string, zoneID string, ownerID string) error {
+	return nil
+}
+
+func (b *fakeBackend) Credentials() (string, map[string]string) {
+	return "generic", map[string]string{"key": "value"}
+}
+
+func (b *fakeBackend) Options(domain string, zoneID string) map[string]string {
+	return map[string]string{"zone": zoneID}
+}
+
+func (b *fakeBackend) CreateCustomZone(ctx context.Context, domain string) (string, error) {
+	b.zones[domain] = true
+
+	return "zone-" + domain, nil
+}
+
+func (b *fakeBackend) DeleteCustomZone(ctx context.Context, domain string, zoneID string) error {
+	delete(b.zones, domain)
+
+	return nil
+}
+
+func (b *fakeBackend) NameServers(ctx context.Context, domain string, zoneID string) ([]string, error) {
+	return []string{"ns1.example.net", "ns2.example.net"}, nil
+}
+
+func (b *fakeBackend) ListRecords(ctx context.Context, domain string, zoneID string) ([]pkgDns.Record, error) {
+	return nil, nil
+}
+
+func (b *fakeBackend) UpsertRecord(ctx context.Context, domain string, zoneID string, record pkgDns.Record) error {
+	return nil
+}
+
+func (b *fakeBackend) DeleteRecord(ctx context.Context, domain string, zoneID string, name string, recordType string) error {
+	return nil
+}
+
+func newTestClient(backend Backend) (*Client, *inmemoryDomainStore, *inmemorySecretStore) {
+	store := &inmemoryDomainStore{domains: make(map[uint]DomainModel)}
+	secrets := &inmemorySecretStore{secrets: make(map[string]*secret.CreateSecretRequest)}
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+
+	client := &Client{
+		provider: "test",
+		backend:  backend,
+		store:    store,
+		secrets:  secrets,
+		logger:   logger.WithField("provider", "test"),
+		locks:    make(map[uint]*sync.Mutex),
+	}
+
+	return client, store, secrets
+}
+
+func TestClient_RegisterDomain(t *testing.T) {
+	backend := &fakeBackend{zones: make(map[string]bool)}
+	client, store, secrets := newTestClient(backend)
+
+	err := client.RegisterDomain(1, "org.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	registered, err := client.IsDomainRegistered(1, "org.example.com")
+	if err != nil || !registered {
+		t.Fatalf("expected domain to be registered, err: %v", err)
+	}
+
+	if store.domains[1].ZoneID != "zone-org.example.com" {
+		t.Errorf("unexpected zone ID: %s", store.domains[1].ZoneID)
+	}
+
+	if _, ok := secrets.secrets["dns-test"]; !ok {
+		t.Error("expected credentials to be stored")
+	}
+
+	settings, err := client.GetProviderSettings(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if settings.Domain != "org.example.com" || settings.Options["zone"] != "zone-org.example.com" || settings.SecretID != secret.GenerateSecretIDFromName("dns-test") {
+		t.Errorf("unexpected provider settings: %+v", settings)
+	}
+
+	err = client.RegisterDomain(1, "other.example.com")
+	if err == nil {
+		t.Error("expected error when registering a second domain")
+	}
+
+	err = client.UnregisterDomain(1, "org.example.com")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(store.domains) != 0 || len(secrets.secrets) != 0 || len(backend.zones) != 0 {
+		t.Error("expected domain, credentials and zone to be deleted")
+	}
+}
+
+func TestClient_RegisterDomain_Failed(t *testing.T) {
+	backend := &fakeBackend{zones: make(map[string]bool), createErr: errors.New("quota exceeded")}
+	client, store, _ := newTestClient(backend)
+
+	err := client.RegisterDomain(1, "org.example.com")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if store.domains[1].Status != FAILED || store.domains[1].ErrorMessage == "" {
+		t.Errorf("expected failed domain state, got %+v", store.domains[1])
+	}
+
+	settings, err := client.GetProviderSettings(1)
+	if err != nil || settings != nil {
+		t.Errorf("expected no provider settings for a failed domain, got %+v, %v", settings, err)
+	}
+}
+
+func TestIsOwnedBy(t *testing.T) {
+	tests := map[string]bool{
+		"\"heritage=external-dns,external-dns/owner=cluster-1\"":                               true,
+		"heritage=external-dns,external-dns/owner=cluster-1,external-dns/resource=ingress/a/b": true,
+		"heritage=external-dns,external-dns/owner=cluster-10":                                  false,
+		"v=spf1 include:example.com":                                                           false,
+	}
+
+	for txt, expected := range tests {
+		if IsOwnedBy(txt, "cluster-1") != expected {
+			t.Errorf("IsOwnedBy(%q) should be %v", txt, expected)
+		}
+	}
+}
+
+func TestOwner(t *testing.T) {
+	owner, ok := Owner("\"heritage=external-dns,external-dns/owner=cluster-1,external-dns/resource=service/a/b\"")
+	if !ok || owner != "cluster-1" {
+		t.Errorf("unexpected owner %q (registry record: %v)", owner, ok)
+	}
+
+	if _, ok := Owner("v=spf1 include:example.com"); ok {
+		t.Error("SPF record should not be a registry record")
+	}
+}
+
+func TestInDomain(t *testing.T) {
+	tests := []struct {
+		name, domain string
+		expected     bool
+	}{
+		{"org.example.com", "org.example.com", true},
+		{"app.org.example.com.", "org.example.com", true},
+		{"App.Org.Example.com", "org.example.com.", true},
+		{"apporg.example.com", "org.example.com", false},
+		{"example.com", "org.example.com", false},
+	}
+
+	for _, test := range tests {
+		if actual := InDomain(test.name, test.domain); actual != test.expected {
+			t.Errorf("InDomain(%q, %q) should be %v", test.name, test.domain, test.expected)
+		}
+	}
+}
+
+func TestRelativeName(t *testing.T) {
+	tests := []struct{ domain, zone, expected string }{
+		{"org.example.com", "example.com", "org"},
+		{"org.example.com.", "example.com.", "org"},
+		{"a.org.example.com", "example.com", "a.org"},
+		{"example.com", "example.com", "@"},
+	}
+
+	for _, test := range tests {
+		if actual := RelativeName(test.domain, test.zone); actual != test.expected {
+			t.Errorf("RelativeName(%q, %q) should be %q, got %q", test.domain, test.zone, test.expected, actual)
+		}
+	}
+}
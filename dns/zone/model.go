@@ -0,0 +1,75 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zone
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	domainsTableName = "dns_domains"
+)
+
+// Domain states
+const (
+	CREATING = "CREATING"
+	CREATED  = "CREATED"
+	FAILED   = "FAILED"
+	REMOVING = "REMOVING"
+)
+
+// DomainModel describes the database model
+// for storing the state of domains registered in DNS zones of a DNS provider.
+type DomainModel struct {
+	ID        uint `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Provider       string `gorm:"unique_index:idx_dns_domains_provider_org;unique_index:idx_dns_domains_provider_domain;not null"`
+	OrganizationID uint   `gorm:"unique_index:idx_dns_domains_provider_org;not null"`
+	Domain         string `gorm:"unique_index:idx_dns_domains_provider_domain;not null"`
+	ZoneID         string
+	Status         string `gorm:"not null"`
+	ErrorMessage   string `sql:"type:text;"`
+}
+
+// TableName changes the default table name.
+func (DomainModel) TableName() string {
+	return domainsTableName
+}
+
+// Migrate executes the table migrations for the DNS zone module.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&DomainModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating dns zone tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,62 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zone
+
+import (
+	"strings"
+)
+
+// IsOwnedBy checks if the value of an external-dns registry TXT record references the given owner.
+// The value looks like: "heritage=external-dns,external-dns/owner=<owner ID>,external-dns/resource=<resource>"
+func IsOwnedBy(txt string, ownerID string) bool {
+	for _, label := range strings.Split(strings.Trim(txt, "\""), ",") {
+		if label == "external-dns/owner="+ownerID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Owner returns the owner ID referenced by the value of an external-dns registry TXT record.
+// The second return value is false if the value is not an external-dns registry record.
+func Owner(txt string) (string, bool) {
+	var owner string
+	var heritage bool
+
+	for _, label := range strings.Split(strings.Trim(txt, "\""), ",") {
+		switch {
+		case label == "heritage=external-dns":
+			heritage = true
+		case strings.HasPrefix(label, "external-dns/owner="):
+			owner = strings.TrimPrefix(label, "external-dns/owner=")
+		}
+	}
+
+	return owner, heritage
+}
+
+// RelativeName returns the name of a domain relative to the given zone, eg. "org" for "org.example.com" in "example.com".
+// The name of the zone apex is "@".
+func RelativeName(domain string, zone string) string {
+	domain = strings.TrimSuffix(domain, ".")
+	zone = strings.TrimSuffix(zone, ".")
+
+	if domain == zone {
+		return "@"
+	}
+
+	return strings.TrimSuffix(domain, "."+zone)
+}
@@ -0,0 +1,74 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zone
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// UnsupportedError is returned when a DNS provider does not support an operation.
+type UnsupportedError struct {
+	Provider  string
+	Operation string
+}
+
+func (e *UnsupportedError) Error() string {
+	return e.Operation + " is not supported by the " + e.Provider + " DNS provider"
+}
+
+// IsInvalid marks the error as a client error.
+func (e *UnsupportedError) IsInvalid() bool {
+	return true
+}
+
+// NormalizeName returns the lowercase name without the trailing dot.
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
+
+// AbsoluteName returns the fully qualified name of a name relative to the domain ("@" is the apex).
+func AbsoluteName(relativeName string, domain string) string {
+	if relativeName == "@" || relativeName == "" {
+		return NormalizeName(domain)
+	}
+
+	return NormalizeName(relativeName + "." + NormalizeName(domain))
+}
+
+// InDomain checks if a name is the domain itself or one of its subdomains.
+func InDomain(name string, domain string) bool {
+	name = NormalizeName(name)
+	domain = NormalizeName(domain)
+
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// ParseMX parses an MX record value in the "<preference> <host>" format.
+func ParseMX(value string) (uint16, string, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return 0, "", errors.Errorf("invalid MX record value %q", value)
+	}
+
+	preference, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return 0, "", errors.Errorf("invalid MX record preference %q", fields[0])
+	}
+
+	return uint16(preference), fields[1], nil
+}
@@ -0,0 +1,104 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zone
+
+import (
+	"fmt"
+
+	"github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// domainStore stores the state of the domains registered with a DNS provider.
+type domainStore interface {
+	// find returns the domain of an organization or nil if it is not found.
+	find(orgID uint) (*DomainModel, error)
+
+	// findByStatus returns the domains with the given status.
+	findByStatus(status string) ([]DomainModel, error)
+
+	// listUnused returns the registered domains of the organizations with no live clusters and no custom domains.
+	listUnused() ([]DomainModel, error)
+
+	save(domain *DomainModel) error
+	delete(domain *DomainModel) error
+}
+
+// dbDomainStore is a database backed domainStore.
+type dbDomainStore struct {
+	db       *gorm.DB
+	provider string
+}
+
+func (s *dbDomainStore) find(orgID uint) (*DomainModel, error) {
+	var domain DomainModel
+
+	err := s.db.Where(&DomainModel{Provider: s.provider, OrganizationID: orgID}).First(&domain).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "failed to find domain"), "provider", s.provider, "organization", orgID)
+	}
+
+	return &domain, nil
+}
+
+func (s *dbDomainStore) findByStatus(status string) ([]DomainModel, error) {
+	var domains []DomainModel
+
+	err := s.db.Where(&DomainModel{Provider: s.provider, Status: status}).Find(&domains).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "failed to find domains"), "provider", s.provider, "status", status)
+	}
+
+	return domains, nil
+}
+
+func (s *dbDomainStore) listUnused() ([]DomainModel, error) {
+	var domains []DomainModel
+
+	sqlFilter := fmt.Sprintf("organization_id NOT IN (SELECT organization_id FROM clusters WHERE deleted_at is NULL AND status<>'%s')", cluster.Error)
+	// custom domains are managed with the credentials of the organization domain
+	customDomainsFilter := "organization_id NOT IN (SELECT organization_id FROM dns_custom_domains)"
+
+	err := s.db.Where(&DomainModel{Provider: s.provider, Status: CREATED}).Where(sqlFilter).Where(customDomainsFilter).Find(&domains).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "failed to list unused domains"), "provider", s.provider)
+	}
+
+	return domains, nil
+}
+
+func (s *dbDomainStore) save(domain *DomainModel) error {
+	domain.Provider = s.provider
+
+	err := s.db.Save(domain).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "failed to save domain"), "provider", s.provider, "domain", domain.Domain)
+	}
+
+	return nil
+}
+
+func (s *dbDomainStore) delete(domain *DomainModel) error {
+	err := s.db.Delete(domain).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "failed to delete domain"), "provider", s.provider, "domain", domain.Domain)
+	}
+
+	return nil
+}
@@ -28,6 +28,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
 	"time"
@@ -41,8 +42,10 @@ import (
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/spf13/cast"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v2"
 	"k8s.io/api/core/v1"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -494,6 +497,25 @@ func DeleteDeployment(releaseName string, kubeConfig []byte) error {
 	return nil
 }
 
+// RollbackDeployment rolls back a Helm deployment to a previous version
+func RollbackDeployment(releaseName string, version int32, kubeConfig []byte) (*rls.RollbackReleaseResponse, error) {
+	hClient, err := pkgHelm.NewClient(kubeConfig, log)
+	if err != nil {
+		return nil, err
+	}
+	defer hClient.Close()
+
+	rollbackRes, err := hClient.RollbackRelease(releaseName, helm.RollbackVersion(version))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, &DeploymentNotFoundError{HelmError: err}
+		}
+		return nil, errors.Wrap(err, "rollback failed")
+	}
+
+	return rollbackRes, nil
+}
+
 // GetDeploymentK8sResources returns K8s resources of a helm deployment
 func GetDeploymentK8sResources(releaseName string, kubeConfig []byte, resourceTypes []string) ([]pkgHelm.DeploymentResource, error) {
 	hClient, err := pkgHelm.NewClient(kubeConfig, log)
@@ -606,6 +628,252 @@ func GetDeploymentByVersion(releaseName string, kubeConfig []byte, version int32
 	}, nil
 }
 
+// maxDeploymentHistory is the maximum number of revisions returned for a helm deployment
+const maxDeploymentHistory = 256
+
+// GetDeploymentHistory returns the revisions of a helm deployment, the latest first
+func GetDeploymentHistory(releaseName string, kubeConfig []byte) ([]pkgHelm.DeploymentRevision, error) {
+	helmClient, err := pkgHelm.NewClient(kubeConfig, log)
+	if err != nil {
+		log.Errorf("Getting Helm client failed: %s", err.Error())
+		return nil, err
+	}
+	defer helmClient.Close()
+
+	historyRes, err := helmClient.ReleaseHistory(releaseName, helm.WithMaxHistory(maxDeploymentHistory))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, &DeploymentNotFoundError{HelmError: err}
+		}
+		return nil, err
+	}
+
+	releases := historyRes.GetReleases()
+	if len(releases) == 0 {
+		return nil, &DeploymentNotFoundError{HelmError: errors.Errorf("release: %q not found", releaseName)}
+	}
+
+	revisions := make([]pkgHelm.DeploymentRevision, 0, len(releases))
+	for _, r := range releases {
+		revisions = append(revisions, ReleaseToDeploymentRevision(r))
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+
+	return revisions, nil
+}
+
+// GetDeploymentDiff returns the manifest and values differences between two revisions of a helm deployment.
+// If to is zero the latest revision is used, if from is zero the revision preceding to is used.
+func GetDeploymentDiff(releaseName string, from, to int32, kubeConfig []byte) (*pkgHelm.DeploymentDiffResponse, error) {
+	helmClient, err := pkgHelm.NewClient(kubeConfig, log)
+	if err != nil {
+		log.Errorf("Getting Helm client failed: %s", err.Error())
+		return nil, err
+	}
+	defer helmClient.Close()
+
+	toRelease, err := getReleaseByVersion(helmClient, releaseName, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if from == 0 {
+		from = toRelease.GetVersion() - 1
+		if from < 1 {
+			return nil, &DeploymentNotFoundError{
+				HelmError: errors.Errorf("release: %q has no revision before %d", releaseName, toRelease.GetVersion()),
+			}
+		}
+	}
+
+	fromRelease, err := getReleaseByVersion(helmClient, releaseName, from)
+	if err != nil {
+		return nil, err
+	}
+
+	// Secret data must not leak through the diff to users who can only read deployments
+	fromManifest, err := redactManifestSecrets(fromRelease.GetManifest(), "")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to redact release manifest")
+	}
+
+	toManifest, err := redactManifestSecrets(toRelease.GetManifest(), fromRelease.GetManifest())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to redact release manifest")
+	}
+
+	manifests := map[*release.Release]string{fromRelease: fromManifest, toRelease: toManifest}
+
+	manifestDiff, err := diffRevisions(releaseName, "manifest", fromRelease, toRelease, func(r *release.Release) string {
+		return manifests[r]
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to diff release manifests")
+	}
+
+	valuesDiff, err := diffRevisions(releaseName, "values", fromRelease, toRelease, func(r *release.Release) string {
+		return r.GetConfig().GetRaw()
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to diff release values")
+	}
+
+	return &pkgHelm.DeploymentDiffResponse{
+		ReleaseName: releaseName,
+		From:        ReleaseToDeploymentRevision(fromRelease),
+		To:          ReleaseToDeploymentRevision(toRelease),
+		Manifest:    manifestDiff,
+		Values:      valuesDiff,
+	}, nil
+}
+
+func getReleaseByVersion(helmClient *pkgHelm.Client, releaseName string, version int32) (*release.Release, error) {
+	releaseContent, err := helmClient.ReleaseContent(releaseName, helm.ContentReleaseVersion(version))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, &DeploymentNotFoundError{HelmError: err}
+		}
+		return nil, err
+	}
+
+	return releaseContent.GetRelease(), nil
+}
+
+func diffRevisions(releaseName, kind string, from, to *release.Release, content func(*release.Release) string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(content(from)),
+		B:        difflib.SplitLines(content(to)),
+		FromFile: fmt.Sprintf("%s/%d/%s", releaseName, from.GetVersion(), kind),
+		ToFile:   fmt.Sprintf("%s/%d/%s", releaseName, to.GetVersion(), kind),
+		Context:  3,
+	})
+}
+
+const (
+	redactedSecretValue = "<redacted>"
+	changedSecretValue  = "<redacted, changed>"
+)
+
+var manifestSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+
+// redactManifestSecrets replaces the data and stringData values of the Secrets in a release manifest.
+// Values differing from the same value in the compared manifest are marked as changed, so the diff still shows them.
+func redactManifestSecrets(manifest string, compared string) (string, error) {
+	comparedValues := map[string]string{}
+	if compared != "" {
+		if _, err := walkManifestSecrets(compared, func(key, value string) string {
+			comparedValues[key] = value
+			return value
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return walkManifestSecrets(manifest, func(key, value string) string {
+		if comparedValue, ok := comparedValues[key]; ok && comparedValue != value {
+			return changedSecretValue
+		}
+
+		return redactedSecretValue
+	})
+}
+
+// walkManifestSecrets calls replace with every data and stringData value of the Secrets in a manifest
+// and returns the manifest with the Secret documents rewritten with the replaced values.
+func walkManifestSecrets(manifest string, replace func(key, value string) string) (string, error) {
+	var result strings.Builder
+
+	separators := manifestSeparator.FindAllStringIndex(manifest, -1)
+
+	start := 0
+	for i := 0; i <= len(separators); i++ {
+		end := len(manifest)
+		if i < len(separators) {
+			end = separators[i][0]
+		}
+
+		document, err := walkSecretDocument(manifest[start:end], replace)
+		if err != nil {
+			return "", err
+		}
+		result.WriteString(document)
+
+		if i < len(separators) {
+			result.WriteString(manifest[separators[i][0]:separators[i][1]])
+			start = separators[i][1]
+		}
+	}
+
+	return result.String(), nil
+}
+
+func walkSecretDocument(document string, replace func(key, value string) string) (string, error) {
+	var object yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+		return "", errors.Wrap(err, "failed to parse manifest")
+	}
+
+	if mapSliceValue(object, "kind") != "Secret" {
+		return document, nil
+	}
+
+	metadata, _ := mapSliceValue(object, "metadata").(yaml.MapSlice)
+	name := fmt.Sprintf("%v/%v", mapSliceValue(metadata, "namespace"), mapSliceValue(metadata, "name"))
+
+	for _, field := range []string{"data", "stringData"} {
+		values, _ := mapSliceValue(object, field).(yaml.MapSlice)
+		for i, item := range values {
+			key := fmt.Sprintf("%s/%s/%v", name, field, item.Key)
+			values[i].Value = replace(key, fmt.Sprint(item.Value))
+		}
+	}
+
+	content, err := yaml.Marshal(object)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to encode manifest")
+	}
+
+	// keep the comments (eg. the template source) heading the document
+	var comments strings.Builder
+	for _, line := range strings.SplitAfter(strings.TrimLeft(document, "\n"), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		comments.WriteString(line)
+	}
+
+	return "\n" + comments.String() + string(content), nil
+}
+
+func mapSliceValue(object yaml.MapSlice, key string) interface{} {
+	for _, item := range object {
+		if item.Key == key {
+			return item.Value
+		}
+	}
+
+	return nil
+}
+
+// ReleaseToDeploymentRevision converts a helm release to a deployment revision
+func ReleaseToDeploymentRevision(r *release.Release) pkgHelm.DeploymentRevision {
+	metadata := r.GetChart().GetMetadata()
+
+	return pkgHelm.DeploymentRevision{
+		Revision:     r.GetVersion(),
+		Status:       r.GetInfo().GetStatus().GetCode().String(),
+		Chart:        GetVersionedChartName(metadata.GetName(), metadata.GetVersion()),
+		ChartName:    metadata.GetName(),
+		ChartVersion: metadata.GetVersion(),
+		AppVersion:   metadata.GetAppVersion(),
+		Description:  r.GetInfo().GetDescription(),
+		UpdatedAt:    time.Unix(r.GetInfo().GetLastDeployed().GetSeconds(), 0),
+	}
+}
+
 // GetDeploymentStatus retrieves the status of the passed in release name.
 // returns with an error if the release is not found or another error occurs
 // in case of error the status is filled with information to classify the error cause
@@ -18,9 +18,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
 )
 
 func TestDownloadFile_TooBig(t *testing.T) {
@@ -32,3 +35,64 @@ func TestDownloadFile_TooBig(t *testing.T) {
 	_, err := DownloadFile(ts.URL)
 	assert.EqualError(t, err, "chart data is too big")
 }
+
+func TestDiffRevisions(t *testing.T) {
+	from := &release.Release{Version: 1, Config: &chart.Config{Raw: "replicas: 1\nimage: nginx\n"}}
+	to := &release.Release{Version: 2, Config: &chart.Config{Raw: "replicas: 2\nimage: nginx\n"}}
+
+	diff, err := diffRevisions("my-release", "values", from, to, func(r *release.Release) string {
+		return r.GetConfig().GetRaw()
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "--- my-release/1/values\n+++ my-release/2/values\n@@ -1,3 +1,3 @@\n-replicas: 1\n+replicas: 2\n image: nginx\n \n", diff)
+
+	diff, err = diffRevisions("my-release", "values", from, from, func(r *release.Release) string {
+		return r.GetConfig().GetRaw()
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+}
+
+func TestRedactManifestSecrets(t *testing.T) {
+	from := `
+---
+# Source: app/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+data:
+  password: b2xk
+  username: YWRtaW4=
+---
+# Source: app/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  replicas: "1"
+`
+	to := strings.Replace(from, "b2xk", "bmV3", 1)
+
+	redactedFrom, err := redactManifestSecrets(from, "")
+	assert.NoError(t, err)
+	assert.NotContains(t, redactedFrom, "b2xk")
+	assert.NotContains(t, redactedFrom, "YWRtaW4=")
+	assert.Contains(t, redactedFrom, "# Source: app/templates/secret.yaml\n")
+	assert.Contains(t, redactedFrom, "replicas: \"1\"")
+
+	redactedTo, err := redactManifestSecrets(to, from)
+	assert.NoError(t, err)
+	assert.NotContains(t, redactedTo, "bmV3")
+
+	diff, err := diffRevisions("my-release", "manifest", &release.Release{Version: 1}, &release.Release{Version: 2}, func(r *release.Release) string {
+		if r.GetVersion() == 1 {
+			return redactedFrom
+		}
+		return redactedTo
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "-  password: <redacted>\n+  password: <redacted, changed>\n")
+	assert.NotContains(t, diff, "YWRtaW4=")
+}
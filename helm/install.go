@@ -350,3 +350,37 @@ func Install(helmInstall *phelm.Install, kubeConfig []byte) error {
 	log.Info("Helm install finished")
 	return nil
 }
+
+// Uninstall removes Tiller and the service account and RBAC resources created for it by PreInstall.
+func Uninstall(helmInstall *phelm.Install, kubeConfig []byte) error {
+	kubeClient, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	opts := installer.Options{
+		Namespace:      helmInstall.Namespace,
+		ServiceAccount: helmInstall.ServiceAccount,
+	}
+	if err := installer.Uninstall(kubeClient, &opts); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "error when uninstalling tiller")
+	}
+
+	err = kubeClient.RbacV1().ClusterRoleBindings().Delete(helmInstall.ServiceAccount, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "delete cluster role binding failed")
+	}
+
+	err = kubeClient.RbacV1().ClusterRoles().Delete(helmInstall.ServiceAccount, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "delete cluster role failed")
+	}
+
+	err = kubeClient.CoreV1().ServiceAccounts(helmInstall.Namespace).Delete(helmInstall.ServiceAccount, &metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrap(err, "delete service account failed")
+	}
+
+	log.Info("Tiller (the Helm server-side component) has been removed from your Kubernetes Cluster.")
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"context"
+
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const (
+	clusterCreatedTopic = "cluster_created"
+	clusterDeletedTopic = "cluster_deleted"
+)
+
+type eventBus interface {
+	SubscribeAsync(topic string, fn interface{}, transactional bool) error
+}
+
+// Register subscribes to the cluster events to apply the alerting config of the organization to the new clusters
+// and to clean up the config of the deleted ones.
+func (s *Service) Register(clusterEvents eventBus) error {
+	subscriptions := map[string]interface{}{
+		clusterCreatedTopic: s.clusterCreated,
+		clusterDeletedTopic: s.clusterDeleted,
+	}
+
+	for topic, fn := range subscriptions {
+		if err := clusterEvents.SubscribeAsync(topic, fn, false); err != nil {
+			return emperror.With(errors.Wrap(err, "could not subscribe to events"), "topic", topic)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) clusterCreated(clusterID uint) {
+	commonCluster, err := s.clusters.GetClusterByIDOnly(context.Background(), clusterID)
+	if err != nil {
+		s.errorHandler.Handle(emperror.With(errors.WithMessage(err, "could not get cluster"), "cluster", clusterID))
+		return
+	}
+
+	// the monitoring release is installed with the default rules, it only has to be upgraded for an organization config
+	orgConfig, err := s.repository.FindConfig(commonCluster.GetOrganizationId(), 0)
+	if err != nil {
+		s.errorHandler.Handle(err)
+		return
+	}
+
+	if orgConfig == nil {
+		return
+	}
+
+	if err := s.Apply(commonCluster); err != nil {
+		s.errorHandler.Handle(err)
+	}
+}
+
+func (s *Service) clusterDeleted(organizationID uint, clusterName string) {
+	if err := s.repository.DeleteClusterConfigByName(organizationID, clusterName); err != nil {
+		s.errorHandler.Handle(err)
+	}
+}
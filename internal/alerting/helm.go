@@ -0,0 +1,54 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"github.com/banzaicloud/pipeline/cluster"
+	pipConfig "github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/helm"
+	"github.com/pkg/errors"
+)
+
+// monitoringNotInstalledError is returned when the monitoring release of a cluster is missing.
+type monitoringNotInstalledError struct {
+	clusterName string
+}
+
+func (e *monitoringNotInstalledError) Error() string {
+	return "monitoring is not installed on the cluster"
+}
+
+func (e *monitoringNotInstalledError) Context() []interface{} {
+	return []interface{}{"cluster", e.clusterName}
+}
+
+// upgradeMonitoringRelease merges the values into the monitoring release of a cluster, keeping its chart version.
+func upgradeMonitoringRelease(commonCluster cluster.CommonCluster, values map[string]interface{}) error {
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return errors.Wrap(err, "could not get k8s config")
+	}
+
+	deployment, err := helm.GetDeployment(pipConfig.MonitorReleaseName, kubeConfig)
+	if _, ok := err.(*helm.DeploymentNotFoundError); ok {
+		return errors.WithStack(&monitoringNotInstalledError{clusterName: commonCluster.GetName()})
+	} else if err != nil {
+		return errors.Wrap(err, "could not get monitoring release")
+	}
+
+	err = cluster.UpgradeReleaseValues(commonCluster, deployment, values, kubeConfig)
+
+	return errors.Wrap(err, "could not upgrade monitoring release")
+}
@@ -0,0 +1,100 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgAlerting "github.com/banzaicloud/pipeline/pkg/alerting"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	configsTableName = "alerting_configs"
+)
+
+// ConfigModel describes the alerting config of an organization, or of one of its clusters when ClusterID is set.
+type ConfigModel struct {
+	ID             uint `gorm:"primary_key"`
+	OrganizationID uint `gorm:"unique_index:idx_alerting_config_org_cluster"`
+	ClusterID      uint `gorm:"unique_index:idx_alerting_config_org_cluster"`
+	ClusterName    string
+
+	Spec string `sql:"type:text;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UpdatedBy uint
+}
+
+// TableName changes the default table name.
+func (ConfigModel) TableName() string {
+	return configsTableName
+}
+
+// GetConfig returns the alerting config stored in the model.
+func (m *ConfigModel) GetConfig() (*pkgAlerting.Config, error) {
+	var config pkgAlerting.Config
+
+	if err := json.Unmarshal([]byte(m.Spec), &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// SetConfig stores an alerting config in the model.
+func (m *ConfigModel) SetConfig(config *pkgAlerting.Config) error {
+	spec, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	m.Spec = string(spec)
+
+	return nil
+}
+
+// Scope returns the scope of the alerting config.
+func (m *ConfigModel) Scope() string {
+	if m.ClusterID != 0 {
+		return pkgAlerting.ClusterScope
+	}
+
+	return pkgAlerting.OrganizationScope
+}
+
+// Migrate executes the table migrations for the alerting models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&ConfigModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating alerting tables")
+
+	return db.AutoMigrate(tables...).Error
+}
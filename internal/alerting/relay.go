@@ -0,0 +1,154 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgAlerting "github.com/banzaicloud/pipeline/pkg/alerting"
+	"github.com/pkg/errors"
+)
+
+// redactedSignature replaces the signatures of the relay URLs in the rendered configs returned to the users.
+const redactedSignature = "REDACTED"
+
+// EmailRelay sends the alert emails of the clusters on behalf of their Alertmanagers,
+// so that the SMTP credentials never leave Pipeline.
+// Alertmanagers call the relay through webhooks whose URLs are signed for a cluster and a receiver.
+type EmailRelay struct {
+	baseURL string
+	secret  []byte
+	smtp    pkgAlerting.SMTPConfig
+}
+
+// NewEmailRelay returns a new EmailRelay instance.
+// The relay is disabled if the externally reachable base URL of Pipeline, the signing secret or the SMTP server is not set.
+func NewEmailRelay(baseURL string, secret string, smtp pkgAlerting.SMTPConfig) *EmailRelay {
+	return &EmailRelay{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+		smtp:    smtp,
+	}
+}
+
+func (r *EmailRelay) enabled() bool {
+	return r != nil && r.baseURL != "" && len(r.secret) > 0 && r.smtp.Host != ""
+}
+
+func (r *EmailRelay) signature(clusterID uint, receiver string) string {
+	mac := hmac.New(sha256.New, r.secret)
+	fmt.Fprintf(mac, "%d/%s", clusterID, receiver)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// urlFunc returns the function rendering the relay URLs of the email receivers of a cluster,
+// or nil if the relay is disabled.
+func (r *EmailRelay) urlFunc(clusterID uint, redact bool) func(receiver string) string {
+	if !r.enabled() {
+		return nil
+	}
+
+	return func(receiver string) string {
+		signature := redactedSignature
+		if !redact {
+			signature = r.signature(clusterID, receiver)
+		}
+
+		return fmt.Sprintf("%s/api/v1/alerting/email/%d/%s/%s", r.baseURL, clusterID, url.PathEscape(receiver), signature)
+	}
+}
+
+func (r *EmailRelay) verify(clusterID uint, receiver string, signature string) bool {
+	return r.enabled() && hmac.Equal([]byte(signature), []byte(r.signature(clusterID, receiver)))
+}
+
+func (r *EmailRelay) send(to []string, msg *pkgAlerting.WebhookMessage) error {
+	var auth smtp.Auth
+	if r.smtp.Username != "" {
+		auth = smtp.PlainAuth("", r.smtp.Username, r.smtp.Password, r.smtp.Host)
+	}
+
+	addr := net.JoinHostPort(r.smtp.Host, strconv.Itoa(r.smtp.Port))
+
+	err := smtp.SendMail(addr, auth, r.smtp.From, to, formatAlertEmail(r.smtp.From, to, msg, time.Now()))
+
+	return errors.Wrap(err, "could not send alert email")
+}
+
+// formatAlertEmail formats an Alertmanager notification as an RFC 5322 message.
+func formatAlertEmail(from string, to []string, msg *pkgAlerting.WebhookMessage, date time.Time) []byte {
+	var firing int
+	for _, alert := range msg.Alerts {
+		if alert.Status == "firing" {
+			firing++
+		}
+	}
+
+	subject := fmt.Sprintf("[%s:%d]", strings.ToUpper(msg.Status), firing)
+	for _, name := range sortedKeys(msg.GroupLabels) {
+		subject += " " + msg.GroupLabels[name]
+	}
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[Pipeline] "+subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+
+	for _, alert := range msg.Alerts {
+		fmt.Fprintf(&buf, "[%s] %s\r\n", strings.ToUpper(alert.Status), alert.Labels["alertname"])
+
+		for _, name := range sortedKeys(alert.Annotations) {
+			fmt.Fprintf(&buf, "%s: %s\r\n", name, strings.Replace(alert.Annotations[name], "\n", "\r\n", -1))
+		}
+
+		for _, name := range sortedKeys(alert.Labels) {
+			fmt.Fprintf(&buf, "  %s = %s\r\n", name, alert.Labels[name])
+		}
+
+		fmt.Fprintf(&buf, "Started at: %s\r\n", alert.StartsAt.Format(time.RFC1123Z))
+		buf.WriteString("\r\n")
+	}
+
+	return buf.Bytes()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,49 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"strings"
+	"testing"
+
+	pkgAlerting "github.com/banzaicloud/pipeline/pkg/alerting"
+)
+
+func TestEmailRelay(t *testing.T) {
+	relay := NewEmailRelay("https://pipeline.example.com/pipeline/", "secret", pkgAlerting.SMTPConfig{Host: "smtp.example.com", Port: 587})
+
+	url := relay.urlFunc(1, false)("ops team")
+	prefix := "https://pipeline.example.com/pipeline/api/v1/alerting/email/1/ops%20team/"
+	if !strings.HasPrefix(url, prefix) {
+		t.Fatalf("unexpected relay URL: %s", url)
+	}
+
+	signature := strings.TrimPrefix(url, prefix)
+	if !relay.verify(1, "ops team", signature) {
+		t.Error("the signature of the rendered URL should be valid")
+	}
+	if relay.verify(2, "ops team", signature) || relay.verify(1, "other", signature) {
+		t.Error("the signature should only be valid for the cluster and the receiver it was rendered for")
+	}
+
+	if redacted := relay.urlFunc(1, true)("ops team"); redacted != prefix+redactedSignature {
+		t.Errorf("the signature should be redacted, got %s", redacted)
+	}
+
+	disabled := NewEmailRelay("", "secret", pkgAlerting.SMTPConfig{Host: "smtp.example.com"})
+	if disabled.urlFunc(1, false) != nil || disabled.verify(1, "ops team", signature) {
+		t.Error("the relay should be disabled without a base URL")
+	}
+}
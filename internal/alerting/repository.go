@@ -0,0 +1,104 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the alerting configs of the organizations and the clusters.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindConfig returns the alerting config of an organization, or of one of its clusters when clusterID is not zero.
+// It returns nil if no config is stored.
+func (r *Repository) FindConfig(organizationID uint, clusterID uint) (*ConfigModel, error) {
+	var config ConfigModel
+
+	err := r.db.Where("organization_id = ? AND cluster_id = ?", organizationID, clusterID).First(&config).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch alerting config"),
+			"organization", organizationID,
+			"cluster", clusterID,
+		)
+	}
+
+	return &config, nil
+}
+
+// FindClusterConfigs returns the alerting configs of the clusters of an organization.
+func (r *Repository) FindClusterConfigs(organizationID uint) ([]*ConfigModel, error) {
+	var configs []*ConfigModel
+
+	err := r.db.Where("organization_id = ? AND cluster_id <> 0", organizationID).Order("cluster_id").Find(&configs).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch cluster alerting configs"), "organization", organizationID)
+	}
+
+	return configs, nil
+}
+
+// SaveConfig persists an alerting config.
+func (r *Repository) SaveConfig(config *ConfigModel) error {
+	err := r.db.Save(config).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save alerting config"),
+			"organization", config.OrganizationID,
+			"cluster", config.ClusterID,
+		)
+	}
+
+	return nil
+}
+
+// DeleteConfig deletes the alerting config of an organization, or of one of its clusters when clusterID is not zero.
+func (r *Repository) DeleteConfig(organizationID uint, clusterID uint) error {
+	err := r.db.Where("organization_id = ? AND cluster_id = ?", organizationID, clusterID).Delete(&ConfigModel{}).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not delete alerting config"),
+			"organization", organizationID,
+			"cluster", clusterID,
+		)
+	}
+
+	return nil
+}
+
+// DeleteClusterConfigByName deletes the alerting config of a cluster identified by its name.
+func (r *Repository) DeleteClusterConfigByName(organizationID uint, clusterName string) error {
+	err := r.db.Where("organization_id = ? AND cluster_id <> 0 AND cluster_name = ?", organizationID, clusterName).Delete(&ConfigModel{}).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not delete cluster alerting config"),
+			"organization", organizationID,
+			"cluster", clusterName,
+		)
+	}
+
+	return nil
+}
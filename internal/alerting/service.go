@@ -0,0 +1,430 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"context"
+	"sync"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgAlerting "github.com/banzaicloud/pipeline/pkg/alerting"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type invalidConfigError struct {
+	err error
+}
+
+func (e *invalidConfigError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidConfigError) IsInvalid() bool {
+	return true
+}
+
+type invalidRelaySignatureError struct{}
+
+func (e *invalidRelaySignatureError) Error() string {
+	return "invalid email relay signature"
+}
+
+func (e *invalidRelaySignatureError) Forbidden() bool {
+	return true
+}
+
+type clusterManager interface {
+	GetClusters(ctx context.Context, organizationID uint) ([]cluster.CommonCluster, error)
+	GetClusterByIDOnly(ctx context.Context, clusterID uint) (cluster.CommonCluster, error)
+}
+
+// Service manages the alerting rules and the Alertmanager routing of the organizations and the clusters,
+// and renders them into the monitoring releases of the clusters.
+type Service struct {
+	repository  *Repository
+	clusters    clusterManager
+	emailRelay  *EmailRelay
+	applyValues func(commonCluster cluster.CommonCluster, values map[string]interface{}) error
+
+	// mu serializes the upgrades of the monitoring releases
+	mu           sync.Mutex
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(
+	repository *Repository,
+	clusters clusterManager,
+	emailRelay *EmailRelay,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Service {
+	return &Service{
+		repository:   repository,
+		clusters:     clusters,
+		emailRelay:   emailRelay,
+		applyValues:  upgradeMonitoringRelease,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// GetOrganizationConfig returns the alerting config of an organization.
+func (s *Service) GetOrganizationConfig(organizationID uint) (*pkgAlerting.ConfigResponse, error) {
+	return s.getConfig(organizationID, 0)
+}
+
+// SetOrganizationConfig replaces the alerting config of an organization and applies it to its clusters in the background.
+func (s *Service) SetOrganizationConfig(organizationID uint, config *pkgAlerting.Config, userID uint) (*pkgAlerting.ConfigResponse, error) {
+	if err := pkgAlerting.Validate(*config, nil); err != nil {
+		return nil, errors.WithStack(&invalidConfigError{err})
+	}
+
+	if err := s.validateEmailRelay(config); err != nil {
+		return nil, err
+	}
+
+	if err := s.validateClusterConfigs(organizationID, config); err != nil {
+		return nil, err
+	}
+
+	model, err := s.saveConfig(organizationID, 0, "", config, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	go s.applyOrganization(organizationID)
+
+	return convertModelToEntity(model, config), nil
+}
+
+// DeleteOrganizationConfig deletes the alerting config of an organization and applies the change to its clusters in the background.
+func (s *Service) DeleteOrganizationConfig(organizationID uint) error {
+	if err := s.validateClusterConfigs(organizationID, nil); err != nil {
+		return err
+	}
+
+	if err := s.repository.DeleteConfig(organizationID, 0); err != nil {
+		return err
+	}
+
+	go s.applyOrganization(organizationID)
+
+	return nil
+}
+
+// GetClusterConfig returns the alerting config of a cluster.
+func (s *Service) GetClusterConfig(commonCluster cluster.CommonCluster) (*pkgAlerting.ConfigResponse, error) {
+	return s.getConfig(commonCluster.GetOrganizationId(), commonCluster.GetID())
+}
+
+// SetClusterConfig replaces the alerting config of a cluster and applies it to its monitoring release.
+func (s *Service) SetClusterConfig(commonCluster cluster.CommonCluster, config *pkgAlerting.Config, userID uint) (*pkgAlerting.ConfigResponse, error) {
+	orgConfig, err := s.findConfig(commonCluster.GetOrganizationId(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pkgAlerting.Validate(*config, orgConfig); err != nil {
+		return nil, errors.WithStack(&invalidConfigError{err})
+	}
+
+	if err := s.validateEmailRelay(config); err != nil {
+		return nil, err
+	}
+
+	model, err := s.saveConfig(commonCluster.GetOrganizationId(), commonCluster.GetID(), commonCluster.GetName(), config, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Apply(commonCluster); err != nil {
+		return nil, err
+	}
+
+	return convertModelToEntity(model, config), nil
+}
+
+// DeleteClusterConfig deletes the alerting config of a cluster and applies the config of its organization to it.
+func (s *Service) DeleteClusterConfig(commonCluster cluster.CommonCluster) error {
+	if err := s.repository.DeleteConfig(commonCluster.GetOrganizationId(), commonCluster.GetID()); err != nil {
+		return err
+	}
+
+	return s.Apply(commonCluster)
+}
+
+// Render returns the rules and the Alertmanager configuration rendered for the monitoring release of a cluster.
+// The signatures of the email relay URLs are redacted.
+func (s *Service) Render(commonCluster cluster.CommonCluster) (*pkgAlerting.RenderedConfigResponse, error) {
+	config, err := s.effectiveConfig(commonCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := yaml.Marshal(pkgAlerting.RenderRules(*config, commonCluster.GetName()))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal alerting rules")
+	}
+
+	alertmanager, err := yaml.Marshal(pkgAlerting.RenderAlertmanagerConfig(*config, s.emailRelay.urlFunc(commonCluster.GetID(), true)))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal alertmanager config")
+	}
+
+	return &pkgAlerting.RenderedConfigResponse{
+		Rules:        string(rules),
+		Alertmanager: string(alertmanager),
+	}, nil
+}
+
+// Apply renders the effective alerting config of a cluster into its monitoring release.
+// Clusters without monitoring are skipped, they get the default rules when monitoring is installed.
+func (s *Service) Apply(commonCluster cluster.CommonCluster) error {
+	config, err := s.effectiveConfig(commonCluster)
+	if err != nil {
+		return err
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"organization": commonCluster.GetOrganizationId(),
+		"cluster":      commonCluster.GetName(),
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = s.applyValues(commonCluster, pkgAlerting.MonitoringValues(*config, commonCluster.GetName(), s.emailRelay.urlFunc(commonCluster.GetID(), false)))
+	if _, ok := errors.Cause(err).(*monitoringNotInstalledError); ok {
+		logger.Info("skipping alerting config of cluster without monitoring")
+
+		return nil
+	} else if err != nil {
+		return emperror.With(errors.WithMessage(err, "could not apply alerting config"), "cluster", commonCluster.GetName())
+	}
+
+	logger.Info("alerting config applied")
+
+	return nil
+}
+
+// applyOrganization applies the alerting config to the running clusters of an organization.
+func (s *Service) applyOrganization(organizationID uint) {
+	clusters, err := s.clusters.GetClusters(context.Background(), organizationID)
+	if err != nil {
+		s.errorHandler.Handle(emperror.With(errors.WithMessage(err, "could not get clusters"), "organization", organizationID))
+		return
+	}
+
+	for _, commonCluster := range clusters {
+		status, err := commonCluster.GetStatus()
+		if err != nil {
+			s.errorHandler.Handle(emperror.With(errors.Wrap(err, "could not get cluster status"), "cluster", commonCluster.GetID()))
+			continue
+		}
+
+		if status.Status != pkgCluster.Running {
+			continue
+		}
+
+		if err := s.Apply(commonCluster); err != nil {
+			s.errorHandler.Handle(emperror.With(err, "organization", organizationID))
+		}
+	}
+}
+
+// RelayEmail sends the alert emails of a receiver on behalf of the Alertmanager of a cluster.
+// The recipients are taken from the alerting config, the request only has to be signed for the cluster and the receiver.
+func (s *Service) RelayEmail(ctx context.Context, clusterID uint, receiverName string, signature string, msg *pkgAlerting.WebhookMessage) error {
+	if !s.emailRelay.verify(clusterID, receiverName, signature) {
+		return errors.WithStack(&invalidRelaySignatureError{})
+	}
+
+	commonCluster, err := s.clusters.GetClusterByIDOnly(ctx, clusterID)
+	if err != nil {
+		return err
+	}
+
+	config, err := s.effectiveConfig(commonCluster)
+	if err != nil {
+		return err
+	}
+
+	for _, receiver := range config.Receivers {
+		if receiver.Name != receiverName {
+			continue
+		}
+
+		for _, emailConfig := range receiver.EmailConfigs {
+			if msg.Status == "resolved" && !emailConfig.SendResolved {
+				continue
+			}
+
+			if err := s.emailRelay.send(emailConfig.To, msg); err != nil {
+				return emperror.With(err, "cluster", clusterID, "receiver", receiverName)
+			}
+		}
+
+		return nil
+	}
+
+	// The receiver was removed since the Alertmanager config was rendered
+	s.logger.WithFields(logrus.Fields{"cluster": clusterID, "receiver": receiverName}).Warn("dropping alert email of unknown receiver")
+
+	return nil
+}
+
+// validateEmailRelay checks that the emails of the email receivers can be relayed.
+func (s *Service) validateEmailRelay(config *pkgAlerting.Config) error {
+	if s.emailRelay.enabled() {
+		return nil
+	}
+
+	for _, receiver := range config.Receivers {
+		if len(receiver.EmailConfigs) > 0 {
+			return errors.WithStack(&invalidConfigError{
+				errors.Errorf("receiver %q: email receivers are not supported as the alert email relay is not configured", receiver.Name),
+			})
+		}
+	}
+
+	return nil
+}
+
+// validateClusterConfigs checks that the cluster configs of an organization remain valid with a new organization config.
+func (s *Service) validateClusterConfigs(organizationID uint, orgConfig *pkgAlerting.Config) error {
+	models, err := s.repository.FindClusterConfigs(organizationID)
+	if err != nil {
+		return err
+	}
+
+	for _, model := range models {
+		config, err := model.GetConfig()
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "could not parse alerting config"), "cluster", model.ClusterID)
+		}
+
+		if err := pkgAlerting.Validate(*config, orgConfig); err != nil {
+			return errors.WithStack(&invalidConfigError{
+				errors.WithMessage(err, "alerting config of cluster "+model.ClusterName),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) effectiveConfig(commonCluster cluster.CommonCluster) (*pkgAlerting.Config, error) {
+	orgConfig, err := s.findConfig(commonCluster.GetOrganizationId(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterConfig, err := s.findConfig(commonCluster.GetOrganizationId(), commonCluster.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	config := pkgAlerting.Merge(orgConfig, clusterConfig)
+
+	return &config, nil
+}
+
+func (s *Service) findConfig(organizationID uint, clusterID uint) (*pkgAlerting.Config, error) {
+	model, err := s.repository.FindConfig(organizationID, clusterID)
+	if err != nil || model == nil {
+		return nil, err
+	}
+
+	config, err := model.GetConfig()
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not parse alerting config"),
+			"organization", organizationID,
+			"cluster", clusterID,
+		)
+	}
+
+	return config, nil
+}
+
+func (s *Service) getConfig(organizationID uint, clusterID uint) (*pkgAlerting.ConfigResponse, error) {
+	model, err := s.repository.FindConfig(organizationID, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		scope := pkgAlerting.OrganizationScope
+		if clusterID != 0 {
+			scope = pkgAlerting.ClusterScope
+		}
+
+		return &pkgAlerting.ConfigResponse{Scope: scope}, nil
+	}
+
+	config, err := model.GetConfig()
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not parse alerting config"),
+			"organization", organizationID,
+			"cluster", clusterID,
+		)
+	}
+
+	return convertModelToEntity(model, config), nil
+}
+
+func (s *Service) saveConfig(organizationID uint, clusterID uint, clusterName string, config *pkgAlerting.Config, userID uint) (*ConfigModel, error) {
+	model, err := s.repository.FindConfig(organizationID, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		model = &ConfigModel{
+			OrganizationID: organizationID,
+			ClusterID:      clusterID,
+		}
+	}
+
+	model.ClusterName = clusterName
+	model.UpdatedBy = userID
+
+	if err := model.SetConfig(config); err != nil {
+		return nil, errors.Wrap(err, "could not marshal alerting config")
+	}
+
+	if err := s.repository.SaveConfig(model); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+func convertModelToEntity(model *ConfigModel, config *pkgAlerting.Config) *pkgAlerting.ConfigResponse {
+	updatedAt := model.UpdatedAt
+
+	return &pkgAlerting.ConfigResponse{
+		Config:    *config,
+		Scope:     model.Scope(),
+		UpdatedAt: &updatedAt,
+	}
+}
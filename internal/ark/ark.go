@@ -40,6 +40,10 @@ func IsProviderSupported(provider string) error {
 		return nil
 	case providers.Azure:
 		return nil
+	case providers.Alibaba:
+		return nil
+	case providers.Oracle:
+		return nil
 	default:
 		return pkgErrors.ErrorNotSupportedCloudType
 	}
@@ -18,9 +18,11 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/internal/ark/providers/alibaba"
 	"github.com/banzaicloud/pipeline/internal/ark/providers/amazon"
 	"github.com/banzaicloud/pipeline/internal/ark/providers/azure"
 	"github.com/banzaicloud/pipeline/internal/ark/providers/google"
+	"github.com/banzaicloud/pipeline/internal/ark/providers/oracle"
 	pkgErrors "github.com/banzaicloud/pipeline/pkg/errors"
 	"github.com/banzaicloud/pipeline/pkg/providers"
 	"github.com/banzaicloud/pipeline/secret"
@@ -64,8 +66,8 @@ type secretContents struct {
 }
 
 type configuration struct {
-	PersistentVolumeProvider persistentVolumeProvider `json:"persistentVolumeProvider"`
-	BackupStorageProvider    backupStorageProvider    `json:"backupStorageProvider"`
+	PersistentVolumeProvider *persistentVolumeProvider `json:"persistentVolumeProvider,omitempty"`
+	BackupStorageProvider    backupStorageProvider     `json:"backupStorageProvider"`
 	RestoreOnlyMode          bool
 }
 
@@ -120,6 +122,7 @@ type bucketConfig struct {
 	Location string
 
 	azureBucketConfig
+	oracleBucketConfig
 }
 
 type azureBucketConfig struct {
@@ -127,6 +130,10 @@ type azureBucketConfig struct {
 	ResourceGroup  string
 }
 
+type oracleBucketConfig struct {
+	Namespace string
+}
+
 // GetChartConfig get a ChartConfig
 func GetChartConfig() ChartConfig {
 
@@ -179,9 +186,10 @@ func (req ConfigRequest) Get() (values ValueOverrides, err error) {
 	}, nil
 }
 
-func (req ConfigRequest) getPVPConfig() (persistentVolumeProvider, error) {
+// getPVPConfig gets the persistent volume provider config,
+// volume snapshots are not supported on Alibaba and Oracle clusters
+func (req ConfigRequest) getPVPConfig() (*persistentVolumeProvider, error) {
 
-	var config persistentVolumeProvider
 	var pvc string
 
 	switch req.Cluster.Provider {
@@ -191,11 +199,13 @@ func (req ConfigRequest) getPVPConfig() (persistentVolumeProvider, error) {
 		pvc = azure.PersistentVolumeProvider
 	case providers.Google:
 		pvc = google.PersistentVolumeProvider
+	case providers.Alibaba, providers.Oracle:
+		return nil, nil
 	default:
-		return config, pkgErrors.ErrorNotSupportedCloudType
+		return nil, pkgErrors.ErrorNotSupportedCloudType
 	}
 
-	return persistentVolumeProvider{
+	return &persistentVolumeProvider{
 		Name: pvc,
 		Config: persistentVolumeProviderConfig{
 			Region:     req.Cluster.Location,
@@ -216,6 +226,10 @@ func (req ConfigRequest) getBSPConfig() (backupStorageProvider, error) {
 		bsp = azure.BackupStorageProvider
 	case providers.Google:
 		bsp = google.BackupStorageProvider
+	case providers.Alibaba:
+		bsp = alibaba.BackupStorageProvider
+	case providers.Oracle:
+		bsp = oracle.BackupStorageProvider
 	default:
 		return config, pkgErrors.ErrorNotSupportedCloudType
 	}
@@ -231,6 +245,14 @@ func (req ConfigRequest) getBSPConfig() (backupStorageProvider, error) {
 		}
 	}
 
+	switch req.Bucket.Provider {
+	case providers.Alibaba:
+		config.Config.S3Url = alibaba.GetS3URL(req.Bucket.Location)
+	case providers.Oracle:
+		config.Config.S3Url = oracle.GetS3URL(req.Bucket.Namespace, req.Bucket.Location)
+		config.Config.S3ForcePathStyle = "true"
+	}
+
 	return config, nil
 }
 
@@ -257,6 +279,8 @@ func (req ConfigRequest) getCredentials() (credentials, error) {
 		if err != nil {
 			return config, err
 		}
+	case providers.Alibaba, providers.Oracle:
+		// no persistent volume provider, cluster credentials are not needed
 	default:
 		return config, pkgErrors.ErrorNotSupportedCloudType
 	}
@@ -277,6 +301,16 @@ func (req ConfigRequest) getCredentials() (credentials, error) {
 		if err != nil {
 			return config, err
 		}
+	case providers.Alibaba:
+		BucketSecretContents, err = alibaba.GetSecret(req.BucketSecret)
+		if err != nil {
+			return config, err
+		}
+	case providers.Oracle:
+		BucketSecretContents, err = oracle.GetSecret(req.BucketSecret)
+		if err != nil {
+			return config, err
+		}
 	default:
 		return config, pkgErrors.ErrorNotSupportedCloudType
 	}
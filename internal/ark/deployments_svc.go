@@ -29,6 +29,7 @@ import (
 	"github.com/banzaicloud/pipeline/helm"
 	"github.com/banzaicloud/pipeline/internal/ark/api"
 	"github.com/banzaicloud/pipeline/internal/ark/client"
+	"github.com/banzaicloud/pipeline/internal/ark/providers/oracle"
 	"github.com/banzaicloud/pipeline/pkg/providers"
 )
 
@@ -138,6 +139,14 @@ func (s *DeploymentsService) Deploy(bucket *ClusterBackupBucketsModel, restoreMo
 		resourceGroup = m.GetResourceGroup()
 	}
 
+	var namespace string
+	if bucket.Cloud == providers.Oracle {
+		namespace, err = oracle.GetNamespace(bucketSecret, bucket.Location)
+		if err != nil {
+			return errors.Wrap(err, "error getting bucket namespace")
+		}
+	}
+
 	config, err := s.getChartConfig(ConfigRequest{
 		Cluster: clusterConfig{
 			Name:     s.cluster.GetName(),
@@ -157,6 +166,9 @@ func (s *DeploymentsService) Deploy(bucket *ClusterBackupBucketsModel, restoreMo
 				StorageAccount: bucket.StorageAccount,
 				ResourceGroup:  bucket.ResourceGroup,
 			},
+			oracleBucketConfig: oracleBucketConfig{
+				Namespace: namespace,
+			},
 		},
 		BucketSecret: bucketSecret,
 
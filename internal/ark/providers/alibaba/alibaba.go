@@ -0,0 +1,29 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alibaba
+
+import "fmt"
+
+const (
+	// BackupStorageProvider is a config value for ARK, OSS is accessed through its S3 compatible API
+	BackupStorageProvider = "aws"
+
+	s3URLTemplate = "https://oss-%s.aliyuncs.com"
+)
+
+// GetS3URL gets the S3 compatible endpoint of OSS in the given region
+func GetS3URL(region string) string {
+	return fmt.Sprintf(s3URLTemplate, region)
+}
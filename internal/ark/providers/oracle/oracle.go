@@ -0,0 +1,56 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracle
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/banzaicloud/pipeline/pkg/providers/oracle/oci"
+	oracleSecret "github.com/banzaicloud/pipeline/pkg/providers/oracle/secret"
+	"github.com/banzaicloud/pipeline/secret"
+)
+
+const (
+	// BackupStorageProvider is a config value for ARK, Object Storage is accessed through its S3 compatible API
+	BackupStorageProvider = "aws"
+
+	s3URLTemplate = "https://%s.compat.objectstorage.%s.oraclecloud.com"
+)
+
+// GetS3URL gets the S3 compatible endpoint of Object Storage for the given namespace and region
+func GetS3URL(namespace string, region string) string {
+	return fmt.Sprintf(s3URLTemplate, namespace, region)
+}
+
+// GetNamespace gets the Object Storage namespace of the tenancy the secret belongs to
+func GetNamespace(secret *secret.SecretItemResponse, region string) (string, error) {
+
+	credential := oracleSecret.CreateOCICredential(secret.Values)
+	credential.Region = region
+
+	client, err := oci.NewOCI(credential)
+	if err != nil {
+		return "", errors.Wrap(err, "could not create OCI client")
+	}
+
+	osClient, err := client.NewObjectStorageClient()
+	if err != nil {
+		return "", errors.Wrap(err, "could not get object storage namespace")
+	}
+
+	return osClient.Namespace, nil
+}
@@ -0,0 +1,55 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracle
+
+import (
+	"github.com/pelletier/go-toml"
+	"github.com/pkg/errors"
+
+	oracleSecret "github.com/banzaicloud/pipeline/pkg/providers/oracle/secret"
+	"github.com/banzaicloud/pipeline/secret"
+)
+
+type secretContents struct {
+	Credentials credentials `toml:"default"`
+}
+
+type credentials struct {
+	KeyID string `toml:"aws_access_key_id"`
+	Key   string `toml:"aws_secret_access_key"`
+}
+
+// GetSecret gets formatted secret for ARK
+// The S3 compatible API only accepts customer secret keys, they must be present in the secret.
+func GetSecret(secret *secret.SecretItemResponse) (string, error) {
+
+	a := secretContents{
+		Credentials: credentials{
+			KeyID: secret.Values[oracleSecret.CustomerSecretKeyID],
+			Key:   secret.Values[oracleSecret.CustomerSecretKey],
+		},
+	}
+
+	if a.Credentials.KeyID == "" || a.Credentials.Key == "" {
+		return "", errors.Errorf("%s and %s are required in the secret", oracleSecret.CustomerSecretKeyID, oracleSecret.CustomerSecretKey)
+	}
+
+	values, err := toml.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+
+	return string(values), nil
+}
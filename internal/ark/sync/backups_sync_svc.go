@@ -16,7 +16,9 @@ package sync
 
 import (
 	"context"
+	"strings"
 
+	arkAPI "github.com/heptio/ark/pkg/apis/ark/v1"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,6 +33,7 @@ import (
 type BackupsSyncService struct {
 	org    *auth.Organization
 	db     *gorm.DB
+	events backupEvents
 	logger logrus.FieldLogger
 
 	backupsSvc *ark.BackupsService
@@ -38,11 +41,12 @@ type BackupsSyncService struct {
 }
 
 // NewBackupsSyncService returns an initialized BackupsSyncService
-func NewBackupsSyncService(org *auth.Organization, db *gorm.DB, logger logrus.FieldLogger) *BackupsSyncService {
+func NewBackupsSyncService(org *auth.Organization, db *gorm.DB, events backupEvents, logger logrus.FieldLogger) *BackupsSyncService {
 
 	s := &BackupsSyncService{
 		org:    org,
 		db:     db,
+		events: events,
 		logger: logger,
 	}
 
@@ -149,6 +153,11 @@ func (s *BackupsSyncService) SyncBackupsForCluster(cluster api.Cluster) error {
 			return err
 		}
 
+		// only newly failed backups are published, not the ones already known as failed
+		if backup.Status.Phase == arkAPI.BackupPhaseFailed && (persitedBackup == nil || persitedBackup.Status != string(arkAPI.BackupPhaseFailed)) {
+			s.events.BackupFailed(s.org.ID, cluster.GetID(), cluster.GetName(), backup.Name, strings.Join(backup.Status.ValidationErrors, "; "))
+		}
+
 		log.Debug("backup synced")
 	}
 
@@ -0,0 +1,40 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sync
+
+// BackupFailedTopic is the name of the topic where failed backups found during the sync are published.
+const BackupFailedTopic = "ark_backup_failed"
+
+// backupEvents is responsible for dispatching backup related domain events.
+type backupEvents interface {
+	BackupFailed(organizationID uint, clusterID uint, clusterName string, backupName string, reason string)
+}
+
+type eventBus interface {
+	Publish(topic string, args ...interface{})
+}
+
+type ebBackupEvents struct {
+	eb eventBus
+}
+
+// NewBackupEvents returns the backup events published on the given event bus.
+func NewBackupEvents(eb eventBus) *ebBackupEvents {
+	return &ebBackupEvents{eb: eb}
+}
+
+func (e *ebBackupEvents) BackupFailed(organizationID uint, clusterID uint, clusterName string, backupName string, reason string) {
+	e.eb.Publish(BackupFailedTopic, organizationID, clusterID, clusterName, backupName, reason)
+}
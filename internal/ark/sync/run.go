@@ -30,6 +30,7 @@ func RunSyncServices(
 	context context.Context,
 	db *gorm.DB,
 	clusterManager *cluster.Manager,
+	events backupEvents,
 	logger logrus.FieldLogger,
 	errorHandler emperror.Handler,
 	bucketSyncInterval, restoreSyncInterval, backupSyncInterval time.Duration,
@@ -55,6 +56,7 @@ func RunSyncServices(
 
 	svc := NewSyncService(
 		clusterManager,
+		events,
 		bucketSyncInterval,
 		restoreSyncInterval,
 		backupSyncInterval,
@@ -29,6 +29,7 @@ import (
 // Service describes a service for every ARK related sync operations
 type Service struct {
 	clusterManager      *cluster.Manager
+	events              backupEvents
 	bucketSyncInterval  time.Duration
 	restoreSyncInterval time.Duration
 	backupSyncInterval  time.Duration
@@ -37,6 +38,7 @@ type Service struct {
 // NewSyncService creates and initializes a Service
 func NewSyncService(
 	ClusterManager *cluster.Manager,
+	events backupEvents,
 	BucketSyncInterval time.Duration,
 	RestoreSyncInterval time.Duration,
 	BackupSyncInterval time.Duration,
@@ -44,6 +46,7 @@ func NewSyncService(
 
 	return &Service{
 		clusterManager:      ClusterManager,
+		events:              events,
 		bucketSyncInterval:  BucketSyncInterval,
 		restoreSyncInterval: RestoreSyncInterval,
 		backupSyncInterval:  BackupSyncInterval,
@@ -207,7 +210,7 @@ func (s *Service) syncBackups(db *gorm.DB, logger logrus.FieldLogger) error {
 	for _, org := range orgs {
 		log := logger.WithField("orgID", org.ID).WithField("orgName", org.Name)
 		log.Debug("syncing backups")
-		syncer := NewBackupsSyncService(org, db, log)
+		syncer := NewBackupsSyncService(org, db, s.events, log)
 		err := syncer.SyncBackups(s.clusterManager)
 		if err != nil {
 			log.Error(err)
@@ -0,0 +1,149 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/goph/emperror"
+	"github.com/sirupsen/logrus"
+)
+
+const sinkSendTimeout = time.Minute
+
+// Dispatcher delivers the queued audit events to the sinks.
+// Events are removed from the outbox only after a successful delivery, so every event is delivered at least once.
+type Dispatcher struct {
+	outbox    *Outbox
+	sinks     []Sink
+	batchSize int
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewDispatcher returns a new Dispatcher instance.
+func NewDispatcher(
+	outbox *Outbox,
+	sinks []Sink,
+	batchSize int,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Dispatcher {
+	return &Dispatcher{
+		outbox:    outbox,
+		sinks:     sinks,
+		batchSize: batchSize,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// SinkNames returns the names of the sinks the events should be queued for.
+func (d *Dispatcher) SinkNames() []string {
+	names := make([]string, 0, len(d.sinks))
+	for _, sink := range d.sinks {
+		names = append(names, sink.Name())
+	}
+
+	return names
+}
+
+// Dispatch delivers the due events of every sink.
+// A failing sink does not hold back the others.
+func (d *Dispatcher) Dispatch(ctx context.Context) {
+	for _, sink := range d.sinks {
+		if err := d.dispatch(ctx, sink); err != nil {
+			d.errorHandler.Handle(emperror.With(err, "sink", sink.Name()))
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, sink Sink) error {
+	logger := d.logger.WithField("sink", sink.Name())
+
+	for {
+		now := time.Now()
+
+		entries, err := d.outbox.FindPending(sink.Name(), now, d.batchSize)
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			return nil
+		}
+
+		events := make([]*AuditEvent, 0, len(entries))
+		attempts := 0
+		for _, entry := range entries {
+			// The event is missing when it has been deleted from the audit log
+			if entry.Event.ID != 0 {
+				events = append(events, &entry.Event)
+			}
+
+			if entry.Attempts > attempts {
+				attempts = entry.Attempts
+			}
+		}
+
+		if len(events) > 0 {
+			sendCtx, cancel := context.WithTimeout(ctx, sinkSendTimeout)
+			err = sink.Send(sendCtx, events)
+			cancel()
+
+			if err != nil {
+				backoff := retryBackoff(attempts)
+
+				logger.WithField("attempts", attempts+1).Warnf("could not deliver audit events, retrying in %s", backoff)
+
+				if postponeErr := d.outbox.Postpone(entries, now.Add(backoff), err); postponeErr != nil {
+					d.errorHandler.Handle(emperror.With(postponeErr, "sink", sink.Name()))
+				}
+
+				return err
+			}
+		}
+
+		if err := d.outbox.Delete(entries); err != nil {
+			return err
+		}
+
+		logger.WithField("events", len(events)).Debug("audit events delivered")
+
+		if len(entries) < d.batchSize {
+			return nil
+		}
+	}
+}
+
+// Run delivers the queued events periodically until the context is cancelled.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	d.Dispatch(ctx)
+
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			d.Dispatch(ctx)
+		case <-ctx.Done():
+			d.logger.Debug("closing ticker")
+			ticker.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	pkgAudit "github.com/banzaicloud/pipeline/pkg/audit"
+	"github.com/pkg/errors"
+)
+
+// EventWriter writes audit events in an export format.
+type EventWriter interface {
+	Write(event *AuditEvent) error
+	Flush() error
+}
+
+type unsupportedFormatError struct {
+	format string
+}
+
+func (e *unsupportedFormatError) Error() string {
+	return fmt.Sprintf("unsupported export format: %s", e.format)
+}
+
+func (e *unsupportedFormatError) IsInvalid() bool {
+	return true
+}
+
+// NewEventWriter returns an EventWriter for the given export format.
+func NewEventWriter(format string, w io.Writer) (EventWriter, error) {
+	switch format {
+	case pkgAudit.FormatCSV:
+		return newCSVEventWriter(w)
+
+	case pkgAudit.FormatNDJSON:
+		return &ndjsonEventWriter{encoder: json.NewEncoder(w)}, nil
+
+	default:
+		return nil, errors.WithStack(&unsupportedFormatError{format: format})
+	}
+}
+
+var csvHeader = []string{
+	"id",
+	"time",
+	"correlationId",
+	"clientIp",
+	"userAgent",
+	"userId",
+	"method",
+	"path",
+	"statusCode",
+	"body",
+	"headers",
+}
+
+type csvEventWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVEventWriter(w io.Writer) (*csvEventWriter, error) {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, errors.Wrap(err, "could not write CSV header")
+	}
+
+	return &csvEventWriter{writer: writer}, nil
+}
+
+func (w *csvEventWriter) Write(event *AuditEvent) error {
+	var body string
+	if event.Body != nil {
+		body = *event.Body
+	}
+
+	err := w.writer.Write([]string{
+		strconv.FormatUint(uint64(event.ID), 10),
+		event.Time.UTC().Format(time.RFC3339),
+		event.CorrelationID,
+		event.ClientIP,
+		event.UserAgent,
+		strconv.FormatUint(uint64(event.UserID), 10),
+		event.Method,
+		event.Path,
+		strconv.Itoa(event.StatusCode),
+		body,
+		event.Headers,
+	})
+
+	return errors.Wrap(err, "could not write CSV record")
+}
+
+func (w *csvEventWriter) Flush() error {
+	w.writer.Flush()
+
+	return errors.Wrap(w.writer.Error(), "could not flush CSV records")
+}
+
+type ndjsonEventWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonEventWriter) Write(event *AuditEvent) error {
+	return errors.Wrap(w.encoder.Encode(event.ConvertModelToEntity()), "could not write JSON record")
+}
+
+func (w *ndjsonEventWriter) Flush() error {
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func testEvents() []*AuditEvent {
+	body := `{"name":"cluster"}`
+
+	return []*AuditEvent{
+		{
+			ID:            2,
+			Time:          time.Date(2019, 2, 10, 12, 0, 0, 0, time.UTC),
+			CorrelationID: "a6f3a1e4-1fcb-4e4b-9c8b-1d5e6e3c5f10",
+			ClientIP:      "10.0.0.1",
+			UserAgent:     "curl/7.54.0",
+			UserID:        1,
+			Method:        "POST",
+			Path:          "/api/v1/orgs/1/clusters",
+			StatusCode:    201,
+			Body:          &body,
+			Headers:       `{"secretId":["abc"]}`,
+		},
+		{
+			ID:         1,
+			Time:       time.Date(2019, 2, 10, 11, 0, 0, 0, time.UTC),
+			UserID:     1,
+			Method:     "GET",
+			Path:       "/api/v1/orgs/1/clusters?fields=name,status",
+			StatusCode: 200,
+			Headers:    `{}`,
+		},
+	}
+}
+
+func writeEvents(t *testing.T, format string) string {
+	var buf bytes.Buffer
+
+	writer, err := NewEventWriter(format, &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, event := range testEvents() {
+		if err := writer.Write(event); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return buf.String()
+}
+
+func TestNewEventWriter_CSV(t *testing.T) {
+	expected := "id,time,correlationId,clientIp,userAgent,userId,method,path,statusCode,body,headers\n" +
+		`2,2019-02-10T12:00:00Z,a6f3a1e4-1fcb-4e4b-9c8b-1d5e6e3c5f10,10.0.0.1,curl/7.54.0,1,POST,/api/v1/orgs/1/clusters,201,"{""name"":""cluster""}","{""secretId"":[""abc""]}"` + "\n" +
+		`1,2019-02-10T11:00:00Z,,,,1,GET,"/api/v1/orgs/1/clusters?fields=name,status",200,,{}` + "\n"
+
+	if actual := writeEvents(t, "csv"); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestNewEventWriter_NDJSON(t *testing.T) {
+	expected := `{"id":2,"time":"2019-02-10T12:00:00Z","correlationId":"a6f3a1e4-1fcb-4e4b-9c8b-1d5e6e3c5f10","clientIp":"10.0.0.1","userAgent":"curl/7.54.0","path":"/api/v1/orgs/1/clusters","method":"POST","userId":1,"statusCode":201,"body":{"name":"cluster"},"headers":{"secretId":["abc"]}}` + "\n" +
+		`{"id":1,"time":"2019-02-10T11:00:00Z","correlationId":"","clientIp":"","userAgent":"","path":"/api/v1/orgs/1/clusters?fields=name,status","method":"GET","userId":1,"statusCode":200}` + "\n"
+
+	if actual := writeEvents(t, "ndjson"); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestNewEventWriter_UnsupportedFormat(t *testing.T) {
+	_, err := NewEventWriter("xml", &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+		t.Errorf("expected invalid error, got: %v", err)
+	}
+}
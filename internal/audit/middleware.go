@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/textproto"
+	"strconv"
 	"strings"
 	"time"
 
@@ -35,10 +36,12 @@ import (
 )
 
 // LogWriter instance is a Gin Middleware which logs all request data into MySQL audit_events table.
+// Events are also queued in the outbox for the given sinks.
 func LogWriter(
 	skipPaths []string,
 	whitelistedHeaders []string,
 	db *gorm.DB,
+	sinks []string,
 	logger logrus.FieldLogger,
 ) gin.HandlerFunc {
 	skip := map[string]struct{}{}
@@ -129,6 +132,9 @@ func LogWriter(
 				}
 			}
 
+			// Process the request first, so that the status code of the response is known
+			c.Next()
+
 			correlationID := c.GetString(correlationid.ContextKey)
 			clientIP := c.ClientIP()
 			method := c.Request.Method
@@ -145,6 +151,12 @@ func LogWriter(
 				userID = user.ID
 			}
 
+			// Organization scoped paths are identified by the numeric organization ID
+			var organizationID uint
+			if orgID, err := strconv.ParseUint(c.Param("orgid"), 10, 32); err == nil {
+				organizationID = uint(orgID)
+			}
+
 			filteredHeaders := http.Header{}
 			for _, header := range whitelistedHeaders {
 				if values := c.Request.Header[textproto.CanonicalMIMEHeaderKey(header)]; len(values) != 0 {
@@ -154,28 +166,30 @@ func LogWriter(
 
 			headers, err := json.Marshal(filteredHeaders)
 			if err != nil {
-				c.AbortWithError(http.StatusInternalServerError, err)
+				c.Error(err)
 				logger.Errorln(err)
 
 				return
 			}
 
 			event := AuditEvent{
-				Time:          start,
-				CorrelationID: correlationID,
-				ClientIP:      clientIP,
-				UserAgent:     userAgent,
-				UserID:        userID,
-				StatusCode:    statusCode,
-				Method:        method,
-				Path:          path,
-				Body:          body,
-				Headers:       string(headers),
+				Time:           start,
+				CorrelationID:  correlationID,
+				ClientIP:       clientIP,
+				UserAgent:      userAgent,
+				UserID:         userID,
+				OrganizationID: organizationID,
+				StatusCode:     statusCode,
+				Method:         method,
+				Path:           path,
+				Body:           body,
+				Headers:        string(headers),
 			}
 
-			err = db.Save(&event).Error
+			// The response has already been written at this point, the error can only be recorded
+			err = saveEvent(db, &event, sinks)
 			if err != nil {
-				c.AbortWithError(http.StatusInternalServerError, err)
+				c.Error(err)
 				logger.Errorln(err)
 
 				return
@@ -14,7 +14,12 @@
 
 package audit
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	pkgAudit "github.com/banzaicloud/pipeline/pkg/audit"
+)
 
 // TableName constants
 const (
@@ -23,20 +28,45 @@ const (
 
 // AuditEvent holds all information related to a user interaction.
 type AuditEvent struct {
-	ID            uint      `gorm:"primary_key"`
-	Time          time.Time `gorm:"index"`
-	CorrelationID string    `gorm:"size:36"`
-	ClientIP      string    `gorm:"size:45"`
-	UserAgent     string
-	Path          string `gorm:"size:8000"`
-	Method        string `gorm:"size:7"`
-	UserID        uint
-	StatusCode    int
-	Body          *string `gorm:"type:json"`
-	Headers       string  `gorm:"type:json"`
+	ID             uint      `gorm:"primary_key"`
+	Time           time.Time `gorm:"index"`
+	CorrelationID  string    `gorm:"size:36"`
+	ClientIP       string    `gorm:"size:45"`
+	UserAgent      string
+	Path           string `gorm:"size:8000"`
+	Method         string `gorm:"size:7"`
+	UserID         uint
+	OrganizationID uint `gorm:"index"`
+	StatusCode     int
+	Body           *string `gorm:"type:json"`
+	Headers        string  `gorm:"type:json"`
 }
 
 // TableName specifies a database table name for the model.
 func (AuditEvent) TableName() string {
 	return auditEventTableName
 }
+
+// ConvertModelToEntity converts an audit event to its API representation.
+func (e *AuditEvent) ConvertModelToEntity() pkgAudit.EventResponse {
+	response := pkgAudit.EventResponse{
+		ID:            e.ID,
+		Time:          e.Time,
+		CorrelationID: e.CorrelationID,
+		ClientIP:      e.ClientIP,
+		UserAgent:     e.UserAgent,
+		Path:          e.Path,
+		Method:        e.Method,
+		UserID:        e.UserID,
+		StatusCode:    e.StatusCode,
+	}
+
+	if e.Body != nil {
+		response.Body = json.RawMessage(*e.Body)
+	}
+
+	// Headers are always written as a JSON object, a broken value is omitted rather than failing the whole page
+	_ = json.Unmarshal([]byte(e.Headers), &response.Headers)
+
+	return response
+}
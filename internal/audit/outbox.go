@@ -0,0 +1,140 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"time"
+
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// TableName constants
+const (
+	outboxTableName = "audit_outbox"
+)
+
+// OutboxModel is an audit event waiting to be delivered to a sink.
+type OutboxModel struct {
+	ID            uint   `gorm:"primary_key"`
+	EventID       uint   `gorm:"index"`
+	Sink          string `gorm:"size:64;index:idx_audit_outbox_sink_next_attempt"`
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index:idx_audit_outbox_sink_next_attempt"`
+	LastError     string    `gorm:"type:text"`
+	CreatedAt     time.Time
+
+	Event AuditEvent
+}
+
+// TableName specifies a database table name for the model.
+func (OutboxModel) TableName() string {
+	return outboxTableName
+}
+
+// saveEvent saves an audit event and queues it for every sink in the same transaction,
+// so that a saved event is never lost for the sinks.
+func saveEvent(db *gorm.DB, event *AuditEvent, sinks []string) error {
+	if len(sinks) == 0 {
+		return errors.Wrap(db.Save(event).Error, "could not save audit event")
+	}
+
+	tx := db.Begin()
+	if err := tx.Error; err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	if err := tx.Save(event).Error; err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, "could not save audit event")
+	}
+
+	for _, sink := range sinks {
+		entry := OutboxModel{
+			EventID:       event.ID,
+			Sink:          sink,
+			NextAttemptAt: event.Time,
+		}
+
+		if err := tx.Save(&entry).Error; err != nil {
+			tx.Rollback()
+			return emperror.With(errors.Wrap(err, "could not queue audit event"), "sink", sink)
+		}
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit transaction")
+}
+
+// Outbox stores the audit events waiting to be delivered to the sinks.
+type Outbox struct {
+	db *gorm.DB
+}
+
+// NewOutbox returns a new Outbox instance.
+func NewOutbox(db *gorm.DB) *Outbox {
+	return &Outbox{db: db}
+}
+
+// FindPending returns at most limit entries of a sink which are due to be delivered until the given time, the oldest first.
+func (o *Outbox) FindPending(sink string, until time.Time, limit int) ([]*OutboxModel, error) {
+	var entries []*OutboxModel
+
+	err := o.db.
+		Preload("Event").
+		Where("sink = ? AND next_attempt_at <= ?", sink, until).
+		Order("id").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch pending audit events"), "sink", sink)
+	}
+
+	return entries, nil
+}
+
+// Delete removes delivered entries from the outbox.
+func (o *Outbox) Delete(entries []*OutboxModel) error {
+	ids := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+
+	err := o.db.Where("id IN (?)", ids).Delete(&OutboxModel{}).Error
+	if err != nil {
+		return errors.Wrap(err, "could not delete delivered audit events")
+	}
+
+	return nil
+}
+
+// Postpone records a failed delivery of entries and schedules their next attempt.
+func (o *Outbox) Postpone(entries []*OutboxModel, nextAttemptAt time.Time, deliveryErr error) error {
+	ids := make([]uint, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+
+	err := o.db.Model(&OutboxModel{}).Where("id IN (?)", ids).Updates(map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"next_attempt_at": nextAttemptAt,
+		"last_error":      deliveryErr.Error(),
+	}).Error
+	if err != nil {
+		return errors.Wrap(err, "could not postpone audit events")
+	}
+
+	return nil
+}
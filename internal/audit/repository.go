@@ -0,0 +1,142 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"strings"
+	"time"
+
+	pkgAudit "github.com/banzaicloud/pipeline/pkg/audit"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// EventFilter narrows down the audit events of an organization.
+// Zero values match every event.
+type EventFilter struct {
+	OrganizationID uint
+	UserID         uint
+	From           time.Time
+	To             time.Time
+	Method         string
+	PathPrefix     string
+	StatusCode     int
+	CorrelationID  string
+}
+
+type invalidFilterError struct {
+	message string
+}
+
+func (e *invalidFilterError) Error() string {
+	return e.message
+}
+
+func (e *invalidFilterError) IsInvalid() bool {
+	return true
+}
+
+// NewEventFilter creates a filter for the events of an organization from a query.
+func NewEventFilter(organizationID uint, query pkgAudit.ListEventsQuery) (EventFilter, error) {
+	filter := EventFilter{
+		OrganizationID: organizationID,
+		UserID:         query.UserID,
+		Method:         query.Method,
+		PathPrefix:     query.Path,
+		StatusCode:     query.StatusCode,
+		CorrelationID:  query.CorrelationID,
+	}
+
+	var err error
+
+	if query.From != "" {
+		filter.From, err = time.Parse(time.RFC3339, query.From)
+		if err != nil {
+			return filter, errors.WithStack(&invalidFilterError{"invalid from time: " + query.From})
+		}
+	}
+
+	if query.To != "" {
+		filter.To, err = time.Parse(time.RFC3339, query.To)
+		if err != nil {
+			return filter, errors.WithStack(&invalidFilterError{"invalid to time: " + query.To})
+		}
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return filter, errors.WithStack(&invalidFilterError{"from time must be before to time"})
+	}
+
+	return filter, nil
+}
+
+// EventRepository reads back the recorded audit events.
+type EventRepository struct {
+	db *gorm.DB
+}
+
+// NewEventRepository returns a new EventRepository instance.
+func NewEventRepository(db *gorm.DB) *EventRepository {
+	return &EventRepository{db: db}
+}
+
+// Find returns at most limit events matching the filter, the latest first.
+// Only events older than the cursor event are returned, unless the cursor is zero.
+func (r *EventRepository) Find(filter EventFilter, cursor uint, limit int) ([]*AuditEvent, error) {
+	query := r.db.Where("organization_id = ?", filter.OrganizationID)
+
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("time < ?", filter.To)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", strings.ToUpper(filter.Method))
+	}
+	if filter.PathPrefix != "" {
+		query = query.Where("path LIKE ?", escapeLike(filter.PathPrefix)+"%")
+	}
+	if filter.StatusCode > 0 {
+		query = query.Where("status_code = ?", filter.StatusCode)
+	}
+	if filter.CorrelationID != "" {
+		query = query.Where("correlation_id = ?", filter.CorrelationID)
+	}
+
+	var events []*AuditEvent
+
+	err := query.Order("id DESC").Limit(limit).Find(&events).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch audit events"),
+			"organization", filter.OrganizationID,
+		)
+	}
+
+	return events, nil
+}
+
+// escapeLike escapes the wildcard characters of a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,78 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"testing"
+	"time"
+
+	pkgAudit "github.com/banzaicloud/pipeline/pkg/audit"
+	"github.com/pkg/errors"
+)
+
+func TestNewEventFilter(t *testing.T) {
+	filter, err := NewEventFilter(1, pkgAudit.ListEventsQuery{
+		UserID: 2,
+		From:   "2019-02-10T00:00:00Z",
+		To:     "2019-02-11T00:00:00+01:00",
+		Method: "post",
+		Path:   "/api/v1/orgs/1/clusters",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := EventFilter{
+		OrganizationID: 1,
+		UserID:         2,
+		From:           time.Date(2019, 2, 10, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2019, 2, 10, 23, 0, 0, 0, time.UTC),
+		Method:         "post",
+		PathPrefix:     "/api/v1/orgs/1/clusters",
+	}
+
+	if filter.OrganizationID != expected.OrganizationID ||
+		filter.UserID != expected.UserID ||
+		!filter.From.Equal(expected.From) ||
+		!filter.To.Equal(expected.To) ||
+		filter.Method != expected.Method ||
+		filter.PathPrefix != expected.PathPrefix {
+		t.Errorf("expected: %+v, got: %+v", expected, filter)
+	}
+}
+
+func TestNewEventFilter_Invalid(t *testing.T) {
+	tests := map[string]pkgAudit.ListEventsQuery{
+		"invalid from":  {From: "yesterday"},
+		"invalid to":    {To: "2019-02-10"},
+		"reverse range": {From: "2019-02-11T00:00:00Z", To: "2019-02-10T00:00:00Z"},
+	}
+
+	for name, query := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEventFilter(1, query)
+
+			if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+				t.Errorf("expected invalid error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	if actual := escapeLike(`/api/v1/orgs/1/secrets_%\`); actual != `/api/v1/orgs/1/secrets\_\%\\` {
+		t.Errorf("unexpected escaped pattern: %s", actual)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"time"
+)
+
+// Sink delivers audit events to an external system.
+type Sink interface {
+	// Name identifies the sink in the outbox, so it must not change between restarts.
+	Name() string
+
+	// Send delivers a batch of events. The whole batch is retried when an error is returned,
+	// so sinks must tolerate receiving the same event more than once.
+	Send(ctx context.Context, events []*AuditEvent) error
+}
+
+// ### [ Sink names ] ### //
+const (
+	WebhookSinkName = "webhook"
+	SyslogSinkName  = "syslog"
+	FileSinkName    = "file"
+)
+
+const (
+	minRetryBackoff = 5 * time.Second
+	maxRetryBackoff = 10 * time.Minute
+)
+
+// retryBackoff returns the exponential delay before the next delivery attempt after the given number of failed attempts.
+func retryBackoff(attempts int) time.Duration {
+	backoff := minRetryBackoff
+	for i := 0; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+
+	return backoff
+}
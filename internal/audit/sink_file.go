@@ -0,0 +1,74 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bufio"
+	"context"
+	"os"
+
+	pkgAudit "github.com/banzaicloud/pipeline/pkg/audit"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+// FileSink appends audit events to a file in NDJSON format.
+type FileSink struct {
+	path string
+}
+
+// NewFileSink returns a new FileSink instance.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Name implements the Sink interface.
+func (s *FileSink) Name() string {
+	return FileSinkName
+}
+
+// Send implements the Sink interface.
+// The file is reopened for every batch, so that it can be rotated by external tools.
+func (s *FileSink) Send(ctx context.Context, events []*AuditEvent) error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not open audit file"), "path", s.path)
+	}
+	defer file.Close()
+
+	buffer := bufio.NewWriter(file)
+
+	writer, err := NewEventWriter(pkgAudit.FormatNDJSON, buffer)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if err := writer.Write(event); err != nil {
+			return err
+		}
+	}
+
+	if err := buffer.Flush(); err != nil {
+		return emperror.With(errors.Wrap(err, "could not write audit file"), "path", s.path)
+	}
+
+	// Events are removed from the outbox after this, so they must be persisted
+	if err := file.Sync(); err != nil {
+		return emperror.With(errors.Wrap(err, "could not sync audit file"), "path", s.path)
+	}
+
+	return nil
+}
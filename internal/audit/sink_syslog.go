@@ -0,0 +1,137 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Audit events are security related, so they are sent with the authpriv facility and informational severity
+	syslogPriority = 10*8 + 6
+
+	syslogMessageID   = "audit"
+	syslogTimestamp   = "2006-01-02T15:04:05.000000Z07:00"
+	syslogDialTimeout = 10 * time.Second
+)
+
+// SyslogSink sends audit events as RFC5424 syslog messages with the JSON encoded event as the message.
+// Stream transports (tcp and tls) use octet counting framing as defined in RFC6587.
+type SyslogSink struct {
+	network  string
+	address  string
+	appName  string
+	hostname string
+}
+
+// NewSyslogSink returns a new SyslogSink instance.
+// The network can be udp, tcp or tls.
+func NewSyslogSink(network string, address string, appName string) (*SyslogSink, error) {
+	switch network {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, errors.Errorf("unsupported syslog network: %s", network)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+
+	return &SyslogSink{
+		network:  network,
+		address:  address,
+		appName:  appName,
+		hostname: hostname,
+	}, nil
+}
+
+// Name implements the Sink interface.
+func (s *SyslogSink) Name() string {
+	return SyslogSinkName
+}
+
+func (s *SyslogSink) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: syslogDialTimeout}
+
+	if s.network == "tls" {
+		return tls.DialWithDialer(dialer, "tcp", s.address, &tls.Config{})
+	}
+
+	return dialer.Dial(s.network, s.address)
+}
+
+// Send implements the Sink interface.
+func (s *SyslogSink) Send(ctx context.Context, events []*AuditEvent) error {
+	conn, err := s.dial()
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not connect to syslog"), "address", s.address)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+	}
+
+	for _, event := range events {
+		message, err := formatSyslogMessage(event, s.hostname, s.appName, os.Getpid())
+		if err != nil {
+			return err
+		}
+
+		if s.network != "udp" {
+			message = strconv.Itoa(len(message)) + " " + message
+		}
+
+		if _, err := conn.Write([]byte(message)); err != nil {
+			return emperror.With(errors.Wrap(err, "could not send audit event to syslog"), "address", s.address)
+		}
+	}
+
+	return nil
+}
+
+// formatSyslogMessage formats an audit event as an RFC5424 syslog message.
+func formatSyslogMessage(event *AuditEvent, hostname string, appName string, pid int) (string, error) {
+	body, err := json.Marshal(event.ConvertModelToEntity())
+	if err != nil {
+		return "", errors.Wrap(err, "could not encode audit event")
+	}
+
+	if appName == "" {
+		appName = "-"
+	}
+
+	return fmt.Sprintf(
+		"<%d>1 %s %s %s %d %s - %s",
+		syslogPriority,
+		event.Time.UTC().Format(syslogTimestamp),
+		hostname,
+		appName,
+		pid,
+		syslogMessageID,
+		body,
+	), nil
+}
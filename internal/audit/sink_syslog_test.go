@@ -0,0 +1,39 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"testing"
+)
+
+func TestFormatSyslogMessage(t *testing.T) {
+	message, err := formatSyslogMessage(testEvents()[1], "pipeline-0", "pipeline", 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `<86>1 2019-02-10T11:00:00.000000Z pipeline-0 pipeline 42 audit - ` +
+		`{"id":1,"time":"2019-02-10T11:00:00Z","correlationId":"","clientIp":"","userAgent":"","path":"/api/v1/orgs/1/clusters?fields=name,status","method":"GET","userId":1,"statusCode":200}`
+
+	if message != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, message)
+	}
+}
+
+func TestNewSyslogSink_UnsupportedNetwork(t *testing.T) {
+	if _, err := NewSyslogSink("unix", "/dev/log", "pipeline"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	tests := map[int]time.Duration{
+		0:   5 * time.Second,
+		1:   10 * time.Second,
+		3:   40 * time.Second,
+		7:   10 * time.Minute,
+		6:   320 * time.Second,
+		8:   10 * time.Minute,
+		100: 10 * time.Minute,
+	}
+
+	for attempts, expected := range tests {
+		if actual := retryBackoff(attempts); actual != expected {
+			t.Errorf("attempts %d: expected %s, got %s", attempts, expected, actual)
+		}
+	}
+}
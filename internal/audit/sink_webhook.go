@@ -0,0 +1,108 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	pkgAudit "github.com/banzaicloud/pipeline/pkg/audit"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+// WebhookSignatureHeader contains the hex encoded HMAC-SHA256 signature of the request body prefixed with "sha256=".
+const WebhookSignatureHeader = "X-Pipeline-Signature-256"
+
+// WebhookSink posts batches of audit events as a JSON array to an HTTP endpoint.
+type WebhookSink struct {
+	url    string
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookSink returns a new WebhookSink instance.
+// Requests are signed with the secret, unless it is empty.
+func NewWebhookSink(url string, secret string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name implements the Sink interface.
+func (s *WebhookSink) Name() string {
+	return WebhookSinkName
+}
+
+// Send implements the Sink interface.
+func (s *WebhookSink) Send(ctx context.Context, events []*AuditEvent) error {
+	payload := make([]pkgAudit.EventResponse, 0, len(events))
+	for _, event := range events {
+		payload = append(payload, event.ConvertModelToEntity())
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "could not encode audit events")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create webhook request")
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not send audit events to webhook")
+	}
+	defer resp.Body.Close()
+
+	// Drain the body, so that the connection can be reused
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return emperror.With(
+			errors.New(fmt.Sprintf("webhook responded with status %d", resp.StatusCode)),
+			"url", s.url,
+		)
+	}
+
+	return nil
+}
+
+// SignWebhookPayload returns the hex encoded HMAC-SHA256 signature of a webhook payload.
+func SignWebhookPayload(secret []byte, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,62 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	pkgAudit "github.com/banzaicloud/pipeline/pkg/audit"
+)
+
+func TestWebhookSink_Send(t *testing.T) {
+	var received []pkgAudit.EventResponse
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if signature := r.Header.Get(WebhookSignatureHeader); signature != "sha256="+SignWebhookPayload([]byte("secret"), body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, "secret", time.Second)
+
+	if err := sink.Send(context.Background(), testEvents()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(received) != 2 || received[0].ID != 2 || received[1].ID != 1 {
+		t.Errorf("unexpected events received: %+v", received)
+	}
+
+	if err := NewWebhookSink(server.URL, "wrong", time.Second).Send(context.Background(), testEvents()); err == nil {
+		t.Error("expected error for rejected request")
+	}
+}
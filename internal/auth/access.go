@@ -16,7 +16,10 @@ package auth
 
 import (
 	"fmt"
+	"net/http"
+	"strings"
 
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
 	"github.com/casbin/casbin"
 )
 
@@ -54,11 +57,42 @@ func (m *AccessManager) GrantDefaultAccessToVirtualUser(userID string) {
 	m.enforcer.AddRoleForUser(userID, "defaultVirtual")
 }
 
-// AddOrganizationPolicies creates an organization role, by adding the default (*) org policies for the given organization.
+// AddOrganizationPolicies creates the built-in organization roles, by adding their policies for the given organization.
+// The admin role is the default (*) organization role.
 func (m *AccessManager) AddOrganizationPolicies(orgID uint) {
-	m.enforcer.AddPolicy(orgRoleName(orgID), fmt.Sprintf("%s/api/v1/orgs/%d", m.basePath, orgID), "*")
-	m.enforcer.AddPolicy(orgRoleName(orgID), fmt.Sprintf("%s/api/v1/orgs/%d/*", m.basePath, orgID), "*")
-	m.enforcer.AddPolicy(orgRoleName(orgID), fmt.Sprintf("%s/dashboard/orgs/%d/*", m.basePath, orgID), "*")
+	for _, role := range pkgAuth.BuiltInRoles {
+		m.addRolePolicies(orgID, role, builtInRoleRules[role])
+
+		dashboardMethod := http.MethodGet
+		if role == pkgAuth.RoleAdmin {
+			dashboardMethod = "*"
+		}
+
+		m.enforcer.AddPolicy(organizationRoleName(orgID, role), fmt.Sprintf("%s/dashboard/orgs/%d/*", m.basePath, orgID), dashboardMethod)
+	}
+}
+
+// SetOrganizationRolePolicies replaces the policies of a custom organization role with the ones created from the rules.
+func (m *AccessManager) SetOrganizationRolePolicies(orgID uint, role string, rules []pkgAuth.RoleRule) {
+	m.enforcer.RemoveFilteredPolicy(0, organizationRoleName(orgID, role))
+	m.addRolePolicies(orgID, role, rules)
+}
+
+// DeleteOrganizationRole removes a custom organization role with its policies and assignments.
+func (m *AccessManager) DeleteOrganizationRole(orgID uint, role string) {
+	m.enforcer.DeleteRole(organizationRoleName(orgID, role))
+}
+
+func (m *AccessManager) addRolePolicies(orgID uint, role string, rules []pkgAuth.RoleRule) {
+	roleName := organizationRoleName(orgID, role)
+
+	for _, rule := range rules {
+		path := fmt.Sprintf("%s/api/v1/orgs/%d%s", m.basePath, orgID, rule.Path)
+
+		for _, method := range rule.Methods {
+			m.enforcer.AddPolicy(roleName, path, strings.ToUpper(method))
+		}
+	}
 }
 
 // GrantOganizationAccessToUser adds a user to an organization by adding the associated organization role.
@@ -66,9 +100,41 @@ func (m *AccessManager) GrantOganizationAccessToUser(userID string, orgID uint)
 	m.enforcer.AddRoleForUser(userID, orgRoleName(orgID))
 }
 
-// RevokeOrganizationAccessFromUser removes a user from an organization by removing the associated organization role.
+// GrantOrganizationRoleToUser replaces the organization roles of a user with the given built-in or custom role.
+// The policies of custom roles must be set before.
+func (m *AccessManager) GrantOrganizationRoleToUser(userID string, orgID uint, role string) {
+	if IsBuiltInRole(role) {
+		m.AddOrganizationPolicies(orgID)
+	}
+
+	m.RevokeOrganizationAccessFromUser(userID, orgID)
+	m.enforcer.AddRoleForUser(userID, organizationRoleName(orgID, role))
+}
+
+// GetOrganizationRoleOfUser returns the built-in or custom role of a user in an organization.
+func (m *AccessManager) GetOrganizationRoleOfUser(userID string, orgID uint) (string, bool) {
+	orgRole := orgRoleName(orgID)
+
+	for _, role := range m.enforcer.GetRolesForUser(userID) {
+		if role == orgRole {
+			return pkgAuth.RoleAdmin, true
+		}
+
+		if strings.HasPrefix(role, orgRole+"-") {
+			return strings.TrimPrefix(role, orgRole+"-"), true
+		}
+	}
+
+	return "", false
+}
+
+// RevokeOrganizationAccessFromUser removes a user from an organization by removing every associated organization role.
 func (m *AccessManager) RevokeOrganizationAccessFromUser(userID string, orgID uint) {
-	m.enforcer.DeleteRoleForUser(userID, orgRoleName(orgID))
+	for _, role := range m.enforcer.GetRolesForUser(userID) {
+		if isOrganizationRole(orgID, role) {
+			m.enforcer.DeleteRoleForUser(userID, role)
+		}
+	}
 }
 
 // RevokeAllAccessFromUser removes all roles for a given user.
@@ -18,6 +18,7 @@ import (
 	"net/http"
 	"testing"
 
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
 	"github.com/casbin/gorm-adapter"
 	_ "github.com/jinzhu/gorm/dialects/sqlite"
 	"github.com/stretchr/testify/assert"
@@ -287,3 +288,145 @@ func TestAccessManager_RevokeAllAccessFromUser(t *testing.T) {
 		})
 	}
 }
+
+func TestAccessManager_GrantOrganizationRoleToUser(t *testing.T) {
+	adapter := gormadapter.NewAdapter("sqlite3", "file::memory:")
+	enforcer := NewEnforcer(adapter)
+	accessManager := NewAccessManager(enforcer, "")
+
+	enforcer.ClearPolicy()
+
+	accessManager.GrantOrganizationRoleToUser("admin", 1, "admin")
+	accessManager.GrantOrganizationRoleToUser("operator", 1, "cluster-operator")
+	accessManager.GrantOrganizationRoleToUser("developer", 1, "developer")
+	accessManager.GrantOrganizationRoleToUser("viewer", 1, "viewer")
+
+	// The previous role of a user is replaced
+	accessManager.GrantOrganizationRoleToUser("demoted", 1, "admin")
+	accessManager.GrantOrganizationRoleToUser("demoted", 1, "viewer")
+
+	tests := []struct {
+		user           string
+		path           string
+		method         string
+		expectedResult bool
+	}{
+		{user: "admin", path: "/api/v1/orgs/1/secrets", method: http.MethodPost, expectedResult: true},
+		{user: "admin", path: "/api/v1/orgs/1/roles", method: http.MethodPost, expectedResult: true},
+		{user: "admin", path: "/api/v1/orgs/2/clusters", method: http.MethodGet, expectedResult: false},
+
+		{user: "operator", path: "/api/v1/orgs/1/clusters", method: http.MethodPost, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/clusters/3", method: http.MethodDelete, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/clusters/3/deployments", method: http.MethodPost, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/backups", method: http.MethodGet, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/backupbuckets", method: http.MethodPost, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/cloudinfo/amazon", method: http.MethodGet, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/azure/resourcegroups", method: http.MethodPost, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/google/projects", method: http.MethodGet, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/domains/example.org/verify", method: http.MethodPost, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/clustertemplates/small/clusters", method: http.MethodPost, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/alerting", method: http.MethodPut, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/clusters/3/alerting/rendered", method: http.MethodGet, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/migrations", method: http.MethodPost, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/clusters/3/proxy/api/v1/pods", method: http.MethodGet, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/clusters/3/backups/daily", method: http.MethodDelete, expectedResult: true},
+		{user: "operator", path: "/api/v1/orgs/1/secrets", method: http.MethodGet, expectedResult: false},
+		{user: "operator", path: "/api/v1/orgs/1/clusters/3/secrets", method: http.MethodGet, expectedResult: false},
+		{user: "operator", path: "/api/v1/orgs/1/clusters/3/secrets/my-secret", method: http.MethodPost, expectedResult: false},
+		{user: "operator", path: "/api/v1/orgs/1/secrets", method: http.MethodPost, expectedResult: false},
+		{user: "operator", path: "/api/v1/orgs/1/users/2", method: http.MethodPost, expectedResult: false},
+
+		{user: "developer", path: "/api/v1/orgs/1/clusters/3/deployments", method: http.MethodPost, expectedResult: true},
+		{user: "developer", path: "/api/v1/orgs/1/clusters/3/deployments/app", method: http.MethodDelete, expectedResult: true},
+		{user: "developer", path: "/api/v1/orgs/1/clusters/3", method: http.MethodGet, expectedResult: true},
+		{user: "developer", path: "/api/v1/orgs/1/clusters/3/desireddeployments/app", method: http.MethodPut, expectedResult: true},
+		{user: "developer", path: "/api/v1/orgs/1/deployments", method: http.MethodPost, expectedResult: true},
+		{user: "developer", path: "/api/v1/orgs/1/clusters", method: http.MethodPost, expectedResult: false},
+		{user: "developer", path: "/api/v1/orgs/1/clusters/3", method: http.MethodDelete, expectedResult: false},
+		{user: "developer", path: "/api/v1/orgs/1/migrations", method: http.MethodPost, expectedResult: false},
+		{user: "developer", path: "/api/v1/orgs/1/secrets", method: http.MethodGet, expectedResult: false},
+		{user: "developer", path: "/api/v1/orgs/1/secrets", method: http.MethodPost, expectedResult: false},
+
+		{user: "viewer", path: "/api/v1/orgs/1", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/clusters/3/deployments", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/dashboard/orgs/1/clusters", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/clusters/3/deployments", method: http.MethodPost, expectedResult: false},
+		{user: "viewer", path: "/api/v1/orgs/1/deployments/5", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/deployments", method: http.MethodPost, expectedResult: false},
+		{user: "viewer", path: "/api/v1/orgs/1/clusters/3/labels", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/clusters/3/labels", method: http.MethodPut, expectedResult: false},
+		{user: "viewer", path: "/api/v1/orgs/1/alerting", method: http.MethodGet, expectedResult: false},
+		{user: "viewer", path: "/api/v1/orgs/1/usage", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/clusters/3/usage", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/clusters/3/config", method: http.MethodGet, expectedResult: false},
+		{user: "viewer", path: "/api/v1/orgs/1/secrets", method: http.MethodGet, expectedResult: false},
+		{user: "viewer", path: "/api/v1/orgs/1/domains/example.org", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/domains", method: http.MethodPost, expectedResult: false},
+		{user: "viewer", path: "/api/v1/orgs/1/clustertemplates/small/versions", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/clusters/3/template/drift", method: http.MethodGet, expectedResult: true},
+		{user: "viewer", path: "/api/v1/orgs/1/clustertemplates", method: http.MethodPost, expectedResult: false},
+
+		{user: "demoted", path: "/api/v1/orgs/1/clusters", method: http.MethodGet, expectedResult: true},
+		{user: "demoted", path: "/api/v1/orgs/1/clusters", method: http.MethodPost, expectedResult: false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			granted := enforcer.Enforce(test.user, test.path, test.method)
+
+			assert.Equal(t, test.expectedResult, granted, "%s %s %s", test.user, test.method, test.path)
+		})
+	}
+}
+
+func TestAccessManager_GetOrganizationRoleOfUser(t *testing.T) {
+	adapter := gormadapter.NewAdapter("sqlite3", "file::memory:")
+	enforcer := NewEnforcer(adapter)
+	accessManager := NewAccessManager(enforcer, "")
+
+	enforcer.ClearPolicy()
+
+	accessManager.GrantOrganizationRoleToUser("admin", 1, pkgAuth.RoleAdmin)
+	accessManager.GrantOrganizationRoleToUser("viewer", 1, pkgAuth.RoleViewer)
+	accessManager.GrantOrganizationRoleToUser("viewer", 12, pkgAuth.RoleDeveloper)
+
+	role, ok := accessManager.GetOrganizationRoleOfUser("admin", 1)
+	assert.True(t, ok)
+	assert.Equal(t, pkgAuth.RoleAdmin, role)
+
+	role, ok = accessManager.GetOrganizationRoleOfUser("viewer", 1)
+	assert.True(t, ok)
+	assert.Equal(t, pkgAuth.RoleViewer, role)
+
+	role, ok = accessManager.GetOrganizationRoleOfUser("viewer", 12)
+	assert.True(t, ok)
+	assert.Equal(t, pkgAuth.RoleDeveloper, role)
+
+	_, ok = accessManager.GetOrganizationRoleOfUser("admin", 12)
+	assert.False(t, ok)
+}
+
+func TestAccessManager_CustomOrganizationRole(t *testing.T) {
+	adapter := gormadapter.NewAdapter("sqlite3", "file::memory:")
+	enforcer := NewEnforcer(adapter)
+	accessManager := NewAccessManager(enforcer, "")
+
+	enforcer.ClearPolicy()
+
+	accessManager.SetOrganizationRolePolicies(1, "auditor", []pkgAuth.RoleRule{
+		{Path: "/clusters", Methods: []string{"*"}},
+	})
+	accessManager.SetOrganizationRolePolicies(1, "auditor", []pkgAuth.RoleRule{
+		{Path: "/audit", Methods: []string{"get"}},
+	})
+	accessManager.GrantOrganizationRoleToUser("user", 1, "auditor")
+
+	assert.True(t, enforcer.Enforce("user", "/api/v1/orgs/1/audit", http.MethodGet))
+	assert.False(t, enforcer.Enforce("user", "/api/v1/orgs/1/clusters", http.MethodGet), "previous policies should be replaced")
+
+	accessManager.DeleteOrganizationRole(1, "auditor")
+
+	assert.False(t, enforcer.Enforce("user", "/api/v1/orgs/1/audit", http.MethodGet))
+}
@@ -15,6 +15,10 @@
 package auth
 
 import (
+	"regexp"
+	"strings"
+	"sync"
+
 	"github.com/casbin/casbin"
 	"github.com/casbin/casbin/persist"
 )
@@ -33,7 +37,7 @@ g = _, _
 e = some(where (p.eft == allow))
 
 [matchers]
-m = g(r.sub, p.sub) && keyMatch(r.obj, p.obj) && (r.act == p.act || p.act == "*")
+m = g(r.sub, p.sub) && pathMatch(r.obj, p.obj) && (r.act == p.act || p.act == "*")
 `
 
 const logging = false
@@ -45,6 +49,39 @@ func NewEnforcer(adapter persist.Adapter) *casbin.SyncedEnforcer {
 	enforcer := casbin.NewSyncedEnforcer()
 	enforcer.EnableLog(logging)
 	enforcer.InitWithModelAndAdapter(model, adapter)
+	enforcer.AddFunction("pathMatch", pathMatchFunc)
 
 	return enforcer
 }
+
+var pathPatterns sync.Map
+
+// pathMatch determines whether the path matches the pattern of a policy.
+// A * matches any characters (including /), a :name segment matches a single path segment.
+func pathMatch(path string, pattern string) bool {
+	if !strings.ContainsAny(pattern, "*:") {
+		return path == pattern
+	}
+
+	if re, ok := pathPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp).MatchString(path)
+	}
+
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "[^/]+"
+		} else {
+			segments[i] = strings.Replace(regexp.QuoteMeta(segment), `\*`, ".*", -1)
+		}
+	}
+
+	re := regexp.MustCompile("^" + strings.Join(segments, "/") + "$")
+	pathPatterns.Store(pattern, re)
+
+	return re.MatchString(path)
+}
+
+func pathMatchFunc(args ...interface{}) (interface{}, error) {
+	return pathMatch(args[0].(string), args[1].(string)), nil
+}
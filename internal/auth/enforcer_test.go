@@ -0,0 +1,48 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+)
+
+func TestPathMatch(t *testing.T) {
+	tests := []struct {
+		path     string
+		pattern  string
+		expected bool
+	}{
+		{path: "/api/v1/orgs/1", pattern: "/api/v1/orgs/1", expected: true},
+		{path: "/api/v1/orgs/12", pattern: "/api/v1/orgs/1", expected: false},
+		{path: "/api/v1/orgs/1/clusters", pattern: "/api/v1/orgs/1/*", expected: true},
+		{path: "/api/v1/orgs/1", pattern: "/api/v1/orgs/1/*", expected: false},
+		{path: "/api/v1/orgs/12/clusters", pattern: "/api/v1/orgs/1/*", expected: false},
+		{path: "/api/v1/orgs/1/clusters/3/deployments", pattern: "/api/v1/orgs/1/clusters/:id/deployments", expected: true},
+		{path: "/api/v1/orgs/1/clusters/3/4/deployments", pattern: "/api/v1/orgs/1/clusters/:id/deployments", expected: false},
+		{path: "/api/v1/orgs/1/clusters/3/deployments/app/images", pattern: "/api/v1/orgs/1/clusters/:id/deployments/*", expected: true},
+		{path: "/api/v1/orgs/1/clusters/3/config", pattern: "/api/v1/orgs/1/clusters/:id", expected: false},
+		{path: "/pipeline/api/v1/orgs/1", pattern: "/pipeline.v2/api/v1/orgs/1/*", expected: false},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			if got := pathMatch(test.path, test.pattern); got != test.expected {
+				t.Errorf("pathMatch(%q, %q) = %t, expected %t", test.path, test.pattern, got, test.expected)
+			}
+		})
+	}
+}
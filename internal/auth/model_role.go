@@ -0,0 +1,77 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	rolesTableName = "organization_roles"
+)
+
+// RoleModel describes a custom role of an organization.
+type RoleModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"unique_index:idx_organization_role_org_name"`
+	Name           string `gorm:"unique_index:idx_organization_role_org_name"`
+	Description    string
+	Rules          string `sql:"type:text;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName changes the default table name.
+func (RoleModel) TableName() string {
+	return rolesTableName
+}
+
+// GetRules returns the rules of the role.
+func (m *RoleModel) GetRules() ([]pkgAuth.RoleRule, error) {
+	var rules []pkgAuth.RoleRule
+
+	if err := json.Unmarshal([]byte(m.Rules), &rules); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// Migrate executes the table migrations for the auth module.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&RoleModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating auth tables")
+
+	return db.AutoMigrate(tables...).Error
+}
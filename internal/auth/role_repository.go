@@ -0,0 +1,129 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// RoleRepository stores the custom roles of the organizations.
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository returns a new RoleRepository instance.
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+type roleNotFoundError struct {
+	organizationID uint
+	name           string
+}
+
+func (e *roleNotFoundError) Error() string {
+	return "role not found"
+}
+
+func (e *roleNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"organization", e.organizationID,
+		"role", e.name,
+	}
+}
+
+func (e *roleNotFoundError) NotFound() bool {
+	return true
+}
+
+// Find returns the custom roles of an organization.
+func (r *RoleRepository) Find(organizationID uint) ([]*RoleModel, error) {
+	var roles []*RoleModel
+
+	err := r.db.Where(&RoleModel{OrganizationID: organizationID}).Order("name").Find(&roles).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch roles"),
+			"organization", organizationID,
+		)
+	}
+
+	return roles, nil
+}
+
+// FindOne returns a custom role of an organization.
+func (r *RoleRepository) FindOne(organizationID uint, name string) (*RoleModel, error) {
+	var role RoleModel
+
+	err := r.db.Where(&RoleModel{OrganizationID: organizationID, Name: name}).First(&role).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&roleNotFoundError{organizationID: organizationID, name: name})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch role"),
+			"organization", organizationID,
+			"role", name,
+		)
+	}
+
+	return &role, nil
+}
+
+// Save creates or updates a custom role.
+func (r *RoleRepository) Save(role *RoleModel) error {
+	err := r.db.Save(role).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save role"),
+			"organization", role.OrganizationID,
+			"role", role.Name,
+		)
+	}
+
+	return nil
+}
+
+// Delete deletes a custom role.
+func (r *RoleRepository) Delete(role *RoleModel) error {
+	err := r.db.Delete(role).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not delete role"),
+			"organization", role.OrganizationID,
+			"role", role.Name,
+		)
+	}
+
+	return nil
+}
+
+// CountUsers returns the number of organization members having a role.
+func (r *RoleRepository) CountUsers(organizationID uint, name string) (int, error) {
+	var count int
+
+	err := r.db.Model(&auth.UserOrganization{}).Where("organization_id = ? AND role = ?", organizationID, name).Count(&count).Error
+	if err != nil {
+		return 0, emperror.With(
+			errors.Wrap(err, "could not count role members"),
+			"organization", organizationID,
+			"role", name,
+		)
+	}
+
+	return count, nil
+}
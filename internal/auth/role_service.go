@@ -0,0 +1,258 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
+	"github.com/pkg/errors"
+)
+
+type invalidRoleError struct {
+	message string
+}
+
+func (e *invalidRoleError) Error() string {
+	return e.message
+}
+
+func (e *invalidRoleError) IsInvalid() bool {
+	return true
+}
+
+func newInvalidRoleError(message string) error {
+	return errors.WithStack(&invalidRoleError{message})
+}
+
+type roleConflictError struct {
+	message string
+}
+
+func (e *roleConflictError) Error() string {
+	return e.message
+}
+
+func (e *roleConflictError) Conflict() bool {
+	return true
+}
+
+// RoleService manages the custom roles of the organizations and keeps their policies in sync.
+type RoleService struct {
+	repository    *RoleRepository
+	accessManager *AccessManager
+}
+
+// NewRoleService returns a new RoleService instance.
+func NewRoleService(repository *RoleRepository, accessManager *AccessManager) *RoleService {
+	return &RoleService{
+		repository:    repository,
+		accessManager: accessManager,
+	}
+}
+
+// ListRoles returns the built-in and the custom roles of an organization.
+func (s *RoleService) ListRoles(organizationID uint) ([]pkgAuth.RoleResponse, error) {
+	roles := make([]pkgAuth.RoleResponse, 0, len(pkgAuth.BuiltInRoles))
+
+	for _, role := range pkgAuth.BuiltInRoles {
+		roles = append(roles, builtInRoleResponse(role))
+	}
+
+	customRoles, err := s.repository.Find(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, role := range customRoles {
+		response, err := roleResponse(role)
+		if err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, response)
+	}
+
+	return roles, nil
+}
+
+// GetRole returns a built-in or custom role of an organization.
+func (s *RoleService) GetRole(organizationID uint, name string) (pkgAuth.RoleResponse, error) {
+	if IsBuiltInRole(name) {
+		return builtInRoleResponse(name), nil
+	}
+
+	role, err := s.repository.FindOne(organizationID, name)
+	if err != nil {
+		return pkgAuth.RoleResponse{}, err
+	}
+
+	return roleResponse(role)
+}
+
+// RoleExists returns true if the role is a built-in role or a custom role of the organization.
+func (s *RoleService) RoleExists(organizationID uint, name string) (bool, error) {
+	if IsBuiltInRole(name) {
+		return true, nil
+	}
+
+	_, err := s.repository.FindOne(organizationID, name)
+	if e, ok := errors.Cause(err).(*roleNotFoundError); ok && e.NotFound() {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CreateRole creates a custom role for an organization.
+func (s *RoleService) CreateRole(organizationID uint, req *pkgAuth.RoleRequest) (pkgAuth.RoleResponse, error) {
+	if err := validateRoleName(req.Name); err != nil {
+		return pkgAuth.RoleResponse{}, err
+	}
+
+	exists, err := s.RoleExists(organizationID, req.Name)
+	if err != nil {
+		return pkgAuth.RoleResponse{}, err
+	}
+
+	if exists {
+		return pkgAuth.RoleResponse{}, errors.WithStack(&roleConflictError{fmt.Sprintf("role %q already exists", req.Name)})
+	}
+
+	role := &RoleModel{
+		OrganizationID: organizationID,
+		Name:           req.Name,
+	}
+
+	return s.save(role, req)
+}
+
+// UpdateRole updates the rules of a custom role of an organization.
+func (s *RoleService) UpdateRole(organizationID uint, name string, req *pkgAuth.RoleRequest) (pkgAuth.RoleResponse, error) {
+	if IsBuiltInRole(name) {
+		return pkgAuth.RoleResponse{}, newInvalidRoleError("built-in roles cannot be modified")
+	}
+
+	if req.Name != name {
+		return pkgAuth.RoleResponse{}, newInvalidRoleError("roles cannot be renamed")
+	}
+
+	role, err := s.repository.FindOne(organizationID, name)
+	if err != nil {
+		return pkgAuth.RoleResponse{}, err
+	}
+
+	return s.save(role, req)
+}
+
+func (s *RoleService) save(role *RoleModel, req *pkgAuth.RoleRequest) (pkgAuth.RoleResponse, error) {
+	if err := validateRoleRules(req.Rules); err != nil {
+		return pkgAuth.RoleResponse{}, err
+	}
+
+	rules, err := json.Marshal(normalizeRoleRules(req.Rules))
+	if err != nil {
+		return pkgAuth.RoleResponse{}, errors.Wrap(err, "could not encode role rules")
+	}
+
+	role.Description = req.Description
+	role.Rules = string(rules)
+
+	if err := s.repository.Save(role); err != nil {
+		return pkgAuth.RoleResponse{}, err
+	}
+
+	response, err := roleResponse(role)
+	if err != nil {
+		return pkgAuth.RoleResponse{}, err
+	}
+
+	s.accessManager.SetOrganizationRolePolicies(role.OrganizationID, role.Name, response.Rules)
+
+	return response, nil
+}
+
+// DeleteRole deletes a custom role of an organization, if no member has it.
+func (s *RoleService) DeleteRole(organizationID uint, name string) error {
+	if IsBuiltInRole(name) {
+		return newInvalidRoleError("built-in roles cannot be deleted")
+	}
+
+	role, err := s.repository.FindOne(organizationID, name)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.repository.CountUsers(organizationID, name)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.WithStack(&roleConflictError{fmt.Sprintf("role %q is assigned to %d member(s)", name, count)})
+	}
+
+	if err := s.repository.Delete(role); err != nil {
+		return err
+	}
+
+	s.accessManager.DeleteOrganizationRole(organizationID, name)
+
+	return nil
+}
+
+// normalizeRoleRules upper-cases the methods of the rules.
+func normalizeRoleRules(rules []pkgAuth.RoleRule) []pkgAuth.RoleRule {
+	normalized := make([]pkgAuth.RoleRule, 0, len(rules))
+
+	for _, rule := range rules {
+		methods := make([]string, 0, len(rule.Methods))
+		for _, method := range rule.Methods {
+			methods = append(methods, strings.ToUpper(method))
+		}
+
+		normalized = append(normalized, pkgAuth.RoleRule{Path: rule.Path, Methods: methods})
+	}
+
+	return normalized
+}
+
+func builtInRoleResponse(role string) pkgAuth.RoleResponse {
+	return pkgAuth.RoleResponse{
+		Name:        role,
+		Description: builtInRoleDescriptions[role],
+		BuiltIn:     true,
+		Rules:       builtInRoleRules[role],
+	}
+}
+
+func roleResponse(role *RoleModel) (pkgAuth.RoleResponse, error) {
+	rules, err := role.GetRules()
+	if err != nil {
+		return pkgAuth.RoleResponse{}, errors.Wrap(err, "could not decode role rules")
+	}
+
+	return pkgAuth.RoleResponse{
+		Name:        role.Name,
+		Description: role.Description,
+		Rules:       rules,
+		CreatedAt:   &role.CreatedAt,
+		UpdatedAt:   &role.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,252 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
+)
+
+var (
+	readOnly   = []string{http.MethodGet}
+	allMethods = []string{"*"}
+)
+
+// viewerRules allow reading the organization, except for credentials: secrets, cluster configs and the cluster proxy.
+var viewerRules = []pkgAuth.RoleRule{
+	{Path: "", Methods: readOnly},
+	{Path: "/clusters", Methods: readOnly},
+	{Path: "/clusters/:id", Methods: readOnly},
+	{Path: "/clusters/:id/details", Methods: readOnly},
+	{Path: "/clusters/:id/pods", Methods: readOnly},
+	{Path: "/clusters/:id/health", Methods: readOnly},
+	{Path: "/clusters/:id/nodes", Methods: readOnly},
+	{Path: "/clusters/:id/endpoints", Methods: readOnly},
+	{Path: "/clusters/:id/deployments", Methods: readOnly},
+	{Path: "/clusters/:id/deployments/*", Methods: readOnly},
+	{Path: "/clusters/:id/desireddeployments", Methods: readOnly},
+	{Path: "/clusters/:id/desireddeployments/*", Methods: readOnly},
+	{Path: "/clusters/:id/hpa", Methods: readOnly},
+	{Path: "/clusters/:id/images", Methods: readOnly},
+	{Path: "/clusters/:id/images/*", Methods: readOnly},
+	{Path: "/clusters/:id/labels", Methods: readOnly},
+	{Path: "/clusters/:id/usage", Methods: readOnly},
+	{Path: "/clusters/:id/dns/records", Methods: readOnly},
+	{Path: "/clusters/:id/template", Methods: readOnly},
+	{Path: "/clusters/:id/template/*", Methods: readOnly},
+	{Path: "/clustertemplates", Methods: readOnly},
+	{Path: "/clustertemplates/*", Methods: readOnly},
+	{Path: "/deployments", Methods: readOnly},
+	{Path: "/deployments/*", Methods: readOnly},
+	{Path: "/helm/*", Methods: readOnly},
+	{Path: "/spotguides", Methods: readOnly},
+	{Path: "/spotguides/*", Methods: readOnly},
+	{Path: "/profiles/*", Methods: readOnly},
+	{Path: "/buckets", Methods: readOnly},
+	{Path: "/domain", Methods: readOnly},
+	{Path: "/domains", Methods: readOnly},
+	{Path: "/domains/*", Methods: readOnly},
+	{Path: "/dns/records", Methods: readOnly},
+	{Path: "/usage", Methods: readOnly},
+	{Path: "/users", Methods: readOnly},
+	{Path: "/users/*", Methods: readOnly},
+	{Path: "/roles", Methods: readOnly},
+	{Path: "/roles/*", Methods: readOnly},
+}
+
+// developerRules allow managing deployments on the existing clusters, but not the clusters or the secrets.
+var developerRules = append([]pkgAuth.RoleRule{
+	{Path: "/clusters/:id/deployments", Methods: allMethods},
+	{Path: "/clusters/:id/deployments/*", Methods: allMethods},
+	{Path: "/clusters/:id/desireddeployments", Methods: allMethods},
+	{Path: "/clusters/:id/desireddeployments/*", Methods: allMethods},
+	{Path: "/clusters/:id/hpa", Methods: allMethods},
+	{Path: "/clusters/:id/helminit", Methods: allMethods},
+	{Path: "/deployments", Methods: allMethods},
+	{Path: "/deployments/*", Methods: allMethods},
+	{Path: "/helm/*", Methods: allMethods},
+	{Path: "/spotguides", Methods: allMethods},
+	{Path: "/spotguides/*", Methods: allMethods},
+}, viewerRules...)
+
+// clusterOperatorRules allow managing clusters and everything running on them, but not the secrets of the organization.
+// The cluster routes are listed one by one to leave out the secret installing and listing ones.
+var clusterOperatorRules = append([]pkgAuth.RoleRule{
+	{Path: "/clusters", Methods: allMethods},
+	{Path: "/clusters/:id", Methods: allMethods},
+	{Path: "/clusters/:id/details", Methods: allMethods},
+	{Path: "/clusters/:id/pods", Methods: allMethods},
+	{Path: "/clusters/:id/posthooks", Methods: allMethods},
+	{Path: "/clusters/:id/posthooks/*", Methods: allMethods},
+	{Path: "/clusters/:id/proxy/*", Methods: allMethods},
+	{Path: "/clusters/:id/detach", Methods: allMethods},
+	{Path: "/clusters/:id/health", Methods: allMethods},
+	{Path: "/clusters/:id/config", Methods: allMethods},
+	{Path: "/clusters/:id/apiendpoint", Methods: allMethods},
+	{Path: "/clusters/:id/nodes", Methods: allMethods},
+	{Path: "/clusters/:id/endpoints", Methods: allMethods},
+	{Path: "/clusters/:id/scanlog", Methods: allMethods},
+	{Path: "/clusters/:id/scanlog/*", Methods: allMethods},
+	{Path: "/clusters/:id/whitelists", Methods: allMethods},
+	{Path: "/clusters/:id/whitelists/*", Methods: allMethods},
+	{Path: "/clusters/:id/policies", Methods: allMethods},
+	{Path: "/clusters/:id/policies/*", Methods: allMethods},
+	{Path: "/clusters/:id/images", Methods: allMethods},
+	{Path: "/clusters/:id/images/*", Methods: allMethods},
+	{Path: "/clusters/:id/imagescan", Methods: allMethods},
+	{Path: "/clusters/:id/imagescan/*", Methods: allMethods},
+	{Path: "/clusters/:id/namespaces/*", Methods: allMethods},
+	{Path: "/clusters/:id/dns/records", Methods: allMethods},
+	{Path: "/clusters/:id/template", Methods: allMethods},
+	{Path: "/clusters/:id/template/*", Methods: allMethods},
+	{Path: "/clusters/:id/labels", Methods: allMethods},
+	{Path: "/clusters/:id/alerting", Methods: allMethods},
+	{Path: "/clusters/:id/alerting/*", Methods: allMethods},
+	{Path: "/clusters/:id/logging", Methods: allMethods},
+	{Path: "/clusters/:id/logging/*", Methods: allMethods},
+	{Path: "/clusters/:id/usage", Methods: allMethods},
+	{Path: "/clusters/:id/backups", Methods: allMethods},
+	{Path: "/clusters/:id/backups/*", Methods: allMethods},
+	{Path: "/clusters/:id/backupservice", Methods: allMethods},
+	{Path: "/clusters/:id/backupservice/*", Methods: allMethods},
+	{Path: "/clusters/:id/restores", Methods: allMethods},
+	{Path: "/clusters/:id/restores/*", Methods: allMethods},
+	{Path: "/clusters/:id/schedules", Methods: allMethods},
+	{Path: "/clusters/:id/schedules/*", Methods: allMethods},
+	{Path: "/clustertemplates", Methods: allMethods},
+	{Path: "/clustertemplates/*", Methods: allMethods},
+	{Path: "/profiles/*", Methods: allMethods},
+	{Path: "/posthooks", Methods: allMethods},
+	{Path: "/posthooks/*", Methods: allMethods},
+	{Path: "/buckets", Methods: allMethods},
+	{Path: "/buckets/*", Methods: allMethods},
+	{Path: "/backups", Methods: allMethods},
+	{Path: "/backups/*", Methods: allMethods},
+	{Path: "/backupbuckets", Methods: allMethods},
+	{Path: "/backupbuckets/*", Methods: allMethods},
+	{Path: "/cloudinfo", Methods: readOnly},
+	{Path: "/cloudinfo/*", Methods: readOnly},
+	{Path: "/azure/*", Methods: allMethods},
+	{Path: "/google/*", Methods: readOnly},
+	{Path: "/domains", Methods: allMethods},
+	{Path: "/domains/*", Methods: allMethods},
+	{Path: "/dns/records", Methods: allMethods},
+	{Path: "/dns/records/*", Methods: allMethods},
+	{Path: "/alerting", Methods: allMethods},
+	{Path: "/migrations", Methods: allMethods},
+	{Path: "/migrations/*", Methods: allMethods},
+}, developerRules...)
+
+// adminRules allow everything in the organization.
+var adminRules = []pkgAuth.RoleRule{
+	{Path: "", Methods: allMethods},
+	{Path: "/*", Methods: allMethods},
+}
+
+var builtInRoleRules = map[string][]pkgAuth.RoleRule{
+	pkgAuth.RoleAdmin:           adminRules,
+	pkgAuth.RoleClusterOperator: clusterOperatorRules,
+	pkgAuth.RoleDeveloper:       developerRules,
+	pkgAuth.RoleViewer:          viewerRules,
+}
+
+var builtInRoleDescriptions = map[string]string{
+	pkgAuth.RoleAdmin:           "Full access to the organization",
+	pkgAuth.RoleClusterOperator: "Manages clusters and deployments",
+	pkgAuth.RoleDeveloper:       "Manages deployments on existing clusters",
+	pkgAuth.RoleViewer:          "Read-only access without credentials",
+}
+
+// IsBuiltInRole returns true if the role is available in every organization.
+func IsBuiltInRole(role string) bool {
+	_, ok := builtInRoleRules[role]
+
+	return ok
+}
+
+var (
+	rolePathRegexp = regexp.MustCompile(`^(/([a-zA-Z0-9_-]+|:[a-zA-Z]+|\*))*$`)
+	roleNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-]{0,62}$`)
+)
+
+var roleMethods = map[string]bool{
+	"*":                true,
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// validateRoleRules checks whether the rules of a custom role can be turned into policies.
+func validateRoleRules(rules []pkgAuth.RoleRule) error {
+	if len(rules) == 0 {
+		return newInvalidRoleError("a role needs at least one rule")
+	}
+
+	for _, rule := range rules {
+		if !rolePathRegexp.MatchString(rule.Path) {
+			return newInvalidRoleError(fmt.Sprintf("invalid rule path: %q", rule.Path))
+		}
+
+		if len(rule.Methods) == 0 {
+			return newInvalidRoleError(fmt.Sprintf("rule of path %q has no methods", rule.Path))
+		}
+
+		for _, method := range rule.Methods {
+			if !roleMethods[strings.ToUpper(method)] {
+				return newInvalidRoleError(fmt.Sprintf("invalid rule method: %q", method))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateRoleName checks whether the name can be used for a custom role.
+func validateRoleName(name string) error {
+	if !roleNameRegexp.MatchString(name) {
+		return newInvalidRoleError("role name must start with a letter and contain only lowercase letters, digits and dashes")
+	}
+
+	if IsBuiltInRole(name) {
+		return newInvalidRoleError(fmt.Sprintf("%q is a built-in role", name))
+	}
+
+	return nil
+}
+
+// organizationRoleName returns the name of an organization role in the enforcer.
+// Admins keep the original organization role, so existing members do not lose access.
+func organizationRoleName(orgID uint, role string) string {
+	if role == pkgAuth.RoleAdmin {
+		return orgRoleName(orgID)
+	}
+
+	return fmt.Sprintf("%s-%s", orgRoleName(orgID), role)
+}
+
+// isOrganizationRole returns true if the enforcer role belongs to the organization.
+func isOrganizationRole(orgID uint, name string) bool {
+	orgRole := orgRoleName(orgID)
+
+	return name == orgRole || strings.HasPrefix(name, orgRole+"-")
+}
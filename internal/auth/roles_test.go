@@ -0,0 +1,96 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
+	"github.com/pkg/errors"
+)
+
+func TestValidateRoleRules(t *testing.T) {
+	tests := map[string]struct {
+		rules []pkgAuth.RoleRule
+		valid bool
+	}{
+		"organization": {
+			rules: []pkgAuth.RoleRule{{Path: "", Methods: []string{"GET"}}},
+			valid: true,
+		},
+		"path with parameter": {
+			rules: []pkgAuth.RoleRule{{Path: "/clusters/:id/deployments/*", Methods: []string{"get", "POST"}}},
+			valid: true,
+		},
+		"every method": {
+			rules: []pkgAuth.RoleRule{{Path: "/secrets", Methods: []string{"*"}}},
+			valid: true,
+		},
+		"no rules": {
+			rules: nil,
+			valid: false,
+		},
+		"no methods": {
+			rules: []pkgAuth.RoleRule{{Path: "/clusters"}},
+			valid: false,
+		},
+		"unknown method": {
+			rules: []pkgAuth.RoleRule{{Path: "/clusters", Methods: []string{"FETCH"}}},
+			valid: false,
+		},
+		"relative path": {
+			rules: []pkgAuth.RoleRule{{Path: "clusters", Methods: []string{"GET"}}},
+			valid: false,
+		},
+		"regular expression": {
+			rules: []pkgAuth.RoleRule{{Path: "/clusters/(.*)", Methods: []string{"GET"}}},
+			valid: false,
+		},
+	}
+
+	for name, test := range tests {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			err := validateRoleRules(test.rules)
+
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !test.valid {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				if _, ok := errors.Cause(err).(interface{ IsInvalid() bool }); !ok {
+					t.Errorf("expected an invalid error, got: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRoleName(t *testing.T) {
+	for _, name := range []string{"release-manager", "auditor2"} {
+		if err := validateRoleName(name); err != nil {
+			t.Errorf("unexpected error for %q: %s", name, err)
+		}
+	}
+
+	for _, name := range []string{"", "admin", "viewer", "2fast", "Release", "release_manager"} {
+		if err := validateRoleName(name); err == nil {
+			t.Errorf("expected an error for %q", name)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/auth"
+	pkgAuth "github.com/banzaicloud/pipeline/pkg/auth"
+	"github.com/gin-gonic/gin"
+	"github.com/goph/emperror"
+)
+
+type apiTokenStore interface {
+	Find(tokenID string) (*auth.APIToken, error)
+	Touch(tokenID string, now time.Time) error
+}
+
+// scopeResources maps the paths of an organization to the resources of the token scopes.
+// The first matching resource is used, so nested resources have to precede their parents.
+var scopeResources = []struct {
+	resource string
+	paths    []string
+}{
+	{
+		resource: "deployments",
+		paths: []string{
+			"/deployments",
+			"/deployments/*",
+			"/clusters/:id/deployments",
+			"/clusters/:id/deployments/*",
+			"/clusters/:id/desireddeployments",
+			"/clusters/:id/desireddeployments/*",
+			"/clusters/:id/hpa",
+			"/clusters/:id/helminit",
+		},
+	},
+	{
+		resource: "secrets",
+		paths: []string{
+			"/secrets",
+			"/secrets/*",
+			"/clusters/:id/secrets",
+			"/clusters/:id/secrets/*",
+		},
+	},
+	{
+		resource: "clusters",
+		paths: []string{
+			"/clusters",
+			"/clusters/*",
+			"/clustertemplates",
+			"/clustertemplates/*",
+			"/profiles/*",
+			"/posthooks",
+			"/posthooks/*",
+			"/buckets",
+			"/buckets/*",
+			"/backups",
+			"/backups/*",
+			"/backupbuckets",
+			"/backupbuckets/*",
+			"/alerting",
+			"/alerting/*",
+			"/usage",
+			"/usage/*",
+			"/migrations",
+			"/migrations/*",
+		},
+	},
+	{
+		resource: "helm",
+		paths: []string{
+			"/helm/*",
+		},
+	},
+	{
+		resource: "spotguides",
+		paths: []string{
+			"/spotguides",
+			"/spotguides/*",
+		},
+	},
+	{
+		resource: "organization",
+		paths: []string{
+			"",
+			"/users",
+			"/users/*",
+			"/roles",
+			"/roles/*",
+			"/notifications",
+			"/notifications/*",
+			"/audit",
+			"/audit/*",
+			"/domain",
+			"/domains",
+			"/domains/*",
+			"/dns/records",
+			"/dns/records/*",
+		},
+	},
+}
+
+// clusterIndependentRules are the paths of an organization that tokens restricted to clusters can access,
+// as they neither act on nor reveal other clusters. Everything else outside of the allowed clusters is denied.
+// The secret list handler leaves out the values and the secrets not tagged with the allowed clusters.
+var clusterIndependentRules = []pkgAuth.RoleRule{
+	{Path: "", Methods: readOnly},
+	{Path: "/secrets", Methods: readOnly},
+	{Path: "/helm/*", Methods: allMethods},
+	{Path: "/spotguides", Methods: readOnly},
+	{Path: "/spotguides/*", Methods: readOnly},
+	{Path: "/profiles/*", Methods: allMethods},
+	{Path: "/posthooks", Methods: allMethods},
+	{Path: "/posthooks/*", Methods: allMethods},
+	{Path: "/buckets", Methods: allMethods},
+	{Path: "/buckets/*", Methods: allMethods},
+	{Path: "/clustertemplates", Methods: allMethods},
+	{Path: "/clustertemplates/:name", Methods: allMethods},
+	{Path: "/clustertemplates/:name/versions", Methods: allMethods},
+	{Path: "/clustertemplates/:name/versions/*", Methods: allMethods},
+	{Path: "/cloudinfo", Methods: readOnly},
+	{Path: "/cloudinfo/*", Methods: readOnly},
+	{Path: "/users", Methods: readOnly},
+	{Path: "/users/*", Methods: readOnly},
+	{Path: "/roles", Methods: readOnly},
+	{Path: "/roles/*", Methods: readOnly},
+	{Path: "/notifications", Methods: allMethods},
+	{Path: "/notifications/*", Methods: allMethods},
+	{Path: "/domain", Methods: readOnly},
+}
+
+// clusterCheckedPaths are the paths of an organization acting on clusters selected in the request body,
+// their handlers check the selected clusters against the clusters of the token.
+var clusterCheckedPaths = []string{
+	"/deployments",
+	"/deployments/*",
+	"/migrations",
+	"/migrations/*",
+}
+
+type tokenClustersKey struct{}
+
+// TokenClusters returns the clusters the API token of the request is restricted to,
+// or nil if the token is not restricted to clusters.
+func TokenClusters(ctx context.Context) []uint {
+	clusterIDs, _ := ctx.Value(tokenClustersKey{}).([]uint)
+
+	return clusterIDs
+}
+
+// IsClusterAllowed tells whether the API token of the request can access a cluster.
+func IsClusterAllowed(ctx context.Context, clusterID uint) bool {
+	clusterIDs := TokenClusters(ctx)
+
+	return clusterIDs == nil || isAllowedCluster(clusterIDs, clusterID)
+}
+
+// ClusterForbiddenError is returned when a cluster is not allowed for the API token of the request.
+// A zero ClusterID stands for a new cluster.
+type ClusterForbiddenError struct {
+	ClusterID uint
+}
+
+func (e *ClusterForbiddenError) Error() string {
+	if e.ClusterID == 0 {
+		return "new clusters are not allowed for the token"
+	}
+
+	return fmt.Sprintf("cluster %d is not allowed for the token", e.ClusterID)
+}
+
+// Forbidden tells the API layer to respond with 403.
+func (e *ClusterForbiddenError) Forbidden() bool {
+	return true
+}
+
+// NewTokenScopeMiddleware returns a new gin middleware that checks the restrictions of the API token of the request.
+// It has to run after the authorization middleware, as it only narrows down the access of the user.
+func NewTokenScopeMiddleware(tokens apiTokenStore, basePath string, errorHandler emperror.Handler) gin.HandlerFunc {
+	m := &tokenScopeMiddleware{
+		tokens:   tokens,
+		basePath: fmt.Sprintf("/%s", strings.Trim(basePath, "/")),
+	}
+
+	return func(c *gin.Context) {
+		user := auth.GetCurrentUser(c.Request)
+		if user == nil || user.APITokenID == "" {
+			return
+		}
+
+		token, err := m.tokens.Find(user.APITokenID)
+		if err != nil {
+			errorHandler.Handle(err)
+			c.AbortWithStatus(http.StatusInternalServerError)
+
+			return
+		}
+
+		if token == nil {
+			return
+		}
+
+		if err := m.tokens.Touch(token.ID, time.Now()); err != nil {
+			errorHandler.Handle(err)
+		}
+
+		if !m.CheckToken(token, c.Request.Method, c.Request.URL.Path) {
+			c.AbortWithStatus(http.StatusForbidden)
+
+			return
+		}
+
+		if clusterIDs := token.GetClusterIDs(); len(clusterIDs) > 0 {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), tokenClustersKey{}, clusterIDs))
+		}
+	}
+}
+
+type tokenScopeMiddleware struct {
+	tokens   apiTokenStore
+	basePath string
+}
+
+// CheckToken checks whether the method/path combination is allowed by the restrictions of the token.
+func (m *tokenScopeMiddleware) CheckToken(token *auth.APIToken, method string, path string) bool {
+	if !token.IsRestricted() {
+		return true
+	}
+
+	if m.basePath != "/" && strings.HasPrefix(path, m.basePath+"/") {
+		path = strings.TrimPrefix(path, m.basePath)
+	}
+
+	// Restricted tokens can list the organizations, but cannot access anything else outside of them
+	if path == "/api/v1/orgs" {
+		return method == http.MethodGet
+	}
+
+	const orgsPrefix = "/api/v1/orgs/"
+	if !strings.HasPrefix(path, orgsPrefix) {
+		return false
+	}
+
+	orgPath := strings.SplitN(strings.TrimPrefix(path, orgsPrefix), "/", 2)
+
+	orgID, err := strconv.ParseUint(orgPath[0], 10, 32)
+	if err != nil {
+		return false
+	}
+
+	if token.OrganizationID != 0 && uint(orgID) != token.OrganizationID {
+		return false
+	}
+
+	var subPath string
+	if len(orgPath) > 1 {
+		subPath = "/" + orgPath[1]
+	}
+
+	return checkTokenClusters(token.GetClusterIDs(), method, subPath) && checkTokenScopes(token.GetScopes(), method, subPath)
+}
+
+// checkTokenClusters checks whether the path of the organization is allowed for the clusters of the token.
+func checkTokenClusters(clusterIDs []uint, method string, path string) bool {
+	if len(clusterIDs) == 0 {
+		return true
+	}
+
+	// New clusters cannot be among the allowed ones
+	if path == "/clusters" {
+		return method == http.MethodGet || method == http.MethodHead
+	}
+
+	if pathMatch(path, "/clusters/:id") || pathMatch(path, "/clusters/:id/*") {
+		clusterID, err := strconv.ParseUint(strings.Split(path, "/")[2], 10, 32)
+		if err != nil {
+			return false
+		}
+
+		return isAllowedCluster(clusterIDs, uint(clusterID))
+	}
+
+	for _, pattern := range clusterCheckedPaths {
+		if pathMatch(path, pattern) {
+			return true
+		}
+	}
+
+	for _, rule := range clusterIndependentRules {
+		if pathMatch(path, rule.Path) && ruleAllowsMethod(rule, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isAllowedCluster(clusterIDs []uint, clusterID uint) bool {
+	for _, id := range clusterIDs {
+		if clusterID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ruleAllowsMethod(rule pkgAuth.RoleRule, method string) bool {
+	for _, m := range rule.Methods {
+		if m == "*" || m == method || (m == http.MethodGet && method == http.MethodHead) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkTokenScopes checks whether the method/path combination of the organization is allowed by the scopes of the token.
+// Write scopes allow every method, read scopes allow only GET and HEAD requests.
+func checkTokenScopes(scopes []string, method string, path string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+
+	resource := scopeResource(path)
+	if resource == "" {
+		return false
+	}
+
+	for _, scope := range scopes {
+		if scope == resource+":write" {
+			return true
+		}
+
+		if scope == resource+":read" && (method == http.MethodGet || method == http.MethodHead) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scopeResource returns the resource of a path of an organization, or an empty string if it is not covered by scopes.
+func scopeResource(path string) string {
+	for _, resource := range scopeResources {
+		for _, pattern := range resource.paths {
+			if pathMatch(path, pattern) {
+				return resource.resource
+			}
+		}
+	}
+
+	return ""
+}
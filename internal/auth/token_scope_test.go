@@ -0,0 +1,125 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/auth"
+)
+
+func TestTokenScopeMiddleware_CheckToken(t *testing.T) {
+	m := &tokenScopeMiddleware{basePath: "/pipeline"}
+
+	tokens := map[string]*auth.APIToken{
+		"unrestricted": {},
+		"organization": {OrganizationID: 1},
+		"clusters":     {OrganizationID: 1, ClusterIDs: "3,4"},
+		"ci":           {OrganizationID: 1, Scopes: "deployments:write,clusters:read"},
+	}
+
+	tests := []struct {
+		token    string
+		method   string
+		path     string
+		expected bool
+	}{
+		{token: "unrestricted", method: http.MethodPost, path: "/api/v1/tokens", expected: true},
+		{token: "unrestricted", method: http.MethodDelete, path: "/api/v1/orgs/2/clusters/5", expected: true},
+
+		{token: "organization", method: http.MethodGet, path: "/api/v1/orgs", expected: true},
+		{token: "organization", method: http.MethodGet, path: "/pipeline/api/v1/orgs/1/secrets", expected: true},
+		{token: "organization", method: http.MethodDelete, path: "/api/v1/orgs/1/clusters/5", expected: true},
+		{token: "organization", method: http.MethodGet, path: "/api/v1/orgs/2/clusters", expected: false},
+		{token: "organization", method: http.MethodGet, path: "/api/v1/orgs/12", expected: false},
+		{token: "organization", method: http.MethodPost, path: "/api/v1/tokens", expected: false},
+		{token: "organization", method: http.MethodGet, path: "/api/v1/allowed/secrets", expected: false},
+
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/clusters", expected: true},
+		{token: "clusters", method: http.MethodPost, path: "/api/v1/orgs/1/clusters", expected: false},
+		{token: "clusters", method: http.MethodPut, path: "/api/v1/orgs/1/clusters/3", expected: true},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/clusters/4/deployments", expected: true},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/clusters/5/deployments", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/clusters/name/config", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/secrets", expected: true},
+		{token: "clusters", method: http.MethodPost, path: "/api/v1/orgs/1/secrets", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/secrets/abc", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/users", expected: true},
+		{token: "clusters", method: http.MethodPost, path: "/api/v1/orgs/1/users/2", expected: false},
+		{token: "clusters", method: http.MethodPut, path: "/api/v1/orgs/1/roles/custom", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1", expected: true},
+		{token: "clusters", method: http.MethodDelete, path: "/api/v1/orgs/1", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/backups", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/backupbuckets", expected: false},
+		{token: "clusters", method: http.MethodPost, path: "/api/v1/orgs/1/spotguides", expected: false},
+		{token: "clusters", method: http.MethodPost, path: "/api/v1/orgs/1/clustertemplates/small/versions", expected: true},
+		{token: "clusters", method: http.MethodPost, path: "/api/v1/orgs/1/clustertemplates/small/clusters", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/unknown", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/dns/records", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/clusters/3/dns/records", expected: true},
+		{token: "clusters", method: http.MethodPost, path: "/api/v1/orgs/1/deployments", expected: true},
+		{token: "clusters", method: http.MethodPut, path: "/api/v1/orgs/1/clusters/3/alerting", expected: true},
+		{token: "clusters", method: http.MethodPut, path: "/api/v1/orgs/1/alerting", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/usage", expected: false},
+		{token: "clusters", method: http.MethodGet, path: "/api/v1/orgs/1/clusters/3/usage", expected: true},
+		{token: "clusters", method: http.MethodPost, path: "/api/v1/orgs/1/migrations", expected: true},
+
+		{token: "ci", method: http.MethodPost, path: "/api/v1/orgs/1/clusters/3/deployments", expected: true},
+		{token: "ci", method: http.MethodPut, path: "/api/v1/orgs/1/clusters/3/deployments/app", expected: true},
+		{token: "ci", method: http.MethodPut, path: "/api/v1/orgs/1/clusters/3/desireddeployments/app", expected: true},
+		{token: "ci", method: http.MethodPost, path: "/api/v1/orgs/1/deployments", expected: true},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/deployments/2", expected: true},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/migrations/2", expected: true},
+		{token: "ci", method: http.MethodPost, path: "/api/v1/orgs/1/migrations", expected: false},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/clusters/3", expected: true},
+		{token: "ci", method: http.MethodDelete, path: "/api/v1/orgs/1/clusters/3", expected: false},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/clusters/3/secrets", expected: false},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/secrets", expected: false},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/users", expected: false},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/cloudinfo", expected: false},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/backups", expected: true},
+		{token: "ci", method: http.MethodGet, path: "/api/v1/orgs/1/clustertemplates", expected: true},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run("", func(t *testing.T) {
+			if got := m.CheckToken(tokens[test.token], test.method, test.path); got != test.expected {
+				t.Errorf("%s token: %s %s = %t, expected %t", test.token, test.method, test.path, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestIsClusterAllowed(t *testing.T) {
+	ctx := context.Background()
+
+	if !IsClusterAllowed(ctx, 5) {
+		t.Error("every cluster should be allowed without token restrictions")
+	}
+
+	ctx = context.WithValue(ctx, tokenClustersKey{}, []uint{3, 4})
+
+	if !IsClusterAllowed(ctx, 3) {
+		t.Error("cluster 3 should be allowed")
+	}
+
+	if IsClusterAllowed(ctx, 5) {
+		t.Error("cluster 5 should not be allowed")
+	}
+}
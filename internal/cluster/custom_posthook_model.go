@@ -0,0 +1,86 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+// TableName constants
+const (
+	customPostHooksTableName = "custom_posthooks"
+)
+
+// CustomPostHookModel describes an organization defined posthook installing a Helm chart.
+type CustomPostHookModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"unique_index:idx_custom_posthook_org_name"`
+	Name           string `gorm:"unique_index:idx_custom_posthook_org_name"`
+
+	ChartRepository string
+	ChartName       string
+	ChartVersion    string
+	Namespace       string
+	ReleaseName     string
+	Values          string `sql:"type:text;"`
+	Wait            bool
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+}
+
+// TableName changes the default table name.
+func (CustomPostHookModel) TableName() string {
+	return customPostHooksTableName
+}
+
+// GetChart returns the chart reference in repository/name format.
+func (m *CustomPostHookModel) GetChart() string {
+	return m.ChartRepository + "/" + m.ChartName
+}
+
+// SetValuesFromRequest sets the modifiable fields from a request.
+func (m *CustomPostHookModel) SetValuesFromRequest(req *pkgCluster.CustomPostHookRequest) {
+	m.ChartRepository = req.ChartRepository
+	m.ChartName = req.ChartName
+	m.ChartVersion = req.ChartVersion
+	m.Namespace = req.Namespace
+	m.ReleaseName = req.ReleaseName
+	m.Values = req.Values
+	m.Wait = req.Wait
+}
+
+// ConvertModelToEntity converts a CustomPostHookModel to a pkgCluster.CustomPostHookResponse.
+func (m *CustomPostHookModel) ConvertModelToEntity() pkgCluster.CustomPostHookResponse {
+	return pkgCluster.CustomPostHookResponse{
+		ID: m.ID,
+		CustomPostHookRequest: pkgCluster.CustomPostHookRequest{
+			Name:            m.Name,
+			ChartRepository: m.ChartRepository,
+			ChartName:       m.ChartName,
+			ChartVersion:    m.ChartVersion,
+			Namespace:       m.Namespace,
+			ReleaseName:     m.ReleaseName,
+			Values:          m.Values,
+			Wait:            m.Wait,
+		},
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+		CreatedBy: m.CreatedBy,
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Labels acts as a repository for the labels of clusters.
+type Labels struct {
+	db *gorm.DB
+}
+
+// NewLabels returns a new Labels instance.
+func NewLabels(db *gorm.DB) *Labels {
+	return &Labels{db: db}
+}
+
+// FindByClusterID returns the labels of a cluster.
+func (l *Labels) FindByClusterID(clusterID uint) (map[string]string, error) {
+	var labels []*LabelModel
+
+	err := l.db.Where(&LabelModel{ClusterID: clusterID}).Find(&labels).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch cluster labels"), "cluster", clusterID)
+	}
+
+	result := make(map[string]string, len(labels))
+	for _, label := range labels {
+		result[label.Key] = label.Value
+	}
+
+	return result, nil
+}
+
+// FindByClusterIDs returns the labels of the given clusters by cluster ID.
+func (l *Labels) FindByClusterIDs(clusterIDs []uint) (map[uint]map[string]string, error) {
+	result := make(map[uint]map[string]string, len(clusterIDs))
+	if len(clusterIDs) == 0 {
+		return result, nil
+	}
+
+	var labels []*LabelModel
+
+	err := l.db.Where("cluster_id IN (?)", clusterIDs).Find(&labels).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch cluster labels")
+	}
+
+	for _, label := range labels {
+		if result[label.ClusterID] == nil {
+			result[label.ClusterID] = make(map[string]string)
+		}
+
+		result[label.ClusterID][label.Key] = label.Value
+	}
+
+	return result, nil
+}
+
+// ReplaceByClusterID replaces the labels of a cluster with a new set.
+func (l *Labels) ReplaceByClusterID(clusterID uint, labels map[string]string) error {
+	tx := l.db.Begin()
+
+	err := tx.Where(&LabelModel{ClusterID: clusterID}).Delete(&LabelModel{}).Error
+	if err != nil {
+		tx.Rollback()
+		return emperror.With(errors.Wrap(err, "could not delete cluster labels"), "cluster", clusterID)
+	}
+
+	for key, value := range labels {
+		err := tx.Create(&LabelModel{ClusterID: clusterID, Key: key, Value: value}).Error
+		if err != nil {
+			tx.Rollback()
+			return emperror.With(errors.Wrap(err, "could not create cluster label"), "cluster", clusterID, "label", key)
+		}
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit cluster labels")
+}
+
+// DeleteByClusterID deletes all labels of a cluster.
+func (l *Labels) DeleteByClusterID(clusterID uint) error {
+	err := l.db.Where(&LabelModel{ClusterID: clusterID}).Delete(&LabelModel{}).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete cluster labels"), "cluster", clusterID)
+	}
+
+	return nil
+}
@@ -26,6 +26,9 @@ import (
 func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 	tables := []interface{}{
 		&ClusterModel{},
+		&PostHookModel{},
+		&CustomPostHookModel{},
+		&LabelModel{},
 	}
 
 	var tableNames string
@@ -0,0 +1,97 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"strings"
+	"time"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+// TableName constants
+const (
+	clusterPostHooksTableName = "cluster_posthooks"
+)
+
+// PostHookModel describes the persisted state of a cluster posthook.
+type PostHookModel struct {
+	ID        uint   `gorm:"primary_key"`
+	ClusterID uint   `gorm:"unique_index:idx_cluster_posthook_name"`
+	Name      string `gorm:"unique_index:idx_cluster_posthook_name"`
+	Position  int
+	Params    string `sql:"type:text;"`
+	DependsOn string `sql:"type:text;"`
+
+	Status     string
+	Attempts   int
+	LastError  string `sql:"type:text;"`
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName changes the default table name.
+func (PostHookModel) TableName() string {
+	return clusterPostHooksTableName
+}
+
+// GetDependencies returns the names of the posthooks this posthook depends on.
+func (m *PostHookModel) GetDependencies() []string {
+	if m.DependsOn == "" {
+		return nil
+	}
+
+	return strings.Split(m.DependsOn, ",")
+}
+
+// SetDependencies sets the names of the posthooks this posthook depends on.
+func (m *PostHookModel) SetDependencies(dependencies []string) {
+	m.DependsOn = strings.Join(dependencies, ",")
+}
+
+// IsFinished returns true if the posthook does not need to be executed (anymore).
+func (m *PostHookModel) IsFinished() bool {
+	return m.Status == pkgCluster.PostHookSucceeded || m.Status == pkgCluster.PostHookSkipped
+}
+
+// Reset sets the posthook back to pending state.
+func (m *PostHookModel) Reset() {
+	m.Status = pkgCluster.PostHookPending
+	m.LastError = ""
+	m.StartedAt = nil
+	m.FinishedAt = nil
+}
+
+// ConvertModelToEntity converts a PostHookModel to a pkgCluster.PostHookStatus.
+func (m *PostHookModel) ConvertModelToEntity() pkgCluster.PostHookStatus {
+	status := pkgCluster.PostHookStatus{
+		Name:       m.Name,
+		DependsOn:  m.GetDependencies(),
+		Status:     m.Status,
+		Attempts:   m.Attempts,
+		LastError:  m.LastError,
+		StartedAt:  m.StartedAt,
+		FinishedAt: m.FinishedAt,
+	}
+
+	if m.StartedAt != nil && m.FinishedAt != nil {
+		status.Duration = m.FinishedAt.Sub(*m.StartedAt).String()
+	}
+
+	return status
+}
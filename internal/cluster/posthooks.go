@@ -0,0 +1,142 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// PostHooks acts as a repository for the persisted posthook states of clusters.
+type PostHooks struct {
+	db *gorm.DB
+}
+
+// NewPostHooks returns a new PostHooks instance.
+func NewPostHooks(db *gorm.DB) *PostHooks {
+	return &PostHooks{db: db}
+}
+
+type postHookNotFoundError struct {
+	clusterID uint
+	name      string
+}
+
+func (e *postHookNotFoundError) Error() string {
+	return "posthook not found"
+}
+
+func (e *postHookNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"cluster", e.clusterID,
+		"posthook", e.name,
+	}
+}
+
+func (e *postHookNotFoundError) NotFound() bool {
+	return true
+}
+
+// FindByClusterID returns the posthooks of a cluster in execution order.
+func (p *PostHooks) FindByClusterID(clusterID uint) ([]*PostHookModel, error) {
+	var postHooks []*PostHookModel
+
+	err := p.db.Where(&PostHookModel{ClusterID: clusterID}).Order("position").Find(&postHooks).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch posthooks"), "cluster", clusterID)
+	}
+
+	return postHooks, nil
+}
+
+// FindOne returns a posthook of a cluster by name.
+func (p *PostHooks) FindOne(clusterID uint, name string) (*PostHookModel, error) {
+	var postHook PostHookModel
+
+	err := p.db.Where(&PostHookModel{ClusterID: clusterID, Name: name}).First(&postHook).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&postHookNotFoundError{
+			clusterID: clusterID,
+			name:      name,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get posthook"),
+			"cluster", clusterID,
+			"posthook", name,
+		)
+	}
+
+	return &postHook, nil
+}
+
+// FindUnfinishedClusterIDs returns the IDs of clusters having pending or running posthooks.
+func (p *PostHooks) FindUnfinishedClusterIDs() ([]uint, error) {
+	var clusterIDs []uint
+
+	err := p.db.Model(&PostHookModel{}).
+		Where("status IN (?)", []string{pkgCluster.PostHookPending, pkgCluster.PostHookRunning}).
+		Pluck("DISTINCT cluster_id", &clusterIDs).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch clusters with unfinished posthooks")
+	}
+
+	return clusterIDs, nil
+}
+
+// ReplaceByClusterID replaces the posthooks of a cluster with a new set.
+func (p *PostHooks) ReplaceByClusterID(clusterID uint, postHooks []*PostHookModel) error {
+	tx := p.db.Begin()
+
+	err := tx.Where(&PostHookModel{ClusterID: clusterID}).Delete(&PostHookModel{}).Error
+	if err != nil {
+		tx.Rollback()
+		return emperror.With(errors.Wrap(err, "could not delete posthooks"), "cluster", clusterID)
+	}
+
+	for _, postHook := range postHooks {
+		postHook.ClusterID = clusterID
+
+		err := tx.Create(postHook).Error
+		if err != nil {
+			tx.Rollback()
+			return emperror.With(errors.Wrap(err, "could not create posthook"), "cluster", clusterID, "posthook", postHook.Name)
+		}
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit posthooks")
+}
+
+// Save persists the state of a posthook.
+func (p *PostHooks) Save(postHook *PostHookModel) error {
+	err := p.db.Save(postHook).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not save posthook"), "cluster", postHook.ClusterID, "posthook", postHook.Name)
+	}
+
+	return nil
+}
+
+// DeleteByClusterID deletes all posthooks of a cluster.
+func (p *PostHooks) DeleteByClusterID(clusterID uint) error {
+	err := p.db.Where(&PostHookModel{ClusterID: clusterID}).Delete(&PostHookModel{}).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete posthooks"), "cluster", clusterID)
+	}
+
+	return nil
+}
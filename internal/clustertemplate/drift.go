@@ -0,0 +1,289 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+)
+
+// NodePoolState describes the actual state of a node pool.
+type NodePoolState struct {
+	Count    int
+	MinCount int
+	MaxCount int
+}
+
+// ReleaseState describes the actual state of a Helm release.
+type ReleaseState struct {
+	ChartName    string
+	ChartVersion string
+	Namespace    string
+	Values       map[string]interface{}
+}
+
+// ClusterInspector reads the actual state of a cluster.
+type ClusterInspector interface {
+	// NodePools returns the node pools of the cluster by name.
+	NodePools() (map[string]NodePoolState, error)
+
+	// Release returns a Helm release of the cluster or nil if it is not installed.
+	Release(releaseName string) (*ReleaseState, error)
+
+	// SecretExists checks whether a secret is installed into the cluster.
+	SecretExists(namespace string, name string) (bool, error)
+
+	// PostHooks returns the status of the posthooks of the cluster by name.
+	PostHooks() (map[string]string, error)
+}
+
+// detectDrift compares the actual state of a cluster with a rendered template spec.
+func detectDrift(spec *pkgCluster.ClusterTemplateSpec, inspector ClusterInspector) ([]pkgCluster.ClusterTemplateDrift, error) {
+	drifts := []pkgCluster.ClusterTemplateDrift{}
+
+	nodePoolDrifts, err := detectNodePoolDrift(spec.Cluster.NodePoolCounts(), inspector)
+	if err != nil {
+		return nil, err
+	}
+	drifts = append(drifts, nodePoolDrifts...)
+
+	for _, deployment := range spec.Deployments {
+		release, err := inspector.Release(deployment.ReleaseName)
+		if err != nil {
+			return nil, emperror.With(err, "release", deployment.ReleaseName)
+		}
+
+		drifts = append(drifts, compareRelease(deployment, release)...)
+	}
+
+	for _, secret := range spec.Secrets {
+		exists, err := inspector.SecretExists(secret.Namespace, secret.Name)
+		if err != nil {
+			return nil, emperror.With(err, "secret", secret.Name)
+		}
+
+		if !exists {
+			drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+				Kind:     pkgCluster.TemplateDriftSecret,
+				Name:     secret.Name,
+				Expected: secret.Namespace,
+				Message:  "secret is not installed",
+			})
+		}
+	}
+
+	if len(spec.Cluster.PostHooks) > 0 {
+		statuses, err := inspector.PostHooks()
+		if err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for name := range spec.Cluster.PostHooks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			status, ok := statuses[name]
+			if !ok {
+				drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+					Kind:     pkgCluster.TemplateDriftPostHook,
+					Name:     name,
+					Expected: pkgCluster.PostHookSucceeded,
+					Message:  "posthook was not executed",
+				})
+			} else if status != pkgCluster.PostHookSucceeded {
+				drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+					Kind:     pkgCluster.TemplateDriftPostHook,
+					Name:     name,
+					Expected: pkgCluster.PostHookSucceeded,
+					Actual:   status,
+					Message:  "posthook did not succeed",
+				})
+			}
+		}
+	}
+
+	return drifts, nil
+}
+
+func detectNodePoolDrift(expected map[string]int, inspector ClusterInspector) ([]pkgCluster.ClusterTemplateDrift, error) {
+	var drifts []pkgCluster.ClusterTemplateDrift
+
+	if len(expected) == 0 {
+		return drifts, nil
+	}
+
+	actual, err := inspector.NodePools()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range expected {
+		names = append(names, name)
+	}
+	for name := range actual {
+		if _, ok := expected[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		count, isExpected := expected[name]
+		state, isActual := actual[name]
+
+		switch {
+		case !isActual:
+			drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+				Kind:     pkgCluster.TemplateDriftNodePool,
+				Name:     name,
+				Expected: strconv.Itoa(count),
+				Message:  "node pool is missing",
+			})
+
+		case !isExpected:
+			drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+				Kind:    pkgCluster.TemplateDriftNodePool,
+				Name:    name,
+				Actual:  strconv.Itoa(state.Count),
+				Message: "node pool is not part of the template",
+			})
+
+		case state.MaxCount > state.MinCount:
+			// autoscaled node pools only drift when the template count falls outside of the scaling bounds
+			if count < state.MinCount || count > state.MaxCount {
+				drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+					Kind:     pkgCluster.TemplateDriftNodePool,
+					Name:     name,
+					Expected: strconv.Itoa(count),
+					Actual:   fmt.Sprintf("%d-%d", state.MinCount, state.MaxCount),
+					Message:  "node count is outside of the autoscaling bounds",
+				})
+			}
+
+		case state.Count != count:
+			drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+				Kind:     pkgCluster.TemplateDriftNodePool,
+				Name:     name,
+				Expected: strconv.Itoa(count),
+				Actual:   strconv.Itoa(state.Count),
+				Message:  "node count differs",
+			})
+		}
+	}
+
+	return drifts, nil
+}
+
+func compareRelease(deployment pkgCluster.ClusterTemplateDeployment, release *ReleaseState) []pkgCluster.ClusterTemplateDrift {
+	if release == nil {
+		return []pkgCluster.ClusterTemplateDrift{{
+			Kind:     pkgCluster.TemplateDriftDeployment,
+			Name:     deployment.ReleaseName,
+			Expected: deployment.Name,
+			Message:  "deployment is not installed",
+		}}
+	}
+
+	var drifts []pkgCluster.ClusterTemplateDrift
+
+	chartName := deployment.Name
+	if i := strings.LastIndex(chartName, "/"); i >= 0 {
+		chartName = chartName[i+1:]
+	}
+
+	if release.ChartName != chartName {
+		drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+			Kind:     pkgCluster.TemplateDriftDeployment,
+			Name:     deployment.ReleaseName,
+			Expected: chartName,
+			Actual:   release.ChartName,
+			Message:  "chart differs",
+		})
+	}
+
+	if deployment.Version != "" && release.ChartVersion != deployment.Version {
+		drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+			Kind:     pkgCluster.TemplateDriftDeployment,
+			Name:     deployment.ReleaseName,
+			Expected: deployment.Version,
+			Actual:   release.ChartVersion,
+			Message:  "chart version differs",
+		})
+	}
+
+	if release.Namespace != deployment.Namespace {
+		drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+			Kind:     pkgCluster.TemplateDriftDeployment,
+			Name:     deployment.ReleaseName,
+			Expected: deployment.Namespace,
+			Actual:   release.Namespace,
+			Message:  "namespace differs",
+		})
+	}
+
+	if paths := diffValues(deployment.Values, release.Values, ""); len(paths) > 0 {
+		drifts = append(drifts, pkgCluster.ClusterTemplateDrift{
+			Kind:    pkgCluster.TemplateDriftDeployment,
+			Name:    deployment.ReleaseName,
+			Message: "values differ: " + strings.Join(paths, ", "),
+		})
+	}
+
+	return drifts
+}
+
+// diffValues returns the paths of the expected values which are missing or different in the actual values.
+// Values not set by the template are ignored, so the chart defaults do not cause drift.
+func diffValues(expected map[string]interface{}, actual map[string]interface{}, prefix string) []string {
+	var paths []string
+
+	var keys []string
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := prefix + key
+		expectedValue := expected[key]
+		actualValue, ok := actual[key]
+
+		if !ok {
+			paths = append(paths, path)
+			continue
+		}
+
+		expectedMap, expectedIsMap := expectedValue.(map[string]interface{})
+		actualMap, actualIsMap := actualValue.(map[string]interface{})
+
+		if expectedIsMap && actualIsMap {
+			paths = append(paths, diffValues(expectedMap, actualMap, path+".")...)
+		} else if !reflect.DeepEqual(expectedValue, actualValue) && fmt.Sprint(expectedValue) != fmt.Sprint(actualValue) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
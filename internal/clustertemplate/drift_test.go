@@ -0,0 +1,124 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+type fakeInspector struct {
+	nodePools map[string]NodePoolState
+	releases  map[string]*ReleaseState
+	secrets   map[string]bool
+	postHooks map[string]string
+}
+
+func (i *fakeInspector) NodePools() (map[string]NodePoolState, error) {
+	return i.nodePools, nil
+}
+
+func (i *fakeInspector) Release(releaseName string) (*ReleaseState, error) {
+	return i.releases[releaseName], nil
+}
+
+func (i *fakeInspector) SecretExists(namespace string, name string) (bool, error) {
+	return i.secrets[namespace+"/"+name], nil
+}
+
+func (i *fakeInspector) PostHooks() (map[string]string, error) {
+	return i.postHooks, nil
+}
+
+func TestDetectDrift(t *testing.T) {
+	spec, _, err := renderSpec(testSpec, "test", map[string]string{"nodes": "3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	inspector := &fakeInspector{
+		nodePools: map[string]NodePoolState{
+			"pool1": {Count: 3},
+		},
+		releases: map[string]*ReleaseState{
+			"test-ingress": {
+				ChartName:    "nginx-ingress",
+				ChartVersion: "0.28.2",
+				Namespace:    "default",
+				Values: map[string]interface{}{
+					"controller": map[string]interface{}{
+						"replicaCount": float64(2),
+						"image":        "nginx",
+					},
+				},
+			},
+		},
+		secrets: map[string]bool{"default/registry": true},
+	}
+
+	drifts, err := detectDrift(spec, inspector)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(drifts) != 0 {
+		t.Errorf("expected no drift, got: %+v", drifts)
+	}
+
+	inspector.nodePools = map[string]NodePoolState{
+		"pool1": {Count: 5},
+		"pool2": {Count: 1},
+	}
+	inspector.releases["test-ingress"].Values = map[string]interface{}{
+		"controller": map[string]interface{}{"replicaCount": float64(1)},
+	}
+	inspector.secrets = nil
+
+	drifts, err = detectDrift(spec, inspector)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	expected := []pkgCluster.ClusterTemplateDrift{
+		{Kind: pkgCluster.TemplateDriftNodePool, Name: "pool1", Expected: "3", Actual: "5", Message: "node count differs"},
+		{Kind: pkgCluster.TemplateDriftNodePool, Name: "pool2", Actual: "1", Message: "node pool is not part of the template"},
+		{Kind: pkgCluster.TemplateDriftDeployment, Name: "test-ingress", Message: "values differ: controller.replicaCount"},
+		{Kind: pkgCluster.TemplateDriftSecret, Name: "registry", Expected: "default", Message: "secret is not installed"},
+	}
+
+	if len(drifts) != len(expected) {
+		t.Fatalf("unexpected drifts: %+v", drifts)
+	}
+
+	for i := range expected {
+		if drifts[i] != expected[i] {
+			t.Errorf("unexpected drift %d: %+v", i, drifts[i])
+		}
+	}
+}
+
+func TestDetectDrift_AutoscaledNodePool(t *testing.T) {
+	drifts, err := detectNodePoolDrift(map[string]int{"pool1": 3}, &fakeInspector{
+		nodePools: map[string]NodePoolState{"pool1": {Count: 5, MinCount: 2, MaxCount: 6}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if len(drifts) != 0 {
+		t.Errorf("expected no drift, got: %+v", drifts)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	templatesTableName        = "cluster_templates"
+	templateVersionsTableName = "cluster_template_versions"
+	templateBindingsTableName = "cluster_template_bindings"
+)
+
+// TemplateModel describes a cluster template of an organization.
+type TemplateModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"unique_index:idx_cluster_template_org_name"`
+	Name           string `gorm:"unique_index:idx_cluster_template_org_name"`
+	Description    string `sql:"type:text;"`
+	LatestVersion  int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+}
+
+// TableName changes the default table name.
+func (TemplateModel) TableName() string {
+	return templatesTableName
+}
+
+// ConvertModelToEntity converts a TemplateModel to a pkgCluster.ClusterTemplateResponse.
+func (m *TemplateModel) ConvertModelToEntity() pkgCluster.ClusterTemplateResponse {
+	return pkgCluster.ClusterTemplateResponse{
+		ID:            m.ID,
+		Name:          m.Name,
+		Description:   m.Description,
+		LatestVersion: m.LatestVersion,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+		CreatedBy:     m.CreatedBy,
+	}
+}
+
+// TemplateVersionModel describes an immutable version of a cluster template.
+type TemplateVersionModel struct {
+	ID         uint   `gorm:"primary_key"`
+	TemplateID uint   `gorm:"unique_index:idx_cluster_template_version"`
+	Version    int    `gorm:"unique_index:idx_cluster_template_version"`
+	Spec       string `sql:"type:text;"`
+
+	CreatedAt time.Time
+	CreatedBy uint
+}
+
+// TableName changes the default table name.
+func (TemplateVersionModel) TableName() string {
+	return templateVersionsTableName
+}
+
+// ConvertModelToEntity converts a TemplateVersionModel to a pkgCluster.ClusterTemplateVersionResponse.
+func (m *TemplateVersionModel) ConvertModelToEntity() pkgCluster.ClusterTemplateVersionResponse {
+	return pkgCluster.ClusterTemplateVersionResponse{
+		Version:   m.Version,
+		Spec:      m.Spec,
+		CreatedAt: m.CreatedAt,
+		CreatedBy: m.CreatedBy,
+	}
+}
+
+// BindingModel records the cluster template version and parameters a cluster was created from.
+type BindingModel struct {
+	ClusterID      uint `gorm:"primary_key;auto_increment:false"`
+	OrganizationID uint `gorm:"index"`
+	TemplateID     uint `gorm:"index"`
+	Version        int
+	Parameters     string `sql:"type:text;"`
+
+	CreatedAt time.Time
+}
+
+// TableName changes the default table name.
+func (BindingModel) TableName() string {
+	return templateBindingsTableName
+}
+
+// GetParameters returns the parameters the cluster was created with.
+func (m *BindingModel) GetParameters() map[string]string {
+	var parameters map[string]string
+
+	if m.Parameters != "" {
+		_ = json.Unmarshal([]byte(m.Parameters), &parameters)
+	}
+
+	return parameters
+}
+
+// SetParameters sets the parameters the cluster was created with.
+func (m *BindingModel) SetParameters(parameters map[string]string) {
+	if len(parameters) == 0 {
+		m.Parameters = ""
+		return
+	}
+
+	parametersJSON, _ := json.Marshal(parameters)
+	m.Parameters = string(parametersJSON)
+}
+
+// Migrate executes the table migrations for the cluster template module.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&TemplateModel{},
+		&TemplateVersionModel{},
+		&BindingModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating cluster template tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,293 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the cluster templates of the organizations and the template versions the clusters were created from.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+type templateNotFoundError struct {
+	organizationID uint
+	name           string
+}
+
+func (e *templateNotFoundError) Error() string {
+	return "cluster template not found"
+}
+
+func (e *templateNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"organization", e.organizationID,
+		"template", e.name,
+	}
+}
+
+func (e *templateNotFoundError) NotFound() bool {
+	return true
+}
+
+type templateVersionNotFoundError struct {
+	templateID uint
+	version    int
+}
+
+func (e *templateVersionNotFoundError) Error() string {
+	return "cluster template version not found"
+}
+
+func (e *templateVersionNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"template", e.templateID,
+		"version", e.version,
+	}
+}
+
+func (e *templateVersionNotFoundError) NotFound() bool {
+	return true
+}
+
+type bindingNotFoundError struct {
+	clusterID uint
+}
+
+func (e *bindingNotFoundError) Error() string {
+	return "cluster was not created from a template"
+}
+
+func (e *bindingNotFoundError) Context() []interface{} {
+	return []interface{}{"cluster", e.clusterID}
+}
+
+func (e *bindingNotFoundError) NotFound() bool {
+	return true
+}
+
+// FindTemplates returns the cluster templates of an organization.
+func (r *Repository) FindTemplates(organizationID uint) ([]*TemplateModel, error) {
+	var templates []*TemplateModel
+
+	err := r.db.Where(&TemplateModel{OrganizationID: organizationID}).Order("name").Find(&templates).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch cluster templates"),
+			"organization", organizationID,
+		)
+	}
+
+	return templates, nil
+}
+
+// FindOneTemplate returns a cluster template of an organization by name.
+func (r *Repository) FindOneTemplate(organizationID uint, name string) (*TemplateModel, error) {
+	var template TemplateModel
+
+	err := r.db.Where(&TemplateModel{OrganizationID: organizationID, Name: name}).First(&template).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&templateNotFoundError{
+			organizationID: organizationID,
+			name:           name,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get cluster template"),
+			"organization", organizationID,
+			"template", name,
+		)
+	}
+
+	return &template, nil
+}
+
+// FindTemplateByID returns a cluster template by its ID.
+func (r *Repository) FindTemplateByID(id uint) (*TemplateModel, error) {
+	var template TemplateModel
+
+	err := r.db.Where("id = ?", id).First(&template).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&templateNotFoundError{})
+	} else if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get cluster template"), "template", id)
+	}
+
+	return &template, nil
+}
+
+// SaveVersion persists a new version of a cluster template and makes it the latest one.
+// The template is created if it does not exist yet.
+func (r *Repository) SaveVersion(template *TemplateModel, version *TemplateVersionModel) error {
+	tx := r.db.Begin()
+	if err := tx.Error; err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	template.LatestVersion = version.Version
+
+	if err := tx.Save(template).Error; err != nil {
+		tx.Rollback()
+		return emperror.With(
+			errors.Wrap(err, "could not save cluster template"),
+			"organization", template.OrganizationID,
+			"template", template.Name,
+		)
+	}
+
+	version.TemplateID = template.ID
+
+	if err := tx.Create(version).Error; err != nil {
+		tx.Rollback()
+		return emperror.With(
+			errors.Wrap(err, "could not save cluster template version"),
+			"organization", template.OrganizationID,
+			"template", template.Name,
+			"version", version.Version,
+		)
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit transaction")
+}
+
+// SaveTemplate persists the fields of a cluster template.
+func (r *Repository) SaveTemplate(template *TemplateModel) error {
+	err := r.db.Save(template).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save cluster template"),
+			"organization", template.OrganizationID,
+			"template", template.Name,
+		)
+	}
+
+	return nil
+}
+
+// DeleteTemplate deletes a cluster template with all of its versions.
+func (r *Repository) DeleteTemplate(template *TemplateModel) error {
+	tx := r.db.Begin()
+	if err := tx.Error; err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	err := tx.Where(&TemplateVersionModel{TemplateID: template.ID}).Delete(&TemplateVersionModel{}).Error
+	if err != nil {
+		tx.Rollback()
+		return emperror.With(
+			errors.Wrap(err, "could not delete cluster template versions"),
+			"organization", template.OrganizationID,
+			"template", template.Name,
+		)
+	}
+
+	if err := tx.Delete(template).Error; err != nil {
+		tx.Rollback()
+		return emperror.With(
+			errors.Wrap(err, "could not delete cluster template"),
+			"organization", template.OrganizationID,
+			"template", template.Name,
+		)
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit transaction")
+}
+
+// FindVersions returns the versions of a cluster template.
+func (r *Repository) FindVersions(templateID uint) ([]*TemplateVersionModel, error) {
+	var versions []*TemplateVersionModel
+
+	err := r.db.Where(&TemplateVersionModel{TemplateID: templateID}).Order("version").Find(&versions).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch cluster template versions"), "template", templateID)
+	}
+
+	return versions, nil
+}
+
+// FindOneVersion returns a version of a cluster template.
+func (r *Repository) FindOneVersion(templateID uint, version int) (*TemplateVersionModel, error) {
+	var templateVersion TemplateVersionModel
+
+	err := r.db.Where(&TemplateVersionModel{TemplateID: templateID, Version: version}).First(&templateVersion).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&templateVersionNotFoundError{
+			templateID: templateID,
+			version:    version,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get cluster template version"),
+			"template", templateID,
+			"version", version,
+		)
+	}
+
+	return &templateVersion, nil
+}
+
+// CountBindings returns the number of clusters created from a cluster template.
+func (r *Repository) CountBindings(templateID uint) (int, error) {
+	var count int
+
+	err := r.db.Model(&BindingModel{}).Where(&BindingModel{TemplateID: templateID}).Count(&count).Error
+	if err != nil {
+		return 0, emperror.With(errors.Wrap(err, "could not count clusters created from template"), "template", templateID)
+	}
+
+	return count, nil
+}
+
+// FindBinding returns the cluster template version a cluster was created from.
+func (r *Repository) FindBinding(clusterID uint) (*BindingModel, error) {
+	var binding BindingModel
+
+	err := r.db.Where("cluster_id = ?", clusterID).First(&binding).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&bindingNotFoundError{clusterID: clusterID})
+	} else if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get cluster template binding"), "cluster", clusterID)
+	}
+
+	return &binding, nil
+}
+
+// SaveBinding persists the cluster template version a cluster was created from.
+func (r *Repository) SaveBinding(binding *BindingModel) error {
+	err := r.db.Save(binding).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not save cluster template binding"), "cluster", binding.ClusterID)
+	}
+
+	return nil
+}
+
+// DeleteBinding deletes the cluster template binding of a cluster.
+func (r *Repository) DeleteBinding(clusterID uint) error {
+	err := r.db.Where("cluster_id = ?", clusterID).Delete(&BindingModel{}).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete cluster template binding"), "cluster", clusterID)
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"fmt"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type invalidTemplateNameError struct {
+	name string
+}
+
+func (e *invalidTemplateNameError) Error() string {
+	return fmt.Sprintf("invalid cluster template name %q", e.name)
+}
+
+func (e *invalidTemplateNameError) IsInvalid() bool {
+	return true
+}
+
+type templateExistsError struct {
+	name string
+}
+
+func (e *templateExistsError) Error() string {
+	return fmt.Sprintf("cluster template %q already exists", e.name)
+}
+
+func (e *templateExistsError) Conflict() bool {
+	return true
+}
+
+type templateInUseError struct {
+	name     string
+	clusters int
+}
+
+func (e *templateInUseError) Error() string {
+	return fmt.Sprintf("cluster template %q is used by %d cluster(s)", e.name, e.clusters)
+}
+
+func (e *templateInUseError) Conflict() bool {
+	return true
+}
+
+// Instance is a cluster template version rendered for a new cluster.
+type Instance struct {
+	Template   *TemplateModel
+	Version    int
+	Parameters map[string]string
+
+	Request   *pkgCluster.CreateClusterRequest
+	Resources pkgCluster.ClusterTemplateResources
+}
+
+// Service manages the cluster templates of the organizations.
+type Service struct {
+	repository *Repository
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(repository *Repository, logger logrus.FieldLogger, errorHandler emperror.Handler) *Service {
+	return &Service{
+		repository: repository,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// ListTemplates returns the cluster templates of an organization.
+func (s *Service) ListTemplates(organizationID uint) ([]*TemplateModel, error) {
+	return s.repository.FindTemplates(organizationID)
+}
+
+// GetTemplate returns a cluster template of an organization.
+func (s *Service) GetTemplate(organizationID uint, name string) (*TemplateModel, error) {
+	return s.repository.FindOneTemplate(organizationID, name)
+}
+
+// CreateTemplate creates a cluster template with its first version.
+func (s *Service) CreateTemplate(organizationID uint, req *pkgCluster.ClusterTemplateRequest, userID uint) (*TemplateModel, error) {
+	if !templateNameRegexp.MatchString(req.Name) {
+		return nil, errors.WithStack(&invalidTemplateNameError{name: req.Name})
+	}
+
+	if err := validateSpec(req.Spec); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	_, err := s.repository.FindOneTemplate(organizationID, req.Name)
+	if err == nil {
+		return nil, errors.WithStack(&templateExistsError{name: req.Name})
+	} else if !isNotFound(err) {
+		return nil, err
+	}
+
+	template := &TemplateModel{
+		OrganizationID: organizationID,
+		Name:           req.Name,
+		Description:    req.Description,
+		CreatedBy:      userID,
+	}
+
+	version := &TemplateVersionModel{
+		Version:   1,
+		Spec:      req.Spec,
+		CreatedBy: userID,
+	}
+
+	if err := s.repository.SaveVersion(template, version); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{"organization": organizationID, "template": template.Name}).Info("cluster template created")
+
+	return template, nil
+}
+
+// AddVersion adds a new version to a cluster template. Existing versions are never modified.
+func (s *Service) AddVersion(organizationID uint, name string, req *pkgCluster.ClusterTemplateVersionRequest, userID uint) (*TemplateVersionModel, error) {
+	template, err := s.repository.FindOneTemplate(organizationID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSpec(req.Spec); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+
+	version := &TemplateVersionModel{
+		Version:   template.LatestVersion + 1,
+		Spec:      req.Spec,
+		CreatedBy: userID,
+	}
+
+	if err := s.repository.SaveVersion(template, version); err != nil {
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"organization": organizationID,
+		"template":     template.Name,
+		"version":      version.Version,
+	}).Info("cluster template version created")
+
+	return version, nil
+}
+
+// DeleteTemplate deletes a cluster template unless there are clusters created from it.
+func (s *Service) DeleteTemplate(organizationID uint, name string) error {
+	template, err := s.repository.FindOneTemplate(organizationID, name)
+	if err != nil {
+		return err
+	}
+
+	count, err := s.repository.CountBindings(template.ID)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return errors.WithStack(&templateInUseError{name: name, clusters: count})
+	}
+
+	return s.repository.DeleteTemplate(template)
+}
+
+// ListVersions returns the versions of a cluster template.
+func (s *Service) ListVersions(organizationID uint, name string) ([]*TemplateVersionModel, error) {
+	template, err := s.repository.FindOneTemplate(organizationID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repository.FindVersions(template.ID)
+}
+
+// GetVersion returns a version of a cluster template. Version 0 means the latest version.
+func (s *Service) GetVersion(organizationID uint, name string, version int) (*TemplateVersionModel, error) {
+	template, err := s.repository.FindOneTemplate(organizationID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		version = template.LatestVersion
+	}
+
+	return s.repository.FindOneVersion(template.ID, version)
+}
+
+// Instantiate renders a cluster template version into a cluster create request and the resources
+// installed into the cluster after it is created.
+func (s *Service) Instantiate(organizationID uint, name string, req *pkgCluster.CreateClusterFromTemplateRequest) (*Instance, error) {
+	template, err := s.repository.FindOneTemplate(organizationID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	version := req.Version
+	if version == 0 {
+		version = template.LatestVersion
+	}
+
+	templateVersion, err := s.repository.FindOneVersion(template.ID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, parameters, err := renderSpec(templateVersion.Spec, req.Name, req.Parameters)
+	if err != nil {
+		return nil, emperror.With(errors.WithStack(err), "template", template.Name, "version", version)
+	}
+
+	request := spec.Cluster
+	request.Name = req.Name
+
+	return &Instance{
+		Template:   template,
+		Version:    version,
+		Parameters: parameters,
+		Request:    &request,
+		Resources: pkgCluster.ClusterTemplateResources{
+			Deployments: spec.Deployments,
+			Secrets:     spec.Secrets,
+		},
+	}, nil
+}
+
+// BindCluster records the cluster template version a cluster was created from.
+func (s *Service) BindCluster(organizationID uint, clusterID uint, instance *Instance) error {
+	binding := &BindingModel{
+		ClusterID:      clusterID,
+		OrganizationID: organizationID,
+		TemplateID:     instance.Template.ID,
+		Version:        instance.Version,
+	}
+	binding.SetParameters(instance.Parameters)
+
+	return s.repository.SaveBinding(binding)
+}
+
+// GetBinding returns the cluster template version a cluster was created from.
+func (s *Service) GetBinding(organizationID uint, clusterID uint) (*pkgCluster.ClusterTemplateBindingResponse, error) {
+	binding, _, err := s.getBinding(organizationID, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return binding, nil
+}
+
+func (s *Service) getBinding(organizationID uint, clusterID uint) (*pkgCluster.ClusterTemplateBindingResponse, *TemplateVersionModel, error) {
+	binding, err := s.repository.FindBinding(clusterID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if binding.OrganizationID != organizationID {
+		return nil, nil, errors.WithStack(&bindingNotFoundError{clusterID: clusterID})
+	}
+
+	template, err := s.repository.FindTemplateByID(binding.TemplateID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	version, err := s.repository.FindOneVersion(template.ID, binding.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &pkgCluster.ClusterTemplateBindingResponse{
+		Template:      template.Name,
+		Version:       binding.Version,
+		LatestVersion: template.LatestVersion,
+		Parameters:    binding.GetParameters(),
+		CreatedAt:     binding.CreatedAt,
+	}, version, nil
+}
+
+// GetDrift compares a cluster with the cluster template version it was created from.
+func (s *Service) GetDrift(organizationID uint, clusterID uint, clusterName string, inspector ClusterInspector) (*pkgCluster.ClusterTemplateDriftResponse, error) {
+	binding, version, err := s.getBinding(organizationID, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, _, err := renderSpec(version.Spec, clusterName, binding.Parameters)
+	if err != nil {
+		return nil, emperror.With(err, "template", binding.Template, "version", binding.Version)
+	}
+
+	drifts, err := detectDrift(spec, inspector)
+	if err != nil {
+		return nil, emperror.With(errors.WithMessage(err, "failed to inspect cluster"), "cluster", clusterID)
+	}
+
+	return &pkgCluster.ClusterTemplateDriftResponse{
+		ClusterTemplateBindingResponse: *binding,
+		InSync:                         len(drifts) == 0,
+		Drifts:                         drifts,
+	}, nil
+}
+
+// DeleteBinding deletes the cluster template binding of a deleted cluster.
+func (s *Service) DeleteBinding(clusterID uint) error {
+	return s.repository.DeleteBinding(clusterID)
+}
+
+func isNotFound(err error) bool {
+	e, ok := errors.Cause(err).(interface{ NotFound() bool })
+
+	return ok && e.NotFound()
+}
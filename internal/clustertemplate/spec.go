@@ -0,0 +1,258 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/ghodss/yaml"
+)
+
+// defaultNamespace is the namespace of the template deployments and secrets without an explicit one
+const defaultNamespace = "default"
+
+var (
+	templateNameRegexp  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	parameterNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+type invalidSpecError struct {
+	reason string
+}
+
+func (e *invalidSpecError) Error() string {
+	return fmt.Sprintf("invalid cluster template: %s", e.reason)
+}
+
+func (e *invalidSpecError) IsInvalid() bool {
+	return true
+}
+
+type invalidParametersError struct {
+	reason string
+}
+
+func (e *invalidParametersError) Error() string {
+	return fmt.Sprintf("invalid template parameters: %s", e.reason)
+}
+
+func (e *invalidParametersError) IsInvalid() bool {
+	return true
+}
+
+// specData is passed to the spec template when it is rendered.
+type specData struct {
+	ClusterName string
+	Params      map[string]string
+}
+
+// parseParameters returns the parameters declared by a spec.
+// The spec is rendered without parameters first, so the parameter declarations must not depend on them.
+func parseParameters(spec string) ([]pkgCluster.ClusterTemplateParameter, error) {
+	rendered, err := renderSpecTemplate(spec, specData{Params: map[string]string{}}, "missingkey=zero")
+	if err != nil {
+		return nil, err
+	}
+
+	var declarations struct {
+		Parameters []pkgCluster.ClusterTemplateParameter `json:"parameters"`
+	}
+	if err := yaml.Unmarshal(rendered, &declarations); err != nil {
+		return nil, &invalidSpecError{reason: err.Error()}
+	}
+
+	seen := make(map[string]bool)
+	for _, parameter := range declarations.Parameters {
+		if !parameterNameRegexp.MatchString(parameter.Name) {
+			return nil, &invalidSpecError{reason: fmt.Sprintf("invalid parameter name %q", parameter.Name)}
+		}
+
+		if seen[parameter.Name] {
+			return nil, &invalidSpecError{reason: fmt.Sprintf("duplicate parameter %q", parameter.Name)}
+		}
+		seen[parameter.Name] = true
+	}
+
+	return declarations.Parameters, nil
+}
+
+// resolveParameters checks the given parameters against the declared ones and fills the default values.
+func resolveParameters(declared []pkgCluster.ClusterTemplateParameter, given map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(declared))
+
+	known := make(map[string]bool, len(declared))
+	for _, parameter := range declared {
+		known[parameter.Name] = true
+	}
+
+	var unknown []string
+	for name := range given {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, &invalidParametersError{reason: "unknown parameters: " + strings.Join(unknown, ", ")}
+	}
+
+	var missing []string
+	for _, parameter := range declared {
+		value, ok := given[parameter.Name]
+		if !ok {
+			value = parameter.Default
+		}
+
+		if value == "" && parameter.Required {
+			missing = append(missing, parameter.Name)
+			continue
+		}
+
+		resolved[parameter.Name] = value
+	}
+	if len(missing) > 0 {
+		return nil, &invalidParametersError{reason: "missing required parameters: " + strings.Join(missing, ", ")}
+	}
+
+	return resolved, nil
+}
+
+// renderSpec renders a spec with the given parameters and parses the result.
+func renderSpec(spec string, clusterName string, parameters map[string]string) (*pkgCluster.ClusterTemplateSpec, map[string]string, error) {
+	declared, err := parseParameters(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolved, err := resolveParameters(declared, parameters)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rendered, err := renderSpecTemplate(spec, specData{ClusterName: clusterName, Params: resolved}, "missingkey=error")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var templateSpec pkgCluster.ClusterTemplateSpec
+	if err := yaml.Unmarshal(rendered, &templateSpec); err != nil {
+		return nil, nil, &invalidSpecError{reason: err.Error()}
+	}
+
+	if err := normalizeSpec(&templateSpec); err != nil {
+		return nil, nil, err
+	}
+
+	return &templateSpec, resolved, nil
+}
+
+// validateSpec checks that a spec can be rendered with the default parameter values.
+// Required parameters without a default value are rendered empty (null in YAML),
+// in which case the mandatory fields are not checked as they may depend on them.
+func validateSpec(spec string) error {
+	declared, err := parseParameters(spec)
+	if err != nil {
+		return err
+	}
+
+	parameters := make(map[string]string)
+	placeholders := false
+	for _, parameter := range declared {
+		parameters[parameter.Name] = parameter.Default
+		if parameter.Required && parameter.Default == "" {
+			placeholders = true
+		}
+	}
+
+	rendered, err := renderSpecTemplate(spec, specData{ClusterName: "cluster", Params: parameters}, "missingkey=error")
+	if err != nil {
+		return err
+	}
+
+	var templateSpec pkgCluster.ClusterTemplateSpec
+	if err := yaml.Unmarshal(rendered, &templateSpec); err != nil {
+		return &invalidSpecError{reason: err.Error()}
+	}
+
+	if placeholders {
+		return nil
+	}
+
+	return normalizeSpec(&templateSpec)
+}
+
+func renderSpecTemplate(spec string, data specData, missingKeyOption string) ([]byte, error) {
+	tmpl, err := template.New("spec").Funcs(pkgHelm.TemplateFuncMap()).Option(missingKeyOption).Parse(spec)
+	if err != nil {
+		return nil, &invalidSpecError{reason: err.Error()}
+	}
+
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, data); err != nil {
+		return nil, &invalidSpecError{reason: err.Error()}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// normalizeSpec checks the mandatory fields of a rendered spec and fills the default values.
+func normalizeSpec(spec *pkgCluster.ClusterTemplateSpec) error {
+	if spec.Cluster.Cloud == "" {
+		return &invalidSpecError{reason: "cluster.cloud is required"}
+	}
+
+	if spec.Cluster.Properties == nil {
+		return &invalidSpecError{reason: "cluster.properties is required"}
+	}
+
+	releases := make(map[string]bool)
+	for i := range spec.Deployments {
+		deployment := &spec.Deployments[i]
+
+		if deployment.Name == "" || deployment.ReleaseName == "" {
+			return &invalidSpecError{reason: "deployments require a chart name and a release name"}
+		}
+
+		if releases[deployment.ReleaseName] {
+			return &invalidSpecError{reason: fmt.Sprintf("duplicate release name %q", deployment.ReleaseName)}
+		}
+		releases[deployment.ReleaseName] = true
+
+		if deployment.Namespace == "" {
+			deployment.Namespace = defaultNamespace
+		}
+	}
+
+	for i := range spec.Secrets {
+		secret := &spec.Secrets[i]
+
+		if secret.Name == "" {
+			return &invalidSpecError{reason: "secrets require a name"}
+		}
+
+		if secret.Namespace == "" {
+			secret.Namespace = defaultNamespace
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertemplate
+
+import (
+	"testing"
+)
+
+const testSpec = `
+parameters:
+  - name: region
+    default: eu-west-1
+  - name: nodes
+    required: true
+cluster:
+  cloud: amazon
+  location: {{ .Params.region }}
+  secretName: aws
+  properties:
+    eks:
+      version: "1.10"
+      nodePools:
+        pool1:
+          instanceType: m4.xlarge
+          count: {{ .Params.nodes }}
+deployments:
+  - name: stable/nginx-ingress
+    releaseName: {{ .ClusterName }}-ingress
+    values:
+      controller:
+        replicaCount: 2
+secrets:
+  - name: registry
+`
+
+func TestRenderSpec(t *testing.T) {
+	spec, parameters, err := renderSpec(testSpec, "test", map[string]string{"nodes": "3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if spec.Cluster.Location != "eu-west-1" || parameters["region"] != "eu-west-1" {
+		t.Errorf("default parameter not applied: %q", spec.Cluster.Location)
+	}
+
+	if counts := spec.Cluster.NodePoolCounts(); counts["pool1"] != 3 {
+		t.Errorf("unexpected node pool counts: %v", counts)
+	}
+
+	if spec.Deployments[0].ReleaseName != "test-ingress" || spec.Deployments[0].Namespace != defaultNamespace {
+		t.Errorf("unexpected deployment: %+v", spec.Deployments[0])
+	}
+
+	if spec.Secrets[0].Namespace != defaultNamespace {
+		t.Errorf("unexpected secret namespace: %q", spec.Secrets[0].Namespace)
+	}
+}
+
+func TestRenderSpec_InvalidParameters(t *testing.T) {
+	if _, _, err := renderSpec(testSpec, "test", nil); err == nil {
+		t.Error("expected error for missing required parameter")
+	}
+
+	if _, _, err := renderSpec(testSpec, "test", map[string]string{"nodes": "1", "zone": "a"}); err == nil {
+		t.Error("expected error for unknown parameter")
+	}
+}
+
+func TestValidateSpec(t *testing.T) {
+	if err := validateSpec(testSpec); err != nil {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+
+	if err := validateSpec("cluster:\n  cloud: amazon\n"); err == nil {
+		t.Error("expected error for missing cluster properties")
+	}
+
+	if err := validateSpec("cluster: {{ .Params.unknown }"); err == nil {
+		t.Error("expected error for invalid template")
+	}
+
+	if err := validateSpec(`cluster: {{ env "HOME" }}`); err == nil {
+		t.Error("expected error for the env function")
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package desiredstate
+
+import (
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/banzaicloud/pipeline/helm"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	k8sHelm "k8s.io/helm/pkg/helm"
+	helm_env "k8s.io/helm/pkg/helm/environment"
+)
+
+// releaseClient manages the releases of a cluster.
+type releaseClient interface {
+	ListReleases() (map[string]ActualRelease, error)
+	InstallRelease(release *ReleaseModel) error
+	UpgradeRelease(release *ReleaseModel) error
+	DeleteRelease(releaseName string) error
+}
+
+// helmReleaseClient manages the releases of a cluster through Tiller.
+type helmReleaseClient struct {
+	kubeConfig []byte
+	env        helm_env.EnvSettings
+}
+
+func newHelmReleaseClient(commonCluster cluster.CommonCluster) (releaseClient, error) {
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get k8s config")
+	}
+
+	organization, err := auth.GetOrganizationById(commonCluster.GetOrganizationId())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get organization")
+	}
+
+	return &helmReleaseClient{
+		kubeConfig: kubeConfig,
+		env:        helm.GenerateHelmRepoEnv(organization.Name),
+	}, nil
+}
+
+func (c *helmReleaseClient) ListReleases() (map[string]ActualRelease, error) {
+	response, err := helm.ListDeployments(nil, "", c.kubeConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list releases")
+	}
+
+	releases := make(map[string]ActualRelease)
+	if response == nil {
+		return releases, nil
+	}
+
+	for _, release := range response.Releases {
+		var values map[string]interface{}
+
+		if raw := release.GetConfig().GetRaw(); raw != "" {
+			if err := yaml.Unmarshal([]byte(raw), &values); err != nil {
+				return nil, emperror.With(errors.Wrap(err, "could not parse release values"), "release", release.GetName())
+			}
+		}
+
+		releases[release.GetName()] = ActualRelease{
+			Name:         release.GetName(),
+			ChartName:    release.GetChart().GetMetadata().GetName(),
+			ChartVersion: release.GetChart().GetMetadata().GetVersion(),
+			Namespace:    release.GetNamespace(),
+			Status:       release.GetInfo().GetStatus().GetCode().String(),
+			Values:       values,
+		}
+	}
+
+	return releases, nil
+}
+
+func (c *helmReleaseClient) InstallRelease(release *ReleaseModel) error {
+	values, err := marshalValues(release)
+	if err != nil {
+		return err
+	}
+
+	options := []k8sHelm.InstallOption{
+		k8sHelm.ValueOverrides(values),
+	}
+
+	_, err = helm.CreateDeployment(
+		release.ChartName,
+		release.ChartVersion,
+		nil,
+		release.Namespace,
+		release.ReleaseName,
+		false,
+		nil,
+		c.kubeConfig,
+		c.env,
+		options...,
+	)
+
+	return errors.Wrap(err, "could not install release")
+}
+
+func (c *helmReleaseClient) UpgradeRelease(release *ReleaseModel) error {
+	values, err := marshalValues(release)
+	if err != nil {
+		return err
+	}
+
+	_, err = helm.UpgradeDeployment(
+		release.ReleaseName,
+		release.ChartName,
+		release.ChartVersion,
+		nil,
+		values,
+		false,
+		c.kubeConfig,
+		c.env,
+	)
+
+	return errors.Wrap(err, "could not upgrade release")
+}
+
+func (c *helmReleaseClient) DeleteRelease(releaseName string) error {
+	return errors.Wrap(helm.DeleteDeployment(releaseName, c.kubeConfig), "could not delete release")
+}
+
+func marshalValues(release *ReleaseModel) ([]byte, error) {
+	values := release.GetValues()
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	valuesYAML, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal release values")
+	}
+
+	return valuesYAML, nil
+}
@@ -0,0 +1,123 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package desiredstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	releasesTableName = "desired_releases"
+)
+
+// ReleaseModel describes a release a cluster should run and the result of its last reconciliation.
+// Releases removed from the desired state are kept as absent until they are deleted from the cluster.
+type ReleaseModel struct {
+	ID          uint   `gorm:"primary_key"`
+	ClusterID   uint   `gorm:"unique_index:idx_desired_release_cluster_release"`
+	ReleaseName string `gorm:"unique_index:idx_desired_release_cluster_release"`
+
+	ChartName    string
+	ChartVersion string
+	Namespace    string
+	Values       string `sql:"type:text;"`
+	Absent       bool
+
+	Status           string
+	LastAction       string
+	LastError        string `sql:"type:text;"`
+	LastReconciledAt *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UpdatedBy uint
+}
+
+// TableName changes the default table name.
+func (ReleaseModel) TableName() string {
+	return releasesTableName
+}
+
+// GetValues returns the values the release should be installed with.
+func (m *ReleaseModel) GetValues() map[string]interface{} {
+	var values map[string]interface{}
+
+	if m.Values != "" {
+		_ = json.Unmarshal([]byte(m.Values), &values)
+	}
+
+	return values
+}
+
+// SetValues sets the values the release should be installed with.
+func (m *ReleaseModel) SetValues(values map[string]interface{}) error {
+	if len(values) == 0 {
+		m.Values = ""
+		return nil
+	}
+
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	m.Values = string(valuesJSON)
+
+	return nil
+}
+
+// ConvertModelToEntity converts a ReleaseModel to a pkgHelm.DesiredReleaseStatus.
+func (m *ReleaseModel) ConvertModelToEntity() pkgHelm.DesiredReleaseStatus {
+	return pkgHelm.DesiredReleaseStatus{
+		DesiredRelease: pkgHelm.DesiredRelease{
+			Name:        m.ChartName,
+			Version:     m.ChartVersion,
+			ReleaseName: m.ReleaseName,
+			Namespace:   m.Namespace,
+			Values:      m.GetValues(),
+		},
+		Status:           m.Status,
+		LastAction:       m.LastAction,
+		LastError:        m.LastError,
+		LastReconciledAt: m.LastReconciledAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+}
+
+// Migrate executes the table migrations for the desired state models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&ReleaseModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating desired state tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,154 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package desiredstate
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+)
+
+// Helm release status codes relevant for the reconciliation
+const (
+	releaseStatusFailed          = "FAILED"
+	releaseStatusDeleting        = "DELETING"
+	releaseStatusPendingInstall  = "PENDING_INSTALL"
+	releaseStatusPendingUpgrade  = "PENDING_UPGRADE"
+	releaseStatusPendingRollback = "PENDING_ROLLBACK"
+)
+
+// ActualRelease describes a release running in a cluster.
+type ActualRelease struct {
+	Name         string
+	ChartName    string
+	ChartVersion string
+	Namespace    string
+	Status       string
+	Values       map[string]interface{}
+}
+
+// inProgress tells whether an operation is being executed on the release.
+func (r ActualRelease) inProgress() bool {
+	switch r.Status {
+	case releaseStatusDeleting, releaseStatusPendingInstall, releaseStatusPendingUpgrade, releaseStatusPendingRollback:
+		return true
+	}
+
+	return false
+}
+
+// Change describes an action converging a release to its desired state.
+type Change struct {
+	Action  string
+	Release *ReleaseModel
+	Reason  string
+}
+
+// ConvertToEntity converts a Change to a pkgHelm.ReleaseChange.
+func (c Change) ConvertToEntity() pkgHelm.ReleaseChange {
+	return pkgHelm.ReleaseChange{
+		ReleaseName: c.Release.ReleaseName,
+		Action:      c.Action,
+		Reason:      c.Reason,
+	}
+}
+
+// planChanges returns the actions converging the actual releases to the desired ones.
+// Releases not managed through the desired state and releases with an operation in progress are left alone.
+func planChanges(desired []*ReleaseModel, actual map[string]ActualRelease) []Change {
+	var changes []Change
+
+	for _, release := range desired {
+		current, installed := actual[release.ReleaseName]
+
+		if installed && current.inProgress() {
+			continue
+		}
+
+		if release.Absent {
+			if installed {
+				changes = append(changes, Change{
+					Action:  pkgHelm.ReconcileDelete,
+					Release: release,
+					Reason:  "release is not desired anymore",
+				})
+			}
+
+			continue
+		}
+
+		if !installed {
+			changes = append(changes, Change{
+				Action:  pkgHelm.ReconcileInstall,
+				Release: release,
+				Reason:  "release is not installed",
+			})
+
+			continue
+		}
+
+		if reason := diffRelease(release, current); reason != "" {
+			action := pkgHelm.ReconcileUpgrade
+
+			// Releases cannot be moved between namespaces
+			if current.Namespace != release.Namespace {
+				action = pkgHelm.ReconcileInstall
+			}
+
+			changes = append(changes, Change{
+				Action:  action,
+				Release: release,
+				Reason:  reason,
+			})
+		}
+	}
+
+	return changes
+}
+
+// diffRelease describes the first difference between a desired and an installed release.
+func diffRelease(release *ReleaseModel, current ActualRelease) string {
+	if current.Namespace != release.Namespace {
+		return fmt.Sprintf("release is installed in namespace %q instead of %q", current.Namespace, release.Namespace)
+	}
+
+	if chartName := path.Base(release.ChartName); current.ChartName != chartName {
+		return fmt.Sprintf("release runs chart %q instead of %q", current.ChartName, chartName)
+	}
+
+	if release.ChartVersion != "" && current.ChartVersion != release.ChartVersion {
+		return fmt.Sprintf("release runs chart version %q instead of %q", current.ChartVersion, release.ChartVersion)
+	}
+
+	if current.Status == releaseStatusFailed {
+		return "last release failed"
+	}
+
+	if !equalValues(release.GetValues(), current.Values) {
+		return "release values differ"
+	}
+
+	return ""
+}
+
+func equalValues(desired map[string]interface{}, actual map[string]interface{}) bool {
+	if len(desired) == 0 && len(actual) == 0 {
+		return true
+	}
+
+	return reflect.DeepEqual(desired, actual)
+}
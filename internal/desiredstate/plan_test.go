@@ -0,0 +1,147 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package desiredstate
+
+import (
+	"testing"
+
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+)
+
+func newRelease(releaseName string, chartName string, chartVersion string, values map[string]interface{}) *ReleaseModel {
+	release := &ReleaseModel{
+		ReleaseName:  releaseName,
+		ChartName:    chartName,
+		ChartVersion: chartVersion,
+		Namespace:    "default",
+	}
+	_ = release.SetValues(values)
+
+	return release
+}
+
+func TestPlanChanges(t *testing.T) {
+	removed := newRelease("removed", "stable/redis", "", nil)
+	removed.Absent = true
+
+	gone := newRelease("gone", "stable/redis", "", nil)
+	gone.Absent = true
+
+	desired := []*ReleaseModel{
+		newRelease("missing", "stable/nginx-ingress", "0.28.2", nil),
+		newRelease("synced", "stable/nginx-ingress", "", map[string]interface{}{"replicaCount": 2}),
+		newRelease("outdated", "stable/nginx-ingress", "0.28.2", nil),
+		newRelease("changed", "stable/mysql", "", map[string]interface{}{"persistence": map[string]interface{}{"enabled": true}}),
+		newRelease("failed", "stable/mysql", "", nil),
+		newRelease("upgrading", "stable/mysql", "0.11.0", nil),
+		newRelease("moved", "stable/mysql", "", nil),
+		removed,
+		gone,
+	}
+
+	actual := map[string]ActualRelease{
+		"synced": {
+			ChartName:    "nginx-ingress",
+			ChartVersion: "0.28.2",
+			Namespace:    "default",
+			Status:       "DEPLOYED",
+			Values:       map[string]interface{}{"replicaCount": float64(2)},
+		},
+		"outdated": {ChartName: "nginx-ingress", ChartVersion: "0.28.0", Namespace: "default", Status: "DEPLOYED"},
+		"changed": {
+			ChartName: "mysql",
+			Namespace: "default",
+			Status:    "DEPLOYED",
+			Values:    map[string]interface{}{"persistence": map[string]interface{}{"enabled": false}},
+		},
+		"failed":    {ChartName: "mysql", Namespace: "default", Status: "FAILED"},
+		"upgrading": {ChartName: "mysql", ChartVersion: "0.10.0", Namespace: "default", Status: "PENDING_UPGRADE"},
+		"moved":     {ChartName: "mysql", Namespace: "kube-system", Status: "DEPLOYED"},
+		"removed":   {ChartName: "redis", Namespace: "default", Status: "DEPLOYED"},
+		"unmanaged": {ChartName: "redis", Namespace: "default", Status: "DEPLOYED"},
+	}
+
+	expected := map[string]string{
+		"missing":  pkgHelm.ReconcileInstall,
+		"outdated": pkgHelm.ReconcileUpgrade,
+		"changed":  pkgHelm.ReconcileUpgrade,
+		"failed":   pkgHelm.ReconcileUpgrade,
+		"moved":    pkgHelm.ReconcileInstall,
+		"removed":  pkgHelm.ReconcileDelete,
+	}
+
+	changes := planChanges(desired, actual)
+
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(expected), len(changes), changes)
+	}
+
+	for _, change := range changes {
+		action, ok := expected[change.Release.ReleaseName]
+		if !ok {
+			t.Errorf("unexpected change of release %q: %s", change.Release.ReleaseName, change.Reason)
+			continue
+		}
+
+		if change.Action != action {
+			t.Errorf("expected %s of release %q, got %s", action, change.Release.ReleaseName, change.Action)
+		}
+
+		if change.Reason == "" {
+			t.Errorf("expected reason for the change of release %q", change.Release.ReleaseName)
+		}
+	}
+}
+
+func TestValidateReleases(t *testing.T) {
+	tests := map[string]struct {
+		releases []pkgHelm.DesiredRelease
+		invalid  bool
+	}{
+		"valid": {
+			releases: []pkgHelm.DesiredRelease{
+				{Name: "stable/nginx-ingress", ReleaseName: "ingress"},
+				{Name: "stable/mysql", ReleaseName: "db"},
+			},
+		},
+		"missing release name": {
+			releases: []pkgHelm.DesiredRelease{{Name: "stable/mysql"}},
+			invalid:  true,
+		},
+		"missing chart name": {
+			releases: []pkgHelm.DesiredRelease{{ReleaseName: "db"}},
+			invalid:  true,
+		},
+		"duplicate release name": {
+			releases: []pkgHelm.DesiredRelease{
+				{Name: "stable/mysql", ReleaseName: "db"},
+				{Name: "stable/postgresql", ReleaseName: "db"},
+			},
+			invalid: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateReleases(test.releases)
+
+			if test.invalid && err == nil {
+				t.Fatal("expected error")
+			} else if !test.invalid && err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+		})
+	}
+}
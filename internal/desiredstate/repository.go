@@ -0,0 +1,114 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package desiredstate
+
+import (
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the desired releases of the clusters.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindReleases returns the desired releases of a cluster, including the absent ones.
+func (r *Repository) FindReleases(clusterID uint) ([]*ReleaseModel, error) {
+	var releases []*ReleaseModel
+
+	err := r.db.Where("cluster_id = ?", clusterID).Order("release_name").Find(&releases).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch desired releases"), "cluster", clusterID)
+	}
+
+	return releases, nil
+}
+
+// FindClusterIDs returns the IDs of the clusters having desired releases.
+func (r *Repository) FindClusterIDs() ([]uint, error) {
+	var clusterIDs []uint
+
+	err := r.db.Model(&ReleaseModel{}).Order("cluster_id").Pluck("DISTINCT cluster_id", &clusterIDs).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch clusters with desired releases")
+	}
+
+	return clusterIDs, nil
+}
+
+// SaveReleases persists the desired releases of a cluster in a single transaction.
+func (r *Repository) SaveReleases(releases []*ReleaseModel) error {
+	tx := r.db.Begin()
+	if err := tx.Error; err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	for _, release := range releases {
+		if err := tx.Save(release).Error; err != nil {
+			tx.Rollback()
+			return emperror.With(
+				errors.Wrap(err, "could not save desired release"),
+				"cluster", release.ClusterID,
+				"release", release.ReleaseName,
+			)
+		}
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit transaction")
+}
+
+// SaveRelease persists a desired release.
+func (r *Repository) SaveRelease(release *ReleaseModel) error {
+	err := r.db.Save(release).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save desired release"),
+			"cluster", release.ClusterID,
+			"release", release.ReleaseName,
+		)
+	}
+
+	return nil
+}
+
+// DeleteRelease deletes a desired release.
+func (r *Repository) DeleteRelease(release *ReleaseModel) error {
+	err := r.db.Delete(release).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not delete desired release"),
+			"cluster", release.ClusterID,
+			"release", release.ReleaseName,
+		)
+	}
+
+	return nil
+}
+
+// DeleteReleases deletes the desired releases of a cluster.
+func (r *Repository) DeleteReleases(clusterID uint) error {
+	err := r.db.Where("cluster_id = ?", clusterID).Delete(&ReleaseModel{}).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete desired releases"), "cluster", clusterID)
+	}
+
+	return nil
+}
@@ -0,0 +1,402 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package desiredstate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultNamespace = "default"
+
+type invalidReleasesError struct {
+	message string
+}
+
+func (e *invalidReleasesError) Error() string {
+	return e.message
+}
+
+func (e *invalidReleasesError) IsInvalid() bool {
+	return true
+}
+
+type clusterManager interface {
+	GetClusterByIDOnly(ctx context.Context, clusterID uint) (cluster.CommonCluster, error)
+}
+
+// Service converges the releases of the clusters to their desired state.
+type Service struct {
+	repository       *Repository
+	clusters         clusterManager
+	newReleaseClient func(commonCluster cluster.CommonCluster) (releaseClient, error)
+	logger           logrus.FieldLogger
+	errorHandler     emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(
+	repository *Repository,
+	clusters clusterManager,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Service {
+	return &Service{
+		repository:       repository,
+		clusters:         clusters,
+		newReleaseClient: newHelmReleaseClient,
+		logger:           logger,
+		errorHandler:     errorHandler,
+	}
+}
+
+// GetReleases returns the desired releases of a cluster with their reconciliation status.
+func (s *Service) GetReleases(clusterID uint) ([]*ReleaseModel, error) {
+	return s.repository.FindReleases(clusterID)
+}
+
+// SetReleases replaces the desired releases of a cluster.
+// Releases missing from the request are deleted from the cluster by the next reconciliation.
+func (s *Service) SetReleases(clusterID uint, req *pkgHelm.DesiredDeploymentsRequest, userID uint) ([]*ReleaseModel, error) {
+	if err := validateReleases(req.Releases); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repository.FindReleases(clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	existingByName := make(map[string]*ReleaseModel, len(existing))
+	for _, release := range existing {
+		existingByName[release.ReleaseName] = release
+	}
+
+	var releases []*ReleaseModel
+
+	for _, desired := range req.Releases {
+		release, ok := existingByName[desired.ReleaseName]
+		if !ok {
+			release = &ReleaseModel{
+				ClusterID:   clusterID,
+				ReleaseName: desired.ReleaseName,
+			}
+		}
+		delete(existingByName, desired.ReleaseName)
+
+		namespace := desired.Namespace
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+
+		previous := *release
+
+		release.ChartName = desired.Name
+		release.ChartVersion = desired.Version
+		release.Namespace = namespace
+		release.Absent = false
+		release.UpdatedBy = userID
+
+		if err := release.SetValues(desired.Values); err != nil {
+			return nil, emperror.With(errors.Wrap(err, "could not marshal release values"), "release", desired.ReleaseName)
+		}
+
+		if !ok || previous.ChartName != release.ChartName || previous.ChartVersion != release.ChartVersion ||
+			previous.Namespace != release.Namespace || previous.Values != release.Values || previous.Absent {
+			release.Status = pkgHelm.DesiredReleasePending
+		}
+
+		releases = append(releases, release)
+	}
+
+	for _, release := range existingByName {
+		if release.Absent {
+			continue
+		}
+
+		release.Absent = true
+		release.Status = pkgHelm.DesiredReleaseDeleting
+		release.UpdatedBy = userID
+
+		releases = append(releases, release)
+	}
+
+	if err := s.repository.SaveReleases(releases); err != nil {
+		return nil, err
+	}
+
+	return s.repository.FindReleases(clusterID)
+}
+
+func validateReleases(releases []pkgHelm.DesiredRelease) error {
+	releaseNames := make(map[string]bool, len(releases))
+
+	for i, release := range releases {
+		if release.ReleaseName == "" {
+			return errors.WithStack(&invalidReleasesError{fmt.Sprintf("releases[%d]: release name is required", i)})
+		}
+
+		if release.Name == "" {
+			return errors.WithStack(&invalidReleasesError{fmt.Sprintf("releases[%d]: chart name is required", i)})
+		}
+
+		if releaseNames[release.ReleaseName] {
+			return errors.WithStack(&invalidReleasesError{fmt.Sprintf("release %q is listed more than once", release.ReleaseName)})
+		}
+
+		releaseNames[release.ReleaseName] = true
+	}
+
+	return nil
+}
+
+// Diff returns the changes the next reconciliation of a cluster would execute.
+func (s *Service) Diff(ctx context.Context, commonCluster cluster.CommonCluster) (*pkgHelm.DesiredDeploymentsDiffResponse, error) {
+	releases, err := s.repository.FindReleases(commonCluster.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pkgHelm.DesiredDeploymentsDiffResponse{
+		Changes: []pkgHelm.ReleaseChange{},
+	}
+
+	if len(releases) > 0 {
+		client, err := s.newReleaseClient(commonCluster)
+		if err != nil {
+			return nil, err
+		}
+
+		actual, err := client.ListReleases()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, change := range planChanges(releases, actual) {
+			response.Changes = append(response.Changes, change.ConvertToEntity())
+		}
+	}
+
+	response.InSync = len(response.Changes) == 0
+
+	return response, nil
+}
+
+// Reconcile converges the releases of a cluster to their desired state.
+// Failed actions are recorded in the status of the releases and returned without error.
+func (s *Service) Reconcile(ctx context.Context, commonCluster cluster.CommonCluster) ([]*ReleaseModel, error) {
+	logger := s.logger.WithField("cluster", commonCluster.GetID())
+
+	releases, err := s.repository.FindReleases(commonCluster.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(releases) == 0 {
+		return releases, nil
+	}
+
+	client, err := s.newReleaseClient(commonCluster)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := client.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for _, change := range planChanges(releases, actual) {
+		changes[change.Release.ReleaseName] = change
+	}
+
+	var result []*ReleaseModel
+
+	for _, release := range releases {
+		now := time.Now()
+		release.LastReconciledAt = &now
+
+		change, ok := changes[release.ReleaseName]
+		if !ok {
+			if current, installed := actual[release.ReleaseName]; installed && current.inProgress() {
+				result = append(result, release)
+				continue
+			}
+
+			if release.Absent {
+				if err := s.repository.DeleteRelease(release); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			release.Status = pkgHelm.DesiredReleaseSynced
+			release.LastError = ""
+		} else {
+			logger.WithFields(logrus.Fields{
+				"release": release.ReleaseName,
+				"action":  change.Action,
+				"reason":  change.Reason,
+			}).Info("reconciling release")
+
+			release.LastAction = change.Action
+
+			if err := applyChange(client, change, actual); err != nil {
+				logger.WithFields(logrus.Fields{
+					"release": release.ReleaseName,
+					"error":   err.Error(),
+				}).Warn("release reconciliation failed")
+
+				release.Status = pkgHelm.DesiredReleaseFailed
+				release.LastError = err.Error()
+			} else if release.Absent {
+				if err := s.repository.DeleteRelease(release); err != nil {
+					return nil, err
+				}
+
+				continue
+			} else {
+				release.Status = pkgHelm.DesiredReleaseSynced
+				release.LastError = ""
+			}
+		}
+
+		if err := s.repository.SaveRelease(release); err != nil {
+			return nil, err
+		}
+
+		result = append(result, release)
+	}
+
+	return result, nil
+}
+
+func applyChange(client releaseClient, change Change, actual map[string]ActualRelease) error {
+	switch change.Action {
+	case pkgHelm.ReconcileInstall:
+		// Releases installed into another namespace have to be deleted first
+		if _, installed := actual[change.Release.ReleaseName]; installed {
+			if err := client.DeleteRelease(change.Release.ReleaseName); err != nil {
+				return err
+			}
+		}
+
+		return client.InstallRelease(change.Release)
+
+	case pkgHelm.ReconcileUpgrade:
+		return client.UpgradeRelease(change.Release)
+
+	case pkgHelm.ReconcileDelete:
+		return client.DeleteRelease(change.Release.ReleaseName)
+	}
+
+	return errors.Errorf("unknown reconcile action: %s", change.Action)
+}
+
+// ReconcileClusters reconciles the running clusters having desired releases.
+// The desired releases of deleted clusters are dropped.
+func (s *Service) ReconcileClusters(ctx context.Context) error {
+	clusterIDs, err := s.repository.FindClusterIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, clusterID := range clusterIDs {
+		commonCluster, err := s.clusters.GetClusterByIDOnly(ctx, clusterID)
+		if isNotFound(err) {
+			if err := s.repository.DeleteReleases(clusterID); err != nil {
+				s.errorHandler.Handle(err)
+			}
+
+			continue
+		} else if err != nil {
+			s.errorHandler.Handle(emperror.With(err, "cluster", clusterID))
+			continue
+		}
+
+		status, err := commonCluster.GetStatus()
+		if err != nil {
+			s.errorHandler.Handle(emperror.With(err, "cluster", clusterID))
+			continue
+		}
+
+		if status.Status != pkgCluster.Running {
+			continue
+		}
+
+		if _, err := s.Reconcile(ctx, commonCluster); err != nil {
+			s.errorHandler.Handle(emperror.With(
+				errors.Wrap(err, "could not reconcile desired releases"),
+				"cluster", clusterID,
+			))
+		}
+	}
+
+	return nil
+}
+
+// Run reconciles the desired releases of the clusters periodically until the context is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration) {
+	run := func() {
+		s.logger.WithField("interval", interval.String()).Debug("reconciling desired releases")
+
+		if err := s.ReconcileClusters(ctx); err != nil {
+			s.errorHandler.Handle(emperror.Wrap(err, "could not reconcile desired releases"))
+		}
+	}
+
+	run()
+
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			run()
+		case <-ctx.Done():
+			s.logger.Debug("closing ticker")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// ConvertReleasesToEntity converts the desired releases to a pkgHelm.DesiredDeploymentsResponse.
+func ConvertReleasesToEntity(releases []*ReleaseModel) pkgHelm.DesiredDeploymentsResponse {
+	response := pkgHelm.DesiredDeploymentsResponse{
+		Releases: make([]pkgHelm.DesiredReleaseStatus, 0, len(releases)),
+	}
+
+	for _, release := range releases {
+		response.Releases = append(response.Releases, release.ConvertModelToEntity())
+	}
+
+	return response
+}
+
+func isNotFound(err error) bool {
+	notFoundErr, ok := errors.Cause(err).(interface{ NotFound() bool })
+
+	return ok && notFoundErr.NotFound()
+}
@@ -0,0 +1,182 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	customDomainsTableName = "dns_custom_domains"
+	staticRecordsTableName = "dns_static_records"
+)
+
+// delegationTTL is the TTL suggested for the NS records delegating a custom domain
+const delegationTTL = 172800
+
+// CustomDomainModel describes a domain of an organization which is hosted in a zone managed by Pipeline.
+type CustomDomainModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"index"`
+	Domain         string `gorm:"unique_index"`
+
+	Provider    string
+	ZoneID      string
+	NameServers string `sql:"type:text;"`
+	Status      string
+	Message     string `sql:"type:text;"`
+
+	// VerifiedAt is the time of the first successful verification
+	VerifiedAt *time.Time
+	CreatedAt  time.Time
+	CreatedBy  uint
+}
+
+// TableName changes the default table name.
+func (CustomDomainModel) TableName() string {
+	return customDomainsTableName
+}
+
+// GetNameServers returns the name servers of the zone of the custom domain.
+func (m *CustomDomainModel) GetNameServers() []string {
+	if m.NameServers == "" {
+		return []string{}
+	}
+
+	return strings.Split(m.NameServers, ",")
+}
+
+// IsVerified returns true if the custom domain is delegated to the zone managed by Pipeline.
+func (m *CustomDomainModel) IsVerified() bool {
+	return m.Status == pkgDns.DomainVerified
+}
+
+// IsExpired returns true if the custom domain was never verified within the verification period.
+func (m *CustomDomainModel) IsExpired(verificationPeriod time.Duration, now time.Time) bool {
+	return m.Status == pkgDns.DomainPending && m.VerifiedAt == nil && m.CreatedAt.Add(verificationPeriod).Before(now)
+}
+
+// ConvertModelToEntity converts a CustomDomainModel to a pkgDns.CustomDomainResponse.
+func (m *CustomDomainModel) ConvertModelToEntity() pkgDns.CustomDomainResponse {
+	nameServers := m.GetNameServers()
+
+	return pkgDns.CustomDomainResponse{
+		Domain:      m.Domain,
+		Provider:    m.Provider,
+		Status:      m.Status,
+		NameServers: nameServers,
+		Delegation: []pkgDns.Record{
+			{
+				Name:   m.Domain,
+				Type:   pkgDns.RecordTypeNS,
+				TTL:    delegationTTL,
+				Values: nameServers,
+			},
+		},
+		Message:    m.Message,
+		CreatedAt:  m.CreatedAt,
+		VerifiedAt: m.VerifiedAt,
+	}
+}
+
+// StaticRecordModel describes a record set pinned by the users of an organization.
+type StaticRecordModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"unique_index:idx_dns_static_record_org_name_type"`
+	Domain         string `gorm:"index"`
+	Name           string `gorm:"unique_index:idx_dns_static_record_org_name_type"`
+	Type           string `gorm:"unique_index:idx_dns_static_record_org_name_type"`
+
+	TTL    int64
+	Values string `sql:"type:text;"`
+
+	CreatedAt time.Time
+	CreatedBy uint
+}
+
+// TableName changes the default table name.
+func (StaticRecordModel) TableName() string {
+	return staticRecordsTableName
+}
+
+// GetValues returns the values of the record set.
+func (m *StaticRecordModel) GetValues() []string {
+	var values []string
+
+	if err := json.Unmarshal([]byte(m.Values), &values); err != nil {
+		return []string{}
+	}
+
+	return values
+}
+
+// SetValues sets the values of the record set.
+func (m *StaticRecordModel) SetValues(values []string) error {
+	v, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	m.Values = string(v)
+
+	return nil
+}
+
+// GetRecord returns the record set described by the static record.
+func (m *StaticRecordModel) GetRecord() pkgDns.Record {
+	return pkgDns.Record{
+		Name:   m.Name,
+		Type:   m.Type,
+		TTL:    m.TTL,
+		Values: m.GetValues(),
+	}
+}
+
+// ConvertModelToEntity converts a StaticRecordModel to a pkgDns.RecordResponse.
+func (m *StaticRecordModel) ConvertModelToEntity() pkgDns.RecordResponse {
+	return pkgDns.RecordResponse{
+		Record: m.GetRecord(),
+		Domain: m.Domain,
+		ID:     m.ID,
+		Pinned: true,
+	}
+}
+
+// Migrate executes the table migrations for the domain models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&CustomDomainModel{},
+		&StaticRecordModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating domain tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,260 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"time"
+
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the custom domains and static records of the organizations.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+type customDomainNotFoundError struct {
+	organizationID uint
+	domain         string
+}
+
+func (e *customDomainNotFoundError) Error() string {
+	return "custom domain not found"
+}
+
+func (e *customDomainNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"organization", e.organizationID,
+		"domain", e.domain,
+	}
+}
+
+func (e *customDomainNotFoundError) NotFound() bool {
+	return true
+}
+
+type staticRecordNotFoundError struct {
+	organizationID uint
+	id             uint
+}
+
+func (e *staticRecordNotFoundError) Error() string {
+	return "static record not found"
+}
+
+func (e *staticRecordNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"organization", e.organizationID,
+		"record", e.id,
+	}
+}
+
+func (e *staticRecordNotFoundError) NotFound() bool {
+	return true
+}
+
+// FindDomains returns the custom domains of an organization.
+func (r *Repository) FindDomains(organizationID uint) ([]*CustomDomainModel, error) {
+	var domains []*CustomDomainModel
+
+	err := r.db.Where(&CustomDomainModel{OrganizationID: organizationID}).Order("domain").Find(&domains).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch custom domains"),
+			"organization", organizationID,
+		)
+	}
+
+	return domains, nil
+}
+
+// FindVerifiedDomains returns the verified custom domains of an organization.
+func (r *Repository) FindVerifiedDomains(organizationID uint) ([]*CustomDomainModel, error) {
+	var domains []*CustomDomainModel
+
+	err := r.db.Where(&CustomDomainModel{OrganizationID: organizationID, Status: pkgDns.DomainVerified}).Order("domain").Find(&domains).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch verified custom domains"),
+			"organization", organizationID,
+		)
+	}
+
+	return domains, nil
+}
+
+// FindOneDomain returns a custom domain of an organization.
+func (r *Repository) FindOneDomain(organizationID uint, domain string) (*CustomDomainModel, error) {
+	var customDomain CustomDomainModel
+
+	err := r.db.Where(&CustomDomainModel{OrganizationID: organizationID, Domain: domain}).First(&customDomain).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&customDomainNotFoundError{
+			organizationID: organizationID,
+			domain:         domain,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get custom domain"),
+			"organization", organizationID,
+			"domain", domain,
+		)
+	}
+
+	return &customDomain, nil
+}
+
+// FindDomainByName returns the custom domain attached to any organization or nil if the domain is not attached.
+func (r *Repository) FindDomainByName(domain string) (*CustomDomainModel, error) {
+	var customDomain CustomDomainModel
+
+	err := r.db.Where(&CustomDomainModel{Domain: domain}).First(&customDomain).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not check custom domain"), "domain", domain)
+	}
+
+	return &customDomain, nil
+}
+
+// FindUnverifiedDomains returns the custom domains which were created before the given time and never verified.
+func (r *Repository) FindUnverifiedDomains(createdBefore time.Time) ([]*CustomDomainModel, error) {
+	var domains []*CustomDomainModel
+
+	err := r.db.
+		Where("status = ? AND verified_at IS NULL AND created_at < ?", pkgDns.DomainPending, createdBefore).
+		Find(&domains).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch unverified custom domains")
+	}
+
+	return domains, nil
+}
+
+// SaveDomain persists a custom domain.
+func (r *Repository) SaveDomain(customDomain *CustomDomainModel) error {
+	err := r.db.Save(customDomain).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save custom domain"),
+			"organization", customDomain.OrganizationID,
+			"domain", customDomain.Domain,
+		)
+	}
+
+	return nil
+}
+
+// DeleteDomain deletes a custom domain and its static records.
+func (r *Repository) DeleteDomain(customDomain *CustomDomainModel) error {
+	tx := r.db.Begin()
+	if err := tx.Error; err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	err := tx.Where(&StaticRecordModel{OrganizationID: customDomain.OrganizationID, Domain: customDomain.Domain}).Delete(&StaticRecordModel{}).Error
+	if err != nil {
+		tx.Rollback()
+		return emperror.With(
+			errors.Wrap(err, "could not delete static records of custom domain"),
+			"organization", customDomain.OrganizationID,
+			"domain", customDomain.Domain,
+		)
+	}
+
+	if err := tx.Delete(customDomain).Error; err != nil {
+		tx.Rollback()
+		return emperror.With(
+			errors.Wrap(err, "could not delete custom domain"),
+			"organization", customDomain.OrganizationID,
+			"domain", customDomain.Domain,
+		)
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit transaction")
+}
+
+// FindRecords returns the static records of an organization.
+func (r *Repository) FindRecords(organizationID uint) ([]*StaticRecordModel, error) {
+	var records []*StaticRecordModel
+
+	err := r.db.Where(&StaticRecordModel{OrganizationID: organizationID}).Order("name, type").Find(&records).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch static records"),
+			"organization", organizationID,
+		)
+	}
+
+	return records, nil
+}
+
+// FindOneRecord returns a static record of an organization.
+func (r *Repository) FindOneRecord(organizationID uint, id uint) (*StaticRecordModel, error) {
+	var record StaticRecordModel
+
+	err := r.db.Where(&StaticRecordModel{ID: id, OrganizationID: organizationID}).First(&record).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&staticRecordNotFoundError{
+			organizationID: organizationID,
+			id:             id,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get static record"),
+			"organization", organizationID,
+			"record", id,
+		)
+	}
+
+	return &record, nil
+}
+
+// SaveRecord persists a static record.
+func (r *Repository) SaveRecord(record *StaticRecordModel) error {
+	err := r.db.Save(record).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save static record"),
+			"organization", record.OrganizationID,
+			"record", record.Name,
+		)
+	}
+
+	return nil
+}
+
+// DeleteRecord deletes a static record.
+func (r *Repository) DeleteRecord(record *StaticRecordModel) error {
+	err := r.db.Delete(record).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not delete static record"),
+			"organization", record.OrganizationID,
+			"record", record.Name,
+		)
+	}
+
+	return nil
+}
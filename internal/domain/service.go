@@ -0,0 +1,772 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/dns/zone"
+	"github.com/banzaicloud/pipeline/model"
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultTTL is the TTL of the static records created without TTL
+const defaultTTL = 300
+
+// expiryCheckInterval is the interval at which the expired custom domains are deleted
+const expiryCheckInterval = time.Hour
+
+// DNSService manages the zones and records of the DNS provider of the organizations.
+type DNSService interface {
+	GetProviderSettings(orgId uint) (*pkgDns.ProviderSettings, error)
+	CreateCustomZone(orgId uint, domain string) (string, []string, error)
+	DeleteCustomZone(orgId uint, domain string, zoneID string) error
+	ListRecords(orgId uint, domain string, zoneID string) ([]pkgDns.Record, error)
+	UpsertRecord(orgId uint, domain string, zoneID string, record pkgDns.Record) error
+	DeleteRecord(orgId uint, domain string, zoneID string, name string, recordType string) error
+}
+
+// Clusters provides access to the clusters of the organizations.
+type Clusters interface {
+	FindByOrganization(organizationID uint) ([]*model.ClusterModel, error)
+	FindOneByID(organizationID uint, clusterID uint) (*model.ClusterModel, error)
+}
+
+type dnsDisabledError struct{}
+
+func (e *dnsDisabledError) Error() string {
+	return "external DNS service is not enabled"
+}
+
+func (e *dnsDisabledError) IsInvalid() bool {
+	return true
+}
+
+type domainNotRegisteredError struct {
+	organizationID uint
+}
+
+func (e *domainNotRegisteredError) Error() string {
+	return "the organization has no registered domain yet, it is registered with the first cluster"
+}
+
+func (e *domainNotRegisteredError) Context() []interface{} {
+	return []interface{}{"organization", e.organizationID}
+}
+
+func (e *domainNotRegisteredError) IsInvalid() bool {
+	return true
+}
+
+type invalidDomainError struct {
+	domain string
+	reason string
+}
+
+func (e *invalidDomainError) Error() string {
+	return fmt.Sprintf("invalid domain %q: %s", e.domain, e.reason)
+}
+
+func (e *invalidDomainError) IsInvalid() bool {
+	return true
+}
+
+type domainExistsError struct {
+	domain string
+}
+
+func (e *domainExistsError) Error() string {
+	return fmt.Sprintf("domain %q is already attached to an organization", e.domain)
+}
+
+func (e *domainExistsError) Conflict() bool {
+	return true
+}
+
+type invalidRecordError struct {
+	name   string
+	reason string
+}
+
+func (e *invalidRecordError) Error() string {
+	return fmt.Sprintf("invalid record %q: %s", e.name, e.reason)
+}
+
+func (e *invalidRecordError) IsInvalid() bool {
+	return true
+}
+
+type recordExistsError struct {
+	name       string
+	recordType string
+	owner      string
+}
+
+func (e *recordExistsError) Error() string {
+	if e.owner != "" {
+		return fmt.Sprintf("%s record %q is managed by external-dns (owner: %s)", e.recordType, e.name, e.owner)
+	}
+
+	return fmt.Sprintf("%s record %q is already pinned", e.recordType, e.name)
+}
+
+func (e *recordExistsError) Conflict() bool {
+	return true
+}
+
+// zoneRef identifies a zone containing a domain of an organization.
+type zoneRef struct {
+	domain string
+	zoneID string
+}
+
+// Service manages the custom domains and the DNS records of the organizations.
+type Service struct {
+	repository *Repository
+	clusters   Clusters
+	dnsService DNSService
+
+	// onDomainsChanged is called when the set of verified domains of an organization changes
+	onDomainsChanged func(organizationID uint)
+
+	// lookupNS resolves the name servers of a domain in the public DNS
+	lookupNS func(name string) ([]*net.NS, error)
+
+	// verificationPeriod is the time after which never verified custom domains are deleted,
+	// so that unverified claims cannot hold a domain name forever
+	verificationPeriod time.Duration
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(
+	repository *Repository,
+	clusters Clusters,
+	dnsService DNSService,
+	onDomainsChanged func(organizationID uint),
+	verificationPeriod time.Duration,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Service {
+	return &Service{
+		repository:         repository,
+		clusters:           clusters,
+		dnsService:         dnsService,
+		onDomainsChanged:   onDomainsChanged,
+		lookupNS:           net.LookupNS,
+		verificationPeriod: verificationPeriod,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// providerSettings returns the settings of the DNS provider managing the domain of the organization.
+func (s *Service) providerSettings(organizationID uint) (*pkgDns.ProviderSettings, error) {
+	if s.dnsService == nil {
+		return nil, errors.WithStack(&dnsDisabledError{})
+	}
+
+	settings, err := s.dnsService.GetProviderSettings(organizationID)
+	if err != nil {
+		return nil, emperror.With(errors.WithMessage(err, "failed to get DNS provider settings"), "organization", organizationID)
+	}
+
+	if settings == nil {
+		return nil, errors.WithStack(&domainNotRegisteredError{organizationID: organizationID})
+	}
+
+	return settings, nil
+}
+
+// zones returns the zones of the domain and the verified custom domains of the organization.
+func (s *Service) zones(organizationID uint) ([]zoneRef, error) {
+	settings, err := s.providerSettings(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	customDomains, err := s.repository.FindVerifiedDomains(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := []zoneRef{{domain: settings.Domain, zoneID: settings.ZoneID}}
+	for _, customDomain := range customDomains {
+		zones = append(zones, zoneRef{domain: customDomain.Domain, zoneID: customDomain.ZoneID})
+	}
+
+	return zones, nil
+}
+
+// ListDomains returns the custom domains of an organization.
+func (s *Service) ListDomains(organizationID uint) ([]*CustomDomainModel, error) {
+	return s.repository.FindDomains(organizationID)
+}
+
+// GetDomain returns a custom domain of an organization.
+func (s *Service) GetDomain(organizationID uint, domain string) (*CustomDomainModel, error) {
+	return s.repository.FindOneDomain(organizationID, zone.NormalizeName(domain))
+}
+
+// CreateDomain attaches a custom domain to an organization by creating a zone for it.
+// The domain has to be delegated to the name servers of the zone, then verified.
+func (s *Service) CreateDomain(organizationID uint, req *pkgDns.CustomDomainRequest, userID uint) (*CustomDomainModel, error) {
+	domain := zone.NormalizeName(req.Domain)
+
+	if !strings.Contains(domain, ".") {
+		return nil, errors.WithStack(&invalidDomainError{domain: domain, reason: "top level domains are not supported"})
+	}
+
+	settings, err := s.providerSettings(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if zone.InDomain(domain, settings.Domain) || zone.InDomain(settings.Domain, domain) {
+		return nil, errors.WithStack(&invalidDomainError{domain: domain, reason: "overlaps with the domain of the organization"})
+	}
+
+	existing, err := s.repository.FindDomainByName(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing != nil {
+		if !existing.IsExpired(s.verificationPeriod, time.Now()) {
+			return nil, errors.WithStack(&domainExistsError{domain: domain})
+		}
+
+		if err := s.expireDomain(existing); err != nil {
+			return nil, err
+		}
+	}
+
+	zoneID, nameServers, err := s.dnsService.CreateCustomZone(organizationID, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	customDomain := &CustomDomainModel{
+		OrganizationID: organizationID,
+		Domain:         domain,
+		Provider:       settings.Provider,
+		ZoneID:         zoneID,
+		NameServers:    strings.Join(normalizeNames(nameServers), ","),
+		Status:         pkgDns.DomainPending,
+		Message:        "delegate the domain to the listed name servers, then verify it",
+		CreatedBy:      userID,
+	}
+
+	if err := s.repository.SaveDomain(customDomain); err != nil {
+		if err := s.dnsService.DeleteCustomZone(organizationID, domain, zoneID); err != nil {
+			s.errorHandler.Handle(err)
+		}
+
+		return nil, err
+	}
+
+	s.logger.WithFields(logrus.Fields{"organization": organizationID, "domain": domain}).Info("custom domain created")
+
+	return customDomain, nil
+}
+
+// VerifyDomain checks whether the custom domain is delegated to the name servers of its zone.
+func (s *Service) VerifyDomain(organizationID uint, domain string) (*CustomDomainModel, error) {
+	customDomain, err := s.repository.FindOneDomain(organizationID, zone.NormalizeName(domain))
+	if err != nil {
+		return nil, err
+	}
+
+	wasVerified := customDomain.IsVerified()
+
+	delegated, message := s.isDelegated(customDomain)
+	if delegated {
+		if customDomain.VerifiedAt == nil {
+			now := time.Now()
+			customDomain.VerifiedAt = &now
+		}
+
+		customDomain.Status = pkgDns.DomainVerified
+	} else {
+		customDomain.Status = pkgDns.DomainPending
+	}
+	customDomain.Message = message
+
+	if err := s.repository.SaveDomain(customDomain); err != nil {
+		return nil, err
+	}
+
+	if wasVerified != customDomain.IsVerified() {
+		s.onDomainsChanged(organizationID)
+	}
+
+	return customDomain, nil
+}
+
+// isDelegated compares the public name servers of the custom domain with the name servers of its zone.
+func (s *Service) isDelegated(customDomain *CustomDomainModel) (bool, string) {
+	records, err := s.lookupNS(customDomain.Domain)
+	if err != nil {
+		return false, fmt.Sprintf("looking up the name servers of the domain failed: %s", err.Error())
+	}
+
+	var publicNameServers []string
+	for _, record := range records {
+		publicNameServers = append(publicNameServers, record.Host)
+	}
+
+	expected := normalizeNames(customDomain.GetNameServers())
+	actual := normalizeNames(publicNameServers)
+
+	if strings.Join(expected, ",") != strings.Join(actual, ",") {
+		return false, fmt.Sprintf("the domain is delegated to %s instead of %s", strings.Join(actual, ", "), strings.Join(expected, ", "))
+	}
+
+	return true, ""
+}
+
+// DeleteDomain detaches a custom domain from an organization and deletes its zone.
+func (s *Service) DeleteDomain(organizationID uint, domain string) error {
+	customDomain, err := s.repository.FindOneDomain(organizationID, zone.NormalizeName(domain))
+	if err != nil {
+		return err
+	}
+
+	if s.dnsService == nil {
+		return errors.WithStack(&dnsDisabledError{})
+	}
+
+	if err := s.dnsService.DeleteCustomZone(organizationID, customDomain.Domain, customDomain.ZoneID); err != nil {
+		return err
+	}
+
+	if err := s.repository.DeleteDomain(customDomain); err != nil {
+		return err
+	}
+
+	if customDomain.IsVerified() {
+		s.onDomainsChanged(organizationID)
+	}
+
+	return nil
+}
+
+// ExpireDomains deletes the custom domains which were not verified within the verification period.
+func (s *Service) ExpireDomains() error {
+	if s.dnsService == nil {
+		return nil
+	}
+
+	domains, err := s.repository.FindUnverifiedDomains(time.Now().Add(-s.verificationPeriod))
+	if err != nil {
+		return err
+	}
+
+	for _, customDomain := range domains {
+		if err := s.expireDomain(customDomain); err != nil {
+			s.errorHandler.Handle(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) expireDomain(customDomain *CustomDomainModel) error {
+	if s.dnsService == nil {
+		return errors.WithStack(&dnsDisabledError{})
+	}
+
+	err := s.dnsService.DeleteCustomZone(customDomain.OrganizationID, customDomain.Domain, customDomain.ZoneID)
+	if err != nil {
+		return emperror.With(errors.WithMessage(err, "could not delete zone of expired custom domain"), "domain", customDomain.Domain)
+	}
+
+	if err := s.repository.DeleteDomain(customDomain); err != nil {
+		return err
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"organization": customDomain.OrganizationID,
+		"domain":       customDomain.Domain,
+	}).Info("unverified custom domain expired")
+
+	return nil
+}
+
+// Run deletes the expired custom domains periodically.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(expiryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ExpireDomains(); err != nil {
+			s.errorHandler.Handle(emperror.Wrap(err, "could not expire unverified custom domains"))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ListRecords returns the records in the domain and the verified custom domains of an organization.
+func (s *Service) ListRecords(organizationID uint) ([]pkgDns.RecordResponse, error) {
+	zones, err := s.zones(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := s.clusters.FindByOrganization(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	clustersByUID := make(map[string]*model.ClusterModel, len(clusters))
+	for _, cluster := range clusters {
+		clustersByUID[cluster.UID] = cluster
+	}
+
+	staticRecords, err := s.repository.FindRecords(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make(map[string]*StaticRecordModel, len(staticRecords))
+	for _, staticRecord := range staticRecords {
+		pinned[recordKey(staticRecord.Name, staticRecord.Type)] = staticRecord
+	}
+
+	var responses []pkgDns.RecordResponse
+	for _, z := range zones {
+		records, err := s.dnsService.ListRecords(organizationID, z.domain, z.zoneID)
+		if err != nil {
+			return nil, err
+		}
+
+		owners := recordOwners(records)
+
+		for _, record := range records {
+			name := zone.NormalizeName(record.Name)
+
+			if isZoneRecord(record, z.domain) || isRegistryRecord(record) {
+				continue
+			}
+
+			response := pkgDns.RecordResponse{
+				Record: record,
+				Domain: z.domain,
+				Owner:  owners[name],
+			}
+			response.Name = name
+
+			if cluster, ok := clustersByUID[response.Owner]; ok {
+				response.ClusterID = cluster.ID
+				response.ClusterName = cluster.Name
+			}
+
+			if staticRecord, ok := pinned[recordKey(name, record.Type)]; ok {
+				response.ID = staticRecord.ID
+				response.Pinned = true
+			}
+
+			responses = append(responses, response)
+		}
+	}
+
+	return responses, nil
+}
+
+// ListClusterRecords returns the records managed by the external-dns deployed to a cluster.
+func (s *Service) ListClusterRecords(organizationID uint, clusterID uint) ([]pkgDns.RecordResponse, error) {
+	cluster, err := s.clusters.FindOneByID(organizationID, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.ListRecords(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []pkgDns.RecordResponse
+	for _, record := range records {
+		if record.Owner == cluster.UID {
+			responses = append(responses, record)
+		}
+	}
+
+	return responses, nil
+}
+
+// CreateStaticRecord pins a record in the domain or in a verified custom domain of an organization.
+// External-dns never modifies the pinned records as it only manages the records it owns.
+func (s *Service) CreateStaticRecord(organizationID uint, req *pkgDns.StaticRecordRequest, userID uint) (*StaticRecordModel, error) {
+	record := pkgDns.Record{
+		Name:   zone.NormalizeName(req.Name),
+		Type:   strings.ToUpper(req.Type),
+		TTL:    req.TTL,
+		Values: req.Values,
+	}
+
+	if record.TTL <= 0 {
+		record.TTL = defaultTTL
+	}
+
+	if err := validateStaticRecord(record); err != nil {
+		return nil, err
+	}
+
+	zones, err := s.zones(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	z, ok := findZone(zones, record.Name)
+	if !ok {
+		return nil, errors.WithStack(&invalidRecordError{
+			name:   record.Name,
+			reason: "the name is not in the domain or in a verified custom domain of the organization",
+		})
+	}
+
+	staticRecords, err := s.repository.FindRecords(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, staticRecord := range staticRecords {
+		if staticRecord.Name == record.Name && staticRecord.Type == record.Type {
+			return nil, errors.WithStack(&recordExistsError{name: record.Name, recordType: record.Type})
+		}
+	}
+
+	records, err := s.dnsService.ListRecords(organizationID, z.domain, z.zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	if owner, ok := recordOwners(records)[record.Name]; ok {
+		return nil, errors.WithStack(&recordExistsError{name: record.Name, recordType: record.Type, owner: owner})
+	}
+
+	staticRecord := &StaticRecordModel{
+		OrganizationID: organizationID,
+		Domain:         z.domain,
+		Name:           record.Name,
+		Type:           record.Type,
+		TTL:            record.TTL,
+		CreatedBy:      userID,
+	}
+
+	if err := staticRecord.SetValues(record.Values); err != nil {
+		return nil, errors.Wrap(err, "failed to encode record values")
+	}
+
+	if err := s.dnsService.UpsertRecord(organizationID, z.domain, z.zoneID, record); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.SaveRecord(staticRecord); err != nil {
+		return nil, err
+	}
+
+	return staticRecord, nil
+}
+
+// DeleteStaticRecord deletes a pinned record of an organization.
+func (s *Service) DeleteStaticRecord(organizationID uint, id uint) error {
+	staticRecord, err := s.repository.FindOneRecord(organizationID, id)
+	if err != nil {
+		return err
+	}
+
+	zones, err := s.zones(organizationID)
+	if err != nil {
+		return err
+	}
+
+	for _, z := range zones {
+		if z.domain == staticRecord.Domain {
+			err := s.dnsService.DeleteRecord(organizationID, z.domain, z.zoneID, staticRecord.Name, staticRecord.Type)
+			if err != nil {
+				return err
+			}
+
+			break
+		}
+	}
+
+	return s.repository.DeleteRecord(staticRecord)
+}
+
+// DeleteRecordsOwnedBy deletes the records owned by the given external-dns owner from the verified custom
+// domains of an organization. The records in the domain of the organization are deleted by the DNS service.
+func DeleteRecordsOwnedBy(repository *Repository, dnsService DNSService, organizationID uint, ownerID string) error {
+	customDomains, err := repository.FindVerifiedDomains(organizationID)
+	if err != nil {
+		return err
+	}
+
+	for _, customDomain := range customDomains {
+		records, err := dnsService.ListRecords(organizationID, customDomain.Domain, customDomain.ZoneID)
+		if err != nil {
+			return err
+		}
+
+		owners := recordOwners(records)
+
+		for _, record := range records {
+			name := zone.NormalizeName(record.Name)
+			if owners[name] != ownerID || isZoneRecord(record, customDomain.Domain) {
+				continue
+			}
+
+			err := dnsService.DeleteRecord(organizationID, customDomain.Domain, customDomain.ZoneID, name, record.Type)
+			if err != nil {
+				return emperror.With(err, "domain", customDomain.Domain, "record", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordOwners maps the record names to the external-dns owner IDs found in the registry TXT records.
+func recordOwners(records []pkgDns.Record) map[string]string {
+	owners := make(map[string]string)
+
+	for _, record := range records {
+		if record.Type != pkgDns.RecordTypeTXT {
+			continue
+		}
+
+		for _, value := range record.Values {
+			if owner, ok := zone.Owner(value); ok {
+				owners[zone.NormalizeName(record.Name)] = owner
+				break
+			}
+		}
+	}
+
+	return owners
+}
+
+// isRegistryRecord checks if the record is an external-dns registry TXT record.
+func isRegistryRecord(record pkgDns.Record) bool {
+	if record.Type != pkgDns.RecordTypeTXT {
+		return false
+	}
+
+	for _, value := range record.Values {
+		if _, ok := zone.Owner(value); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isZoneRecord checks if the record is maintained by the DNS provider for the zone itself.
+func isZoneRecord(record pkgDns.Record, domain string) bool {
+	return (record.Type == pkgDns.RecordTypeNS || record.Type == "SOA") &&
+		zone.NormalizeName(record.Name) == zone.NormalizeName(domain)
+}
+
+// findZone returns the zone with the longest domain containing the name.
+func findZone(zones []zoneRef, name string) (zoneRef, bool) {
+	var found zoneRef
+	var ok bool
+
+	for _, z := range zones {
+		if zone.InDomain(name, z.domain) && len(z.domain) > len(found.domain) {
+			found, ok = z, true
+		}
+	}
+
+	return found, ok
+}
+
+func validateStaticRecord(record pkgDns.Record) error {
+	supported := false
+	for _, recordType := range pkgDns.StaticRecordTypes {
+		if record.Type == recordType {
+			supported = true
+			break
+		}
+	}
+
+	if !supported {
+		return errors.WithStack(&invalidRecordError{
+			name:   record.Name,
+			reason: fmt.Sprintf("record type must be one of %s", strings.Join(pkgDns.StaticRecordTypes, ", ")),
+		})
+	}
+
+	if len(record.Values) == 0 {
+		return errors.WithStack(&invalidRecordError{name: record.Name, reason: "at least one value is required"})
+	}
+
+	if record.Type == pkgDns.RecordTypeCNAME && len(record.Values) > 1 {
+		return errors.WithStack(&invalidRecordError{name: record.Name, reason: "CNAME records must have exactly one value"})
+	}
+
+	for _, value := range record.Values {
+		switch record.Type {
+		case pkgDns.RecordTypeA:
+			if ip := net.ParseIP(value); ip == nil || ip.To4() == nil {
+				return errors.WithStack(&invalidRecordError{name: record.Name, reason: fmt.Sprintf("%q is not an IPv4 address", value)})
+			}
+		case pkgDns.RecordTypeAAAA:
+			if ip := net.ParseIP(value); ip == nil || ip.To4() != nil {
+				return errors.WithStack(&invalidRecordError{name: record.Name, reason: fmt.Sprintf("%q is not an IPv6 address", value)})
+			}
+		case pkgDns.RecordTypeMX:
+			if _, _, err := zone.ParseMX(value); err != nil {
+				return errors.WithStack(&invalidRecordError{name: record.Name, reason: err.Error()})
+			}
+		}
+	}
+
+	return nil
+}
+
+func recordKey(name string, recordType string) string {
+	return name + "/" + recordType
+}
+
+// normalizeNames returns the sorted, normalized list of the names.
+func normalizeNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		normalized = append(normalized, zone.NormalizeName(name))
+	}
+
+	sort.Strings(normalized)
+
+	return normalized
+}
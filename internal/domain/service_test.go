@@ -0,0 +1,223 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package domain
+
+import (
+	"testing"
+	"time"
+
+	pkgDns "github.com/banzaicloud/pipeline/pkg/dns"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRecordOwners(t *testing.T) {
+	records := []pkgDns.Record{
+		{Name: "app.org.example.com", Type: pkgDns.RecordTypeA, Values: []string{"10.0.0.1"}},
+		{Name: "app.org.example.com.", Type: pkgDns.RecordTypeTXT, Values: []string{"heritage=external-dns,external-dns/owner=cluster-1,external-dns/resource=service/default/app"}},
+		{Name: "org.example.com", Type: pkgDns.RecordTypeTXT, Values: []string{"v=spf1 -all"}},
+	}
+
+	owners := recordOwners(records)
+
+	if owners["app.org.example.com"] != "cluster-1" {
+		t.Errorf("unexpected owner: %q", owners["app.org.example.com"])
+	}
+
+	if _, ok := owners["org.example.com"]; ok {
+		t.Error("the SPF record should not be owned")
+	}
+
+	if !isRegistryRecord(records[1]) || isRegistryRecord(records[2]) {
+		t.Error("only the external-dns TXT record should be a registry record")
+	}
+}
+
+func TestFindZone(t *testing.T) {
+	zones := []zoneRef{
+		{domain: "org.example.com", zoneID: "1"},
+		{domain: "example.org", zoneID: "2"},
+		{domain: "shop.example.org", zoneID: "3"},
+	}
+
+	tests := map[string]string{
+		"app.org.example.com":   "1",
+		"example.org":           "2",
+		"www.shop.example.org":  "3",
+		"www.other.example.com": "",
+	}
+
+	for name, expected := range tests {
+		z, ok := findZone(zones, name)
+		if ok != (expected != "") || z.zoneID != expected {
+			t.Errorf("findZone(%q) should be %q, got %q", name, expected, z.zoneID)
+		}
+	}
+}
+
+func TestValidateStaticRecord(t *testing.T) {
+	tests := []struct {
+		record pkgDns.Record
+		valid  bool
+	}{
+		{pkgDns.Record{Type: pkgDns.RecordTypeA, Values: []string{"10.0.0.1"}}, true},
+		{pkgDns.Record{Type: pkgDns.RecordTypeA, Values: []string{"::1"}}, false},
+		{pkgDns.Record{Type: pkgDns.RecordTypeAAAA, Values: []string{"::1"}}, true},
+		{pkgDns.Record{Type: pkgDns.RecordTypeCNAME, Values: []string{"a.example.com", "b.example.com"}}, false},
+		{pkgDns.Record{Type: pkgDns.RecordTypeMX, Values: []string{"10 mail.example.com"}}, true},
+		{pkgDns.Record{Type: pkgDns.RecordTypeMX, Values: []string{"mail.example.com"}}, false},
+		{pkgDns.Record{Type: pkgDns.RecordTypeNS, Values: []string{"ns.example.com"}}, false},
+		{pkgDns.Record{Type: pkgDns.RecordTypeTXT}, false},
+	}
+
+	for _, test := range tests {
+		err := validateStaticRecord(test.record)
+		if (err == nil) != test.valid {
+			t.Errorf("unexpected validation result for %s %v: %v", test.record.Type, test.record.Values, err)
+		}
+	}
+}
+
+func TestCustomDomainModel_ConvertModelToEntity(t *testing.T) {
+	customDomain := CustomDomainModel{
+		Domain:      "example.org",
+		Status:      pkgDns.DomainPending,
+		NameServers: "ns1.example.net,ns2.example.net",
+	}
+
+	response := customDomain.ConvertModelToEntity()
+
+	if len(response.Delegation) != 1 {
+		t.Fatalf("expected one delegation record, got %d", len(response.Delegation))
+	}
+
+	delegation := response.Delegation[0]
+	if delegation.Name != "example.org" || delegation.Type != pkgDns.RecordTypeNS || len(delegation.Values) != 2 {
+		t.Errorf("unexpected delegation record: %+v", delegation)
+	}
+}
+
+func TestCustomDomainModel_IsExpired(t *testing.T) {
+	now := time.Now()
+	verifiedAt := now.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		domain  CustomDomainModel
+		expired bool
+	}{
+		{"new", CustomDomainModel{Status: pkgDns.DomainPending, CreatedAt: now.Add(-time.Hour)}, false},
+		{"unverified", CustomDomainModel{Status: pkgDns.DomainPending, CreatedAt: now.Add(-25 * time.Hour)}, true},
+		{"verified", CustomDomainModel{Status: pkgDns.DomainVerified, CreatedAt: now.Add(-25 * time.Hour), VerifiedAt: &verifiedAt}, false},
+		{"delegation lost", CustomDomainModel{Status: pkgDns.DomainPending, CreatedAt: now.Add(-25 * time.Hour), VerifiedAt: &verifiedAt}, false},
+	}
+
+	for _, test := range tests {
+		if expired := test.domain.IsExpired(24*time.Hour, now); expired != test.expired {
+			t.Errorf("%s domain should be expired: %t, got %t", test.name, test.expired, expired)
+		}
+	}
+}
+
+type testErrorHandler struct {
+	t *testing.T
+}
+
+func (h testErrorHandler) Handle(err error) {
+	h.t.Error(err)
+}
+
+type fakeDNSService struct {
+	DNSService
+
+	zones map[string]uint
+}
+
+func (s *fakeDNSService) GetProviderSettings(orgId uint) (*pkgDns.ProviderSettings, error) {
+	return &pkgDns.ProviderSettings{Provider: pkgDns.Route53, Domain: "org.example.com"}, nil
+}
+
+func (s *fakeDNSService) CreateCustomZone(orgId uint, domain string) (string, []string, error) {
+	s.zones[domain] = orgId
+
+	return domain, []string{"ns1.example.net", "ns2.example.net"}, nil
+}
+
+func (s *fakeDNSService) DeleteCustomZone(orgId uint, domain string, zoneID string) error {
+	delete(s.zones, domain)
+
+	return nil
+}
+
+func TestService_CreateDomain_Expired(t *testing.T) {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// every connection would get its own in-memory database
+	db.DB().SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&CustomDomainModel{}, &StaticRecordModel{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	dnsService := &fakeDNSService{zones: map[string]uint{}}
+	service := NewService(NewRepository(db), nil, dnsService, func(uint) {}, 24*time.Hour, logrus.New(), testErrorHandler{t})
+
+	req := &pkgDns.CustomDomainRequest{Domain: "example.org"}
+
+	if _, err := service.CreateDomain(1, req, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := service.CreateDomain(2, req, 2); err == nil {
+		t.Fatal("a pending domain should not be attached to another organization")
+	}
+
+	// let the pending domain of the first organization expire
+	err = db.Model(&CustomDomainModel{}).Where("domain = ?", "example.org").UpdateColumn("created_at", time.Now().Add(-25*time.Hour)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	customDomain, err := service.CreateDomain(2, req, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if customDomain.OrganizationID != 2 || dnsService.zones["example.org"] != 2 {
+		t.Errorf("the expired domain should be attached to the second organization")
+	}
+
+	if _, err := service.GetDomain(1, "example.org"); err == nil {
+		t.Error("the expired domain should be detached from the first organization")
+	}
+
+	err = db.Model(&CustomDomainModel{}).Where("domain = ?", "example.org").UpdateColumn("created_at", time.Now().Add(-25*time.Hour)).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ExpireDomains(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dnsService.zones) != 0 {
+		t.Errorf("the zone of the expired domain should be deleted")
+	}
+}
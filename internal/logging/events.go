@@ -0,0 +1,41 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const clusterDeletedTopic = "cluster_deleted"
+
+type eventBus interface {
+	SubscribeAsync(topic string, fn interface{}, transactional bool) error
+}
+
+// Register subscribes to the cluster events to clean up the logging config of the deleted clusters.
+func (s *Service) Register(clusterEvents eventBus) error {
+	if err := clusterEvents.SubscribeAsync(clusterDeletedTopic, s.clusterDeleted, false); err != nil {
+		return emperror.With(errors.Wrap(err, "could not subscribe to events"), "topic", clusterDeletedTopic)
+	}
+
+	return nil
+}
+
+func (s *Service) clusterDeleted(organizationID uint, clusterName string) {
+	if err := s.repository.DeleteConfigByClusterName(organizationID, clusterName); err != nil {
+		s.errorHandler.Handle(err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	pipConfig "github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/helm"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/pkg/errors"
+)
+
+// loggingNotInstalledError is returned when the logging release of a cluster is missing.
+type loggingNotInstalledError struct {
+	clusterName string
+}
+
+func (e *loggingNotInstalledError) Error() string {
+	return "logging is not installed on the cluster"
+}
+
+func (e *loggingNotInstalledError) Context() []interface{} {
+	return []interface{}{"cluster", e.clusterName}
+}
+
+func (e *loggingNotInstalledError) Conflict() bool {
+	return true
+}
+
+// getLoggingRelease returns the logging release of a cluster.
+func getLoggingRelease(clusterName string, kubeConfig []byte) (*pkgHelm.GetDeploymentResponse, error) {
+	deployment, err := helm.GetDeployment(pipConfig.LoggingReleaseName, kubeConfig)
+	if _, ok := err.(*helm.DeploymentNotFoundError); ok {
+		return nil, errors.WithStack(&loggingNotInstalledError{clusterName: clusterName})
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not get logging release")
+	}
+
+	return deployment, nil
+}
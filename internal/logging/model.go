@@ -0,0 +1,91 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgLogging "github.com/banzaicloud/pipeline/pkg/logging"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	configsTableName = "logging_configs"
+)
+
+// ConfigModel describes the logging config of a cluster.
+type ConfigModel struct {
+	ID             uint `gorm:"primary_key"`
+	OrganizationID uint
+	ClusterID      uint `gorm:"unique_index:idx_logging_config_cluster"`
+	ClusterName    string
+
+	Spec string `sql:"type:text;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UpdatedBy uint
+}
+
+// TableName changes the default table name.
+func (ConfigModel) TableName() string {
+	return configsTableName
+}
+
+// GetConfig returns the logging config stored in the model.
+func (m *ConfigModel) GetConfig() (*pkgLogging.Config, error) {
+	var config pkgLogging.Config
+
+	if err := json.Unmarshal([]byte(m.Spec), &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// SetConfig stores a logging config in the model.
+func (m *ConfigModel) SetConfig(config *pkgLogging.Config) error {
+	spec, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+
+	m.Spec = string(spec)
+
+	return nil
+}
+
+// Migrate executes the table migrations for the logging models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&ConfigModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating logging tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,243 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/banzaicloud/pipeline/internal/objectstore"
+	pkgLogging "github.com/banzaicloud/pipeline/pkg/logging"
+	"github.com/banzaicloud/pipeline/pkg/providers"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/pkg/errors"
+)
+
+// Keys of the access secrets of Azure buckets
+const (
+	azureStorageAccount = "storageAccount"
+	azureAccessKey      = "accessKey"
+)
+
+// outputSecretPrefix is the name prefix of the Kubernetes secrets holding the credentials of the outputs
+const outputSecretPrefix = "logging-output-"
+
+// resolvedOutput holds the bucket and the credentials of an output, the credentials are keyed by output plugin parameter.
+type resolvedOutput struct {
+	bucket      *objectstore.BucketInfo
+	credentials map[string]string
+}
+
+func outputSecretName(outputName string) string {
+	return outputSecretPrefix + outputName
+}
+
+// outputCredentials maps the values of the organization secret of an output to the parameters of its output plugin.
+func outputCredentials(output pkgLogging.Output, bucket *objectstore.BucketInfo, secretType string, values map[string]string) (map[string]string, error) {
+	if output.Type == pkgLogging.ObjectStoreOutput {
+		// Azure buckets are accessed with the storage account key of their access secret,
+		// the others with the secret of their cloud
+		if bucket.Cloud != providers.Azure && secretType != bucket.Cloud {
+			return nil, errors.Errorf("secret of output %q has to be a %s secret", output.Name, bucket.Cloud)
+		}
+
+		switch bucket.Cloud {
+		case providers.Amazon:
+			return map[string]string{
+				"aws_key_id":  values[pkgSecret.AwsAccessKeyId],
+				"aws_sec_key": values[pkgSecret.AwsSecretAccessKey],
+			}, nil
+
+		case providers.Google:
+			credentials, err := json.Marshal(values)
+			if err != nil {
+				return nil, errors.Wrap(err, "could not marshal google credentials")
+			}
+
+			return map[string]string{
+				"credentials_json": string(credentials),
+			}, nil
+
+		case providers.Azure:
+			if values[azureAccessKey] == "" {
+				return nil, errors.Errorf("secret of output %q has no storage account access key", output.Name)
+			}
+
+			return map[string]string{
+				"azure_storage_account":    values[azureStorageAccount],
+				"azure_storage_access_key": values[azureAccessKey],
+			}, nil
+
+		case providers.Alibaba:
+			return map[string]string{
+				"access_key_id":     values[pkgSecret.AlibabaAccessKeyId],
+				"access_key_secret": values[pkgSecret.AlibabaSecretAccessKey],
+			}, nil
+		}
+
+		return nil, errors.Errorf("output %q has unsupported bucket cloud %q", output.Name, bucket.Cloud)
+	}
+
+	if secretType != pkgSecret.PasswordSecretType {
+		return nil, errors.Errorf("secret of output %q has to be a %s secret", output.Name, pkgSecret.PasswordSecretType)
+	}
+
+	user := "username"
+	if output.Type == pkgLogging.ElasticsearchOutput {
+		user = "user"
+	}
+
+	return map[string]string{
+		user:       values[pkgSecret.Username],
+		"password": values[pkgSecret.Password],
+	}, nil
+}
+
+// renderOutput renders an output as a logging operator cluster output.
+func renderOutput(output pkgLogging.Output, resolved resolvedOutput) map[string]interface{} {
+	var plugin string
+	params := make(map[string]interface{})
+
+	switch output.Type {
+	case pkgLogging.ObjectStoreOutput:
+		bucket := resolved.bucket
+
+		switch bucket.Cloud {
+		case providers.Amazon:
+			plugin = "s3"
+			params["s3_bucket"] = bucket.Name
+			params["s3_region"] = bucket.Location
+
+		case providers.Google:
+			plugin = "gcs"
+			params["bucket"] = bucket.Name
+
+		case providers.Azure:
+			plugin = "azurestorage"
+			params["azure_container"] = bucket.Name
+
+		case providers.Alibaba:
+			plugin = "oss"
+			params["oss_bucket"] = bucket.Name
+			params["oss_endpoint"] = fmt.Sprintf("oss-%s.aliyuncs.com", bucket.Location)
+		}
+
+		if output.ObjectStore.Path != "" {
+			params["path"] = output.ObjectStore.Path
+		}
+
+	case pkgLogging.ElasticsearchOutput:
+		plugin = "elasticsearch"
+		params["host"] = output.Elasticsearch.Host
+
+		if output.Elasticsearch.Port != 0 {
+			params["port"] = output.Elasticsearch.Port
+		}
+
+		if output.Elasticsearch.Scheme != "" {
+			params["scheme"] = output.Elasticsearch.Scheme
+		}
+
+		if output.Elasticsearch.IndexName != "" {
+			params["index_name"] = output.Elasticsearch.IndexName
+		}
+
+		if output.Elasticsearch.SSLVerify != nil {
+			params["ssl_verify"] = *output.Elasticsearch.SSLVerify
+		}
+
+	case pkgLogging.LokiOutput:
+		plugin = "loki"
+		params["url"] = output.Loki.URL
+
+		if output.Loki.TenantID != "" {
+			params["tenant"] = output.Loki.TenantID
+		}
+
+	case pkgLogging.HTTPOutput:
+		plugin = "http"
+		params["endpoint"] = output.HTTP.Endpoint
+	}
+
+	for key := range resolved.credentials {
+		params[key] = map[string]interface{}{
+			"valueFrom": map[string]interface{}{
+				"secretKeyRef": map[string]interface{}{
+					"name": outputSecretName(output.Name),
+					"key":  key,
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"name": output.Name,
+		"spec": map[string]interface{}{
+			plugin: params,
+		},
+	}
+}
+
+// renderValues renders the outputs and the flows of a logging config into the values of the logging release.
+// Flows without namespaces become cluster flows, the others are rendered as a flow in each of their namespaces.
+func renderValues(config pkgLogging.Config, resolved map[string]resolvedOutput) map[string]interface{} {
+	outputs := make([]interface{}, 0, len(config.Outputs))
+	for _, output := range config.Outputs {
+		outputs = append(outputs, renderOutput(output, resolved[output.Name]))
+	}
+
+	clusterFlows := make([]interface{}, 0)
+	flows := make([]interface{}, 0)
+
+	for _, flow := range config.Flows {
+		selectors := flow.Selector
+		if selectors == nil {
+			selectors = map[string]string{}
+		}
+
+		if len(flow.Namespaces) == 0 {
+			clusterFlows = append(clusterFlows, map[string]interface{}{
+				"name": flow.Name,
+				"spec": map[string]interface{}{
+					"selectors":  selectors,
+					"outputRefs": flow.Outputs,
+				},
+			})
+
+			continue
+		}
+
+		namespaces := append([]string(nil), flow.Namespaces...)
+		sort.Strings(namespaces)
+
+		for _, namespace := range namespaces {
+			flows = append(flows, map[string]interface{}{
+				"name":      flow.Name,
+				"namespace": namespace,
+				"spec": map[string]interface{}{
+					"selectors":        selectors,
+					"globalOutputRefs": flow.Outputs,
+				},
+			})
+		}
+	}
+
+	return map[string]interface{}{
+		"clusterOutputs": outputs,
+		"clusterFlows":   clusterFlows,
+		"flows":          flows,
+	}
+}
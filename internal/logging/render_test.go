@@ -0,0 +1,154 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/internal/objectstore"
+	pkgLogging "github.com/banzaicloud/pipeline/pkg/logging"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+)
+
+func secretRef(output string, key string) map[string]interface{} {
+	return map[string]interface{}{
+		"valueFrom": map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{
+				"name": "logging-output-" + output,
+				"key":  key,
+			},
+		},
+	}
+}
+
+func TestOutputCredentials(t *testing.T) {
+	bucket := &objectstore.BucketInfo{Name: "logs", Cloud: "amazon", Location: "eu-west-1"}
+	archive := pkgLogging.Output{Name: "archive", Type: pkgLogging.ObjectStoreOutput, ObjectStore: &pkgLogging.ObjectStoreOutputConfig{Bucket: "logs", Cloud: "amazon"}}
+
+	credentials, err := outputCredentials(archive, bucket, "amazon", map[string]string{
+		pkgSecret.AwsAccessKeyId:     "id",
+		pkgSecret.AwsSecretAccessKey: "key",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{"aws_key_id": "id", "aws_sec_key": "key"}
+	if !reflect.DeepEqual(credentials, expected) {
+		t.Errorf("expected %v credentials, got %v", expected, credentials)
+	}
+
+	if _, err := outputCredentials(archive, bucket, "google", map[string]string{}); err == nil {
+		t.Error("expected error for a secret of another cloud")
+	}
+
+	search := pkgLogging.Output{Name: "search", Type: pkgLogging.ElasticsearchOutput, Elasticsearch: &pkgLogging.ElasticsearchOutputConfig{Host: "es"}}
+
+	credentials, err = outputCredentials(search, nil, pkgSecret.PasswordSecretType, map[string]string{
+		pkgSecret.Username: "elastic",
+		pkgSecret.Password: "changeme",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = map[string]string{"user": "elastic", "password": "changeme"}
+	if !reflect.DeepEqual(credentials, expected) {
+		t.Errorf("expected %v credentials, got %v", expected, credentials)
+	}
+
+	if _, err := outputCredentials(search, nil, pkgSecret.GenericSecret, map[string]string{}); err == nil {
+		t.Error("expected error for a non-password secret")
+	}
+}
+
+func TestRenderValues(t *testing.T) {
+	config := pkgLogging.Config{
+		Outputs: []pkgLogging.Output{
+			{Name: "archive", Type: pkgLogging.ObjectStoreOutput, ObjectStore: &pkgLogging.ObjectStoreOutputConfig{Bucket: "logs", Cloud: "amazon", Path: "cluster/"}},
+			{Name: "loki", Type: pkgLogging.LokiOutput, Loki: &pkgLogging.LokiOutputConfig{URL: "http://loki:3100"}},
+		},
+		Flows: []pkgLogging.Flow{
+			{Name: "all", Outputs: []string{"archive"}},
+			{Name: "apps", Namespaces: []string{"web", "api"}, Selector: map[string]string{"tier": "frontend"}, Outputs: []string{"loki"}},
+		},
+	}
+
+	resolved := map[string]resolvedOutput{
+		"archive": {
+			bucket:      &objectstore.BucketInfo{Name: "logs", Cloud: "amazon", Location: "eu-west-1"},
+			credentials: map[string]string{"aws_key_id": "id", "aws_sec_key": "key"},
+		},
+		"loki": {},
+	}
+
+	expected := map[string]interface{}{
+		"clusterOutputs": []interface{}{
+			map[string]interface{}{
+				"name": "archive",
+				"spec": map[string]interface{}{
+					"s3": map[string]interface{}{
+						"s3_bucket":   "logs",
+						"s3_region":   "eu-west-1",
+						"path":        "cluster/",
+						"aws_key_id":  secretRef("archive", "aws_key_id"),
+						"aws_sec_key": secretRef("archive", "aws_sec_key"),
+					},
+				},
+			},
+			map[string]interface{}{
+				"name": "loki",
+				"spec": map[string]interface{}{
+					"loki": map[string]interface{}{
+						"url": "http://loki:3100",
+					},
+				},
+			},
+		},
+		"clusterFlows": []interface{}{
+			map[string]interface{}{
+				"name": "all",
+				"spec": map[string]interface{}{
+					"selectors":  map[string]string{},
+					"outputRefs": []string{"archive"},
+				},
+			},
+		},
+		"flows": []interface{}{
+			map[string]interface{}{
+				"name":      "apps",
+				"namespace": "api",
+				"spec": map[string]interface{}{
+					"selectors":        map[string]string{"tier": "frontend"},
+					"globalOutputRefs": []string{"loki"},
+				},
+			},
+			map[string]interface{}{
+				"name":      "apps",
+				"namespace": "web",
+				"spec": map[string]interface{}{
+					"selectors":        map[string]string{"tier": "frontend"},
+					"globalOutputRefs": []string{"loki"},
+				},
+			},
+		},
+	}
+
+	values := renderValues(config, resolved)
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values:\n%#v\nexpected:\n%#v", values, expected)
+	}
+}
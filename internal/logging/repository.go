@@ -0,0 +1,79 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the logging configs of the clusters.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// FindConfig returns the logging config of a cluster, nil if no config is stored.
+func (r *Repository) FindConfig(clusterID uint) (*ConfigModel, error) {
+	var config ConfigModel
+
+	err := r.db.Where("cluster_id = ?", clusterID).First(&config).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch logging config"), "cluster", clusterID)
+	}
+
+	return &config, nil
+}
+
+// SaveConfig persists a logging config.
+func (r *Repository) SaveConfig(config *ConfigModel) error {
+	err := r.db.Save(config).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not save logging config"), "cluster", config.ClusterID)
+	}
+
+	return nil
+}
+
+// DeleteConfig deletes the logging config of a cluster.
+func (r *Repository) DeleteConfig(clusterID uint) error {
+	err := r.db.Where("cluster_id = ?", clusterID).Delete(&ConfigModel{}).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not delete logging config"), "cluster", clusterID)
+	}
+
+	return nil
+}
+
+// DeleteConfigByClusterName deletes the logging config of a cluster identified by its name.
+func (r *Repository) DeleteConfigByClusterName(organizationID uint, clusterName string) error {
+	err := r.db.Where("organization_id = ? AND cluster_name = ?", organizationID, clusterName).Delete(&ConfigModel{}).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not delete logging config"),
+			"organization", organizationID,
+			"cluster", clusterName,
+		)
+	}
+
+	return nil
+}
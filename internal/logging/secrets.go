@@ -0,0 +1,79 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// outputSecretLabel marks the Kubernetes secrets holding output credentials with the name of their output
+const outputSecretLabel = "logging.banzaicloud.io/output"
+
+// installOutputSecrets creates or updates the Kubernetes secrets of the output credentials
+// and deletes the secrets of the outputs removed from the config.
+func installOutputSecrets(client kubernetes.Interface, namespace string, resolved map[string]resolvedOutput) error {
+	secrets := client.CoreV1().Secrets(namespace)
+
+	for name, output := range resolved {
+		if len(output.credentials) == 0 {
+			continue
+		}
+
+		secretName := outputSecretName(name)
+
+		secret, err := secrets.Get(secretName, metav1.GetOptions{})
+		if k8sErrors.IsNotFound(err) {
+			_, err = secrets.Create(&v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      secretName,
+					Namespace: namespace,
+					Labels:    map[string]string{outputSecretLabel: name},
+				},
+				StringData: output.credentials,
+			})
+		} else if err == nil {
+			secret.Data = nil // clear data so that it is created from string data again
+			secret.StringData = output.credentials
+			_, err = secrets.Update(secret)
+		}
+
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "could not install output secret"), "secret", secretName)
+		}
+	}
+
+	list, err := secrets.List(metav1.ListOptions{LabelSelector: outputSecretLabel})
+	if err != nil {
+		return errors.Wrap(err, "could not list output secrets")
+	}
+
+	for _, secret := range list.Items {
+		if output, ok := resolved[secret.Labels[outputSecretLabel]]; ok && len(output.credentials) > 0 {
+			continue
+		}
+
+		err := secrets.Delete(secret.Name, &metav1.DeleteOptions{})
+		if err != nil && !k8sErrors.IsNotFound(err) {
+			return emperror.With(errors.Wrap(err, "could not delete output secret"), "secret", secret.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,335 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"sync"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/banzaicloud/pipeline/internal/objectstore"
+	"github.com/banzaicloud/pipeline/internal/providers"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	pkgLogging "github.com/banzaicloud/pipeline/pkg/logging"
+	pkgProviders "github.com/banzaicloud/pipeline/pkg/providers"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type invalidConfigError struct {
+	err error
+}
+
+func (e *invalidConfigError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidConfigError) IsInvalid() bool {
+	return true
+}
+
+type secretStore interface {
+	Get(organizationID uint, secretID string) (*secret.SecretItemResponse, error)
+}
+
+// Service manages the logging outputs and flows of the clusters.
+type Service struct {
+	repository *Repository
+	secrets    secretStore
+
+	// mu serializes the upgrades of the logging releases
+	mu           sync.Mutex
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(repository *Repository, logger logrus.FieldLogger, errorHandler emperror.Handler) *Service {
+	return &Service{
+		repository:   repository,
+		secrets:      secret.Store,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// GetConfig returns the logging config of a cluster.
+func (s *Service) GetConfig(commonCluster cluster.CommonCluster) (*pkgLogging.ConfigResponse, error) {
+	model, err := s.repository.FindConfig(commonCluster.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		return &pkgLogging.ConfigResponse{
+			Config: pkgLogging.Config{
+				Outputs: []pkgLogging.Output{},
+				Flows:   []pkgLogging.Flow{},
+			},
+		}, nil
+	}
+
+	config, err := s.parseConfig(model)
+	if err != nil {
+		return nil, err
+	}
+
+	return convertModelToEntity(model, config), nil
+}
+
+// SetConfig replaces the logging config of a cluster and applies it to its logging release.
+func (s *Service) SetConfig(commonCluster cluster.CommonCluster, config *pkgLogging.Config, userID uint) (*pkgLogging.ConfigResponse, error) {
+	if err := pkgLogging.Validate(*config); err != nil {
+		return nil, errors.WithStack(&invalidConfigError{err})
+	}
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get k8s config")
+	}
+
+	// fail early when logging is not installed, the config is applied as an upgrade of the logging release
+	if _, err := getLoggingRelease(commonCluster.GetName(), kubeConfig); err != nil {
+		return nil, err
+	}
+
+	resolved, err := s.resolveOutputs(commonCluster.GetOrganizationId(), *config)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := s.repository.FindConfig(commonCluster.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	if model == nil {
+		model = &ConfigModel{
+			OrganizationID: commonCluster.GetOrganizationId(),
+			ClusterID:      commonCluster.GetID(),
+		}
+	}
+
+	model.ClusterName = commonCluster.GetName()
+	model.UpdatedBy = userID
+
+	if err := model.SetConfig(config); err != nil {
+		return nil, errors.Wrap(err, "could not marshal logging config")
+	}
+
+	if err := s.repository.SaveConfig(model); err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(commonCluster, kubeConfig, *config, resolved); err != nil {
+		return nil, err
+	}
+
+	return convertModelToEntity(model, config), nil
+}
+
+// DeleteConfig deletes the logging config of a cluster and removes its outputs and flows from the logging release.
+func (s *Service) DeleteConfig(commonCluster cluster.CommonCluster) error {
+	if err := s.repository.DeleteConfig(commonCluster.GetID()); err != nil {
+		return err
+	}
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return errors.Wrap(err, "could not get k8s config")
+	}
+
+	err = s.apply(commonCluster, kubeConfig, pkgLogging.Config{}, nil)
+	if _, ok := errors.Cause(err).(*loggingNotInstalledError); ok {
+		return nil
+	}
+
+	return err
+}
+
+// Render returns the values rendered for the logging release of a cluster.
+// Credentials are not included, the values refer to the secrets installed for the outputs.
+func (s *Service) Render(commonCluster cluster.CommonCluster) (*pkgLogging.RenderedConfigResponse, error) {
+	model, err := s.repository.FindConfig(commonCluster.GetID())
+	if err != nil {
+		return nil, err
+	}
+
+	config := &pkgLogging.Config{}
+	if model != nil {
+		config, err = s.parseConfig(model)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resolved, err := s.resolveOutputs(commonCluster.GetOrganizationId(), *config)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := yaml.Marshal(renderValues(*config, resolved))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not marshal logging values")
+	}
+
+	return &pkgLogging.RenderedConfigResponse{
+		Values: string(values),
+	}, nil
+}
+
+// apply installs the output secrets and upgrades the logging release of a cluster with the outputs and the flows.
+func (s *Service) apply(
+	commonCluster cluster.CommonCluster,
+	kubeConfig []byte,
+	config pkgLogging.Config,
+	resolved map[string]resolvedOutput,
+) error {
+	logger := s.logger.WithFields(logrus.Fields{
+		"organization": commonCluster.GetOrganizationId(),
+		"cluster":      commonCluster.GetName(),
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deployment, err := getLoggingRelease(commonCluster.GetName(), kubeConfig)
+	if err != nil {
+		return err
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return errors.Wrap(err, "could not create k8s client")
+	}
+
+	if err := installOutputSecrets(client, deployment.Namespace, resolved); err != nil {
+		return emperror.With(err, "cluster", commonCluster.GetName())
+	}
+
+	err = cluster.UpgradeReleaseValues(commonCluster, deployment, renderValues(config, resolved), kubeConfig)
+	if err != nil {
+		return emperror.With(errors.WithMessage(err, "could not upgrade logging release"), "cluster", commonCluster.GetName())
+	}
+
+	logger.Info("logging config applied")
+
+	return nil
+}
+
+// resolveOutputs looks up the buckets and the credentials of the outputs of a config.
+func (s *Service) resolveOutputs(organizationID uint, config pkgLogging.Config) (map[string]resolvedOutput, error) {
+	resolved := make(map[string]resolvedOutput, len(config.Outputs))
+
+	for _, output := range config.Outputs {
+		var bucket *objectstore.BucketInfo
+		secretID := output.SecretID
+
+		if output.Type == pkgLogging.ObjectStoreOutput {
+			var err error
+			bucket, err = s.findBucket(organizationID, output.ObjectStore.Cloud, output.ObjectStore.Bucket)
+			if err != nil {
+				return nil, err
+			}
+
+			if bucket == nil {
+				return nil, errors.WithStack(&invalidConfigError{
+					errors.Errorf("bucket %q of output %q not found", output.ObjectStore.Bucket, output.Name),
+				})
+			}
+
+			if secretID == "" {
+				secretID = bucket.SecretRef
+				if bucket.Cloud == pkgProviders.Azure {
+					secretID = bucket.AccessSecretRef
+				}
+			}
+		}
+
+		if secretID == "" {
+			resolved[output.Name] = resolvedOutput{bucket: bucket}
+
+			continue
+		}
+
+		secretItem, err := s.secrets.Get(organizationID, secretID)
+		if errors.Cause(err) == secret.ErrSecretNotExists {
+			return nil, errors.WithStack(&invalidConfigError{
+				errors.Errorf("secret of output %q not found", output.Name),
+			})
+		} else if err != nil {
+			return nil, emperror.With(errors.Wrap(err, "could not get output secret"), "output", output.Name)
+		}
+
+		credentials, err := outputCredentials(output, bucket, secretItem.Type, secretItem.Values)
+		if err != nil {
+			return nil, errors.WithStack(&invalidConfigError{err})
+		}
+
+		resolved[output.Name] = resolvedOutput{bucket: bucket, credentials: credentials}
+	}
+
+	return resolved, nil
+}
+
+// findBucket returns a managed bucket of an organization, nil if the bucket does not exist.
+func (s *Service) findBucket(organizationID uint, cloud string, name string) (*objectstore.BucketInfo, error) {
+	organization, err := auth.GetOrganizationById(organizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get organization")
+	}
+
+	objectStore, err := providers.NewObjectStore(&providers.ObjectStoreContext{
+		Provider:     cloud,
+		Organization: organization,
+	}, s.logger)
+	if err != nil {
+		return nil, emperror.With(errors.WithMessage(err, "could not create object store"), "cloud", cloud)
+	}
+
+	buckets, err := objectStore.ListManagedBuckets()
+	if err != nil {
+		return nil, emperror.With(errors.WithMessage(err, "could not list buckets"), "cloud", cloud)
+	}
+
+	for _, bucket := range buckets {
+		if bucket.Name == name {
+			return bucket, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *Service) parseConfig(model *ConfigModel) (*pkgLogging.Config, error) {
+	config, err := model.GetConfig()
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not parse logging config"), "cluster", model.ClusterID)
+	}
+
+	return config, nil
+}
+
+func convertModelToEntity(model *ConfigModel, config *pkgLogging.Config) *pkgLogging.ConfigResponse {
+	updatedAt := model.UpdatedAt
+
+	return &pkgLogging.ConfigResponse{
+		Config:    *config,
+		UpdatedAt: &updatedAt,
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/pkg/errors"
+	"k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// restoreNameLabel is the label Ark puts on the restored resources
+	restoreNameLabel = "ark.heptio.com/restore-name"
+
+	claimDeletionTimeout = 2 * time.Minute
+	claimDeletionPoll    = 2 * time.Second
+)
+
+// claimBindingAnnotations are set by the persistent volume controller when a claim is bound.
+var claimBindingAnnotations = []string{
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"volume.beta.kubernetes.io/storage-provisioner",
+}
+
+// unboundClaim returns a copy of a claim without its volume, so that a new volume is provisioned for it.
+func unboundClaim(claim v1.PersistentVolumeClaim) *v1.PersistentVolumeClaim {
+	annotations := make(map[string]string, len(claim.Annotations))
+	for key, value := range claim.Annotations {
+		annotations[key] = value
+	}
+	for _, key := range claimBindingAnnotations {
+		delete(annotations, key)
+	}
+
+	spec := *claim.Spec.DeepCopy()
+	spec.VolumeName = ""
+
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        claim.Name,
+			Namespace:   claim.Namespace,
+			Labels:      claim.Labels,
+			Annotations: annotations,
+		},
+		Spec: spec,
+	}
+}
+
+// rebindClaims recreates the restored claims whose volumes are not migrated.
+// The volume of a claim cannot be changed, and the claims restored without their volumes are never bound.
+// Pods waiting for the claims are not scheduled, so the claims can be deleted.
+func rebindClaims(client kubernetes.Interface, restoreName string) ([]pkgCluster.MigrationResult, error) {
+	claims, err := client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: restoreNameLabel + "=" + restoreName,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list restored persistent volume claims")
+	}
+
+	var results []pkgCluster.MigrationResult
+
+	for _, claim := range claims.Items {
+		if claim.Spec.VolumeName == "" || claim.Status.Phase == v1.ClaimBound {
+			continue
+		}
+
+		claimClient := client.CoreV1().PersistentVolumeClaims(claim.Namespace)
+
+		err := claimClient.Delete(claim.Name, &metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return results, errors.Wrapf(err, "could not delete persistent volume claim %s/%s", claim.Namespace, claim.Name)
+		}
+
+		deadline := time.Now().Add(claimDeletionTimeout)
+		for {
+			_, err := claimClient.Get(claim.Name, metav1.GetOptions{})
+			if k8serrors.IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return results, errors.Wrapf(err, "could not get persistent volume claim %s/%s", claim.Namespace, claim.Name)
+			}
+			if time.Now().After(deadline) {
+				return results, errors.Errorf("timeout during waiting for persistent volume claim %s/%s to be deleted", claim.Namespace, claim.Name)
+			}
+
+			time.Sleep(claimDeletionPoll)
+		}
+
+		_, err = claimClient.Create(unboundClaim(claim))
+		if err != nil {
+			return results, errors.Wrapf(err, "could not recreate persistent volume claim %s/%s", claim.Namespace, claim.Name)
+		}
+
+		results = append(results, pkgCluster.MigrationResult{
+			Type:      pkgCluster.MigrationResultWarning,
+			Scope:     pkgCluster.MigrationScopeNamespace,
+			Namespace: claim.Namespace,
+			Message:   fmt.Sprintf("persistent volume claim %s is bound to a new volume, its data is not migrated", claim.Name),
+		})
+	}
+
+	return results, nil
+}
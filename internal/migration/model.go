@@ -0,0 +1,191 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	migrationsTableName = "cluster_migrations"
+)
+
+// MigrationModel describes the migration of the workloads of a cluster to another cluster.
+type MigrationModel struct {
+	ID             uint `gorm:"primary_key"`
+	OrganizationID uint `gorm:"index"`
+
+	SourceClusterID   uint
+	SourceClusterName string
+	TargetClusterID   uint
+	TargetClusterName string
+	TargetCreated     bool
+
+	Options         string `sql:"type:text;"`
+	VolumesMigrated bool
+	BackupName      string
+	RestoreName     string
+
+	Status         string
+	Message        string `sql:"type:text;"`
+	StorageClasses string `sql:"type:text;"`
+	Results        string `sql:"type:mediumtext;"`
+
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	FinishedAt *time.Time
+	CreatedBy  uint
+}
+
+// TableName changes the default table name.
+func (MigrationModel) TableName() string {
+	return migrationsTableName
+}
+
+// migrationOptions are the filters and the storage class mappings of a migration.
+type migrationOptions struct {
+	Namespaces           []string          `json:"namespaces,omitempty"`
+	Labels               map[string]string `json:"labels,omitempty"`
+	StorageClassMappings map[string]string `json:"storageClassMappings,omitempty"`
+}
+
+// GetOptions returns the filters and the storage class mappings of the migration.
+func (m *MigrationModel) GetOptions() migrationOptions {
+	var options migrationOptions
+
+	if m.Options != "" {
+		_ = json.Unmarshal([]byte(m.Options), &options)
+	}
+
+	return options
+}
+
+// SetOptions sets the filters and the storage class mappings of the migration.
+func (m *MigrationModel) SetOptions(options migrationOptions) error {
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal migration options")
+	}
+
+	m.Options = string(optionsJSON)
+
+	return nil
+}
+
+// GetStorageClasses returns how the storage classes of the source cluster are provided in the target cluster.
+func (m *MigrationModel) GetStorageClasses() []pkgCluster.MigrationStorageClass {
+	var storageClasses []pkgCluster.MigrationStorageClass
+
+	if m.StorageClasses != "" {
+		_ = json.Unmarshal([]byte(m.StorageClasses), &storageClasses)
+	}
+
+	return storageClasses
+}
+
+// SetStorageClasses sets how the storage classes of the source cluster are provided in the target cluster.
+func (m *MigrationModel) SetStorageClasses(storageClasses []pkgCluster.MigrationStorageClass) error {
+	storageClassesJSON, err := json.Marshal(storageClasses)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal migration storage classes")
+	}
+
+	m.StorageClasses = string(storageClassesJSON)
+
+	return nil
+}
+
+// GetResults returns the errors and the warnings of the migration.
+func (m *MigrationModel) GetResults() []pkgCluster.MigrationResult {
+	var results []pkgCluster.MigrationResult
+
+	if m.Results != "" {
+		_ = json.Unmarshal([]byte(m.Results), &results)
+	}
+
+	return results
+}
+
+// SetResults sets the errors and the warnings of the migration.
+func (m *MigrationModel) SetResults(results []pkgCluster.MigrationResult) error {
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal migration results")
+	}
+
+	m.Results = string(resultsJSON)
+
+	return nil
+}
+
+// ConvertModelToEntity converts a MigrationModel to a pkgCluster.MigrationResponse, the results are only included if requested.
+func (m *MigrationModel) ConvertModelToEntity(withResults bool) pkgCluster.MigrationResponse {
+	options := m.GetOptions()
+
+	response := pkgCluster.MigrationResponse{
+		ID:                   m.ID,
+		SourceClusterID:      m.SourceClusterID,
+		SourceClusterName:    m.SourceClusterName,
+		TargetClusterID:      m.TargetClusterID,
+		TargetClusterName:    m.TargetClusterName,
+		TargetCreated:        m.TargetCreated,
+		Namespaces:           options.Namespaces,
+		Labels:               options.Labels,
+		StorageClassMappings: options.StorageClassMappings,
+		VolumesMigrated:      m.VolumesMigrated,
+		BackupName:           m.BackupName,
+		RestoreName:          m.RestoreName,
+		Status:               m.Status,
+		Message:              m.Message,
+		CreatedAt:            m.CreatedAt,
+		UpdatedAt:            m.UpdatedAt,
+		FinishedAt:           m.FinishedAt,
+		CreatedBy:            m.CreatedBy,
+	}
+
+	if withResults {
+		response.StorageClasses = m.GetStorageClasses()
+		response.Results = m.GetResults()
+	}
+
+	return response
+}
+
+// Migrate executes the table migrations for the migration models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&MigrationModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating cluster migration tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,107 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the cluster migrations of the organizations.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+type migrationNotFoundError struct {
+	organizationID uint
+	id             uint
+}
+
+func (e *migrationNotFoundError) Error() string {
+	return "cluster migration not found"
+}
+
+func (e *migrationNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"organization", e.organizationID,
+		"migration", e.id,
+	}
+}
+
+func (e *migrationNotFoundError) NotFound() bool {
+	return true
+}
+
+// FindMigrations returns the cluster migrations of an organization, the latest first.
+func (r *Repository) FindMigrations(organizationID uint) ([]*MigrationModel, error) {
+	var migrations []*MigrationModel
+
+	err := r.db.Where(&MigrationModel{OrganizationID: organizationID}).Order("id DESC").Find(&migrations).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch cluster migrations"), "organization", organizationID)
+	}
+
+	return migrations, nil
+}
+
+// FindOneMigration returns a cluster migration of an organization.
+func (r *Repository) FindOneMigration(organizationID uint, id uint) (*MigrationModel, error) {
+	var migration MigrationModel
+
+	err := r.db.Where(&MigrationModel{ID: id, OrganizationID: organizationID}).First(&migration).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&migrationNotFoundError{
+			organizationID: organizationID,
+			id:             id,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get cluster migration"),
+			"organization", organizationID,
+			"migration", id,
+		)
+	}
+
+	return &migration, nil
+}
+
+// FindRunningMigrations returns the cluster migrations being executed.
+func (r *Repository) FindRunningMigrations() ([]*MigrationModel, error) {
+	var migrations []*MigrationModel
+
+	err := r.db.Where("status NOT IN (?)", []string{pkgCluster.MigrationSucceeded, pkgCluster.MigrationFailed}).Find(&migrations).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch running cluster migrations")
+	}
+
+	return migrations, nil
+}
+
+// SaveMigration persists the state of a cluster migration.
+func (r *Repository) SaveMigration(migration *MigrationModel) error {
+	err := r.db.Save(migration).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not save cluster migration"), "migration", migration.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"sort"
+
+	"github.com/banzaicloud/pipeline/internal/ark/api"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	arkAPI "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+// convertRestoreResults converts the errors and the warnings of a restore to migration results, errors first.
+func convertRestoreResults(results *api.RestoreResults) []pkgCluster.MigrationResult {
+	if results == nil {
+		return nil
+	}
+
+	var converted []pkgCluster.MigrationResult
+	converted = appendRestoreResult(converted, pkgCluster.MigrationResultError, results.Errors)
+	converted = appendRestoreResult(converted, pkgCluster.MigrationResultWarning, results.Warnings)
+
+	return converted
+}
+
+func appendRestoreResult(results []pkgCluster.MigrationResult, resultType string, result arkAPI.RestoreResult) []pkgCluster.MigrationResult {
+	for _, message := range result.Ark {
+		results = append(results, pkgCluster.MigrationResult{
+			Type:    resultType,
+			Scope:   pkgCluster.MigrationScopeBackupService,
+			Message: message,
+		})
+	}
+
+	for _, message := range result.Cluster {
+		results = append(results, pkgCluster.MigrationResult{
+			Type:    resultType,
+			Scope:   pkgCluster.MigrationScopeCluster,
+			Message: message,
+		})
+	}
+
+	namespaces := make([]string, 0, len(result.Namespaces))
+	for namespace := range result.Namespaces {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		for _, message := range result.Namespaces[namespace] {
+			results = append(results, pkgCluster.MigrationResult{
+				Type:      resultType,
+				Scope:     pkgCluster.MigrationScopeNamespace,
+				Namespace: namespace,
+				Message:   message,
+			})
+		}
+	}
+
+	return results
+}
+
+// countResults returns the number of errors and warnings of a migration.
+func countResults(results []pkgCluster.MigrationResult) (errorCount int, warningCount int) {
+	for _, result := range results {
+		switch result.Type {
+		case pkgCluster.MigrationResultError:
+			errorCount++
+		case pkgCluster.MigrationResultWarning:
+			warningCount++
+		}
+	}
+
+	return errorCount, warningCount
+}
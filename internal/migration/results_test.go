@@ -0,0 +1,79 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/internal/ark/api"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	arkAPI "github.com/heptio/ark/pkg/apis/ark/v1"
+)
+
+func TestConvertRestoreResults(t *testing.T) {
+	results := convertRestoreResults(&api.RestoreResults{
+		Errors: arkAPI.RestoreResult{
+			Namespaces: map[string][]string{
+				"web": {"error restoring services/web/frontend"},
+				"db":  {"error restoring statefulsets.apps/db/mysql"},
+			},
+		},
+		Warnings: arkAPI.RestoreResult{
+			Ark:     []string{"backup storage location not found"},
+			Cluster: []string{"could not restore, persistentvolumes \"pv-1\" already exists"},
+		},
+	})
+
+	expected := []pkgCluster.MigrationResult{
+		{
+			Type:      pkgCluster.MigrationResultError,
+			Scope:     pkgCluster.MigrationScopeNamespace,
+			Namespace: "db",
+			Message:   "error restoring statefulsets.apps/db/mysql",
+		},
+		{
+			Type:      pkgCluster.MigrationResultError,
+			Scope:     pkgCluster.MigrationScopeNamespace,
+			Namespace: "web",
+			Message:   "error restoring services/web/frontend",
+		},
+		{
+			Type:    pkgCluster.MigrationResultWarning,
+			Scope:   pkgCluster.MigrationScopeBackupService,
+			Message: "backup storage location not found",
+		},
+		{
+			Type:    pkgCluster.MigrationResultWarning,
+			Scope:   pkgCluster.MigrationScopeCluster,
+			Message: "could not restore, persistentvolumes \"pv-1\" already exists",
+		},
+	}
+
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("unexpected results\nexpected: %+v\ngot:      %+v", expected, results)
+	}
+
+	errorCount, warningCount := countResults(results)
+	if errorCount != 2 || warningCount != 2 {
+		t.Errorf("expected 2 errors and 2 warnings, got %d errors and %d warnings", errorCount, warningCount)
+	}
+}
+
+func TestConvertRestoreResults_Nil(t *testing.T) {
+	if results := convertRestoreResults(nil); results != nil {
+		t.Errorf("expected no results, got %+v", results)
+	}
+}
@@ -0,0 +1,767 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/banzaicloud/pipeline/config"
+	"github.com/banzaicloud/pipeline/internal/ark"
+	"github.com/banzaicloud/pipeline/internal/ark/api"
+	intAuth "github.com/banzaicloud/pipeline/internal/auth"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	"github.com/banzaicloud/pipeline/pkg/providers"
+	"github.com/goph/emperror"
+	arkAPI "github.com/heptio/ark/pkg/apis/ark/v1"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// migrationLabel marks the backups, the restores and the storage classes created by a migration
+	migrationLabel = "pipeline-migration"
+
+	pollInterval   = 15 * time.Second
+	clusterTimeout = time.Hour
+	backupTimeout  = time.Hour
+	restoreTimeout = time.Hour
+
+	backupTTL = 72 * time.Hour
+)
+
+type invalidMigrationError struct {
+	message string
+}
+
+func (e *invalidMigrationError) Error() string {
+	return e.message
+}
+
+func (e *invalidMigrationError) IsInvalid() bool {
+	return true
+}
+
+type migrationConflictError struct {
+	message string
+}
+
+func (e *migrationConflictError) Error() string {
+	return e.message
+}
+
+func (e *migrationConflictError) Conflict() bool {
+	return true
+}
+
+type clusterManager interface {
+	GetClusterByID(ctx context.Context, organizationID uint, clusterID uint) (cluster.CommonCluster, error)
+}
+
+type clusterCreator interface {
+	CreateClusterFromRequest(ctx context.Context, req *pkgCluster.CreateClusterRequest, organizationID uint, userID uint) (cluster.CommonCluster, error)
+}
+
+// Service migrates the workloads of a cluster to another cluster through a backup and a restore.
+type Service struct {
+	repository   *Repository
+	clusters     clusterManager
+	creator      clusterCreator
+	db           *gorm.DB
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(
+	repository *Repository,
+	clusters clusterManager,
+	creator clusterCreator,
+	db *gorm.DB,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Service {
+	return &Service{
+		repository:   repository,
+		clusters:     clusters,
+		creator:      creator,
+		db:           db,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// ListMigrations returns the cluster migrations of an organization.
+// Tokens restricted to clusters only see the migrations between their clusters.
+func (s *Service) ListMigrations(ctx context.Context, organizationID uint) ([]*MigrationModel, error) {
+	migrations, err := s.repository.FindMigrations(organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make([]*MigrationModel, 0, len(migrations))
+	for _, migration := range migrations {
+		if checkClustersAllowed(ctx, migration.SourceClusterID, migration.TargetClusterID) == nil {
+			allowed = append(allowed, migration)
+		}
+	}
+
+	return allowed, nil
+}
+
+// GetMigration returns a cluster migration of an organization.
+func (s *Service) GetMigration(ctx context.Context, organizationID uint, id uint) (*MigrationModel, error) {
+	migration, err := s.repository.FindOneMigration(organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkClustersAllowed(ctx, migration.SourceClusterID, migration.TargetClusterID); err != nil {
+		return nil, err
+	}
+
+	return migration, nil
+}
+
+// checkClustersAllowed makes sure that the token of the request can access every cluster of a migration.
+// New target clusters cannot be among the clusters of a restricted token.
+func checkClustersAllowed(ctx context.Context, clusterIDs ...uint) error {
+	for _, clusterID := range clusterIDs {
+		if !intAuth.IsClusterAllowed(ctx, clusterID) {
+			return errors.WithStack(&intAuth.ClusterForbiddenError{ClusterID: clusterID})
+		}
+	}
+
+	return nil
+}
+
+// CreateMigration validates a migration, creates the target cluster if requested and starts migrating in the background.
+func (s *Service) CreateMigration(ctx context.Context, organizationID uint, req *pkgCluster.CreateMigrationRequest, userID uint) (*MigrationModel, error) {
+	options, err := validateRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkClustersAllowed(ctx, req.SourceClusterID, req.Target.ClusterID); err != nil {
+		return nil, err
+	}
+
+	organization, err := auth.GetOrganizationById(organizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get organization")
+	}
+
+	source, err := s.getRunningCluster(ctx, organizationID, req.SourceClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ark.DeploymentsServiceFactory(organization, source, s.db, s.logger).GetActiveDeployment()
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&invalidMigrationError{"backup service is not enabled on the source cluster"})
+	} else if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get backup service of the source cluster"), "cluster", source.GetID())
+	}
+
+	if err := s.checkRunningMigrations(organizationID, req.SourceClusterID, req.Target.ClusterID); err != nil {
+		return nil, err
+	}
+
+	migration := &MigrationModel{
+		OrganizationID:    organizationID,
+		SourceClusterID:   source.GetID(),
+		SourceClusterName: source.GetName(),
+		Status:            pkgCluster.MigrationPending,
+		CreatedBy:         userID,
+	}
+
+	if err := migration.SetOptions(options); err != nil {
+		return nil, err
+	}
+
+	var target cluster.CommonCluster
+	if req.Target.ClusterID != 0 {
+		target, err = s.getRunningCluster(ctx, organizationID, req.Target.ClusterID)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = ark.DeploymentsServiceFactory(organization, target, s.db, s.logger).GetActiveDeployment()
+		if err == nil {
+			return nil, errors.WithStack(&migrationConflictError{"backup service is enabled on the target cluster"})
+		} else if !gorm.IsRecordNotFoundError(err) {
+			return nil, emperror.With(errors.Wrap(err, "could not get backup service of the target cluster"), "cluster", target.GetID())
+		}
+	} else {
+		target, err = s.creator.CreateClusterFromRequest(ctx, req.Target.Cluster, organizationID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		migration.TargetCreated = true
+		migration.Status = pkgCluster.MigrationCreatingCluster
+	}
+
+	migration.TargetClusterID = target.GetID()
+	migration.TargetClusterName = target.GetName()
+	migration.VolumesMigrated = migrateVolumes(source, target)
+
+	if err := s.repository.SaveMigration(migration); err != nil {
+		return nil, err
+	}
+
+	go s.run(migration.OrganizationID, migration.ID)
+
+	return migration, nil
+}
+
+// validateRequest checks a migration request and returns the options of the migration.
+func validateRequest(req *pkgCluster.CreateMigrationRequest) (migrationOptions, error) {
+	options := migrationOptions{
+		Namespaces:           req.Namespaces,
+		Labels:               req.Labels,
+		StorageClassMappings: req.StorageClassMappings,
+	}
+
+	if (req.Target.ClusterID == 0) == (req.Target.Cluster == nil) {
+		return options, errors.WithStack(&invalidMigrationError{"either the ID or the spec of the target cluster must be set"})
+	}
+
+	if req.Target.ClusterID == req.SourceClusterID {
+		return options, errors.WithStack(&invalidMigrationError{"source and target clusters must differ"})
+	}
+
+	for _, namespace := range req.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return options, errors.WithStack(&invalidMigrationError{fmt.Sprintf("invalid namespace %q: %s", namespace, strings.Join(errs, "; "))})
+		}
+
+		if isExcludedNamespace(namespace) {
+			return options, errors.WithStack(&invalidMigrationError{fmt.Sprintf("namespace %q cannot be migrated", namespace)})
+		}
+	}
+
+	for key, value := range req.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return options, errors.WithStack(&invalidMigrationError{fmt.Sprintf("invalid label key %q: %s", key, strings.Join(errs, "; "))})
+		}
+
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return options, errors.WithStack(&invalidMigrationError{fmt.Sprintf("invalid value of label %q: %s", key, strings.Join(errs, "; "))})
+		}
+	}
+
+	for source, target := range req.StorageClassMappings {
+		for _, name := range []string{source, target} {
+			if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+				return options, errors.WithStack(&invalidMigrationError{fmt.Sprintf("invalid storage class name %q: %s", name, strings.Join(errs, "; "))})
+			}
+		}
+	}
+
+	return options, nil
+}
+
+// getRunningCluster returns a cluster of an organization if it is running.
+func (s *Service) getRunningCluster(ctx context.Context, organizationID uint, clusterID uint) (cluster.CommonCluster, error) {
+	commonCluster, err := s.clusters.GetClusterByID(ctx, organizationID, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := commonCluster.GetStatus()
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get cluster status"), "cluster", clusterID)
+	}
+
+	if status.Status != pkgCluster.Running {
+		return nil, errors.WithStack(&invalidMigrationError{fmt.Sprintf("cluster %s is not running", commonCluster.GetName())})
+	}
+
+	return commonCluster, nil
+}
+
+// checkRunningMigrations makes sure that the clusters are not part of another migration being executed.
+func (s *Service) checkRunningMigrations(organizationID uint, clusterIDs ...uint) error {
+	migrations, err := s.repository.FindRunningMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.OrganizationID != organizationID {
+			continue
+		}
+
+		for _, clusterID := range clusterIDs {
+			if clusterID != 0 && (clusterID == migration.SourceClusterID || clusterID == migration.TargetClusterID) {
+				return errors.WithStack(&migrationConflictError{fmt.Sprintf("cluster %d is being migrated", clusterID)})
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateVolumes tells whether the persistent volumes can be migrated through snapshots.
+// Snapshots can only be restored in the same cloud and location by the volume providers of Ark.
+func migrateVolumes(source cluster.CommonCluster, target cluster.CommonCluster) bool {
+	switch source.GetCloud() {
+	case providers.Amazon, providers.Azure, providers.Google:
+	default:
+		return false
+	}
+
+	return source.GetCloud() == target.GetCloud() && source.GetLocation() == target.GetLocation()
+}
+
+// excludedNamespaces are never migrated, they are managed by the clusters and by Pipeline.
+func excludedNamespaces() []string {
+	return []string{
+		metav1.NamespaceSystem,
+		metav1.NamespacePublic,
+		viper.GetString(config.PipelineSystemNamespace),
+		viper.GetString(config.ARKNamespace),
+	}
+}
+
+func isExcludedNamespace(namespace string) bool {
+	for _, excluded := range excludedNamespaces() {
+		if namespace == excluded {
+			return true
+		}
+	}
+
+	return false
+}
+
+func labelSelector(matchLabels map[string]string) *metav1.LabelSelector {
+	if len(matchLabels) == 0 {
+		return nil
+	}
+
+	return &metav1.LabelSelector{MatchLabels: matchLabels}
+}
+
+// run executes a cluster migration and records its result.
+func (s *Service) run(organizationID uint, id uint) {
+	migration, err := s.repository.FindOneMigration(organizationID, id)
+	if err != nil {
+		s.errorHandler.Handle(errors.Wrap(err, "could not start cluster migration"))
+		return
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"organization": migration.OrganizationID,
+		"migration":    migration.ID,
+		"source":       migration.SourceClusterID,
+		"target":       migration.TargetClusterID,
+	})
+
+	logger.Info("migrating cluster")
+
+	err = s.migrate(migration, logger)
+
+	now := time.Now()
+	migration.FinishedAt = &now
+
+	if err != nil {
+		migration.Status = pkgCluster.MigrationFailed
+		migration.Message = err.Error()
+
+		logger.WithError(err).Warn("cluster migration failed")
+	} else {
+		logger.Info("cluster migrated")
+	}
+
+	if err := s.repository.SaveMigration(migration); err != nil {
+		s.errorHandler.Handle(err)
+	}
+}
+
+// saveStatus persists the progress of a migration, the migration continues on errors.
+func (s *Service) saveStatus(migration *MigrationModel, status string) {
+	migration.Status = status
+
+	if err := s.repository.SaveMigration(migration); err != nil {
+		s.errorHandler.Handle(err)
+	}
+}
+
+// migrate backs up the source cluster and restores the backup in the target cluster.
+func (s *Service) migrate(migration *MigrationModel, logger logrus.FieldLogger) error {
+	ctx := context.Background()
+
+	organization, err := auth.GetOrganizationById(migration.OrganizationID)
+	if err != nil {
+		return errors.Wrap(err, "could not get organization")
+	}
+
+	if migration.Status == pkgCluster.MigrationCreatingCluster {
+		logger.Info("waiting for the target cluster to be created")
+
+		if err := s.waitForCluster(ctx, migration.OrganizationID, migration.TargetClusterID); err != nil {
+			return err
+		}
+	}
+
+	source, err := s.clusters.GetClusterByID(ctx, migration.OrganizationID, migration.SourceClusterID)
+	if err != nil {
+		return errors.Wrap(err, "could not get source cluster")
+	}
+
+	target, err := s.clusters.GetClusterByID(ctx, migration.OrganizationID, migration.TargetClusterID)
+	if err != nil {
+		return errors.Wrap(err, "could not get target cluster")
+	}
+
+	sourceClient, err := newClient(source)
+	if err != nil {
+		return errors.Wrap(err, "could not create client for the source cluster")
+	}
+
+	targetClient, err := newClient(target)
+	if err != nil {
+		return errors.Wrap(err, "could not create client for the target cluster")
+	}
+
+	options := migration.GetOptions()
+	migrationLabels := map[string]string{
+		migrationLabel: strconv.FormatUint(uint64(migration.ID), 10),
+	}
+
+	logger.Info("providing storage classes in the target cluster")
+
+	storageClasses, err := applyStorageClasses(sourceClient, targetClient, options, migrationLabels)
+	if err != nil {
+		return err
+	}
+
+	if err := migration.SetStorageClasses(storageClasses); err != nil {
+		return err
+	}
+
+	migration.BackupName = fmt.Sprintf("migration-%d-%s", migration.ID, time.Now().Format("20060102150405"))
+	s.saveStatus(migration, pkgCluster.MigrationBackingUp)
+
+	logger.WithField("backup", migration.BackupName).Info("backing up source cluster")
+
+	backup, err := s.backup(organization, source, migration, options, migrationLabels, logger)
+	if err != nil {
+		return err
+	}
+
+	s.saveStatus(migration, pkgCluster.MigrationRestoring)
+
+	logger.Info("restoring backup in the target cluster")
+
+	results, err := s.restore(organization, target, backup, migration, options, migrationLabels, logger)
+	if err != nil {
+		return err
+	}
+
+	if !migration.VolumesMigrated {
+		rebound, err := rebindClaims(targetClient, migration.RestoreName)
+		results = append(results, rebound...)
+		if err != nil {
+			_ = migration.SetResults(results)
+			return err
+		}
+	}
+
+	if err := migration.SetResults(results); err != nil {
+		return err
+	}
+
+	migration.Status = pkgCluster.MigrationSucceeded
+
+	if errorCount, warningCount := countResults(results); errorCount > 0 || warningCount > 0 {
+		migration.Message = fmt.Sprintf("migration finished with %d errors and %d warnings", errorCount, warningCount)
+	}
+
+	return nil
+}
+
+// waitForCluster waits until a cluster being created is running.
+func (s *Service) waitForCluster(ctx context.Context, organizationID uint, clusterID uint) error {
+	deadline := time.Now().Add(clusterTimeout)
+
+	for {
+		commonCluster, err := s.clusters.GetClusterByID(ctx, organizationID, clusterID)
+		if err != nil {
+			return errors.Wrap(err, "could not get target cluster")
+		}
+
+		status, err := commonCluster.GetStatus()
+		if err != nil {
+			return errors.Wrap(err, "could not get target cluster status")
+		}
+
+		switch status.Status {
+		case pkgCluster.Running:
+			return nil
+		case pkgCluster.Error:
+			return errors.Errorf("target cluster creation failed: %s", status.StatusMessage)
+		}
+
+		if time.Now().After(deadline) {
+			return errors.New("timeout during waiting for the target cluster to be created")
+		}
+
+		time.Sleep(pollInterval)
+	}
+}
+
+// backup creates a backup of the source cluster and waits for it to complete.
+func (s *Service) backup(
+	organization *auth.Organization,
+	source cluster.CommonCluster,
+	migration *MigrationModel,
+	options migrationOptions,
+	migrationLabels map[string]string,
+	logger logrus.FieldLogger,
+) (*ark.ClusterBackupsModel, error) {
+	svc := ark.NewARKService(organization, source, s.db, logger)
+
+	err := svc.GetClusterBackupsService().Create(api.CreateBackupRequest{
+		Name:   migration.BackupName,
+		TTL:    metav1.Duration{Duration: backupTTL},
+		Labels: labels.Merge(nil, migrationLabels),
+		Options: api.BackupOptions{
+			IncludedNamespaces: options.Namespaces,
+			ExcludedNamespaces: excludedNamespaces(),
+			LabelSelector:      labelSelector(options.Labels),
+			SnapshotVolumes:    &migration.VolumesMigrated,
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create backup")
+	}
+
+	client, err := svc.GetDeploymentsService().GetClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get backup service client of the source cluster")
+	}
+
+	deadline := time.Now().Add(backupTimeout)
+
+	for {
+		backup, err := client.GetBackupByName(migration.BackupName)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "could not get backup")
+		}
+
+		if err == nil {
+			switch backup.Status.Phase {
+			case arkAPI.BackupPhaseCompleted:
+				model, err := svc.GetBackupsService().GetModelByName(migration.BackupName)
+				if err != nil {
+					return nil, err
+				}
+
+				return model, nil
+
+			case arkAPI.BackupPhaseFailed, arkAPI.BackupPhaseFailedValidation:
+				return nil, errors.Errorf("backup failed: %s", phaseMessage(string(backup.Status.Phase), backup.Status.ValidationErrors))
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("timeout during waiting for the backup to complete")
+		}
+
+		time.Sleep(pollInterval)
+	}
+}
+
+// restore deploys Ark in restore mode in the target cluster, restores the backup and returns the results of the restore.
+// Ark is removed from the target cluster when the restore finishes.
+func (s *Service) restore(
+	organization *auth.Organization,
+	target cluster.CommonCluster,
+	backup *ark.ClusterBackupsModel,
+	migration *MigrationModel,
+	options migrationOptions,
+	migrationLabels map[string]string,
+	logger logrus.FieldLogger,
+) ([]pkgCluster.MigrationResult, error) {
+	svc := ark.NewARKService(organization, target, s.db, logger)
+	deployments := svc.GetDeploymentsService()
+
+	if err := deployments.Deploy(&backup.Bucket, true); err != nil {
+		return nil, errors.Wrap(err, "could not deploy backup service to the target cluster")
+	}
+
+	defer func() {
+		if err := deployments.Remove(); err != nil {
+			s.errorHandler.Handle(emperror.With(
+				errors.Wrap(err, "could not remove backup service from the target cluster"),
+				"migration", migration.ID,
+				"cluster", target.GetID(),
+			))
+		}
+	}()
+
+	restoreOptions := api.RestoreOptions{
+		IncludedNamespaces: options.Namespaces,
+		ExcludedNamespaces: excludedNamespaces(),
+		LabelSelector:      labelSelector(options.Labels),
+		RestorePVs:         &migration.VolumesMigrated,
+	}
+
+	// volumes are provisioned again for the claims if the snapshots cannot be restored
+	if !migration.VolumesMigrated {
+		restoreOptions.ExcludedResources = []string{"persistentvolumes"}
+	}
+
+	restore, err := svc.GetRestoresService().Create(api.CreateRestoreRequest{
+		BackupName: backup.Name,
+		Labels:     labels.Merge(nil, migrationLabels),
+		Options:    restoreOptions,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create restore")
+	}
+
+	migration.RestoreName = restore.Name
+	s.saveStatus(migration, migration.Status)
+
+	client, err := deployments.GetClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get backup service client of the target cluster")
+	}
+
+	deadline := time.Now().Add(restoreTimeout)
+
+	for {
+		arkRestore, err := client.GetRestoreByName(restore.Name)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return nil, errors.Wrap(err, "could not get restore")
+		}
+
+		if err == nil {
+			switch arkRestore.Status.Phase {
+			case arkAPI.RestorePhaseCompleted:
+				return s.restoreResults(svc, backup, arkRestore, target, logger), nil
+
+			case arkAPI.RestorePhaseFailedValidation:
+				return nil, errors.Errorf("restore failed: %s", phaseMessage(string(arkRestore.Status.Phase), arkRestore.Status.ValidationErrors))
+			}
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("timeout during waiting for the restore to complete")
+		}
+
+		time.Sleep(pollInterval)
+	}
+}
+
+// restoreResults persists a completed restore with its results and converts the results to migration results.
+func (s *Service) restoreResults(
+	svc *ark.Service,
+	backup *ark.ClusterBackupsModel,
+	restore *arkAPI.Restore,
+	target cluster.CommonCluster,
+	logger logrus.FieldLogger,
+) []pkgCluster.MigrationResult {
+	req := &api.PersistRestoreRequest{
+		BucketID:  backup.BucketID,
+		ClusterID: target.GetID(),
+		Restore:   restore,
+	}
+
+	var results []pkgCluster.MigrationResult
+
+	buf := new(bytes.Buffer)
+	err := svc.GetBucketsService().StreamRestoreResultsFromObjectStore(backup.Bucket.ConvertModelToEntity(), backup.Name, restore.Name, buf)
+	if err == nil {
+		var restoreResults api.RestoreResults
+		err = json.Unmarshal(buf.Bytes(), &restoreResults)
+		if err == nil {
+			req.Results = &restoreResults
+			results = convertRestoreResults(&restoreResults)
+		}
+	}
+	if err != nil {
+		logger.WithError(err).Warn("could not get restore results")
+
+		results = append(results, pkgCluster.MigrationResult{
+			Type:    pkgCluster.MigrationResultWarning,
+			Scope:   pkgCluster.MigrationScopeBackupService,
+			Message: "could not get restore results: " + err.Error(),
+		})
+	}
+
+	if _, err := svc.GetRestoresService().Persist(req); err != nil {
+		s.errorHandler.Handle(emperror.With(errors.Wrap(err, "could not persist restore"), "restore", restore.Name))
+	}
+
+	return results
+}
+
+func phaseMessage(phase string, validationErrors []string) string {
+	if len(validationErrors) == 0 {
+		return phase
+	}
+
+	return phase + ": " + strings.Join(validationErrors, ", ")
+}
+
+func newClient(commonCluster cluster.CommonCluster) (kubernetes.Interface, error) {
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get k8s config")
+	}
+
+	return k8sclient.NewClientFromKubeConfig(kubeConfig)
+}
+
+// FailInterruptedMigrations marks the migrations left running by a previous process as failed.
+func (s *Service) FailInterruptedMigrations() error {
+	migrations, err := s.repository.FindRunningMigrations()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		now := time.Now()
+
+		migration.Status = pkgCluster.MigrationFailed
+		migration.Message = "migration was interrupted"
+		migration.FinishedAt = &now
+
+		if err := s.repository.SaveMigration(migration); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"fmt"
+	"sort"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/pkg/errors"
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	storageUtil "k8s.io/kubernetes/pkg/apis/storage/util"
+)
+
+// betaStorageClassAnnotation is the storage class of claims created before the storageClassName field
+const betaStorageClassAnnotation = "volume.beta.kubernetes.io/storage-class"
+
+// claimStorageClass returns the name of the storage class of a claim, empty if the claim has no class.
+func claimStorageClass(claim v1.PersistentVolumeClaim) string {
+	if class, ok := claim.Annotations[betaStorageClassAnnotation]; ok {
+		return class
+	}
+
+	if claim.Spec.StorageClassName != nil {
+		return *claim.Spec.StorageClassName
+	}
+
+	return ""
+}
+
+// isDefaultStorageClass tells whether a storage class is the default storage class of a cluster.
+func isDefaultStorageClass(class storagev1.StorageClass) bool {
+	return class.Annotations[storageUtil.IsDefaultStorageClassAnnotation] == "true" ||
+		class.Annotations[storageUtil.BetaIsDefaultStorageClassAnnotation] == "true"
+}
+
+// planStorageClasses decides how the storage classes of the migrated claims are provided in the target cluster.
+// Ark restores the claims with their original storage class, so a class missing from the target cluster is
+// created under the same name as a copy of the mapped target class, or of the default class if not mapped.
+func planStorageClasses(
+	claims []v1.PersistentVolumeClaim,
+	targetClasses []storagev1.StorageClass,
+	mappings map[string]string,
+) ([]pkgCluster.MigrationStorageClass, []storagev1.StorageClass, error) {
+	targetClassesByName := make(map[string]storagev1.StorageClass, len(targetClasses))
+	var defaultClass string
+	for _, class := range targetClasses {
+		targetClassesByName[class.Name] = class
+
+		if isDefaultStorageClass(class) {
+			defaultClass = class.Name
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, claim := range claims {
+		if class := claimStorageClass(claim); class != "" {
+			used[class] = true
+		}
+	}
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var plan []pkgCluster.MigrationStorageClass
+	var aliases []storagev1.StorageClass
+
+	for _, name := range names {
+		target, mapped := mappings[name]
+
+		if _, ok := targetClassesByName[name]; ok {
+			item := pkgCluster.MigrationStorageClass{
+				Source: name,
+				Target: name,
+				Action: pkgCluster.MigrationStorageClassExisting,
+			}
+
+			if mapped && target != name {
+				item.Message = fmt.Sprintf("storage class exists in the target cluster, mapping to %s is ignored", target)
+			}
+
+			plan = append(plan, item)
+
+			continue
+		}
+
+		if !mapped {
+			target = defaultClass
+		}
+
+		if target == "" {
+			plan = append(plan, pkgCluster.MigrationStorageClass{
+				Source:  name,
+				Action:  pkgCluster.MigrationStorageClassMissing,
+				Message: "no default storage class in the target cluster, volumes of the class are not provisioned",
+			})
+
+			continue
+		}
+
+		class, ok := targetClassesByName[target]
+		if !ok {
+			return nil, nil, errors.WithStack(&invalidMigrationError{
+				fmt.Sprintf("storage class %s mapped from %s not found in the target cluster", target, name),
+			})
+		}
+
+		plan = append(plan, pkgCluster.MigrationStorageClass{
+			Source: name,
+			Target: target,
+			Action: pkgCluster.MigrationStorageClassCreated,
+		})
+
+		aliases = append(aliases, aliasStorageClass(name, class))
+	}
+
+	return plan, aliases, nil
+}
+
+// aliasStorageClass returns a copy of a storage class under another name.
+func aliasStorageClass(name string, class storagev1.StorageClass) storagev1.StorageClass {
+	classLabels := make(map[string]string, len(class.Labels))
+	for key, value := range class.Labels {
+		classLabels[key] = value
+	}
+
+	return storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: classLabels,
+		},
+		Provisioner:          class.Provisioner,
+		Parameters:           class.Parameters,
+		ReclaimPolicy:        class.ReclaimPolicy,
+		MountOptions:         class.MountOptions,
+		AllowVolumeExpansion: class.AllowVolumeExpansion,
+		VolumeBindingMode:    class.VolumeBindingMode,
+		AllowedTopologies:    class.AllowedTopologies,
+	}
+}
+
+// listClaims returns the persistent volume claims of a cluster matching the filters of a migration.
+func listClaims(client kubernetes.Interface, options migrationOptions) ([]v1.PersistentVolumeClaim, error) {
+	claims, err := client.CoreV1().PersistentVolumeClaims(metav1.NamespaceAll).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(options.Labels).String(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list persistent volume claims")
+	}
+
+	included := make(map[string]bool, len(options.Namespaces))
+	for _, namespace := range options.Namespaces {
+		included[namespace] = true
+	}
+
+	var result []v1.PersistentVolumeClaim
+	for _, claim := range claims.Items {
+		if len(included) > 0 && !included[claim.Namespace] {
+			continue
+		}
+
+		if isExcludedNamespace(claim.Namespace) {
+			continue
+		}
+
+		result = append(result, claim)
+	}
+
+	return result, nil
+}
+
+// applyStorageClasses provides the storage classes of the migrated claims in the target cluster.
+func applyStorageClasses(
+	source kubernetes.Interface,
+	target kubernetes.Interface,
+	options migrationOptions,
+	migrationLabels map[string]string,
+) ([]pkgCluster.MigrationStorageClass, error) {
+	claims, err := listClaims(source, options)
+	if err != nil {
+		return nil, err
+	}
+
+	targetClasses, err := target.StorageV1().StorageClasses().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list storage classes of the target cluster")
+	}
+
+	plan, aliases, err := planStorageClasses(claims, targetClasses.Items, options.StorageClassMappings)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, alias := range aliases {
+		alias := alias
+
+		for key, value := range migrationLabels {
+			alias.Labels[key] = value
+		}
+
+		_, err := target.StorageV1().StorageClasses().Create(&alias)
+		if k8serrors.IsAlreadyExists(err) {
+			for i := range plan {
+				if plan[i].Source == alias.Name {
+					plan[i].Action = pkgCluster.MigrationStorageClassExisting
+					plan[i].Target = alias.Name
+				}
+			}
+
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not create storage class %s", alias.Name)
+		}
+	}
+
+	return plan, nil
+}
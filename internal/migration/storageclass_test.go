@@ -0,0 +1,171 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"reflect"
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/pkg/errors"
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	storageUtil "k8s.io/kubernetes/pkg/apis/storage/util"
+)
+
+func newClaim(namespace string, name string, class string) v1.PersistentVolumeClaim {
+	return v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: v1.PersistentVolumeClaimSpec{
+			StorageClassName: &class,
+		},
+	}
+}
+
+func newStorageClass(name string, provisioner string, isDefault bool) storagev1.StorageClass {
+	class := storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: name},
+		Provisioner: provisioner,
+		Parameters:  map[string]string{"type": name},
+	}
+
+	if isDefault {
+		class.Annotations = map[string]string{storageUtil.IsDefaultStorageClassAnnotation: "true"}
+	}
+
+	return class
+}
+
+func TestClaimStorageClass(t *testing.T) {
+	claim := newClaim("default", "data", "standard")
+	if class := claimStorageClass(claim); class != "standard" {
+		t.Errorf("expected storage class standard, got %q", class)
+	}
+
+	claim.Annotations = map[string]string{betaStorageClassAnnotation: "gp2"}
+	if class := claimStorageClass(claim); class != "gp2" {
+		t.Errorf("expected storage class of the beta annotation gp2, got %q", class)
+	}
+
+	if class := claimStorageClass(v1.PersistentVolumeClaim{}); class != "" {
+		t.Errorf("expected no storage class, got %q", class)
+	}
+}
+
+func TestPlanStorageClasses(t *testing.T) {
+	claims := []v1.PersistentVolumeClaim{
+		newClaim("default", "data", "standard"),
+		newClaim("default", "logs", "standard"),
+		newClaim("db", "data", "ssd"),
+		newClaim("db", "backup", "slow"),
+		newClaim("web", "cache", ""),
+	}
+
+	targetClasses := []storagev1.StorageClass{
+		newStorageClass("gp2", "kubernetes.io/aws-ebs", true),
+		newStorageClass("io1", "kubernetes.io/aws-ebs", false),
+		newStorageClass("slow", "kubernetes.io/aws-ebs", false),
+	}
+
+	plan, aliases, err := planStorageClasses(claims, targetClasses, map[string]string{
+		"ssd":  "io1",
+		"slow": "gp2",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedPlan := []pkgCluster.MigrationStorageClass{
+		{
+			Source:  "slow",
+			Target:  "slow",
+			Action:  pkgCluster.MigrationStorageClassExisting,
+			Message: "storage class exists in the target cluster, mapping to gp2 is ignored",
+		},
+		{
+			Source: "ssd",
+			Target: "io1",
+			Action: pkgCluster.MigrationStorageClassCreated,
+		},
+		{
+			Source: "standard",
+			Target: "gp2",
+			Action: pkgCluster.MigrationStorageClassCreated,
+		},
+	}
+
+	if !reflect.DeepEqual(plan, expectedPlan) {
+		t.Errorf("unexpected plan\nexpected: %+v\ngot:      %+v", expectedPlan, plan)
+	}
+
+	if len(aliases) != 2 {
+		t.Fatalf("expected 2 storage classes to create, got %d", len(aliases))
+	}
+
+	for i, expected := range []struct {
+		name      string
+		classType string
+	}{
+		{name: "ssd", classType: "io1"},
+		{name: "standard", classType: "gp2"},
+	} {
+		alias := aliases[i]
+
+		if alias.Name != expected.name {
+			t.Errorf("expected storage class %s, got %s", expected.name, alias.Name)
+		}
+
+		if alias.Provisioner != "kubernetes.io/aws-ebs" || alias.Parameters["type"] != expected.classType {
+			t.Errorf("storage class %s is not cloned from %s: %+v", alias.Name, expected.classType, alias)
+		}
+
+		if isDefaultStorageClass(alias) {
+			t.Errorf("storage class %s must not be the default storage class", alias.Name)
+		}
+	}
+}
+
+func TestPlanStorageClasses_NoDefault(t *testing.T) {
+	claims := []v1.PersistentVolumeClaim{newClaim("default", "data", "standard")}
+	targetClasses := []storagev1.StorageClass{newStorageClass("gp2", "kubernetes.io/aws-ebs", false)}
+
+	plan, aliases, err := planStorageClasses(claims, targetClasses, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(plan) != 1 || plan[0].Action != pkgCluster.MigrationStorageClassMissing {
+		t.Errorf("expected storage class to be missing, got %+v", plan)
+	}
+
+	if len(aliases) != 0 {
+		t.Errorf("expected no storage classes to create, got %d", len(aliases))
+	}
+}
+
+func TestPlanStorageClasses_InvalidMapping(t *testing.T) {
+	claims := []v1.PersistentVolumeClaim{newClaim("default", "data", "standard")}
+	targetClasses := []storagev1.StorageClass{newStorageClass("gp2", "kubernetes.io/aws-ebs", true)}
+
+	_, _, err := planStorageClasses(claims, targetClasses, map[string]string{"standard": "io1"})
+	if err == nil {
+		t.Fatal("expected error for a mapping to a missing storage class")
+	}
+
+	if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+		t.Errorf("expected invalid migration error, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/pkg/errors"
+)
+
+const channelTimeout = 30 * time.Second
+
+// message is a rendered notification of an event.
+type message struct {
+	Event Event
+	Text  string
+}
+
+// channel delivers notifications to a single destination.
+type channel interface {
+	Send(ctx context.Context, msg message) error
+}
+
+// newChannel returns the channel of a subscription.
+func newChannel(subscription *SubscriptionModel, smtp SMTPConfig) (channel, error) {
+	config, err := subscription.getChannelConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse channel config")
+	}
+
+	switch subscription.Channel {
+	case pkgNotification.Slack:
+		if config.Slack != nil {
+			return &slackChannel{config: *config.Slack}, nil
+		}
+
+	case pkgNotification.Webhook:
+		if config.Webhook != nil {
+			return &webhookChannel{config: *config.Webhook}, nil
+		}
+
+	case pkgNotification.Email:
+		if config.Email != nil {
+			return &emailChannel{config: *config.Email, smtp: smtp}, nil
+		}
+
+	case pkgNotification.Teams:
+		if config.Teams != nil {
+			return &teamsChannel{config: *config.Teams}, nil
+		}
+
+	default:
+		return nil, errors.Errorf("unsupported notification channel: %s", subscription.Channel)
+	}
+
+	return nil, errors.Errorf("missing %s channel config", subscription.Channel)
+}
+
+var httpClient = &http.Client{Timeout: channelTimeout}
+
+// postJSON posts a JSON encoded payload and checks that it has been accepted.
+func postJSON(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "could not create notification request")
+	}
+	req = req.WithContext(ctx)
+
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not send notification")
+	}
+	defer resp.Body.Close()
+
+	// Drain the body, so that the connection can be reused
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("notification endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/pkg/errors"
+)
+
+// SMTPConfig describes the mail server sending the email notifications.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// emailChannel sends notifications as plain text emails.
+type emailChannel struct {
+	config pkgNotification.EmailConfig
+	smtp   SMTPConfig
+}
+
+func (c *emailChannel) Send(ctx context.Context, msg message) error {
+	if c.smtp.Host == "" {
+		return errors.New("email notifications are not configured")
+	}
+
+	var auth smtp.Auth
+	if c.smtp.Username != "" {
+		auth = smtp.PlainAuth("", c.smtp.Username, c.smtp.Password, c.smtp.Host)
+	}
+
+	addr := net.JoinHostPort(c.smtp.Host, strconv.Itoa(c.smtp.Port))
+
+	err := smtp.SendMail(addr, auth, c.smtp.From, c.config.To, formatEmail(c.smtp.From, c.config.To, msg, time.Now()))
+
+	return errors.Wrap(err, "could not send email")
+}
+
+// formatEmail formats a notification as an RFC 5322 message.
+func formatEmail(from string, to []string, msg message, date time.Time) []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", "[Pipeline] "+msg.Event.Title()))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.Replace(msg.Text, "\n", "\r\n", -1))
+	buf.WriteString("\r\n")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,49 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/pkg/errors"
+)
+
+// slackChannel posts notifications to a Slack incoming webhook.
+type slackChannel struct {
+	config pkgNotification.SlackConfig
+}
+
+type slackMessage struct {
+	Text      string `json:"text"`
+	Channel   string `json:"channel,omitempty"`
+	Username  string `json:"username"`
+	IconEmoji string `json:"icon_emoji"`
+}
+
+func (c *slackChannel) Send(ctx context.Context, msg message) error {
+	body, err := json.Marshal(slackMessage{
+		Text:      msg.Text,
+		Channel:   c.config.Channel,
+		Username:  "banzaicloud",
+		IconEmoji: ":cloud:",
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not encode Slack message")
+	}
+
+	return errors.WithMessage(postJSON(ctx, c.config.WebhookURL, body, nil), "slack")
+}
@@ -0,0 +1,51 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/pkg/errors"
+)
+
+// teamsChannel posts notifications as message cards to a Microsoft Teams incoming webhook.
+type teamsChannel struct {
+	config pkgNotification.TeamsConfig
+}
+
+type teamsMessageCard struct {
+	Type    string `json:"@type"`
+	Context string `json:"@context"`
+	Summary string `json:"summary"`
+	Title   string `json:"title"`
+	Text    string `json:"text"`
+}
+
+func (c *teamsChannel) Send(ctx context.Context, msg message) error {
+	body, err := json.Marshal(teamsMessageCard{
+		Type:    "MessageCard",
+		Context: "https://schema.org/extensions",
+		Summary: msg.Event.Title(),
+		Title:   msg.Event.Title(),
+		Text:    msg.Text,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not encode Teams message card")
+	}
+
+	return errors.WithMessage(postJSON(ctx, c.config.WebhookURL, body, nil), "teams")
+}
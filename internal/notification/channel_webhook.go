@@ -0,0 +1,61 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/pkg/errors"
+)
+
+// signatureHeader contains the hex encoded HMAC-SHA256 signature of the request body prefixed with "sha256=".
+const signatureHeader = "X-Pipeline-Signature-256"
+
+// webhookChannel posts the events with their rendered message as JSON to an HTTP endpoint.
+type webhookChannel struct {
+	config pkgNotification.WebhookConfig
+}
+
+type webhookPayload struct {
+	Event
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+func (c *webhookChannel) Send(ctx context.Context, msg message) error {
+	body, err := json.Marshal(webhookPayload{
+		Event:   msg.Event,
+		Title:   msg.Event.Title(),
+		Message: msg.Text,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not encode webhook payload")
+	}
+
+	headers := map[string]string{}
+	if c.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.config.Secret))
+		mac.Write(body)
+
+		headers[signatureHeader] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	return errors.WithMessage(postJSON(ctx, c.config.URL, body, headers), "webhook")
+}
@@ -0,0 +1,93 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/pkg/errors"
+)
+
+// Event is something an organization can be notified about.
+type Event struct {
+	Type           string    `json:"type"`
+	OrganizationID uint      `json:"organizationId"`
+	ClusterID      uint      `json:"clusterId,omitempty"`
+	ClusterName    string    `json:"clusterName,omitempty"`
+	Resource       string    `json:"resource,omitempty"`
+	Reason         string    `json:"reason,omitempty"`
+	Time           time.Time `json:"time"`
+}
+
+var defaultTemplates = map[string]string{
+	pkgNotification.ClusterCreated:         `Cluster {{ .ClusterName }} has been created.`,
+	pkgNotification.ClusterCreationFailed:  `Cluster {{ .ClusterName }} could not be created: {{ .Reason }}`,
+	pkgNotification.ClusterPostHooksFailed: `Posthooks of cluster {{ .ClusterName }} failed: {{ .Reason }}`,
+	pkgNotification.ClusterDeleted:         `Cluster {{ .ClusterName }} has been deleted.`,
+	pkgNotification.BackupFailed:           `Backup {{ .Resource }} of cluster {{ .ClusterName }} failed{{ if .Reason }}: {{ .Reason }}{{ end }}`,
+	pkgNotification.SecretValidationFailed: `Validation of secret {{ .Resource }} failed: {{ .Reason }}`,
+	pkgNotification.Test:                   `This is a test notification from Pipeline.`,
+}
+
+var eventTitles = map[string]string{
+	pkgNotification.ClusterCreated:         "Cluster created",
+	pkgNotification.ClusterCreationFailed:  "Cluster creation failed",
+	pkgNotification.ClusterPostHooksFailed: "Cluster posthooks failed",
+	pkgNotification.ClusterDeleted:         "Cluster deleted",
+	pkgNotification.BackupFailed:           "Backup failed",
+	pkgNotification.SecretValidationFailed: "Secret validation failed",
+	pkgNotification.Test:                   "Test notification",
+}
+
+// Title returns a short human readable summary of the event.
+func (e Event) Title() string {
+	if title, ok := eventTitles[e.Type]; ok {
+		return title
+	}
+
+	return e.Type
+}
+
+// parseTemplate parses a message template, the default template of the event type is used if it is empty.
+func parseTemplate(text string, eventType string) (*template.Template, error) {
+	if text == "" {
+		text = defaultTemplates[eventType]
+	}
+
+	tmpl, err := template.New("message").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse message template")
+	}
+
+	return tmpl, nil
+}
+
+// renderMessage renders the message of an event with the given template.
+func renderMessage(text string, event Event) (string, error) {
+	tmpl, err := parseTemplate(text, event.Type)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, event); err != nil {
+		return "", errors.Wrap(err, "could not render message template")
+	}
+
+	return buf.String(), nil
+}
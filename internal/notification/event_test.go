@@ -0,0 +1,80 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"testing"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+)
+
+func TestRenderMessage(t *testing.T) {
+	event := Event{
+		Type:           pkgNotification.BackupFailed,
+		OrganizationID: 1,
+		ClusterID:      2,
+		ClusterName:    "my-cluster",
+		Resource:       "daily-20190210",
+		Reason:         "bucket not found",
+	}
+
+	tests := map[string]struct {
+		template string
+		expected string
+	}{
+		"default template": {
+			expected: "Backup daily-20190210 of cluster my-cluster failed: bucket not found",
+		},
+		"custom template": {
+			template: "[{{ .Type }}] {{ .ClusterName }} ({{ .ClusterID }})",
+			expected: "[backup_failed] my-cluster (2)",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := renderMessage(test.template, event)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual != test.expected {
+				t.Errorf("expected: %q, got: %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRenderMessage_InvalidTemplate(t *testing.T) {
+	if _, err := renderMessage("{{ .Unknown }}", Event{Type: pkgNotification.Test}); err == nil {
+		t.Error("expected error for unknown field")
+	}
+
+	if _, err := renderMessage("{{ .ClusterName", Event{Type: pkgNotification.Test}); err == nil {
+		t.Error("expected error for unterminated action")
+	}
+}
+
+func TestDefaultTemplates(t *testing.T) {
+	for _, eventType := range append(pkgNotification.EventTypes, pkgNotification.Test) {
+		if _, ok := defaultTemplates[eventType]; !ok {
+			t.Errorf("missing default template for %s", eventType)
+		}
+
+		if _, ok := eventTitles[eventType]; !ok {
+			t.Errorf("missing title for %s", eventType)
+		}
+	}
+}
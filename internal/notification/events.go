@@ -0,0 +1,144 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"context"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	arkSync "github.com/banzaicloud/pipeline/internal/ark/sync"
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+)
+
+const (
+	clusterCreatedTopic         = "cluster_created"
+	clusterCreationFailedTopic  = "cluster_creation_failed"
+	clusterPostHooksFailedTopic = "cluster_posthooks_failed"
+	clusterDeletedTopic         = "cluster_deleted"
+)
+
+type eventBus interface {
+	SubscribeAsync(topic string, fn interface{}, transactional bool) error
+}
+
+type clusterGetter interface {
+	GetClusterByIDOnly(ctx context.Context, clusterID uint) (cluster.CommonCluster, error)
+}
+
+// Subscriber turns the domain events published on the event buses into notifications.
+type Subscriber struct {
+	service  *Service
+	clusters clusterGetter
+}
+
+// NewSubscriber returns a new Subscriber instance.
+func NewSubscriber(service *Service, clusters clusterGetter) *Subscriber {
+	return &Subscriber{
+		service:  service,
+		clusters: clusters,
+	}
+}
+
+// Register subscribes to the cluster events and to the events published on the global event bus
+// (Ark sync results and secret validations).
+func (s *Subscriber) Register(clusterEvents eventBus, events eventBus) error {
+	subscriptions := []struct {
+		eb    eventBus
+		topic string
+		fn    interface{}
+	}{
+		{clusterEvents, clusterCreatedTopic, s.clusterCreated},
+		{clusterEvents, clusterCreationFailedTopic, s.clusterCreationFailed},
+		{clusterEvents, clusterPostHooksFailedTopic, s.clusterPostHooksFailed},
+		{clusterEvents, clusterDeletedTopic, s.clusterDeleted},
+		{events, arkSync.BackupFailedTopic, s.backupFailed},
+		{events, secret.ValidationFailedTopic, s.secretValidationFailed},
+	}
+
+	for _, subscription := range subscriptions {
+		if err := subscription.eb.SubscribeAsync(subscription.topic, subscription.fn, false); err != nil {
+			return emperror.With(errors.Wrap(err, "could not subscribe to events"), "topic", subscription.topic)
+		}
+	}
+
+	return nil
+}
+
+func (s *Subscriber) notifyCluster(eventType string, clusterID uint, reason string) {
+	ctx := context.Background()
+
+	commonCluster, err := s.clusters.GetClusterByIDOnly(ctx, clusterID)
+	if err != nil {
+		s.service.errorHandler.Handle(emperror.With(
+			errors.WithMessage(err, "could not get cluster for notification"),
+			"cluster", clusterID,
+			"event", eventType,
+		))
+
+		return
+	}
+
+	s.service.Notify(ctx, Event{
+		Type:           eventType,
+		OrganizationID: commonCluster.GetOrganizationId(),
+		ClusterID:      clusterID,
+		ClusterName:    commonCluster.GetName(),
+		Reason:         reason,
+	})
+}
+
+func (s *Subscriber) clusterCreated(clusterID uint) {
+	s.notifyCluster(pkgNotification.ClusterCreated, clusterID, "")
+}
+
+func (s *Subscriber) clusterCreationFailed(clusterID uint, reason string) {
+	s.notifyCluster(pkgNotification.ClusterCreationFailed, clusterID, reason)
+}
+
+func (s *Subscriber) clusterPostHooksFailed(clusterID uint, reason string) {
+	s.notifyCluster(pkgNotification.ClusterPostHooksFailed, clusterID, reason)
+}
+
+// clusterDeleted is published after the cluster is removed, so it cannot be looked up anymore.
+func (s *Subscriber) clusterDeleted(organizationID uint, clusterName string) {
+	s.service.Notify(context.Background(), Event{
+		Type:           pkgNotification.ClusterDeleted,
+		OrganizationID: organizationID,
+		ClusterName:    clusterName,
+	})
+}
+
+func (s *Subscriber) backupFailed(organizationID uint, clusterID uint, clusterName string, backupName string, reason string) {
+	s.service.Notify(context.Background(), Event{
+		Type:           pkgNotification.BackupFailed,
+		OrganizationID: organizationID,
+		ClusterID:      clusterID,
+		ClusterName:    clusterName,
+		Resource:       backupName,
+		Reason:         reason,
+	})
+}
+
+func (s *Subscriber) secretValidationFailed(organizationID uint, secretName string, secretType string, reason string) {
+	s.service.Notify(context.Background(), Event{
+		Type:           pkgNotification.SecretValidationFailed,
+		OrganizationID: organizationID,
+		Resource:       secretName,
+		Reason:         reason,
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	subscriptionsTableName = "notification_subscriptions"
+)
+
+// channelConfig holds the configuration of the channel of a subscription, only the field of the channel is set.
+type channelConfig struct {
+	Slack   *pkgNotification.SlackConfig   `json:"slack,omitempty"`
+	Webhook *pkgNotification.WebhookConfig `json:"webhook,omitempty"`
+	Email   *pkgNotification.EmailConfig   `json:"email,omitempty"`
+	Teams   *pkgNotification.TeamsConfig   `json:"teams,omitempty"`
+}
+
+// SubscriptionModel describes a notification channel of an organization with the events it is notified about.
+type SubscriptionModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"unique_index:idx_notification_subscription_org_name"`
+	Name           string `gorm:"unique_index:idx_notification_subscription_org_name"`
+
+	Channel    string
+	Config     string `sql:"type:text;"`
+	EventTypes string `sql:"type:text;"`
+	Template   string `sql:"type:text;"`
+	Enabled    bool
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+}
+
+// TableName changes the default table name.
+func (SubscriptionModel) TableName() string {
+	return subscriptionsTableName
+}
+
+func (m *SubscriptionModel) getChannelConfig() (*channelConfig, error) {
+	var config channelConfig
+
+	if err := json.Unmarshal([]byte(m.Config), &config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// GetEventTypes returns the event types the subscription is notified about, all of them if empty.
+func (m *SubscriptionModel) GetEventTypes() []string {
+	if m.EventTypes == "" {
+		return []string{}
+	}
+
+	return strings.Split(m.EventTypes, ",")
+}
+
+// Matches returns true if the subscription should be notified about the event type.
+func (m *SubscriptionModel) Matches(eventType string) bool {
+	eventTypes := m.GetEventTypes()
+	if len(eventTypes) == 0 {
+		return true
+	}
+
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConvertModelToEntity converts a SubscriptionModel to a pkgNotification.SubscriptionResponse.
+// The webhook secret is never returned.
+func (m *SubscriptionModel) ConvertModelToEntity() pkgNotification.SubscriptionResponse {
+	response := pkgNotification.SubscriptionResponse{
+		ID:         m.ID,
+		Name:       m.Name,
+		Channel:    m.Channel,
+		EventTypes: m.GetEventTypes(),
+		Template:   m.Template,
+		Enabled:    m.Enabled,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+		CreatedBy:  m.CreatedBy,
+	}
+
+	if config, err := m.getChannelConfig(); err == nil {
+		response.Slack = config.Slack
+		response.Email = config.Email
+		response.Teams = config.Teams
+
+		if config.Webhook != nil {
+			response.Webhook = &pkgNotification.WebhookConfig{URL: config.Webhook.URL}
+		}
+	}
+
+	return response
+}
+
+// Migrate executes the table migrations for the notification models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&SubscriptionModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating notification tables")
+
+	return db.AutoMigrate(tables...).Error
+}
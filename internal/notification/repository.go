@@ -0,0 +1,147 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the notification subscriptions of the organizations.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+type subscriptionNotFoundError struct {
+	organizationID uint
+	id             uint
+}
+
+func (e *subscriptionNotFoundError) Error() string {
+	return "notification subscription not found"
+}
+
+func (e *subscriptionNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"organization", e.organizationID,
+		"subscription", e.id,
+	}
+}
+
+func (e *subscriptionNotFoundError) NotFound() bool {
+	return true
+}
+
+// Find returns the notification subscriptions of an organization.
+func (r *Repository) Find(organizationID uint) ([]*SubscriptionModel, error) {
+	var subscriptions []*SubscriptionModel
+
+	err := r.db.Where(&SubscriptionModel{OrganizationID: organizationID}).Order("name").Find(&subscriptions).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch notification subscriptions"),
+			"organization", organizationID,
+		)
+	}
+
+	return subscriptions, nil
+}
+
+// FindEnabled returns the enabled notification subscriptions of an organization.
+func (r *Repository) FindEnabled(organizationID uint) ([]*SubscriptionModel, error) {
+	var subscriptions []*SubscriptionModel
+
+	err := r.db.Where("organization_id = ? AND enabled = ?", organizationID, true).Find(&subscriptions).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch enabled notification subscriptions"),
+			"organization", organizationID,
+		)
+	}
+
+	return subscriptions, nil
+}
+
+// FindOne returns a notification subscription of an organization.
+func (r *Repository) FindOne(organizationID uint, id uint) (*SubscriptionModel, error) {
+	var subscription SubscriptionModel
+
+	err := r.db.Where(&SubscriptionModel{ID: id, OrganizationID: organizationID}).First(&subscription).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&subscriptionNotFoundError{
+			organizationID: organizationID,
+			id:             id,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get notification subscription"),
+			"organization", organizationID,
+			"subscription", id,
+		)
+	}
+
+	return &subscription, nil
+}
+
+// ExistsWithName checks whether another subscription of the organization has the given name.
+func (r *Repository) ExistsWithName(organizationID uint, name string, exceptID uint) (bool, error) {
+	var count int
+
+	err := r.db.Model(&SubscriptionModel{}).
+		Where("organization_id = ? AND name = ? AND id <> ?", organizationID, name, exceptID).
+		Count(&count).Error
+	if err != nil {
+		return false, emperror.With(
+			errors.Wrap(err, "could not check notification subscription name"),
+			"organization", organizationID,
+		)
+	}
+
+	return count > 0, nil
+}
+
+// Save persists a notification subscription.
+func (r *Repository) Save(subscription *SubscriptionModel) error {
+	err := r.db.Save(subscription).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save notification subscription"),
+			"organization", subscription.OrganizationID,
+			"subscription", subscription.Name,
+		)
+	}
+
+	return nil
+}
+
+// Delete deletes a notification subscription.
+func (r *Repository) Delete(subscription *SubscriptionModel) error {
+	err := r.db.Delete(subscription).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not delete notification subscription"),
+			"organization", subscription.OrganizationID,
+			"subscription", subscription.Name,
+		)
+	}
+
+	return nil
+}
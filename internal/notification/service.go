@@ -0,0 +1,211 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type subscriptionExistsError struct {
+	name string
+}
+
+func (e *subscriptionExistsError) Error() string {
+	return fmt.Sprintf("notification subscription %q already exists", e.name)
+}
+
+func (e *subscriptionExistsError) Conflict() bool {
+	return true
+}
+
+type deliveryError struct {
+	err error
+}
+
+func (e *deliveryError) Error() string {
+	return "could not send notification: " + e.err.Error()
+}
+
+// IsInvalid marks a failed test notification as a client error, as it is most likely caused by a wrong channel config.
+func (e *deliveryError) IsInvalid() bool {
+	return true
+}
+
+// Service manages the notification subscriptions and notifies them about events.
+type Service struct {
+	repository *Repository
+	smtp       SMTPConfig
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(repository *Repository, smtp SMTPConfig, logger logrus.FieldLogger, errorHandler emperror.Handler) *Service {
+	return &Service{
+		repository: repository,
+		smtp:       smtp,
+
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// ListSubscriptions returns the notification subscriptions of an organization.
+func (s *Service) ListSubscriptions(organizationID uint) ([]*SubscriptionModel, error) {
+	return s.repository.Find(organizationID)
+}
+
+// GetSubscription returns a notification subscription of an organization.
+func (s *Service) GetSubscription(organizationID uint, id uint) (*SubscriptionModel, error) {
+	return s.repository.FindOne(organizationID, id)
+}
+
+// CreateSubscription creates a new notification subscription for an organization.
+func (s *Service) CreateSubscription(organizationID uint, req *pkgNotification.SubscriptionRequest, userID uint) (*SubscriptionModel, error) {
+	subscription := &SubscriptionModel{
+		OrganizationID: organizationID,
+		CreatedBy:      userID,
+	}
+
+	if err := s.save(subscription, req); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// UpdateSubscription updates a notification subscription of an organization.
+func (s *Service) UpdateSubscription(organizationID uint, id uint, req *pkgNotification.SubscriptionRequest) (*SubscriptionModel, error) {
+	subscription, err := s.repository.FindOne(organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.save(subscription, req); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *Service) save(subscription *SubscriptionModel, req *pkgNotification.SubscriptionRequest) error {
+	if err := applySubscriptionRequest(subscription, req); err != nil {
+		return err
+	}
+
+	exists, err := s.repository.ExistsWithName(subscription.OrganizationID, subscription.Name, subscription.ID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return errors.WithStack(&subscriptionExistsError{name: subscription.Name})
+	}
+
+	return s.repository.Save(subscription)
+}
+
+// DeleteSubscription deletes a notification subscription of an organization.
+func (s *Service) DeleteSubscription(organizationID uint, id uint) error {
+	subscription, err := s.repository.FindOne(organizationID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.Delete(subscription)
+}
+
+// SendTestNotification sends a test notification to a subscription, even if it is disabled.
+func (s *Service) SendTestNotification(ctx context.Context, organizationID uint, id uint) error {
+	subscription, err := s.repository.FindOne(organizationID, id)
+	if err != nil {
+		return err
+	}
+
+	event := Event{
+		Type:           pkgNotification.Test,
+		OrganizationID: organizationID,
+		Time:           time.Now(),
+	}
+
+	if err := s.send(ctx, subscription, event); err != nil {
+		return errors.WithStack(&deliveryError{err: err})
+	}
+
+	return nil
+}
+
+// Notify sends an event to every enabled subscription of its organization which is interested in it.
+// Failed notifications are reported to the error handler, they do not stop the others.
+func (s *Service) Notify(ctx context.Context, event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"organization": event.OrganizationID,
+		"event":        event.Type,
+	})
+
+	subscriptions, err := s.repository.FindEnabled(event.OrganizationID)
+	if err != nil {
+		s.errorHandler.Handle(err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.Type) {
+			continue
+		}
+
+		if err := s.send(ctx, subscription, event); err != nil {
+			s.errorHandler.Handle(emperror.With(
+				errors.WithMessage(err, "could not send notification"),
+				"organization", event.OrganizationID,
+				"subscription", subscription.Name,
+				"event", event.Type,
+			))
+
+			continue
+		}
+
+		logger.WithField("subscription", subscription.Name).Debug("notification sent")
+	}
+}
+
+func (s *Service) send(ctx context.Context, subscription *SubscriptionModel, event Event) error {
+	text, err := renderMessage(subscription.Template, event)
+	if err != nil {
+		return err
+	}
+
+	channel, err := newChannel(subscription, s.smtp)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, channelTimeout)
+	defer cancel()
+
+	return channel.Send(ctx, message{Event: event, Text: text})
+}
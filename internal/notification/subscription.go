@@ -0,0 +1,124 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"encoding/json"
+	"strings"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/pkg/errors"
+)
+
+type invalidSubscriptionError struct {
+	message string
+}
+
+func (e *invalidSubscriptionError) Error() string {
+	return e.message
+}
+
+func (e *invalidSubscriptionError) IsInvalid() bool {
+	return true
+}
+
+func newInvalidSubscriptionError(message string) error {
+	return errors.WithStack(&invalidSubscriptionError{message})
+}
+
+// applySubscriptionRequest validates a subscription request and applies it to a subscription.
+// The webhook secret of the subscription is kept if the request does not contain one.
+func applySubscriptionRequest(subscription *SubscriptionModel, req *pkgNotification.SubscriptionRequest) error {
+	config := channelConfig{}
+
+	switch req.Channel {
+	case pkgNotification.Slack:
+		if req.Slack == nil || req.Slack.WebhookURL == "" {
+			return newInvalidSubscriptionError("slack webhook URL is required")
+		}
+		config.Slack = req.Slack
+
+	case pkgNotification.Webhook:
+		if req.Webhook == nil || req.Webhook.URL == "" {
+			return newInvalidSubscriptionError("webhook URL is required")
+		}
+		config.Webhook = req.Webhook
+
+		if config.Webhook.Secret == "" && subscription.Channel == pkgNotification.Webhook {
+			if current, err := subscription.getChannelConfig(); err == nil && current.Webhook != nil {
+				config.Webhook.Secret = current.Webhook.Secret
+			}
+		}
+
+	case pkgNotification.Email:
+		if req.Email == nil || len(req.Email.To) == 0 {
+			return newInvalidSubscriptionError("email recipients are required")
+		}
+		for _, to := range req.Email.To {
+			if !strings.Contains(to, "@") || strings.ContainsAny(to, "\r\n") {
+				return newInvalidSubscriptionError("invalid email recipient: " + to)
+			}
+		}
+		config.Email = req.Email
+
+	case pkgNotification.Teams:
+		if req.Teams == nil || req.Teams.WebhookURL == "" {
+			return newInvalidSubscriptionError("teams webhook URL is required")
+		}
+		config.Teams = req.Teams
+
+	default:
+		return newInvalidSubscriptionError("unsupported notification channel: " + req.Channel)
+	}
+
+	for _, eventType := range req.EventTypes {
+		if !isValidEventType(eventType) {
+			return newInvalidSubscriptionError("unsupported event type: " + eventType)
+		}
+	}
+
+	// every event type has a default template, so any of them can be used to check the custom one
+	if _, err := parseTemplate(req.Template, pkgNotification.Test); err != nil {
+		return newInvalidSubscriptionError(err.Error())
+	}
+
+	rawConfig, err := json.Marshal(config)
+	if err != nil {
+		return errors.Wrap(err, "could not encode channel config")
+	}
+
+	subscription.Name = req.Name
+	subscription.Channel = req.Channel
+	subscription.Config = string(rawConfig)
+	subscription.EventTypes = strings.Join(req.EventTypes, ",")
+	subscription.Template = req.Template
+
+	subscription.Enabled = true
+	if req.Enabled != nil {
+		subscription.Enabled = *req.Enabled
+	}
+
+	return nil
+}
+
+func isValidEventType(eventType string) bool {
+	for _, t := range pkgNotification.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,115 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"testing"
+
+	pkgNotification "github.com/banzaicloud/pipeline/pkg/notification"
+	"github.com/pkg/errors"
+)
+
+func TestApplySubscriptionRequest(t *testing.T) {
+	subscription := &SubscriptionModel{}
+
+	err := applySubscriptionRequest(subscription, &pkgNotification.SubscriptionRequest{
+		Name:       "ops",
+		Channel:    pkgNotification.Webhook,
+		Webhook:    &pkgNotification.WebhookConfig{URL: "https://example.com/hook", Secret: "secret"},
+		EventTypes: []string{pkgNotification.ClusterCreationFailed, pkgNotification.BackupFailed},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !subscription.Enabled {
+		t.Error("subscription should be enabled by default")
+	}
+
+	if !subscription.Matches(pkgNotification.BackupFailed) || subscription.Matches(pkgNotification.ClusterCreated) {
+		t.Errorf("unexpected event type filter: %s", subscription.EventTypes)
+	}
+
+	// the secret is kept when it is not sent again
+	disabled := false
+	err = applySubscriptionRequest(subscription, &pkgNotification.SubscriptionRequest{
+		Name:    "ops",
+		Channel: pkgNotification.Webhook,
+		Webhook: &pkgNotification.WebhookConfig{URL: "https://example.com/other"},
+		Enabled: &disabled,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config, err := subscription.getChannelConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Webhook.URL != "https://example.com/other" || config.Webhook.Secret != "secret" {
+		t.Errorf("unexpected webhook config: %+v", config.Webhook)
+	}
+
+	if subscription.Enabled || !subscription.Matches(pkgNotification.ClusterCreated) {
+		t.Errorf("unexpected subscription: %+v", subscription)
+	}
+
+	if response := subscription.ConvertModelToEntity(); response.Webhook.Secret != "" {
+		t.Error("webhook secret must not be returned")
+	}
+}
+
+func TestApplySubscriptionRequest_Invalid(t *testing.T) {
+	tests := map[string]pkgNotification.SubscriptionRequest{
+		"unknown channel": {
+			Name:    "ops",
+			Channel: "pager",
+		},
+		"missing config": {
+			Name:    "ops",
+			Channel: pkgNotification.Slack,
+		},
+		"invalid recipient": {
+			Name:    "ops",
+			Channel: pkgNotification.Email,
+			Email:   &pkgNotification.EmailConfig{To: []string{"ops@example.com\r\nBcc: x@example.com"}},
+		},
+		"unknown event type": {
+			Name:       "ops",
+			Channel:    pkgNotification.Teams,
+			Teams:      &pkgNotification.TeamsConfig{WebhookURL: "https://example.com/hook"},
+			EventTypes: []string{"cluster_exploded"},
+		},
+		"invalid template": {
+			Name:     "ops",
+			Channel:  pkgNotification.Teams,
+			Teams:    &pkgNotification.TeamsConfig{WebhookURL: "https://example.com/hook"},
+			Template: "{{ .ClusterName",
+		},
+	}
+
+	for name, req := range tests {
+		req := req
+
+		t.Run(name, func(t *testing.T) {
+			err := applySubscriptionRequest(&SubscriptionModel{}, &req)
+
+			if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+				t.Errorf("expected invalid error, got: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"context"
+
+	"github.com/banzaicloud/pipeline/helm"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/ghodss/yaml"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	k8sHelm "k8s.io/helm/pkg/helm"
+	helm_env "k8s.io/helm/pkg/helm/environment"
+)
+
+// helmDeployer deploys the release of a rollout through the Tiller of the target clusters.
+type helmDeployer struct {
+	ctx      context.Context
+	rollout  *RolloutModel
+	clusters clusterManager
+	env      helm_env.EnvSettings
+}
+
+func (d *helmDeployer) getK8sConfig(target *TargetModel) ([]byte, error) {
+	commonCluster, err := d.clusters.GetClusterByID(d.ctx, d.rollout.OrganizationID, target.ClusterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get cluster")
+	}
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get k8s config")
+	}
+
+	return kubeConfig, nil
+}
+
+func (d *helmDeployer) Deploy(target *TargetModel) error {
+	kubeConfig, err := d.getK8sConfig(target)
+	if err != nil {
+		return err
+	}
+
+	var values []byte
+	if rolloutValues := d.rollout.GetValues(); len(rolloutValues) > 0 {
+		values, err = yaml.Marshal(rolloutValues)
+		if err != nil {
+			return errors.Wrap(err, "could not marshal release values")
+		}
+	}
+
+	deployment, err := helm.GetDeployment(d.rollout.ReleaseName, kubeConfig)
+	if _, ok := err.(*helm.DeploymentNotFoundError); ok {
+		target.Action = pkgHelm.RolloutInstall
+
+		options := []k8sHelm.InstallOption{
+			k8sHelm.InstallWait(d.rollout.Wait),
+			k8sHelm.ValueOverrides(values),
+		}
+
+		release, err := helm.CreateDeployment(
+			d.rollout.ChartName,
+			d.rollout.ChartVersion,
+			nil,
+			d.rollout.Namespace,
+			d.rollout.ReleaseName,
+			false,
+			nil,
+			kubeConfig,
+			d.env,
+			options...,
+		)
+		if err != nil {
+			return errors.Wrap(err, "could not install release")
+		}
+
+		target.Revision = release.GetRelease().GetVersion()
+
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "could not get release")
+	}
+
+	if deployment.Namespace != d.rollout.Namespace {
+		return emperror.With(
+			errors.Errorf("release is installed in namespace %q", deployment.Namespace),
+			"release", d.rollout.ReleaseName,
+		)
+	}
+
+	target.Action = pkgHelm.RolloutUpgrade
+	target.PreviousRevision = deployment.Version
+
+	release, err := helm.UpgradeDeployment(
+		d.rollout.ReleaseName,
+		d.rollout.ChartName,
+		d.rollout.ChartVersion,
+		nil,
+		values,
+		false,
+		kubeConfig,
+		d.env,
+	)
+	if err != nil {
+		return errors.Wrap(err, "could not upgrade release")
+	}
+
+	target.Revision = release.GetRelease().GetVersion()
+
+	return nil
+}
+
+func (d *helmDeployer) Rollback(target *TargetModel) error {
+	kubeConfig, err := d.getK8sConfig(target)
+	if err != nil {
+		return err
+	}
+
+	switch target.Action {
+	case pkgHelm.RolloutInstall:
+		return errors.Wrap(helm.DeleteDeployment(d.rollout.ReleaseName, kubeConfig), "could not delete release")
+
+	case pkgHelm.RolloutUpgrade:
+		_, err := helm.RollbackDeployment(d.rollout.ReleaseName, target.PreviousRevision, kubeConfig)
+
+		return errors.Wrap(err, "could not roll back release")
+	}
+
+	return nil
+}
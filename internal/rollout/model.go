@@ -0,0 +1,165 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	rolloutsTableName       = "deployment_rollouts"
+	rolloutTargetsTableName = "deployment_rollout_targets"
+)
+
+// RolloutModel describes a deployment rolled out to multiple clusters of an organization.
+type RolloutModel struct {
+	ID             uint `gorm:"primary_key"`
+	OrganizationID uint `gorm:"index"`
+
+	ChartName    string
+	ChartVersion string
+	ReleaseName  string
+	Namespace    string
+	Values       string `sql:"type:text;"`
+	Wait         bool
+	Strategy     string
+
+	Status  string
+	Message string `sql:"type:text;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+}
+
+// TableName changes the default table name.
+func (RolloutModel) TableName() string {
+	return rolloutsTableName
+}
+
+// GetValues returns the values the release is deployed with.
+func (m *RolloutModel) GetValues() map[string]interface{} {
+	var values map[string]interface{}
+
+	if m.Values != "" {
+		_ = json.Unmarshal([]byte(m.Values), &values)
+	}
+
+	return values
+}
+
+// SetValues sets the values the release is deployed with.
+func (m *RolloutModel) SetValues(values map[string]interface{}) error {
+	if len(values) == 0 {
+		m.Values = ""
+		return nil
+	}
+
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	m.Values = string(valuesJSON)
+
+	return nil
+}
+
+// ConvertModelToEntity converts a RolloutModel with its targets to a pkgHelm.RolloutResponse.
+func (m *RolloutModel) ConvertModelToEntity(targets []*TargetModel) pkgHelm.RolloutResponse {
+	response := pkgHelm.RolloutResponse{
+		ID:          m.ID,
+		Name:        m.ChartName,
+		Version:     m.ChartVersion,
+		ReleaseName: m.ReleaseName,
+		Namespace:   m.Namespace,
+		Strategy:    m.Strategy,
+		Status:      m.Status,
+		Message:     m.Message,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+		CreatedBy:   m.CreatedBy,
+	}
+
+	for _, target := range targets {
+		response.Clusters = append(response.Clusters, target.ConvertModelToEntity())
+	}
+
+	return response
+}
+
+// TargetModel describes the state of a rollout in a single cluster.
+type TargetModel struct {
+	ID          uint `gorm:"primary_key"`
+	RolloutID   uint `gorm:"index"`
+	ClusterID   uint
+	ClusterName string
+	Position    int
+
+	Action           string
+	Status           string
+	PreviousRevision int32
+	Revision         int32
+	Error            string `sql:"type:text;"`
+	StartedAt        *time.Time
+	FinishedAt       *time.Time
+}
+
+// TableName changes the default table name.
+func (TargetModel) TableName() string {
+	return rolloutTargetsTableName
+}
+
+// ConvertModelToEntity converts a TargetModel to a pkgHelm.RolloutClusterStatus.
+func (m *TargetModel) ConvertModelToEntity() pkgHelm.RolloutClusterStatus {
+	return pkgHelm.RolloutClusterStatus{
+		ClusterID:        m.ClusterID,
+		ClusterName:      m.ClusterName,
+		Action:           m.Action,
+		Status:           m.Status,
+		PreviousRevision: m.PreviousRevision,
+		Revision:         m.Revision,
+		Error:            m.Error,
+		StartedAt:        m.StartedAt,
+		FinishedAt:       m.FinishedAt,
+	}
+}
+
+// Migrate executes the table migrations for the rollout models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&RolloutModel{},
+		&TargetModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating deployment rollout tables")
+
+	return db.AutoMigrate(tables...).Error
+}
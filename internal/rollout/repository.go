@@ -0,0 +1,165 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the deployment rollouts of the organizations.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+type rolloutNotFoundError struct {
+	organizationID uint
+	id             uint
+}
+
+func (e *rolloutNotFoundError) Error() string {
+	return "deployment rollout not found"
+}
+
+func (e *rolloutNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"organization", e.organizationID,
+		"rollout", e.id,
+	}
+}
+
+func (e *rolloutNotFoundError) NotFound() bool {
+	return true
+}
+
+// FindRollouts returns the deployment rollouts of an organization, the latest first.
+func (r *Repository) FindRollouts(organizationID uint) ([]*RolloutModel, error) {
+	var rollouts []*RolloutModel
+
+	err := r.db.Where(&RolloutModel{OrganizationID: organizationID}).Order("id DESC").Find(&rollouts).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch deployment rollouts"), "organization", organizationID)
+	}
+
+	return rollouts, nil
+}
+
+// FindOneRollout returns a deployment rollout of an organization.
+func (r *Repository) FindOneRollout(organizationID uint, id uint) (*RolloutModel, error) {
+	var rollout RolloutModel
+
+	err := r.db.Where(&RolloutModel{ID: id, OrganizationID: organizationID}).First(&rollout).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&rolloutNotFoundError{
+			organizationID: organizationID,
+			id:             id,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get deployment rollout"),
+			"organization", organizationID,
+			"rollout", id,
+		)
+	}
+
+	return &rollout, nil
+}
+
+// FindRunningRollouts returns the deployment rollouts being executed.
+func (r *Repository) FindRunningRollouts() ([]*RolloutModel, error) {
+	var rollouts []*RolloutModel
+
+	err := r.db.Where("status IN (?)", []string{pkgHelm.RolloutPending, pkgHelm.RolloutRunning}).Find(&rollouts).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch running deployment rollouts")
+	}
+
+	return rollouts, nil
+}
+
+// FindTargets returns the target clusters of a deployment rollout in execution order.
+func (r *Repository) FindTargets(rolloutID uint) ([]*TargetModel, error) {
+	var targets []*TargetModel
+
+	err := r.db.Where(&TargetModel{RolloutID: rolloutID}).Order("position").Find(&targets).Error
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not fetch deployment rollout targets"), "rollout", rolloutID)
+	}
+
+	return targets, nil
+}
+
+// CreateRollout persists a new deployment rollout with its targets.
+func (r *Repository) CreateRollout(rollout *RolloutModel, targets []*TargetModel) error {
+	tx := r.db.Begin()
+	if err := tx.Error; err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	if err := tx.Create(rollout).Error; err != nil {
+		tx.Rollback()
+		return emperror.With(
+			errors.Wrap(err, "could not create deployment rollout"),
+			"organization", rollout.OrganizationID,
+			"release", rollout.ReleaseName,
+		)
+	}
+
+	for _, target := range targets {
+		target.RolloutID = rollout.ID
+
+		if err := tx.Create(target).Error; err != nil {
+			tx.Rollback()
+			return emperror.With(
+				errors.Wrap(err, "could not create deployment rollout target"),
+				"rollout", rollout.ID,
+				"cluster", target.ClusterID,
+			)
+		}
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit transaction")
+}
+
+// SaveRollout persists the state of a deployment rollout.
+func (r *Repository) SaveRollout(rollout *RolloutModel) error {
+	err := r.db.Save(rollout).Error
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not save deployment rollout"), "rollout", rollout.ID)
+	}
+
+	return nil
+}
+
+// SaveTarget persists the state of a deployment rollout in a cluster.
+func (r *Repository) SaveTarget(target *TargetModel) error {
+	err := r.db.Save(target).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save deployment rollout target"),
+			"rollout", target.RolloutID,
+			"cluster", target.ClusterID,
+		)
+	}
+
+	return nil
+}
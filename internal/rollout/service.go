@@ -0,0 +1,433 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/banzaicloud/pipeline/auth"
+	"github.com/banzaicloud/pipeline/cluster"
+	"github.com/banzaicloud/pipeline/helm"
+	intAuth "github.com/banzaicloud/pipeline/internal/auth"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultNamespace = "default"
+
+type invalidRolloutError struct {
+	message string
+}
+
+func (e *invalidRolloutError) Error() string {
+	return e.message
+}
+
+func (e *invalidRolloutError) IsInvalid() bool {
+	return true
+}
+
+type clusterManager interface {
+	GetClusters(ctx context.Context, organizationID uint) ([]cluster.CommonCluster, error)
+	GetClusterByID(ctx context.Context, organizationID uint, clusterID uint) (cluster.CommonCluster, error)
+}
+
+type clusterLabels interface {
+	FindByClusterIDs(clusterIDs []uint) (map[uint]map[string]string, error)
+}
+
+// Service rolls out deployments to multiple clusters of an organization.
+type Service struct {
+	repository   *Repository
+	clusters     clusterManager
+	labels       clusterLabels
+	newDeployer  func(ctx context.Context, rollout *RolloutModel) (deployer, error)
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(
+	repository *Repository,
+	clusters clusterManager,
+	labels clusterLabels,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Service {
+	s := &Service{
+		repository:   repository,
+		clusters:     clusters,
+		labels:       labels,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+	s.newDeployer = s.newHelmDeployer
+
+	return s
+}
+
+func (s *Service) newHelmDeployer(ctx context.Context, rollout *RolloutModel) (deployer, error) {
+	organization, err := auth.GetOrganizationById(rollout.OrganizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get organization")
+	}
+
+	return &helmDeployer{
+		ctx:      ctx,
+		rollout:  rollout,
+		clusters: s.clusters,
+		env:      helm.GenerateHelmRepoEnv(organization.Name),
+	}, nil
+}
+
+// ListRollouts returns the deployment rollouts of an organization.
+// Tokens restricted to clusters only see the rollouts targeting their clusters.
+func (s *Service) ListRollouts(ctx context.Context, organizationID uint) ([]*RolloutModel, error) {
+	rollouts, err := s.repository.FindRollouts(organizationID)
+	if err != nil || intAuth.TokenClusters(ctx) == nil {
+		return rollouts, err
+	}
+
+	allowed := make([]*RolloutModel, 0, len(rollouts))
+	for _, rollout := range rollouts {
+		targets, err := s.repository.FindTargets(rollout.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if checkTargetsAllowed(ctx, targets) == nil {
+			allowed = append(allowed, rollout)
+		}
+	}
+
+	return allowed, nil
+}
+
+// GetRollout returns a deployment rollout of an organization with the state of its target clusters.
+func (s *Service) GetRollout(ctx context.Context, organizationID uint, id uint) (*RolloutModel, []*TargetModel, error) {
+	rollout, err := s.repository.FindOneRollout(organizationID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets, err := s.repository.FindTargets(rollout.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkTargetsAllowed(ctx, targets); err != nil {
+		return nil, nil, err
+	}
+
+	return rollout, targets, nil
+}
+
+// checkTargetsAllowed makes sure that the token of the request can access every target cluster.
+func checkTargetsAllowed(ctx context.Context, targets []*TargetModel) error {
+	for _, target := range targets {
+		if !intAuth.IsClusterAllowed(ctx, target.ClusterID) {
+			return errors.WithStack(&intAuth.ClusterForbiddenError{ClusterID: target.ClusterID})
+		}
+	}
+
+	return nil
+}
+
+// CreateRollout selects the target clusters of a deployment and starts rolling it out in the background.
+func (s *Service) CreateRollout(ctx context.Context, organizationID uint, req *pkgHelm.CreateRolloutRequest, userID uint) (*RolloutModel, []*TargetModel, error) {
+	strategy := req.Strategy
+	switch strategy {
+	case "":
+		strategy = pkgHelm.RolloutAllAtOnce
+	case pkgHelm.RolloutAllAtOnce, pkgHelm.RolloutSequential, pkgHelm.RolloutCanary:
+	default:
+		return nil, nil, errors.WithStack(&invalidRolloutError{fmt.Sprintf("unknown rollout strategy: %s", req.Strategy)})
+	}
+
+	if req.CanaryClusterID != 0 && strategy != pkgHelm.RolloutCanary {
+		return nil, nil, errors.WithStack(&invalidRolloutError{"canary cluster can only be set for the canary strategy"})
+	}
+
+	targets, err := s.selectTargets(ctx, organizationID, req.Targets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if req.CanaryClusterID != 0 {
+		targets, err = moveCanaryFirst(targets, req.CanaryClusterID)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	rollout := &RolloutModel{
+		OrganizationID: organizationID,
+		ChartName:      req.Name,
+		ChartVersion:   req.Version,
+		ReleaseName:    req.ReleaseName,
+		Namespace:      namespace,
+		Wait:           req.Wait,
+		Strategy:       strategy,
+		Status:         pkgHelm.RolloutPending,
+		CreatedBy:      userID,
+	}
+
+	if err := rollout.SetValues(req.Values); err != nil {
+		return nil, nil, errors.Wrap(err, "could not marshal release values")
+	}
+
+	if err := s.repository.CreateRollout(rollout, targets); err != nil {
+		return nil, nil, err
+	}
+
+	go s.run(rollout.OrganizationID, rollout.ID)
+
+	return rollout, targets, nil
+}
+
+// selectTargets returns the running clusters of an organization selected by ID or by labels, ordered by ID.
+// Labels only select the clusters allowed for the token of the request.
+func (s *Service) selectTargets(ctx context.Context, organizationID uint, selector pkgHelm.ClusterSelector) ([]*TargetModel, error) {
+	if len(selector.ClusterIDs) == 0 && len(selector.Labels) == 0 {
+		return nil, errors.WithStack(&invalidRolloutError{"target clusters must be selected by ID or by labels"})
+	}
+
+	clusters, err := s.clusters.GetClusters(ctx, organizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get clusters")
+	}
+
+	clustersByID := make(map[uint]cluster.CommonCluster, len(clusters))
+	clusterIDs := make([]uint, 0, len(clusters))
+	for _, commonCluster := range clusters {
+		if !intAuth.IsClusterAllowed(ctx, commonCluster.GetID()) {
+			continue
+		}
+
+		clustersByID[commonCluster.GetID()] = commonCluster
+		clusterIDs = append(clusterIDs, commonCluster.GetID())
+	}
+
+	selected := make(map[uint]bool)
+
+	for _, clusterID := range selector.ClusterIDs {
+		if !intAuth.IsClusterAllowed(ctx, clusterID) {
+			return nil, errors.WithStack(&intAuth.ClusterForbiddenError{ClusterID: clusterID})
+		}
+
+		if _, ok := clustersByID[clusterID]; !ok {
+			return nil, errors.WithStack(&invalidRolloutError{fmt.Sprintf("cluster %d not found", clusterID)})
+		}
+
+		selected[clusterID] = true
+	}
+
+	if len(selector.Labels) > 0 {
+		labels, err := s.labels.FindByClusterIDs(clusterIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, clusterID := range clusterIDs {
+			if matchLabels(labels[clusterID], selector.Labels) {
+				selected[clusterID] = true
+			}
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.WithStack(&invalidRolloutError{"no clusters match the target selector"})
+	}
+
+	targets := make([]*TargetModel, 0, len(selected))
+
+	for clusterID := range selected {
+		commonCluster := clustersByID[clusterID]
+
+		status, err := commonCluster.GetStatus()
+		if err != nil {
+			return nil, emperror.With(errors.Wrap(err, "could not get cluster status"), "cluster", clusterID)
+		}
+
+		if status.Status != pkgCluster.Running {
+			return nil, errors.WithStack(&invalidRolloutError{fmt.Sprintf("cluster %s is not running", commonCluster.GetName())})
+		}
+
+		targets = append(targets, &TargetModel{
+			ClusterID:   clusterID,
+			ClusterName: commonCluster.GetName(),
+			Status:      pkgHelm.RolloutPending,
+		})
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].ClusterID < targets[j].ClusterID
+	})
+
+	for i, target := range targets {
+		target.Position = i
+	}
+
+	return targets, nil
+}
+
+// matchLabels tells whether the labels contain every label of the selector.
+func matchLabels(labels map[string]string, selector map[string]string) bool {
+	for key, value := range selector {
+		if labelValue, ok := labels[key]; !ok || labelValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// moveCanaryFirst moves the canary cluster to the front of the targets.
+func moveCanaryFirst(targets []*TargetModel, canaryClusterID uint) ([]*TargetModel, error) {
+	ordered := make([]*TargetModel, 0, len(targets))
+
+	for _, target := range targets {
+		if target.ClusterID == canaryClusterID {
+			ordered = append([]*TargetModel{target}, ordered...)
+		} else {
+			ordered = append(ordered, target)
+		}
+	}
+
+	if len(ordered) == 0 || ordered[0].ClusterID != canaryClusterID {
+		return nil, errors.WithStack(&invalidRolloutError{fmt.Sprintf("canary cluster %d is not a target cluster", canaryClusterID)})
+	}
+
+	for i, target := range ordered {
+		target.Position = i
+	}
+
+	return ordered, nil
+}
+
+// run executes a deployment rollout and records its result.
+func (s *Service) run(organizationID uint, id uint) {
+	rollout, targets, err := s.GetRollout(organizationID, id)
+	if err != nil {
+		s.errorHandler.Handle(errors.Wrap(err, "could not start deployment rollout"))
+		return
+	}
+
+	logger := s.logger.WithFields(logrus.Fields{
+		"organization": rollout.OrganizationID,
+		"rollout":      rollout.ID,
+		"release":      rollout.ReleaseName,
+	})
+
+	logger.WithField("strategy", rollout.Strategy).Info("rolling out deployment")
+
+	rollout.Status = pkgHelm.RolloutRunning
+	if err := s.repository.SaveRollout(rollout); err != nil {
+		s.errorHandler.Handle(err)
+	}
+
+	d, err := s.newDeployer(context.Background(), rollout)
+	if err != nil {
+		s.errorHandler.Handle(emperror.With(err, "rollout", rollout.ID))
+
+		rollout.Status = pkgHelm.RolloutFailed
+		rollout.Message = err.Error()
+		if err := s.repository.SaveRollout(rollout); err != nil {
+			s.errorHandler.Handle(err)
+		}
+
+		return
+	}
+
+	succeeded := execute(rollout.Strategy, targets, d, func(target *TargetModel) {
+		if err := s.repository.SaveTarget(target); err != nil {
+			s.errorHandler.Handle(err)
+		}
+	})
+
+	if succeeded {
+		rollout.Status = pkgHelm.RolloutSucceeded
+		rollout.Message = ""
+
+		logger.Info("deployment rolled out")
+	} else {
+		var failed []string
+		for _, target := range targets {
+			if target.Status == pkgHelm.RolloutFailed {
+				failed = append(failed, target.ClusterName)
+			}
+		}
+
+		rollout.Status = pkgHelm.RolloutFailed
+		rollout.Message = fmt.Sprintf("deployment failed in clusters: %s", strings.Join(failed, ", "))
+
+		logger.WithField("clusters", strings.Join(failed, ",")).Warn("deployment rollout failed")
+	}
+
+	if err := s.repository.SaveRollout(rollout); err != nil {
+		s.errorHandler.Handle(err)
+	}
+}
+
+// FailInterruptedRollouts marks the rollouts left running by a previous process as failed.
+func (s *Service) FailInterruptedRollouts() error {
+	rollouts, err := s.repository.FindRunningRollouts()
+	if err != nil {
+		return err
+	}
+
+	for _, rollout := range rollouts {
+		targets, err := s.repository.FindTargets(rollout.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, target := range targets {
+			switch target.Status {
+			case pkgHelm.RolloutPending:
+				target.Status = pkgHelm.RolloutSkipped
+			case pkgHelm.RolloutRunning:
+				target.Status = pkgHelm.RolloutFailed
+				target.Error = "rollout was interrupted"
+			default:
+				continue
+			}
+
+			if err := s.repository.SaveTarget(target); err != nil {
+				return err
+			}
+		}
+
+		rollout.Status = pkgHelm.RolloutFailed
+		rollout.Message = "rollout was interrupted"
+
+		if err := s.repository.SaveRollout(rollout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
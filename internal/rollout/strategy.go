@@ -0,0 +1,131 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"sync"
+	"time"
+
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+)
+
+// deployer deploys the release of a rollout into single clusters.
+type deployer interface {
+	// Deploy installs or upgrades the release in the target cluster
+	// and records the executed action and the revisions in the target.
+	Deploy(target *TargetModel) error
+
+	// Rollback reverts a successful Deploy.
+	Rollback(target *TargetModel) error
+}
+
+// planBatches splits the targets into batches executed one after the other.
+// The targets of a batch are deployed in parallel.
+func planBatches(strategy string, targets []*TargetModel) [][]*TargetModel {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	switch strategy {
+	case pkgHelm.RolloutSequential:
+		batches := make([][]*TargetModel, 0, len(targets))
+		for _, target := range targets {
+			batches = append(batches, []*TargetModel{target})
+		}
+
+		return batches
+
+	case pkgHelm.RolloutCanary:
+		if len(targets) == 1 {
+			return [][]*TargetModel{targets}
+		}
+
+		return [][]*TargetModel{targets[:1], targets[1:]}
+	}
+
+	return [][]*TargetModel{targets}
+}
+
+// execute deploys the batches of the targets. Once a batch fails, the remaining targets are skipped
+// and the targets deployed successfully are rolled back. The update function is called on every state change
+// of a target, possibly from multiple goroutines.
+func execute(strategy string, targets []*TargetModel, d deployer, update func(target *TargetModel)) bool {
+	var deployed []*TargetModel
+	failed := false
+
+	for _, batch := range planBatches(strategy, targets) {
+		if failed {
+			for _, target := range batch {
+				target.Status = pkgHelm.RolloutSkipped
+				update(target)
+			}
+
+			continue
+		}
+
+		var wg sync.WaitGroup
+		for _, target := range batch {
+			wg.Add(1)
+
+			go func(target *TargetModel) {
+				defer wg.Done()
+
+				now := time.Now()
+				target.Status = pkgHelm.RolloutRunning
+				target.StartedAt = &now
+				update(target)
+
+				err := d.Deploy(target)
+
+				now = time.Now()
+				target.FinishedAt = &now
+				if err != nil {
+					target.Status = pkgHelm.RolloutFailed
+					target.Error = err.Error()
+				} else {
+					target.Status = pkgHelm.RolloutSucceeded
+				}
+				update(target)
+			}(target)
+		}
+		wg.Wait()
+
+		for _, target := range batch {
+			if target.Status == pkgHelm.RolloutSucceeded {
+				deployed = append(deployed, target)
+			} else {
+				failed = true
+			}
+		}
+	}
+
+	if !failed {
+		return true
+	}
+
+	// Roll back in reverse order
+	for i := len(deployed) - 1; i >= 0; i-- {
+		target := deployed[i]
+
+		if err := d.Rollback(target); err != nil {
+			target.Error = "rollback failed: " + err.Error()
+		} else {
+			target.Status = pkgHelm.RolloutRolledBack
+		}
+		update(target)
+	}
+
+	return false
+}
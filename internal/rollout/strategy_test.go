@@ -0,0 +1,196 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	pkgHelm "github.com/banzaicloud/pipeline/pkg/helm"
+)
+
+type fakeDeployer struct {
+	mu         sync.Mutex
+	failing    map[uint]bool
+	deployed   []uint
+	rolledBack []uint
+}
+
+func (d *fakeDeployer) Deploy(target *TargetModel) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.deployed = append(d.deployed, target.ClusterID)
+
+	if d.failing[target.ClusterID] {
+		return errors.New("deployment failed")
+	}
+
+	target.Action = pkgHelm.RolloutUpgrade
+
+	return nil
+}
+
+func (d *fakeDeployer) Rollback(target *TargetModel) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rolledBack = append(d.rolledBack, target.ClusterID)
+
+	return nil
+}
+
+func newTargets(clusterIDs ...uint) []*TargetModel {
+	targets := make([]*TargetModel, 0, len(clusterIDs))
+	for i, clusterID := range clusterIDs {
+		targets = append(targets, &TargetModel{ClusterID: clusterID, Position: i, Status: pkgHelm.RolloutPending})
+	}
+
+	return targets
+}
+
+func TestExecute(t *testing.T) {
+	tests := map[string]struct {
+		strategy   string
+		failing    []uint
+		succeeded  bool
+		deployed   int
+		statuses   map[uint]string
+		rolledBack []uint
+	}{
+		"all at once": {
+			strategy:  pkgHelm.RolloutAllAtOnce,
+			succeeded: true,
+			deployed:  3,
+			statuses: map[uint]string{
+				1: pkgHelm.RolloutSucceeded,
+				2: pkgHelm.RolloutSucceeded,
+				3: pkgHelm.RolloutSucceeded,
+			},
+		},
+		"all at once with failure": {
+			strategy: pkgHelm.RolloutAllAtOnce,
+			failing:  []uint{2},
+			deployed: 3,
+			statuses: map[uint]string{
+				1: pkgHelm.RolloutRolledBack,
+				2: pkgHelm.RolloutFailed,
+				3: pkgHelm.RolloutRolledBack,
+			},
+			rolledBack: []uint{3, 1},
+		},
+		"sequential stops at the first failure": {
+			strategy: pkgHelm.RolloutSequential,
+			failing:  []uint{2},
+			deployed: 2,
+			statuses: map[uint]string{
+				1: pkgHelm.RolloutRolledBack,
+				2: pkgHelm.RolloutFailed,
+				3: pkgHelm.RolloutSkipped,
+			},
+			rolledBack: []uint{1},
+		},
+		"canary failure skips the rest": {
+			strategy: pkgHelm.RolloutCanary,
+			failing:  []uint{1},
+			deployed: 1,
+			statuses: map[uint]string{
+				1: pkgHelm.RolloutFailed,
+				2: pkgHelm.RolloutSkipped,
+				3: pkgHelm.RolloutSkipped,
+			},
+		},
+		"canary succeeds": {
+			strategy:  pkgHelm.RolloutCanary,
+			succeeded: true,
+			deployed:  3,
+			statuses: map[uint]string{
+				1: pkgHelm.RolloutSucceeded,
+				2: pkgHelm.RolloutSucceeded,
+				3: pkgHelm.RolloutSucceeded,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := &fakeDeployer{failing: make(map[uint]bool)}
+			for _, clusterID := range test.failing {
+				d.failing[clusterID] = true
+			}
+
+			targets := newTargets(1, 2, 3)
+
+			succeeded := execute(test.strategy, targets, d, func(target *TargetModel) {})
+
+			if succeeded != test.succeeded {
+				t.Errorf("expected succeeded to be %t", test.succeeded)
+			}
+
+			if len(d.deployed) != test.deployed {
+				t.Errorf("expected %d deployments, got %v", test.deployed, d.deployed)
+			}
+
+			for _, target := range targets {
+				if target.Status != test.statuses[target.ClusterID] {
+					t.Errorf("expected status %s in cluster %d, got %s", test.statuses[target.ClusterID], target.ClusterID, target.Status)
+				}
+			}
+
+			if len(d.rolledBack) != len(test.rolledBack) {
+				t.Fatalf("expected rollbacks %v, got %v", test.rolledBack, d.rolledBack)
+			}
+
+			for i, clusterID := range test.rolledBack {
+				if d.rolledBack[i] != clusterID {
+					t.Errorf("expected rollbacks %v, got %v", test.rolledBack, d.rolledBack)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMatchLabels(t *testing.T) {
+	labels := map[string]string{"region": "eu", "env": "prod"}
+
+	if !matchLabels(labels, map[string]string{"region": "eu"}) {
+		t.Error("expected labels to match")
+	}
+
+	if matchLabels(labels, map[string]string{"region": "eu", "env": "dev"}) {
+		t.Error("expected labels not to match")
+	}
+
+	if matchLabels(nil, map[string]string{"region": "eu"}) {
+		t.Error("expected missing labels not to match")
+	}
+}
+
+func TestMoveCanaryFirst(t *testing.T) {
+	targets, err := moveCanaryFirst(newTargets(1, 2, 3), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if targets[0].ClusterID != 2 || targets[0].Position != 0 || targets[1].ClusterID != 1 || targets[2].ClusterID != 3 {
+		t.Errorf("unexpected order: %d, %d, %d", targets[0].ClusterID, targets[1].ClusterID, targets[2].ClusterID)
+	}
+
+	if _, err := moveCanaryFirst(newTargets(1, 3), 2); err == nil {
+		t.Error("expected error for a canary cluster missing from the targets")
+	}
+}
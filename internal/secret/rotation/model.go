@@ -0,0 +1,129 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	policiesTableName  = "secret_rotation_policies"
+	rotationsTableName = "secret_rotations"
+)
+
+// PolicyModel describes the rotation policy of a cloud credential secret.
+type PolicyModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"unique_index:idx_secret_rotation_policy_org_secret"`
+	SecretID       string `gorm:"unique_index:idx_secret_rotation_policy_org_secret"`
+
+	Interval       time.Duration
+	GracePeriod    time.Duration
+	Enabled        bool
+	LastRotatedAt  *time.Time
+	NextRotationAt time.Time `gorm:"index:idx_secret_rotation_policy_next_rotation"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	CreatedBy uint
+}
+
+// TableName changes the default table name.
+func (PolicyModel) TableName() string {
+	return policiesTableName
+}
+
+// ConvertModelToEntity converts a PolicyModel to a pkgSecret.RotationPolicyResponse.
+func (m *PolicyModel) ConvertModelToEntity() pkgSecret.RotationPolicyResponse {
+	return pkgSecret.RotationPolicyResponse{
+		SecretID:       m.SecretID,
+		Interval:       m.Interval.String(),
+		GracePeriod:    m.GracePeriod.String(),
+		Enabled:        m.Enabled,
+		LastRotatedAt:  m.LastRotatedAt,
+		NextRotationAt: m.NextRotationAt,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+// RotationModel describes a single rotation of a cloud credential secret.
+type RotationModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"index:idx_secret_rotation_org_secret"`
+	SecretID       string `gorm:"index:idx_secret_rotation_org_secret"`
+
+	Status      string
+	Message     string `sql:"type:text;"`
+	FromVersion int
+	ToVersion   int
+	NewKeyID    string
+	OldKeyID    string `sql:"type:text;"`
+	RevokeAfter *time.Time
+	RevokedAt   *time.Time
+	RevokeError string `sql:"type:text;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName changes the default table name.
+func (RotationModel) TableName() string {
+	return rotationsTableName
+}
+
+// ConvertModelToEntity converts a RotationModel to a pkgSecret.RotationResponse.
+func (m *RotationModel) ConvertModelToEntity() pkgSecret.RotationResponse {
+	return pkgSecret.RotationResponse{
+		ID:          m.ID,
+		SecretID:    m.SecretID,
+		Status:      m.Status,
+		Message:     m.Message,
+		FromVersion: m.FromVersion,
+		ToVersion:   m.ToVersion,
+		NewKeyID:    m.NewKeyID,
+		OldKeyID:    m.OldKeyID,
+		RevokeAfter: m.RevokeAfter,
+		RevokedAt:   m.RevokedAt,
+		RevokeError: m.RevokeError,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// Migrate executes the table migrations for the secret rotation models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&PolicyModel{},
+		&RotationModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating secret rotation tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,90 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"time"
+
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/pkg/errors"
+)
+
+// MinInterval is the minimum interval between two rotations of a secret.
+const MinInterval = time.Hour
+
+type invalidPolicyError struct {
+	message string
+}
+
+func (e *invalidPolicyError) Error() string {
+	return e.message
+}
+
+func (e *invalidPolicyError) IsInvalid() bool {
+	return true
+}
+
+func newInvalidPolicyError(message string) error {
+	return errors.WithStack(&invalidPolicyError{message})
+}
+
+// applyPolicyRequest validates a policy request and applies it to a policy.
+// The next rotation is rescheduled when the interval changes.
+func applyPolicyRequest(policy *PolicyModel, req *pkgSecret.RotationPolicyRequest, defaultGracePeriod time.Duration, now time.Time) error {
+	interval, err := time.ParseDuration(req.Interval)
+	if err != nil {
+		return newInvalidPolicyError("invalid interval: " + req.Interval)
+	}
+
+	if interval < MinInterval {
+		return newInvalidPolicyError("interval must be at least " + MinInterval.String())
+	}
+
+	gracePeriod := defaultGracePeriod
+	if req.GracePeriod != "" {
+		gracePeriod, err = time.ParseDuration(req.GracePeriod)
+		if err != nil {
+			return newInvalidPolicyError("invalid grace period: " + req.GracePeriod)
+		}
+	}
+
+	if gracePeriod < 0 {
+		return newInvalidPolicyError("grace period must not be negative")
+	}
+
+	// Providers limit the number of active keys, so the old key must be revoked before the next rotation
+	if gracePeriod >= interval {
+		return newInvalidPolicyError("grace period must be shorter than the interval")
+	}
+
+	if policy.Interval != interval || policy.NextRotationAt.IsZero() {
+		lastRotation := now
+		if policy.LastRotatedAt != nil {
+			lastRotation = *policy.LastRotatedAt
+		}
+
+		policy.NextRotationAt = lastRotation.Add(interval)
+	}
+
+	policy.Interval = interval
+	policy.GracePeriod = gracePeriod
+
+	policy.Enabled = true
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"testing"
+	"time"
+
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/pkg/errors"
+)
+
+func TestApplyPolicyRequest(t *testing.T) {
+	now := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	lastRotation := now.Add(-48 * time.Hour)
+	disabled := false
+
+	tests := map[string]struct {
+		policy         PolicyModel
+		req            pkgSecret.RotationPolicyRequest
+		invalid        bool
+		gracePeriod    time.Duration
+		nextRotationAt time.Time
+		enabled        bool
+	}{
+		"new policy": {
+			req:            pkgSecret.RotationPolicyRequest{Interval: "720h"},
+			gracePeriod:    24 * time.Hour,
+			nextRotationAt: now.Add(720 * time.Hour),
+			enabled:        true,
+		},
+		"changed interval is counted from the last rotation": {
+			policy: PolicyModel{
+				Interval:       720 * time.Hour,
+				LastRotatedAt:  &lastRotation,
+				NextRotationAt: lastRotation.Add(720 * time.Hour),
+			},
+			req:            pkgSecret.RotationPolicyRequest{Interval: "168h", GracePeriod: "1h", Enabled: &disabled},
+			gracePeriod:    time.Hour,
+			nextRotationAt: lastRotation.Add(168 * time.Hour),
+		},
+		"unchanged interval keeps the schedule": {
+			policy: PolicyModel{
+				Interval:       720 * time.Hour,
+				NextRotationAt: now.Add(time.Hour),
+			},
+			req:            pkgSecret.RotationPolicyRequest{Interval: "720h"},
+			gracePeriod:    24 * time.Hour,
+			nextRotationAt: now.Add(time.Hour),
+			enabled:        true,
+		},
+		"invalid interval": {
+			req:     pkgSecret.RotationPolicyRequest{Interval: "monthly"},
+			invalid: true,
+		},
+		"too short interval": {
+			req:     pkgSecret.RotationPolicyRequest{Interval: "10m"},
+			invalid: true,
+		},
+		"grace period longer than interval": {
+			req:     pkgSecret.RotationPolicyRequest{Interval: "24h", GracePeriod: "48h"},
+			invalid: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			policy := test.policy
+
+			err := applyPolicyRequest(&policy, &test.req, 24*time.Hour, now)
+			if test.invalid {
+				if e, ok := errors.Cause(err).(interface{ IsInvalid() bool }); !ok || !e.IsInvalid() {
+					t.Fatalf("expected invalid error, got: %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if policy.GracePeriod != test.gracePeriod {
+				t.Errorf("expected grace period %s, got %s", test.gracePeriod, policy.GracePeriod)
+			}
+
+			if !policy.NextRotationAt.Equal(test.nextRotationAt) {
+				t.Errorf("expected next rotation at %s, got %s", test.nextRotationAt, policy.NextRotationAt)
+			}
+
+			if policy.Enabled != test.enabled {
+				t.Errorf("expected enabled %t, got %t", test.enabled, policy.Enabled)
+			}
+		})
+	}
+}
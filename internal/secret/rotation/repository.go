@@ -0,0 +1,160 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"time"
+
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the rotation policies and the rotation history of secrets.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+type policyNotFoundError struct {
+	organizationID uint
+	secretID       string
+}
+
+func (e *policyNotFoundError) Error() string {
+	return "secret rotation policy not found"
+}
+
+func (e *policyNotFoundError) Context() []interface{} {
+	return []interface{}{
+		"organization", e.organizationID,
+		"secret", e.secretID,
+	}
+}
+
+func (e *policyNotFoundError) NotFound() bool {
+	return true
+}
+
+// FindPolicy returns the rotation policy of a secret.
+func (r *Repository) FindPolicy(organizationID uint, secretID string) (*PolicyModel, error) {
+	var policy PolicyModel
+
+	err := r.db.Where(&PolicyModel{OrganizationID: organizationID, SecretID: secretID}).First(&policy).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, errors.WithStack(&policyNotFoundError{
+			organizationID: organizationID,
+			secretID:       secretID,
+		})
+	} else if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not get secret rotation policy"),
+			"organization", organizationID,
+			"secret", secretID,
+		)
+	}
+
+	return &policy, nil
+}
+
+// FindDuePolicies returns the enabled rotation policies which should have been executed until the given time.
+func (r *Repository) FindDuePolicies(until time.Time) ([]*PolicyModel, error) {
+	var policies []*PolicyModel
+
+	err := r.db.Where("enabled = ? AND next_rotation_at <= ?", true, until).Order("next_rotation_at").Find(&policies).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch due secret rotation policies")
+	}
+
+	return policies, nil
+}
+
+// SavePolicy persists a rotation policy.
+func (r *Repository) SavePolicy(policy *PolicyModel) error {
+	err := r.db.Save(policy).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save secret rotation policy"),
+			"organization", policy.OrganizationID,
+			"secret", policy.SecretID,
+		)
+	}
+
+	return nil
+}
+
+// DeletePolicy deletes a rotation policy.
+func (r *Repository) DeletePolicy(policy *PolicyModel) error {
+	err := r.db.Delete(policy).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not delete secret rotation policy"),
+			"organization", policy.OrganizationID,
+			"secret", policy.SecretID,
+		)
+	}
+
+	return nil
+}
+
+// FindRotations returns the rotation history of a secret, the latest first.
+func (r *Repository) FindRotations(organizationID uint, secretID string) ([]*RotationModel, error) {
+	var rotations []*RotationModel
+
+	err := r.db.Where(&RotationModel{OrganizationID: organizationID, SecretID: secretID}).Order("id DESC").Find(&rotations).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch secret rotations"),
+			"organization", organizationID,
+			"secret", secretID,
+		)
+	}
+
+	return rotations, nil
+}
+
+// FindExpiredKeys returns the successful rotations whose replaced key should have been revoked until the given time.
+func (r *Repository) FindExpiredKeys(until time.Time) ([]*RotationModel, error) {
+	var rotations []*RotationModel
+
+	err := r.db.
+		Where("status = ? AND revoked_at IS NULL AND revoke_after <= ?", pkgSecret.RotationSucceeded, until).
+		Order("id").
+		Find(&rotations).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "could not fetch expired secret rotation keys")
+	}
+
+	return rotations, nil
+}
+
+// SaveRotation persists a rotation.
+func (r *Repository) SaveRotation(rotation *RotationModel) error {
+	err := r.db.Save(rotation).Error
+	if err != nil {
+		return emperror.With(
+			errors.Wrap(err, "could not save secret rotation"),
+			"organization", rotation.OrganizationID,
+			"secret", rotation.SecretID,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/pkg/errors"
+)
+
+// RotatedKey is a new access key of a cloud credential.
+type RotatedKey struct {
+	// Values are the secret values containing the new key.
+	Values map[string]string
+
+	// NewKeyID identifies the new key.
+	NewKeyID string
+
+	// OldKeyID identifies the replaced key(s).
+	OldKeyID string
+}
+
+// KeyRotator creates and revokes the access keys of a cloud credential through the provider API.
+type KeyRotator interface {
+	// CreateKey creates a new access key using the current credentials.
+	CreateKey(ctx context.Context, values map[string]string) (*RotatedKey, error)
+
+	// RevokeKey revokes an access key using the given credentials.
+	RevokeKey(ctx context.Context, values map[string]string, keyID string) error
+}
+
+// NewKeyRotator returns the key rotator of a secret type.
+func NewKeyRotator(secretType string) (KeyRotator, error) {
+	switch secretType {
+	case pkgCluster.Alibaba:
+		return &alibabaKeyRotator{}, nil
+	case pkgCluster.Amazon:
+		return &amazonKeyRotator{}, nil
+	case pkgCluster.Azure:
+		return &azureKeyRotator{}, nil
+	case pkgCluster.Google:
+		return &googleKeyRotator{}, nil
+	default:
+		return nil, errors.WithStack(&unsupportedSecretTypeError{secretType: secretType})
+	}
+}
+
+type unsupportedSecretTypeError struct {
+	secretType string
+}
+
+func (e *unsupportedSecretTypeError) Error() string {
+	return "secret type does not support rotation: " + e.secretType
+}
+
+func (e *unsupportedSecretTypeError) IsInvalid() bool {
+	return true
+}
+
+// copyValues returns a copy of the secret values.
+func copyValues(values map[string]string) map[string]string {
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+
+	return result
+}
@@ -0,0 +1,85 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/ram"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/pkg/errors"
+)
+
+const defaultAlibabaRegion = "cn-beijing"
+
+// alibabaKeyRotator rotates the RAM access keys of the user owning the credentials.
+type alibabaKeyRotator struct{}
+
+func newRAMClient(values map[string]string) (*ram.Client, error) {
+	region := values[pkgSecret.AlibabaRegion]
+	if region == "" {
+		region = defaultAlibabaRegion
+	}
+
+	client, err := ram.NewClientWithAccessKey(region, values[pkgSecret.AlibabaAccessKeyId], values[pkgSecret.AlibabaSecretAccessKey])
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create RAM client")
+	}
+
+	return client, nil
+}
+
+func (r *alibabaKeyRotator) CreateKey(ctx context.Context, values map[string]string) (*RotatedKey, error) {
+	client, err := newRAMClient(values)
+	if err != nil {
+		return nil, err
+	}
+
+	req := ram.CreateCreateAccessKeyRequest()
+	req.SetScheme(requests.HTTPS)
+
+	resp, err := client.CreateAccessKey(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create RAM access key")
+	}
+
+	newValues := copyValues(values)
+	newValues[pkgSecret.AlibabaAccessKeyId] = resp.AccessKey.AccessKeyId
+	newValues[pkgSecret.AlibabaSecretAccessKey] = resp.AccessKey.AccessKeySecret
+
+	return &RotatedKey{
+		Values:   newValues,
+		NewKeyID: resp.AccessKey.AccessKeyId,
+		OldKeyID: values[pkgSecret.AlibabaAccessKeyId],
+	}, nil
+}
+
+func (r *alibabaKeyRotator) RevokeKey(ctx context.Context, values map[string]string, keyID string) error {
+	client, err := newRAMClient(values)
+	if err != nil {
+		return err
+	}
+
+	req := ram.CreateDeleteAccessKeyRequest()
+	req.SetScheme(requests.HTTPS)
+	req.UserAccessKeyId = keyID
+
+	if _, err := client.DeleteAccessKey(req); err != nil {
+		return errors.Wrap(err, "could not delete RAM access key")
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/banzaicloud/pipeline/pkg/amazon"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret/verify"
+	"github.com/pkg/errors"
+)
+
+// amazonKeyRotator rotates IAM user access keys.
+// By default the keys of the IAM user owning the credentials are rotated,
+// otherwise the keys of userName are rotated using the adminValues credentials (eg. EKS cluster users).
+type amazonKeyRotator struct {
+	userName    string
+	adminValues map[string]string
+}
+
+func (r *amazonKeyRotator) newIAMClient(values map[string]string) (*iam.IAM, error) {
+	if r.adminValues != nil {
+		values = r.adminValues
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: verify.CreateAWSCredentials(values),
+		Region:      aws.String(verify.DefaultRegion),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create AWS session")
+	}
+
+	return iam.New(sess), nil
+}
+
+func (r *amazonKeyRotator) userNameParam() *string {
+	if r.userName == "" {
+		// IAM determines the user implicitly based on the access key signing the request
+		return nil
+	}
+
+	return aws.String(r.userName)
+}
+
+func (r *amazonKeyRotator) CreateKey(ctx context.Context, values map[string]string) (*RotatedKey, error) {
+	client, err := r.newIAMClient(values)
+	if err != nil {
+		return nil, err
+	}
+
+	accessKey, err := amazon.CreateUserAccessKey(client, r.userNameParam())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create IAM access key")
+	}
+
+	newValues := copyValues(values)
+	newValues[pkgSecret.AwsAccessKeyId] = aws.StringValue(accessKey.AccessKeyId)
+	newValues[pkgSecret.AwsSecretAccessKey] = aws.StringValue(accessKey.SecretAccessKey)
+
+	return &RotatedKey{
+		Values:   newValues,
+		NewKeyID: aws.StringValue(accessKey.AccessKeyId),
+		OldKeyID: values[pkgSecret.AwsAccessKeyId],
+	}, nil
+}
+
+func (r *amazonKeyRotator) RevokeKey(ctx context.Context, values map[string]string, keyID string) error {
+	client, err := r.newIAMClient(values)
+	if err != nil {
+		return err
+	}
+
+	err = amazon.DeleteUserAccessKey(client, r.userNameParam(), aws.String(keyID))
+	if err != nil {
+		return errors.Wrap(err, "could not delete IAM access key")
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/graphrbac/1.6/graphrbac"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/Azure/go-autorest/autorest/date"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/pkg/errors"
+	"github.com/satori/go.uuid"
+)
+
+const (
+	azurePasswordLength   = 44
+	azurePasswordValidity = 2 * 365 * 24 * time.Hour
+)
+
+// azureKeyRotator rotates the password credentials of the application behind the service principal.
+// The service principal needs permission to manage its own application in Azure Active Directory.
+type azureKeyRotator struct{}
+
+type azureApplication struct {
+	client   graphrbac.ApplicationsClient
+	objectID string
+}
+
+func getAzureApplication(ctx context.Context, values map[string]string) (*azureApplication, error) {
+	config := auth.NewClientCredentialsConfig(
+		values[pkgSecret.AzureClientId],
+		values[pkgSecret.AzureClientSecret],
+		values[pkgSecret.AzureTenantId],
+	)
+	config.Resource = azure.PublicCloud.GraphEndpoint
+
+	authorizer, err := config.Authorizer()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create Azure authorizer")
+	}
+
+	client := graphrbac.NewApplicationsClient(values[pkgSecret.AzureTenantId])
+	client.Authorizer = authorizer
+
+	page, err := client.List(ctx, fmt.Sprintf("appId eq '%s'", values[pkgSecret.AzureClientId]))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not find Azure application")
+	}
+
+	applications := page.Values()
+	if len(applications) == 0 || applications[0].ObjectID == nil {
+		return nil, errors.New("could not find Azure application of the service principal")
+	}
+
+	return &azureApplication{client: client, objectID: *applications[0].ObjectID}, nil
+}
+
+func (a *azureApplication) listPasswordCredentials(ctx context.Context) ([]graphrbac.PasswordCredential, error) {
+	result, err := a.client.ListPasswordCredentials(ctx, a.objectID)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list Azure application password credentials")
+	}
+
+	if result.Value == nil {
+		return nil, nil
+	}
+
+	return *result.Value, nil
+}
+
+func (a *azureApplication) updatePasswordCredentials(ctx context.Context, credentials []graphrbac.PasswordCredential) error {
+	_, err := a.client.UpdatePasswordCredentials(ctx, a.objectID, graphrbac.PasswordCredentialsUpdateParameters{
+		Value: &credentials,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not update Azure application password credentials")
+	}
+
+	return nil
+}
+
+func (r *azureKeyRotator) CreateKey(ctx context.Context, values map[string]string) (*RotatedKey, error) {
+	application, err := getAzureApplication(ctx, values)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := application.listPasswordCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The client secret does not identify its credential, so every existing credential is replaced
+	var oldKeyIDs []string
+	for _, credential := range credentials {
+		if credential.KeyID != nil {
+			oldKeyIDs = append(oldKeyIDs, *credential.KeyID)
+		}
+	}
+
+	password, err := secret.RandomString("randAlphaNum", azurePasswordLength)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not generate password")
+	}
+
+	keyID := uuid.NewV4().String()
+	now := time.Now()
+
+	credentials = append(credentials, graphrbac.PasswordCredential{
+		KeyID:     &keyID,
+		Value:     &password,
+		StartDate: &date.Time{Time: now},
+		EndDate:   &date.Time{Time: now.Add(azurePasswordValidity)},
+	})
+
+	if err := application.updatePasswordCredentials(ctx, credentials); err != nil {
+		return nil, err
+	}
+
+	newValues := copyValues(values)
+	newValues[pkgSecret.AzureClientSecret] = password
+
+	return &RotatedKey{
+		Values:   newValues,
+		NewKeyID: keyID,
+		OldKeyID: strings.Join(oldKeyIDs, ","),
+	}, nil
+}
+
+func (r *azureKeyRotator) RevokeKey(ctx context.Context, values map[string]string, keyID string) error {
+	application, err := getAzureApplication(ctx, values)
+	if err != nil {
+		return err
+	}
+
+	credentials, err := application.listPasswordCredentials(ctx)
+	if err != nil {
+		return err
+	}
+
+	revokedKeyIDs := make(map[string]bool)
+	for _, id := range strings.Split(keyID, ",") {
+		revokedKeyIDs[id] = true
+	}
+
+	var keptCredentials []graphrbac.PasswordCredential
+	for _, credential := range credentials {
+		if credential.KeyID == nil || !revokedKeyIDs[*credential.KeyID] {
+			keptCredentials = append(keptCredentials, credential)
+		}
+	}
+
+	if len(keptCredentials) == len(credentials) {
+		return nil
+	}
+
+	return application.updatePasswordCredentials(ctx, keptCredentials)
+}
@@ -0,0 +1,106 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret/verify"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/iam/v1"
+)
+
+// googleKeyRotator rotates the keys of the service account owning the credentials.
+type googleKeyRotator struct{}
+
+func newIAMService(ctx context.Context, values map[string]string) (*iam.Service, error) {
+	credentials, err := json.Marshal(verify.CreateServiceAccount(values))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode service account")
+	}
+
+	config, err := google.JWTConfigFromJSON(credentials, iam.CloudPlatformScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse service account")
+	}
+
+	service, err := iam.New(config.Client(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create IAM client")
+	}
+
+	return service, nil
+}
+
+func serviceAccountName(values map[string]string) string {
+	return fmt.Sprintf("projects/%s/serviceAccounts/%s", values[pkgSecret.ProjectId], values[pkgSecret.ClientEmail])
+}
+
+func (r *googleKeyRotator) CreateKey(ctx context.Context, values map[string]string) (*RotatedKey, error) {
+	service, err := newIAMService(ctx, values)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := service.Projects.ServiceAccounts.Keys.
+		Create(serviceAccountName(values), &iam.CreateServiceAccountKeyRequest{}).
+		Context(ctx).
+		Do()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create service account key")
+	}
+
+	keyFile, err := base64.StdEncoding.DecodeString(key.PrivateKeyData)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode service account key")
+	}
+
+	var keyValues map[string]string
+	if err := json.Unmarshal(keyFile, &keyValues); err != nil {
+		return nil, errors.Wrap(err, "could not parse service account key")
+	}
+
+	// The fields of the key file are the same as the secret keys
+	newValues := copyValues(values)
+	for k, v := range keyValues {
+		newValues[k] = v
+	}
+
+	return &RotatedKey{
+		Values:   newValues,
+		NewKeyID: newValues[pkgSecret.PrivateKeyId],
+		OldKeyID: values[pkgSecret.PrivateKeyId],
+	}, nil
+}
+
+func (r *googleKeyRotator) RevokeKey(ctx context.Context, values map[string]string, keyID string) error {
+	service, err := newIAMService(ctx, values)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s/keys/%s", serviceAccountName(values), keyID)
+
+	if _, err := service.Projects.ServiceAccounts.Keys.Delete(name).Context(ctx).Do(); err != nil {
+		return errors.Wrap(err, "could not delete service account key")
+	}
+
+	return nil
+}
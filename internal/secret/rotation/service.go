@@ -0,0 +1,547 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	pkgSecret "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/banzaicloud/pipeline/secret"
+	"github.com/banzaicloud/pipeline/secret/verify"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// rotatedBy is recorded as the author of the secret versions created by rotations
+	rotatedBy = "pipeline-secret-rotation"
+
+	// eksClusterUserAccessKeyTagPrefix marks the access key secrets of EKS cluster users
+	eksClusterUserAccessKeyTagPrefix = "eksClusterUserAccessKey:"
+
+	// New keys need some time to propagate through the provider before they can be used
+	verifyAttempts = 6
+	verifyDelay    = 10 * time.Second
+)
+
+type secretStore interface {
+	Get(organizationID uint, secretID string) (*secret.SecretItemResponse, error)
+	Update(organizationID uint, secretID string, request *secret.CreateSecretRequest) error
+}
+
+type clusterManager interface {
+	GetAllClusters(ctx context.Context) ([]cluster.CommonCluster, error)
+	GetClusterByName(ctx context.Context, organizationID uint, clusterName string) (cluster.CommonCluster, error)
+	GetClustersBySecretID(ctx context.Context, organizationID uint, secretID string) ([]cluster.CommonCluster, error)
+}
+
+// Service rotates the cloud credentials stored in secrets according to their rotation policies.
+type Service struct {
+	repository   *Repository
+	secrets      secretStore
+	clusters     clusterManager
+	gracePeriod  time.Duration
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(
+	repository *Repository,
+	secrets secretStore,
+	clusters clusterManager,
+	gracePeriod time.Duration,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Service {
+	return &Service{
+		repository:   repository,
+		secrets:      secrets,
+		clusters:     clusters,
+		gracePeriod:  gracePeriod,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// GetPolicy returns the rotation policy of a secret.
+func (s *Service) GetPolicy(organizationID uint, secretID string) (*PolicyModel, error) {
+	return s.repository.FindPolicy(organizationID, secretID)
+}
+
+// SetPolicy creates or updates the rotation policy of a secret.
+func (s *Service) SetPolicy(organizationID uint, secretID string, req *pkgSecret.RotationPolicyRequest, userID uint) (*PolicyModel, error) {
+	secretItem, err := s.secrets.Get(organizationID, secretID)
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get secret"), "secret", secretID)
+	}
+
+	if _, err := NewKeyRotator(secretItem.Type); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.repository.FindPolicy(organizationID, secretID)
+	if isNotFound(err) {
+		policy = &PolicyModel{
+			OrganizationID: organizationID,
+			SecretID:       secretID,
+			CreatedBy:      userID,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := applyPolicyRequest(policy, req, s.gracePeriod, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repository.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// DeletePolicy deletes the rotation policy of a secret.
+func (s *Service) DeletePolicy(organizationID uint, secretID string) error {
+	policy, err := s.repository.FindPolicy(organizationID, secretID)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.DeletePolicy(policy)
+}
+
+// ListRotations returns the rotation history of a secret.
+func (s *Service) ListRotations(organizationID uint, secretID string) ([]*RotationModel, error) {
+	return s.repository.FindRotations(organizationID, secretID)
+}
+
+// Rotate creates a new key for a secret, verifies it, stores it as a new secret version
+// and installs it into the clusters using the secret. The old key is revoked after the grace period.
+// Failed rotations are recorded and returned without error.
+func (s *Service) Rotate(ctx context.Context, organizationID uint, secretID string) (*RotationModel, error) {
+	logger := s.logger.WithFields(logrus.Fields{"organization": organizationID, "secret": secretID})
+
+	policy, err := s.repository.FindPolicy(organizationID, secretID)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	gracePeriod := s.gracePeriod
+	if policy != nil {
+		gracePeriod = policy.GracePeriod
+	}
+
+	current, err := s.secrets.Get(organizationID, secretID)
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get secret"), "secret", secretID)
+	}
+
+	rotator, err := s.newKeyRotator(ctx, organizationID, current)
+	if err != nil {
+		return nil, err
+	}
+
+	rotation := &RotationModel{
+		OrganizationID: organizationID,
+		SecretID:       secretID,
+		FromVersion:    current.Version,
+	}
+
+	logger.Info("rotating secret")
+
+	key, err := s.rotate(ctx, rotator, organizationID, current)
+	if err != nil {
+		logger.WithField("error", err.Error()).Warn("secret rotation failed")
+
+		rotation.Status = pkgSecret.RotationFailed
+		rotation.Message = err.Error()
+	} else {
+		rotation.Status = pkgSecret.RotationSucceeded
+		rotation.NewKeyID = key.NewKeyID
+		rotation.OldKeyID = key.OldKeyID
+
+		if key.OldKeyID != "" {
+			revokeAfter := time.Now().Add(gracePeriod)
+			rotation.RevokeAfter = &revokeAfter
+		}
+
+		updated, err := s.secrets.Get(organizationID, secretID)
+		if err != nil {
+			s.errorHandler.Handle(emperror.With(errors.Wrap(err, "could not get rotated secret"), "secret", secretID))
+		} else {
+			rotation.ToVersion = updated.Version
+
+			if err := s.updateEKSKubeConfig(ctx, organizationID, updated); err != nil {
+				s.errorHandler.Handle(err)
+				rotation.Message = err.Error()
+			}
+
+			if err := s.syncClusters(ctx, organizationID, updated); err != nil {
+				s.errorHandler.Handle(err)
+				rotation.Message = err.Error()
+			}
+		}
+
+		logger.WithField("version", rotation.ToVersion).Info("secret rotated")
+	}
+
+	if err := s.repository.SaveRotation(rotation); err != nil {
+		return nil, err
+	}
+
+	if policy != nil {
+		now := time.Now()
+		if rotation.Status == pkgSecret.RotationSucceeded {
+			policy.LastRotatedAt = &now
+		}
+		// Failed rotations are retried in the next interval as well to avoid hammering the provider
+		policy.NextRotationAt = now.Add(policy.Interval)
+
+		if err := s.repository.SavePolicy(policy); err != nil {
+			return nil, err
+		}
+	}
+
+	return rotation, nil
+}
+
+// rotate creates and verifies a new key and stores it as a new secret version.
+// The new key is revoked if it cannot be used.
+func (s *Service) rotate(ctx context.Context, rotator KeyRotator, organizationID uint, current *secret.SecretItemResponse) (*RotatedKey, error) {
+	key, err := rotator.CreateKey(ctx, current.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	revokeNewKey := func(err error) error {
+		if revokeErr := rotator.RevokeKey(ctx, current.Values, key.NewKeyID); revokeErr != nil {
+			s.errorHandler.Handle(emperror.With(
+				errors.Wrap(revokeErr, "could not revoke key of failed rotation"),
+				"secret", current.ID,
+				"key", key.NewKeyID,
+			))
+		}
+
+		return err
+	}
+
+	if err := verifyKey(ctx, current.Type, key.Values); err != nil {
+		return nil, revokeNewKey(errors.Wrap(err, "could not verify new key"))
+	}
+
+	request := &secret.CreateSecretRequest{
+		Name:      current.Name,
+		Type:      current.Type,
+		Values:    key.Values,
+		Tags:      current.Tags,
+		Version:   &current.Version,
+		UpdatedBy: rotatedBy,
+	}
+
+	if err := s.secrets.Update(organizationID, current.ID, request); err != nil {
+		return nil, revokeNewKey(errors.Wrap(err, "could not store new key"))
+	}
+
+	return key, nil
+}
+
+func verifyKey(ctx context.Context, secretType string, values map[string]string) error {
+	verifier := verify.NewVerifier(secretType, values)
+	if verifier == nil {
+		return nil
+	}
+
+	var err error
+	for i := 0; i < verifyAttempts; i++ {
+		if err = verifier.VerifySecret(); err == nil {
+			return nil
+		}
+
+		select {
+		case <-time.After(verifyDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return err
+}
+
+// eksClusterName returns the name of the EKS cluster whose cluster user access key is stored in the secret.
+func eksClusterName(secretItem *secret.SecretItemResponse) (string, bool) {
+	for _, tag := range secretItem.Tags {
+		if strings.HasPrefix(tag, eksClusterUserAccessKeyTagPrefix) {
+			return strings.TrimPrefix(tag, eksClusterUserAccessKeyTagPrefix), true
+		}
+	}
+
+	return "", false
+}
+
+// newKeyRotator returns the key rotator of a secret.
+// EKS cluster user keys are rotated with the credentials of the cluster.
+func (s *Service) newKeyRotator(ctx context.Context, organizationID uint, secretItem *secret.SecretItemResponse) (KeyRotator, error) {
+	clusterName, ok := eksClusterName(secretItem)
+	if !ok {
+		return NewKeyRotator(secretItem.Type)
+	}
+
+	commonCluster, err := s.clusters.GetClusterByName(ctx, organizationID, clusterName)
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get EKS cluster"), "cluster", clusterName)
+	}
+
+	clusterSecret, err := commonCluster.GetSecretWithValidation()
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get EKS cluster secret"), "cluster", clusterName)
+	}
+
+	return &amazonKeyRotator{userName: clusterName, adminValues: clusterSecret.Values}, nil
+}
+
+// updateEKSKubeConfig regenerates and stores the kubeconfig of an EKS cluster after its cluster user key is rotated,
+// as the kubeconfig embeds the key.
+func (s *Service) updateEKSKubeConfig(ctx context.Context, organizationID uint, secretItem *secret.SecretItemResponse) error {
+	clusterName, ok := eksClusterName(secretItem)
+	if !ok {
+		return nil
+	}
+
+	// A freshly loaded cluster reads the rotated key from the secret store
+	commonCluster, err := s.clusters.GetClusterByName(ctx, organizationID, clusterName)
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not get EKS cluster"), "cluster", clusterName)
+	}
+
+	kubeConfig, err := commonCluster.DownloadK8sConfig()
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not generate kubeconfig with the rotated key"), "cluster", clusterName)
+	}
+
+	if err := cluster.StoreKubernetesConfig(commonCluster, kubeConfig); err != nil {
+		return emperror.With(errors.Wrap(err, "could not store kubeconfig with the rotated key"), "cluster", clusterName)
+	}
+
+	return nil
+}
+
+// syncClusters updates the copies of the secret installed into the clusters using it.
+func (s *Service) syncClusters(ctx context.Context, organizationID uint, secretItem *secret.SecretItemResponse) error {
+	clusters, err := s.clusters.GetClustersBySecretID(ctx, organizationID, secretItem.ID)
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not get clusters of secret"), "secret", secretItem.ID)
+	}
+
+	var failed []string
+
+	for _, commonCluster := range clusters {
+		if err := syncCluster(commonCluster, secretItem); err != nil {
+			s.errorHandler.Handle(emperror.With(err, "cluster", commonCluster.GetName(), "secret", secretItem.ID))
+			failed = append(failed, commonCluster.GetName())
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf("could not install rotated secret into clusters: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+func syncCluster(commonCluster cluster.CommonCluster, secretItem *secret.SecretItemResponse) error {
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return errors.Wrap(err, "could not get k8s config")
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return errors.Wrap(err, "could not create k8s client")
+	}
+
+	kubeSecrets, err := client.CoreV1().Secrets(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: "metadata.name=" + secretItem.Name,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not list k8s secrets")
+	}
+
+	for _, kubeSecret := range kubeSecrets.Items {
+		query := &pkgSecret.ListSecretsQuery{IDs: []string{secretItem.ID}}
+
+		_, err := cluster.InstallSecretsByK8SConfig(kubeConfig, commonCluster.GetOrganizationId(), query, kubeSecret.Namespace)
+		if err != nil {
+			return emperror.With(errors.Wrap(err, "could not update k8s secret"), "namespace", kubeSecret.Namespace)
+		}
+	}
+
+	return nil
+}
+
+// RotateDueSecrets rotates the secrets whose rotation policy is due.
+func (s *Service) RotateDueSecrets(ctx context.Context) error {
+	policies, err := s.repository.FindDuePolicies(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		if _, err := s.Rotate(ctx, policy.OrganizationID, policy.SecretID); err != nil {
+			s.errorHandler.Handle(emperror.With(
+				errors.Wrap(err, "could not rotate secret"),
+				"organization", policy.OrganizationID,
+				"secret", policy.SecretID,
+			))
+		}
+	}
+
+	return nil
+}
+
+// RevokeExpiredKeys revokes the replaced keys whose grace period is over.
+func (s *Service) RevokeExpiredKeys(ctx context.Context) error {
+	rotations, err := s.repository.FindExpiredKeys(time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, rotation := range rotations {
+		err := s.revokeKey(ctx, rotation)
+		if err != nil {
+			s.errorHandler.Handle(emperror.With(
+				errors.Wrap(err, "could not revoke replaced key"),
+				"organization", rotation.OrganizationID,
+				"secret", rotation.SecretID,
+			))
+
+			rotation.RevokeError = err.Error()
+		} else {
+			now := time.Now()
+			rotation.RevokedAt = &now
+			rotation.RevokeError = ""
+		}
+
+		if err := s.repository.SaveRotation(rotation); err != nil {
+			s.errorHandler.Handle(err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) revokeKey(ctx context.Context, rotation *RotationModel) error {
+	current, err := s.secrets.Get(rotation.OrganizationID, rotation.SecretID)
+	if err == secret.ErrSecretNotExists {
+		// Nothing to revoke the key with, give up
+		rotation.RevokeAfter = nil
+
+		return errors.New("secret does not exist anymore")
+	} else if err != nil {
+		return errors.Wrap(err, "could not get secret")
+	}
+
+	rotator, err := s.newKeyRotator(ctx, rotation.OrganizationID, current)
+	if err != nil {
+		return err
+	}
+
+	return rotator.RevokeKey(ctx, current.Values, rotation.OldKeyID)
+}
+
+// EnsureEKSClusterUserKeyPolicies creates rotation policies with the given interval
+// for the access keys of EKS cluster users which do not have one yet.
+func (s *Service) EnsureEKSClusterUserKeyPolicies(ctx context.Context, interval time.Duration) error {
+	clusters, err := s.clusters.GetAllClusters(ctx)
+	if err != nil {
+		return errors.Wrap(err, "could not get clusters")
+	}
+
+	for _, commonCluster := range clusters {
+		if commonCluster.GetDistribution() != pkgCluster.EKS {
+			continue
+		}
+
+		organizationID := commonCluster.GetOrganizationId()
+		secretID := secret.GenerateSecretIDFromName(commonCluster.GetName() + "-key")
+
+		_, err := s.repository.FindPolicy(organizationID, secretID)
+		if err == nil {
+			continue
+		} else if !isNotFound(err) {
+			return err
+		}
+
+		req := &pkgSecret.RotationPolicyRequest{Interval: interval.String()}
+
+		if _, err := s.SetPolicy(organizationID, secretID, req, 0); err != nil {
+			s.errorHandler.Handle(emperror.With(
+				errors.Wrap(err, "could not create EKS cluster user key rotation policy"),
+				"cluster", commonCluster.GetName(),
+			))
+		}
+	}
+
+	return nil
+}
+
+// Run executes the due rotations and revocations periodically until the context is cancelled.
+func (s *Service) Run(ctx context.Context, interval time.Duration, eksClusterUserKeyInterval time.Duration) {
+	run := func() {
+		s.logger.WithField("interval", interval.String()).Debug("rotating secrets")
+
+		if eksClusterUserKeyInterval > 0 {
+			if err := s.EnsureEKSClusterUserKeyPolicies(ctx, eksClusterUserKeyInterval); err != nil {
+				s.errorHandler.Handle(emperror.Wrap(err, "could not ensure EKS cluster user key rotation policies"))
+			}
+		}
+
+		if err := s.RotateDueSecrets(ctx); err != nil {
+			s.errorHandler.Handle(emperror.Wrap(err, "could not rotate due secrets"))
+		}
+
+		if err := s.RevokeExpiredKeys(ctx); err != nil {
+			s.errorHandler.Handle(emperror.Wrap(err, "could not revoke expired keys"))
+		}
+	}
+
+	run()
+
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			run()
+		case <-ctx.Done():
+			s.logger.Debug("closing ticker")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+func isNotFound(err error) bool {
+	notFoundErr, ok := errors.Cause(err).(interface{ NotFound() bool })
+
+	return ok && notFoundErr.NotFound()
+}
@@ -0,0 +1,34 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rotation
+
+import (
+	"testing"
+
+	"github.com/banzaicloud/pipeline/secret"
+)
+
+func TestEKSClusterName(t *testing.T) {
+	secretItem := &secret.SecretItemResponse{Tags: []string{"banzai:readonly", eksClusterUserAccessKeyTagPrefix + "my-cluster"}}
+
+	clusterName, ok := eksClusterName(secretItem)
+	if !ok || clusterName != "my-cluster" {
+		t.Errorf("expected the cluster user key of my-cluster, got %q (%t)", clusterName, ok)
+	}
+
+	if _, ok := eksClusterName(&secret.SecretItemResponse{Tags: []string{"banzai:readonly"}}); ok {
+		t.Error("expected a secret without the EKS cluster user tag not to belong to a cluster")
+	}
+}
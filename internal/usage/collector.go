@@ -0,0 +1,297 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Well-known node labels
+const (
+	instanceTypeLabel = "beta.kubernetes.io/instance-type"
+	regionLabel       = "failure-domain.beta.kubernetes.io/region"
+	zoneLabel         = "failure-domain.beta.kubernetes.io/zone"
+)
+
+const metricsAPIPath = "/apis/metrics.k8s.io/v1beta1/"
+
+// resources is an amount of CPU in millicores and memory in bytes.
+type resources struct {
+	cpu    int64
+	memory int64
+}
+
+func (r *resources) add(list v1.ResourceList) {
+	if q, ok := list[v1.ResourceCPU]; ok {
+		r.cpu += q.MilliValue()
+	}
+
+	if q, ok := list[v1.ResourceMemory]; ok {
+		r.memory += q.Value()
+	}
+}
+
+// snapshot is the state of the nodes and the pods of a cluster at a point in time.
+type snapshot struct {
+	nodes []v1.Node
+	pods  []v1.Pod
+
+	// nodeUsage is the usage of the nodes by node name
+	nodeUsage map[string]resources
+	// podUsage is the usage of the pods by namespace/name
+	podUsage map[string]resources
+}
+
+// clusterInfo identifies the cluster of the samples.
+type clusterInfo struct {
+	organizationID uint
+	id             uint
+	name           string
+}
+
+// collectSnapshot reads the nodes, the non-terminated pods and their usage from a cluster.
+// The usage is zero if the metrics API is not available in the cluster.
+func collectSnapshot(client kubernetes.Interface, logger logrus.FieldLogger) (*snapshot, error) {
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list nodes")
+	}
+
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(metav1.ListOptions{
+		FieldSelector: "status.phase!=" + string(v1.PodSucceeded) + ",status.phase!=" + string(v1.PodFailed),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list pods")
+	}
+
+	s := &snapshot{
+		nodes:     nodes.Items,
+		pods:      pods.Items,
+		nodeUsage: make(map[string]resources),
+		podUsage:  make(map[string]resources),
+	}
+
+	nodeMetrics, err := getMetrics(client, "nodes")
+	if err != nil {
+		logger.WithError(err).Warn("could not get node metrics, usage is not recorded")
+
+		return s, nil
+	}
+
+	for _, item := range nodeMetrics.Items {
+		var usage resources
+		usage.add(item.Usage)
+		s.nodeUsage[item.Metadata.Name] = usage
+	}
+
+	podMetrics, err := getMetrics(client, "pods")
+	if err != nil {
+		logger.WithError(err).Warn("could not get pod metrics, usage is not recorded")
+
+		return s, nil
+	}
+
+	for _, item := range podMetrics.Items {
+		var usage resources
+		for _, container := range item.Containers {
+			usage.add(container.Usage)
+		}
+		s.podUsage[item.Metadata.Namespace+"/"+item.Metadata.Name] = usage
+	}
+
+	return s, nil
+}
+
+// metricsList is the subset of the metrics API node and pod metrics lists used by the collector.
+type metricsList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Usage      v1.ResourceList `json:"usage"`
+		Containers []struct {
+			Usage v1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+func getMetrics(client kubernetes.Interface, resource string) (*metricsList, error) {
+	raw, err := client.CoreV1().RESTClient().Get().AbsPath(metricsAPIPath + resource).DoRaw()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get %s metrics", resource)
+	}
+
+	var list metricsList
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s metrics", resource)
+	}
+
+	return &list, nil
+}
+
+// buildSamples creates the node and the namespace samples of a snapshot accounting for a number of hours.
+// The cost of each node is allocated to the namespaces by the average of their CPU and memory request
+// share of the allocatable resources of the node, the rest of the cost remains unallocated.
+func buildSamples(
+	cluster clusterInfo,
+	s *snapshot,
+	price func(node v1.Node) (float64, bool),
+	hours float64,
+	sampledAt time.Time,
+) ([]*NodeSampleModel, []*NamespaceSampleModel) {
+	// requests by node name and namespace
+	nodeRequests := make(map[string]map[string]resources)
+	for _, pod := range s.pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+
+		requests := podRequests(pod)
+
+		if nodeRequests[pod.Spec.NodeName] == nil {
+			nodeRequests[pod.Spec.NodeName] = make(map[string]resources)
+		}
+
+		nsRequests := nodeRequests[pod.Spec.NodeName][pod.Namespace]
+		nsRequests.cpu += requests.cpu
+		nsRequests.memory += requests.memory
+		nodeRequests[pod.Spec.NodeName][pod.Namespace] = nsRequests
+	}
+
+	namespaceSamples := make(map[string]*NamespaceSampleModel)
+	namespaceSample := func(namespace string) *NamespaceSampleModel {
+		sample, ok := namespaceSamples[namespace]
+		if !ok {
+			sample = &NamespaceSampleModel{
+				OrganizationID: cluster.organizationID,
+				ClusterID:      cluster.id,
+				ClusterName:    cluster.name,
+				Namespace:      namespace,
+				Hours:          hours,
+				SampledAt:      sampledAt,
+			}
+			namespaceSamples[namespace] = sample
+		}
+
+		return sample
+	}
+
+	nodeSamples := make([]*NodeSampleModel, 0, len(s.nodes))
+	for _, node := range s.nodes {
+		var allocatable resources
+		allocatable.add(node.Status.Allocatable)
+
+		sample := &NodeSampleModel{
+			OrganizationID: cluster.organizationID,
+			ClusterID:      cluster.id,
+			ClusterName:    cluster.name,
+			NodePool:       node.Labels[pkgCommon.LabelKey],
+			NodeName:       node.Name,
+			InstanceType:   node.Labels[instanceTypeLabel],
+			Spot:           isSpot(node),
+			CPUCapacity:    allocatable.cpu,
+			CPUUsage:       s.nodeUsage[node.Name].cpu,
+			MemoryCapacity: allocatable.memory,
+			MemoryUsage:    s.nodeUsage[node.Name].memory,
+			Hours:          hours,
+			SampledAt:      sampledAt,
+		}
+
+		sample.PricePerHour, sample.Priced = price(node)
+		sample.Cost = sample.PricePerHour * hours
+
+		shares := make(map[string]float64)
+		var totalShare float64
+		for namespace, requests := range nodeRequests[node.Name] {
+			sample.CPURequests += requests.cpu
+			sample.MemoryRequests += requests.memory
+
+			share := (ratio(requests.cpu, allocatable.cpu) + ratio(requests.memory, allocatable.memory)) / 2
+			shares[namespace] = share
+			totalShare += share
+		}
+
+		// overcommitted nodes are allocated entirely
+		if totalShare > 1 {
+			for namespace := range shares {
+				shares[namespace] /= totalShare
+			}
+		}
+
+		for namespace, share := range shares {
+			namespaceSample(namespace).Cost += sample.Cost * share
+		}
+
+		nodeSamples = append(nodeSamples, sample)
+	}
+
+	for _, pod := range s.pods {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+
+		requests := podRequests(pod)
+		usage := s.podUsage[pod.Namespace+"/"+pod.Name]
+
+		sample := namespaceSample(pod.Namespace)
+		sample.CPURequests += requests.cpu
+		sample.MemoryRequests += requests.memory
+		sample.CPUUsage += usage.cpu
+		sample.MemoryUsage += usage.memory
+	}
+
+	namespaces := make([]*NamespaceSampleModel, 0, len(namespaceSamples))
+	for _, sample := range namespaceSamples {
+		namespaces = append(namespaces, sample)
+	}
+
+	sort.Slice(namespaces, func(i, j int) bool {
+		return namespaces[i].Namespace < namespaces[j].Namespace
+	})
+
+	return nodeSamples, namespaces
+}
+
+// isSpot tells whether a node is a spot or preemptible instance.
+func isSpot(node v1.Node) bool {
+	return node.Labels[pkgCommon.OnDemandLabelKey] == "false"
+}
+
+func podRequests(pod v1.Pod) resources {
+	var requests resources
+	for _, container := range pod.Spec.Containers {
+		requests.add(container.Resources.Requests)
+	}
+
+	return requests
+}
+
+func ratio(a int64, b int64) float64 {
+	if b == 0 {
+		return 0
+	}
+
+	return float64(a) / float64(b)
+}
@@ -0,0 +1,176 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	pkgCommon "github.com/banzaicloud/pipeline/pkg/common"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newNode(name string, pool string, cpu string, memory string) v1.Node {
+	return v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			Labels: map[string]string{
+				pkgCommon.LabelKey: pool,
+				instanceTypeLabel:  "m5.xlarge",
+			},
+		},
+		Status: v1.NodeStatus{
+			Allocatable: v1.ResourceList{
+				v1.ResourceCPU:    resource.MustParse(cpu),
+				v1.ResourceMemory: resource.MustParse(memory),
+			},
+		},
+	}
+}
+
+func newPod(namespace string, name string, node string, cpu string, memory string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: v1.PodSpec{
+			NodeName: node,
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU:    resource.MustParse(cpu),
+							v1.ResourceMemory: resource.MustParse(memory),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildSamples(t *testing.T) {
+	s := &snapshot{
+		nodes: []v1.Node{
+			newNode("node1", "pool1", "4", "16Gi"),
+			newNode("node2", "pool2", "2", "8Gi"),
+		},
+		pods: []v1.Pod{
+			newPod("app", "app-1", "node1", "1500m", "2Gi"),
+			newPod("app", "app-2", "node1", "500m", "2Gi"),
+			newPod("db", "db-1", "node1", "1", "8Gi"),
+			newPod("pending", "pending-1", "", "1", "1Gi"),
+		},
+		nodeUsage: map[string]resources{
+			"node1": {cpu: 3000, memory: 10 << 30},
+		},
+		podUsage: map[string]resources{
+			"app/app-1": {cpu: 1000, memory: 1 << 30},
+			"app/app-2": {cpu: 200, memory: 1 << 30},
+			"db/db-1":   {cpu: 1500, memory: 6 << 30},
+		},
+	}
+
+	price := func(node v1.Node) (float64, bool) {
+		if node.Name == "node1" {
+			return 0.4, true
+		}
+
+		return 0, false
+	}
+
+	sampledAt := time.Date(2019, 2, 20, 12, 0, 0, 0, time.UTC)
+
+	nodes, namespaces := buildSamples(clusterInfo{organizationID: 1, id: 2, name: "cluster"}, s, price, 0.25, sampledAt)
+
+	if len(nodes) != 2 {
+		t.Fatalf("expected 2 node samples, got %d", len(nodes))
+	}
+
+	node := nodes[0]
+	if node.NodePool != "pool1" || node.InstanceType != "m5.xlarge" || node.Spot {
+		t.Errorf("unexpected node metadata: %+v", node)
+	}
+
+	if node.CPUCapacity != 4000 || node.CPURequests != 3000 || node.CPUUsage != 3000 {
+		t.Errorf("unexpected node CPU: capacity=%d requests=%d usage=%d", node.CPUCapacity, node.CPURequests, node.CPUUsage)
+	}
+
+	if node.MemoryCapacity != 16<<30 || node.MemoryRequests != 12<<30 || node.MemoryUsage != 10<<30 {
+		t.Errorf("unexpected node memory: capacity=%d requests=%d usage=%d", node.MemoryCapacity, node.MemoryRequests, node.MemoryUsage)
+	}
+
+	if !node.Priced || !equal(node.Cost, 0.1) {
+		t.Errorf("expected priced node with 0.1 cost, got priced=%t cost=%f", node.Priced, node.Cost)
+	}
+
+	if nodes[1].Priced || nodes[1].Cost != 0 {
+		t.Errorf("expected unpriced node without cost, got priced=%t cost=%f", nodes[1].Priced, nodes[1].Cost)
+	}
+
+	if len(namespaces) != 2 {
+		t.Fatalf("expected 2 namespace samples, got %d", len(namespaces))
+	}
+
+	app, db := namespaces[0], namespaces[1]
+	if app.Namespace != "app" || db.Namespace != "db" {
+		t.Fatalf("unexpected namespaces: %s, %s", app.Namespace, db.Namespace)
+	}
+
+	if app.CPURequests != 2000 || app.CPUUsage != 1200 || app.MemoryRequests != 4<<30 || app.MemoryUsage != 2<<30 {
+		t.Errorf("unexpected app namespace resources: %+v", app)
+	}
+
+	// (2/4 CPU + 4/16 memory) / 2 share of the node cost
+	if !equal(app.Cost, 0.0375) {
+		t.Errorf("expected 0.0375 app namespace cost, got %f", app.Cost)
+	}
+
+	// (1/4 CPU + 8/16 memory) / 2 share of the node cost
+	if !equal(db.Cost, 0.0375) {
+		t.Errorf("expected 0.0375 db namespace cost, got %f", db.Cost)
+	}
+
+	if app.Hours != 0.25 || !app.SampledAt.Equal(sampledAt) || app.OrganizationID != 1 || app.ClusterID != 2 {
+		t.Errorf("unexpected app namespace sample: %+v", app)
+	}
+}
+
+func TestBuildSamples_Overcommitted(t *testing.T) {
+	s := &snapshot{
+		nodes: []v1.Node{newNode("node1", "pool1", "2", "4Gi")},
+		pods: []v1.Pod{
+			newPod("app", "app-1", "node1", "2", "4Gi"),
+			newPod("db", "db-1", "node1", "2", "4Gi"),
+		},
+	}
+
+	price := func(node v1.Node) (float64, bool) {
+		return 1, true
+	}
+
+	_, namespaces := buildSamples(clusterInfo{}, s, price, 1, time.Now())
+
+	for _, namespace := range namespaces {
+		if !equal(namespace.Cost, 0.5) {
+			t.Errorf("expected 0.5 cost for namespace %s, got %f", namespace.Namespace, namespace.Cost)
+		}
+	}
+}
+
+func equal(a float64, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
@@ -0,0 +1,105 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+)
+
+// TableName constants
+const (
+	nodeSamplesTableName      = "usage_node_samples"
+	namespaceSamplesTableName = "usage_namespace_samples"
+)
+
+// NodeSampleModel describes the capacity, the requests and the usage of a node at a point in time.
+// CPU is measured in millicores, memory in bytes, the sample accounts for Hours node-hours.
+type NodeSampleModel struct {
+	ID             uint `gorm:"primary_key"`
+	OrganizationID uint `gorm:"index:idx_usage_node_sample_org_time"`
+	ClusterID      uint
+	ClusterName    string
+	NodePool       string
+	NodeName       string
+	InstanceType   string
+	Spot           bool
+
+	CPUCapacity    int64
+	CPURequests    int64
+	CPUUsage       int64
+	MemoryCapacity int64
+	MemoryRequests int64
+	MemoryUsage    int64
+
+	Hours        float64
+	Priced       bool
+	PricePerHour float64
+	Cost         float64
+
+	SampledAt time.Time `gorm:"index:idx_usage_node_sample_org_time"`
+}
+
+// TableName changes the default table name.
+func (NodeSampleModel) TableName() string {
+	return nodeSamplesTableName
+}
+
+// NamespaceSampleModel describes the requests, the usage and the allocated node costs of a namespace at a point in time.
+type NamespaceSampleModel struct {
+	ID             uint `gorm:"primary_key"`
+	OrganizationID uint `gorm:"index:idx_usage_namespace_sample_org_time"`
+	ClusterID      uint
+	ClusterName    string
+	Namespace      string
+
+	CPURequests    int64
+	CPUUsage       int64
+	MemoryRequests int64
+	MemoryUsage    int64
+
+	Hours float64
+	Cost  float64
+
+	SampledAt time.Time `gorm:"index:idx_usage_namespace_sample_org_time"`
+}
+
+// TableName changes the default table name.
+func (NamespaceSampleModel) TableName() string {
+	return namespaceSamplesTableName
+}
+
+// Migrate executes the table migrations for the usage models.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&NodeSampleModel{},
+		&NamespaceSampleModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating usage tables")
+
+	return db.AutoMigrate(tables...).Error
+}
@@ -0,0 +1,190 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/goph/emperror"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+)
+
+const (
+	cloudInfoTimeout = 30 * time.Second
+	productsCacheTTL = 6 * time.Hour
+)
+
+// instance describes a node whose hourly price is looked up.
+type instance struct {
+	Cloud        string
+	Distribution string
+	Region       string
+	Zone         string
+	InstanceType string
+	Spot         bool
+}
+
+// pricer returns the hourly price of instances.
+type pricer interface {
+	// GetPrice returns the hourly price of an instance, false if the price is not known.
+	GetPrice(instance instance) (float64, bool, error)
+}
+
+// CloudInfoPricer looks up instance prices in Cloudinfo.
+type CloudInfoPricer struct {
+	endpoint string
+	client   *http.Client
+	products *cache.Cache
+}
+
+// NewCloudInfoPricer returns a new CloudInfoPricer instance.
+func NewCloudInfoPricer(endpoint string) *CloudInfoPricer {
+	return &CloudInfoPricer{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: cloudInfoTimeout},
+		products: cache.New(productsCacheTTL, productsCacheTTL),
+	}
+}
+
+type cloudInfoProducts struct {
+	Products []cloudInfoProduct `json:"products"`
+}
+
+type cloudInfoProduct struct {
+	Type          string           `json:"type"`
+	OnDemandPrice float64          `json:"onDemandPrice"`
+	SpotPrice     []cloudInfoPrice `json:"spotPrice"`
+}
+
+type cloudInfoPrice struct {
+	Zone  string  `json:"zone"`
+	Price float64 `json:"price"`
+}
+
+// GetPrice returns the hourly price of an instance, false if the price is not known or no endpoint is configured.
+// The spot price of the zone of the instance is used if known, the average spot price of the region otherwise.
+func (p *CloudInfoPricer) GetPrice(instance instance) (float64, bool, error) {
+	provider, service := cloudInfoService(instance.Cloud, instance.Distribution)
+	if p.endpoint == "" || provider == "" || instance.Region == "" || instance.InstanceType == "" {
+		return 0, false, nil
+	}
+
+	products, err := p.getProducts(provider, service, instance.Region)
+	if err != nil {
+		return 0, false, err
+	}
+
+	product, ok := products[instance.InstanceType]
+	if !ok {
+		return 0, false, nil
+	}
+
+	if !instance.Spot {
+		return product.OnDemandPrice, product.OnDemandPrice > 0, nil
+	}
+
+	var sum float64
+	for _, price := range product.SpotPrice {
+		if price.Zone == instance.Zone && price.Price > 0 {
+			return price.Price, true, nil
+		}
+
+		sum += price.Price
+	}
+
+	if sum > 0 {
+		return sum / float64(len(product.SpotPrice)), true, nil
+	}
+
+	// fall back to the on-demand price if the provider has no spot prices (eg. managed node pools)
+	return product.OnDemandPrice, product.OnDemandPrice > 0, nil
+}
+
+func (p *CloudInfoPricer) getProducts(provider string, service string, region string) (map[string]cloudInfoProduct, error) {
+	key := provider + "/" + service + "/" + region
+	if products, ok := p.products.Get(key); ok {
+		return products.(map[string]cloudInfoProduct), nil
+	}
+
+	u := fmt.Sprintf(
+		"%s/providers/%s/services/%s/regions/%s/products",
+		p.endpoint,
+		url.PathEscape(provider),
+		url.PathEscape(service),
+		url.PathEscape(region),
+	)
+
+	resp, err := p.client.Get(u)
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not get products from cloudinfo"), "url", u)
+	}
+	defer resp.Body.Close()
+
+	products := make(map[string]cloudInfoProduct)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		// unknown regions and services are cached too, to avoid requesting them again in every collection
+		p.products.SetDefault(key, products)
+		return products, nil
+
+	case resp.StatusCode != http.StatusOK:
+		return nil, emperror.With(errors.New("unexpected cloudinfo response"), "url", u, "status", resp.StatusCode)
+	}
+
+	var response cloudInfoProducts
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not decode cloudinfo response"), "url", u)
+	}
+
+	for _, product := range response.Products {
+		products[product.Type] = product
+	}
+
+	p.products.SetDefault(key, products)
+
+	return products, nil
+}
+
+// cloudInfoService returns the Cloudinfo provider and service of a cluster.
+// Cloudinfo providers are named after the clouds of Pipeline.
+func cloudInfoService(cloud string, distribution string) (string, string) {
+	switch distribution {
+	case pkgCluster.EKS:
+		return pkgCluster.Amazon, "eks"
+	case pkgCluster.GKE:
+		return pkgCluster.Google, "gke"
+	case pkgCluster.AKS:
+		return pkgCluster.Azure, "aks"
+	case pkgCluster.ACSK:
+		return pkgCluster.Alibaba, "ack"
+	case pkgCluster.OKE:
+		return pkgCluster.Oracle, "oke"
+	}
+
+	switch cloud {
+	case pkgCluster.Alibaba, pkgCluster.Amazon, pkgCluster.Azure, pkgCluster.Google, pkgCluster.Oracle:
+		return cloud, "compute"
+	}
+
+	return "", ""
+}
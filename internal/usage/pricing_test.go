@@ -0,0 +1,62 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+)
+
+func TestCloudInfoPricer_GetPrice(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.URL.Path != "/providers/amazon/services/compute/regions/eu-west-1/products" {
+			http.NotFound(w, r)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"products": [{"type": "m5.large", "onDemandPrice": 0.107}]}`))
+	}))
+	defer server.Close()
+
+	node := instance{Cloud: pkgCluster.Amazon, Region: "eu-west-1", InstanceType: "m5.large"}
+
+	price, ok, err := NewCloudInfoPricer(server.URL + "/").GetPrice(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !ok || price != 0.107 {
+		t.Errorf("expected the on-demand price, got %v (known: %t)", price, ok)
+	}
+
+	price, ok, err = NewCloudInfoPricer("").GetPrice(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok || price != 0 {
+		t.Errorf("expected no price without a configured endpoint, got %v", price)
+	}
+
+	if requests != 1 {
+		t.Errorf("expected a single Cloudinfo request, got %d", requests)
+	}
+}
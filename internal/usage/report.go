@@ -0,0 +1,303 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	pkgUsage "github.com/banzaicloud/pipeline/pkg/usage"
+	"github.com/pkg/errors"
+)
+
+const (
+	dateFormat  = "2006-01-02"
+	monthFormat = "2006-01"
+
+	// maxReportDays limits the time range of a report
+	maxReportDays = 366
+
+	bytesPerGiB = 1 << 30
+)
+
+type invalidQueryError struct {
+	err error
+}
+
+func (e *invalidQueryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidQueryError) IsInvalid() bool {
+	return true
+}
+
+// reportRange is a validated report query, to is exclusive.
+type reportRange struct {
+	period  string
+	groupBy string
+	from    time.Time
+	to      time.Time
+}
+
+// parseReportQuery validates a report query and fills its defaults.
+func parseReportQuery(query pkgUsage.ReportQuery, defaultGroupBy string, now time.Time) (*reportRange, error) {
+	r := reportRange{
+		period:  query.Period,
+		groupBy: query.GroupBy,
+	}
+
+	switch r.period {
+	case "":
+		r.period = pkgUsage.Daily
+	case pkgUsage.Daily, pkgUsage.Monthly:
+	default:
+		return nil, errors.WithStack(&invalidQueryError{errors.Errorf("invalid period: %q", query.Period)})
+	}
+
+	switch r.groupBy {
+	case "":
+		r.groupBy = defaultGroupBy
+	case pkgUsage.GroupByOrganization, pkgUsage.GroupByCluster, pkgUsage.GroupByNodePool, pkgUsage.GroupByNamespace:
+	default:
+		return nil, errors.WithStack(&invalidQueryError{errors.Errorf("invalid grouping: %q", query.GroupBy)})
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	last := today
+	if query.To != "" {
+		t, err := time.Parse(dateFormat, query.To)
+		if err != nil {
+			return nil, errors.WithStack(&invalidQueryError{errors.Errorf("invalid end date: %q", query.To)})
+		}
+		last = t
+	}
+
+	if query.From != "" {
+		t, err := time.Parse(dateFormat, query.From)
+		if err != nil {
+			return nil, errors.WithStack(&invalidQueryError{errors.Errorf("invalid start date: %q", query.From)})
+		}
+		r.from = t
+	} else if r.period == pkgUsage.Monthly {
+		r.from = time.Date(last.Year(), last.Month()-2, 1, 0, 0, 0, 0, time.UTC)
+	} else {
+		r.from = last.AddDate(0, 0, -29)
+	}
+
+	r.to = last.AddDate(0, 0, 1)
+
+	if !r.from.Before(r.to) {
+		return nil, errors.WithStack(&invalidQueryError{errors.New("start date is after end date")})
+	}
+
+	if r.to.Sub(r.from) > maxReportDays*24*time.Hour {
+		return nil, errors.WithStack(&invalidQueryError{errors.Errorf("time range exceeds %d days", maxReportDays)})
+	}
+
+	return &r, nil
+}
+
+func (r reportRange) periodOf(t time.Time) string {
+	if r.period == pkgUsage.Monthly {
+		return t.UTC().Format(monthFormat)
+	}
+
+	return t.UTC().Format(dateFormat)
+}
+
+// reportKey identifies an item of a report.
+type reportKey struct {
+	period    string
+	clusterID uint
+	nodePool  string
+	namespace string
+}
+
+// reportItem accumulates the samples of an item, CPU in millicore-hours and memory in byte-hours.
+type reportItem struct {
+	clusterName string
+
+	nodeHours float64
+
+	cpuCapacity float64
+	cpuRequests float64
+	cpuUsage    float64
+
+	memoryCapacity float64
+	memoryRequests float64
+	memoryUsage    float64
+
+	cost float64
+}
+
+// buildReport aggregates the samples of a time range into a report.
+func buildReport(r reportRange, nodes []*NodeSampleModel, namespaces []*NamespaceSampleModel) *pkgUsage.ReportResponse {
+	response := &pkgUsage.ReportResponse{
+		Period:   r.period,
+		From:     r.from.Format(dateFormat),
+		To:       r.to.AddDate(0, 0, -1).Format(dateFormat),
+		GroupBy:  r.groupBy,
+		Currency: pkgUsage.Currency,
+		Items:    []pkgUsage.ReportItem{},
+	}
+
+	items := make(map[reportKey]*reportItem)
+	item := func(key reportKey, clusterName string) *reportItem {
+		i, ok := items[key]
+		if !ok {
+			i = &reportItem{clusterName: clusterName}
+			items[key] = i
+		}
+
+		return i
+	}
+
+	var totalCost, unpricedNodeHours float64
+
+	// allocated costs by period and cluster, only used when grouping by namespace
+	allocated := make(map[reportKey]float64)
+
+	for _, sample := range nodes {
+		totalCost += sample.Cost
+		if !sample.Priced {
+			unpricedNodeHours += sample.Hours
+		}
+
+		key := reportKey{period: r.periodOf(sample.SampledAt)}
+
+		switch r.groupBy {
+		case pkgUsage.GroupByNamespace:
+			key.clusterID = sample.ClusterID
+			item(key, sample.ClusterName)
+			allocated[key] += sample.Cost
+
+			continue
+
+		case pkgUsage.GroupByCluster:
+			key.clusterID = sample.ClusterID
+
+		case pkgUsage.GroupByNodePool:
+			key.clusterID = sample.ClusterID
+			key.nodePool = sample.NodePool
+		}
+
+		i := item(key, sample.ClusterName)
+		i.nodeHours += sample.Hours
+		i.cpuCapacity += float64(sample.CPUCapacity) * sample.Hours
+		i.cpuRequests += float64(sample.CPURequests) * sample.Hours
+		i.cpuUsage += float64(sample.CPUUsage) * sample.Hours
+		i.memoryCapacity += float64(sample.MemoryCapacity) * sample.Hours
+		i.memoryRequests += float64(sample.MemoryRequests) * sample.Hours
+		i.memoryUsage += float64(sample.MemoryUsage) * sample.Hours
+		i.cost += sample.Cost
+	}
+
+	if r.groupBy == pkgUsage.GroupByNamespace {
+		for _, sample := range namespaces {
+			clusterKey := reportKey{period: r.periodOf(sample.SampledAt), clusterID: sample.ClusterID}
+			allocated[clusterKey] -= sample.Cost
+
+			key := clusterKey
+			key.namespace = sample.Namespace
+
+			i := item(key, sample.ClusterName)
+			i.cpuRequests += float64(sample.CPURequests) * sample.Hours
+			i.cpuUsage += float64(sample.CPUUsage) * sample.Hours
+			i.memoryRequests += float64(sample.MemoryRequests) * sample.Hours
+			i.memoryUsage += float64(sample.MemoryUsage) * sample.Hours
+			i.cost += sample.Cost
+		}
+
+		// the node costs not allocated to any namespace are reported separately
+		for clusterKey, unallocated := range allocated {
+			i, ok := items[clusterKey]
+			delete(items, clusterKey)
+
+			if !ok || round(unallocated) <= 0 {
+				continue
+			}
+
+			key := clusterKey
+			key.namespace = pkgUsage.UnallocatedNamespace
+
+			item(key, i.clusterName).cost = unallocated
+		}
+	}
+
+	for key, i := range items {
+		reportItem := pkgUsage.ReportItem{
+			Period:    key.period,
+			ClusterID: key.clusterID,
+			NodePool:  key.nodePool,
+			Namespace: key.namespace,
+			NodeHours: round(i.nodeHours),
+			CPU:       resourceUsage(i.cpuCapacity/1000, i.cpuRequests/1000, i.cpuUsage/1000),
+			Memory:    resourceUsage(i.memoryCapacity/bytesPerGiB, i.memoryRequests/bytesPerGiB, i.memoryUsage/bytesPerGiB),
+			Cost:      round(i.cost),
+		}
+
+		if key.clusterID != 0 {
+			reportItem.ClusterName = i.clusterName
+		}
+
+		response.Items = append(response.Items, reportItem)
+	}
+
+	sort.Slice(response.Items, func(i, j int) bool {
+		a, b := response.Items[i], response.Items[j]
+
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+
+		if a.ClusterName != b.ClusterName {
+			return a.ClusterName < b.ClusterName
+		}
+
+		if a.NodePool != b.NodePool {
+			return a.NodePool < b.NodePool
+		}
+
+		return a.Namespace < b.Namespace
+	})
+
+	response.TotalCost = round(totalCost)
+	response.UnpricedNodeHours = round(unpricedNodeHours)
+
+	return response
+}
+
+func resourceUsage(capacity float64, requests float64, usage float64) pkgUsage.ResourceUsage {
+	resourceUsage := pkgUsage.ResourceUsage{
+		Capacity: round(capacity),
+		Requests: round(requests),
+		Usage:    round(usage),
+	}
+
+	if capacity > 0 {
+		resourceUsage.RequestsPercent = round(requests / capacity * 100)
+		resourceUsage.UsagePercent = round(usage / capacity * 100)
+	}
+
+	return resourceUsage
+}
+
+// round rounds to two decimal places.
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
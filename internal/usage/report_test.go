@@ -0,0 +1,273 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	pkgUsage "github.com/banzaicloud/pipeline/pkg/usage"
+	"github.com/pkg/errors"
+)
+
+func TestParseReportQuery(t *testing.T) {
+	now := time.Date(2019, 2, 20, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   pkgUsage.ReportQuery
+		from    string
+		to      string
+		period  string
+		groupBy string
+		invalid bool
+	}{
+		{
+			name:    "defaults",
+			from:    "2019-01-22",
+			to:      "2019-02-21",
+			period:  pkgUsage.Daily,
+			groupBy: pkgUsage.GroupByCluster,
+		},
+		{
+			name:    "monthly defaults",
+			query:   pkgUsage.ReportQuery{Period: pkgUsage.Monthly, GroupBy: pkgUsage.GroupByNamespace},
+			from:    "2018-12-01",
+			to:      "2019-02-21",
+			period:  pkgUsage.Monthly,
+			groupBy: pkgUsage.GroupByNamespace,
+		},
+		{
+			name:    "explicit range",
+			query:   pkgUsage.ReportQuery{From: "2019-01-01", To: "2019-01-31"},
+			from:    "2019-01-01",
+			to:      "2019-02-01",
+			period:  pkgUsage.Daily,
+			groupBy: pkgUsage.GroupByCluster,
+		},
+		{
+			name:    "invalid period",
+			query:   pkgUsage.ReportQuery{Period: "weekly"},
+			invalid: true,
+		},
+		{
+			name:    "invalid grouping",
+			query:   pkgUsage.ReportQuery{GroupBy: "pod"},
+			invalid: true,
+		},
+		{
+			name:    "invalid date",
+			query:   pkgUsage.ReportQuery{From: "01/01/2019"},
+			invalid: true,
+		},
+		{
+			name:    "start after end",
+			query:   pkgUsage.ReportQuery{From: "2019-02-01", To: "2019-01-01"},
+			invalid: true,
+		},
+		{
+			name:    "range too long",
+			query:   pkgUsage.ReportQuery{From: "2017-01-01", To: "2019-01-01"},
+			invalid: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := parseReportQuery(test.query, pkgUsage.GroupByCluster, now)
+
+			if test.invalid {
+				if _, ok := errors.Cause(err).(*invalidQueryError); !ok {
+					t.Fatalf("expected invalid query error, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if r.from.Format(dateFormat) != test.from || r.to.Format(dateFormat) != test.to {
+				t.Errorf("expected [%s, %s) range, got [%s, %s)", test.from, test.to, r.from.Format(dateFormat), r.to.Format(dateFormat))
+			}
+
+			if r.period != test.period || r.groupBy != test.groupBy {
+				t.Errorf("expected %s %s, got %s %s", test.period, test.groupBy, r.period, r.groupBy)
+			}
+		})
+	}
+}
+
+func TestBuildReport(t *testing.T) {
+	day1 := time.Date(2019, 2, 19, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2019, 2, 20, 10, 0, 0, 0, time.UTC)
+
+	nodes := []*NodeSampleModel{
+		{
+			ClusterID:      1,
+			ClusterName:    "alpha",
+			NodePool:       "pool1",
+			CPUCapacity:    4000,
+			CPURequests:    2000,
+			CPUUsage:       1000,
+			MemoryCapacity: 8 << 30,
+			MemoryRequests: 4 << 30,
+			MemoryUsage:    2 << 30,
+			Hours:          1,
+			Priced:         true,
+			PricePerHour:   0.5,
+			Cost:           0.5,
+			SampledAt:      day1,
+		},
+		{
+			ClusterID:   1,
+			ClusterName: "alpha",
+			NodePool:    "pool2",
+			CPUCapacity: 2000,
+			Hours:       1,
+			SampledAt:   day1,
+		},
+		{
+			ClusterID:    2,
+			ClusterName:  "beta",
+			NodePool:     "pool1",
+			CPUCapacity:  1000,
+			CPURequests:  1000,
+			CPUUsage:     500,
+			Hours:        2,
+			Priced:       true,
+			PricePerHour: 0.5,
+			Cost:         1,
+			SampledAt:    day2,
+		},
+	}
+
+	namespaces := []*NamespaceSampleModel{
+		{ClusterID: 1, ClusterName: "alpha", Namespace: "app", CPURequests: 2000, Hours: 1, Cost: 0.3, SampledAt: day1},
+		{ClusterID: 2, ClusterName: "beta", Namespace: "app", CPURequests: 1000, Hours: 2, Cost: 1, SampledAt: day2},
+	}
+
+	r := reportRange{
+		period:  pkgUsage.Daily,
+		groupBy: pkgUsage.GroupByCluster,
+		from:    time.Date(2019, 2, 19, 0, 0, 0, 0, time.UTC),
+		to:      time.Date(2019, 2, 21, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("cluster", func(t *testing.T) {
+		report := buildReport(r, nodes, nil)
+
+		if report.From != "2019-02-19" || report.To != "2019-02-20" || report.Currency != pkgUsage.Currency {
+			t.Errorf("unexpected report header: %+v", report)
+		}
+
+		if report.TotalCost != 1.5 || report.UnpricedNodeHours != 1 {
+			t.Errorf("expected 1.5 total cost and 1 unpriced node-hour, got %f and %f", report.TotalCost, report.UnpricedNodeHours)
+		}
+
+		expected := []pkgUsage.ReportItem{
+			{
+				Period:      "2019-02-19",
+				ClusterID:   1,
+				ClusterName: "alpha",
+				NodeHours:   2,
+				CPU:         pkgUsage.ResourceUsage{Capacity: 6, Requests: 2, Usage: 1, RequestsPercent: 33.33, UsagePercent: 16.67},
+				Memory:      pkgUsage.ResourceUsage{Capacity: 8, Requests: 4, Usage: 2, RequestsPercent: 50, UsagePercent: 25},
+				Cost:        0.5,
+			},
+			{
+				Period:      "2019-02-20",
+				ClusterID:   2,
+				ClusterName: "beta",
+				NodeHours:   2,
+				CPU:         pkgUsage.ResourceUsage{Capacity: 2, Requests: 2, Usage: 1, RequestsPercent: 100, UsagePercent: 50},
+				Cost:        1,
+			},
+		}
+
+		if !reflect.DeepEqual(report.Items, expected) {
+			t.Errorf("unexpected items:\n%+v\nexpected:\n%+v", report.Items, expected)
+		}
+	})
+
+	t.Run("organization", func(t *testing.T) {
+		r := r
+		r.period = pkgUsage.Monthly
+		r.groupBy = pkgUsage.GroupByOrganization
+
+		report := buildReport(r, nodes, nil)
+
+		if len(report.Items) != 1 {
+			t.Fatalf("expected 1 item, got %d", len(report.Items))
+		}
+
+		item := report.Items[0]
+		if item.Period != "2019-02" || item.ClusterName != "" || item.NodeHours != 4 || item.Cost != 1.5 {
+			t.Errorf("unexpected item: %+v", item)
+		}
+	})
+
+	t.Run("node pool", func(t *testing.T) {
+		r := r
+		r.groupBy = pkgUsage.GroupByNodePool
+
+		report := buildReport(r, nodes, nil)
+
+		var pools []string
+		for _, item := range report.Items {
+			pools = append(pools, item.ClusterName+"/"+item.NodePool)
+		}
+
+		expected := []string{"alpha/pool1", "alpha/pool2", "beta/pool1"}
+		if !reflect.DeepEqual(pools, expected) {
+			t.Errorf("expected %v node pools, got %v", expected, pools)
+		}
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		r := r
+		r.groupBy = pkgUsage.GroupByNamespace
+
+		report := buildReport(r, nodes, namespaces)
+
+		type namespaceCost struct {
+			period    string
+			cluster   string
+			namespace string
+			cost      float64
+		}
+
+		var costs []namespaceCost
+		for _, item := range report.Items {
+			costs = append(costs, namespaceCost{item.Period, item.ClusterName, item.Namespace, item.Cost})
+		}
+
+		expected := []namespaceCost{
+			{"2019-02-19", "alpha", pkgUsage.UnallocatedNamespace, 0.2},
+			{"2019-02-19", "alpha", "app", 0.3},
+			{"2019-02-20", "beta", "app", 1},
+		}
+
+		if !reflect.DeepEqual(costs, expected) {
+			t.Errorf("expected %v namespace costs, got %v", expected, costs)
+		}
+
+		if report.Items[1].CPU.Requests != 2 || report.Items[1].CPU.RequestsPercent != 0 {
+			t.Errorf("unexpected namespace CPU: %+v", report.Items[1].CPU)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"time"
+
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// Repository stores the usage samples of the clusters.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository returns a new Repository instance.
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// SaveSamples persists the samples of a cluster in a single transaction.
+func (r *Repository) SaveSamples(nodes []*NodeSampleModel, namespaces []*NamespaceSampleModel) error {
+	tx := r.db.Begin()
+	if err := tx.Error; err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+
+	for _, sample := range nodes {
+		if err := tx.Create(sample).Error; err != nil {
+			tx.Rollback()
+			return emperror.With(
+				errors.Wrap(err, "could not save node usage sample"),
+				"cluster", sample.ClusterID,
+				"node", sample.NodeName,
+			)
+		}
+	}
+
+	for _, sample := range namespaces {
+		if err := tx.Create(sample).Error; err != nil {
+			tx.Rollback()
+			return emperror.With(
+				errors.Wrap(err, "could not save namespace usage sample"),
+				"cluster", sample.ClusterID,
+				"namespace", sample.Namespace,
+			)
+		}
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit transaction")
+}
+
+// FindNodeSamples returns the node samples of an organization taken in [from, to),
+// only the ones of a single cluster when clusterID is not zero.
+func (r *Repository) FindNodeSamples(organizationID uint, clusterID uint, from time.Time, to time.Time) ([]*NodeSampleModel, error) {
+	var samples []*NodeSampleModel
+
+	err := r.sampleQuery(organizationID, clusterID, from, to).Find(&samples).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch node usage samples"),
+			"organization", organizationID,
+			"cluster", clusterID,
+		)
+	}
+
+	return samples, nil
+}
+
+// FindNamespaceSamples returns the namespace samples of an organization taken in [from, to),
+// only the ones of a single cluster when clusterID is not zero.
+func (r *Repository) FindNamespaceSamples(organizationID uint, clusterID uint, from time.Time, to time.Time) ([]*NamespaceSampleModel, error) {
+	var samples []*NamespaceSampleModel
+
+	err := r.sampleQuery(organizationID, clusterID, from, to).Find(&samples).Error
+	if err != nil {
+		return nil, emperror.With(
+			errors.Wrap(err, "could not fetch namespace usage samples"),
+			"organization", organizationID,
+			"cluster", clusterID,
+		)
+	}
+
+	return samples, nil
+}
+
+func (r *Repository) sampleQuery(organizationID uint, clusterID uint, from time.Time, to time.Time) *gorm.DB {
+	query := r.db.Where("organization_id = ? AND sampled_at >= ? AND sampled_at < ?", organizationID, from, to)
+	if clusterID != 0 {
+		query = query.Where("cluster_id = ?", clusterID)
+	}
+
+	return query.Order("sampled_at")
+}
+
+// DeleteSamplesBefore deletes the samples taken before a point in time.
+func (r *Repository) DeleteSamplesBefore(t time.Time) error {
+	if err := r.db.Where("sampled_at < ?", t).Delete(&NodeSampleModel{}).Error; err != nil {
+		return errors.Wrap(err, "could not delete node usage samples")
+	}
+
+	if err := r.db.Where("sampled_at < ?", t).Delete(&NamespaceSampleModel{}).Error; err != nil {
+		return errors.Wrap(err, "could not delete namespace usage samples")
+	}
+
+	return nil
+}
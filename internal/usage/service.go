@@ -0,0 +1,207 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+import (
+	"context"
+	"time"
+
+	"github.com/banzaicloud/pipeline/cluster"
+	pkgCluster "github.com/banzaicloud/pipeline/pkg/cluster"
+	"github.com/banzaicloud/pipeline/pkg/k8sclient"
+	pkgUsage "github.com/banzaicloud/pipeline/pkg/usage"
+	"github.com/goph/emperror"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+)
+
+type clusterManager interface {
+	GetAllClusters(ctx context.Context) ([]cluster.CommonCluster, error)
+}
+
+// Service collects the utilization of the clusters and reports their usage and costs.
+type Service struct {
+	repository *Repository
+	clusters   clusterManager
+	pricer     pricer
+
+	logger       logrus.FieldLogger
+	errorHandler emperror.Handler
+}
+
+// NewService returns a new Service instance.
+func NewService(
+	repository *Repository,
+	clusters clusterManager,
+	pricer pricer,
+	logger logrus.FieldLogger,
+	errorHandler emperror.Handler,
+) *Service {
+	return &Service{
+		repository:   repository,
+		clusters:     clusters,
+		pricer:       pricer,
+		logger:       logger,
+		errorHandler: errorHandler,
+	}
+}
+
+// Report returns the utilization and cost report of an organization, or of a single cluster when clusterID is not zero.
+func (s *Service) Report(organizationID uint, clusterID uint, query pkgUsage.ReportQuery) (*pkgUsage.ReportResponse, error) {
+	defaultGroupBy := pkgUsage.GroupByCluster
+	if clusterID != 0 {
+		defaultGroupBy = pkgUsage.GroupByNodePool
+	}
+
+	r, err := parseReportQuery(query, defaultGroupBy, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := s.repository.FindNodeSamples(organizationID, clusterID, r.from, r.to)
+	if err != nil {
+		return nil, err
+	}
+
+	var namespaces []*NamespaceSampleModel
+	if r.groupBy == pkgUsage.GroupByNamespace {
+		namespaces, err = s.repository.FindNamespaceSamples(organizationID, clusterID, r.from, r.to)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return buildReport(*r, nodes, namespaces), nil
+}
+
+// Run collects samples of the running clusters on an interval and deletes the samples older than the retention period.
+func (s *Service) Run(ctx context.Context, interval time.Duration, retention time.Duration) {
+	run := func() {
+		s.logger.WithField("interval", interval.String()).Debug("collecting cluster usage")
+
+		if err := s.CollectClusters(ctx, interval); err != nil {
+			s.errorHandler.Handle(emperror.Wrap(err, "could not collect cluster usage"))
+		}
+
+		if err := s.repository.DeleteSamplesBefore(time.Now().Add(-retention)); err != nil {
+			s.errorHandler.Handle(err)
+		}
+	}
+
+	run()
+
+	ticker := time.NewTicker(interval)
+	for {
+		select {
+		case <-ticker.C:
+			run()
+		case <-ctx.Done():
+			s.logger.Debug("closing ticker")
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// CollectClusters collects a sample of every running cluster, each accounting for the given duration.
+func (s *Service) CollectClusters(ctx context.Context, duration time.Duration) error {
+	clusters, err := s.clusters.GetAllClusters(ctx)
+	if err != nil {
+		return errors.WithMessage(err, "could not get clusters")
+	}
+
+	sampledAt := time.Now().UTC()
+
+	for _, commonCluster := range clusters {
+		status, err := commonCluster.GetStatus()
+		if err != nil {
+			s.errorHandler.Handle(emperror.With(errors.Wrap(err, "could not get cluster status"), "cluster", commonCluster.GetID()))
+			continue
+		}
+
+		if status.Status != pkgCluster.Running {
+			continue
+		}
+
+		if err := s.Collect(commonCluster, duration, sampledAt); err != nil {
+			s.errorHandler.Handle(err)
+		}
+	}
+
+	return nil
+}
+
+// Collect saves a sample of the nodes and the namespaces of a cluster accounting for the given duration.
+func (s *Service) Collect(commonCluster cluster.CommonCluster, duration time.Duration, sampledAt time.Time) error {
+	logger := s.logger.WithFields(logrus.Fields{
+		"organization": commonCluster.GetOrganizationId(),
+		"cluster":      commonCluster.GetName(),
+	})
+
+	kubeConfig, err := commonCluster.GetK8sConfig()
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not get k8s config"), "cluster", commonCluster.GetName())
+	}
+
+	client, err := k8sclient.NewClientFromKubeConfig(kubeConfig)
+	if err != nil {
+		return emperror.With(errors.Wrap(err, "could not create k8s client"), "cluster", commonCluster.GetName())
+	}
+
+	snapshot, err := collectSnapshot(client, logger)
+	if err != nil {
+		return emperror.With(err, "cluster", commonCluster.GetName())
+	}
+
+	price := func(node v1.Node) (float64, bool) {
+		region := node.Labels[regionLabel]
+		if region == "" {
+			region = commonCluster.GetLocation()
+		}
+
+		price, ok, err := s.pricer.GetPrice(instance{
+			Cloud:        commonCluster.GetCloud(),
+			Distribution: commonCluster.GetDistribution(),
+			Region:       region,
+			Zone:         node.Labels[zoneLabel],
+			InstanceType: node.Labels[instanceTypeLabel],
+			Spot:         isSpot(node),
+		})
+		if err != nil {
+			logger.WithError(err).WithField("node", node.Name).Warn("could not get instance price")
+
+			return 0, false
+		}
+
+		return price, ok
+	}
+
+	info := clusterInfo{
+		organizationID: commonCluster.GetOrganizationId(),
+		id:             commonCluster.GetID(),
+		name:           commonCluster.GetName(),
+	}
+
+	nodes, namespaces := buildSamples(info, snapshot, price, duration.Hours(), sampledAt)
+
+	if err := s.repository.SaveSamples(nodes, namespaces); err != nil {
+		return emperror.With(err, "cluster", commonCluster.GetName())
+	}
+
+	logger.WithField("nodes", len(nodes)).Debug("cluster usage collected")
+
+	return nil
+}
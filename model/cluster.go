@@ -44,6 +44,7 @@ const (
 	TableNameAzureNodePools       = "azure_aks_node_pools"
 	TableNameDummyProperties      = "dummy_clusters"
 	TableNameKubernetesProperties = "kubernetes_clusters"
+	TableNameKubernetesNodePools  = "kubernetes_node_pools"
 )
 
 //ClusterModel describes the common cluster model
@@ -165,6 +166,24 @@ type KubernetesClusterModel struct {
 	ID          uint              `gorm:"primary_key"`
 	Metadata    map[string]string `gorm:"-"`
 	MetadataRaw []byte            `gorm:"meta_data"`
+	Provider    string
+	NodePools   []*KubernetesNodePoolModel `gorm:"foreignkey:ClusterID"`
+
+	// TillerPreinstalled and PreinstalledReleases record the Helm components found during the import,
+	// so that detaching the cluster leaves them in place
+	TillerPreinstalled   bool
+	PreinstalledReleases string `sql:"type:text;"`
+}
+
+// KubernetesNodePoolModel describes the node pools discovered in an imported cluster
+type KubernetesNodePoolModel struct {
+	ID               uint `gorm:"primary_key"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	ClusterID        uint   `gorm:"unique_index:idx_cluster_id_name"`
+	Name             string `gorm:"unique_index:idx_cluster_id_name"`
+	Count            int
+	NodeInstanceType string
 }
 
 func (cs *ClusterModel) BeforeCreate() (err error) {
@@ -326,6 +345,11 @@ func (KubernetesClusterModel) TableName() string {
 	return TableNameKubernetesProperties
 }
 
+//TableName sets the KubernetesNodePoolModel's table name
+func (KubernetesNodePoolModel) TableName() string {
+	return TableNameKubernetesNodePools
+}
+
 // AfterUpdate removes marked node pool(s)
 func (a *EKSClusterModel) AfterUpdate(scope *gorm.Scope) error {
 	log.Info("Remove node pools marked for deletion")
@@ -34,6 +34,7 @@ func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
 		&AKSNodePoolModel{},
 		&DummyClusterModel{},
 		&KubernetesClusterModel{},
+		&KubernetesNodePoolModel{},
 	}
 
 	var tableNames string
@@ -0,0 +1,135 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"time"
+)
+
+// ### [ Alerting scopes ] ### //
+const (
+	OrganizationScope = "organization"
+	ClusterScope      = "cluster"
+)
+
+// Rule describes a Prometheus alerting rule
+type Rule struct {
+	Alert string `json:"alert" binding:"required"`
+	Expr  string `json:"expr" binding:"required"`
+	// For is the duration the expression has to be true before the alert fires, eg. 5m
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// SlackConfig describes a Slack incoming webhook receiving alerts
+type SlackConfig struct {
+	APIURL       string `json:"apiUrl" binding:"required"`
+	Channel      string `json:"channel,omitempty"`
+	SendResolved bool   `json:"sendResolved,omitempty"`
+}
+
+// EmailConfig describes the recipients of alert emails, the mails are sent through the SMTP server of Pipeline
+type EmailConfig struct {
+	To           []string `json:"to" binding:"required"`
+	SendResolved bool     `json:"sendResolved,omitempty"`
+}
+
+// WebhookConfig describes a generic webhook receiving alerts
+type WebhookConfig struct {
+	URL          string `json:"url" binding:"required"`
+	SendResolved bool   `json:"sendResolved,omitempty"`
+}
+
+// PagerDutyConfig describes a PagerDuty service receiving alerts
+type PagerDutyConfig struct {
+	RoutingKey   string `json:"routingKey" binding:"required"`
+	SendResolved bool   `json:"sendResolved,omitempty"`
+}
+
+// Receiver describes an Alertmanager receiver, at least one of its configs has to be set
+type Receiver struct {
+	Name             string            `json:"name" binding:"required"`
+	SlackConfigs     []SlackConfig     `json:"slackConfigs,omitempty"`
+	EmailConfigs     []EmailConfig     `json:"emailConfigs,omitempty"`
+	WebhookConfigs   []WebhookConfig   `json:"webhookConfigs,omitempty"`
+	PagerDutyConfigs []PagerDutyConfig `json:"pagerDutyConfigs,omitempty"`
+}
+
+// Route describes an Alertmanager route sending the matching alerts to a receiver
+type Route struct {
+	Receiver       string            `json:"receiver" binding:"required"`
+	Match          map[string]string `json:"match,omitempty"`
+	MatchRE        map[string]string `json:"matchRe,omitempty"`
+	GroupBy        []string          `json:"groupBy,omitempty"`
+	GroupWait      string            `json:"groupWait,omitempty"`
+	GroupInterval  string            `json:"groupInterval,omitempty"`
+	RepeatInterval string            `json:"repeatInterval,omitempty"`
+	Continue       bool              `json:"continue,omitempty"`
+}
+
+// Config describes the alerting rules and the Alertmanager routing of an organization or a cluster
+type Config struct {
+	// DisableDefaultRules turns off the built-in cluster health alerts
+	DisableDefaultRules bool       `json:"disableDefaultRules,omitempty"`
+	Rules               []Rule     `json:"rules,omitempty"`
+	Receivers           []Receiver `json:"receivers,omitempty"`
+	Routes              []Route    `json:"routes,omitempty"`
+	// DefaultReceiver receives the alerts not matching any route, they are dropped if it is not set
+	DefaultReceiver string `json:"defaultReceiver,omitempty"`
+}
+
+// ConfigResponse describes the stored alerting config of an organization or a cluster
+type ConfigResponse struct {
+	Config
+	Scope     string     `json:"scope"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// RenderedConfigResponse describes the alerting config rendered for the monitoring release of a cluster
+type RenderedConfigResponse struct {
+	Rules        string `json:"rules"`
+	Alertmanager string `json:"alertmanager"`
+}
+
+// SMTPConfig describes the SMTP server Pipeline relays the alert emails through
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// WebhookMessage describes the notification Alertmanager sends to webhook receivers
+type WebhookMessage struct {
+	Receiver          string            `json:"receiver"`
+	Status            string            `json:"status"`
+	Alerts            []WebhookAlert    `json:"alerts"`
+	GroupLabels       map[string]string `json:"groupLabels"`
+	CommonLabels      map[string]string `json:"commonLabels"`
+	CommonAnnotations map[string]string `json:"commonAnnotations"`
+	ExternalURL       string            `json:"externalURL"`
+}
+
+// WebhookAlert describes an alert of a webhook notification
+type WebhookAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+}
@@ -0,0 +1,68 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+// ### [ Default rule severities ] ### //
+const (
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// DefaultRules returns the cluster health alerts every cluster gets unless they are disabled.
+// They rely on the metrics of kube-state-metrics and cert-manager deployed to the clusters.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Alert:  "NodeNotReady",
+			Expr:   `kube_node_status_condition{condition="Ready",status="true"} == 0`,
+			For:    "5m",
+			Labels: map[string]string{"severity": SeverityCritical},
+			Annotations: map[string]string{
+				"summary":     "Node {{ $labels.node }} is not ready",
+				"description": "Node {{ $labels.node }} has been unready for more than 5 minutes.",
+			},
+		},
+		{
+			Alert:  "NodeDiskPressure",
+			Expr:   `kube_node_status_condition{condition="DiskPressure",status="true"} == 1`,
+			For:    "5m",
+			Labels: map[string]string{"severity": SeverityWarning},
+			Annotations: map[string]string{
+				"summary":     "Node {{ $labels.node }} is under disk pressure",
+				"description": "Node {{ $labels.node }} has been reporting disk pressure for more than 5 minutes.",
+			},
+		},
+		{
+			Alert:  "PodCrashLooping",
+			Expr:   `rate(kube_pod_container_status_restarts_total[15m]) * 60 * 5 > 0`,
+			For:    "15m",
+			Labels: map[string]string{"severity": SeverityWarning},
+			Annotations: map[string]string{
+				"summary":     "Pod {{ $labels.namespace }}/{{ $labels.pod }} is crash looping",
+				"description": "Container {{ $labels.container }} of pod {{ $labels.namespace }}/{{ $labels.pod }} is restarting {{ printf \"%.2f\" $value }} times every 5 minutes.",
+			},
+		},
+		{
+			Alert:  "CertificateExpiringSoon",
+			Expr:   `certmanager_certificate_expiry_timestamp_seconds - time() < 7 * 24 * 3600`,
+			For:    "1h",
+			Labels: map[string]string{"severity": SeverityWarning},
+			Annotations: map[string]string{
+				"summary":     "Certificate {{ $labels.namespace }}/{{ $labels.name }} expires soon",
+				"description": "Certificate {{ $labels.namespace }}/{{ $labels.name }} expires in less than 7 days.",
+			},
+		},
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+// ### [ Rendered rule groups ] ### //
+const (
+	DefaultRuleGroup = "pipeline-default"
+	RuleGroup        = "pipeline"
+)
+
+// NullReceiver drops the alerts not matching any route when no default receiver is set
+const NullReceiver = "null"
+
+// ClusterLabel is added to every alert to tell the clusters apart in shared receivers
+const ClusterLabel = "cluster"
+
+// RuleFile describes a Prometheus rule file
+type RuleFile struct {
+	Groups []RuleGroupConfig `json:"groups"`
+}
+
+// RuleGroupConfig describes a group of Prometheus rules
+type RuleGroupConfig struct {
+	Name  string `json:"name"`
+	Rules []Rule `json:"rules"`
+}
+
+// AlertmanagerConfig describes an Alertmanager configuration file
+type AlertmanagerConfig struct {
+	Route     alertmanagerRoute      `json:"route"`
+	Receivers []alertmanagerReceiver `json:"receivers"`
+}
+
+type alertmanagerRoute struct {
+	Receiver       string              `json:"receiver"`
+	GroupBy        []string            `json:"group_by,omitempty"`
+	GroupWait      string              `json:"group_wait,omitempty"`
+	GroupInterval  string              `json:"group_interval,omitempty"`
+	RepeatInterval string              `json:"repeat_interval,omitempty"`
+	Match          map[string]string   `json:"match,omitempty"`
+	MatchRE        map[string]string   `json:"match_re,omitempty"`
+	Continue       bool                `json:"continue,omitempty"`
+	Routes         []alertmanagerRoute `json:"routes,omitempty"`
+}
+
+type alertmanagerReceiver struct {
+	Name             string                  `json:"name"`
+	SlackConfigs     []alertmanagerSlack     `json:"slack_configs,omitempty"`
+	WebhookConfigs   []alertmanagerWebhook   `json:"webhook_configs,omitempty"`
+	PagerDutyConfigs []alertmanagerPagerDuty `json:"pagerduty_configs,omitempty"`
+}
+
+type alertmanagerSlack struct {
+	APIURL       string `json:"api_url"`
+	Channel      string `json:"channel,omitempty"`
+	SendResolved bool   `json:"send_resolved"`
+}
+
+type alertmanagerWebhook struct {
+	URL          string `json:"url"`
+	SendResolved bool   `json:"send_resolved"`
+}
+
+type alertmanagerPagerDuty struct {
+	RoutingKey   string `json:"routing_key"`
+	SendResolved bool   `json:"send_resolved"`
+}
+
+// Merge returns the effective alerting config of a cluster from the config of its organization and its own one.
+// The rules and receivers of the cluster override the ones of the organization with the same name,
+// the routes of the cluster are evaluated before the ones of the organization.
+func Merge(org *Config, cluster *Config) Config {
+	var merged Config
+
+	for _, config := range []*Config{org, cluster} {
+		if config == nil {
+			continue
+		}
+
+		merged.DisableDefaultRules = merged.DisableDefaultRules || config.DisableDefaultRules
+		merged.Rules = mergeRules(merged.Rules, config.Rules)
+		merged.Receivers = mergeReceivers(merged.Receivers, config.Receivers)
+
+		if config.DefaultReceiver != "" {
+			merged.DefaultReceiver = config.DefaultReceiver
+		}
+	}
+
+	if cluster != nil {
+		merged.Routes = append(merged.Routes, cluster.Routes...)
+	}
+	if org != nil {
+		merged.Routes = append(merged.Routes, org.Routes...)
+	}
+
+	return merged
+}
+
+func mergeRules(rules []Rule, overrides []Rule) []Rule {
+	merged := append([]Rule(nil), rules...)
+
+	for _, override := range overrides {
+		replaced := false
+		for i, rule := range merged {
+			if rule.Alert == override.Alert {
+				merged[i] = override
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			merged = append(merged, override)
+		}
+	}
+
+	return merged
+}
+
+func mergeReceivers(receivers []Receiver, overrides []Receiver) []Receiver {
+	merged := append([]Receiver(nil), receivers...)
+
+	for _, override := range overrides {
+		replaced := false
+		for i, receiver := range merged {
+			if receiver.Name == override.Name {
+				merged[i] = override
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			merged = append(merged, override)
+		}
+	}
+
+	return merged
+}
+
+// RenderRules renders the Prometheus rule file of an alerting config.
+// The default rules are left out if they are disabled or overridden by a rule with the same name.
+func RenderRules(config Config, clusterName string) RuleFile {
+	file := RuleFile{
+		Groups: []RuleGroupConfig{},
+	}
+
+	if !config.DisableDefaultRules {
+		overridden := make(map[string]bool, len(config.Rules))
+		for _, rule := range config.Rules {
+			overridden[rule.Alert] = true
+		}
+
+		var defaults []Rule
+		for _, rule := range DefaultRules() {
+			if !overridden[rule.Alert] {
+				defaults = append(defaults, withClusterLabel(rule, clusterName))
+			}
+		}
+
+		if len(defaults) > 0 {
+			file.Groups = append(file.Groups, RuleGroupConfig{Name: DefaultRuleGroup, Rules: defaults})
+		}
+	}
+
+	if len(config.Rules) > 0 {
+		rules := make([]Rule, 0, len(config.Rules))
+		for _, rule := range config.Rules {
+			rules = append(rules, withClusterLabel(rule, clusterName))
+		}
+
+		file.Groups = append(file.Groups, RuleGroupConfig{Name: RuleGroup, Rules: rules})
+	}
+
+	return file
+}
+
+func withClusterLabel(rule Rule, clusterName string) Rule {
+	if clusterName == "" {
+		return rule
+	}
+
+	if _, ok := rule.Labels[ClusterLabel]; ok {
+		return rule
+	}
+
+	labels := make(map[string]string, len(rule.Labels)+1)
+	for k, v := range rule.Labels {
+		labels[k] = v
+	}
+	labels[ClusterLabel] = clusterName
+	rule.Labels = labels
+
+	return rule
+}
+
+// RenderAlertmanagerConfig renders the Alertmanager configuration of an alerting config.
+// Email receivers are relayed through the Pipeline webhook returned by emailRelayURL, they are dropped if it is nil.
+func RenderAlertmanagerConfig(config Config, emailRelayURL func(receiver string) string) AlertmanagerConfig {
+	rendered := AlertmanagerConfig{
+		Route: alertmanagerRoute{
+			Receiver: config.DefaultReceiver,
+			GroupBy:  []string{"alertname", ClusterLabel},
+		},
+		Receivers: []alertmanagerReceiver{},
+	}
+
+	if rendered.Route.Receiver == "" {
+		rendered.Route.Receiver = NullReceiver
+		rendered.Receivers = append(rendered.Receivers, alertmanagerReceiver{Name: NullReceiver})
+	}
+
+	for _, receiver := range config.Receivers {
+		r := alertmanagerReceiver{
+			Name: receiver.Name,
+		}
+
+		for _, c := range receiver.SlackConfigs {
+			r.SlackConfigs = append(r.SlackConfigs, alertmanagerSlack{APIURL: c.APIURL, Channel: c.Channel, SendResolved: c.SendResolved})
+		}
+		// Emails are sent by Pipeline so that the SMTP credentials never reach the clusters
+		if len(receiver.EmailConfigs) > 0 && emailRelayURL != nil {
+			sendResolved := false
+			for _, c := range receiver.EmailConfigs {
+				sendResolved = sendResolved || c.SendResolved
+			}
+
+			r.WebhookConfigs = append(r.WebhookConfigs, alertmanagerWebhook{URL: emailRelayURL(receiver.Name), SendResolved: sendResolved})
+		}
+		for _, c := range receiver.WebhookConfigs {
+			r.WebhookConfigs = append(r.WebhookConfigs, alertmanagerWebhook{URL: c.URL, SendResolved: c.SendResolved})
+		}
+		for _, c := range receiver.PagerDutyConfigs {
+			r.PagerDutyConfigs = append(r.PagerDutyConfigs, alertmanagerPagerDuty{RoutingKey: c.RoutingKey, SendResolved: c.SendResolved})
+		}
+
+		rendered.Receivers = append(rendered.Receivers, r)
+	}
+
+	for _, route := range config.Routes {
+		rendered.Route.Routes = append(rendered.Route.Routes, alertmanagerRoute{
+			Receiver:       route.Receiver,
+			GroupBy:        route.GroupBy,
+			GroupWait:      route.GroupWait,
+			GroupInterval:  route.GroupInterval,
+			RepeatInterval: route.RepeatInterval,
+			Match:          route.Match,
+			MatchRE:        route.MatchRE,
+			Continue:       route.Continue,
+		})
+	}
+
+	return rendered
+}
+
+// MonitoringValues returns the values of the monitoring release deploying the rendered alerting config
+// to the Prometheus server and the Alertmanager of a cluster.
+func MonitoringValues(config Config, clusterName string, emailRelayURL func(receiver string) string) map[string]interface{} {
+	return map[string]interface{}{
+		"prometheus": map[string]interface{}{
+			"serverFiles": map[string]interface{}{
+				"alerts": RenderRules(config, clusterName),
+			},
+			"alertmanagerFiles": map[string]interface{}{
+				"alertmanager.yml": RenderAlertmanagerConfig(config, emailRelayURL),
+			},
+		},
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	org := &Config{
+		Rules: []Rule{
+			{Alert: "HighLatency", Expr: "latency > 1"},
+			{Alert: "HighErrorRate", Expr: "errors > 1"},
+		},
+		Receivers: []Receiver{
+			{Name: "ops", WebhookConfigs: []WebhookConfig{{URL: "https://ops.example.com"}}},
+		},
+		Routes:          []Route{{Receiver: "ops"}},
+		DefaultReceiver: "ops",
+	}
+
+	cluster := &Config{
+		DisableDefaultRules: true,
+		Rules: []Rule{
+			{Alert: "HighLatency", Expr: "latency > 2"},
+			{Alert: "QueueFull", Expr: "queue > 100"},
+		},
+		Receivers: []Receiver{
+			{Name: "team", SlackConfigs: []SlackConfig{{APIURL: "https://hooks.slack.com/x"}}},
+		},
+		Routes: []Route{{Receiver: "team", Match: map[string]string{"team": "a"}}},
+	}
+
+	merged := Merge(org, cluster)
+
+	if !merged.DisableDefaultRules {
+		t.Error("default rules should be disabled")
+	}
+
+	expectedRules := []Rule{
+		{Alert: "HighLatency", Expr: "latency > 2"},
+		{Alert: "HighErrorRate", Expr: "errors > 1"},
+		{Alert: "QueueFull", Expr: "queue > 100"},
+	}
+	if !reflect.DeepEqual(merged.Rules, expectedRules) {
+		t.Errorf("unexpected rules: %+v", merged.Rules)
+	}
+
+	if len(merged.Receivers) != 2 || merged.Receivers[0].Name != "ops" || merged.Receivers[1].Name != "team" {
+		t.Errorf("unexpected receivers: %+v", merged.Receivers)
+	}
+
+	if len(merged.Routes) != 2 || merged.Routes[0].Receiver != "team" || merged.Routes[1].Receiver != "ops" {
+		t.Errorf("cluster routes should precede organization routes: %+v", merged.Routes)
+	}
+
+	if merged.DefaultReceiver != "ops" {
+		t.Errorf("unexpected default receiver: %q", merged.DefaultReceiver)
+	}
+
+	if len(org.Rules) != 2 || org.Rules[0].Expr != "latency > 1" {
+		t.Error("merging should not modify the organization config")
+	}
+}
+
+func TestRenderRules(t *testing.T) {
+	config := Config{
+		Rules: []Rule{
+			{Alert: "NodeNotReady", Expr: "up == 0", Labels: map[string]string{"severity": "page"}},
+		},
+	}
+
+	file := RenderRules(config, "my-cluster")
+
+	if len(file.Groups) != 2 {
+		t.Fatalf("expected default and custom rule groups, got %d", len(file.Groups))
+	}
+
+	defaults := file.Groups[0]
+	if defaults.Name != DefaultRuleGroup || len(defaults.Rules) != len(DefaultRules())-1 {
+		t.Errorf("the overridden default rule should be left out: %+v", defaults)
+	}
+	for _, rule := range defaults.Rules {
+		if rule.Alert == "NodeNotReady" {
+			t.Error("the overridden default rule should be left out")
+		}
+		if rule.Labels[ClusterLabel] != "my-cluster" {
+			t.Errorf("rule %q should have the cluster label", rule.Alert)
+		}
+	}
+
+	custom := file.Groups[1]
+	expected := map[string]string{"severity": "page", ClusterLabel: "my-cluster"}
+	if custom.Name != RuleGroup || !reflect.DeepEqual(custom.Rules[0].Labels, expected) {
+		t.Errorf("unexpected custom rule group: %+v", custom)
+	}
+	if _, ok := config.Rules[0].Labels[ClusterLabel]; ok {
+		t.Error("rendering should not modify the config")
+	}
+
+	config.DisableDefaultRules = true
+	if file := RenderRules(config, ""); len(file.Groups) != 1 || file.Groups[0].Name != RuleGroup {
+		t.Errorf("default rules should be disabled: %+v", file)
+	}
+}
+
+func TestRenderAlertmanagerConfig(t *testing.T) {
+	emailRelayURL := func(receiver string) string {
+		return "https://pipeline.example.com/api/v1/alerting/email/1/" + receiver + "/token"
+	}
+
+	rendered := RenderAlertmanagerConfig(Config{}, emailRelayURL)
+	if rendered.Route.Receiver != NullReceiver || len(rendered.Receivers) != 1 || rendered.Receivers[0].Name != NullReceiver {
+		t.Errorf("alerts should be dropped without a default receiver: %+v", rendered)
+	}
+
+	config := Config{
+		Receivers: []Receiver{
+			{Name: "mail", EmailConfigs: []EmailConfig{{To: []string{"a@example.com", "b@example.com"}, SendResolved: true}}},
+		},
+		Routes:          []Route{{Receiver: "mail", Match: map[string]string{"severity": "critical"}}},
+		DefaultReceiver: "mail",
+	}
+
+	rendered = RenderAlertmanagerConfig(config, emailRelayURL)
+
+	if rendered.Route.Receiver != "mail" || len(rendered.Receivers) != 1 || len(rendered.Route.Routes) != 1 {
+		t.Errorf("unexpected config: %+v", rendered)
+	}
+	expected := []alertmanagerWebhook{{URL: emailRelayURL("mail"), SendResolved: true}}
+	if webhooks := rendered.Receivers[0].WebhookConfigs; !reflect.DeepEqual(webhooks, expected) {
+		t.Errorf("emails should be relayed through pipeline, got %+v", webhooks)
+	}
+
+	rendered = RenderAlertmanagerConfig(config, nil)
+	if webhooks := rendered.Receivers[0].WebhookConfigs; len(webhooks) != 0 {
+		t.Errorf("emails should be dropped without a relay, got %+v", webhooks)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	org := &Config{
+		Receivers: []Receiver{
+			{Name: "ops", WebhookConfigs: []WebhookConfig{{URL: "https://ops.example.com"}}},
+		},
+	}
+
+	tests := map[string]struct {
+		config Config
+		valid  bool
+	}{
+		"valid": {
+			config: Config{
+				Rules:           []Rule{{Alert: "HighLatency", Expr: "latency > 1", For: "5m"}},
+				Routes:          []Route{{Receiver: "ops", GroupWait: "30s", MatchRE: map[string]string{"team": "a|b"}}},
+				DefaultReceiver: "ops",
+			},
+			valid: true,
+		},
+		"invalid rule name": {
+			config: Config{Rules: []Rule{{Alert: "high latency", Expr: "latency > 1"}}},
+		},
+		"invalid duration": {
+			config: Config{Rules: []Rule{{Alert: "HighLatency", Expr: "latency > 1", For: "five minutes"}}},
+		},
+		"duplicate rule": {
+			config: Config{Rules: []Rule{{Alert: "HighLatency", Expr: "a"}, {Alert: "HighLatency", Expr: "b"}}},
+		},
+		"empty receiver": {
+			config: Config{Receivers: []Receiver{{Name: "empty"}}},
+		},
+		"reserved receiver": {
+			config: Config{Receivers: []Receiver{{Name: NullReceiver, WebhookConfigs: []WebhookConfig{{URL: "https://example.com"}}}}},
+		},
+		"relative webhook URL": {
+			config: Config{Receivers: []Receiver{{Name: "hook", WebhookConfigs: []WebhookConfig{{URL: "/alerts"}}}}},
+		},
+		"unknown route receiver": {
+			config: Config{Routes: []Route{{Receiver: "team"}}},
+		},
+		"invalid route regexp": {
+			config: Config{Routes: []Route{{Receiver: "ops", MatchRE: map[string]string{"team": "("}}}},
+		},
+		"unknown default receiver": {
+			config: Config{DefaultReceiver: "team"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := Validate(test.config, org)
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !test.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
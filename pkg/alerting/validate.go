@@ -0,0 +1,189 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package alerting
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	alertNameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Validate checks an alerting config, its routes may refer to the receivers of the parent config as well.
+func Validate(config Config, parent *Config) error {
+	alerts := make(map[string]bool, len(config.Rules))
+	for _, rule := range config.Rules {
+		if err := validateRule(rule); err != nil {
+			return err
+		}
+
+		if alerts[rule.Alert] {
+			return errors.Errorf("duplicate rule %q", rule.Alert)
+		}
+		alerts[rule.Alert] = true
+	}
+
+	receivers := make(map[string]bool)
+	if parent != nil {
+		for _, receiver := range parent.Receivers {
+			receivers[receiver.Name] = true
+		}
+	}
+
+	names := make(map[string]bool, len(config.Receivers))
+	for _, receiver := range config.Receivers {
+		if err := validateReceiver(receiver); err != nil {
+			return err
+		}
+
+		if names[receiver.Name] {
+			return errors.Errorf("duplicate receiver %q", receiver.Name)
+		}
+		names[receiver.Name] = true
+		receivers[receiver.Name] = true
+	}
+
+	for i, route := range config.Routes {
+		if !receivers[route.Receiver] {
+			return errors.Errorf("route %d: unknown receiver %q", i, route.Receiver)
+		}
+
+		if err := validateRoute(route); err != nil {
+			return errors.WithMessage(err, "route "+strconv.Itoa(i))
+		}
+	}
+
+	if config.DefaultReceiver != "" && !receivers[config.DefaultReceiver] {
+		return errors.Errorf("unknown default receiver %q", config.DefaultReceiver)
+	}
+
+	return nil
+}
+
+func validateRule(rule Rule) error {
+	if !alertNameRegexp.MatchString(rule.Alert) {
+		return errors.Errorf("invalid rule name %q", rule.Alert)
+	}
+
+	if rule.Expr == "" {
+		return errors.Errorf("rule %q: expression is required", rule.Alert)
+	}
+
+	if rule.For != "" {
+		if _, err := model.ParseDuration(rule.For); err != nil {
+			return errors.Errorf("rule %q: invalid duration %q", rule.Alert, rule.For)
+		}
+	}
+
+	for _, labels := range []map[string]string{rule.Labels, rule.Annotations} {
+		for name := range labels {
+			if !labelNameRegexp.MatchString(name) {
+				return errors.Errorf("rule %q: invalid label name %q", rule.Alert, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateReceiver(receiver Receiver) error {
+	if receiver.Name == "" {
+		return errors.New("receiver name is required")
+	}
+
+	if receiver.Name == NullReceiver {
+		return errors.Errorf("receiver name %q is reserved", NullReceiver)
+	}
+
+	if len(receiver.SlackConfigs)+len(receiver.EmailConfigs)+len(receiver.WebhookConfigs)+len(receiver.PagerDutyConfigs) == 0 {
+		return errors.Errorf("receiver %q: at least one config is required", receiver.Name)
+	}
+
+	for _, c := range receiver.SlackConfigs {
+		if err := validateURL(c.APIURL); err != nil {
+			return errors.Errorf("receiver %q: invalid Slack API URL %q", receiver.Name, c.APIURL)
+		}
+	}
+
+	for _, c := range receiver.EmailConfigs {
+		if len(c.To) == 0 {
+			return errors.Errorf("receiver %q: email recipients are required", receiver.Name)
+		}
+	}
+
+	for _, c := range receiver.WebhookConfigs {
+		if err := validateURL(c.URL); err != nil {
+			return errors.Errorf("receiver %q: invalid webhook URL %q", receiver.Name, c.URL)
+		}
+	}
+
+	for _, c := range receiver.PagerDutyConfigs {
+		if c.RoutingKey == "" {
+			return errors.Errorf("receiver %q: PagerDuty routing key is required", receiver.Name)
+		}
+	}
+
+	return nil
+}
+
+func validateRoute(route Route) error {
+	for _, duration := range []string{route.GroupWait, route.GroupInterval, route.RepeatInterval} {
+		if duration == "" {
+			continue
+		}
+
+		if _, err := model.ParseDuration(duration); err != nil {
+			return errors.Errorf("invalid duration %q", duration)
+		}
+	}
+
+	for name := range route.Match {
+		if !labelNameRegexp.MatchString(name) {
+			return errors.Errorf("invalid label name %q", name)
+		}
+	}
+
+	for name, expr := range route.MatchRE {
+		if !labelNameRegexp.MatchString(name) {
+			return errors.Errorf("invalid label name %q", name)
+		}
+
+		if _, err := regexp.Compile("^(?:" + expr + ")$"); err != nil {
+			return errors.Errorf("invalid regular expression %q", expr)
+		}
+	}
+
+	return nil
+}
+
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return errors.New("URL must be absolute")
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ### [ Audit event export formats ] ### //
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// EventResponse describes a single audited request
+type EventResponse struct {
+	ID            uint            `json:"id"`
+	Time          time.Time       `json:"time"`
+	CorrelationID string          `json:"correlationId"`
+	ClientIP      string          `json:"clientIp"`
+	UserAgent     string          `json:"userAgent"`
+	Path          string          `json:"path"`
+	Method        string          `json:"method"`
+	UserID        uint            `json:"userId"`
+	StatusCode    int             `json:"statusCode"`
+	Body          json.RawMessage `json:"body,omitempty"`
+	Headers       http.Header     `json:"headers,omitempty"`
+}
+
+// ListEventsResponse describes a page of audited requests
+type ListEventsResponse struct {
+	Events []EventResponse `json:"events"`
+	// NextCursor should be passed as the cursor parameter to get the next page, it is empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListEventsQuery describes the filters of the audited requests of an organization
+type ListEventsQuery struct {
+	UserID uint `form:"userId"`
+	// From and To limit the time range of the events in RFC3339 format, To is exclusive
+	From   string `form:"from"`
+	To     string `form:"to"`
+	Method string `form:"method"`
+	// Path matches the events whose path starts with it
+	Path          string `form:"path"`
+	StatusCode    int    `form:"statusCode"`
+	CorrelationID string `form:"correlationId"`
+	Cursor        string `form:"cursor"`
+	Limit         int    `form:"limit"`
+	// Format of the export, csv or ndjson
+	Format string `form:"format"`
+}
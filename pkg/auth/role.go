@@ -0,0 +1,68 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"time"
+)
+
+// ### [ Built-in organization roles ] ### //
+const (
+	RoleAdmin           = "admin"
+	RoleClusterOperator = "cluster-operator"
+	RoleDeveloper       = "developer"
+	RoleViewer          = "viewer"
+)
+
+// BuiltInRoles contains the roles available in every organization
+var BuiltInRoles = []string{
+	RoleAdmin,
+	RoleClusterOperator,
+	RoleDeveloper,
+	RoleViewer,
+}
+
+// DefaultRole is assigned to users added to an organization without a role
+const DefaultRole = RoleDeveloper
+
+// RoleRule allows the listed methods on a path of the organization
+type RoleRule struct {
+	// Path is relative to the organization (eg. /clusters/:id/deployments/*), empty means the organization itself
+	Path string `json:"path"`
+	// Methods contains HTTP methods, * allows every method
+	Methods []string `json:"methods" binding:"required"`
+}
+
+// RoleRequest describes a custom role of an organization
+type RoleRequest struct {
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description,omitempty"`
+	Rules       []RoleRule `json:"rules" binding:"required"`
+}
+
+// RoleResponse describes a built-in or custom role of an organization
+type RoleResponse struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	BuiltIn     bool       `json:"builtIn"`
+	Rules       []RoleRule `json:"rules"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// AddUserRequest describes the role of a user added to an organization
+type AddUserRequest struct {
+	Role string `json:"role"`
+}
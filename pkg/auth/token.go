@@ -0,0 +1,76 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"time"
+)
+
+// ### [ API token scopes ] ### //
+const (
+	ScopeClustersRead      = "clusters:read"
+	ScopeClustersWrite     = "clusters:write"
+	ScopeDeploymentsRead   = "deployments:read"
+	ScopeDeploymentsWrite  = "deployments:write"
+	ScopeSecretsRead       = "secrets:read"
+	ScopeSecretsWrite      = "secrets:write"
+	ScopeHelmRead          = "helm:read"
+	ScopeHelmWrite         = "helm:write"
+	ScopeSpotguidesRead    = "spotguides:read"
+	ScopeSpotguidesWrite   = "spotguides:write"
+	ScopeOrganizationRead  = "organization:read"
+	ScopeOrganizationWrite = "organization:write"
+)
+
+// TokenScopes contains every scope an API token can be restricted to
+var TokenScopes = []string{
+	ScopeClustersRead,
+	ScopeClustersWrite,
+	ScopeDeploymentsRead,
+	ScopeDeploymentsWrite,
+	ScopeSecretsRead,
+	ScopeSecretsWrite,
+	ScopeHelmRead,
+	ScopeHelmWrite,
+	ScopeSpotguidesRead,
+	ScopeSpotguidesWrite,
+	ScopeOrganizationRead,
+	ScopeOrganizationWrite,
+}
+
+// CreateTokenRequest describes an API token to be created
+type CreateTokenRequest struct {
+	Name        string     `json:"name,omitempty"`
+	VirtualUser string     `json:"virtualUser,omitempty"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	// OrganizationID restricts the token to a single organization
+	OrganizationID uint `json:"organizationId,omitempty"`
+	// ClusterIDs restricts the token to the listed clusters of the organization
+	ClusterIDs []uint `json:"clusterIds,omitempty"`
+	// Scopes restricts the operations of the token, every operation is allowed if empty
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// TokenResponse describes an API token without its value
+type TokenResponse struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt      *time.Time `json:"createdAt,omitempty"`
+	OrganizationID uint       `json:"organizationId,omitempty"`
+	ClusterIDs     []uint     `json:"clusterIds,omitempty"`
+	Scopes         []string   `json:"scopes,omitempty"`
+	LastUsedAt     *time.Time `json:"lastUsedAt,omitempty"`
+}
@@ -81,6 +81,7 @@ const (
 	InstallMonitoring                      = "InstallMonitoring"
 	InstallLogging                         = "InstallLogging"
 	RegisterDomainPostHook                 = "RegisterDomainPostHook"
+	InstallCertManagerPostHook             = "InstallCertManagerPostHook"
 	LabelNodes                             = "LabelNodes"
 	TaintHeadNodes                         = "TaintHeadNodes"
 	InstallPVCOperator                     = "InstallPVCOperator"
@@ -169,10 +170,14 @@ type GetClusterStatusResponse struct {
 	Version       string                     `json:"version,omitempty"`
 	ResourceID    uint                       `json:"id"`
 	NodePools     map[string]*NodePoolStatus `json:"nodePools,omitempty"`
+	PostHooks     []PostHookStatus           `json:"postHooks,omitempty"`
 	pkgCommon.CreatorBaseFields
 
 	// ONLY in case of GKE
 	Region string `json:"region,omitempty"`
+
+	// ONLY in case of imported Kubernetes clusters
+	Provider string `json:"provider,omitempty"`
 }
 
 // NodePoolStatus describes cluster's node status
@@ -495,11 +500,26 @@ type DetailsResponse struct {
 	Master        map[string]ResourceSummary `json:"master,omitempty"`
 	TotalSummary  *ResourceSummary           `json:"totalSummary,omitempty"`
 	Status        string                     `json:"status"`
+	Certificate   *CertificateDetails        `json:"certificate,omitempty"`
 
 	// ONLY in case of GKE
 	Region string `json:"region,omitempty"`
 }
 
+// CertificateDetails describes the certificate issued for the domain of a cluster
+type CertificateDetails struct {
+	SecretID   string    `json:"secretId"`
+	SecretName string    `json:"secretName"`
+	Hosts      []string  `json:"hosts"`
+	NotBefore  time.Time `json:"notBefore"`
+	NotAfter   time.Time `json:"notAfter"`
+}
+
+// ClusterLabels describes the labels of a cluster
+type ClusterLabels struct {
+	Labels map[string]string `json:"labels"`
+}
+
 // PodDetailsResponse describes a pod
 type PodDetailsResponse struct {
 	Name          string            `json:"name"`
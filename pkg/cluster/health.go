@@ -12,15 +12,13 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package notify
+package cluster
 
-import (
-	"github.com/banzaicloud/pipeline/config"
-	"github.com/sirupsen/logrus"
-)
-
-var log logrus.FieldLogger
-
-func init() {
-	log = config.Logger()
+// HealthResponse describes the result of a cluster health check
+type HealthResponse struct {
+	Healthy    bool     `json:"healthy"`
+	Version    string   `json:"version,omitempty"`
+	Nodes      int      `json:"nodes"`
+	ReadyNodes int      `json:"readyNodes"`
+	Problems   []string `json:"problems,omitempty"`
 }
@@ -0,0 +1,106 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import "time"
+
+// Migration states
+const (
+	MigrationPending         = "PENDING"
+	MigrationCreatingCluster = "CREATING_CLUSTER"
+	MigrationBackingUp       = "BACKING_UP"
+	MigrationRestoring       = "RESTORING"
+	MigrationSucceeded       = "SUCCEEDED"
+	MigrationFailed          = "FAILED"
+)
+
+// Storage class actions of a migration
+const (
+	MigrationStorageClassExisting = "existing"
+	MigrationStorageClassCreated  = "created"
+	MigrationStorageClassMissing  = "missing"
+)
+
+// Migration result types
+const (
+	MigrationResultError   = "error"
+	MigrationResultWarning = "warning"
+)
+
+// Migration result scopes
+const (
+	MigrationScopeBackupService = "backupService"
+	MigrationScopeCluster       = "cluster"
+	MigrationScopeNamespace     = "namespace"
+)
+
+// MigrationTarget is the target of a migration, either an existing cluster or a new cluster created by the migration
+type MigrationTarget struct {
+	ClusterID uint                  `json:"clusterId,omitempty"`
+	Cluster   *CreateClusterRequest `json:"cluster,omitempty"`
+}
+
+// CreateMigrationRequest describes the migration of the workloads of a cluster to another cluster
+type CreateMigrationRequest struct {
+	SourceClusterID uint            `json:"sourceClusterId" binding:"required"`
+	Target          MigrationTarget `json:"target"`
+
+	// Namespaces are the migrated namespaces, all namespaces if empty
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Labels match the labels of the migrated resources, all resources if empty
+	Labels map[string]string `json:"labels,omitempty"`
+	// StorageClassMappings maps the storage classes of the source cluster to the storage classes of the target cluster
+	StorageClassMappings map[string]string `json:"storageClassMappings,omitempty"`
+}
+
+// MigrationResponse describes the migration of the workloads of a cluster to another cluster
+type MigrationResponse struct {
+	ID                   uint                    `json:"id"`
+	SourceClusterID      uint                    `json:"sourceClusterId"`
+	SourceClusterName    string                  `json:"sourceClusterName"`
+	TargetClusterID      uint                    `json:"targetClusterId"`
+	TargetClusterName    string                  `json:"targetClusterName"`
+	TargetCreated        bool                    `json:"targetCreated"`
+	Namespaces           []string                `json:"namespaces,omitempty"`
+	Labels               map[string]string       `json:"labels,omitempty"`
+	StorageClassMappings map[string]string       `json:"storageClassMappings,omitempty"`
+	VolumesMigrated      bool                    `json:"volumesMigrated"`
+	BackupName           string                  `json:"backupName,omitempty"`
+	RestoreName          string                  `json:"restoreName,omitempty"`
+	Status               string                  `json:"status"`
+	Message              string                  `json:"message,omitempty"`
+	StorageClasses       []MigrationStorageClass `json:"storageClasses,omitempty"`
+	Results              []MigrationResult       `json:"results,omitempty"`
+	CreatedAt            time.Time               `json:"createdAt"`
+	UpdatedAt            time.Time               `json:"updatedAt"`
+	FinishedAt           *time.Time              `json:"finishedAt,omitempty"`
+	CreatedBy            uint                    `json:"createdBy,omitempty"`
+}
+
+// MigrationStorageClass describes how a storage class of the source cluster is provided in the target cluster
+type MigrationStorageClass struct {
+	Source  string `json:"source"`
+	Target  string `json:"target,omitempty"`
+	Action  string `json:"action"`
+	Message string `json:"message,omitempty"`
+}
+
+// MigrationResult is an error or a warning of a migration about a resource
+type MigrationResult struct {
+	Type      string `json:"type"`
+	Scope     string `json:"scope"`
+	Namespace string `json:"namespace,omitempty"`
+	Message   string `json:"message"`
+}
@@ -0,0 +1,61 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+)
+
+// ### [ Posthook statuses ] ### //
+const (
+	PostHookPending   = "PENDING"
+	PostHookRunning   = "RUNNING"
+	PostHookSucceeded = "SUCCEEDED"
+	PostHookFailed    = "FAILED"
+	PostHookSkipped   = "SKIPPED"
+)
+
+// PostHookStatus describes the state of a single posthook of a cluster
+type PostHookStatus struct {
+	Name       string     `json:"name"`
+	DependsOn  []string   `json:"dependsOn,omitempty"`
+	Status     string     `json:"status"`
+	Attempts   int        `json:"attempts"`
+	LastError  string     `json:"lastError,omitempty"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Duration   string     `json:"duration,omitempty"`
+}
+
+// CustomPostHookRequest describes an organization defined posthook installing a Helm chart
+type CustomPostHookRequest struct {
+	Name            string `json:"name" binding:"required"`
+	ChartRepository string `json:"chartRepository" binding:"required"`
+	ChartName       string `json:"chartName" binding:"required"`
+	ChartVersion    string `json:"chartVersion,omitempty"`
+	Namespace       string `json:"namespace,omitempty"`
+	ReleaseName     string `json:"releaseName,omitempty"`
+	Values          string `json:"values,omitempty"`
+	Wait            bool   `json:"wait,omitempty"`
+}
+
+// CustomPostHookResponse describes an organization defined posthook
+type CustomPostHookResponse struct {
+	ID uint `json:"id"`
+	CustomPostHookRequest
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	CreatedBy uint      `json:"createdBy,omitempty"`
+}
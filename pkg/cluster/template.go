@@ -0,0 +1,183 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"time"
+)
+
+// ApplyClusterTemplate is the posthook installing the secrets and deployments of a cluster template
+const ApplyClusterTemplate = "ApplyClusterTemplate"
+
+// ### [ Cluster template drift kinds ] ### //
+const (
+	TemplateDriftNodePool   = "nodePool"
+	TemplateDriftDeployment = "deployment"
+	TemplateDriftSecret     = "secret"
+	TemplateDriftPostHook   = "postHook"
+)
+
+// ClusterTemplateSpec describes the content of a cluster template version.
+// The spec is stored as YAML and rendered as a Go template with the parameters before parsing.
+type ClusterTemplateSpec struct {
+	Parameters  []ClusterTemplateParameter  `json:"parameters,omitempty"`
+	Cluster     CreateClusterRequest        `json:"cluster"`
+	Deployments []ClusterTemplateDeployment `json:"deployments,omitempty"`
+	Secrets     []ClusterTemplateSecret     `json:"secrets,omitempty"`
+}
+
+// ClusterTemplateParameter describes a parameter of a cluster template
+type ClusterTemplateParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ClusterTemplateDeployment describes a Helm deployment installed by a cluster template
+type ClusterTemplateDeployment struct {
+	Name        string                 `json:"name"`
+	Version     string                 `json:"version,omitempty"`
+	ReleaseName string                 `json:"releaseName"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty"`
+	Wait        bool                   `json:"wait,omitempty"`
+}
+
+// ClusterTemplateSecret describes an organization secret installed into the cluster by a cluster template
+type ClusterTemplateSecret struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ClusterTemplateResources describes the resources installed by the ApplyClusterTemplate posthook
+type ClusterTemplateResources struct {
+	Deployments []ClusterTemplateDeployment `json:"deployments,omitempty"`
+	Secrets     []ClusterTemplateSecret     `json:"secrets,omitempty"`
+}
+
+// ClusterTemplateRequest describes a cluster template create request
+type ClusterTemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+	Spec        string `json:"spec" binding:"required"`
+}
+
+// ClusterTemplateVersionRequest describes a request adding a new version to a cluster template
+type ClusterTemplateVersionRequest struct {
+	Description *string `json:"description,omitempty"`
+	Spec        string  `json:"spec" binding:"required"`
+}
+
+// ClusterTemplateResponse describes a cluster template
+type ClusterTemplateResponse struct {
+	ID            uint      `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description,omitempty"`
+	LatestVersion int       `json:"latestVersion"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	CreatedBy     uint      `json:"createdBy,omitempty"`
+}
+
+// ClusterTemplateVersionResponse describes a version of a cluster template
+type ClusterTemplateVersionResponse struct {
+	Version   int       `json:"version"`
+	Spec      string    `json:"spec"`
+	CreatedAt time.Time `json:"createdAt"`
+	CreatedBy uint      `json:"createdBy,omitempty"`
+}
+
+// CreateClusterFromTemplateRequest describes a request creating a cluster from a cluster template
+type CreateClusterFromTemplateRequest struct {
+	Name       string            `json:"name" binding:"required"`
+	Version    int               `json:"version,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ClusterTemplateBindingResponse describes the cluster template version a cluster was created from
+type ClusterTemplateBindingResponse struct {
+	Template      string            `json:"template"`
+	Version       int               `json:"version"`
+	LatestVersion int               `json:"latestVersion"`
+	Parameters    map[string]string `json:"parameters,omitempty"`
+	CreatedAt     time.Time         `json:"createdAt"`
+}
+
+// ClusterTemplateDrift describes a difference between a cluster and the template version it was created from
+type ClusterTemplateDrift struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Message  string `json:"message"`
+}
+
+// ClusterTemplateDriftResponse describes the drift report of a cluster
+type ClusterTemplateDriftResponse struct {
+	ClusterTemplateBindingResponse
+	InSync bool                   `json:"inSync"`
+	Drifts []ClusterTemplateDrift `json:"drifts"`
+}
+
+// NodePoolCounts returns the requested node count of the node pools by name.
+func (r *CreateClusterRequest) NodePoolCounts() map[string]int {
+	counts := make(map[string]int)
+
+	if r.Properties == nil {
+		return counts
+	}
+
+	switch r.Cloud {
+	case Alibaba:
+		if r.Properties.CreateClusterACSK != nil {
+			for name, np := range r.Properties.CreateClusterACSK.NodePools {
+				counts[name] = np.Count
+			}
+		}
+	case Amazon:
+		if r.Properties.CreateClusterEKS != nil {
+			for name, np := range r.Properties.CreateClusterEKS.NodePools {
+				counts[name] = np.Count
+			}
+		}
+	case Azure:
+		if r.Properties.CreateClusterAKS != nil {
+			for name, np := range r.Properties.CreateClusterAKS.NodePools {
+				counts[name] = np.Count
+			}
+		}
+	case Google:
+		if r.Properties.CreateClusterGKE != nil {
+			for name, np := range r.Properties.CreateClusterGKE.NodePools {
+				counts[name] = np.Count
+			}
+		}
+	case Oracle:
+		if r.Properties.CreateClusterOKE != nil {
+			for name, np := range r.Properties.CreateClusterOKE.NodePools {
+				counts[name] = int(np.Count)
+			}
+		}
+	case DigitalOcean:
+		if r.Properties.CreateClusterDO != nil {
+			for name, np := range r.Properties.CreateClusterDO.NodePools {
+				counts[name] = np.Count
+			}
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,188 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DNS providers
+const (
+	Route53 = "route53"
+	Azure   = "azure"
+	Google  = "google"
+	RFC2136 = "rfc2136"
+)
+
+// Providers lists the supported DNS providers
+var Providers = []string{Route53, Azure, Google, RFC2136}
+
+// IsSupportedProvider checks if the given DNS provider is supported.
+func IsSupportedProvider(provider string) bool {
+	for _, p := range Providers {
+		if p == provider {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasOrganizationScopedCredentials checks if the DNS provider issues credentials restricted to the zone of an organization.
+// The other providers hand out the credentials of the whole provider to the clusters of the organizations.
+func HasOrganizationScopedCredentials(provider string) bool {
+	return provider == Route53
+}
+
+// ValidateProviderAssignment checks that the providers without organization scoped credentials are assigned to a single
+// organization, otherwise the organizations could manage the zones of each other with the credentials.
+func ValidateProviderAssignment(defaultProvider string, organizations map[string]string) error {
+	if !HasOrganizationScopedCredentials(defaultProvider) {
+		return errors.Errorf(
+			"the credentials of the %s DNS provider cannot be restricted to an organization, it can only be assigned to a single organization",
+			defaultProvider,
+		)
+	}
+
+	orgNames := make(map[string][]string)
+	for orgName, provider := range organizations {
+		if !HasOrganizationScopedCredentials(provider) {
+			orgNames[provider] = append(orgNames[provider], orgName)
+		}
+	}
+
+	for provider, names := range orgNames {
+		if len(names) > 1 {
+			sort.Strings(names)
+
+			return errors.Errorf(
+				"the credentials of the %s DNS provider cannot be restricted to an organization, it is assigned to multiple organizations: %s",
+				provider,
+				strings.Join(names, ", "),
+			)
+		}
+	}
+
+	return nil
+}
+
+// Secret keys of the RFC2136 provider
+const (
+	RFC2136TSIGSecret = "tsigSecret"
+)
+
+// Options of the DNS providers
+const (
+	Route53Region      = "region"
+	AzureResourceGroup = "resourceGroup"
+	GoogleProject      = "project"
+	RFC2136Host        = "host"
+	RFC2136Port        = "port"
+	RFC2136Zone        = "zone"
+	RFC2136TSIGKeyName = "tsigKeyName"
+	RFC2136TSIGAlg     = "tsigAlgorithm"
+)
+
+// ProviderSettings describes the DNS provider managing the domain of an organization.
+// Clients running in the clusters of the organization (eg. external-dns) can use the credentials
+// stored in the referenced secret to manage the records of the domain.
+type ProviderSettings struct {
+	Provider string
+	Domain   string
+
+	// ZoneID is the provider specific ID of the zone containing the domain
+	ZoneID string
+
+	// SecretID is the ID of the organization secret holding the credentials of the provider
+	SecretID string
+
+	// Options contains the provider specific, non-sensitive settings
+	Options map[string]string
+}
+
+// Record types
+const (
+	RecordTypeA     = "A"
+	RecordTypeAAAA  = "AAAA"
+	RecordTypeCNAME = "CNAME"
+	RecordTypeMX    = "MX"
+	RecordTypeNS    = "NS"
+	RecordTypeTXT   = "TXT"
+)
+
+// StaticRecordTypes lists the record types that can be pinned as static records
+var StaticRecordTypes = []string{RecordTypeA, RecordTypeAAAA, RecordTypeCNAME, RecordTypeMX, RecordTypeTXT}
+
+// Record is a DNS record set.
+// Name is the fully qualified name of the record set without the trailing dot.
+// TXT values are unquoted, MX values are in the "<preference> <host>" format.
+type Record struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	TTL    int64    `json:"ttl"`
+	Values []string `json:"values"`
+}
+
+// Custom domain states
+const (
+	DomainPending  = "PENDING"
+	DomainVerified = "VERIFIED"
+)
+
+// CustomDomainRequest describes a request attaching a custom domain to an organization
+type CustomDomainRequest struct {
+	Domain string `json:"domain" binding:"required"`
+}
+
+// CustomDomainResponse describes a custom domain of an organization.
+// The custom domain has to be delegated to the listed name servers before it can be verified.
+type CustomDomainResponse struct {
+	Domain      string     `json:"domain"`
+	Provider    string     `json:"provider"`
+	Status      string     `json:"status"`
+	NameServers []string   `json:"nameServers"`
+	Delegation  []Record   `json:"delegation"`
+	Message     string     `json:"message,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	VerifiedAt  *time.Time `json:"verifiedAt,omitempty"`
+}
+
+// StaticRecordRequest describes a request pinning a static record in a domain of an organization
+type StaticRecordRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Type   string   `json:"type" binding:"required"`
+	TTL    int64    `json:"ttl"`
+	Values []string `json:"values" binding:"required"`
+}
+
+// RecordResponse describes a DNS record set in a domain of an organization
+type RecordResponse struct {
+	Record
+
+	Domain string `json:"domain"`
+
+	// ID is the ID of the static record (if the record is pinned)
+	ID     uint `json:"id,omitempty"`
+	Pinned bool `json:"pinned"`
+
+	// Owner is the external-dns owner ID of the record (if the record is managed by a cluster)
+	Owner       string `json:"owner,omitempty"`
+	ClusterID   uint   `json:"clusterId,omitempty"`
+	ClusterName string `json:"clusterName,omitempty"`
+}
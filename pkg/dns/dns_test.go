@@ -0,0 +1,57 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dns
+
+import (
+	"testing"
+)
+
+func TestValidateProviderAssignment(t *testing.T) {
+	tests := map[string]struct {
+		defaultProvider string
+		organizations   map[string]string
+		valid           bool
+	}{
+		"route53": {
+			defaultProvider: Route53,
+			valid:           true,
+		},
+		"single organization": {
+			defaultProvider: Route53,
+			organizations:   map[string]string{"org1": Azure, "org2": Google, "org3": Route53},
+			valid:           true,
+		},
+		"default provider without scoped credentials": {
+			defaultProvider: RFC2136,
+			valid:           false,
+		},
+		"multiple organizations": {
+			defaultProvider: Route53,
+			organizations:   map[string]string{"org1": RFC2136, "org2": RFC2136},
+			valid:           false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateProviderAssignment(test.defaultProvider, test.organizations)
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if !test.valid && err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
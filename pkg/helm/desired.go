@@ -0,0 +1,74 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import "time"
+
+// Desired release states
+const (
+	DesiredReleasePending  = "PENDING"
+	DesiredReleaseSynced   = "SYNCED"
+	DesiredReleaseFailed   = "FAILED"
+	DesiredReleaseDeleting = "DELETING"
+)
+
+// Reconcile actions
+const (
+	ReconcileInstall = "install"
+	ReconcileUpgrade = "upgrade"
+	ReconcileDelete  = "delete"
+)
+
+// DesiredRelease describes a release the cluster should run
+type DesiredRelease struct {
+	Name        string                 `json:"name" binding:"required"`
+	Version     string                 `json:"version,omitempty"`
+	ReleaseName string                 `json:"releaseName" binding:"required"`
+	Namespace   string                 `json:"namespace,omitempty"`
+	Values      map[string]interface{} `json:"values,omitempty"`
+}
+
+// DesiredDeploymentsRequest describes the full set of desired releases of a cluster
+type DesiredDeploymentsRequest struct {
+	Releases []DesiredRelease `json:"releases"`
+}
+
+// DesiredReleaseStatus describes a desired release with its last reconciliation
+type DesiredReleaseStatus struct {
+	DesiredRelease
+	Status           string     `json:"status"`
+	LastAction       string     `json:"lastAction,omitempty"`
+	LastError        string     `json:"lastError,omitempty"`
+	LastReconciledAt *time.Time `json:"lastReconciledAt,omitempty"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+// DesiredDeploymentsResponse describes the desired releases of a cluster
+type DesiredDeploymentsResponse struct {
+	Releases []DesiredReleaseStatus `json:"releases"`
+}
+
+// ReleaseChange describes an action needed to converge a release to its desired state
+type ReleaseChange struct {
+	ReleaseName string `json:"releaseName"`
+	Action      string `json:"action"`
+	Reason      string `json:"reason"`
+}
+
+// DesiredDeploymentsDiffResponse lists the pending changes of the desired releases of a cluster
+type DesiredDeploymentsDiffResponse struct {
+	InSync  bool            `json:"inSync"`
+	Changes []ReleaseChange `json:"changes"`
+}
@@ -161,6 +161,32 @@ type GetDeploymentResponse struct {
 	Values       map[string]interface{} `json:"values"`
 }
 
+// DeploymentRevision describes a revision of a helm deployment
+type DeploymentRevision struct {
+	Revision     int32     `json:"revision"`
+	Status       string    `json:"status"`
+	Chart        string    `json:"chart"`
+	ChartName    string    `json:"chartName"`
+	ChartVersion string    `json:"chartVersion"`
+	AppVersion   string    `json:"appVersion,omitempty"`
+	Description  string    `json:"description"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// DeploymentDiffResponse describes the differences between two revisions of a helm deployment
+type DeploymentDiffResponse struct {
+	ReleaseName string             `json:"releaseName"`
+	From        DeploymentRevision `json:"from"`
+	To          DeploymentRevision `json:"to"`
+	Manifest    string             `json:"manifest"`
+	Values      string             `json:"values"`
+}
+
+// RollbackDeploymentRequest describes a helm deployment rollback request
+type RollbackDeploymentRequest struct {
+	Revision int32 `json:"revision" binding:"required"`
+}
+
 // GetDeploymentResourcesResponse lists the resources of a helm deployment
 type GetDeploymentResourcesResponse struct {
 	DeploymentResources []DeploymentResource `json:"resources"`
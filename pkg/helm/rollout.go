@@ -0,0 +1,89 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import "time"
+
+// Rollout strategies
+const (
+	RolloutAllAtOnce  = "allAtOnce"
+	RolloutSequential = "sequential"
+	RolloutCanary     = "canary"
+)
+
+// Rollout states
+const (
+	RolloutPending    = "PENDING"
+	RolloutRunning    = "RUNNING"
+	RolloutSucceeded  = "SUCCEEDED"
+	RolloutFailed     = "FAILED"
+	RolloutRolledBack = "ROLLED_BACK"
+	RolloutSkipped    = "SKIPPED"
+)
+
+// Rollout actions executed in a cluster
+const (
+	RolloutInstall = "install"
+	RolloutUpgrade = "upgrade"
+)
+
+// ClusterSelector selects the target clusters of a rollout.
+// Clusters listed by ID and clusters matching all labels are selected.
+type ClusterSelector struct {
+	ClusterIDs []uint            `json:"clusterIds,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// CreateRolloutRequest describes a deployment rolled out to multiple clusters
+type CreateRolloutRequest struct {
+	Name            string                 `json:"name" binding:"required"`
+	Version         string                 `json:"version,omitempty"`
+	ReleaseName     string                 `json:"releaseName" binding:"required"`
+	Namespace       string                 `json:"namespace,omitempty"`
+	Values          map[string]interface{} `json:"values,omitempty"`
+	Wait            bool                   `json:"wait,omitempty"`
+	Targets         ClusterSelector        `json:"targets"`
+	Strategy        string                 `json:"strategy,omitempty"`
+	CanaryClusterID uint                   `json:"canaryClusterId,omitempty"`
+}
+
+// RolloutResponse describes a deployment rolled out to multiple clusters
+type RolloutResponse struct {
+	ID          uint                   `json:"id"`
+	Name        string                 `json:"name"`
+	Version     string                 `json:"version,omitempty"`
+	ReleaseName string                 `json:"releaseName"`
+	Namespace   string                 `json:"namespace"`
+	Strategy    string                 `json:"strategy"`
+	Status      string                 `json:"status"`
+	Message     string                 `json:"message,omitempty"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+	CreatedBy   uint                   `json:"createdBy,omitempty"`
+	Clusters    []RolloutClusterStatus `json:"clusters,omitempty"`
+}
+
+// RolloutClusterStatus describes the result of a rollout in a single cluster
+type RolloutClusterStatus struct {
+	ClusterID        uint       `json:"clusterId"`
+	ClusterName      string     `json:"clusterName"`
+	Action           string     `json:"action,omitempty"`
+	Status           string     `json:"status"`
+	PreviousRevision int32      `json:"previousRevision,omitempty"`
+	Revision         int32      `json:"revision,omitempty"`
+	Error            string     `json:"error,omitempty"`
+	StartedAt        *time.Time `json:"startedAt,omitempty"`
+	FinishedAt       *time.Time `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,32 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+)
+
+// TemplateFuncMap returns the sprig functions for templates written by users.
+// Like Helm does for chart templates, it leaves out the functions reading the environment of Pipeline.
+func TemplateFuncMap() template.FuncMap {
+	funcMap := sprig.TxtFuncMap()
+
+	delete(funcMap, "env")
+	delete(funcMap, "expandenv")
+
+	return funcMap
+}
@@ -0,0 +1,96 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"time"
+)
+
+// ### [ Output types ] ### //
+const (
+	ObjectStoreOutput   = "objectStore"
+	ElasticsearchOutput = "elasticsearch"
+	LokiOutput          = "loki"
+	HTTPOutput          = "http"
+)
+
+// Output describes a destination of the logs of a cluster
+type Output struct {
+	Name string `json:"name" binding:"required"`
+	Type string `json:"type" binding:"required"`
+	// SecretID references the organization secret holding the credentials of the output,
+	// object store outputs use the secret of their bucket by default
+	SecretID      string                     `json:"secretId,omitempty"`
+	ObjectStore   *ObjectStoreOutputConfig   `json:"objectStore,omitempty"`
+	Elasticsearch *ElasticsearchOutputConfig `json:"elasticsearch,omitempty"`
+	Loki          *LokiOutputConfig          `json:"loki,omitempty"`
+	HTTP          *HTTPOutputConfig          `json:"http,omitempty"`
+}
+
+// ObjectStoreOutputConfig describes a managed bucket of the organization receiving logs
+type ObjectStoreOutputConfig struct {
+	Bucket string `json:"bucket" binding:"required"`
+	Cloud  string `json:"cloud" binding:"required"`
+	// Path is the prefix of the log objects in the bucket
+	Path string `json:"path,omitempty"`
+}
+
+// ElasticsearchOutputConfig describes an Elasticsearch cluster receiving logs, it uses a password secret for basic authentication
+type ElasticsearchOutputConfig struct {
+	Host      string `json:"host" binding:"required"`
+	Port      int    `json:"port,omitempty"`
+	Scheme    string `json:"scheme,omitempty"`
+	IndexName string `json:"indexName,omitempty"`
+	SSLVerify *bool  `json:"sslVerify,omitempty"`
+}
+
+// LokiOutputConfig describes a Loki server receiving logs, it uses a password secret for basic authentication
+type LokiOutputConfig struct {
+	URL      string `json:"url" binding:"required"`
+	TenantID string `json:"tenantId,omitempty"`
+}
+
+// HTTPOutputConfig describes a generic HTTP endpoint receiving logs, it uses a password secret for basic authentication
+type HTTPOutputConfig struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+}
+
+// Flow routes the logs of the pods selected by namespace and labels to outputs
+type Flow struct {
+	Name string `json:"name" binding:"required"`
+	// Namespaces of the collected pods, all namespaces if empty
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selector matches the labels of the collected pods, all pods if empty
+	Selector map[string]string `json:"selector,omitempty"`
+	// Outputs are the names of the outputs receiving the logs
+	Outputs []string `json:"outputs" binding:"required"`
+}
+
+// Config describes the logging outputs and flows of a cluster
+type Config struct {
+	Outputs []Output `json:"outputs"`
+	Flows   []Flow   `json:"flows"`
+}
+
+// ConfigResponse describes the logging config of a cluster
+type ConfigResponse struct {
+	Config
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// RenderedConfigResponse describes the logging config rendered for the logging release of a cluster
+type RenderedConfigResponse struct {
+	Values string `json:"values"`
+}
@@ -0,0 +1,175 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/banzaicloud/pipeline/pkg/providers/alibaba"
+	"github.com/banzaicloud/pipeline/pkg/providers/amazon"
+	"github.com/banzaicloud/pipeline/pkg/providers/azure"
+	"github.com/banzaicloud/pipeline/pkg/providers/google"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Validate checks a logging config.
+func Validate(config Config) error {
+	outputs := make(map[string]bool, len(config.Outputs))
+	for _, output := range config.Outputs {
+		if err := validateOutput(output); err != nil {
+			return err
+		}
+
+		if outputs[output.Name] {
+			return errors.Errorf("duplicate output %q", output.Name)
+		}
+		outputs[output.Name] = true
+	}
+
+	flows := make(map[string]bool, len(config.Flows))
+	for _, flow := range config.Flows {
+		if err := validateFlow(flow, outputs); err != nil {
+			return err
+		}
+
+		if flows[flow.Name] {
+			return errors.Errorf("duplicate flow %q", flow.Name)
+		}
+		flows[flow.Name] = true
+	}
+
+	return nil
+}
+
+func validateOutput(output Output) error {
+	if errs := validation.IsDNS1123Label(output.Name); len(errs) > 0 {
+		return errors.Errorf("invalid output name %q: %s", output.Name, strings.Join(errs, "; "))
+	}
+
+	configs := 0
+	for _, set := range []bool{output.ObjectStore != nil, output.Elasticsearch != nil, output.Loki != nil, output.HTTP != nil} {
+		if set {
+			configs++
+		}
+	}
+
+	if configs > 1 {
+		return errors.Errorf("output %q has more than one destination", output.Name)
+	}
+
+	switch output.Type {
+	case ObjectStoreOutput:
+		if output.ObjectStore == nil {
+			return errors.Errorf("output %q has no object store config", output.Name)
+		}
+
+		if output.ObjectStore.Bucket == "" {
+			return errors.Errorf("output %q has no bucket", output.Name)
+		}
+
+		switch output.ObjectStore.Cloud {
+		case alibaba.Provider, amazon.Provider, azure.Provider, google.Provider:
+		default:
+			return errors.Errorf("output %q has unsupported bucket cloud %q", output.Name, output.ObjectStore.Cloud)
+		}
+
+	case ElasticsearchOutput:
+		if output.Elasticsearch == nil || output.Elasticsearch.Host == "" {
+			return errors.Errorf("output %q has no Elasticsearch host", output.Name)
+		}
+
+		switch output.Elasticsearch.Scheme {
+		case "", "http", "https":
+		default:
+			return errors.Errorf("output %q has invalid scheme %q", output.Name, output.Elasticsearch.Scheme)
+		}
+
+		if port := output.Elasticsearch.Port; port < 0 || port > 65535 {
+			return errors.Errorf("output %q has invalid port %d", output.Name, port)
+		}
+
+	case LokiOutput:
+		if output.Loki == nil {
+			return errors.Errorf("output %q has no Loki config", output.Name)
+		}
+
+		if err := validateURL(output.Loki.URL); err != nil {
+			return errors.WithMessage(err, "output "+output.Name)
+		}
+
+	case HTTPOutput:
+		if output.HTTP == nil {
+			return errors.Errorf("output %q has no HTTP config", output.Name)
+		}
+
+		if err := validateURL(output.HTTP.Endpoint); err != nil {
+			return errors.WithMessage(err, "output "+output.Name)
+		}
+
+	default:
+		return errors.Errorf("output %q has unknown type %q", output.Name, output.Type)
+	}
+
+	return nil
+}
+
+func validateFlow(flow Flow, outputs map[string]bool) error {
+	if errs := validation.IsDNS1123Label(flow.Name); len(errs) > 0 {
+		return errors.Errorf("invalid flow name %q: %s", flow.Name, strings.Join(errs, "; "))
+	}
+
+	for _, namespace := range flow.Namespaces {
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return errors.Errorf("invalid namespace %q in flow %q: %s", namespace, flow.Name, strings.Join(errs, "; "))
+		}
+	}
+
+	for key, value := range flow.Selector {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return errors.Errorf("invalid label key %q in flow %q: %s", key, flow.Name, strings.Join(errs, "; "))
+		}
+
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return errors.Errorf("invalid value of label %q in flow %q: %s", key, flow.Name, strings.Join(errs, "; "))
+		}
+	}
+
+	if len(flow.Outputs) == 0 {
+		return errors.Errorf("flow %q has no outputs", flow.Name)
+	}
+
+	for _, output := range flow.Outputs {
+		if !outputs[output] {
+			return errors.Errorf("flow %q refers to unknown output %q", flow.Name, output)
+		}
+	}
+
+	return nil
+}
+
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid URL %q", rawURL)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("invalid URL %q: an absolute http or https URL is required", rawURL)
+	}
+
+	return nil
+}
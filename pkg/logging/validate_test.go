@@ -0,0 +1,123 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Config{
+		Outputs: []Output{
+			{Name: "archive", Type: ObjectStoreOutput, ObjectStore: &ObjectStoreOutputConfig{Bucket: "logs", Cloud: "amazon"}},
+			{Name: "search", Type: ElasticsearchOutput, SecretID: "secret", Elasticsearch: &ElasticsearchOutputConfig{Host: "es.example.com", Scheme: "https"}},
+			{Name: "loki", Type: LokiOutput, Loki: &LokiOutputConfig{URL: "http://loki:3100"}},
+			{Name: "collector", Type: HTTPOutput, HTTP: &HTTPOutputConfig{Endpoint: "https://logs.example.com/ingest"}},
+		},
+		Flows: []Flow{
+			{Name: "all", Outputs: []string{"archive"}},
+			{Name: "apps", Namespaces: []string{"default", "apps"}, Selector: map[string]string{"app.kubernetes.io/name": "web"}, Outputs: []string{"search", "loki"}},
+		},
+	}
+
+	if err := Validate(valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]func(config *Config){
+		"invalid output name": func(config *Config) {
+			config.Outputs[0].Name = "Archive"
+		},
+		"duplicate output": func(config *Config) {
+			config.Outputs[1].Name = "archive"
+		},
+		"unknown output type": func(config *Config) {
+			config.Outputs[2].Type = "kafka"
+		},
+		"missing output config": func(config *Config) {
+			config.Outputs[2].Loki = nil
+		},
+		"multiple destinations": func(config *Config) {
+			config.Outputs[2].HTTP = &HTTPOutputConfig{Endpoint: "http://example.com"}
+		},
+		"unsupported bucket cloud": func(config *Config) {
+			config.Outputs[0].ObjectStore.Cloud = "oracle"
+		},
+		"invalid scheme": func(config *Config) {
+			config.Outputs[1].Elasticsearch.Scheme = "ftp"
+		},
+		"relative URL": func(config *Config) {
+			config.Outputs[3].HTTP.Endpoint = "/ingest"
+		},
+		"duplicate flow": func(config *Config) {
+			config.Flows[1].Name = "all"
+		},
+		"invalid namespace": func(config *Config) {
+			config.Flows[1].Namespaces = []string{"Apps"}
+		},
+		"invalid selector": func(config *Config) {
+			config.Flows[1].Selector = map[string]string{"app": "not valid"}
+		},
+		"flow without outputs": func(config *Config) {
+			config.Flows[0].Outputs = nil
+		},
+		"unknown flow output": func(config *Config) {
+			config.Flows[0].Outputs = []string{"missing"}
+		},
+	}
+
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := copyConfig(valid)
+			modify(&config)
+
+			if err := Validate(config); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+}
+
+func copyConfig(config Config) Config {
+	c := Config{}
+
+	for _, output := range config.Outputs {
+		if output.ObjectStore != nil {
+			objectStore := *output.ObjectStore
+			output.ObjectStore = &objectStore
+		}
+
+		if output.Elasticsearch != nil {
+			elasticsearch := *output.Elasticsearch
+			output.Elasticsearch = &elasticsearch
+		}
+
+		if output.Loki != nil {
+			loki := *output.Loki
+			output.Loki = &loki
+		}
+
+		if output.HTTP != nil {
+			http := *output.HTTP
+			output.HTTP = &http
+		}
+
+		c.Outputs = append(c.Outputs, output)
+	}
+
+	c.Flows = append(c.Flows, config.Flows...)
+
+	return c
+}
@@ -0,0 +1,104 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"time"
+)
+
+// ### [ Notification event types ] ### //
+const (
+	ClusterCreated         = "cluster_created"
+	ClusterCreationFailed  = "cluster_creation_failed"
+	ClusterPostHooksFailed = "cluster_posthooks_failed"
+	ClusterDeleted         = "cluster_deleted"
+	BackupFailed           = "backup_failed"
+	SecretValidationFailed = "secret_validation_failed"
+	Test                   = "test"
+)
+
+// EventTypes contains every event type a subscription can filter for
+var EventTypes = []string{
+	ClusterCreated,
+	ClusterCreationFailed,
+	ClusterPostHooksFailed,
+	ClusterDeleted,
+	BackupFailed,
+	SecretValidationFailed,
+}
+
+// ### [ Notification channels ] ### //
+const (
+	Slack   = "slack"
+	Webhook = "webhook"
+	Email   = "email"
+	Teams   = "teams"
+)
+
+// SlackConfig describes a Slack incoming webhook
+type SlackConfig struct {
+	WebhookURL string `json:"webhookUrl" binding:"required"`
+	Channel    string `json:"channel,omitempty"`
+}
+
+// WebhookConfig describes a generic webhook receiving the events as JSON
+type WebhookConfig struct {
+	URL string `json:"url" binding:"required"`
+	// Secret signs the requests with HMAC-SHA256 in the X-Pipeline-Signature-256 header, it is never returned
+	Secret string `json:"secret,omitempty"`
+}
+
+// EmailConfig describes the recipients of email notifications
+type EmailConfig struct {
+	To []string `json:"to" binding:"required"`
+}
+
+// TeamsConfig describes a Microsoft Teams incoming webhook
+type TeamsConfig struct {
+	WebhookURL string `json:"webhookUrl" binding:"required"`
+}
+
+// SubscriptionRequest describes a notification subscription of an organization
+type SubscriptionRequest struct {
+	Name string `json:"name" binding:"required"`
+	// Channel is one of slack, webhook, email or teams, the config of the channel is given in the field of the same name
+	Channel string         `json:"channel" binding:"required"`
+	Slack   *SlackConfig   `json:"slack,omitempty"`
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+	Email   *EmailConfig   `json:"email,omitempty"`
+	Teams   *TeamsConfig   `json:"teams,omitempty"`
+	// EventTypes filters the notified events, every event is notified if empty
+	EventTypes []string `json:"eventTypes,omitempty"`
+	// Template is a Go template of the message, the default message of the event is used if empty
+	Template string `json:"template,omitempty"`
+	Enabled  *bool  `json:"enabled,omitempty"`
+}
+
+// SubscriptionResponse describes a notification subscription of an organization
+type SubscriptionResponse struct {
+	ID         uint           `json:"id"`
+	Name       string         `json:"name"`
+	Channel    string         `json:"channel"`
+	Slack      *SlackConfig   `json:"slack,omitempty"`
+	Webhook    *WebhookConfig `json:"webhook,omitempty"`
+	Email      *EmailConfig   `json:"email,omitempty"`
+	Teams      *TeamsConfig   `json:"teams,omitempty"`
+	EventTypes []string       `json:"eventTypes"`
+	Template   string         `json:"template,omitempty"`
+	Enabled    bool           `json:"enabled"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	CreatedBy  uint           `json:"createdBy,omitempty"`
+}
@@ -26,6 +26,10 @@ const (
 	APIKeyFingerprint = "api_key_fingerprint"
 	Region            = "region"
 	CompartmentOCID   = "compartment_ocid"
+
+	// Customer secret keys used by the Amazon S3 Compatibility API of Object Storage
+	CustomerSecretKeyID = "customer_secret_key_id"
+	CustomerSecretKey   = "customer_secret_key"
 )
 
 // OCIVerify for validation OCI credentials
@@ -0,0 +1,62 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"time"
+)
+
+// ### [ Secret rotation statuses ] ### //
+const (
+	RotationSucceeded = "SUCCEEDED"
+	RotationFailed    = "FAILED"
+)
+
+// RotationPolicyRequest describes the rotation policy of a cloud credential secret
+type RotationPolicyRequest struct {
+	// Interval between two rotations, eg. 720h
+	Interval string `json:"interval" binding:"required"`
+	// GracePeriod after the old key is revoked, eg. 24h
+	GracePeriod string `json:"gracePeriod,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+}
+
+// RotationPolicyResponse describes the rotation policy of a cloud credential secret
+type RotationPolicyResponse struct {
+	SecretID       string     `json:"secretId"`
+	Interval       string     `json:"interval"`
+	GracePeriod    string     `json:"gracePeriod"`
+	Enabled        bool       `json:"enabled"`
+	LastRotatedAt  *time.Time `json:"lastRotatedAt,omitempty"`
+	NextRotationAt time.Time  `json:"nextRotationAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// RotationResponse describes a single rotation of a cloud credential secret
+type RotationResponse struct {
+	ID          uint       `json:"id"`
+	SecretID    string     `json:"secretId"`
+	Status      string     `json:"status"`
+	Message     string     `json:"message,omitempty"`
+	FromVersion int        `json:"fromVersion"`
+	ToVersion   int        `json:"toVersion,omitempty"`
+	NewKeyID    string     `json:"newKeyId,omitempty"`
+	OldKeyID    string     `json:"oldKeyId,omitempty"`
+	RevokeAfter *time.Time `json:"revokeAfter,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+	RevokeError string     `json:"revokeError,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
@@ -197,6 +197,8 @@ var DefaultRules = map[string]Meta{
 			{Name: oracle.APIKeyFingerprint, Required: true},
 			{Name: oracle.Region, Required: true},
 			{Name: oracle.CompartmentOCID, Required: true},
+			{Name: oracle.CustomerSecretKeyID, Required: false},
+			{Name: oracle.CustomerSecretKey, Required: false},
 		},
 	},
 	cluster.DigitalOcean: {
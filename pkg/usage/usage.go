@@ -0,0 +1,84 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package usage
+
+// ### [ Report periods ] ### //
+const (
+	Daily   = "daily"
+	Monthly = "monthly"
+)
+
+// ### [ Report groupings ] ### //
+const (
+	GroupByOrganization = "organization"
+	GroupByCluster      = "cluster"
+	GroupByNodePool     = "nodePool"
+	GroupByNamespace    = "namespace"
+)
+
+// UnallocatedNamespace is the namespace of the node costs not requested by any pod in namespace reports
+const UnallocatedNamespace = "_unallocated"
+
+// Currency of the costs
+const Currency = "USD"
+
+// ReportQuery describes the time range and the breakdown of a utilization and cost report
+type ReportQuery struct {
+	// Period is daily or monthly, daily by default
+	Period string `form:"period"`
+	// From is the first day of the report in YYYY-MM-DD format, 30 days ago by default
+	From string `form:"from"`
+	// To is the last day of the report in YYYY-MM-DD format, today by default
+	To string `form:"to"`
+	// GroupBy is organization, cluster, nodePool or namespace
+	GroupBy string `form:"groupBy"`
+}
+
+// ResourceUsage describes the capacity, the requests and the actual usage of a resource over a period,
+// CPU is measured in core-hours, memory in GiB-hours
+type ResourceUsage struct {
+	Capacity        float64 `json:"capacity,omitempty"`
+	Requests        float64 `json:"requests"`
+	Usage           float64 `json:"usage"`
+	RequestsPercent float64 `json:"requestsPercent,omitempty"`
+	UsagePercent    float64 `json:"usagePercent,omitempty"`
+}
+
+// ReportItem describes the utilization and the cost of a cluster, node pool or namespace in a period
+type ReportItem struct {
+	// Period is the day (YYYY-MM-DD) or the month (YYYY-MM) of the item
+	Period      string        `json:"period"`
+	ClusterID   uint          `json:"clusterId,omitempty"`
+	ClusterName string        `json:"clusterName,omitempty"`
+	NodePool    string        `json:"nodePool,omitempty"`
+	Namespace   string        `json:"namespace,omitempty"`
+	NodeHours   float64       `json:"nodeHours,omitempty"`
+	CPU         ResourceUsage `json:"cpu"`
+	Memory      ResourceUsage `json:"memory"`
+	Cost        float64       `json:"cost"`
+}
+
+// ReportResponse describes a utilization and cost report
+type ReportResponse struct {
+	Period    string       `json:"period"`
+	From      string       `json:"from"`
+	To        string       `json:"to"`
+	GroupBy   string       `json:"groupBy"`
+	Currency  string       `json:"currency"`
+	Items     []ReportItem `json:"items"`
+	TotalCost float64      `json:"totalCost"`
+	// UnpricedNodeHours are the node-hours of the instances without a known price, they are missing from the costs
+	UnpricedNodeHours float64 `json:"unpricedNodeHours,omitempty"`
+}
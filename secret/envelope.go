@@ -0,0 +1,126 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const envelopeKeySize = 32
+
+// Envelope encrypts every piece of data with a new random data key (AES-256-GCM)
+// and stores the data key encrypted with the master key next to the data.
+type Envelope struct {
+	masterKey cipher.AEAD
+}
+
+// NewEnvelope returns a new Envelope encrypting the data keys with the given 32 bytes long master key.
+func NewEnvelope(masterKey []byte) (*Envelope, error) {
+	if len(masterKey) != envelopeKeySize {
+		return nil, errors.Errorf("master key must be %d bytes long", envelopeKeySize)
+	}
+
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{masterKey: aead}, nil
+}
+
+// Encrypt encrypts the plaintext with a new data key and returns the encrypted data key and the ciphertext.
+// Both are bound to the associated data, so they can only be decrypted with the same associated data.
+func (e *Envelope) Encrypt(plaintext []byte, associatedData []byte) ([]byte, []byte, error) {
+	dataKey := make([]byte, envelopeKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, nil, errors.Wrap(err, "could not generate data key")
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, err := seal(aead, plaintext, associatedData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encryptedDataKey, err := seal(e.masterKey, dataKey, associatedData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return encryptedDataKey, ciphertext, nil
+}
+
+// Decrypt decrypts the data key with the master key, then the ciphertext with the data key.
+func (e *Envelope) Decrypt(encryptedDataKey []byte, ciphertext []byte, associatedData []byte) ([]byte, error) {
+	dataKey, err := open(e.masterKey, encryptedDataKey, associatedData)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt data key")
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(aead, ciphertext, associatedData)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decrypt data")
+	}
+
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create cipher")
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create GCM")
+	}
+
+	return aead, nil
+}
+
+// seal encrypts the plaintext with a random nonce prepended to the result.
+func seal(aead cipher.AEAD, plaintext []byte, associatedData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "could not generate nonce")
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(aead cipher.AEAD, data []byte, associatedData []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+
+	return aead.Open(nil, nonce, ciphertext, associatedData)
+}
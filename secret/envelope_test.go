@@ -0,0 +1,70 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEnvelope(t *testing.T) {
+	masterKey := bytes.Repeat([]byte{1}, envelopeKeySize)
+
+	e, err := NewEnvelope(masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte(`{"password":"secret"}`)
+
+	associatedData := []byte("1/secret/1")
+
+	dataKey, ciphertext, err := e.Encrypt(plaintext, associatedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(ciphertext, plaintext) {
+		t.Error("ciphertext contains the plaintext")
+	}
+
+	decrypted, err := e.Decrypt(dataKey, ciphertext, associatedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("expected %s, got %s", plaintext, decrypted)
+	}
+
+	other, err := NewEnvelope(bytes.Repeat([]byte{2}, envelopeKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := other.Decrypt(dataKey, ciphertext, associatedData); err == nil {
+		t.Error("expected decryption with a different master key to fail")
+	}
+
+	if _, err := e.Decrypt(dataKey, ciphertext, []byte("1/secret/2")); err == nil {
+		t.Error("expected decryption with different associated data to fail")
+	}
+}
+
+func TestEnvelope_InvalidMasterKey(t *testing.T) {
+	if _, err := NewEnvelope([]byte("short")); err == nil {
+		t.Error("expected error for a short master key")
+	}
+}
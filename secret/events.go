@@ -0,0 +1,46 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"github.com/banzaicloud/pipeline/config"
+)
+
+// ValidationFailedTopic is the name of the topic where failed secret validations are published.
+const ValidationFailedTopic = "secret_validation_failed"
+
+// secretEvents is responsible for dispatching secret related domain events.
+type secretEvents interface {
+	ValidationFailed(organizationID uint, secretName string, secretType string, reason string)
+}
+
+type eventBus interface {
+	Publish(topic string, args ...interface{})
+}
+
+type ebSecretEvents struct {
+	eb eventBus
+}
+
+func (e ebSecretEvents) ValidationFailed(organizationID uint, secretName string, secretType string, reason string) {
+	e.eb.Publish(ValidationFailedTopic, organizationID, secretName, secretType, reason)
+}
+
+var events secretEvents = ebSecretEvents{eb: config.EventBus}
+
+// PublishValidationFailed publishes a failed validation of a secret.
+func PublishValidationFailed(organizationID uint, secretName string, secretType string, reason string) {
+	events.ValidationFailed(organizationID, secretName, secretType, reason)
+}
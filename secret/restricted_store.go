@@ -43,6 +43,31 @@ func (s *restrictedSecretStore) List(orgid uint, query *secretTypes.ListSecretsQ
 	return newResponseItems, nil
 }
 
+func (s *restrictedSecretStore) GetVersion(organizationID uint, secretID string, version int) (*SecretItemResponse, error) {
+	if err := s.checkForbiddenTags(organizationID, secretID); err != nil {
+		return nil, err
+	}
+
+	secretItem, err := s.secretStore.GetVersion(organizationID, secretID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := HasForbiddenTag(secretItem.Tags); err != nil {
+		return nil, err
+	}
+
+	return secretItem, nil
+}
+
+func (s *restrictedSecretStore) ListVersions(organizationID uint, secretID string) ([]*SecretVersionResponse, error) {
+	if err := s.checkForbiddenTags(organizationID, secretID); err != nil {
+		return nil, err
+	}
+
+	return s.secretStore.ListVersions(organizationID, secretID)
+}
+
 func (s *restrictedSecretStore) Update(organizationID uint, secretID string, value *CreateSecretRequest) error {
 	if err := s.checkBlockingTags(organizationID, secretID); err != nil {
 		return err
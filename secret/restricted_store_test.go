@@ -29,6 +29,9 @@ const (
 var version = 1
 
 func TestBlockingTags(t *testing.T) {
+	if err := secret.InitStore(nil); err != nil {
+		t.Fatal(err)
+	}
 
 	cases := []struct {
 		name    string
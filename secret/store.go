@@ -16,6 +16,7 @@ package secret
 
 import (
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -24,20 +25,25 @@ import (
 	"time"
 
 	"github.com/banzaicloud/bank-vaults/pkg/tls"
-	"github.com/banzaicloud/bank-vaults/pkg/vault"
+	"github.com/banzaicloud/pipeline/config"
 	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
 	"github.com/banzaicloud/pipeline/secret/verify"
-	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/jinzhu/gorm"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/cast"
 	"github.com/spf13/viper"
 	"golang.org/x/crypto/bcrypt"
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
-// Store object that wraps up vault logical store
+// Secret store backends
+const (
+	BackendVault    = "vault"
+	BackendDatabase = "database"
+)
+
+// Store object that wraps up the configured secret store backend
 var Store *secretStore
 
 // RestrictedStore object that wraps the main secret store and restricts access to certain items
@@ -46,14 +52,92 @@ var RestrictedStore *restrictedSecretStore
 // ErrSecretNotExists denotes 'Not Found' errors for secrets
 var ErrSecretNotExists = fmt.Errorf("There's no secret with this ID")
 
-func init() {
-	Store = newVaultSecretStore()
+// InitStore sets up Store and RestrictedStore with the secret store backend selected in the configuration.
+// The database backend keeps the secrets in db, so it has to be migrated before the stores are used.
+func InitStore(db *gorm.DB) error {
+	store, err := newSecretStore(db)
+	if err != nil {
+		return err
+	}
+
+	Store = store
 	RestrictedStore = &restrictedSecretStore{Store}
+
+	return nil
+}
+
+// SecretStore is implemented by the secret store backends.
+// Writes use check-and-set semantics on the secret version: version 0 means the secret must not exist yet.
+type SecretStore interface {
+	// Store saves a new secret and returns its ID.
+	Store(organizationID uint, request *CreateSecretRequest) (string, error)
+
+	// Get returns the latest version of a secret.
+	Get(organizationID uint, secretID string) (*SecretItemResponse, error)
+
+	// GetByName returns the latest version of a secret by its name.
+	GetByName(organizationID uint, name string) (*SecretItemResponse, error)
+
+	// GetVersion returns a specific version of a secret.
+	GetVersion(organizationID uint, secretID string, version int) (*SecretItemResponse, error)
+
+	// ListVersions returns the metadata of every version of a secret ordered by version.
+	ListVersions(organizationID uint, secretID string) ([]*SecretVersionResponse, error)
+
+	// List returns the secrets of an organization matching the query.
+	List(organizationID uint, query *secretTypes.ListSecretsQuery) ([]*SecretItemResponse, error)
+
+	// Update saves a new version of a secret if request.Version matches its current version.
+	Update(organizationID uint, secretID string, request *CreateSecretRequest) error
+
+	// Delete deletes a secret with all of its versions.
+	Delete(organizationID uint, secretID string) error
+
+	// DeleteByClusterUID deletes the secrets belonging to a cluster.
+	DeleteByClusterUID(organizationID uint, clusterUID string) error
 }
 
+// secretStore adds convenience methods to a secret store backend.
 type secretStore struct {
-	Client  *vault.Client
-	Logical *vaultapi.Logical
+	SecretStore
+}
+
+func newSecretStore(db *gorm.DB) (*secretStore, error) {
+	switch backend := viper.GetString(config.SecretStoreBackend); backend {
+	case BackendVault:
+		store, err := newVaultSecretStore()
+		if err != nil {
+			return nil, err
+		}
+
+		return &secretStore{store}, nil
+
+	case BackendDatabase:
+		masterKey, err := MasterKey()
+		if err != nil {
+			return nil, err
+		}
+
+		store, err := newDatabaseSecretStore(db, masterKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return &secretStore{store}, nil
+
+	default:
+		return nil, errors.Errorf("unknown secret store backend: %s", backend)
+	}
+}
+
+// MasterKey returns the configured master key of the database secret store.
+func MasterKey() ([]byte, error) {
+	masterKey, err := base64.StdEncoding.DecodeString(viper.GetString(config.SecretStoreMasterKey))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid secret store master key")
+	}
+
+	return masterKey, nil
 }
 
 // CreateSecretResponse API response for AddSecrets
@@ -68,7 +152,7 @@ type CreateSecretResponse struct {
 }
 
 // CreateSecretRequest param for Store.Store
-// Only fields with `mapstructure` tag are getting written to Vault
+// Only fields with `mapstructure` tag are getting written to the store
 type CreateSecretRequest struct {
 	Name      string            `json:"name" binding:"required" mapstructure:"name"`
 	Type      string            `json:"type" binding:"required" mapstructure:"type"`
@@ -133,16 +217,6 @@ type AllowedFilteredSecretTypesResponse struct {
 // AllowedSecretTypesResponse for API response for AllowedSecretTypes
 type AllowedSecretTypesResponse map[string]secretTypes.Meta
 
-func newVaultSecretStore() *secretStore {
-	role := "pipeline"
-	client, err := vault.NewClient(role)
-	if err != nil {
-		panic(err)
-	}
-	logical := client.Vault().Logical()
-	return &secretStore{Client: client, Logical: logical}
-}
-
 // GenerateSecretIDFromName generates a "unique by name per organization" id for Secrets
 func GenerateSecretIDFromName(name string) string {
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(name)))
@@ -221,8 +295,42 @@ func (r *CreateSecretRequest) ValidateAsNew(verifier verify.Verifier) error {
 	return nil
 }
 
-// DeleteByClusterUID Delete secrets by ClusterUID
-func (ss *secretStore) DeleteByClusterUID(orgID uint, clusterUID string) error {
+// prepareRequest validates the name of a new secret, generates its values if needed and sorts its tags.
+func prepareRequest(request *CreateSecretRequest) error {
+	// We allow only Kubernetes compatible Secret names
+	if errorList := validation.IsDNS1123Subdomain(request.Name); errorList != nil {
+		return errors.New(errorList[0])
+	}
+
+	if err := generateValuesIfNeeded(request); err != nil {
+		return err
+	}
+
+	sort.Strings(request.Tags)
+
+	return nil
+}
+
+// getByName returns a secret of a backend by name.
+func getByName(store SecretStore, organizationID uint, name string) (*SecretItemResponse, error) {
+
+	secretID := GenerateSecretIDFromName(name)
+	secret, err := store.Get(organizationID, secretID)
+	if err == ErrSecretNotExists {
+		return nil, err
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Error during reading secret")
+	}
+
+	if secret == nil {
+		return nil, ErrSecretNotExists
+	}
+
+	return secret, nil
+}
+
+// deleteByClusterUID deletes the secrets of a backend tagged with the cluster UID.
+func deleteByClusterUID(store SecretStore, orgID uint, clusterUID string) error {
 	if clusterUID == "" {
 		return errors.New("ClusterUID is empty.")
 	}
@@ -230,7 +338,7 @@ func (ss *secretStore) DeleteByClusterUID(orgID uint, clusterUID string) error {
 	log := log.WithFields(logrus.Fields{"organization": orgID, "clusterUID": clusterUID})
 
 	clusterIdTag := fmt.Sprintf("clusterUID:%s", clusterUID)
-	secrets, err := Store.List(orgID,
+	secrets, err := store.List(orgID,
 		&secretTypes.ListSecretsQuery{
 			Tags: []string{clusterIdTag},
 		})
@@ -242,7 +350,7 @@ func (ss *secretStore) DeleteByClusterUID(orgID uint, clusterUID string) error {
 
 	for _, s := range secrets {
 		log := log.WithFields(logrus.Fields{"secret": s.ID, "secretName": s.Name})
-		err := Store.Delete(orgID, s.ID)
+		err := store.Delete(orgID, s.ID)
 		if err != nil {
 			log.Errorf("Error during delete secret: %s", err.Error())
 		}
@@ -252,78 +360,11 @@ func (ss *secretStore) DeleteByClusterUID(orgID uint, clusterUID string) error {
 	return nil
 }
 
-// Delete secret secret/orgs/:orgid:/:id: scope
-func (ss *secretStore) Delete(organizationID uint, secretID string) error {
-
-	path := secretMetadataPath(organizationID, secretID)
-
-	log.Debugln("Delete secret:", path)
-
-	if _, err := ss.Logical.Delete(path); err != nil {
-		return errors.Wrap(err, "Error during deleting secret")
-	}
-
-	return nil
-}
-
-// Save secret secret/orgs/:orgid:/:id: scope
-func (ss *secretStore) Store(organizationID uint, request *CreateSecretRequest) (string, error) {
-
-	// We allow only Kubernetes compatible Secret names
-	if errorList := validation.IsDNS1123Subdomain(request.Name); errorList != nil {
-		return "", errors.New(errorList[0])
-	}
-
-	secretID := GenerateSecretID(request)
-	path := secretDataPath(organizationID, secretID)
-
-	if err := generateValuesIfNeeded(request); err != nil {
-		return "", err
-	}
-
-	sort.Strings(request.Tags)
-
-	data, err := secretData(0, request)
-	if err != nil {
-		return "", err
-	}
-
-	if _, err := ss.Logical.Write(path, data); err != nil {
-		return "", errors.Wrap(err, "Error during storing secret")
-	}
-
-	return secretID, nil
-}
-
-// Update secret secret/orgs/:orgid:/:id: scope
-func (ss *secretStore) Update(organizationID uint, secretID string, request *CreateSecretRequest) error {
-
-	if GenerateSecretID(request) != secretID {
-		return errors.New("Secret name cannot be changed")
-	}
-
-	path := secretDataPath(organizationID, secretID)
-
-	log.Debugln("Update secret:", path)
-
-	sort.Strings(request.Tags)
-
-	// If secret doesn't exists, create it.
-	version := 0
-	if request.Version != nil {
-		version = *request.Version
-	}
-
-	data, err := secretData(version, request)
-	if err != nil {
-		return err
-	}
-
-	if _, err := ss.Logical.Write(path, data); err != nil {
-		return errors.Wrap(err, "Error during updating secret")
+// hideValues replaces the secret values with a placeholder.
+func hideValues(response *SecretItemResponse) {
+	for k := range response.Values {
+		response.Values[k] = "<hidden>"
 	}
-
-	return nil
 }
 
 // GetOrCreate create new secret or get if it's exist. secret/orgs/:orgid:/:id: scope
@@ -331,13 +372,13 @@ func (ss *secretStore) GetOrCreate(organizationID uint, value *CreateSecretReque
 	secretID := GenerateSecretID(value)
 
 	// Try to get the secret version first
-	if secret, err := Store.Get(organizationID, secretID); err != nil && err != ErrSecretNotExists {
+	if secret, err := ss.Get(organizationID, secretID); err != nil && err != ErrSecretNotExists {
 		log.Errorf("Error during checking secret: %s", err.Error())
 		return "", err
 	} else if secret != nil {
 		return secret.ID, nil
 	} else {
-		secretID, err = Store.Store(organizationID, value)
+		secretID, err = ss.Store(organizationID, value)
 		if err != nil {
 			log.Errorf("Error during storing secret: %s", err.Error())
 			return "", err
@@ -352,18 +393,18 @@ func (ss *secretStore) CreateOrUpdate(organizationID uint, value *CreateSecretRe
 	secretID := GenerateSecretID(value)
 
 	// Try to get the secret version first
-	if secret, err := Store.Get(organizationID, secretID); err != nil && err != ErrSecretNotExists {
+	if secret, err := ss.Get(organizationID, secretID); err != nil && err != ErrSecretNotExists {
 		log.Errorf("Error during checking secret: %s", err.Error())
 		return "", err
 	} else if secret != nil {
 		value.Version = &(secret.Version)
-		err := Store.Update(organizationID, secretID, value)
+		err := ss.Update(organizationID, secretID, value)
 		if err != nil {
 			log.Errorf("Error during updating secret: %s", err.Error())
 			return "", err
 		}
 	} else {
-		secretID, err = Store.Store(organizationID, value)
+		secretID, err = ss.Store(organizationID, value)
 		if err != nil {
 			log.Errorf("Error during storing secret: %s", err.Error())
 			return "", err
@@ -372,149 +413,6 @@ func (ss *secretStore) CreateOrUpdate(organizationID uint, value *CreateSecretRe
 	return secretID, nil
 }
 
-func parseSecret(secretID string, secret *vaultapi.Secret, values bool) (*SecretItemResponse, error) {
-
-	data := cast.ToStringMap(secret.Data["data"])
-	metadata := cast.ToStringMap(secret.Data["metadata"])
-
-	version, _ := metadata["version"].(json.Number).Int64()
-
-	updatedAt, err := time.Parse(time.RFC3339, metadata["created_time"].(string))
-	if err != nil {
-		return nil, err
-	}
-
-	response := SecretItemResponse{
-		ID:        secretID,
-		Version:   int(version),
-		UpdatedAt: updatedAt,
-	}
-
-	if err := mapstructure.Decode(data["value"], &response); err != nil {
-		return nil, err
-	}
-
-	if !values {
-		// Clear the values otherwise
-		for k := range response.Values {
-			response.Values[k] = "<hidden>"
-		}
-	}
-
-	return &response, nil
-}
-
-// Retrieve secret secret/orgs/:orgid:/:id: scope
-func (ss *secretStore) Get(organizationID uint, secretID string) (*SecretItemResponse, error) {
-
-	path := secretDataPath(organizationID, secretID)
-
-	log.Debugln("Get secret:", path)
-
-	secret, err := ss.Logical.Read(path)
-
-	if err != nil {
-		return nil, errors.Wrap(err, "Error during reading secret")
-	}
-
-	if secret == nil {
-		return nil, ErrSecretNotExists
-	}
-
-	return parseSecret(secretID, secret, true)
-}
-
-// Retrieve secret by secret Name secret/orgs/:orgid:/:id: scope
-func (ss *secretStore) GetByName(organizationID uint, name string) (*SecretItemResponse, error) {
-
-	secretID := GenerateSecretIDFromName(name)
-	secret, err := Store.Get(organizationID, secretID)
-	if err == ErrSecretNotExists {
-		return nil, err
-	} else if err != nil {
-		return nil, errors.Wrap(err, "Error during reading secret")
-	}
-
-	if secret == nil {
-		return nil, ErrSecretNotExists
-	}
-
-	return secret, nil
-}
-
-func (ss *secretStore) getSecretIDs(orgid uint, query *secretTypes.ListSecretsQuery) ([]string, error) {
-	if len(query.IDs) > 0 {
-		return query.IDs, nil
-	}
-
-	listPath := fmt.Sprintf("secret/metadata/orgs/%d", orgid)
-
-	list, err := ss.Logical.List(listPath)
-	if err != nil {
-		return nil, err
-	}
-
-	if list != nil {
-		return cast.ToStringSlice(list.Data["keys"]), nil
-	}
-
-	return []string{}, nil
-}
-
-// List secret secret/orgs/:orgid:/ scope
-func (ss *secretStore) List(orgid uint, query *secretTypes.ListSecretsQuery) ([]*SecretItemResponse, error) {
-
-	log.Debugf("Searching for secrets [orgid: %d, query: %#v]", orgid, query)
-
-	secretIDs, err := ss.getSecretIDs(orgid, query)
-	if err != nil {
-		log.Errorf("Error listing secrets: %s", err.Error())
-		return nil, err
-	}
-
-	responseItems := []*SecretItemResponse{}
-
-	for _, secretID := range secretIDs {
-
-		if secret, err := ss.Logical.Read(secretDataPath(orgid, secretID)); err != nil {
-
-			log.Errorf("Error listing secrets: %s", err.Error())
-			return nil, err
-
-		} else if secret != nil {
-
-			sir, err := parseSecret(secretID, secret, query.Values)
-			if err != nil {
-				return nil, err
-			}
-
-			if (query.Type == secretTypes.AllSecrets || sir.Type == query.Type) && hasTags(sir.Tags, query.Tags) {
-				responseItems = append(responseItems, sir)
-			}
-		}
-	}
-
-	return responseItems, nil
-}
-
-func secretData(version int, request *CreateSecretRequest) (map[string]interface{}, error) {
-	valueData := map[string]interface{}{}
-
-	if err := mapstructure.Decode(request, &valueData); err != nil {
-		return nil, errors.Wrap(err, "Error during encoding secret")
-	}
-
-	return vault.NewData(version, map[string]interface{}{"value": valueData}), nil
-}
-
-func secretDataPath(organizationID uint, secretID string) string {
-	return fmt.Sprintf("secret/data/orgs/%d/%s", organizationID, secretID)
-}
-
-func secretMetadataPath(organizationID uint, secretID string) string {
-	return fmt.Sprintf("secret/metadata/orgs/%d/%s", organizationID, secretID)
-}
-
 func hasTags(tags []string, searchingTag []string) bool {
 	var isOK bool
 	for _, t := range searchingTag {
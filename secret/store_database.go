@@ -0,0 +1,397 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/goph/emperror"
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const secretsTableName = "secrets"
+
+// SecretModel is a single version of a secret stored in the database.
+// The secret values are encrypted with envelope encryption.
+type SecretModel struct {
+	ID             uint   `gorm:"primary_key"`
+	OrganizationID uint   `gorm:"unique_index:idx_secret_org_id_version"`
+	SecretID       string `gorm:"unique_index:idx_secret_org_id_version"`
+	Version        int    `gorm:"unique_index:idx_secret_org_id_version"`
+	Name           string
+	Type           string
+	Tags           string `sql:"type:text;"`
+	UpdatedBy      string
+	DataKey        string `sql:"type:text;"`
+	Values         string `sql:"type:text;"`
+	CreatedAt      time.Time
+}
+
+// TableName changes the default table name.
+func (SecretModel) TableName() string {
+	return secretsTableName
+}
+
+// Migrate executes the table migrations for the secret model.
+func Migrate(db *gorm.DB, logger logrus.FieldLogger) error {
+	tables := []interface{}{
+		&SecretModel{},
+	}
+
+	var tableNames string
+	for _, table := range tables {
+		tableNames += fmt.Sprintf(" %s", db.NewScope(table).TableName())
+	}
+
+	logger.WithFields(logrus.Fields{
+		"table_names": strings.TrimSpace(tableNames),
+	}).Info("migrating secret tables")
+
+	return db.AutoMigrate(tables...).Error
+}
+
+// casError mimics the error message returned by Vault on check-and-set failures, see IsCASError.
+type casError struct {
+	secretID string
+	version  int
+	current  int
+}
+
+func (e casError) Error() string {
+	return fmt.Sprintf(
+		"secret %s: version %d does not match current version %d: check-and-set parameter did not match the current version",
+		e.secretID,
+		e.version,
+		e.current,
+	)
+}
+
+// databaseSecretStore stores the secrets encrypted in the Pipeline database
+type databaseSecretStore struct {
+	db       *gorm.DB
+	envelope *Envelope
+}
+
+func newDatabaseSecretStore(db *gorm.DB, masterKey []byte) (*databaseSecretStore, error) {
+	envelope, err := NewEnvelope(masterKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid secret store master key")
+	}
+
+	return &databaseSecretStore{db: db, envelope: envelope}, nil
+}
+
+// Store saves the first version of a new secret.
+func (ss *databaseSecretStore) Store(organizationID uint, request *CreateSecretRequest) (string, error) {
+	if err := prepareRequest(request); err != nil {
+		return "", err
+	}
+
+	secretID := GenerateSecretID(request)
+
+	if err := ss.save(organizationID, secretID, 0, request); err != nil {
+		return "", errors.Wrap(err, "Error during storing secret")
+	}
+
+	return secretID, nil
+}
+
+// Update saves a new version of an existing secret.
+func (ss *databaseSecretStore) Update(organizationID uint, secretID string, request *CreateSecretRequest) error {
+	if GenerateSecretID(request) != secretID {
+		return errors.New("Secret name cannot be changed")
+	}
+
+	log.Debugf("Update secret: [orgid: %d, id: %s]", organizationID, secretID)
+
+	sort.Strings(request.Tags)
+
+	// If secret doesn't exists, create it.
+	version := 0
+	if request.Version != nil {
+		version = *request.Version
+	}
+
+	if err := ss.save(organizationID, secretID, version, request); err != nil {
+		return errors.Wrap(err, "Error during updating secret")
+	}
+
+	return nil
+}
+
+// save inserts a new version of the secret if its current version matches the expected one.
+func (ss *databaseSecretStore) save(organizationID uint, secretID string, version int, request *CreateSecretRequest) error {
+	tx := ss.db.Begin()
+	if err := tx.Error; err != nil {
+		return errors.Wrap(err, "could not start transaction")
+	}
+
+	current, err := latestVersion(tx, organizationID, secretID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if current != version {
+		tx.Rollback()
+		return casError{secretID: secretID, version: version, current: current}
+	}
+
+	model, err := ss.newModel(organizationID, secretID, current+1, request)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(model).Error; err != nil {
+		tx.Rollback()
+		return emperror.With(errors.Wrap(err, "could not save secret"), "secretId", secretID, "version", model.Version)
+	}
+
+	return errors.Wrap(tx.Commit().Error, "could not commit transaction")
+}
+
+func (ss *databaseSecretStore) newModel(organizationID uint, secretID string, version int, request *CreateSecretRequest) (*SecretModel, error) {
+	tags, err := json.Marshal(request.Tags)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode secret tags")
+	}
+
+	values, err := json.Marshal(request.Values)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encode secret values")
+	}
+
+	dataKey, ciphertext, err := ss.envelope.Encrypt(values, associatedData(organizationID, secretID, version))
+	if err != nil {
+		return nil, errors.Wrap(err, "could not encrypt secret values")
+	}
+
+	return &SecretModel{
+		OrganizationID: organizationID,
+		SecretID:       secretID,
+		Version:        version,
+		Name:           request.Name,
+		Type:           request.Type,
+		Tags:           string(tags),
+		UpdatedBy:      request.UpdatedBy,
+		DataKey:        base64.StdEncoding.EncodeToString(dataKey),
+		Values:         base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+// associatedData binds the encrypted values to a secret version,
+// so they cannot be moved to another organization, secret or version in the database.
+func associatedData(organizationID uint, secretID string, version int) []byte {
+	return []byte(fmt.Sprintf("%d/%s/%d", organizationID, secretID, version))
+}
+
+func latestVersion(db *gorm.DB, organizationID uint, secretID string) (int, error) {
+	var result struct {
+		Version int
+	}
+
+	err := db.Model(&SecretModel{}).
+		Select("COALESCE(MAX(version), 0) AS version").
+		Where(&SecretModel{OrganizationID: organizationID, SecretID: secretID}).
+		Scan(&result).Error
+	if err != nil {
+		return 0, emperror.With(errors.Wrap(err, "could not get latest secret version"), "secretId", secretID)
+	}
+
+	return result.Version, nil
+}
+
+func (ss *databaseSecretStore) parseModel(model *SecretModel, values bool) (*SecretItemResponse, error) {
+	response := SecretItemResponse{
+		ID:        model.SecretID,
+		Name:      model.Name,
+		Type:      model.Type,
+		Version:   model.Version,
+		UpdatedAt: model.CreatedAt,
+		UpdatedBy: model.UpdatedBy,
+	}
+
+	if err := json.Unmarshal([]byte(model.Tags), &response.Tags); err != nil {
+		return nil, errors.Wrap(err, "could not decode secret tags")
+	}
+
+	dataKey, err := base64.StdEncoding.DecodeString(model.DataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode secret data key")
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(model.Values)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode secret values")
+	}
+
+	plaintext, err := ss.envelope.Decrypt(dataKey, ciphertext, associatedData(model.OrganizationID, model.SecretID, model.Version))
+	if err != nil {
+		return nil, emperror.With(errors.Wrap(err, "could not decrypt secret values"), "secretId", model.SecretID)
+	}
+
+	if err := json.Unmarshal(plaintext, &response.Values); err != nil {
+		return nil, errors.Wrap(err, "could not decode secret values")
+	}
+
+	if !values {
+		hideValues(&response)
+	}
+
+	return &response, nil
+}
+
+// Get returns the latest version of a secret.
+func (ss *databaseSecretStore) Get(organizationID uint, secretID string) (*SecretItemResponse, error) {
+	log.Debugf("Get secret: [orgid: %d, id: %s]", organizationID, secretID)
+
+	var model SecretModel
+
+	err := ss.db.
+		Where(&SecretModel{OrganizationID: organizationID, SecretID: secretID}).
+		Order("version DESC").
+		First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrSecretNotExists
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Error during reading secret")
+	}
+
+	return ss.parseModel(&model, true)
+}
+
+// GetVersion returns a specific version of a secret.
+func (ss *databaseSecretStore) GetVersion(organizationID uint, secretID string, version int) (*SecretItemResponse, error) {
+	log.Debugf("Get secret version: [orgid: %d, id: %s, version: %d]", organizationID, secretID, version)
+
+	var model SecretModel
+
+	err := ss.db.
+		Where(&SecretModel{OrganizationID: organizationID, SecretID: secretID}).
+		Where("version = ?", version).
+		First(&model).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, ErrSecretNotExists
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Error during reading secret version")
+	}
+
+	return ss.parseModel(&model, true)
+}
+
+// ListVersions returns the metadata of every version of a secret.
+func (ss *databaseSecretStore) ListVersions(organizationID uint, secretID string) ([]*SecretVersionResponse, error) {
+	log.Debugf("List secret versions: [orgid: %d, id: %s]", organizationID, secretID)
+
+	var models []SecretModel
+
+	err := ss.db.
+		Select("version, updated_by, created_at").
+		Where(&SecretModel{OrganizationID: organizationID, SecretID: secretID}).
+		Order("version").
+		Find(&models).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "Error during listing secret versions")
+	}
+
+	if len(models) == 0 {
+		return nil, ErrSecretNotExists
+	}
+
+	versions := make([]*SecretVersionResponse, 0, len(models))
+	for _, model := range models {
+		versions = append(versions, &SecretVersionResponse{
+			Version:   model.Version,
+			UpdatedAt: model.CreatedAt,
+			UpdatedBy: model.UpdatedBy,
+		})
+	}
+
+	return versions, nil
+}
+
+// GetByName returns the latest version of a secret by its name.
+func (ss *databaseSecretStore) GetByName(organizationID uint, name string) (*SecretItemResponse, error) {
+	return getByName(ss, organizationID, name)
+}
+
+// List returns the latest version of the secrets matching the query.
+func (ss *databaseSecretStore) List(organizationID uint, query *secretTypes.ListSecretsQuery) ([]*SecretItemResponse, error) {
+	log.Debugf("Searching for secrets [orgid: %d, query: %#v]", organizationID, query)
+
+	latestVersions := ss.db.Model(&SecretModel{}).
+		Select("secret_id, MAX(version)").
+		Where(&SecretModel{OrganizationID: organizationID}).
+		Group("secret_id")
+
+	db := ss.db.
+		Where(&SecretModel{OrganizationID: organizationID}).
+		Where("(secret_id, version) IN (?)", latestVersions.QueryExpr())
+	if len(query.IDs) > 0 {
+		db = db.Where("secret_id IN (?)", query.IDs)
+	}
+
+	var models []SecretModel
+
+	if err := db.Order("secret_id").Find(&models).Error; err != nil {
+		log.Errorf("Error listing secrets: %s", err.Error())
+		return nil, errors.Wrap(err, "Error during listing secrets")
+	}
+
+	responseItems := []*SecretItemResponse{}
+
+	for i, model := range models {
+		if query.Type != secretTypes.AllSecrets && model.Type != query.Type {
+			continue
+		}
+
+		sir, err := ss.parseModel(&models[i], query.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		if hasTags(sir.Tags, query.Tags) {
+			responseItems = append(responseItems, sir)
+		}
+	}
+
+	return responseItems, nil
+}
+
+// Delete deletes a secret with all of its versions.
+func (ss *databaseSecretStore) Delete(organizationID uint, secretID string) error {
+	log.Debugf("Delete secret: [orgid: %d, id: %s]", organizationID, secretID)
+
+	err := ss.db.Where(&SecretModel{OrganizationID: organizationID, SecretID: secretID}).Delete(&SecretModel{}).Error
+	if err != nil {
+		return errors.Wrap(err, "Error during deleting secret")
+	}
+
+	return nil
+}
+
+// DeleteByClusterUID deletes the secrets belonging to a cluster.
+func (ss *databaseSecretStore) DeleteByClusterUID(organizationID uint, clusterUID string) error {
+	return deleteByClusterUID(ss, organizationID, clusterUID)
+}
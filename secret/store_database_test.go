@@ -0,0 +1,190 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+)
+
+func newTestDatabaseSecretStore(t *testing.T) *databaseSecretStore {
+	db, err := gorm.Open("sqlite3", "file::memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every connection would get its own in-memory database
+	db.DB().SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&SecretModel{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := newDatabaseSecretStore(db, bytes.Repeat([]byte{1}, envelopeKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return store
+}
+
+func newTestSecretRequest(password string, version *int) *CreateSecretRequest {
+	return &CreateSecretRequest{
+		Name:    "my-secret",
+		Type:    secretTypes.GenericSecret,
+		Values:  map[string]string{"password": password},
+		Version: version,
+	}
+}
+
+func TestDatabaseSecretStore_Versions(t *testing.T) {
+	const orgID = 1
+
+	store := newTestDatabaseSecretStore(t)
+
+	secretID, err := store.Store(orgID, newTestSecretRequest("first", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	version := 1
+	if err := store.Update(orgID, secretID, newTestSecretRequest("second", &version)); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := store.Get(orgID, secretID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if latest.Version != 2 || latest.Values["password"] != "second" {
+		t.Errorf("unexpected latest secret version %d with values %v", latest.Version, latest.Values)
+	}
+
+	first, err := store.GetVersion(orgID, secretID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Version != 1 || first.Values["password"] != "first" {
+		t.Errorf("unexpected first secret version %d with values %v", first.Version, first.Values)
+	}
+
+	versions, err := store.ListVersions(orgID, secretID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var versionNumbers []int
+	for _, v := range versions {
+		versionNumbers = append(versionNumbers, v.Version)
+	}
+
+	if expected := []int{1, 2}; !reflect.DeepEqual(versionNumbers, expected) {
+		t.Errorf("expected versions %v, got %v", expected, versionNumbers)
+	}
+
+	secrets, err := store.List(orgID, &secretTypes.ListSecretsQuery{Type: secretTypes.AllSecrets, Values: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(secrets) != 1 || secrets[0].Version != 2 {
+		t.Errorf("expected only the latest version to be listed, got %+v", secrets)
+	}
+
+	if _, err := store.GetVersion(orgID, secretID, 3); err != ErrSecretNotExists {
+		t.Errorf("expected ErrSecretNotExists for a missing version, got %v", err)
+	}
+
+	var firstModel SecretModel
+	if err := store.db.Where("version = ?", 1).First(&firstModel).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.db.Model(&SecretModel{}).
+		Where("version = ?", 2).
+		Updates(map[string]interface{}{"data_key": firstModel.DataKey, "values": firstModel.Values}).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(orgID, secretID); err == nil {
+		t.Error("expected the values of another version to fail decryption")
+	}
+}
+
+func TestDatabaseSecretStore_CheckAndSet(t *testing.T) {
+	const orgID = 1
+
+	store := newTestDatabaseSecretStore(t)
+
+	secretID, err := store.Store(orgID, newTestSecretRequest("first", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Store(orgID, newTestSecretRequest("again", nil)); err == nil || !IsCASError(err) {
+		t.Errorf("expected a check-and-set error when storing an existing secret, got %v", err)
+	}
+
+	if err := store.Update(orgID, secretID, newTestSecretRequest("unversioned", nil)); err == nil || !IsCASError(err) {
+		t.Errorf("expected a check-and-set error when updating without a version, got %v", err)
+	}
+
+	stale := 2
+	if err := store.Update(orgID, secretID, newTestSecretRequest("stale", &stale)); err == nil || !IsCASError(err) {
+		t.Errorf("expected a check-and-set error when updating a stale version, got %v", err)
+	}
+
+	current := 1
+	if err := store.Update(orgID, secretID, newTestSecretRequest("second", &current)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Update(orgID, secretID, newTestSecretRequest("stale", &current)); err == nil || !IsCASError(err) {
+		t.Errorf("expected a check-and-set error when updating an outdated version, got %v", err)
+	}
+
+	latest, err := store.Get(orgID, secretID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if latest.Version != 2 || latest.Values["password"] != "second" {
+		t.Errorf("unexpected latest secret version %d with values %v", latest.Version, latest.Values)
+	}
+
+	if _, err := store.Get(orgID+1, secretID); err != ErrSecretNotExists {
+		t.Errorf("expected secrets to be isolated between organizations, got %v", err)
+	}
+
+	if err := store.Delete(orgID, secretID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(orgID, secretID); err != ErrSecretNotExists {
+		t.Errorf("expected ErrSecretNotExists after delete, got %v", err)
+	}
+
+	if _, err := store.Store(orgID, newTestSecretRequest("recreated", nil)); err != nil {
+		t.Errorf("expected the secret to be recreated after delete, got %v", err)
+	}
+}
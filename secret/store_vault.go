@@ -0,0 +1,341 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/banzaicloud/bank-vaults/pkg/vault"
+	secretTypes "github.com/banzaicloud/pipeline/pkg/secret"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// vaultSecretStore stores the secrets in the KV version 2 secret engine of Vault
+type vaultSecretStore struct {
+	Client  *vault.Client
+	Logical *vaultapi.Logical
+}
+
+func newVaultSecretStore() (*vaultSecretStore, error) {
+	role := "pipeline"
+	client, err := vault.NewClient(role)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not create Vault client")
+	}
+	logical := client.Vault().Logical()
+	return &vaultSecretStore{Client: client, Logical: logical}, nil
+}
+
+// IsVaultStore tells whether the secrets are stored in Vault.
+func IsVaultStore() bool {
+	_, ok := Store.SecretStore.(*vaultSecretStore)
+
+	return ok
+}
+
+// ReadVaultPath reads a raw Vault path outside of the organization secrets.
+// It returns nil if the path does not exist or the secrets are not stored in Vault.
+func ReadVaultPath(path string) (*vaultapi.Secret, error) {
+	vaultStore, ok := Store.SecretStore.(*vaultSecretStore)
+	if !ok {
+		return nil, nil
+	}
+
+	return vaultStore.Logical.Read(path)
+}
+
+// DeleteByClusterUID Delete secrets by ClusterUID
+func (ss *vaultSecretStore) DeleteByClusterUID(orgID uint, clusterUID string) error {
+	return deleteByClusterUID(ss, orgID, clusterUID)
+}
+
+// Delete secret secret/orgs/:orgid:/:id: scope
+func (ss *vaultSecretStore) Delete(organizationID uint, secretID string) error {
+
+	path := secretMetadataPath(organizationID, secretID)
+
+	log.Debugln("Delete secret:", path)
+
+	if _, err := ss.Logical.Delete(path); err != nil {
+		return errors.Wrap(err, "Error during deleting secret")
+	}
+
+	return nil
+}
+
+// Save secret secret/orgs/:orgid:/:id: scope
+func (ss *vaultSecretStore) Store(organizationID uint, request *CreateSecretRequest) (string, error) {
+
+	if err := prepareRequest(request); err != nil {
+		return "", err
+	}
+
+	secretID := GenerateSecretID(request)
+	path := secretDataPath(organizationID, secretID)
+
+	data, err := secretData(0, request)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := ss.Logical.Write(path, data); err != nil {
+		return "", errors.Wrap(err, "Error during storing secret")
+	}
+
+	return secretID, nil
+}
+
+// Update secret secret/orgs/:orgid:/:id: scope
+func (ss *vaultSecretStore) Update(organizationID uint, secretID string, request *CreateSecretRequest) error {
+
+	if GenerateSecretID(request) != secretID {
+		return errors.New("Secret name cannot be changed")
+	}
+
+	path := secretDataPath(organizationID, secretID)
+
+	log.Debugln("Update secret:", path)
+
+	sort.Strings(request.Tags)
+
+	// If secret doesn't exists, create it.
+	version := 0
+	if request.Version != nil {
+		version = *request.Version
+	}
+
+	data, err := secretData(version, request)
+	if err != nil {
+		return err
+	}
+
+	if _, err := ss.Logical.Write(path, data); err != nil {
+		return errors.Wrap(err, "Error during updating secret")
+	}
+
+	return nil
+}
+
+func parseSecret(secretID string, secret *vaultapi.Secret, values bool) (*SecretItemResponse, error) {
+
+	data := cast.ToStringMap(secret.Data["data"])
+	metadata := cast.ToStringMap(secret.Data["metadata"])
+
+	version, _ := metadata["version"].(json.Number).Int64()
+
+	updatedAt, err := time.Parse(time.RFC3339, metadata["created_time"].(string))
+	if err != nil {
+		return nil, err
+	}
+
+	response := SecretItemResponse{
+		ID:        secretID,
+		Version:   int(version),
+		UpdatedAt: updatedAt,
+	}
+
+	if err := mapstructure.Decode(data["value"], &response); err != nil {
+		return nil, err
+	}
+
+	if !values {
+		// Clear the values otherwise
+		hideValues(&response)
+	}
+
+	return &response, nil
+}
+
+// Retrieve secret secret/orgs/:orgid:/:id: scope
+func (ss *vaultSecretStore) Get(organizationID uint, secretID string) (*SecretItemResponse, error) {
+
+	path := secretDataPath(organizationID, secretID)
+
+	log.Debugln("Get secret:", path)
+
+	secret, err := ss.Logical.Read(path)
+
+	if err != nil {
+		return nil, errors.Wrap(err, "Error during reading secret")
+	}
+
+	if secret == nil {
+		return nil, ErrSecretNotExists
+	}
+
+	return parseSecret(secretID, secret, true)
+}
+
+// Retrieve secret by secret Name secret/orgs/:orgid:/:id: scope
+func (ss *vaultSecretStore) GetByName(organizationID uint, name string) (*SecretItemResponse, error) {
+	return getByName(ss, organizationID, name)
+}
+
+// GetVersion returns a specific version of a secret
+func (ss *vaultSecretStore) GetVersion(organizationID uint, secretID string, version int) (*SecretItemResponse, error) {
+
+	path := secretDataPath(organizationID, secretID)
+
+	log.Debugln("Get secret version:", path, version)
+
+	secret, err := ss.Logical.ReadWithData(path, map[string][]string{"version": {strconv.Itoa(version)}})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error during reading secret version")
+	}
+
+	// Deleted and destroyed versions are returned without data
+	if secret == nil || secret.Data["data"] == nil {
+		return nil, ErrSecretNotExists
+	}
+
+	return parseSecret(secretID, secret, true)
+}
+
+// ListVersions returns the metadata of every version of a secret
+func (ss *vaultSecretStore) ListVersions(organizationID uint, secretID string) ([]*SecretVersionResponse, error) {
+
+	path := secretMetadataPath(organizationID, secretID)
+
+	log.Debugln("List secret versions:", path)
+
+	metadata, err := ss.Logical.Read(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error during reading secret metadata")
+	}
+
+	if metadata == nil {
+		return nil, ErrSecretNotExists
+	}
+
+	versions := []*SecretVersionResponse{}
+
+	for key, value := range cast.ToStringMap(metadata.Data["versions"]) {
+		version, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid secret version: %s", key)
+		}
+
+		versionMetadata := cast.ToStringMap(value)
+
+		updatedAt, err := time.Parse(time.RFC3339, cast.ToString(versionMetadata["created_time"]))
+		if err != nil {
+			return nil, err
+		}
+
+		item := &SecretVersionResponse{
+			Version:   version,
+			UpdatedAt: updatedAt,
+			Deleted:   cast.ToBool(versionMetadata["destroyed"]) || cast.ToString(versionMetadata["deletion_time"]) != "",
+		}
+
+		// The author of a version is only stored next to the data
+		if !item.Deleted {
+			secret, err := ss.GetVersion(organizationID, secretID, version)
+			if err != nil && err != ErrSecretNotExists {
+				return nil, err
+			} else if secret != nil {
+				item.UpdatedBy = secret.UpdatedBy
+			}
+		}
+
+		versions = append(versions, item)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	return versions, nil
+}
+
+func (ss *vaultSecretStore) getSecretIDs(orgid uint, query *secretTypes.ListSecretsQuery) ([]string, error) {
+	if len(query.IDs) > 0 {
+		return query.IDs, nil
+	}
+
+	listPath := fmt.Sprintf("secret/metadata/orgs/%d", orgid)
+
+	list, err := ss.Logical.List(listPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if list != nil {
+		return cast.ToStringSlice(list.Data["keys"]), nil
+	}
+
+	return []string{}, nil
+}
+
+// List secret secret/orgs/:orgid:/ scope
+func (ss *vaultSecretStore) List(orgid uint, query *secretTypes.ListSecretsQuery) ([]*SecretItemResponse, error) {
+
+	log.Debugf("Searching for secrets [orgid: %d, query: %#v]", orgid, query)
+
+	secretIDs, err := ss.getSecretIDs(orgid, query)
+	if err != nil {
+		log.Errorf("Error listing secrets: %s", err.Error())
+		return nil, err
+	}
+
+	responseItems := []*SecretItemResponse{}
+
+	for _, secretID := range secretIDs {
+
+		if secret, err := ss.Logical.Read(secretDataPath(orgid, secretID)); err != nil {
+
+			log.Errorf("Error listing secrets: %s", err.Error())
+			return nil, err
+
+		} else if secret != nil {
+
+			sir, err := parseSecret(secretID, secret, query.Values)
+			if err != nil {
+				return nil, err
+			}
+
+			if (query.Type == secretTypes.AllSecrets || sir.Type == query.Type) && hasTags(sir.Tags, query.Tags) {
+				responseItems = append(responseItems, sir)
+			}
+		}
+	}
+
+	return responseItems, nil
+}
+
+func secretData(version int, request *CreateSecretRequest) (map[string]interface{}, error) {
+	valueData := map[string]interface{}{}
+
+	if err := mapstructure.Decode(request, &valueData); err != nil {
+		return nil, errors.Wrap(err, "Error during encoding secret")
+	}
+
+	return vault.NewData(version, map[string]interface{}{"value": valueData}), nil
+}
+
+func secretDataPath(organizationID uint, secretID string) string {
+	return fmt.Sprintf("secret/data/orgs/%d/%s", organizationID, secretID)
+}
+
+func secretMetadataPath(organizationID uint, secretID string) string {
+	return fmt.Sprintf("secret/metadata/orgs/%d/%s", organizationID, secretID)
+}
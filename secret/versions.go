@@ -0,0 +1,104 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"sort"
+	"time"
+)
+
+// SecretVersionResponse describes a single version of a secret without its values
+type SecretVersionResponse struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	Deleted   bool      `json:"deleted,omitempty"`
+}
+
+// RollbackSecretRequest describes a secret rollback request
+type RollbackSecretRequest struct {
+	Version int `json:"version" binding:"required"`
+}
+
+// SecretDiffResponse describes the differences between two versions of a secret.
+// Only the keys of the changed values are returned, never the values themselves.
+type SecretDiffResponse struct {
+	From          int      `json:"from"`
+	To            int      `json:"to"`
+	TypeChanged   bool     `json:"typeChanged,omitempty"`
+	AddedValues   []string `json:"addedValues"`
+	RemovedValues []string `json:"removedValues"`
+	ChangedValues []string `json:"changedValues"`
+	AddedTags     []string `json:"addedTags"`
+	RemovedTags   []string `json:"removedTags"`
+}
+
+// DiffSecrets compares two versions of a secret
+func DiffSecrets(from *SecretItemResponse, to *SecretItemResponse) *SecretDiffResponse {
+	diff := &SecretDiffResponse{
+		From:          from.Version,
+		To:            to.Version,
+		TypeChanged:   from.Type != to.Type,
+		AddedValues:   []string{},
+		RemovedValues: []string{},
+		ChangedValues: []string{},
+	}
+
+	for key, value := range to.Values {
+		if fromValue, ok := from.Values[key]; !ok {
+			diff.AddedValues = append(diff.AddedValues, key)
+		} else if fromValue != value {
+			diff.ChangedValues = append(diff.ChangedValues, key)
+		}
+	}
+
+	for key := range from.Values {
+		if _, ok := to.Values[key]; !ok {
+			diff.RemovedValues = append(diff.RemovedValues, key)
+		}
+	}
+
+	sort.Strings(diff.AddedValues)
+	sort.Strings(diff.RemovedValues)
+	sort.Strings(diff.ChangedValues)
+
+	diff.AddedTags = subtractTags(to.Tags, from.Tags)
+	diff.RemovedTags = subtractTags(from.Tags, to.Tags)
+
+	return diff
+}
+
+// subtractTags returns the tags which are present in a, but not in b
+func subtractTags(a []string, b []string) []string {
+	result := []string{}
+
+	for _, tag := range a {
+		found := false
+		for _, t := range b {
+			if t == tag {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, tag)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
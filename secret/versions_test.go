@@ -0,0 +1,52 @@
+// Copyright © 2018 Banzai Cloud
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/pipeline/secret"
+)
+
+func TestDiffSecrets(t *testing.T) {
+	from := &secret.SecretItemResponse{
+		Version: 1,
+		Type:    "generic",
+		Values:  map[string]string{"user": "admin", "password": "old", "token": "abc"},
+		Tags:    []string{"a", "b"},
+	}
+
+	to := &secret.SecretItemResponse{
+		Version: 3,
+		Type:    "generic",
+		Values:  map[string]string{"user": "admin", "password": "new", "url": "http://example.com"},
+		Tags:    []string{"b", "c"},
+	}
+
+	expected := &secret.SecretDiffResponse{
+		From:          1,
+		To:            3,
+		AddedValues:   []string{"url"},
+		RemovedValues: []string{"token"},
+		ChangedValues: []string{"password"},
+		AddedTags:     []string{"c"},
+		RemovedTags:   []string{"a"},
+	}
+
+	if diff := secret.DiffSecrets(from, to); !reflect.DeepEqual(diff, expected) {
+		t.Errorf("expected %+v, got %+v", expected, diff)
+	}
+}